@@ -25,8 +25,16 @@ SOFTWARE.
 package v1alpha1
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
 	"github.com/ngrok/ngrok-api-go/v5"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,13 +44,144 @@ import (
 type DomainSpec struct {
 	ngrokAPICommon `json:",inline"`
 
-	// Domain is the domain name to reserve
+	// Domain is the domain name to reserve. A wildcard domain like *.example.com reserves all of its
+	// parent domain's subdomains so they can share a single edge.
 	// +kubebuilder:validation:Required
 	Domain string `json:"domain"`
 
 	// Region is the region in which to reserve the domain
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=us;eu;au;ap;sa;jp;in
 	Region string `json:"region,omitempty"`
+
+	// DeletionProtection keeps the reserved domain in ngrok when the Domain is deleted, so deleting the
+	// resource by accident doesn't release the domain and break its DNS
+	// +kubebuilder:default:=false
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// MetadataMap is the metadata of the reserved domain as key value pairs, an alternative to writing the
+	// JSON of Metadata by hand. When set, it's used instead of Metadata, which must either be left at its
+	// default or have the same keys and values.
+	MetadataMap map[string]string `json:"metadataMap,omitempty"`
+
+	// CertificateManagementPolicy has ngrok automatically issue and renew the domain's TLS certificate with the
+	// policy's settings. When unset, the domain keeps the certificate ngrok gave it when it was reserved. Leave
+	// it unset for a domain served by an NgrokCertificate, since the policy replaces uploaded certificates.
+	CertificateManagementPolicy *DomainCertificateManagementPolicy `json:"certificateManagementPolicy,omitempty"`
+}
+
+// DomainCertificateManagementPolicy is how ngrok automatically issues and renews a domain's TLS certificate
+type DomainCertificateManagementPolicy struct {
+	// Authority is the certificate authority to request certificates from. The only one supported is letsencrypt.
+	// +kubebuilder:default:=letsencrypt
+	// +kubebuilder:validation:Enum=letsencrypt
+	Authority string `json:"authority,omitempty"`
+
+	// PrivateKeyType is the type of private key to use when requesting certificates
+	// +kubebuilder:default:=rsa
+	// +kubebuilder:validation:Enum=rsa;ecdsa
+	PrivateKeyType string `json:"privateKeyType,omitempty"`
+}
+
+// defaultMetadata is the default of ngrokAPICommon.Metadata
+const defaultMetadata = `{"owned-by":"kubernetes-ingress-controller"}`
+
+// ErrConflictingMetadata is returned by ResolvedMetadata when Metadata and MetadataMap disagree
+var ErrConflictingMetadata = errors.New("conflicting metadata")
+
+// ResolvedMetadata returns the metadata string to send to the ngrok API. It's MetadataMap serialized to JSON
+// when that's set, and Metadata as is otherwise. Setting both is an error wrapping ErrConflictingMetadata
+// unless Metadata is its default or is a JSON object with the same keys and values as MetadataMap.
+func (s DomainSpec) ResolvedMetadata() (string, error) {
+	if len(s.MetadataMap) == 0 {
+		return s.Metadata, nil
+	}
+
+	resolved, err := json.Marshal(s.MetadataMap)
+	if err != nil {
+		return "", err
+	}
+	if s.Metadata == "" || s.Metadata == defaultMetadata {
+		return string(resolved), nil
+	}
+
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(s.Metadata), &metadata); err != nil || !maps.Equal(metadata, s.MetadataMap) {
+		return "", fmt.Errorf("%w: metadata %s doesn't match metadataMap %s, only set one of them", ErrConflictingMetadata, s.Metadata, resolved)
+	}
+	return string(resolved), nil
+}
+
+// regions are the ngrok regions a domain can be reserved in. Keep in sync with the enum on DomainSpec.Region.
+var regions = []string{"us", "eu", "au", "ap", "sa", "jp", "in"}
+
+// ErrInvalidRegion is returned by ValidateRegion for a region ngrok doesn't support
+var ErrInvalidRegion = errors.New("invalid region")
+
+// ValidateRegion returns an error wrapping ErrInvalidRegion unless the region is one ngrok supports. An empty
+// region is valid and lets ngrok pick the default.
+func ValidateRegion(region string) error {
+	if region == "" {
+		return nil
+	}
+	for _, r := range regions {
+		if region == r {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q, must be one of: %s", ErrInvalidRegion, region, strings.Join(regions, ", "))
+}
+
+// ResolveRegion returns the region to reserve the domain of the spec in: the spec's region when it's set, and
+// the cluster wide default region otherwise. An empty default lets ngrok pick the region. It returns an error
+// wrapping ErrInvalidRegion if the region it would return isn't one ngrok supports.
+func ResolveRegion(spec DomainSpec, defaultRegion string) (string, error) {
+	region := spec.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	if err := ValidateRegion(region); err != nil {
+		return "", err
+	}
+	return region, nil
+}
+
+// ErrInvalidDomain is returned by ValidateDomain for a domain ngrok can't reserve
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ValidateDomain returns an error wrapping ErrInvalidDomain unless the domain is a plain domain or a wildcard
+// domain with exactly one leading "*." label, e.g. "*.example.com".
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("%w: domain is required", ErrInvalidDomain)
+	}
+	if strings.Contains(strings.TrimPrefix(domain, "*."), "*") {
+		return fmt.Errorf("%w %q, a wildcard is only allowed as the leading label, e.g. *.example.com", ErrInvalidDomain, domain)
+	}
+	if domain == "*." {
+		return fmt.Errorf("%w %q, a wildcard domain needs a parent domain, e.g. *.example.com", ErrInvalidDomain, domain)
+	}
+	return nil
+}
+
+// ngrokDomainSuffixes are the domains owned by ngrok. Their subdomains are served by ngrok without a CNAME record.
+var ngrokDomainSuffixes = []string{".ngrok.app", ".ngrok.dev", ".ngrok-free.app", ".ngrok-free.dev", ".ngrok.io", ".ngrok.pizza"}
+
+// isNgrokSubdomain returns true if the domain is a subdomain of a domain owned by ngrok
+func isNgrokSubdomain(domain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, suffix := range ngrokDomainSuffixes {
+		if strings.HasSuffix(domain, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWildcard returns true if the spec reserves a wildcard domain like "*.example.com", which covers all of
+// the parent domain's subdomains
+func (s DomainSpec) IsWildcard() bool {
+	return strings.HasPrefix(s.Domain, "*.")
 }
 
 // DomainStatus defines the observed state of Domain
@@ -64,6 +203,106 @@ type DomainStatus struct {
 
 	// CNAMETarget is the CNAME target for the domain
 	CNAMETarget *string `json:"cnameTarget,omitempty"`
+
+	// ACMEChallengeCNAMETarget is the CNAME target for the _acme-challenge record of a wildcard domain, which
+	// ngrok needs to issue certificates for it
+	ACMEChallengeCNAMETarget *string `json:"acmeChallengeCNAMETarget,omitempty"`
+
+	// CertificateManagementPolicy is how ngrok automatically manages the domain's TLS certificate, if it does
+	CertificateManagementPolicy *DomainStatusCertificateManagementPolicy `json:"certificateManagementPolicy,omitempty"`
+
+	// Certificate is the manually uploaded TLS certificate used for the domain, if there is one
+	Certificate *DomainStatusCertificate `json:"certificate,omitempty"`
+
+	// CertificateManagementStatus is the state of ngrok's automatic management of the domain's certificate, if
+	// it manages it
+	CertificateManagementStatus *DomainStatusCertificateManagementStatus `json:"certificateManagementStatus,omitempty"`
+
+	// ObservedGeneration is the generation of the domain's spec that was last reserved in ngrok
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the current state of the domain
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// Condition types for the status of a Domain
+const (
+	// DomainConditionReserved is True once the domain is reserved in ngrok
+	DomainConditionReserved = "DomainReserved"
+	// DomainConditionCNAMEVerified is True once the domain doesn't need a CNAME record or its CNAME record is verified
+	DomainConditionCNAMEVerified = "CNAMEVerified"
+	// DomainConditionCertificateReady is True once the domain's certificate isn't being provisioned or renewed,
+	// and False when ngrok is failing to provision it
+	DomainConditionCertificateReady = "CertificateReady"
+)
+
+// Reasons for the conditions of a Domain
+const (
+	DomainReasonReserved           = "Reserved"
+	DomainReasonReservationFailed  = "ReservationFailed"
+	DomainReasonCNAMENotRequired   = "CNAMENotRequired"
+	DomainReasonCNAMEPending       = "CNAMEPending"
+	DomainReasonCNAMETargetMissing = "CNAMETargetMissing"
+	DomainReasonCNAMEVerified      = "CNAMEVerified"
+	DomainReasonCertificateReady   = "CertificateReady"
+	DomainReasonProvisioning       = "Provisioning"
+	DomainReasonProvisioningError  = "ProvisioningError"
+)
+
+// SetCondition adds the condition to the status or updates the existing condition of the same type. The
+// condition's LastTransitionTime is only changed when its status changes, and is set to now if it's unset.
+func (s *DomainStatus) SetCondition(condition metav1.Condition) {
+	meta.SetStatusCondition(&s.Conditions, condition)
+}
+
+// GetCondition returns the condition of the type, or nil if the status doesn't have one
+func (s *DomainStatus) GetCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(s.Conditions, conditionType)
+}
+
+// DomainStatusCertificateManagementPolicy is the policy ngrok uses to automatically manage a domain's certificate
+type DomainStatusCertificateManagementPolicy struct {
+	// Authority is the certificate authority certificates are requested from
+	Authority string `json:"authority,omitempty"`
+
+	// PrivateKeyType is the type of private key used when requesting certificates
+	PrivateKeyType string `json:"privateKeyType,omitempty"`
+}
+
+// DomainStatusCertificateManagementStatus is the state of ngrok's automatic management of a domain's certificate
+type DomainStatusCertificateManagementStatus struct {
+	// RenewsAt is when the certificate will next be renewed, in RFC 3339 format
+	RenewsAt *string `json:"renewsAt,omitempty"`
+
+	// ProvisioningJob is the job provisioning or renewing the certificate, if there is one
+	ProvisioningJob *DomainStatusProvisioningJob `json:"provisioningJob,omitempty"`
+}
+
+// DomainStatusProvisioningJob is the state of the job provisioning or renewing a domain's certificate
+type DomainStatusProvisioningJob struct {
+	// ErrorCode is why provisioning is failing, if it is. It's either temporary, like INTERNAL_ERROR, or must
+	// be corrected, like DNS_ERROR.
+	ErrorCode *string `json:"errorCode,omitempty"`
+
+	// Message describes the current state of the job or its error
+	Message string `json:"message,omitempty"`
+
+	// StartedAt is when the job started, in RFC 3339 format
+	StartedAt string `json:"startedAt,omitempty"`
+
+	// RetriesAt is when the job will be retried, in RFC 3339 format
+	RetriesAt *string `json:"retriesAt,omitempty"`
+}
+
+// DomainStatusCertificate is a reference to a manually uploaded TLS certificate
+type DomainStatusCertificate struct {
+	// ID is the unique identifier of the certificate
+	ID string `json:"id,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -72,6 +311,7 @@ type DomainStatus struct {
 //+kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.status.region`,description="Region"
 //+kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.status.domain`,description="Domain"
 //+kubebuilder:printcolumn:name="CNAME Target",type=string,JSONPath=`.status.cnameTarget`,description="CNAME Target"
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="DomainReserved")].status`,description="Ready"
 //+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
 
 // Domain is the Schema for the domains API
@@ -96,22 +336,225 @@ func init() {
 	SchemeBuilder.Register(&Domain{}, &DomainList{})
 }
 
-// SetStatus pulls the fields off the ngrok domain and sets each one on the status field of the domain
+// SetStatus pulls the fields off the ngrok domain and sets each one on the status field of the domain, and records
+// the domain's generation as observed
 func (d *Domain) SetStatus(ngrokDomain *ngrok.ReservedDomain) {
+	d.Status.ObservedGeneration = d.Generation
 	d.Status.ID = ngrokDomain.ID
 	d.Status.Region = ngrokDomain.Region
 	d.Status.Domain = ngrokDomain.Domain
 	d.Status.URI = ngrokDomain.URI
 	d.Status.CNAMETarget = ngrokDomain.CNAMETarget
+	d.Status.ACMEChallengeCNAMETarget = ngrokDomain.ACMEChallengeCNAMETarget
+	d.Status.CertificateManagementPolicy = nil
+	if policy := ngrokDomain.CertificateManagementPolicy; policy != nil {
+		d.Status.CertificateManagementPolicy = &DomainStatusCertificateManagementPolicy{
+			Authority:      policy.Authority,
+			PrivateKeyType: policy.PrivateKeyType,
+		}
+	}
+	d.Status.Certificate = nil
+	if ngrokDomain.Certificate != nil {
+		d.Status.Certificate = &DomainStatusCertificate{ID: ngrokDomain.Certificate.ID}
+	}
+	d.Status.CertificateManagementStatus = nil
+	if status := ngrokDomain.CertificateManagementStatus; status != nil {
+		d.Status.CertificateManagementStatus = &DomainStatusCertificateManagementStatus{RenewsAt: status.RenewsAt}
+		if job := status.ProvisioningJob; job != nil {
+			d.Status.CertificateManagementStatus.ProvisioningJob = &DomainStatusProvisioningJob{
+				ErrorCode: job.ErrorCode,
+				Message:   job.Msg,
+				StartedAt: job.StartedAt,
+				RetriesAt: job.RetriesAt,
+			}
+		}
+	}
+	d.setCertificateCondition(ngrokDomain)
+
+	d.Status.SetCondition(metav1.Condition{
+		Type:               DomainConditionReserved,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: d.Generation,
+		Reason:             DomainReasonReserved,
+		Message:            "Domain is reserved",
+	})
+	switch {
+	case ngrokDomain.CNAMETarget == nil && isNgrokSubdomain(ngrokDomain.Domain):
+		d.Status.SetCondition(metav1.Condition{
+			Type:               DomainConditionCNAMEVerified,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: d.Generation,
+			Reason:             DomainReasonCNAMENotRequired,
+			Message:            "Domain doesn't need a CNAME record",
+		})
+	case ngrokDomain.CNAMETarget == nil:
+		// the CNAME target is assigned by ngrok and can't be worked out from the domain, so it's left unset
+		// instead of pointing users at a host that may not exist
+		d.Status.SetCondition(metav1.Condition{
+			Type:               DomainConditionCNAMEVerified,
+			Status:             metav1.ConditionUnknown,
+			ObservedGeneration: d.Generation,
+			Reason:             DomainReasonCNAMETargetMissing,
+			Message:            "ngrok didn't return a CNAME target for the domain, find the CNAME record to create in the ngrok dashboard",
+		})
+	default:
+		d.Status.SetCondition(metav1.Condition{
+			Type:               DomainConditionCNAMEVerified,
+			Status:             metav1.ConditionUnknown,
+			ObservedGeneration: d.Generation,
+			Reason:             DomainReasonCNAMEPending,
+			Message:            cnameMessage(ngrokDomain),
+		})
+	}
+}
+
+// cnameMessage describes the CNAME records to create for the ngrok domain. Wildcard domains also need an
+// _acme-challenge record on their parent domain so ngrok can issue their certificates.
+func cnameMessage(ngrokDomain *ngrok.ReservedDomain) string {
+	msg := fmt.Sprintf("Create a CNAME record for %s pointing to %s", ngrokDomain.Domain, *ngrokDomain.CNAMETarget)
+	if ngrokDomain.ACMEChallengeCNAMETarget != nil {
+		msg += fmt.Sprintf(" and a CNAME record for _acme-challenge.%s pointing to %s",
+			strings.TrimPrefix(ngrokDomain.Domain, "*."), *ngrokDomain.ACMEChallengeCNAMETarget)
+	}
+	return msg
+}
+
+// setCertificateCondition sets the domain's CertificateReady condition from the provisioning job of the ngrok
+// domain's certificate
+func (d *Domain) setCertificateCondition(ngrokDomain *ngrok.ReservedDomain) {
+	condition := metav1.Condition{
+		Type:               DomainConditionCertificateReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: d.Generation,
+		Reason:             DomainReasonCertificateReady,
+		Message:            "Certificate is ready",
+	}
+	if status := ngrokDomain.CertificateManagementStatus; status != nil && status.ProvisioningJob != nil {
+		job := status.ProvisioningJob
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = DomainReasonProvisioning
+		condition.Message = job.Msg
+		if job.ErrorCode != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = DomainReasonProvisioningError
+			condition.Message = fmt.Sprintf("%s: %s", *job.ErrorCode, job.Msg)
+		}
+		if ngrokDomain.ACMEChallengeCNAMETarget != nil {
+			condition.Message += fmt.Sprintf(". ngrok needs a CNAME record for _acme-challenge.%s pointing to %s",
+				strings.TrimPrefix(ngrokDomain.Domain, "*."), *ngrokDomain.ACMEChallengeCNAMETarget)
+		}
+	}
+	d.Status.SetCondition(condition)
+}
+
+// SetReservationFailed marks the domain as not reserved because of the error
+func (d *Domain) SetReservationFailed(err error) {
+	d.Status.SetCondition(metav1.Condition{
+		Type:               DomainConditionReserved,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: d.Generation,
+		Reason:             DomainReasonReservationFailed,
+		Message:            err.Error(),
+	})
+}
+
+// SetCNAMEVerified marks the domain's CNAME record as verified to point to its CNAME target
+func (d *Domain) SetCNAMEVerified() {
+	d.Status.SetCondition(metav1.Condition{
+		Type:               DomainConditionCNAMEVerified,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: d.Generation,
+		Reason:             DomainReasonCNAMEVerified,
+		Message:            fmt.Sprintf("CNAME record for %s points to %s", d.Status.Domain, ptr.Deref(d.Status.CNAMETarget, "")),
+	})
+}
+
+// IsCNAMEVerified returns true if the domain's CNAMEVerified condition is True
+func (d *Domain) IsCNAMEVerified() bool {
+	condition := d.Status.GetCondition(DomainConditionCNAMEVerified)
+	return condition != nil && condition.Status == metav1.ConditionTrue
+}
+
+// IsReserved returns true if the domain's DomainReserved condition is True for its current generation
+func (d *Domain) IsReserved() bool {
+	condition := d.Status.GetCondition(DomainConditionReserved)
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == d.Generation
+}
+
+// IsCertificateReady returns true if the domain's CertificateReady condition is True
+func (d *Domain) IsCertificateReady() bool {
+	condition := d.Status.GetCondition(DomainConditionCertificateReady)
+	return condition != nil && condition.Status == metav1.ConditionTrue
+}
+
+// IsUpToDate returns true if the domain's current generation was reserved, its CNAME record verified, and its
+// certificate provisioned, so there's nothing left to reconcile until its spec changes
+func (d *Domain) IsUpToDate() bool {
+	return d.Status.ObservedGeneration == d.Generation && d.IsReserved() && d.IsCNAMEVerified() && d.IsCertificateReady()
+}
+
+// HasManualCertificate returns true if the domain uses a manually uploaded certificate rather than one
+// managed by ngrok
+func (d *Domain) HasManualCertificate() bool {
+	return d.Status.Certificate != nil && d.Status.CertificateManagementPolicy == nil
 }
 
 // Equal returns true if the domain status is equal to the ngrok domain
 func (d *Domain) Equal(ngrokDomain *ngrok.ReservedDomain) bool {
-	return d.Status.ID == ngrokDomain.ID &&
+	metadata, err := d.Spec.ResolvedMetadata()
+	return err == nil &&
+		d.Status.ID == ngrokDomain.ID &&
 		d.Status.Region == ngrokDomain.Region &&
 		d.Status.Domain == ngrokDomain.Domain &&
 		d.Status.URI == ngrokDomain.URI &&
-		d.Status.CNAMETarget == ngrokDomain.CNAMETarget &&
+		ptr.Equal(d.Status.CNAMETarget, ngrokDomain.CNAMETarget) &&
+		ptr.Equal(d.Status.ACMEChallengeCNAMETarget, ngrokDomain.ACMEChallengeCNAMETarget) &&
+		certificateManagementPolicyEqual(d.Status.CertificateManagementPolicy, ngrokDomain.CertificateManagementPolicy) &&
+		certificateEqual(d.Status.Certificate, ngrokDomain.Certificate) &&
+		certificateManagementStatusEqual(d.Status.CertificateManagementStatus, ngrokDomain.CertificateManagementStatus) &&
 		d.Spec.Description == ngrokDomain.Description &&
-		d.Spec.Metadata == ngrokDomain.Metadata
+		metadata == ngrokDomain.Metadata
+}
+
+func certificateManagementPolicyEqual(policy *DomainStatusCertificateManagementPolicy, ngrokPolicy *ngrok.ReservedDomainCertPolicy) bool {
+	if policy == nil || ngrokPolicy == nil {
+		return policy == nil && ngrokPolicy == nil
+	}
+	return policy.Authority == ngrokPolicy.Authority && policy.PrivateKeyType == ngrokPolicy.PrivateKeyType
+}
+
+func certificateManagementStatusEqual(status *DomainStatusCertificateManagementStatus, ngrokStatus *ngrok.ReservedDomainCertStatus) bool {
+	if status == nil || ngrokStatus == nil {
+		return status == nil && ngrokStatus == nil
+	}
+	if !ptr.Equal(status.RenewsAt, ngrokStatus.RenewsAt) {
+		return false
+	}
+	job, ngrokJob := status.ProvisioningJob, ngrokStatus.ProvisioningJob
+	if job == nil || ngrokJob == nil {
+		return job == nil && ngrokJob == nil
+	}
+	return ptr.Equal(job.ErrorCode, ngrokJob.ErrorCode) &&
+		job.Message == ngrokJob.Msg &&
+		job.StartedAt == ngrokJob.StartedAt &&
+		ptr.Equal(job.RetriesAt, ngrokJob.RetriesAt)
+}
+
+// NgrokCertificateManagementPolicy returns the spec's certificate management policy for the ngrok API, or nil
+// if it doesn't set one
+func (s DomainSpec) NgrokCertificateManagementPolicy() *ngrok.ReservedDomainCertPolicy {
+	if s.CertificateManagementPolicy == nil {
+		return nil
+	}
+	return &ngrok.ReservedDomainCertPolicy{
+		Authority:      s.CertificateManagementPolicy.Authority,
+		PrivateKeyType: s.CertificateManagementPolicy.PrivateKeyType,
+	}
+}
+
+func certificateEqual(cert *DomainStatusCertificate, ngrokCert *ngrok.Ref) bool {
+	if cert == nil || ngrokCert == nil {
+		return cert == nil && ngrokCert == nil
+	}
+	return cert.ID == ngrokCert.ID
 }
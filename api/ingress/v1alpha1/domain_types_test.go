@@ -0,0 +1,436 @@
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDomainCertificateStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		ngrokDomain *ngrok.ReservedDomain
+		manualCert  bool
+	}{
+		{
+			name:        "ngrok domain",
+			ngrokDomain: &ngrok.ReservedDomain{ID: "rd_123"},
+			manualCert:  false,
+		},
+		{
+			name: "certificate management policy",
+			ngrokDomain: &ngrok.ReservedDomain{
+				ID:                          "rd_123",
+				CertificateManagementPolicy: &ngrok.ReservedDomainCertPolicy{Authority: "letsencrypt", PrivateKeyType: "ecdsa"},
+			},
+			manualCert: false,
+		},
+		{
+			name: "manually uploaded certificate",
+			ngrokDomain: &ngrok.ReservedDomain{
+				ID:          "rd_123",
+				Certificate: &ngrok.Ref{ID: "cert_123"},
+			},
+			manualCert: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			domain := &Domain{}
+			domain.SetStatus(c.ngrokDomain)
+
+			if got := domain.HasManualCertificate(); got != c.manualCert {
+				t.Errorf("expected HasManualCertificate to be %v, got %v", c.manualCert, got)
+			}
+			if !domain.Equal(c.ngrokDomain) {
+				t.Error("expected the domain to equal the ngrok domain its status was set from")
+			}
+
+			changed := *c.ngrokDomain
+			changed.Certificate = &ngrok.Ref{ID: "cert_456"}
+			if domain.Equal(&changed) {
+				t.Error("expected the domain not to equal an ngrok domain with a different certificate")
+			}
+		})
+	}
+}
+
+func TestDomainCertificateReadyCondition(t *testing.T) {
+	cases := []struct {
+		name        string
+		ngrokDomain *ngrok.ReservedDomain
+		status      metav1.ConditionStatus
+		reason      string
+		message     string
+	}{
+		{
+			name:        "no provisioning job",
+			ngrokDomain: &ngrok.ReservedDomain{ID: "rd_123", CertificateManagementStatus: &ngrok.ReservedDomainCertStatus{RenewsAt: ptr.To("2027-01-01T00:00:00Z")}},
+			status:      metav1.ConditionTrue,
+			reason:      DomainReasonCertificateReady,
+			message:     "Certificate is ready",
+		},
+		{
+			name: "provisioning",
+			ngrokDomain: &ngrok.ReservedDomain{
+				ID:                          "rd_123",
+				CertificateManagementStatus: &ngrok.ReservedDomainCertStatus{ProvisioningJob: &ngrok.ReservedDomainCertJob{Msg: "requesting certificate"}},
+			},
+			status:  metav1.ConditionUnknown,
+			reason:  DomainReasonProvisioning,
+			message: "requesting certificate",
+		},
+		{
+			name: "provisioning failed for a wildcard domain",
+			ngrokDomain: &ngrok.ReservedDomain{
+				ID:                       "rd_123",
+				Domain:                   "*.example.com",
+				ACMEChallengeCNAMETarget: ptr.To("abc.acme.ngrok-cname.com"),
+				CertificateManagementStatus: &ngrok.ReservedDomainCertStatus{ProvisioningJob: &ngrok.ReservedDomainCertJob{
+					ErrorCode: ptr.To("DNS_ERROR"),
+					Msg:       "challenge record not found",
+				}},
+			},
+			status:  metav1.ConditionFalse,
+			reason:  DomainReasonProvisioningError,
+			message: "DNS_ERROR: challenge record not found. ngrok needs a CNAME record for _acme-challenge.example.com pointing to abc.acme.ngrok-cname.com",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			domain := &Domain{}
+			domain.SetStatus(c.ngrokDomain)
+
+			condition := domain.Status.GetCondition(DomainConditionCertificateReady)
+			if condition == nil {
+				t.Fatal("expected the domain to have a CertificateReady condition")
+			}
+			if condition.Status != c.status || condition.Reason != c.reason || condition.Message != c.message {
+				t.Errorf("expected condition %s/%s %q, got %s/%s %q", c.status, c.reason, c.message, condition.Status, condition.Reason, condition.Message)
+			}
+			if !domain.Equal(c.ngrokDomain) {
+				t.Error("expected the domain to equal the ngrok domain its status was set from")
+			}
+		})
+	}
+}
+
+func TestValidateRegion(t *testing.T) {
+	cases := []struct {
+		region string
+		valid  bool
+	}{
+		{region: "", valid: true},
+		{region: "us", valid: true},
+		{region: "eu", valid: true},
+		{region: "au", valid: true},
+		{region: "ap", valid: true},
+		{region: "sa", valid: true},
+		{region: "jp", valid: true},
+		{region: "in", valid: true},
+		{region: "us-wesst", valid: false},
+		{region: "US", valid: false},
+		{region: " us", valid: false},
+	}
+
+	for _, c := range cases {
+		err := ValidateRegion(c.region)
+		if c.valid && err != nil {
+			t.Errorf("expected region %q to be valid, got %v", c.region, err)
+		}
+		if !c.valid && !errors.Is(err, ErrInvalidRegion) {
+			t.Errorf("expected region %q to be invalid, got %v", c.region, err)
+		}
+	}
+}
+
+func TestResolveRegion(t *testing.T) {
+	cases := []struct {
+		name          string
+		specRegion    string
+		defaultRegion string
+		expected      string
+		valid         bool
+	}{
+		{name: "spec region", specRegion: "eu", defaultRegion: "ap", expected: "eu", valid: true},
+		{name: "default region", specRegion: "", defaultRegion: "ap", expected: "ap", valid: true},
+		{name: "no region", specRegion: "", defaultRegion: "", expected: "", valid: true},
+		{name: "spec region overrides an invalid default", specRegion: "eu", defaultRegion: "mars", expected: "eu", valid: true},
+		{name: "invalid spec region", specRegion: "mars", defaultRegion: "ap", valid: false},
+		{name: "invalid default region", specRegion: "", defaultRegion: "mars", valid: false},
+		{name: "both invalid", specRegion: "mars", defaultRegion: "venus", valid: false},
+	}
+
+	for _, c := range cases {
+		region, err := ResolveRegion(DomainSpec{Domain: "example.com", Region: c.specRegion}, c.defaultRegion)
+		if c.valid {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", c.name, err)
+			}
+			if region != c.expected {
+				t.Errorf("%s: expected region %q, got %q", c.name, c.expected, region)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidRegion) {
+			t.Errorf("%s: expected an invalid region error, got %v", c.name, err)
+		}
+	}
+}
+
+func TestValidateDomain(t *testing.T) {
+	cases := []struct {
+		domain   string
+		valid    bool
+		wildcard bool
+	}{
+		{domain: "example.com", valid: true},
+		{domain: "foo.example.com", valid: true},
+		{domain: "*.example.com", valid: true, wildcard: true},
+		{domain: "*.foo.example.com", valid: true, wildcard: true},
+		{domain: "", valid: false},
+		{domain: "*.", valid: false, wildcard: true},
+		{domain: "foo.*.com", valid: false},
+		{domain: "*.*.example.com", valid: false, wildcard: true},
+		{domain: "*example.com", valid: false},
+		{domain: "foo.example.*", valid: false},
+	}
+
+	for _, c := range cases {
+		err := ValidateDomain(c.domain)
+		if c.valid && err != nil {
+			t.Errorf("expected domain %q to be valid, got %v", c.domain, err)
+		}
+		if !c.valid && !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("expected domain %q to be invalid, got %v", c.domain, err)
+		}
+		if got := (DomainSpec{Domain: c.domain}).IsWildcard(); got != c.wildcard {
+			t.Errorf("expected IsWildcard for domain %q to be %v, got %v", c.domain, c.wildcard, got)
+		}
+	}
+}
+
+func TestDomainStatusSetCondition(t *testing.T) {
+	status := DomainStatus{}
+	if status.GetCondition(DomainConditionReserved) != nil {
+		t.Fatal("expected no condition before one is set")
+	}
+
+	status.SetCondition(metav1.Condition{
+		Type:               DomainConditionReserved,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 1,
+		Reason:             DomainReasonReservationFailed,
+		Message:            "failed",
+	})
+	condition := status.GetCondition(DomainConditionReserved)
+	if condition == nil {
+		t.Fatal("expected the condition to be set")
+	}
+	if condition.LastTransitionTime.IsZero() {
+		t.Error("expected the last transition time to be set")
+	}
+	if condition.ObservedGeneration != 1 {
+		t.Errorf("expected observed generation 1, got %d", condition.ObservedGeneration)
+	}
+
+	// Setting the same status for a new generation updates the generation but isn't a transition
+	transitioned := metav1.NewTime(time.Now().Add(-time.Hour))
+	condition.LastTransitionTime = transitioned
+	status.SetCondition(metav1.Condition{
+		Type:               DomainConditionReserved,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 2,
+		Reason:             DomainReasonReservationFailed,
+		Message:            "failed again",
+	})
+	condition = status.GetCondition(DomainConditionReserved)
+	if condition.ObservedGeneration != 2 {
+		t.Errorf("expected observed generation 2, got %d", condition.ObservedGeneration)
+	}
+	if !condition.LastTransitionTime.Equal(&transitioned) {
+		t.Errorf("expected the last transition time to be unchanged, got %v", condition.LastTransitionTime)
+	}
+	if condition.Message != "failed again" {
+		t.Errorf("expected the message to be updated, got %q", condition.Message)
+	}
+
+	// Changing the status is a transition
+	status.SetCondition(metav1.Condition{
+		Type:               DomainConditionReserved,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 3,
+		Reason:             DomainReasonReserved,
+	})
+	condition = status.GetCondition(DomainConditionReserved)
+	if !condition.LastTransitionTime.After(transitioned.Time) {
+		t.Errorf("expected the last transition time to be updated, got %v", condition.LastTransitionTime)
+	}
+	if condition.ObservedGeneration != 3 {
+		t.Errorf("expected observed generation 3, got %d", condition.ObservedGeneration)
+	}
+	if len(status.Conditions) != 1 {
+		t.Errorf("expected a single condition, got %d", len(status.Conditions))
+	}
+}
+
+func TestDomainSetStatusConditions(t *testing.T) {
+	cnameTarget := "abc.ngrok-cname.com"
+	acmeChallengeCNAMETarget := "abc.acme.ngrok-cname.com"
+	cases := []struct {
+		name        string
+		ngrokDomain *ngrok.ReservedDomain
+		cname       metav1.ConditionStatus
+	}{
+		{
+			name:        "ngrok domain",
+			ngrokDomain: &ngrok.ReservedDomain{ID: "rd_123", Domain: "example.ngrok.app"},
+			cname:       metav1.ConditionTrue,
+		},
+		{
+			name:        "custom domain",
+			ngrokDomain: &ngrok.ReservedDomain{ID: "rd_123", Domain: "example.com", CNAMETarget: &cnameTarget},
+			cname:       metav1.ConditionUnknown,
+		},
+		{
+			name: "wildcard domain",
+			ngrokDomain: &ngrok.ReservedDomain{
+				ID:                       "rd_123",
+				Domain:                   "*.example.com",
+				CNAMETarget:              &cnameTarget,
+				ACMEChallengeCNAMETarget: &acmeChallengeCNAMETarget,
+			},
+			cname: metav1.ConditionUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := &Domain{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+			d.SetReservationFailed(errors.New("failed"))
+			if d.IsReserved() {
+				t.Fatal("expected the domain not to be reserved after failing")
+			}
+
+			d.SetStatus(c.ngrokDomain)
+			if !d.IsReserved() {
+				t.Error("expected the domain to be reserved")
+			}
+			if got := d.Status.GetCondition(DomainConditionCNAMEVerified).Status; got != c.cname {
+				t.Errorf("expected CNAMEVerified to be %s, got %s", c.cname, got)
+			}
+
+			d.Generation = 3
+			if d.IsReserved() {
+				t.Error("expected the reserved condition to be out of date for a new generation")
+			}
+		})
+	}
+}
+
+func TestWildcardDomainStatus(t *testing.T) {
+	cnameTarget := "abc.ngrok-cname.com"
+	acmeChallengeCNAMETarget := "abc.acme.ngrok-cname.com"
+	ngrokDomain := &ngrok.ReservedDomain{
+		ID:                       "rd_123",
+		Domain:                   "*.example.com",
+		CNAMETarget:              &cnameTarget,
+		ACMEChallengeCNAMETarget: &acmeChallengeCNAMETarget,
+	}
+
+	d := &Domain{Spec: DomainSpec{Domain: "*.example.com"}}
+	d.SetStatus(ngrokDomain)
+
+	if d.Status.Domain != "*.example.com" {
+		t.Errorf("expected the status to report the wildcard domain, got %q", d.Status.Domain)
+	}
+	if d.Status.ACMEChallengeCNAMETarget == nil || *d.Status.ACMEChallengeCNAMETarget != acmeChallengeCNAMETarget {
+		t.Errorf("expected the status to report the ACME challenge CNAME target %q, got %v", acmeChallengeCNAMETarget, d.Status.ACMEChallengeCNAMETarget)
+	}
+
+	expected := "Create a CNAME record for *.example.com pointing to abc.ngrok-cname.com and a CNAME record for " +
+		"_acme-challenge.example.com pointing to abc.acme.ngrok-cname.com"
+	if got := d.Status.GetCondition(DomainConditionCNAMEVerified).Message; got != expected {
+		t.Errorf("expected the CNAMEVerified message %q, got %q", expected, got)
+	}
+
+	// The targets are compared by value, so a fresh copy of the same ngrok domain is still equal
+	fresh := *ngrokDomain
+	fresh.CNAMETarget = ptr.To(cnameTarget)
+	fresh.ACMEChallengeCNAMETarget = ptr.To(acmeChallengeCNAMETarget)
+	if !d.Equal(&fresh) {
+		t.Error("expected the domain to equal an ngrok domain with the same CNAME targets")
+	}
+}
+
+func TestDomainSpecResolvedMetadata(t *testing.T) {
+	cases := []struct {
+		name        string
+		metadata    string
+		metadataMap map[string]string
+		expected    string
+		conflicting bool
+	}{
+		{
+			name:     "metadata only",
+			metadata: `{"team":"payments"}`,
+			expected: `{"team":"payments"}`,
+		},
+		{
+			name:        "metadata map only",
+			metadataMap: map[string]string{"team": "payments", "env": "prod"},
+			expected:    `{"env":"prod","team":"payments"}`,
+		},
+		{
+			name:        "metadata map with the default metadata",
+			metadata:    defaultMetadata,
+			metadataMap: map[string]string{"team": "payments"},
+			expected:    `{"team":"payments"}`,
+		},
+		{
+			name:        "both with the same keys and values",
+			metadata:    `{ "team": "payments", "env": "prod" }`,
+			metadataMap: map[string]string{"env": "prod", "team": "payments"},
+			expected:    `{"env":"prod","team":"payments"}`,
+		},
+		{
+			name:        "both with different values",
+			metadata:    `{"team":"search"}`,
+			metadataMap: map[string]string{"team": "payments"},
+			conflicting: true,
+		},
+		{
+			name:        "both with metadata that isn't a JSON object",
+			metadata:    "owned by payments",
+			metadataMap: map[string]string{"team": "payments"},
+			conflicting: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := DomainSpec{MetadataMap: c.metadataMap}
+			spec.Metadata = c.metadata
+
+			resolved, err := spec.ResolvedMetadata()
+			if c.conflicting {
+				if !errors.Is(err, ErrConflictingMetadata) {
+					t.Fatalf("expected an error wrapping ErrConflictingMetadata, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resolved != c.expected {
+				t.Errorf("expected metadata %s, got %s", c.expected, resolved)
+			}
+		})
+	}
+}
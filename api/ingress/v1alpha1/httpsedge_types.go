@@ -52,6 +52,11 @@ type HTTPSEdgeRouteSpec struct {
 	// +kubebuilder:validation:Required
 	Backend TunnelGroupBackend `json:"backend,omitempty"`
 
+	// WeightedBackends split the traffic of the route between tunnel group backends in proportion to their
+	// weights. When there are any, the route is served by a weighted backend of them instead of by Backend.
+	// +kubebuilder:validation:Optional
+	WeightedBackends []WeightedTunnelGroupBackend `json:"weightedBackends,omitempty"`
+
 	// CircuitBreaker is a circuit breaker configuration to apply to this route
 	CircuitBreaker *EndpointCircuitBreaker `json:"circuitBreaker,omitempty"`
 
@@ -73,6 +78,9 @@ type HTTPSEdgeRouteSpec struct {
 	// SAML is the SAML configuration to apply to this route
 	SAML *EndpointSAML `json:"saml,omitempty"`
 
+	// UserAgentFilter allows or denies requests to this route by their User-Agent header
+	UserAgentFilter *EndpointUserAgentFilter `json:"userAgentFilter,omitempty"`
+
 	// WebhookVerification is webhook verification configuration to apply to this route
 	WebhookVerification *EndpointWebhookVerification `json:"webhookVerification,omitempty"`
 
@@ -200,10 +208,21 @@ func (e *HTTPSEdge) mutualTLSEqual(edge *ngrok.HTTPSEdge) bool {
 		// one is nil and the other is not so they don't match
 		return false
 	}
-	edgeCAIDs := make([]string, len(e.Spec.MutualTLS.CertificateAuthorities))
+	edgeCAIDs := make([]string, len(edge.MutualTls.CertificateAuthorities))
 	for i, ca := range edge.MutualTls.CertificateAuthorities {
 		edgeCAIDs[i] = ca.ID
 	}
 
+	// The IDs of the certificate authorities uploaded from Secrets and ConfigMaps aren't known from the spec,
+	// so the edge only has to have the ones given by ID
+	if e.Spec.MutualTLS.HasCertificateAuthorityRefs() {
+		for _, id := range e.Spec.MutualTLS.CertificateAuthorities {
+			if !slices.Contains(edgeCAIDs, id) {
+				return false
+			}
+		}
+		return true
+	}
+
 	return slices.Equal(e.Spec.MutualTLS.CertificateAuthorities, edgeCAIDs)
 }
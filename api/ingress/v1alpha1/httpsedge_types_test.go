@@ -232,6 +232,39 @@ func TestHTTPSEdgeEqual(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "mtls uploaded certificate authorities",
+			a: &HTTPSEdge{
+				Spec: HTTPSEdgeSpec{
+					MutualTLS: &EndpointMutualTLS{
+						CertificateAuthorities:      []string{"a123"},
+						CertificateAuthoritySecrets: []string{"client-ca"},
+					},
+				},
+			},
+			b: &ngrok.HTTPSEdge{
+				MutualTls: &ngrok.EndpointMutualTLS{
+					CertificateAuthorities: []ngrok.Ref{{ID: "a123"}, {ID: "b456"}},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "mtls more remote certificate authorities",
+			a: &HTTPSEdge{
+				Spec: HTTPSEdgeSpec{
+					MutualTLS: &EndpointMutualTLS{
+						CertificateAuthorities: []string{"a123"},
+					},
+				},
+			},
+			b: &ngrok.HTTPSEdge{
+				MutualTls: &ngrok.EndpointMutualTLS{
+					CertificateAuthorities: []ngrok.Ref{{ID: "a123"}, {ID: "b456"}},
+				},
+			},
+			expected: false,
+		},
 	}
 
 	for _, c := range cases {
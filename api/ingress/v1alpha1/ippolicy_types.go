@@ -62,6 +62,7 @@ type IPPolicyStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 	ID string `json:"id,omitempty"`
 
+	// Rules are the rules of the policy in ngrok as of the last reconcile
 	Rules []IPPolicyRuleStatus `json:"rules,omitempty"`
 }
 
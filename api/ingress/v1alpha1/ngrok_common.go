@@ -1,9 +1,20 @@
 package v1alpha1
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/ngrok/ngrok-api-go/v5"
+	"golang.org/x/net/http/httpguts"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -23,6 +34,35 @@ type ngrokAPICommon struct {
 type EndpointCompression struct {
 	// Enabled is whether or not to enable compression for this endpoint
 	Enabled bool `json:"enabled,omitempty"`
+	// Algorithms are the compression algorithms to use, in order of preference, when the client accepts
+	// more than one. One of gzip, br, deflate, or compress. ngrok's defaults are used when empty.
+	Algorithms []string `json:"algorithms,omitempty"`
+}
+
+// ErrInvalidCompression is returned by EndpointCompression.Validate for unknown or repeated algorithms
+var ErrInvalidCompression = errors.New("invalid compression configuration")
+
+// CompressionAlgorithms are the algorithms accepted in EndpointCompression.Algorithms
+var CompressionAlgorithms = []string{"gzip", "br", "deflate", "compress"}
+
+// Validate returns an error wrapping ErrInvalidCompression if an algorithm isn't one of CompressionAlgorithms
+// or is listed twice
+func (compression *EndpointCompression) Validate() error {
+	if compression == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for _, algorithm := range compression.Algorithms {
+		if !slices.Contains(CompressionAlgorithms, algorithm) {
+			return fmt.Errorf("%w: unknown algorithm %q, must be one of %v", ErrInvalidCompression, algorithm, CompressionAlgorithms)
+		}
+		if seen[algorithm] {
+			return fmt.Errorf("%w: algorithm %q is listed more than once", ErrInvalidCompression, algorithm)
+		}
+		seen[algorithm] = true
+	}
+	return nil
 }
 
 type EndpointIPPolicy struct {
@@ -34,6 +74,7 @@ type EndpointIPPolicy struct {
 type EndpointRequestHeaders struct {
 	// a map of header key to header value that will be injected into the HTTP Request
 	// before being sent to the upstream application server
+	// Values may interpolate variables like ${.conn.client_ip} or ${.ngrok.geo.country_code}
 	Add map[string]string `json:"add,omitempty"`
 	// a list of header names that will be removed from the HTTP Request before being
 	// sent to the upstream application server
@@ -45,6 +86,7 @@ type EndpointRequestHeaders struct {
 type EndpointResponseHeaders struct {
 	// a map of header key to header value that will be injected into the HTTP Response
 	// returned to the HTTP client
+	// Values may interpolate variables like ${.conn.client_ip} or ${.ngrok.geo.country_code}
 	Add map[string]string `json:"add,omitempty"`
 	// a list of header names that will be removed from the HTTP Response returned to
 	// the HTTP client
@@ -58,10 +100,155 @@ type EndpointHeaders struct {
 	Response *EndpointResponseHeaders `json:"response,omitempty"`
 }
 
+// ErrInvalidHeaders is returned by EndpointHeaders.Validate for header names that aren't valid HTTP tokens and
+// values with unknown variables
+var ErrInvalidHeaders = errors.New("invalid headers configuration")
+
+// HeaderVariables are the variables ngrok interpolates into the values of added headers, written as
+// ${.conn.client_ip} for example
+var HeaderVariables = []string{
+	".basic_auth.username",
+	".conn.client_ip",
+	".conn.client_port",
+	".conn.server_ip",
+	".conn.server_port",
+	".conn.tls.cipher_suite",
+	".conn.tls.version",
+	".ngrok.geo.city",
+	".ngrok.geo.country",
+	".ngrok.geo.country_code",
+	".ngrok.geo.latitude",
+	".ngrok.geo.longitude",
+	".ngrok.geo.postal_code",
+	".ngrok.geo.subdivision",
+	".oauth.user.email",
+	".oauth.user.id",
+	".oauth.user.name",
+	".oidc.user.email",
+	".oidc.user.id",
+	".oidc.user.name",
+}
+
+// HeaderValueVariables returns the names of the variables interpolated into a header value, in the order they
+// appear, or an error if a variable isn't terminated
+func HeaderValueVariables(value string) ([]string, error) {
+	var names []string
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			return names, nil
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated variable in %q", value)
+		}
+		names = append(names, strings.TrimSpace(value[start+2:start+end]))
+		value = value[start+end+1:]
+	}
+}
+
+// Validate returns an error wrapping ErrInvalidHeaders if any header added to or removed from the request or
+// response has an empty or malformed name, or if an added value interpolates a variable that isn't one of
+// HeaderVariables
+func (headers *EndpointHeaders) Validate() error {
+	if headers == nil {
+		return nil
+	}
+
+	var names []string
+	added := map[string]string{}
+	if headers.Request != nil {
+		for name, value := range headers.Request.Add {
+			names = append(names, name)
+			added["request header "+name] = value
+		}
+		names = append(names, headers.Request.Remove...)
+	}
+	if headers.Response != nil {
+		for name, value := range headers.Response.Add {
+			names = append(names, name)
+			added["response header "+name] = value
+		}
+		names = append(names, headers.Response.Remove...)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("%w: header names must not be empty", ErrInvalidHeaders)
+		}
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("%w: %q is not a valid header name", ErrInvalidHeaders, name)
+		}
+	}
+
+	headerKeys := make([]string, 0, len(added))
+	for key := range added {
+		headerKeys = append(headerKeys, key)
+	}
+	sort.Strings(headerKeys)
+	for _, key := range headerKeys {
+		variables, err := HeaderValueVariables(added[key])
+		if err != nil {
+			return fmt.Errorf("%w: %s has an %v", ErrInvalidHeaders, key, err)
+		}
+		for _, variable := range variables {
+			if !slices.Contains(HeaderVariables, variable) {
+				return fmt.Errorf("%w: %s uses unknown variable %q, must be one of %v", ErrInvalidHeaders, key, variable, HeaderVariables)
+			}
+		}
+	}
+	return nil
+}
+
 type EndpointMutualTLS struct {
 	// List of CA IDs that will be used to validate incoming connections to the
 	// edge.
 	CertificateAuthorities []string `json:"certificateAuthorities,omitempty"`
+	// CertificateAuthoritySecrets are the names of Secrets, in the same namespace, with the PEM encoded CA
+	// certificate under the ca.crt key that will be used to validate incoming connections to the edge
+	CertificateAuthoritySecrets []string `json:"certificateAuthoritySecrets,omitempty"`
+	// CertificateAuthorityConfigMaps are the names of ConfigMaps, in the same namespace, with the PEM encoded
+	// CA certificate under the ca.crt key that will be used to validate incoming connections to the edge
+	CertificateAuthorityConfigMaps []string `json:"certificateAuthorityConfigMaps,omitempty"`
+}
+
+// MutualTLSCACertKey is the key of the CA certificate in the Secrets of
+// EndpointMutualTLS.CertificateAuthoritySecrets and the ConfigMaps of
+// EndpointMutualTLS.CertificateAuthorityConfigMaps
+const MutualTLSCACertKey = "ca.crt"
+
+// ErrInvalidMutualTLS is returned by EndpointMutualTLS.Validate when no certificate authority is given, and
+// for Secrets and ConfigMaps of the module missing a CA certificate
+var ErrInvalidMutualTLS = errors.New("invalid mutual TLS configuration")
+
+// Validate returns an error wrapping ErrInvalidMutualTLS unless there's at least one certificate authority,
+// either by ID, Secret or ConfigMap, and every Secret and ConfigMap reference has a name
+func (mtls *EndpointMutualTLS) Validate() error {
+	if mtls == nil {
+		return nil
+	}
+
+	if len(mtls.CertificateAuthorities) == 0 && !mtls.HasCertificateAuthorityRefs() {
+		return fmt.Errorf("%w: at least one of certificateAuthorities, certificateAuthoritySecrets or certificateAuthorityConfigMaps is required", ErrInvalidMutualTLS)
+	}
+	for i, name := range mtls.CertificateAuthoritySecrets {
+		if name == "" {
+			return fmt.Errorf("%w: certificateAuthoritySecrets[%d] is missing a name", ErrInvalidMutualTLS, i)
+		}
+	}
+	for i, name := range mtls.CertificateAuthorityConfigMaps {
+		if name == "" {
+			return fmt.Errorf("%w: certificateAuthorityConfigMaps[%d] is missing a name", ErrInvalidMutualTLS, i)
+		}
+	}
+	return nil
+}
+
+// HasCertificateAuthorityRefs returns true if any of the module's certificate authorities are loaded from
+// Secrets or ConfigMaps, rather than given by ID
+func (mtls *EndpointMutualTLS) HasCertificateAuthorityRefs() bool {
+	return mtls != nil && (len(mtls.CertificateAuthoritySecrets) > 0 || len(mtls.CertificateAuthorityConfigMaps) > 0)
 }
 
 type EndpointTLSTermination struct {
@@ -96,6 +283,49 @@ type EndpointWebhookVerification struct {
 	SecretRef *SecretKeyRef `json:"secret,omitempty"`
 }
 
+type EndpointUserAgentFilter struct {
+	// Allow is a list of regular expressions for the User-Agent header of requests that are allowed. When
+	// set, requests whose User-Agent matches none of them are rejected.
+	Allow []string `json:"allow,omitempty"`
+	// Deny is a list of regular expressions for the User-Agent header of requests that are rejected. A
+	// request matching both lists is allowed.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// ErrInvalidUserAgentFilter is returned by EndpointUserAgentFilter.Validate for an empty filter or a pattern
+// that isn't a valid regular expression
+var ErrInvalidUserAgentFilter = errors.New("invalid user agent filter configuration")
+
+// Validate returns an error wrapping ErrInvalidUserAgentFilter unless there's at least one allow or deny
+// pattern and every pattern is a valid regular expression
+func (filter *EndpointUserAgentFilter) Validate() error {
+	if filter == nil {
+		return nil
+	}
+
+	if len(filter.Allow) == 0 && len(filter.Deny) == 0 {
+		return fmt.Errorf("%w: at least one allow or deny pattern is required", ErrInvalidUserAgentFilter)
+	}
+	lists := []struct {
+		name     string
+		patterns []string
+	}{
+		{"allow", filter.Allow},
+		{"deny", filter.Deny},
+	}
+	for _, list := range lists {
+		for i, pattern := range list.patterns {
+			if pattern == "" {
+				return fmt.Errorf("%w: %s[%d] is empty", ErrInvalidUserAgentFilter, list.name, i)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%w: %s[%d] %q is not a valid regular expression: %v", ErrInvalidUserAgentFilter, list.name, i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
 type EndpointCircuitBreaker struct {
 	// Duration after which the circuit is tripped to wait before re-evaluating upstream health
 	//+kubebuilder:validation:Format=duration
@@ -118,6 +348,39 @@ type EndpointCircuitBreaker struct {
 	ErrorThresholdPercentage resource.Quantity `json:"errorThresholdPercentage,omitempty"`
 }
 
+// ErrInvalidCircuitBreaker is returned by EndpointCircuitBreaker.Validate for settings outside of the ranges
+// ngrok accepts
+var ErrInvalidCircuitBreaker = errors.New("invalid circuit breaker configuration")
+
+// Validate returns an error wrapping ErrInvalidCircuitBreaker if the error threshold percentage isn't between 0
+// and 1.0, if a duration is negative or shorter than the one second ngrok measures durations in, or if there
+// are more than 128 buckets. Unset fields use ngrok's defaults.
+func (cb *EndpointCircuitBreaker) Validate() error {
+	if cb == nil {
+		return nil
+	}
+
+	if threshold := cb.ErrorThresholdPercentage.AsApproximateFloat64(); threshold < 0 || threshold > 1 {
+		return fmt.Errorf("%w: errorThresholdPercentage %s must be between 0 and 1.0", ErrInvalidCircuitBreaker, cb.ErrorThresholdPercentage.String())
+	}
+	durations := []struct {
+		name     string
+		duration v1.Duration
+	}{
+		{"trippedDuration", cb.TrippedDuration},
+		{"rollingWindow", cb.RollingWindow},
+	}
+	for _, d := range durations {
+		if d.duration.Duration < 0 || (d.duration.Duration > 0 && d.duration.Duration < time.Second) {
+			return fmt.Errorf("%w: %s %s must be at least 1s", ErrInvalidCircuitBreaker, d.name, d.duration.Duration)
+		}
+	}
+	if cb.NumBuckets > 128 {
+		return fmt.Errorf("%w: numBuckets %d must be at most 128", ErrInvalidCircuitBreaker, cb.NumBuckets)
+	}
+	return nil
+}
+
 type EndpointOIDC struct {
 	// Do not enforce authentication on HTTP OPTIONS requests. necessary if you are
 	// supporting CORS.
@@ -144,6 +407,29 @@ type EndpointOIDC struct {
 	Scopes []string `json:"scopes,omitempty"`
 }
 
+// ErrInvalidOIDC is returned by EndpointOIDC.Validate for OIDC configuration ngrok can't use
+var ErrInvalidOIDC = errors.New("invalid OIDC configuration")
+
+// Validate returns an error wrapping ErrInvalidOIDC unless the issuer is an https URL and both the client ID
+// and a reference to the client secret are given
+func (oidc *EndpointOIDC) Validate() error {
+	if oidc == nil {
+		return nil
+	}
+
+	issuer, err := url.Parse(oidc.Issuer)
+	if err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+		return fmt.Errorf("%w: issuer %q must be an https URL", ErrInvalidOIDC, oidc.Issuer)
+	}
+	if oidc.ClientID == "" {
+		return fmt.Errorf("%w: clientId is required", ErrInvalidOIDC)
+	}
+	if oidc.ClientSecret.Name == "" || oidc.ClientSecret.Key == "" {
+		return fmt.Errorf("%w: clientSecret requires both a name and a key", ErrInvalidOIDC)
+	}
+	return nil
+}
+
 type EndpointSAML struct {
 	// Do not enforce authentication on HTTP OPTIONS requests. necessary if you are
 	// supporting CORS.
@@ -182,6 +468,36 @@ type EndpointSAML struct {
 	NameIDFormat string `json:"nameidFormat,omitempty"`
 }
 
+// ErrInvalidSAML is returned by EndpointSAML.Validate for SAML configuration ngrok can't use
+var ErrInvalidSAML = errors.New("invalid SAML configuration")
+
+// Validate returns an error wrapping ErrInvalidSAML unless the IdP metadata is an XML EntityDescriptor and
+// none of the authorized groups are empty
+func (saml *EndpointSAML) Validate() error {
+	if saml == nil {
+		return nil
+	}
+
+	if saml.IdPMetadata == "" {
+		return fmt.Errorf("%w: idpMetadata is required", ErrInvalidSAML)
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal([]byte(saml.IdPMetadata), &root); err != nil {
+		return fmt.Errorf("%w: idpMetadata is not valid XML: %v", ErrInvalidSAML, err)
+	}
+	if root.XMLName.Local != "EntityDescriptor" {
+		return fmt.Errorf("%w: idpMetadata must be an EntityDescriptor, not %s", ErrInvalidSAML, root.XMLName.Local)
+	}
+	for i, group := range saml.AuthorizedGroups {
+		if group == "" {
+			return fmt.Errorf("%w: authorizedGroups[%d] is empty", ErrInvalidSAML, i)
+		}
+	}
+	return nil
+}
+
 type OAuthProviderCommon struct {
 	// Do not enforce authentication on HTTP OPTIONS requests. necessary if you are
 	// supporting CORS.
@@ -261,6 +577,67 @@ type EndpointOAuth struct {
 	Amazon *EndpointOAuthAmazon `json:"amazon,omitempty"`
 }
 
+// ErrInvalidOAuth is returned by EndpointOAuth.Validate for OAuth configuration ngrok can't use
+var ErrInvalidOAuth = errors.New("invalid OAuth configuration")
+
+// Validate returns an error wrapping ErrInvalidOAuth unless exactly one provider is configured. A provider
+// with a custom OAuth app needs both its clientId and clientSecret, while one without either uses ngrok's
+// managed OAuth app. GitHub teams must be qualified with their org.
+func (oauth *EndpointOAuth) Validate() error {
+	if oauth == nil {
+		return nil
+	}
+
+	type provider struct {
+		name   string
+		common *OAuthProviderCommon
+	}
+	var providers []provider
+	if oauth.Github != nil {
+		providers = append(providers, provider{"github", &oauth.Github.OAuthProviderCommon})
+	}
+	if oauth.Facebook != nil {
+		providers = append(providers, provider{"facebook", &oauth.Facebook.OAuthProviderCommon})
+	}
+	if oauth.Microsoft != nil {
+		providers = append(providers, provider{"microsoft", &oauth.Microsoft.OAuthProviderCommon})
+	}
+	if oauth.Google != nil {
+		providers = append(providers, provider{"google", &oauth.Google.OAuthProviderCommon})
+	}
+	if oauth.Linkedin != nil {
+		providers = append(providers, provider{"linkedin", &oauth.Linkedin.OAuthProviderCommon})
+	}
+	if oauth.Gitlab != nil {
+		providers = append(providers, provider{"gitlab", &oauth.Gitlab.OAuthProviderCommon})
+	}
+	if oauth.Twitch != nil {
+		providers = append(providers, provider{"twitch", &oauth.Twitch.OAuthProviderCommon})
+	}
+	if oauth.Amazon != nil {
+		providers = append(providers, provider{"amazon", &oauth.Amazon.OAuthProviderCommon})
+	}
+
+	if len(providers) != 1 {
+		return fmt.Errorf("%w: exactly one provider must be configured, found %d", ErrInvalidOAuth, len(providers))
+	}
+
+	p := providers[0]
+	hasClientID := p.common.ClientID != nil && *p.common.ClientID != ""
+	hasClientSecret := p.common.ClientSecret != nil && p.common.ClientSecret.Name != "" && p.common.ClientSecret.Key != ""
+	if hasClientID != hasClientSecret {
+		return fmt.Errorf("%w: %s requires both clientId and clientSecret to use a custom OAuth app", ErrInvalidOAuth, p.name)
+	}
+	if oauth.Github != nil {
+		for _, team := range oauth.Github.Teams {
+			if org, name, ok := strings.Cut(team, "/"); !ok || org == "" || name == "" {
+				return fmt.Errorf("%w: github team %q must be qualified with its org, like org-name/team-name", ErrInvalidOAuth, team)
+			}
+		}
+	}
+	return nil
+}
+
 type EndpointOAuthGitHub struct {
 	OAuthProviderCommon `json:",inline"`
 	// a list of github teams identifiers. users will be allowed access to the endpoint
@@ -463,6 +840,58 @@ func (amazon *EndpointOAuthAmazon) ToNgrok(clientSecret *string) *ngrok.Endpoint
 	return mod
 }
 
+// EndpointTracing is the configuration for propagating distributed tracing headers. Trace context
+// headers already present on a request are forwarded upstream untouched, and any that are absent
+// are generated at the edge from the trace and span ID variables.
+type EndpointTracing struct {
+	// Formats are the trace context header formats to ensure are present on requests sent upstream.
+	// "w3c" for the W3C traceparent header and "b3" for the single b3 header. Defaults to w3c.
+	// +kubebuilder:validation:items:Enum=w3c;b3
+	Formats []string `json:"formats,omitempty"`
+	// TraceIDVariable is the traffic policy interpolation, e.g. ${...}, whose value is used as the trace ID of
+	// generated headers. ngrok's documented policy variables don't include a trace ID, so there's no default:
+	// without it, and SpanIDVariable, headers aren't generated and requests are forwarded as they are.
+	TraceIDVariable string `json:"traceIDVariable,omitempty"`
+	// SpanIDVariable is the traffic policy interpolation whose value is used as the span ID of generated
+	// headers. It's required along with TraceIDVariable.
+	SpanIDVariable string `json:"spanIDVariable,omitempty"`
+}
+
+// EndpointFallback is the configuration for a fallback backend that responds in place of the upstream
+// when it responds with a 5xx status code.
+type EndpointFallback struct {
+	// Static is a static backend whose response replaces the upstream's 5xx response
+	// +kubebuilder:validation:Required
+	Static *EndpointStaticBackend `json:"static"`
+}
+
+// EndpointStaticBackend is a backend that always responds with the same static response, such as a
+// maintenance page.
+type EndpointStaticBackend struct {
+	// StatusCode is the status code of the response. Defaults to 503.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode,omitempty"`
+	// Content is the body of the response
+	Content string `json:"content,omitempty"`
+	// Headers are added to the response
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// EndpointRateLimit is the configuration for limiting the rate of requests to the endpoint. Requests over the
+// limit are rejected with a 429 status code.
+type EndpointRateLimit struct {
+	// RequestsPerSecond is the number of requests allowed each second
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+	// Burst is the number of requests allowed in any one second on top of RequestsPerSecond
+	// +kubebuilder:validation:Minimum=0
+	Burst int32 `json:"burst,omitempty"`
+	// PerClientIP limits the requests of each client IP separately instead of all requests together
+	PerClientIP bool `json:"perClientIP,omitempty"`
+}
+
 type EndpointPolicy struct {
 	// Determines if the rule will be applied to traffic
 	Enabled *bool `json:"enabled,omitempty"`
@@ -489,6 +918,43 @@ type EndpointAction struct {
 	Config json.RawMessage `json:"config,omitempty"`
 }
 
+// ErrInvalidTrafficPolicy is returned by ValidateTrafficPolicy for a policy document ngrok can't apply
+var ErrInvalidTrafficPolicy = errors.New("invalid traffic policy")
+
+// ValidateTrafficPolicy returns an error wrapping ErrInvalidTrafficPolicy unless the raw policy is a JSON object
+// with only enabled, inbound, and outbound fields, and every rule has at least one action and every action a
+// type. An empty policy is valid.
+func ValidateTrafficPolicy(raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var policy EndpointPolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTrafficPolicy, err)
+	}
+
+	directions := []struct {
+		name  string
+		rules []EndpointRule
+	}{{"inbound", policy.Inbound}, {"outbound", policy.Outbound}}
+	for _, direction := range directions {
+		for i, rule := range direction.rules {
+			if len(rule.Actions) == 0 {
+				return fmt.Errorf("%w: %s rule %d (%q) has no actions", ErrInvalidTrafficPolicy, direction.name, i, rule.Name)
+			}
+			for j, action := range rule.Actions {
+				if action.Type == "" {
+					return fmt.Errorf("%w: action %d of %s rule %d (%q) has no type", ErrInvalidTrafficPolicy, j, direction.name, i, rule.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (policy *EndpointPolicy) ToNgrok() *ngrok.EndpointPolicy {
 	if policy == nil {
 		return nil
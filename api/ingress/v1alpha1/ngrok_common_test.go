@@ -43,3 +43,40 @@ func TestOAuthCommonProvided(t *testing.T) {
 	assert.False(t, oauth.Twitch.Provided())
 	assert.False(t, oauth.Github.Provided())
 }
+
+func TestHeaderValueVariables(t *testing.T) {
+	variables, err := HeaderValueVariables("${.conn.client_ip} in ${.ngrok.geo.country_code}")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".conn.client_ip", ".ngrok.geo.country_code"}, variables)
+
+	variables, err = HeaderValueVariables("plain $value {}")
+	assert.NoError(t, err)
+	assert.Empty(t, variables)
+}
+
+func TestValidateTrafficPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{name: "empty", policy: ""},
+		{name: "valid", policy: `{"inbound":[{"name":"deny","expressions":["req.Method == 'PUT'"],"actions":[{"type":"deny"}]}],"outbound":[]}`},
+		{name: "unknown field", policy: `{"inbound":[],"sideways":[]}`, wantErr: `unknown field "sideways"`},
+		{name: "not an object", policy: `["deny"]`, wantErr: "cannot unmarshal array"},
+		{name: "rule without actions", policy: `{"outbound":[{"name":"headers"}]}`, wantErr: `outbound rule 0 ("headers") has no actions`},
+		{name: "action without type", policy: `{"inbound":[{"name":"deny","actions":[{"config":{}}]}]}`, wantErr: `action 0 of inbound rule 0 ("deny") has no type`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTrafficPolicy([]byte(test.policy))
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidTrafficPolicy)
+			assert.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
@@ -25,6 +25,12 @@ SOFTWARE.
 package v1alpha1
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +39,8 @@ type NgrokModuleSetModules struct {
 	CircuitBreaker *EndpointCircuitBreaker `json:"circuitBreaker,omitempty"`
 	// Compression configuration for this module set
 	Compression *EndpointCompression `json:"compression,omitempty"`
+	// Fallback configuration for this module set
+	Fallback *EndpointFallback `json:"fallback,omitempty"`
 	// Header configuration for this module set
 	Headers *EndpointHeaders `json:"headers,omitempty"`
 	// IPRestriction configuration for this module set
@@ -41,6 +49,8 @@ type NgrokModuleSetModules struct {
 	OAuth *EndpointOAuth `json:"oauth,omitempty"`
 	// Policy configuration for this module set
 	Policy *EndpointPolicy `json:"policy,omitempty"`
+	// RateLimit configuration for this module set
+	RateLimit *EndpointRateLimit `json:"rateLimit,omitempty"`
 	// OIDC configuration for this module set
 	OIDC *EndpointOIDC `json:"oidc,omitempty"`
 	// SAML configuration for this module set
@@ -49,6 +59,10 @@ type NgrokModuleSetModules struct {
 	TLSTermination *EndpointTLSTermination `json:"tlsTermination,omitempty"`
 	// MutualTLS configuration for this module set
 	MutualTLS *EndpointMutualTLS `json:"mutualTLS,omitempty"`
+	// Tracing configuration for this module set
+	Tracing *EndpointTracing `json:"tracing,omitempty"`
+	// UserAgentFilter configuration for this module set
+	UserAgentFilter *EndpointUserAgentFilter `json:"userAgentFilter,omitempty"`
 	// WebhookVerification configuration for this module set
 	WebhookVerification *EndpointWebhookVerification `json:"webhookVerification,omitempty"`
 }
@@ -64,6 +78,27 @@ type NgrokModuleSet struct {
 	Modules NgrokModuleSetModules `json:"modules,omitempty"`
 }
 
+// Validate returns an error if any of the module set's modules is configured in a way ngrok can't use. The error
+// wraps the module's error, e.g. ErrInvalidOAuth, and names the module set.
+func (ms *NgrokModuleSet) Validate() error {
+	modules := []interface{ Validate() error }{
+		ms.Modules.OAuth,
+		ms.Modules.Headers,
+		ms.Modules.CircuitBreaker,
+		ms.Modules.Compression,
+		ms.Modules.MutualTLS,
+		ms.Modules.OIDC,
+		ms.Modules.SAML,
+		ms.Modules.UserAgentFilter,
+	}
+	for _, module := range modules {
+		if err := module.Validate(); err != nil {
+			return fmt.Errorf("NgrokModuleSet %v: %w", ms.Name, err)
+		}
+	}
+	return nil
+}
+
 func (ms *NgrokModuleSet) Merge(o *NgrokModuleSet) {
 	if o == nil {
 		return
@@ -78,6 +113,9 @@ func (ms *NgrokModuleSet) Merge(o *NgrokModuleSet) {
 	if omod.Compression != nil {
 		msmod.Compression = omod.Compression
 	}
+	if omod.Fallback != nil {
+		msmod.Fallback = omod.Fallback
+	}
 	if omod.Headers != nil {
 		msmod.Headers = omod.Headers
 	}
@@ -90,6 +128,9 @@ func (ms *NgrokModuleSet) Merge(o *NgrokModuleSet) {
 	if omod.Policy != nil {
 		msmod.Policy = omod.Policy
 	}
+	if omod.RateLimit != nil {
+		msmod.RateLimit = omod.RateLimit
+	}
 	if omod.OIDC != nil {
 		msmod.OIDC = omod.OIDC
 	}
@@ -102,11 +143,140 @@ func (ms *NgrokModuleSet) Merge(o *NgrokModuleSet) {
 	if omod.MutualTLS != nil {
 		msmod.MutualTLS = omod.MutualTLS
 	}
+	if omod.Tracing != nil {
+		msmod.Tracing = omod.Tracing
+	}
+	if omod.UserAgentFilter != nil {
+		msmod.UserAgentFilter = omod.UserAgentFilter
+	}
 	if omod.WebhookVerification != nil {
 		msmod.WebhookVerification = omod.WebhookVerification
 	}
 }
 
+// ErrModuleSetConflict is returned by MergeModuleSets when two module sets set a module field to different values
+var ErrModuleSetConflict = errors.New("conflicting module set values")
+
+// ModuleSetMergeStrategy is how MergeModuleSetsWithStrategy handles module sets setting the same field to
+// different values
+type ModuleSetMergeStrategy string
+
+const (
+	// ModuleSetMergeStrategyStrict returns an error wrapping ErrModuleSetConflict for the conflicting values
+	ModuleSetMergeStrategyStrict ModuleSetMergeStrategy = "strict"
+	// ModuleSetMergeStrategyOverride takes the value of the later module set, so app specific module sets can
+	// override the fields of a shared base module set listed before them
+	ModuleSetMergeStrategyOverride ModuleSetMergeStrategy = "override"
+)
+
+// ModuleSetMergeStrategies are the valid ModuleSetMergeStrategy values
+var ModuleSetMergeStrategies = []ModuleSetMergeStrategy{ModuleSetMergeStrategyStrict, ModuleSetMergeStrategyOverride}
+
+// MergeModuleSets deep merges the modules of the sets into a single module set. The sets take precedence in the
+// order they're given, so later sets win over earlier ones:
+//   - a module configured by only one of the sets is taken as is
+//   - when several sets configure the same module, their fields are merged. Lists are replaced by the last set
+//     that sets them and maps are combined.
+//   - two sets setting a scalar field, or the same map key, to different values is a conflict and returns an
+//     error wrapping ErrModuleSetConflict
+//
+// A zero value, such as false or an empty string, is the same as the field not being set, so it never
+// conflicts with or overrides another set's value.
+func MergeModuleSets(sets ...*NgrokModuleSet) (*NgrokModuleSet, error) {
+	return MergeModuleSetsWithStrategy(ModuleSetMergeStrategyStrict, sets...)
+}
+
+// MergeModuleSetsWithStrategy merges the module sets like MergeModuleSets, except that conflicting values are
+// handled according to the strategy. An empty strategy is the same as ModuleSetMergeStrategyStrict.
+func MergeModuleSetsWithStrategy(strategy ModuleSetMergeStrategy, sets ...*NgrokModuleSet) (*NgrokModuleSet, error) {
+	var override bool
+	switch strategy {
+	case "", ModuleSetMergeStrategyStrict:
+	case ModuleSetMergeStrategyOverride:
+		override = true
+	default:
+		return nil, fmt.Errorf("unknown module set merge strategy %q, must be one of %v", strategy, ModuleSetMergeStrategies)
+	}
+
+	merged := &NgrokModuleSet{}
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		modules := set.Modules.DeepCopy()
+		if err := mergeModuleValue(reflect.ValueOf(&merged.Modules).Elem(), reflect.ValueOf(modules).Elem(), "modules", override); err != nil {
+			return nil, fmt.Errorf("NgrokModuleSet %q conflicts with an earlier module set: %w", set.Name, err)
+		}
+	}
+	return merged, nil
+}
+
+// moduleSetPkgPath is the package of the module types, whose fields are merged individually. Structs from other
+// packages, like durations and quantities, are treated as scalars.
+var moduleSetPkgPath = reflect.TypeOf(NgrokModuleSet{}).PkgPath()
+
+// mergeModuleValue merges src into dst, where path is the JSON path of the value used in conflict errors. When
+// override is true, conflicting values are replaced by src instead.
+func mergeModuleValue(dst, src reflect.Value, path string, override bool) error {
+	if src.IsZero() {
+		return nil
+	}
+	if dst.IsZero() {
+		dst.Set(src)
+		return nil
+	}
+
+	switch src.Kind() {
+	case reflect.Pointer:
+		return mergeModuleValue(dst.Elem(), src.Elem(), path, override)
+	case reflect.Struct:
+		if src.Type().PkgPath() != moduleSetPkgPath {
+			break
+		}
+		for i := 0; i < src.NumField(); i++ {
+			fieldPath := path
+			if name := moduleFieldName(src.Type().Field(i)); name != "" {
+				fieldPath = path + "." + name
+			}
+			if err := mergeModuleValue(dst.Field(i), src.Field(i), fieldPath, override); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		dst.Set(src)
+		return nil
+	case reflect.Map:
+		for _, key := range src.MapKeys() {
+			existing := dst.MapIndex(key)
+			value := src.MapIndex(key)
+			if !override && existing.IsValid() && !equality.Semantic.DeepEqual(existing.Interface(), value.Interface()) {
+				return fmt.Errorf("%w: %s[%v] is set to both %v and %v", ErrModuleSetConflict, path, key.Interface(), existing.Interface(), value.Interface())
+			}
+			dst.SetMapIndex(key, value)
+		}
+		return nil
+	}
+
+	if !equality.Semantic.DeepEqual(dst.Interface(), src.Interface()) {
+		if override {
+			dst.Set(src)
+			return nil
+		}
+		return fmt.Errorf("%w: %s is set to both %v and %v", ErrModuleSetConflict, path, dst.Interface(), src.Interface())
+	}
+	return nil
+}
+
+// moduleFieldName returns the JSON name of the field, or an empty string for inlined fields
+func moduleFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" && !field.Anonymous {
+		return field.Name
+	}
+	return name
+}
+
 //+kubebuilder:object:root=true
 
 // NgrokModuleSetList contains a list of NgrokModule
@@ -0,0 +1,400 @@
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func newModuleSet(name string, modules NgrokModuleSetModules) *NgrokModuleSet {
+	return &NgrokModuleSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Modules:    modules,
+	}
+}
+
+func TestMergeModuleSetsDisjoint(t *testing.T) {
+	merged, err := MergeModuleSets(
+		newModuleSet("compression", NgrokModuleSetModules{
+			Compression: &EndpointCompression{Enabled: true},
+		}),
+		nil,
+		newModuleSet("ip-restriction", NgrokModuleSetModules{
+			IPRestriction: &EndpointIPPolicy{IPPolicies: []string{"policy1"}},
+		}),
+		newModuleSet("oauth", NgrokModuleSetModules{
+			OAuth: &EndpointOAuth{Google: &EndpointOAuthGoogle{}},
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, NgrokModuleSetModules{
+		Compression:   &EndpointCompression{Enabled: true},
+		IPRestriction: &EndpointIPPolicy{IPPolicies: []string{"policy1"}},
+		OAuth:         &EndpointOAuth{Google: &EndpointOAuthGoogle{}},
+	}, merged.Modules)
+}
+
+func TestMergeModuleSetsOverlappingIdentical(t *testing.T) {
+	modules := func() NgrokModuleSetModules {
+		return NgrokModuleSetModules{
+			Compression: &EndpointCompression{Enabled: true},
+			Headers: &EndpointHeaders{
+				Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "prod"}},
+			},
+			CircuitBreaker: &EndpointCircuitBreaker{
+				TrippedDuration: metav1.Duration{Duration: time.Minute},
+			},
+			TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.2")},
+		}
+	}
+
+	merged, err := MergeModuleSets(newModuleSet("first", modules()), newModuleSet("second", modules()))
+	require.NoError(t, err)
+	assert.Equal(t, modules(), merged.Modules)
+}
+
+func TestMergeModuleSetsMergesFields(t *testing.T) {
+	merged, err := MergeModuleSets(
+		newModuleSet("first", NgrokModuleSetModules{
+			Headers: &EndpointHeaders{
+				Request: &EndpointRequestHeaders{
+					Add:    map[string]string{"X-Env": "prod"},
+					Remove: []string{"X-First"},
+				},
+			},
+			IPRestriction: &EndpointIPPolicy{IPPolicies: []string{"policy1"}},
+		}),
+		newModuleSet("second", NgrokModuleSetModules{
+			Headers: &EndpointHeaders{
+				Request: &EndpointRequestHeaders{
+					Add:    map[string]string{"X-Team": "payments"},
+					Remove: []string{"X-Second"},
+				},
+				Response: &EndpointResponseHeaders{Add: map[string]string{"X-Served-By": "ngrok"}},
+			},
+			IPRestriction: &EndpointIPPolicy{IPPolicies: []string{"policy2"}},
+		}),
+	)
+	require.NoError(t, err)
+
+	// maps are combined and the last set wins for lists
+	assert.Equal(t, &EndpointHeaders{
+		Request: &EndpointRequestHeaders{
+			Add:    map[string]string{"X-Env": "prod", "X-Team": "payments"},
+			Remove: []string{"X-Second"},
+		},
+		Response: &EndpointResponseHeaders{Add: map[string]string{"X-Served-By": "ngrok"}},
+	}, merged.Modules.Headers)
+	assert.Equal(t, []string{"policy2"}, merged.Modules.IPRestriction.IPPolicies)
+}
+
+func TestMergeModuleSetsDoesNotModifyInputs(t *testing.T) {
+	first := newModuleSet("first", NgrokModuleSetModules{
+		Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "prod"}}},
+	})
+	second := newModuleSet("second", NgrokModuleSetModules{
+		Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Team": "payments"}}},
+	})
+
+	_, err := MergeModuleSets(first, second)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Env": "prod"}, first.Modules.Headers.Request.Add)
+}
+
+func TestMergeModuleSetsConflicts(t *testing.T) {
+	cases := []struct {
+		name    string
+		first   NgrokModuleSetModules
+		second  NgrokModuleSetModules
+		errPath string
+	}{
+		{
+			name:    "scalar field",
+			first:   NgrokModuleSetModules{OIDC: &EndpointOIDC{Issuer: "https://one.example.com"}},
+			second:  NgrokModuleSetModules{OIDC: &EndpointOIDC{Issuer: "https://two.example.com"}},
+			errPath: "modules.oidc.issuer",
+		},
+		{
+			name:    "pointer field",
+			first:   NgrokModuleSetModules{TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.2")}},
+			second:  NgrokModuleSetModules{TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.3")}},
+			errPath: "modules.tlsTermination.minVersion",
+		},
+		{
+			name:    "inlined field",
+			first:   NgrokModuleSetModules{OAuth: &EndpointOAuth{Google: &EndpointOAuthGoogle{OAuthProviderCommon: OAuthProviderCommon{ClientID: ptr.To("one")}}}},
+			second:  NgrokModuleSetModules{OAuth: &EndpointOAuth{Google: &EndpointOAuthGoogle{OAuthProviderCommon: OAuthProviderCommon{ClientID: ptr.To("two")}}}},
+			errPath: "modules.oauth.google.clientId",
+		},
+		{
+			name:    "duration",
+			first:   NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{RollingWindow: metav1.Duration{Duration: time.Minute}}},
+			second:  NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{RollingWindow: metav1.Duration{Duration: time.Hour}}},
+			errPath: "modules.circuitBreaker.rollingWindow",
+		},
+		{
+			name:    "map key",
+			first:   NgrokModuleSetModules{Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "prod"}}}},
+			second:  NgrokModuleSetModules{Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "staging"}}}},
+			errPath: "modules.headers.request.add[X-Env]",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := MergeModuleSets(newModuleSet("first", c.first), newModuleSet("second", c.second))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrModuleSetConflict))
+			assert.Contains(t, err.Error(), `NgrokModuleSet "second"`)
+			assert.Contains(t, err.Error(), c.errPath)
+		})
+	}
+}
+
+func TestMergeModuleSetsWithOverrideStrategy(t *testing.T) {
+	base := newModuleSet("base", NgrokModuleSetModules{
+		Compression:    &EndpointCompression{Enabled: true, Algorithms: []string{"gzip"}},
+		Headers:        &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "prod", "X-Team": "platform"}}},
+		TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.2")},
+	})
+	app := newModuleSet("app", NgrokModuleSetModules{
+		Compression:    &EndpointCompression{Algorithms: []string{"br"}},
+		Headers:        &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Team": "payments"}}},
+		TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.3")},
+	})
+
+	_, err := MergeModuleSetsWithStrategy(ModuleSetMergeStrategyStrict, base, app)
+	assert.True(t, errors.Is(err, ErrModuleSetConflict))
+
+	merged, err := MergeModuleSetsWithStrategy(ModuleSetMergeStrategyOverride, base, app)
+	require.NoError(t, err)
+	assert.Equal(t, NgrokModuleSetModules{
+		Compression:    &EndpointCompression{Enabled: true, Algorithms: []string{"br"}},
+		Headers:        &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Env": "prod", "X-Team": "payments"}}},
+		TLSTermination: &EndpointTLSTermination{MinVersion: ptr.To("1.3")},
+	}, merged.Modules)
+
+	// the inputs are left as they were
+	assert.Equal(t, []string{"gzip"}, base.Modules.Compression.Algorithms)
+	assert.Equal(t, "1.2", *base.Modules.TLSTermination.MinVersion)
+
+	_, err = MergeModuleSetsWithStrategy("newest", base, app)
+	assert.ErrorContains(t, err, `unknown module set merge strategy "newest"`)
+}
+
+func TestNgrokModuleSetValidate(t *testing.T) {
+	secret := &SecretKeyRef{Name: "oauth-secret", Key: "client-secret"}
+	google := func(clientID *string, clientSecret *SecretKeyRef) *EndpointOAuth {
+		return &EndpointOAuth{Google: &EndpointOAuthGoogle{
+			OAuthProviderCommon: OAuthProviderCommon{ClientID: clientID, ClientSecret: clientSecret},
+		}}
+	}
+	oidc := func(issuer, clientID, secretKey string) *EndpointOIDC {
+		return &EndpointOIDC{
+			Issuer:       issuer,
+			ClientID:     clientID,
+			ClientSecret: SecretKeyRef{Name: "oidc", Key: secretKey},
+		}
+	}
+	const metadata = `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="http://www.okta.com/abc"></md:EntityDescriptor>`
+	saml := func(idpMetadata string, groups ...string) *EndpointSAML {
+		return &EndpointSAML{IdPMetadata: idpMetadata, AuthorizedGroups: groups}
+	}
+
+	tests := []struct {
+		name    string
+		modules NgrokModuleSetModules
+		wantErr error
+		// contains is checked in addition to wantErr when set
+		contains string
+	}{
+		{name: "no modules"},
+		{name: "every module", modules: NgrokModuleSetModules{
+			OAuth: google(ptr.To("client-id"), secret),
+			Headers: &EndpointHeaders{
+				Request: &EndpointRequestHeaders{
+					Add:    map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Custom_Header": "value"},
+					Remove: []string{"Authorization"},
+				},
+				Response: &EndpointResponseHeaders{Remove: []string{"Server"}},
+			},
+			CircuitBreaker: &EndpointCircuitBreaker{
+				TrippedDuration:          metav1.Duration{Duration: 10 * time.Second},
+				RollingWindow:            metav1.Duration{Duration: time.Minute},
+				NumBuckets:               128,
+				ErrorThresholdPercentage: resource.MustParse("1"),
+			},
+			Compression:     &EndpointCompression{Enabled: true, Algorithms: CompressionAlgorithms},
+			MutualTLS:       &EndpointMutualTLS{CertificateAuthorities: []string{"ca_123"}},
+			OIDC:            oidc("https://accounts.example.com", "client-id", "client-secret"),
+			SAML:            saml(metadata, "engineering"),
+			UserAgentFilter: &EndpointUserAgentFilter{Allow: []string{"Mozilla/.*"}, Deny: []string{`(?i)curl/\d+`}},
+		}},
+
+		{name: "oauth managed app", modules: NgrokModuleSetModules{OAuth: google(nil, nil)}},
+		{name: "oauth client id without secret", modules: NgrokModuleSetModules{OAuth: google(ptr.To("client-id"), nil)}, wantErr: ErrInvalidOAuth},
+		{name: "oauth secret without client id", modules: NgrokModuleSetModules{OAuth: google(nil, secret)}, wantErr: ErrInvalidOAuth},
+		{name: "oauth secret without key", modules: NgrokModuleSetModules{OAuth: google(ptr.To("client-id"), &SecretKeyRef{Name: "oauth-secret"})}, wantErr: ErrInvalidOAuth},
+		{
+			name:     "oauth github secret without client id",
+			modules:  NgrokModuleSetModules{OAuth: &EndpointOAuth{Github: &EndpointOAuthGitHub{OAuthProviderCommon: OAuthProviderCommon{ClientSecret: secret}}}},
+			wantErr:  ErrInvalidOAuth,
+			contains: "github requires both clientId and clientSecret",
+		},
+		{name: "oauth no provider", modules: NgrokModuleSetModules{OAuth: &EndpointOAuth{}}, wantErr: ErrInvalidOAuth, contains: "exactly one provider must be configured, found 0"},
+		{
+			name:     "oauth two providers",
+			modules:  NgrokModuleSetModules{OAuth: &EndpointOAuth{Google: &EndpointOAuthGoogle{}, Microsoft: &EndpointOAuthMicrosoft{}}},
+			wantErr:  ErrInvalidOAuth,
+			contains: "exactly one provider must be configured, found 2",
+		},
+		{name: "oauth github teams", modules: NgrokModuleSetModules{OAuth: &EndpointOAuth{Github: &EndpointOAuthGitHub{Teams: []string{"ngrok/eng"}, Organizations: []string{"ngrok"}}}}},
+		{
+			name:     "oauth github unqualified team",
+			modules:  NgrokModuleSetModules{OAuth: &EndpointOAuth{Github: &EndpointOAuthGitHub{Teams: []string{"eng"}}}},
+			wantErr:  ErrInvalidOAuth,
+			contains: `github team "eng" must be qualified with its org`,
+		},
+
+		{name: "headers empty", modules: NgrokModuleSetModules{Headers: &EndpointHeaders{}}},
+		{name: "headers empty name to add", modules: NgrokModuleSetModules{Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"": "value"}}}}, wantErr: ErrInvalidHeaders},
+		{name: "headers empty name to remove", modules: NgrokModuleSetModules{Headers: &EndpointHeaders{Response: &EndpointResponseHeaders{Remove: []string{""}}}}, wantErr: ErrInvalidHeaders},
+		{
+			name:     "headers malformed name",
+			modules:  NgrokModuleSetModules{Headers: &EndpointHeaders{Response: &EndpointResponseHeaders{Add: map[string]string{"X-Served:By": "ngrok"}}}},
+			wantErr:  ErrInvalidHeaders,
+			contains: `"X-Served:By" is not a valid header name`,
+		},
+		{name: "headers known variables", modules: NgrokModuleSetModules{Headers: &EndpointHeaders{
+			Request: &EndpointRequestHeaders{Add: map[string]string{
+				"X-Country": "${.ngrok.geo.country_code}",
+				"X-Client":  "${ .conn.client_ip }:${.conn.client_port}",
+			}},
+			Response: &EndpointResponseHeaders{Add: map[string]string{"X-TLS-Version": "TLS ${.conn.tls.version}"}},
+		}}},
+		{
+			name:     "headers unknown variable",
+			modules:  NgrokModuleSetModules{Headers: &EndpointHeaders{Request: &EndpointRequestHeaders{Add: map[string]string{"X-Country": "${.geo.country}"}}}},
+			wantErr:  ErrInvalidHeaders,
+			contains: `request header X-Country uses unknown variable ".geo.country"`,
+		},
+		{
+			name:     "headers unterminated variable",
+			modules:  NgrokModuleSetModules{Headers: &EndpointHeaders{Response: &EndpointResponseHeaders{Add: map[string]string{"X-Client": "${.conn.client_ip"}}}},
+			wantErr:  ErrInvalidHeaders,
+			contains: "response header X-Client has an unterminated variable",
+		},
+
+		{name: "circuit breaker defaults", modules: NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{}}},
+		{name: "circuit breaker negative threshold", modules: NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{ErrorThresholdPercentage: resource.MustParse("-0.1")}}, wantErr: ErrInvalidCircuitBreaker},
+		{name: "circuit breaker threshold above 1", modules: NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{ErrorThresholdPercentage: resource.MustParse("1.5")}}, wantErr: ErrInvalidCircuitBreaker},
+		{name: "circuit breaker threshold as a percentage", modules: NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{ErrorThresholdPercentage: resource.MustParse("50")}}, wantErr: ErrInvalidCircuitBreaker},
+		{
+			name:     "circuit breaker negative tripped duration",
+			modules:  NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{TrippedDuration: metav1.Duration{Duration: -time.Second}}},
+			wantErr:  ErrInvalidCircuitBreaker,
+			contains: "trippedDuration -1s must be at least 1s",
+		},
+		{
+			name:     "circuit breaker sub-second rolling window",
+			modules:  NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{RollingWindow: metav1.Duration{Duration: 500 * time.Millisecond}}},
+			wantErr:  ErrInvalidCircuitBreaker,
+			contains: "rollingWindow 500ms must be at least 1s",
+		},
+		{name: "circuit breaker too many buckets", modules: NgrokModuleSetModules{CircuitBreaker: &EndpointCircuitBreaker{NumBuckets: 129}}, wantErr: ErrInvalidCircuitBreaker},
+
+		{name: "compression only enabled", modules: NgrokModuleSetModules{Compression: &EndpointCompression{Enabled: true}}},
+		{
+			name:     "compression unknown algorithm",
+			modules:  NgrokModuleSetModules{Compression: &EndpointCompression{Algorithms: []string{"gzip", "lz4"}}},
+			wantErr:  ErrInvalidCompression,
+			contains: `unknown algorithm "lz4"`,
+		},
+		{name: "compression uppercase algorithm", modules: NgrokModuleSetModules{Compression: &EndpointCompression{Algorithms: []string{"GZIP"}}}, wantErr: ErrInvalidCompression},
+		{
+			name:     "compression duplicate algorithm",
+			modules:  NgrokModuleSetModules{Compression: &EndpointCompression{Algorithms: []string{"br", "br"}}},
+			wantErr:  ErrInvalidCompression,
+			contains: `algorithm "br" is listed more than once`,
+		},
+
+		{name: "mutual tls secret", modules: NgrokModuleSetModules{MutualTLS: &EndpointMutualTLS{CertificateAuthoritySecrets: []string{"client-ca"}}}},
+		{name: "mutual tls config map", modules: NgrokModuleSetModules{MutualTLS: &EndpointMutualTLS{CertificateAuthorityConfigMaps: []string{"client-ca"}}}},
+		{name: "mutual tls no certificate authorities", modules: NgrokModuleSetModules{MutualTLS: &EndpointMutualTLS{}}, wantErr: ErrInvalidMutualTLS},
+		{
+			name:     "mutual tls unnamed secret",
+			modules:  NgrokModuleSetModules{MutualTLS: &EndpointMutualTLS{CertificateAuthoritySecrets: []string{"client-ca", ""}}},
+			wantErr:  ErrInvalidMutualTLS,
+			contains: "certificateAuthoritySecrets[1] is missing a name",
+		},
+		{
+			name:     "mutual tls unnamed config map",
+			modules:  NgrokModuleSetModules{MutualTLS: &EndpointMutualTLS{CertificateAuthorityConfigMaps: []string{""}}},
+			wantErr:  ErrInvalidMutualTLS,
+			contains: "certificateAuthorityConfigMaps[0] is missing a name",
+		},
+
+		{name: "oidc no issuer", modules: NgrokModuleSetModules{OIDC: oidc("", "client-id", "client-secret")}, wantErr: ErrInvalidOIDC},
+		{name: "oidc http issuer", modules: NgrokModuleSetModules{OIDC: oidc("http://accounts.example.com", "client-id", "client-secret")}, wantErr: ErrInvalidOIDC},
+		{name: "oidc issuer without scheme", modules: NgrokModuleSetModules{OIDC: oidc("accounts.example.com", "client-id", "client-secret")}, wantErr: ErrInvalidOIDC},
+		{name: "oidc issuer without host", modules: NgrokModuleSetModules{OIDC: oidc("https://", "client-id", "client-secret")}, wantErr: ErrInvalidOIDC},
+		{name: "oidc unparseable issuer", modules: NgrokModuleSetModules{OIDC: oidc("://bad", "client-id", "client-secret")}, wantErr: ErrInvalidOIDC},
+		{name: "oidc no client id", modules: NgrokModuleSetModules{OIDC: oidc("https://accounts.example.com", "", "client-secret")}, wantErr: ErrInvalidOIDC, contains: "clientId is required"},
+		{
+			name:     "oidc secret without key",
+			modules:  NgrokModuleSetModules{OIDC: oidc("https://accounts.example.com", "client-id", "")},
+			wantErr:  ErrInvalidOIDC,
+			contains: "clientSecret requires both a name and a key",
+		},
+
+		{name: "saml no metadata", modules: NgrokModuleSetModules{SAML: saml("", "engineering")}, wantErr: ErrInvalidSAML, contains: "idpMetadata is required"},
+		{
+			name:     "saml metadata url",
+			modules:  NgrokModuleSetModules{SAML: saml("https://example.okta.com/app/abc/sso/saml/metadata")},
+			wantErr:  ErrInvalidSAML,
+			contains: "idpMetadata is not valid XML",
+		},
+		{
+			name:     "saml wrong metadata root",
+			modules:  NgrokModuleSetModules{SAML: saml(`<EntitiesDescriptor></EntitiesDescriptor>`)},
+			wantErr:  ErrInvalidSAML,
+			contains: "idpMetadata must be an EntityDescriptor, not EntitiesDescriptor",
+		},
+		{name: "saml empty group", modules: NgrokModuleSetModules{SAML: saml(metadata, "engineering", "")}, wantErr: ErrInvalidSAML, contains: "authorizedGroups[1] is empty"},
+
+		{name: "user agent filter no patterns", modules: NgrokModuleSetModules{UserAgentFilter: &EndpointUserAgentFilter{}}, wantErr: ErrInvalidUserAgentFilter},
+		{
+			name:     "user agent filter empty pattern",
+			modules:  NgrokModuleSetModules{UserAgentFilter: &EndpointUserAgentFilter{Deny: []string{".*bot.*", ""}}},
+			wantErr:  ErrInvalidUserAgentFilter,
+			contains: "deny[1] is empty",
+		},
+		{
+			name:     "user agent filter invalid pattern",
+			modules:  NgrokModuleSetModules{UserAgentFilter: &EndpointUserAgentFilter{Allow: []string{"scraper("}}},
+			wantErr:  ErrInvalidUserAgentFilter,
+			contains: `allow[0] "scraper(" is not a valid regular expression`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := newModuleSet("modules", test.modules).Validate()
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.ErrorContains(t, err, "NgrokModuleSet modules: ")
+			if test.contains != "" {
+				assert.ErrorContains(t, err, test.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReservedAddrSpec defines the desired state of ReservedAddr
+type ReservedAddrSpec struct {
+	ngrokAPICommon `json:",inline"`
+
+	// Region is the region in which to reserve the TCP address. It can't be changed once the address is reserved.
+	// +kubebuilder:validation:Enum=us;eu;au;ap;sa;jp;in
+	Region string `json:"region,omitempty"`
+
+	// DeletionProtection keeps the reserved address in ngrok when the ReservedAddr is deleted, so deleting the
+	// resource by accident doesn't release the address clients connect to
+	// +kubebuilder:default:=false
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+}
+
+// ReservedAddrStatus defines the observed state of ReservedAddr
+type ReservedAddrStatus struct {
+	// ID is the unique identifier of the reserved address
+	ID string `json:"id,omitempty"`
+
+	// Addr is the hostname:port of the reserved address
+	Addr string `json:"addr,omitempty"`
+
+	// Region is the region in which the address was reserved
+	Region string `json:"region,omitempty"`
+
+	// URI of the reserved address API resource
+	URI string `json:"uri,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`,description="Reserved Addr ID"
+//+kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.status.region`,description="Region"
+//+kubebuilder:printcolumn:name="Addr",type=string,JSONPath=`.status.addr`,description="Addr"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// ReservedAddr is the Schema for the reservedaddrs API. It reserves a TCP address in ngrok that outlives the
+// TCPEdges serving it, so recreating an edge doesn't change the address its clients connect to.
+type ReservedAddr struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReservedAddrSpec   `json:"spec,omitempty"`
+	Status ReservedAddrStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReservedAddrList contains a list of ReservedAddr
+type ReservedAddrList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReservedAddr `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReservedAddr{}, &ReservedAddrList{})
+}
+
+// SetStatus pulls the fields off the ngrok reserved address and sets each one on the status of the ReservedAddr
+func (a *ReservedAddr) SetStatus(ngrokAddr *ngrok.ReservedAddr) {
+	a.Status.ID = ngrokAddr.ID
+	a.Status.Addr = ngrokAddr.Addr
+	a.Status.Region = ngrokAddr.Region
+	a.Status.URI = ngrokAddr.URI
+}
+
+// IsReserved returns true once the address has been reserved in ngrok
+func (a *ReservedAddr) IsReserved() bool {
+	return a.Status.ID != "" && a.Status.Addr != ""
+}
+
+// Equal returns true if the ngrok reserved address matches the spec and status of the ReservedAddr
+func (a *ReservedAddr) Equal(ngrokAddr *ngrok.ReservedAddr) bool {
+	return a.Status.ID == ngrokAddr.ID &&
+		a.Status.Addr == ngrokAddr.Addr &&
+		a.Status.Region == ngrokAddr.Region &&
+		a.Status.URI == ngrokAddr.URI &&
+		a.Spec.Description == ngrokAddr.Description &&
+		a.NgrokMetadata() == ngrokAddr.Metadata
+}
+
+// reservedAddrMetadata is the metadata of a reserved address in ngrok. It tags the address with the UID of the
+// ReservedAddr it was reserved for, alongside the metadata in the spec.
+type reservedAddrMetadata struct {
+	ReservedAddrUID types.UID `json:"reservedAddrUID"`
+	Metadata        string    `json:"metadata,omitempty"`
+}
+
+// NgrokMetadata returns the metadata to set on the reserved address in ngrok, which is the metadata in the spec
+// tagged with the UID of the ReservedAddr
+func (a *ReservedAddr) NgrokMetadata() string {
+	// marshaling a struct of strings can't fail
+	metadata, _ := json.Marshal(reservedAddrMetadata{ReservedAddrUID: a.UID, Metadata: a.Spec.Metadata})
+	return string(metadata)
+}
+
+// Owns returns true if the ngrok reserved address is tagged with the UID of the ReservedAddr, which is how an
+// address reserved by a create call that seemed to fail is found again
+func (a *ReservedAddr) Owns(ngrokAddr *ngrok.ReservedAddr) bool {
+	metadata := reservedAddrMetadata{}
+	if err := json.Unmarshal([]byte(ngrokAddr.Metadata), &metadata); err != nil {
+		return false
+	}
+	return a.UID != "" && metadata.ReservedAddrUID == a.UID
+}
@@ -42,6 +42,12 @@ type TCPEdgeSpec struct {
 	// +kubebuilder:validation:Required
 	Backend TunnelGroupBackend `json:"backend,omitempty"`
 
+	// ReservedAddr is the name of a ReservedAddr in the edge's namespace whose address this edge serves. The
+	// address stays reserved when the edge is deleted, so recreating the edge keeps the same hostport. When
+	// it's empty, the controller reserves an address for the edge.
+	// +optional
+	ReservedAddr string `json:"reservedAddr,omitempty"`
+
 	// IPRestriction is an IPRestriction to apply to this edge
 	IPRestriction *EndpointIPPolicy `json:"ipRestriction,omitempty"`
 
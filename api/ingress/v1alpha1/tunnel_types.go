@@ -43,6 +43,9 @@ type TunnelSpec struct {
 	// Labels are key/value pairs that are attached to the tunnel
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// Metadata is a string of arbitrary data associated with the tunnel
+	Metadata string `json:"metadata,omitempty"`
+
 	// The configuration for backend connections to services
 	BackendConfig *BackendConfig `json:"backend,omitempty"`
 
@@ -50,10 +53,33 @@ type TunnelSpec struct {
 	AppProtocol string `json:"appProtocol,omitempty"`
 }
 
+// BackendCACertKey is the key of the PEM encoded certificate authorities in a backend certificate authority Secret
+const BackendCACertKey = "ca.crt"
+
 // BackendConfig defines the configuration for backend connections to services.
-// This can be extended to include ServerName, InsecureSkipVerify, etc. down the road.
 type BackendConfig struct {
+	// Protocol is the protocol used to connect to the backend, either HTTP or HTTPS
 	Protocol string `json:"protocol,omitempty"`
+
+	// InsecureSkipVerify skips verification of the backend's certificate for HTTPS backends
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ServerName is the name sent with SNI and verified against the certificate of HTTPS backends, for backends
+	// whose certificate isn't issued for their service's cluster DNS name. Defaults to the host of forwardsTo.
+	ServerName string `json:"serverName,omitempty"`
+
+	// CertificateAuthoritySecret is the name of a Secret in the tunnel's namespace whose ca.crt key holds the
+	// PEM encoded certificate authorities that verify the certificate of HTTPS backends instead of the system's
+	CertificateAuthoritySecret string `json:"certificateAuthoritySecret,omitempty"`
+
+	// MaxConnections is the maximum number of concurrent connections to the backend service, shared by all
+	// tunnels forwarding to it. Unset means there is no limit.
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+
+	// KeepAliveInterval is how often keepalive probes are sent on connections to the backend service to keep
+	// them alive and detect dead ones. Defaults to 15s.
+	KeepAliveInterval *metav1.Duration `json:"keepAliveInterval,omitempty"`
 }
 
 // TunnelStatus defines the observed state of Tunnel
@@ -92,6 +118,18 @@ type TunnelGroupBackend struct {
 	Labels map[string]string `json:"labels,omitempty"`
 }
 
+// WeightedTunnelGroupBackend is a tunnel group backend that's sent a share of a route's traffic in proportion to
+// its weight
+type WeightedTunnelGroupBackend struct {
+	TunnelGroupBackend `json:",inline"`
+
+	// Weight is the share of the traffic sent to the backend, relative to the weights of the route's other
+	// backends
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10000
+	Weight int64 `json:"weight"`
+}
+
 type TunnelGroupBackendStatus struct {
 	// ID is the unique identifier for this backend
 	ID string `json:"id,omitempty"`
@@ -30,12 +30,18 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendConfig) DeepCopyInto(out *BackendConfig) {
 	*out = *in
+	if in.KeepAliveInterval != nil {
+		in, out := &in.KeepAliveInterval, &out.KeepAliveInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendConfig.
@@ -53,7 +59,7 @@ func (in *Domain) DeepCopyInto(out *Domain) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -75,6 +81,21 @@ func (in *Domain) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainCertificateManagementPolicy) DeepCopyInto(out *DomainCertificateManagementPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainCertificateManagementPolicy.
+func (in *DomainCertificateManagementPolicy) DeepCopy() *DomainCertificateManagementPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainCertificateManagementPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DomainList) DeepCopyInto(out *DomainList) {
 	*out = *in
@@ -111,6 +132,18 @@ func (in *DomainList) DeepCopyObject() runtime.Object {
 func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 	*out = *in
 	out.ngrokAPICommon = in.ngrokAPICommon
+	if in.MetadataMap != nil {
+		in, out := &in.MetadataMap, &out.MetadataMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CertificateManagementPolicy != nil {
+		in, out := &in.CertificateManagementPolicy, &out.CertificateManagementPolicy
+		*out = new(DomainCertificateManagementPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainSpec.
@@ -131,6 +164,33 @@ func (in *DomainStatus) DeepCopyInto(out *DomainStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ACMEChallengeCNAMETarget != nil {
+		in, out := &in.ACMEChallengeCNAMETarget, &out.ACMEChallengeCNAMETarget
+		*out = new(string)
+		**out = **in
+	}
+	if in.CertificateManagementPolicy != nil {
+		in, out := &in.CertificateManagementPolicy, &out.CertificateManagementPolicy
+		*out = new(DomainStatusCertificateManagementPolicy)
+		**out = **in
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(DomainStatusCertificate)
+		**out = **in
+	}
+	if in.CertificateManagementStatus != nil {
+		in, out := &in.CertificateManagementStatus, &out.CertificateManagementStatus
+		*out = new(DomainStatusCertificateManagementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatus.
@@ -143,6 +203,86 @@ func (in *DomainStatus) DeepCopy() *DomainStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainStatusCertificate) DeepCopyInto(out *DomainStatusCertificate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatusCertificate.
+func (in *DomainStatusCertificate) DeepCopy() *DomainStatusCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainStatusCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainStatusCertificateManagementPolicy) DeepCopyInto(out *DomainStatusCertificateManagementPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatusCertificateManagementPolicy.
+func (in *DomainStatusCertificateManagementPolicy) DeepCopy() *DomainStatusCertificateManagementPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainStatusCertificateManagementPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainStatusCertificateManagementStatus) DeepCopyInto(out *DomainStatusCertificateManagementStatus) {
+	*out = *in
+	if in.RenewsAt != nil {
+		in, out := &in.RenewsAt, &out.RenewsAt
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProvisioningJob != nil {
+		in, out := &in.ProvisioningJob, &out.ProvisioningJob
+		*out = new(DomainStatusProvisioningJob)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatusCertificateManagementStatus.
+func (in *DomainStatusCertificateManagementStatus) DeepCopy() *DomainStatusCertificateManagementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainStatusCertificateManagementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainStatusProvisioningJob) DeepCopyInto(out *DomainStatusProvisioningJob) {
+	*out = *in
+	if in.ErrorCode != nil {
+		in, out := &in.ErrorCode, &out.ErrorCode
+		*out = new(string)
+		**out = **in
+	}
+	if in.RetriesAt != nil {
+		in, out := &in.RetriesAt, &out.RetriesAt
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatusProvisioningJob.
+func (in *DomainStatusProvisioningJob) DeepCopy() *DomainStatusProvisioningJob {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainStatusProvisioningJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointAction) DeepCopyInto(out *EndpointAction) {
 	*out = *in
@@ -184,6 +324,11 @@ func (in *EndpointCircuitBreaker) DeepCopy() *EndpointCircuitBreaker {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointCompression) DeepCopyInto(out *EndpointCompression) {
 	*out = *in
+	if in.Algorithms != nil {
+		in, out := &in.Algorithms, &out.Algorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointCompression.
@@ -196,6 +341,26 @@ func (in *EndpointCompression) DeepCopy() *EndpointCompression {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointFallback) DeepCopyInto(out *EndpointFallback) {
+	*out = *in
+	if in.Static != nil {
+		in, out := &in.Static, &out.Static
+		*out = new(EndpointStaticBackend)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointFallback.
+func (in *EndpointFallback) DeepCopy() *EndpointFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointHeaders) DeepCopyInto(out *EndpointHeaders) {
 	*out = *in
@@ -249,6 +414,16 @@ func (in *EndpointMutualTLS) DeepCopyInto(out *EndpointMutualTLS) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.CertificateAuthoritySecrets != nil {
+		in, out := &in.CertificateAuthoritySecrets, &out.CertificateAuthoritySecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CertificateAuthorityConfigMaps != nil {
+		in, out := &in.CertificateAuthorityConfigMaps, &out.CertificateAuthorityConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointMutualTLS.
@@ -511,6 +686,21 @@ func (in *EndpointPolicy) DeepCopy() *EndpointPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointRateLimit) DeepCopyInto(out *EndpointRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointRateLimit.
+func (in *EndpointRateLimit) DeepCopy() *EndpointRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointRequestHeaders) DeepCopyInto(out *EndpointRequestHeaders) {
 	*out = *in
@@ -619,6 +809,28 @@ func (in *EndpointSAML) DeepCopy() *EndpointSAML {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointStaticBackend) DeepCopyInto(out *EndpointStaticBackend) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointStaticBackend.
+func (in *EndpointStaticBackend) DeepCopy() *EndpointStaticBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointStaticBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointTLSTermination) DeepCopyInto(out *EndpointTLSTermination) {
 	*out = *in
@@ -654,6 +866,51 @@ func (in *EndpointTLSTerminationAtEdge) DeepCopy() *EndpointTLSTerminationAtEdge
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointTracing) DeepCopyInto(out *EndpointTracing) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointTracing.
+func (in *EndpointTracing) DeepCopy() *EndpointTracing {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointTracing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointUserAgentFilter) DeepCopyInto(out *EndpointUserAgentFilter) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointUserAgentFilter.
+func (in *EndpointUserAgentFilter) DeepCopy() *EndpointUserAgentFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointUserAgentFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointWebhookVerification) DeepCopyInto(out *EndpointWebhookVerification) {
 	*out = *in
@@ -738,6 +995,13 @@ func (in *HTTPSEdgeRouteSpec) DeepCopyInto(out *HTTPSEdgeRouteSpec) {
 	*out = *in
 	out.ngrokAPICommon = in.ngrokAPICommon
 	in.Backend.DeepCopyInto(&out.Backend)
+	if in.WeightedBackends != nil {
+		in, out := &in.WeightedBackends, &out.WeightedBackends
+		*out = make([]WeightedTunnelGroupBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.CircuitBreaker != nil {
 		in, out := &in.CircuitBreaker, &out.CircuitBreaker
 		*out = new(EndpointCircuitBreaker)
@@ -746,7 +1010,7 @@ func (in *HTTPSEdgeRouteSpec) DeepCopyInto(out *HTTPSEdgeRouteSpec) {
 	if in.Compression != nil {
 		in, out := &in.Compression, &out.Compression
 		*out = new(EndpointCompression)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.IPRestriction != nil {
 		in, out := &in.IPRestriction, &out.IPRestriction
@@ -773,6 +1037,11 @@ func (in *HTTPSEdgeRouteSpec) DeepCopyInto(out *HTTPSEdgeRouteSpec) {
 		*out = new(EndpointSAML)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UserAgentFilter != nil {
+		in, out := &in.UserAgentFilter, &out.UserAgentFilter
+		*out = new(EndpointUserAgentFilter)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.WebhookVerification != nil {
 		in, out := &in.WebhookVerification, &out.WebhookVerification
 		*out = new(EndpointWebhookVerification)
@@ -1000,6 +1269,80 @@ func (in *IPPolicyStatus) DeepCopy() *IPPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokIngressClassParams) DeepCopyInto(out *NgrokIngressClassParams) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokIngressClassParams.
+func (in *NgrokIngressClassParams) DeepCopy() *NgrokIngressClassParams {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokIngressClassParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokIngressClassParams) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokIngressClassParamsList) DeepCopyInto(out *NgrokIngressClassParamsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NgrokIngressClassParams, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokIngressClassParamsList.
+func (in *NgrokIngressClassParamsList) DeepCopy() *NgrokIngressClassParamsList {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokIngressClassParamsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokIngressClassParamsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokIngressClassParamsSpec) DeepCopyInto(out *NgrokIngressClassParamsSpec) {
+	*out = *in
+	out.ngrokAPICommon = in.ngrokAPICommon
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokIngressClassParamsSpec.
+func (in *NgrokIngressClassParamsSpec) DeepCopy() *NgrokIngressClassParamsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokIngressClassParamsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NgrokModuleSet) DeepCopyInto(out *NgrokModuleSet) {
 	*out = *in
@@ -1069,7 +1412,12 @@ func (in *NgrokModuleSetModules) DeepCopyInto(out *NgrokModuleSetModules) {
 	if in.Compression != nil {
 		in, out := &in.Compression, &out.Compression
 		*out = new(EndpointCompression)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(EndpointFallback)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
@@ -1091,6 +1439,11 @@ func (in *NgrokModuleSetModules) DeepCopyInto(out *NgrokModuleSetModules) {
 		*out = new(EndpointPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(EndpointRateLimit)
+		**out = **in
+	}
 	if in.OIDC != nil {
 		in, out := &in.OIDC, &out.OIDC
 		*out = new(EndpointOIDC)
@@ -1111,6 +1464,16 @@ func (in *NgrokModuleSetModules) DeepCopyInto(out *NgrokModuleSetModules) {
 		*out = new(EndpointMutualTLS)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(EndpointTracing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserAgentFilter != nil {
+		in, out := &in.UserAgentFilter, &out.UserAgentFilter
+		*out = new(EndpointUserAgentFilter)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.WebhookVerification != nil {
 		in, out := &in.WebhookVerification, &out.WebhookVerification
 		*out = new(EndpointWebhookVerification)
@@ -1171,6 +1534,96 @@ func (in *OAuthProviderCommon) DeepCopy() *OAuthProviderCommon {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedAddr) DeepCopyInto(out *ReservedAddr) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedAddr.
+func (in *ReservedAddr) DeepCopy() *ReservedAddr {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedAddr)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReservedAddr) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedAddrList) DeepCopyInto(out *ReservedAddrList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReservedAddr, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedAddrList.
+func (in *ReservedAddrList) DeepCopy() *ReservedAddrList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedAddrList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReservedAddrList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedAddrSpec) DeepCopyInto(out *ReservedAddrSpec) {
+	*out = *in
+	out.ngrokAPICommon = in.ngrokAPICommon
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedAddrSpec.
+func (in *ReservedAddrSpec) DeepCopy() *ReservedAddrSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedAddrSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedAddrStatus) DeepCopyInto(out *ReservedAddrStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedAddrStatus.
+func (in *ReservedAddrStatus) DeepCopy() *ReservedAddrStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedAddrStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
 	*out = *in
@@ -1532,7 +1985,7 @@ func (in *TunnelSpec) DeepCopyInto(out *TunnelSpec) {
 	if in.BackendConfig != nil {
 		in, out := &in.BackendConfig, &out.BackendConfig
 		*out = new(BackendConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1560,3 +2013,19 @@ func (in *TunnelStatus) DeepCopy() *TunnelStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedTunnelGroupBackend) DeepCopyInto(out *WeightedTunnelGroupBackend) {
+	*out = *in
+	in.TunnelGroupBackend.DeepCopyInto(&out.TunnelGroupBackend)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedTunnelGroupBackend.
+func (in *WeightedTunnelGroupBackend) DeepCopy() *WeightedTunnelGroupBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedTunnelGroupBackend)
+	in.DeepCopyInto(out)
+	return out
+}
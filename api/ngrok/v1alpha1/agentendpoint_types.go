@@ -0,0 +1,96 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AgentEndpointSpec defines the desired state of AgentEndpoint
+type AgentEndpointSpec struct {
+	// URL is the URL the agent endpoint is served on. The scheme determines the kind of endpoint: https:// or
+	// http:// for an HTTP endpoint on a domain, tcp:// for a TCP endpoint on a reserved address, and tls:// for
+	// a TLS endpoint on a domain, e.g. https://example.ngrok.app or tcp://1.tcp.ngrok.io:12345
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(https?|tcp|tls)://.+`
+	URL string `json:"url"`
+
+	// Upstream is the service in the cluster the endpoint's traffic is forwarded to
+	// +kubebuilder:validation:Required
+	Upstream AgentEndpointUpstream `json:"upstream"`
+
+	// Metadata is a string of arbitrary data associated with the endpoint in the ngrok API/Dashboard
+	// +kubebuilder:default:=`{"owned-by":"kubernetes-ingress-controller"}`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// AgentEndpointUpstream is the in-cluster destination of an agent endpoint's traffic
+type AgentEndpointUpstream struct {
+	// URL is the address of the upstream service, e.g. http://my-service.default:80. An https:// URL
+	// makes the agent connect to the upstream over TLS.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(https?|tcp|tls)://.+:[0-9]+$`
+	URL string `json:"url"`
+
+	// Protocol is the application protocol the upstream speaks, http1 or http2. Only used by HTTP endpoints.
+	// +kubebuilder:validation:Enum=http1;http2
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// AgentEndpointStatus defines the observed state of AgentEndpoint
+type AgentEndpointStatus struct {
+	// URL is the URL the endpoint is being served on. Every controller replica's agent serves the endpoint,
+	// so the IDs of their tunnels aren't recorded.
+	URL string `json:"url,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`,description="URL"
+//+kubebuilder:printcolumn:name="Upstream",type=string,JSONPath=`.spec.upstream.url`,description="Upstream URL"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// AgentEndpoint is the Schema for the agentendpoints API. It manages an endpoint started directly by the
+// controller's agent, without any edges.
+type AgentEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AgentEndpointSpec   `json:"spec,omitempty"`
+	Status AgentEndpointStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AgentEndpointList contains a list of AgentEndpoint
+type AgentEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AgentEndpoint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AgentEndpoint{}, &AgentEndpointList{})
+}
@@ -0,0 +1,105 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CloudEndpointSpec defines the desired state of CloudEndpoint
+type CloudEndpointSpec struct {
+	// URL is the URL the cloud endpoint is served on, e.g. https://example.ngrok.app. Its domain or TCP address
+	// must be reserved in ngrok.
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// TrafficPolicyName is the name of an NgrokTrafficPolicy in the endpoint's namespace whose policy the
+	// endpoint applies. Only one of TrafficPolicyName and TrafficPolicy may be set.
+	TrafficPolicyName string `json:"trafficPolicyName,omitempty"`
+
+	// TrafficPolicy is the raw json encoded traffic policy the endpoint applies, rather than one from an
+	// NgrokTrafficPolicy
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	TrafficPolicy json.RawMessage `json:"trafficPolicy,omitempty"`
+
+	// Description is a human-readable description of the endpoint in the ngrok API/Dashboard
+	// +kubebuilder:default:=`Created by kubernetes-ingress-controller`
+	Description string `json:"description,omitempty"`
+
+	// Metadata is a string of arbitrary data associated with the endpoint in the ngrok API/Dashboard
+	// +kubebuilder:default:=`{"owned-by":"kubernetes-ingress-controller"}`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// CloudEndpointStatus defines the observed state of CloudEndpoint
+type CloudEndpointStatus struct {
+	// ID is the unique identifier of the cloud endpoint
+	ID string `json:"id,omitempty"`
+
+	// URI of the cloud endpoint API resource
+	URI string `json:"uri,omitempty"`
+
+	// TrafficPolicy is the raw json encoded traffic policy that was applied to the endpoint
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	TrafficPolicy json.RawMessage `json:"trafficPolicy,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`,description="Cloud Endpoint ID"
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`,description="URL"
+//+kubebuilder:printcolumn:name="Traffic Policy",type=string,JSONPath=`.spec.trafficPolicyName`,description="Traffic Policy"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// CloudEndpoint is the Schema for the cloudendpoints API. It manages an always-on endpoint hosted by ngrok,
+// which doesn't depend on an agent being connected.
+// Its traffic policy can't forward to services in the cluster yet: the controller's tunnels are labeled tunnels
+// for edges, not the internal agent endpoints a cloud endpoint forwards to.
+type CloudEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudEndpointSpec   `json:"spec,omitempty"`
+	Status CloudEndpointStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudEndpointList contains a list of CloudEndpoint
+type CloudEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudEndpoint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudEndpoint{}, &CloudEndpointList{})
+}
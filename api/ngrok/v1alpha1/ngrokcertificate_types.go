@@ -0,0 +1,119 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrInvalidCertificateSecret is returned for an NgrokCertificate whose Secret is missing its certificate or
+// private key
+var ErrInvalidCertificateSecret = errors.New("invalid certificate secret")
+
+// NgrokCertificateSpec defines the desired state of NgrokCertificate
+type NgrokCertificateSpec struct {
+	// SecretName is the name of a Secret in the certificate's namespace with the PEM encoded certificate chain,
+	// leaf first, in its tls.crt key and the private key in its tls.key key, like a kubernetes.io/tls Secret.
+	// When the Secret changes, the new certificate is uploaded and replaces the old one.
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+
+	// Domain is the reserved domain, e.g. *.example.com, that serves the certificate instead of one managed
+	// by ngrok. It must already be reserved in ngrok, for example by a Domain.
+	// +kubebuilder:validation:Required
+	Domain string `json:"domain"`
+
+	// Description is a human-readable description of the certificate in the ngrok API/Dashboard
+	// +kubebuilder:default:=`Created by kubernetes-ingress-controller`
+	Description string `json:"description,omitempty"`
+
+	// Metadata is a string of arbitrary data associated with the certificate in the ngrok API/Dashboard
+	// +kubebuilder:default:=`{"owned-by":"kubernetes-ingress-controller"}`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// NgrokCertificateStatus defines the observed state of NgrokCertificate
+type NgrokCertificateStatus struct {
+	// ID is the unique identifier of the uploaded TLS certificate
+	ID string `json:"id,omitempty"`
+
+	// URI of the TLS certificate API resource
+	URI string `json:"uri,omitempty"`
+
+	// Domain is the reserved domain the certificate is bound to
+	Domain string `json:"domain,omitempty"`
+
+	// SubjectCommonName is the subject common name of the certificate
+	SubjectCommonName string `json:"subjectCommonName,omitempty"`
+
+	// NotAfter is when the certificate expires
+	NotAfter string `json:"notAfter,omitempty"`
+
+	// SecretHash is the hash of the Secret's certificate and private key that were uploaded, used to upload
+	// them again when they change
+	SecretHash string `json:"secretHash,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`,description="Certificate ID"
+//+kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.status.domain`,description="Domain"
+//+kubebuilder:printcolumn:name="Expires",type=string,JSONPath=`.status.notAfter`,description="Expires"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// NgrokCertificate is the Schema for the ngrokcertificates API. It uploads the TLS certificate from a Secret to
+// ngrok and binds it to a reserved domain, so the domain serves that certificate instead of one issued by ngrok.
+type NgrokCertificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NgrokCertificateSpec   `json:"spec,omitempty"`
+	Status NgrokCertificateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NgrokCertificateList contains a list of NgrokCertificate
+type NgrokCertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NgrokCertificate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NgrokCertificate{}, &NgrokCertificateList{})
+}
+
+// SetStatus pulls the fields off the uploaded ngrok certificate and sets each one on the status of the
+// NgrokCertificate
+func (c *NgrokCertificate) SetStatus(ngrokCert *ngrok.TLSCertificate) {
+	c.Status.ID = ngrokCert.ID
+	c.Status.URI = ngrokCert.URI
+	c.Status.SubjectCommonName = ngrokCert.SubjectCommonName
+	c.Status.NotAfter = ngrokCert.NotAfter
+}
@@ -0,0 +1,87 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NgrokControllerStatusStatus defines the observed state of the ngrok ingress controller
+type NgrokControllerStatusStatus struct {
+	// ManagedIngresses is the number of ingresses handled by the controller
+	ManagedIngresses int `json:"managedIngresses"`
+
+	// HTTPSEdges is the number of HTTPS edges managed by the controller
+	HTTPSEdges int `json:"httpsEdges"`
+
+	// Domains is the number of reserved domains managed by the controller
+	Domains int `json:"domains"`
+
+	// Tunnels is the number of tunnels managed by the controller
+	Tunnels int `json:"tunnels"`
+
+	// LastSuccessfulAPIContact is the last time the controller successfully reached the ngrok API
+	LastSuccessfulAPIContact *metav1.Time `json:"lastSuccessfulAPIContact,omitempty"`
+
+	// LastUpdated is the last time this status was reported
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+
+	// Degraded is true when the controller could not reach the ngrok API on its last attempt
+	Degraded bool `json:"degraded"`
+
+	// Message is a human readable explanation of the degraded state
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Ingresses",type=integer,JSONPath=`.status.managedIngresses`,description="Managed Ingresses"
+//+kubebuilder:printcolumn:name="Edges",type=integer,JSONPath=`.status.httpsEdges`,description="HTTPS Edges"
+//+kubebuilder:printcolumn:name="Domains",type=integer,JSONPath=`.status.domains`,description="Domains"
+//+kubebuilder:printcolumn:name="Degraded",type=boolean,JSONPath=`.status.degraded`,description="Degraded"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// NgrokControllerStatus is a cluster scoped singleton that reports the overall state of
+// the controller. It is created and periodically updated by the controller itself.
+type NgrokControllerStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NgrokControllerStatusStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NgrokControllerStatusList contains a list of NgrokControllerStatus
+type NgrokControllerStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NgrokControllerStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NgrokControllerStatus{}, &NgrokControllerStatusList{})
+}
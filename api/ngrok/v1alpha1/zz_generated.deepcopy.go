@@ -33,6 +33,377 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentEndpoint) DeepCopyInto(out *AgentEndpoint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentEndpoint.
+func (in *AgentEndpoint) DeepCopy() *AgentEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AgentEndpoint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentEndpointList) DeepCopyInto(out *AgentEndpointList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AgentEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentEndpointList.
+func (in *AgentEndpointList) DeepCopy() *AgentEndpointList {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentEndpointList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AgentEndpointList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentEndpointSpec) DeepCopyInto(out *AgentEndpointSpec) {
+	*out = *in
+	out.Upstream = in.Upstream
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentEndpointSpec.
+func (in *AgentEndpointSpec) DeepCopy() *AgentEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentEndpointStatus) DeepCopyInto(out *AgentEndpointStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentEndpointStatus.
+func (in *AgentEndpointStatus) DeepCopy() *AgentEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentEndpointUpstream) DeepCopyInto(out *AgentEndpointUpstream) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentEndpointUpstream.
+func (in *AgentEndpointUpstream) DeepCopy() *AgentEndpointUpstream {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentEndpointUpstream)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEndpoint) DeepCopyInto(out *CloudEndpoint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEndpoint.
+func (in *CloudEndpoint) DeepCopy() *CloudEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudEndpoint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEndpointList) DeepCopyInto(out *CloudEndpointList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEndpointList.
+func (in *CloudEndpointList) DeepCopy() *CloudEndpointList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEndpointList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudEndpointList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEndpointSpec) DeepCopyInto(out *CloudEndpointSpec) {
+	*out = *in
+	if in.TrafficPolicy != nil {
+		in, out := &in.TrafficPolicy, &out.TrafficPolicy
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEndpointSpec.
+func (in *CloudEndpointSpec) DeepCopy() *CloudEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEndpointStatus) DeepCopyInto(out *CloudEndpointStatus) {
+	*out = *in
+	if in.TrafficPolicy != nil {
+		in, out := &in.TrafficPolicy, &out.TrafficPolicy
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEndpointStatus.
+func (in *CloudEndpointStatus) DeepCopy() *CloudEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokCertificate) DeepCopyInto(out *NgrokCertificate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokCertificate.
+func (in *NgrokCertificate) DeepCopy() *NgrokCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokCertificate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokCertificateList) DeepCopyInto(out *NgrokCertificateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NgrokCertificate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokCertificateList.
+func (in *NgrokCertificateList) DeepCopy() *NgrokCertificateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokCertificateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokCertificateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokCertificateSpec) DeepCopyInto(out *NgrokCertificateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokCertificateSpec.
+func (in *NgrokCertificateSpec) DeepCopy() *NgrokCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokCertificateStatus) DeepCopyInto(out *NgrokCertificateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokCertificateStatus.
+func (in *NgrokCertificateStatus) DeepCopy() *NgrokCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokControllerStatus) DeepCopyInto(out *NgrokControllerStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokControllerStatus.
+func (in *NgrokControllerStatus) DeepCopy() *NgrokControllerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokControllerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokControllerStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokControllerStatusList) DeepCopyInto(out *NgrokControllerStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NgrokControllerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokControllerStatusList.
+func (in *NgrokControllerStatusList) DeepCopy() *NgrokControllerStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokControllerStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NgrokControllerStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NgrokControllerStatusStatus) DeepCopyInto(out *NgrokControllerStatusStatus) {
+	*out = *in
+	if in.LastSuccessfulAPIContact != nil {
+		in, out := &in.LastSuccessfulAPIContact, &out.LastSuccessfulAPIContact
+		*out = (*in).DeepCopy()
+	}
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NgrokControllerStatusStatus.
+func (in *NgrokControllerStatusStatus) DeepCopy() *NgrokControllerStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NgrokControllerStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NgrokTrafficPolicy) DeepCopyInto(out *NgrokTrafficPolicy) {
 	*out = *in
@@ -25,6 +25,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -32,26 +33,36 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/ngrok/ngrok-api-go/v5"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
 	gatewaycontroller "github.com/ngrok/kubernetes-ingress-controller/internal/controller/gateway"
 	controllers "github.com/ngrok/kubernetes-ingress-controller/internal/controller/ingress"
 	ngrokctr "github.com/ngrok/kubernetes-ingress-controller/internal/controller/ngrok"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/credentials"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/version"
@@ -67,6 +78,8 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1alpha2.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1beta1.AddToScheme(scheme))
 	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -91,13 +104,22 @@ type managerOpts struct {
 	metaData                  string
 	managerName               string
 	useExperimentalGatewayAPI bool
+	statusReportInterval      time.Duration
+	backendMissingBehavior    string
+	verifyUpstreamTLS         bool
+	backendKeepAliveInterval  time.Duration
+	translateModulesToPolicy  bool
+	apiKeySource              credentials.Source
+	authtokenSource           credentials.Source
 	zapOpts                   *zap.Options
 
 	// env vars
-	namespace   string
-	ngrokAPIKey string
+	namespace      string
+	ngrokAPIKey    string
+	ngrokAuthtoken string
 
-	region string
+	region              string
+	defaultDomainRegion string
 
 	rootCAs string
 }
@@ -116,13 +138,23 @@ func cmd() *cobra.Command {
 	c.Flags().StringVar(&opts.electionID, "election-id", "ngrok-ingress-controller-leader", "The name of the configmap that is used for holding the leader lock")
 	c.Flags().StringVar(&opts.metaData, "metadata", "", "A comma separated list of key value pairs such as 'key1=value1,key2=value2' to be added to ngrok api resources as labels")
 	c.Flags().StringVar(&opts.region, "region", "", "The region to use for ngrok tunnels")
+	c.Flags().StringVar(&opts.defaultDomainRegion, "default-domain-region", "", "The region to reserve domains in when their Domain doesn't set one. Defaults to the region ngrok picks")
 	c.Flags().StringVar(&opts.serverAddr, "server-addr", "", "The address of the ngrok server to use for tunnels")
 	c.Flags().StringVar(&opts.apiURL, "api-url", "", "The base URL to use for the ngrok api")
 	c.Flags().StringVar(&opts.controllerName, "controller-name", "k8s.ngrok.com/ingress-controller", "The name of the controller to use for matching ingresses classes")
-	c.Flags().StringVar(&opts.watchNamespace, "watch-namespace", "", "Namespace to watch for Kubernetes resources. Defaults to all namespaces.")
+	c.Flags().StringVar(&opts.watchNamespace, "watch-namespace", "", "Namespace to watch for Kubernetes resources, or a comma separated list of namespaces. Defaults to all namespaces.")
 	c.Flags().StringVar(&opts.managerName, "manager-name", "ngrok-ingress-controller-manager", "Manager name to identify unique ngrok ingress controller instances")
 	c.Flags().BoolVar(&opts.useExperimentalGatewayAPI, "use-experimental-gateway-api", false, "sets up experemental gatewayAPI")
 	c.Flags().StringVar(&opts.rootCAs, "root-cas", "trusted", "trusted (default) or host: use the trusted ngrok agent CA or the host CA")
+	c.Flags().StringVar(&opts.backendMissingBehavior, "backend-missing-behavior", store.BackendMissingBehaviorServe503, "What happens to an ingress's edge when its backend service is deleted: serve-503 (default) keeps the edge and responds with a 503, teardown removes the edge")
+	c.Flags().BoolVar(&opts.verifyUpstreamTLS, "verify-upstream-tls", false, "Verify the certificates of HTTPS backends. Services can opt out with the k8s.ngrok.com/upstream-tls-skip-verify annotation")
+	c.Flags().DurationVar(&opts.backendKeepAliveInterval, "backend-keepalive-interval", 0, "How often keepalive probes are sent on connections to backend services. Must be at least 1s. Services can override this with the k8s.ngrok.com/backend-keepalive-interval annotation. Defaults to 15s")
+	c.Flags().StringVar(&opts.apiKeySource.Env, "api-key-env", "", "The environment variable to read the ngrok API key from instead of NGROK_API_KEY")
+	c.Flags().StringVar(&opts.apiKeySource.File, "api-key-file", "", "The file to read the ngrok API key from instead of NGROK_API_KEY")
+	c.Flags().StringVar(&opts.authtokenSource.Env, "authtoken-env", "", "The environment variable to read the ngrok authtoken from instead of NGROK_AUTHTOKEN")
+	c.Flags().StringVar(&opts.authtokenSource.File, "authtoken-file", "", "The file to read the ngrok authtoken from instead of NGROK_AUTHTOKEN")
+	c.Flags().BoolVar(&opts.translateModulesToPolicy, "translate-modules-to-traffic-policy", false, "Experimental: configure the compression, IP restriction, headers, OAuth and OIDC modules of HTTPS edge routes as traffic policy rules instead of individual modules")
+	c.Flags().DurationVar(&opts.statusReportInterval, "status-report-interval", 30*time.Second, "How often the controller reports its state to the NgrokControllerStatus resource")
 	opts.zapOpts = &zap.Options{}
 	goFlagSet := flag.NewFlagSet("manager", flag.ContinueOnError)
 	opts.zapOpts.BindFlags(goFlagSet)
@@ -140,16 +172,29 @@ func runController(ctx context.Context, opts managerOpts) error {
 		return errors.New("POD_NAMESPACE environment variable should be set, but was not")
 	}
 
-	opts.ngrokAPIKey, ok = os.LookupEnv("NGROK_API_KEY")
-	if !ok {
-		return errors.New("NGROK_API_KEY environment variable should be set, but was not")
+	apiKey, apiKeyFrom, err := credentials.Resolve("ngrok API key", opts.apiKeySource, credentials.DefaultAPIKeyEnv)
+	if err != nil {
+		return err
+	}
+	opts.ngrokAPIKey = apiKey
+
+	authtoken, authtokenFrom, err := credentials.Resolve("ngrok authtoken", opts.authtokenSource, credentials.DefaultAuthtokenEnv)
+	if err != nil {
+		return err
+	}
+	opts.ngrokAuthtoken = authtoken
+
+	if err := ingressv1alpha1.ValidateRegion(opts.defaultDomainRegion); err != nil {
+		return fmt.Errorf("invalid default domain region: %w", err)
 	}
 
 	buildInfo := version.Get()
 	setupLog.Info("starting manager", "version", buildInfo.Version, "commit", buildInfo.GitCommit)
+	setupLog.Info("resolved ngrok credentials", "api_key_from", apiKeyFrom, "authtoken_from", authtokenFrom)
 
 	clientConfigOpts := []ngrok.ClientConfigOption{
 		ngrok.WithUserAgent(version.GetUserAgent()),
+		ngrok.WithHTTPClient(&http.Client{Transport: &ngrokapi.RetryAfterTransport{}}),
 	}
 
 	ngrokClientConfig := ngrok.NewClientConfig(opts.ngrokAPIKey, clientConfigOpts...)
@@ -178,11 +223,12 @@ func runController(ctx context.Context, opts managerOpts) error {
 		LeaderElectionID:       opts.electionID,
 	}
 
-	if opts.watchNamespace != "" {
+	if namespaces := opts.watchNamespaces(); len(namespaces) > 0 {
 		options.Cache = cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				opts.watchNamespace: {},
-			},
+			DefaultNamespaces: map[string]cache.Config{},
+		}
+		for _, ns := range namespaces {
+			options.Cache.DefaultNamespaces[ns] = cache.Config{}
 		}
 	}
 
@@ -203,18 +249,20 @@ func runController(ctx context.Context, opts managerOpts) error {
 		Recorder:             mgr.GetEventRecorderFor("ingress-controller"),
 		Namespace:            opts.namespace,
 		AnnotationsExtractor: annotations.NewAnnotationsExtractor(),
+		DeprecationWarner:    annotations.NewDeprecationWarner(mgr.GetEventRecorderFor("ingress-controller"), annotations.DefaultDeprecationWarningInterval, parser.DefaultDeprecatedAnnotations()),
 		Driver:               driver,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create ingress controller: %w", err)
 	}
 
 	if err = (&controllers.ServiceReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("service"),
-		Scheme:    mgr.GetScheme(),
-		Recorder:  mgr.GetEventRecorderFor("service-controller"),
-		Namespace: opts.namespace,
-		Driver:    driver,
+		Client:            mgr.GetClient(),
+		Log:               ctrl.Log.WithName("controllers").WithName("service"),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          mgr.GetEventRecorderFor("service-controller"),
+		Namespace:         opts.namespace,
+		Driver:            driver,
+		DeprecationWarner: annotations.NewDeprecationWarner(mgr.GetEventRecorderFor("service-controller"), annotations.DefaultDeprecationWarningInterval, parser.DefaultDeprecatedAnnotations()),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Service")
 		os.Exit(1)
@@ -226,10 +274,21 @@ func runController(ctx context.Context, opts managerOpts) error {
 		Scheme:        mgr.GetScheme(),
 		Recorder:      mgr.GetEventRecorderFor("domain-controller"),
 		DomainsClient: ngrokClientset.Domains(),
+		DefaultRegion: opts.defaultDomainRegion,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Domain")
 		os.Exit(1)
 	}
+	if err = (&controllers.ReservedAddrReconciler{
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("reserved-addr"),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("reserved-addr-controller"),
+		AddrsClient: ngrokClientset.TCPAddresses(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ReservedAddr")
+		os.Exit(1)
+	}
 
 	var comments tunneldriver.TunnelDriverComments
 
@@ -249,6 +308,7 @@ func runController(ctx context.Context, opts managerOpts) error {
 		tunneldriver.TunnelDriverOpts{
 			ServerAddr: opts.serverAddr,
 			Region:     opts.region,
+			Authtoken:  opts.ngrokAuthtoken,
 			RootCAs:    rootCAs,
 			Comments:   &comments,
 		},
@@ -268,6 +328,16 @@ func runController(ctx context.Context, opts managerOpts) error {
 		setupLog.Error(err, "unable to create controller", "controller", "Tunnel")
 		os.Exit(1)
 	}
+	if err = (&controllers.AgentEndpointReconciler{
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("agent-endpoint"),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("agent-endpoint-controller"),
+		TunnelDriver: td,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AgentEndpoint")
+		os.Exit(1)
+	}
 	if err = (&controllers.TCPEdgeReconciler{
 		Client:         mgr.GetClient(),
 		Log:            ctrl.Log.WithName("controllers").WithName("tcp-edge"),
@@ -288,12 +358,36 @@ func runController(ctx context.Context, opts managerOpts) error {
 		setupLog.Error(err, "unable to create controller", "controller", "TLSEdge")
 		os.Exit(1)
 	}
+	if err = (&controllers.CloudEndpointReconciler{
+		Client:               mgr.GetClient(),
+		Log:                  ctrl.Log.WithName("controllers").WithName("cloud-endpoint"),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorderFor("cloud-endpoint-controller"),
+		CloudEndpointsClient: ngrokClientset.CloudEndpoints(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CloudEndpoint")
+		os.Exit(1)
+	}
+	if err = (&controllers.NgrokCertificateReconciler{
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("ngrok-certificate"),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("ngrok-certificate-controller"),
+		CertificatesClient: ngrokClientset.TLSCertificates(),
+		DomainsClient:      ngrokClientset.Domains(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NgrokCertificate")
+		os.Exit(1)
+	}
 	if err = (&controllers.HTTPSEdgeReconciler{
 		Client:         mgr.GetClient(),
 		Log:            ctrl.Log.WithName("controllers").WithName("https-edge"),
 		Scheme:         mgr.GetScheme(),
 		Recorder:       mgr.GetEventRecorderFor("https-edge-controller"),
 		NgrokClientset: ngrokClientset,
+		Driver:         driver,
+
+		TranslateModulesToPolicy: opts.translateModulesToPolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HTTPSEdge")
 		os.Exit(1)
@@ -320,6 +414,16 @@ func runController(ctx context.Context, opts managerOpts) error {
 		os.Exit(1)
 	}
 	if opts.useExperimentalGatewayAPI {
+		if err = (&gatewaycontroller.GatewayClassReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("GatewayClass"),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("gateway-controller"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "GatewayClass")
+			os.Exit(1)
+		}
+
 		if err = (&gatewaycontroller.GatewayReconciler{
 			Client:   mgr.GetClient(),
 			Log:      ctrl.Log.WithName("controllers").WithName("Gateway"),
@@ -341,6 +445,37 @@ func runController(ctx context.Context, opts managerOpts) error {
 			setupLog.Error(err, "unable to create controller", "controller", "HTTPRoute")
 			os.Exit(1)
 		}
+
+		if err = (&gatewaycontroller.TCPRouteReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("TCPRoute"),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("gateway-controller"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "TCPRoute")
+			os.Exit(1)
+		}
+
+		if err = (&gatewaycontroller.TLSRouteReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("TLSRoute"),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("gateway-controller"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "TLSRoute")
+			os.Exit(1)
+		}
+
+		if err = (&gatewaycontroller.GRPCRouteReconciler{
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("controllers").WithName("GRPCRoute"),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("gateway-controller"),
+			Driver:   driver,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "GRPCRoute")
+			os.Exit(1)
+		}
 	}
 
 	if err = (&ngrokctr.NgrokTrafficPolicyReconciler{
@@ -353,6 +488,22 @@ func runController(ctx context.Context, opts managerOpts) error {
 		setupLog.Error(err, "unable to create controller", "controller", "TrafficPolicy")
 		os.Exit(1)
 	}
+	if err = (&ngrokctr.NgrokControllerStatusReporter{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("controller-status"),
+		Driver:   driver,
+		Name:     opts.managerName,
+		Interval: opts.statusReportInterval,
+		APIHealthCheck: func(ctx context.Context) error {
+			limit := "1"
+			iter := ngrokClientset.Domains().List(&ngrok.Paging{Limit: &limit})
+			iter.Next(ctx)
+			return iter.Err()
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NgrokControllerStatus")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddReadyzCheck("readyz", func(req *http.Request) error {
@@ -374,6 +525,18 @@ func runController(ctx context.Context, opts managerOpts) error {
 	return nil
 }
 
+// watchNamespaces returns the namespaces in the comma separated --watch-namespace flag, or none to watch all
+// namespaces
+func (o managerOpts) watchNamespaces() []string {
+	var namespaces []string
+	for _, ns := range strings.Split(o.watchNamespace, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 // getDriver returns a new Driver instance that is seeded with the current state of the cluster.
 func getDriver(ctx context.Context, mgr manager.Manager, options managerOpts) (*store.Driver, error) {
 	logger := mgr.GetLogger().WithName("cache-store-driver")
@@ -387,6 +550,29 @@ func getDriver(ctx context.Context, mgr manager.Manager, options managerOpts) (*
 		},
 		options.useExperimentalGatewayAPI,
 	)
+
+	switch options.backendMissingBehavior {
+	case store.BackendMissingBehaviorServe503, store.BackendMissingBehaviorTeardown:
+		d.WithBackendMissingBehavior(options.backendMissingBehavior)
+	default:
+		return nil, fmt.Errorf("invalid backend missing behavior: %q", options.backendMissingBehavior)
+	}
+
+	d.WithUpstreamTLSVerification(options.verifyUpstreamTLS)
+
+	if options.backendKeepAliveInterval != 0 && options.backendKeepAliveInterval < time.Second {
+		return nil, fmt.Errorf("invalid backend keepalive interval: %s, must be at least 1s", options.backendKeepAliveInterval)
+	}
+	d.WithBackendKeepAliveInterval(options.backendKeepAliveInterval)
+	d.WithEventRecorder(mgr.GetEventRecorderFor("ingress-controller"))
+	d.WithWatchNamespaces(options.watchNamespaces()...)
+
+	synced, err := informersSynced(ctx, mgr, options)
+	if err != nil {
+		return nil, err
+	}
+	d.WithCacheSynced(synced...)
+
 	if options.metaData != "" {
 		metaData := strings.TrimSuffix(options.metaData, ",")
 		// metadata is a comma separated list of key=value pairs.
@@ -403,6 +589,10 @@ func getDriver(ctx context.Context, mgr manager.Manager, options managerOpts) (*
 		d.WithMetaData(customMetaData)
 	}
 
+	if err := d.RegisterMetrics(ctrlmetrics.Registry); err != nil {
+		return nil, fmt.Errorf("unable to register store metrics: %w", err)
+	}
+
 	if err := d.Seed(ctx, mgr.GetAPIReader()); err != nil {
 		return nil, fmt.Errorf("unable to seed cache store: %w", err)
 	}
@@ -411,3 +601,44 @@ func getDriver(ctx context.Context, mgr manager.Manager, options managerOpts) (*
 
 	return d, nil
 }
+
+// informersSynced gets the manager cache's informers for the types the driver's store holds, returning their
+// sync status funcs. The informers start with the manager.
+func informersSynced(ctx context.Context, mgr manager.Manager, options managerOpts) ([]toolscache.InformerSynced, error) {
+	objs := []client.Object{
+		&netv1.IngressClass{},
+		&netv1.Ingress{},
+		&corev1.Service{},
+		&corev1.Secret{},
+		&ingressv1alpha1.Domain{},
+		&ingressv1alpha1.HTTPSEdge{},
+		&ingressv1alpha1.TCPEdge{},
+		&ingressv1alpha1.Tunnel{},
+		&ingressv1alpha1.IPPolicy{},
+		&ingressv1alpha1.NgrokModuleSet{},
+		&ingressv1alpha1.NgrokIngressClassParams{},
+		&ngrokv1alpha1.NgrokTrafficPolicy{},
+	}
+	if options.useExperimentalGatewayAPI {
+		objs = append(objs,
+			&gatewayv1.Gateway{},
+			&gatewayv1.HTTPRoute{},
+			&gatewayv1alpha2.GRPCRoute{},
+			&gatewayv1beta1.ReferenceGrant{},
+		)
+	}
+
+	var synced []toolscache.InformerSynced
+	for _, obj := range objs {
+		informer, err := mgr.GetCache().GetInformer(ctx, obj)
+		if err != nil {
+			// GRPCRoutes are optional, clusters with older gateway CRDs don't have them
+			if _, ok := obj.(*gatewayv1alpha2.GRPCRoute); ok && meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to get informer for %T: %w", obj, err)
+		}
+		synced = append(synced, informer.HasSynced)
+	}
+	return synced, nil
+}
@@ -18,6 +18,8 @@ package annotations
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/imdario/mergo"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
@@ -26,6 +28,7 @@ import (
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/ip_policies"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/tls"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/user_agent_filter"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/webhook_verification"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
 	networking "k8s.io/api/networking/v1"
@@ -41,6 +44,7 @@ type RouteModules struct {
 	Headers             *ingressv1alpha1.EndpointHeaders
 	IPRestriction       *ingressv1alpha1.EndpointIPPolicy
 	TLSTermination      *ingressv1alpha1.EndpointTLSTerminationAtEdge
+	UserAgentFilter     *ingressv1alpha1.EndpointUserAgentFilter
 	WebhookVerification *ingressv1alpha1.EndpointWebhookVerification
 }
 
@@ -55,6 +59,7 @@ func NewAnnotationsExtractor() Extractor {
 			"Headers":             headers.NewParser(),
 			"IPRestriction":       ip_policies.NewParser(),
 			"TLSTermination":      tls.NewParser(),
+			"UserAgentFilter":     user_agent_filter.NewParser(),
 			"WebhookVerification": webhook_verification.NewParser(),
 		},
 	}
@@ -107,6 +112,39 @@ func ExtractNgrokModuleSetsFromAnnotations(obj client.Object) ([]string, error)
 	return parser.GetStringSliceAnnotation("modules", obj)
 }
 
+// Extracts the module sets of individual paths of the ingress from the annotation, which maps paths to a
+// comma separated list of module set names. Annotation names can't contain the paths themselves.
+// k8s.ngrok.com/path-modules: '{"/admin": "oauth,audit-headers"}'
+func ExtractPathNgrokModuleSetsFromAnnotations(obj client.Object) (map[string][]string, error) {
+	m, err := parser.GetStringMapAnnotation("path-modules", obj)
+	if err != nil {
+		return nil, err
+	}
+
+	pathSets := make(map[string][]string, len(m))
+	for path, names := range m {
+		sets := []string{}
+		for _, name := range strings.Split(names, ",") {
+			sets = append(sets, strings.TrimSpace(name))
+		}
+		pathSets[path] = sets
+	}
+	return pathSets, nil
+}
+
+// Extracts how the module sets named by the modules annotation are merged when they set the same field
+// k8s.ngrok.com/modules-merge-strategy: "strict" or "override"
+func ExtractModuleSetMergeStrategyFromAnnotations(obj client.Object) (ingressv1alpha1.ModuleSetMergeStrategy, error) {
+	strategy, err := parser.GetStringAnnotation("modules-merge-strategy", obj)
+	if err != nil {
+		if errors.IsMissingAnnotations(err) {
+			return ingressv1alpha1.ModuleSetMergeStrategyStrict, nil
+		}
+		return "", err
+	}
+	return ingressv1alpha1.ModuleSetMergeStrategy(strategy), nil
+}
+
 // Extracts a single traffic policy str from the annotation
 // k8s.ngrok.com/traffic-policy: "module1"
 func ExtractNgrokTrafficPolicyFromAnnotations(obj client.Object) (string, error) {
@@ -126,3 +164,71 @@ func ExtractNgrokTrafficPolicyFromAnnotations(obj client.Object) (string, error)
 
 	return "", nil
 }
+
+// Extracts whether the ingress's hosts should share a single wildcard edge per parent domain
+// instead of an edge per host
+// k8s.ngrok.com/wildcard-edge: "true"
+func ExtractUseWildcardEdgeFromAnnotations(obj client.Object) (bool, error) {
+	return parser.GetBoolAnnotation("wildcard-edge", obj)
+}
+
+// Extracts what should happen to the ingress's edge when one of its backend services is missing
+// k8s.ngrok.com/backend-missing-behavior: "serve-503" or "teardown"
+func ExtractBackendMissingBehaviorFromAnnotations(obj client.Object) (string, error) {
+	return parser.GetStringAnnotation("backend-missing-behavior", obj)
+}
+
+// Extracts the user agent filter of the ingress's routes from the annotations, which take one regular
+// expression per line
+// k8s.ngrok.com/user-agent-filter-allow: "Mozilla/.*"
+// k8s.ngrok.com/user-agent-filter-deny: "(?i).*bot.*"
+func ExtractUserAgentFilterFromAnnotations(obj client.Object) (*ingressv1alpha1.EndpointUserAgentFilter, error) {
+	filter, err := user_agent_filter.NewParser().Parse(obj)
+	if err != nil {
+		return nil, err
+	}
+	return filter.(*ingressv1alpha1.EndpointUserAgentFilter), nil
+}
+
+// Extracts whether tunnels should skip verifying the certificate of a backend service's HTTPS ports from the
+// service's annotations. This only matters when the controller is started with --verify-upstream-tls
+// k8s.ngrok.com/upstream-tls-skip-verify: "true"
+func ExtractUpstreamTLSSkipVerifyFromAnnotations(obj client.Object) (bool, error) {
+	return parser.GetBoolAnnotation("upstream-tls-skip-verify", obj)
+}
+
+// Extracts the name sent with SNI and verified against the certificate of a backend service's HTTPS ports from
+// the service's annotations, for backends whose certificate isn't issued for the service's cluster DNS name.
+// k8s.ngrok.com/upstream-tls-server-name: "api.internal.example.com"
+func ExtractUpstreamTLSServerNameFromAnnotations(obj client.Object) (string, error) {
+	return parser.GetStringAnnotation("upstream-tls-server-name", obj)
+}
+
+// Extracts the name of the Secret in a backend service's namespace whose ca.crt key holds the certificate
+// authorities that verify the certificate of the service's HTTPS ports from the service's annotations.
+// k8s.ngrok.com/upstream-tls-ca-secret: "backend-ca"
+func ExtractUpstreamTLSCASecretFromAnnotations(obj client.Object) (string, error) {
+	return parser.GetStringAnnotation("upstream-tls-ca-secret", obj)
+}
+
+// Extracts the maximum number of concurrent connections to a backend service from the service's annotations.
+// The limit is shared by all ingresses using the service as a backend.
+// k8s.ngrok.com/max-connections: "100"
+func ExtractMaxConnectionsFromAnnotations(obj client.Object) (int, error) {
+	return parser.GetIntAnnotation("max-connections", obj)
+}
+
+// Extracts how often keepalive probes are sent on connections to a backend service from the service's annotations.
+// k8s.ngrok.com/backend-keepalive-interval: "30s"
+func ExtractBackendKeepAliveIntervalFromAnnotations(obj client.Object) (time.Duration, error) {
+	v, err := parser.GetStringAnnotation("backend-keepalive-interval", obj)
+	if err != nil {
+		return 0, err
+	}
+
+	interval, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.NewInvalidAnnotationContent("backend-keepalive-interval", v)
+	}
+	return interval, nil
+}
@@ -16,7 +16,7 @@ func NewParser() parser.Annotation {
 // compression configuration or an error. If no compression annotations are
 // found, the returned error an errors.ErrMissingAnnotations.
 func (c compression) Parse(obj client.Object) (interface{}, error) {
-	v, err := parser.GetBoolAnnotation("https-compression", obj)
+	v, err := parser.GetBoolAnnotation("compression", obj)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +23,7 @@ func TestCompressionWhenNotSupplied(t *testing.T) {
 func TestCompressionWhenSuppliedAndTrue(t *testing.T) {
 	ing := testutil.NewIngress()
 	annotations := map[string]string{}
-	annotations[parser.GetAnnotationWithPrefix("https-compression")] = "true"
+	annotations[parser.GetAnnotationWithPrefix("compression")] = "true"
 	ing.SetAnnotations(annotations)
 
 	parsed, err := NewParser().Parse(ing)
@@ -39,7 +39,7 @@ func TestCompressionWhenSuppliedAndTrue(t *testing.T) {
 func TestCompressionWhenSuppliedAndFalse(t *testing.T) {
 	ing := testutil.NewIngress()
 	annotations := map[string]string{}
-	annotations[parser.GetAnnotationWithPrefix("https-compression")] = "false"
+	annotations[parser.GetAnnotationWithPrefix("compression")] = "false"
 	ing.SetAnnotations(annotations)
 
 	parsed, err := NewParser().Parse(ing)
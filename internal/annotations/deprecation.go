@@ -0,0 +1,76 @@
+package annotations
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+)
+
+// DefaultDeprecationWarningInterval is how long to wait before warning about the same deprecated annotation
+// on the same object again
+const DefaultDeprecationWarningInterval = time.Hour
+
+// DeprecationWarner emits a Warning event on objects using deprecated annotations. Each deprecated annotation
+// is warned about once per object, and again only after the interval has passed, so reconciling an object
+// repeatedly doesn't flood its events.
+type DeprecationWarner struct {
+	recorder   record.EventRecorder
+	deprecated parser.DeprecatedAnnotations
+	interval   time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	warned map[string]time.Time
+}
+
+// NewDeprecationWarner creates a DeprecationWarner emitting events with the recorder for the deprecated
+// annotations
+func NewDeprecationWarner(recorder record.EventRecorder, interval time.Duration, deprecated parser.DeprecatedAnnotations) *DeprecationWarner {
+	return &DeprecationWarner{
+		recorder:   recorder,
+		deprecated: deprecated,
+		interval:   interval,
+		now:        time.Now,
+		warned:     make(map[string]time.Time),
+	}
+}
+
+// Warn emits a Warning event for each deprecated annotation on the object that hasn't been warned about
+// within the interval. The deprecated annotations are still honored.
+func (w *DeprecationWarner) Warn(obj client.Object) {
+	if w == nil || obj == nil {
+		return
+	}
+
+	used := w.deprecated.Used(obj)
+	if len(used) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	for key, at := range w.warned {
+		if now.Sub(at) >= w.interval {
+			delete(w.warned, key)
+		}
+	}
+
+	for _, annotation := range used {
+		key := fmt.Sprintf("%s/%s", obj.GetUID(), annotation.Deprecated)
+		if _, ok := w.warned[key]; ok {
+			continue
+		}
+		w.warned[key] = now
+		w.recorder.Eventf(obj, corev1.EventTypeWarning, events.ReasonDeprecatedAnnotation,
+			"Annotation %s is deprecated, use %s instead", annotation.Deprecated, annotation.Replacement)
+	}
+}
@@ -0,0 +1,102 @@
+package annotations
+
+import (
+	"testing"
+	"time"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/compression"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDeprecatedAnnotationIsHonored(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("https-compression"): "true",
+	})
+
+	parsed, err := compression.NewParser().Parse(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, &ingressv1alpha1.EndpointCompression{Enabled: true}, parsed)
+}
+
+func TestReplacementAnnotationTakesPrecedence(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("https-compression"): "true",
+		parser.GetAnnotationWithPrefix("compression"):       "false",
+	})
+
+	parsed, err := compression.NewParser().Parse(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, &ingressv1alpha1.EndpointCompression{Enabled: false}, parsed)
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	warner := NewDeprecationWarner(recorder, time.Hour, parser.DefaultDeprecatedAnnotations())
+	now := time.Now()
+	warner.now = func() time.Time { return now }
+
+	ing := testutil.NewIngress()
+	ing.SetUID("test-uid")
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("https-compression"): "true",
+	})
+
+	warner.Warn(ing)
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning DeprecatedAnnotation Annotation k8s.ngrok.com/https-compression is deprecated, use k8s.ngrok.com/compression instead", <-recorder.Events)
+
+	// only warned once per object within the interval
+	warner.Warn(ing)
+	assert.Len(t, recorder.Events, 0)
+
+	// other objects are warned about separately
+	other := testutil.NewIngress()
+	other.SetUID("other-uid")
+	other.SetAnnotations(ing.GetAnnotations())
+	warner.Warn(other)
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// and warned about again after the interval
+	now = now.Add(time.Hour)
+	warner.Warn(ing)
+	assert.Len(t, recorder.Events, 1)
+}
+
+func TestNoDeprecationWarning(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	warner := NewDeprecationWarner(recorder, time.Hour, parser.DefaultDeprecatedAnnotations())
+
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("compression"): "true",
+	})
+	warner.Warn(ing)
+	assert.Len(t, recorder.Events, 0)
+
+	// a nil warner is a no-op
+	var nilWarner *DeprecationWarner
+	nilWarner.Warn(ing)
+}
+
+func TestDeprecationWarningForInjectedAnnotations(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	warner := NewDeprecationWarner(recorder, time.Hour, parser.DeprecatedAnnotations{"old-wildcard-edge": "wildcard-edge"})
+
+	ing := testutil.NewIngress()
+	ing.SetUID("test-uid")
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("old-wildcard-edge"): "true",
+		parser.GetAnnotationWithPrefix("https-compression"): "true",
+	})
+
+	warner.Warn(ing)
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning DeprecatedAnnotation Annotation k8s.ngrok.com/old-wildcard-edge is deprecated, use k8s.ngrok.com/wildcard-edge instead", <-recorder.Events)
+}
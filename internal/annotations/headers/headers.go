@@ -87,5 +87,9 @@ func (h headers) Parse(obj client.Object) (interface{}, error) {
 		return nil, errors.ErrMissingAnnotations
 	}
 
+	if err := parsed.Validate(); err != nil {
+		return nil, err
+	}
+
 	return parsed, nil
 }
@@ -3,6 +3,7 @@ package headers
 import (
 	"testing"
 
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/testutil"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
@@ -83,3 +84,13 @@ func TestInvalidResponseHeadersAdd(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.IsInvalidContent(err))
 }
+
+func TestRequestHeadersAddWithUnknownVariable(t *testing.T) {
+	ing := testutil.NewIngress()
+	annotations := map[string]string{}
+	annotations[parser.GetAnnotationWithPrefix("request-headers-add")] = `{"X-Country": "${.geo.country}"}`
+	ing.SetAnnotations(annotations)
+
+	_, err := NewParser().Parse(ing)
+	assert.ErrorIs(t, err, ingressv1alpha1.ErrInvalidHeaders)
+}
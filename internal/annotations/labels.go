@@ -0,0 +1,51 @@
+package annotations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Extracts the labels to attach as metadata to the ngrok edges and tunnels created for the object, such as
+// to attribute usage to a team or cost center. Keys and values follow the syntax of Kubernetes labels.
+// k8s.ngrok.com/labels: "team=payments,cost-center=1234"
+func ExtractLabelsFromAnnotations(obj client.Object) (map[string]string, error) {
+	pairs, err := parser.GetStringSliceAnnotation("labels", obj)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := parseLabels(pairs)
+	if err != nil {
+		return nil, errors.NewInvalidAnnotationContent("labels", fmt.Sprintf("%s: %v", strings.Join(pairs, ","), err))
+	}
+	return labels, nil
+}
+
+// parseLabels parses a list of key=value pairs, validating them as Kubernetes labels
+func parseLabels(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q must be of the form key=value", pair)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value for label %q: %s", key, strings.Join(errs, "; "))
+		}
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("label %q is set more than once", key)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
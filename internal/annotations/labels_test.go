@@ -0,0 +1,50 @@
+package annotations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/testutil"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractLabelsFromAnnotations(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("labels"): "team=payments, example.com/cost-center=1234,empty=",
+	})
+
+	labels, err := ExtractLabelsFromAnnotations(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"team":                    "payments",
+		"example.com/cost-center": "1234",
+		"empty":                   "",
+	}, labels)
+}
+
+func TestExtractLabelsFromAnnotationsMissing(t *testing.T) {
+	_, err := ExtractLabelsFromAnnotations(testutil.NewIngress())
+	assert.True(t, errors.IsMissingAnnotations(err))
+}
+
+func TestExtractLabelsFromAnnotationsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"team",
+		"=payments",
+		"team=payments,team=billing",
+		"bad key=payments",
+		"team=not a valid value",
+		"team=" + strings.Repeat("a", 64),
+	} {
+		ing := testutil.NewIngress()
+		ing.SetAnnotations(map[string]string{
+			parser.GetAnnotationWithPrefix("labels"): value,
+		})
+
+		_, err := ExtractLabelsFromAnnotations(ing)
+		assert.True(t, errors.IsInvalidContent(err), "expected %q to be invalid", value)
+	}
+}
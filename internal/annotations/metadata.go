@@ -0,0 +1,13 @@
+package annotations
+
+import (
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Extracts the metadata to attach to the ngrok edges created for the object, as a JSON object of string values
+// like the metadata of a reserved domain.
+// k8s.ngrok.com/metadata: '{"team": "payments", "cost-center": "1234"}'
+func ExtractMetadataFromAnnotations(obj client.Object) (map[string]string, error) {
+	return parser.GetStringMapAnnotation("metadata", obj)
+}
@@ -0,0 +1,38 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/testutil"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMetadataFromAnnotations(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("metadata"): `{"team": "payments", "cost-center": "1234"}`,
+	})
+
+	metadata, err := ExtractMetadataFromAnnotations(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "cost-center": "1234"}, metadata)
+}
+
+func TestExtractMetadataFromAnnotationsInvalid(t *testing.T) {
+	for _, value := range []string{
+		`team=payments`,
+		`{"team": "payments"`,
+		`{"replicas": 3}`,
+		`["payments"]`,
+	} {
+		ing := testutil.NewIngress()
+		ing.SetAnnotations(map[string]string{
+			parser.GetAnnotationWithPrefix("metadata"): value,
+		})
+
+		_, err := ExtractMetadataFromAnnotations(ing)
+		assert.True(t, errors.IsInvalidContent(err), "expected %q to be invalid", value)
+	}
+}
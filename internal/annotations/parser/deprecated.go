@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeprecatedAnnotations maps deprecated annotations to the annotations replacing them, both without the
+// AnnotationsPrefix. A deprecated annotation is still honored when an object doesn't have its replacement.
+type DeprecatedAnnotations map[string]string
+
+// DefaultDeprecatedAnnotations returns the annotations the controller has deprecated
+func DefaultDeprecatedAnnotations() DeprecatedAnnotations {
+	return DeprecatedAnnotations{
+		// compression applies to every kind of edge and endpoint, not just https edges
+		"https-compression": "compression",
+	}
+}
+
+// deprecatedAnnotations are the deprecated annotations the getters fall back to
+var deprecatedAnnotations = DefaultDeprecatedAnnotations()
+
+// DeprecatedAnnotation is a deprecated annotation used by an object along with the annotation replacing it
+type DeprecatedAnnotation struct {
+	Deprecated  string
+	Replacement string
+}
+
+// replacedBy returns the deprecated annotations replaced by name, in a stable order
+func (d DeprecatedAnnotations) replacedBy(name string) []string {
+	deprecated := []string{}
+	for old, replacement := range d {
+		if replacement == name {
+			deprecated = append(deprecated, old)
+		}
+	}
+	sort.Strings(deprecated)
+	return deprecated
+}
+
+// resolve returns the prefixed annotation to read for name from the object. This is the annotation itself
+// unless the object only has one of the deprecated annotations it replaces.
+func (d DeprecatedAnnotations) resolve(name string, obj client.Object) string {
+	key := GetAnnotationWithPrefix(name)
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[key]; ok {
+		return key
+	}
+
+	for _, old := range d.replacedBy(name) {
+		oldKey := GetAnnotationWithPrefix(old)
+		if _, ok := annotations[oldKey]; ok {
+			return oldKey
+		}
+	}
+	return key
+}
+
+// resolveAnnotation returns the prefixed annotation to read for name from the object, falling back to the
+// deprecated annotations it replaces
+func resolveAnnotation(name string, obj client.Object) string {
+	return deprecatedAnnotations.resolve(name, obj)
+}
+
+// Used returns the deprecated annotations set on the object, sorted by the deprecated annotation
+func (d DeprecatedAnnotations) Used(obj client.Object) []DeprecatedAnnotation {
+	used := []DeprecatedAnnotation{}
+	if obj == nil {
+		return used
+	}
+
+	annotations := obj.GetAnnotations()
+	for old, replacement := range d {
+		oldKey := GetAnnotationWithPrefix(old)
+		if _, ok := annotations[oldKey]; ok {
+			used = append(used, DeprecatedAnnotation{
+				Deprecated:  oldKey,
+				Replacement: GetAnnotationWithPrefix(replacement),
+			})
+		}
+	}
+	sort.Slice(used, func(i, j int) bool {
+		return used[i].Deprecated < used[j].Deprecated
+	})
+	return used
+}
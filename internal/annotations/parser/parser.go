@@ -144,6 +144,7 @@ func GetBoolAnnotation(name string, obj client.Object) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseBool(v)
 }
 
@@ -154,7 +155,7 @@ func GetStringAnnotation(name string, obj client.Object) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseString(v)
 }
 
@@ -164,7 +165,7 @@ func GetStringSliceAnnotation(name string, obj client.Object) ([]string, error)
 	if err != nil {
 		return []string{}, err
 	}
-
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseStringSlice(v)
 }
 
@@ -174,7 +175,7 @@ func GetStringMapAnnotation(name string, obj client.Object) (map[string]string,
 	if err != nil {
 		return nil, err
 	}
-
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseStringMap(v)
 }
 
@@ -185,6 +186,7 @@ func GetIntAnnotation(name string, obj client.Object) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseInt(v)
 }
 
@@ -195,6 +197,7 @@ func GetFloatAnnotation(name string, obj client.Object) (float32, error) {
 	if err != nil {
 		return 0, err
 	}
+	v = resolveAnnotation(name, obj)
 	return annotations(obj.GetAnnotations()).parseFloat32(v)
 }
 
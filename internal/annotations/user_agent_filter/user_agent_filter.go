@@ -0,0 +1,61 @@
+package user_agent_filter
+
+import (
+	"strings"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type userAgentFilter struct{}
+
+func NewParser() parser.Annotation {
+	return userAgentFilter{}
+}
+
+// Parse parses the user-agent-filter-allow and user-agent-filter-deny annotations of the ingress into a user
+// agent filter. Each annotation is a list of regular expressions, one per line, since a regular expression
+// may contain commas. If neither annotation is found, the returned error is errors.ErrMissingAnnotations.
+func (f userAgentFilter) Parse(obj client.Object) (interface{}, error) {
+	allow, err := getPatterns("user-agent-filter-allow", obj)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := getPatterns("user-agent-filter-deny", obj)
+	if err != nil {
+		return nil, err
+	}
+	if allow == nil && deny == nil {
+		return nil, errors.ErrMissingAnnotations
+	}
+
+	filter := &ingressv1alpha1.EndpointUserAgentFilter{
+		Allow: allow,
+		Deny:  deny,
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// getPatterns returns the non-empty lines of the annotation, or nil if the annotation isn't set
+func getPatterns(name string, obj client.Object) ([]string, error) {
+	v, err := parser.GetStringAnnotation(name, obj)
+	if err != nil {
+		if errors.IsMissingAnnotations(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	patterns := []string{}
+	for _, line := range strings.Split(v, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, nil
+}
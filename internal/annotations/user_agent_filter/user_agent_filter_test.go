@@ -0,0 +1,59 @@
+package user_agent_filter
+
+import (
+	"testing"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/testutil"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAgentFilterWhenNotSupplied(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{})
+	parsed, err := NewParser().Parse(ing)
+
+	assert.Nil(t, parsed)
+	assert.True(t, errors.IsMissingAnnotations(err))
+}
+
+func TestUserAgentFilterParsesPatternsPerLine(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("user-agent-filter-deny"): "(?i)curl/.*\n  ^python-requests/\\d{1,3}\n",
+	})
+
+	parsed, err := NewParser().Parse(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, &ingressv1alpha1.EndpointUserAgentFilter{
+		Deny: []string{"(?i)curl/.*", `^python-requests/\d{1,3}`},
+	}, parsed)
+}
+
+func TestUserAgentFilterAllowAndDeny(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("user-agent-filter-allow"): "Mozilla/.*",
+		parser.GetAnnotationWithPrefix("user-agent-filter-deny"):  ".*bot.*",
+	})
+
+	parsed, err := NewParser().Parse(ing)
+	assert.NoError(t, err)
+	assert.Equal(t, &ingressv1alpha1.EndpointUserAgentFilter{
+		Allow: []string{"Mozilla/.*"},
+		Deny:  []string{".*bot.*"},
+	}, parsed)
+}
+
+func TestUserAgentFilterInvalidPattern(t *testing.T) {
+	ing := testutil.NewIngress()
+	ing.SetAnnotations(map[string]string{
+		parser.GetAnnotationWithPrefix("user-agent-filter-deny"): "scraper(",
+	})
+
+	parsed, err := NewParser().Parse(ing)
+	assert.Nil(t, parsed)
+	assert.ErrorIs(t, err, ingressv1alpha1.ErrInvalidUserAgentFilter)
+}
@@ -43,3 +43,22 @@ func RemoveAndSyncFinalizer(ctx context.Context, c client.Writer, o client.Objec
 	RemoveFinalizer(o)
 	return c.Update(ctx, o)
 }
+
+// HandleDeletion short-circuits the reconcile of an object that is being deleted. When the object has a
+// deletion timestamp and still has the finalizer, cleanup is run and the finalizer is removed once it succeeds.
+// It returns whether the object is being deleted, in which case the caller should skip its normal reconcile logic.
+func HandleDeletion(ctx context.Context, c client.Writer, o client.Object, cleanup func(ctx context.Context) error) (bool, error) {
+	if IsUpsert(o) {
+		return false, nil
+	}
+
+	if !HasFinalizer(o) {
+		return true, nil
+	}
+
+	if err := cleanup(ctx); err != nil {
+		return true, err
+	}
+
+	return true, RemoveAndSyncFinalizer(ctx, c, o)
+}
@@ -2,10 +2,18 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"strings"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ierr "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/certificate_authorities"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -57,14 +65,12 @@ func (r *IpPolicyResolver) ResolveIPPolicyNamesorIds(ctx context.Context, namesp
 
 type SecretResolver struct {
 	Client client.Reader
+	// Driver, when set, is used to look up secrets so they are cached in its store across reconciles
+	Driver *store.Driver
 }
 
 func (r *SecretResolver) GetSecret(ctx context.Context, namespace, name, key string) (string, error) {
-	secret := &v1.Secret{}
-	err := r.Client.Get(ctx, types.NamespacedName{
-		Namespace: namespace,
-		Name:      name,
-	}, secret)
+	secret, err := r.getSecret(ctx, namespace, name)
 	if err != nil {
 		return "", err
 	}
@@ -75,3 +81,150 @@ func (r *SecretResolver) GetSecret(ctx context.Context, namespace, name, key str
 	}
 	return string(value), nil
 }
+
+func (r *SecretResolver) getSecret(ctx context.Context, namespace, name string) (*v1.Secret, error) {
+	if r.Driver != nil {
+		return r.Driver.GetSecretV1(ctx, r.Client, name, namespace)
+	}
+
+	secret := &v1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}, secret)
+	return secret, err
+}
+
+// CertificateAuthorityResolver uploads the CA certificates in the Secrets and ConfigMaps referenced by a
+// mutual TLS module to ngrok, reusing certificate authorities that were already uploaded
+type CertificateAuthorityResolver struct {
+	Client                       client.Reader
+	CertificateAuthoritiesClient *certificate_authorities.Client
+}
+
+// ResolveCertificateAuthorityIDs returns the IDs of the module's certificate authorities, uploading the ones
+// from its Secrets and ConfigMaps that ngrok doesn't have yet
+func (r *CertificateAuthorityResolver) ResolveCertificateAuthorityIDs(ctx context.Context, namespace string, mtls *ingressv1alpha1.EndpointMutualTLS) ([]string, error) {
+	ids := append([]string{}, mtls.CertificateAuthorities...)
+	if !mtls.HasCertificateAuthorityRefs() {
+		return ids, nil
+	}
+
+	existing, err := r.listCertificateAuthorities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := func(kind, name string, data []byte) error {
+		certs, err := parseCertificateBundle(data)
+		if err != nil {
+			return ierr.NewErrInvalidConfiguration(fmt.Errorf("%w: %s '%s/%s': %w", ingressv1alpha1.ErrInvalidMutualTLS, kind, namespace, name, err))
+		}
+
+		for _, cert := range certs {
+			fingerprint := certificateFingerprint(cert)
+			if id, ok := existing[fingerprint]; ok {
+				ids = append(ids, id)
+				continue
+			}
+
+			ca, err := r.CertificateAuthoritiesClient.Create(ctx, &ngrok.CertificateAuthorityCreate{
+				Description: fmt.Sprintf("Created by the ngrok ingress controller from %s %s/%s", kind, namespace, name),
+				CAPEM:       string(pem.EncodeToMemory(cert)),
+			})
+			if err != nil {
+				return err
+			}
+			existing[fingerprint] = ca.ID
+			ids = append(ids, ca.ID)
+		}
+		return nil
+	}
+
+	for _, name := range mtls.CertificateAuthoritySecrets {
+		secret := &v1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			return nil, err
+		}
+		if err := upload("Secret", name, secret.Data[ingressv1alpha1.MutualTLSCACertKey]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range mtls.CertificateAuthorityConfigMaps {
+		configMap := &v1.ConfigMap{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap); err != nil {
+			return nil, err
+		}
+		data := []byte(configMap.Data[ingressv1alpha1.MutualTLSCACertKey])
+		if len(data) == 0 {
+			data = configMap.BinaryData[ingressv1alpha1.MutualTLSCACertKey]
+		}
+		if err := upload("ConfigMap", name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return uniqueStrings(ids), nil
+}
+
+// listCertificateAuthorities returns the IDs of the certificate authorities in ngrok by the fingerprint of
+// their certificate
+func (r *CertificateAuthorityResolver) listCertificateAuthorities(ctx context.Context) (map[string]string, error) {
+	existing := map[string]string{}
+	iter := r.CertificateAuthoritiesClient.List(&ngrok.Paging{})
+	for iter.Next(ctx) {
+		ca := iter.Item()
+		certs, err := parseCertificateBundle([]byte(ca.CAPEM))
+		if err != nil {
+			continue
+		}
+		existing[certificateFingerprint(certs[0])] = ca.ID
+	}
+	return existing, iter.Err()
+}
+
+// parseCertificateBundle returns the certificates in PEM encoded data, which may hold more than one
+func parseCertificateBundle(data []byte) ([]*pem.Block, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("key '%s' is missing or empty", ingressv1alpha1.MutualTLSCACertKey)
+	}
+
+	certs := []*pem.Block{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, fmt.Errorf("key '%s' has an invalid certificate: %w", ingressv1alpha1.MutualTLSCACertKey, err)
+		}
+		certs = append(certs, block)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("key '%s' has no PEM encoded certificates", ingressv1alpha1.MutualTLSCACertKey)
+	}
+	return certs, nil
+}
+
+func certificateFingerprint(cert *pem.Block) string {
+	sum := sha256.Sum256(cert.Bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
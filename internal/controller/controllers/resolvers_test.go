@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/certificate_authorities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ierr "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+)
+
+// fakeCertificateAuthorities serves the list and create endpoints of the ngrok certificate authorities API
+type fakeCertificateAuthorities struct {
+	items   []ngrok.CertificateAuthority
+	created []ngrok.CertificateAuthorityCreate
+}
+
+func (f *fakeCertificateAuthorities) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(ngrok.CertificateAuthorityList{CertificateAuthorities: f.items})
+	case http.MethodPost:
+		var create ngrok.CertificateAuthorityCreate
+		_ = json.NewDecoder(r.Body).Decode(&create)
+		f.created = append(f.created, create)
+		ca := ngrok.CertificateAuthority{
+			ID:          fmt.Sprintf("ca_%d", len(f.items)+1),
+			Description: create.Description,
+			CAPEM:       create.CAPEM,
+		}
+		f.items = append(f.items, ca)
+		_ = json.NewEncoder(w).Encode(ca)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestCACert(t *testing.T, name string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestResolveCertificateAuthorityIDs(t *testing.T) {
+	ctx := context.Background()
+	rootCA := newTestCACert(t, "root")
+	intermediateCA := newTestCACert(t, "intermediate")
+	partnerCA := newTestCACert(t, "partner")
+
+	api := &fakeCertificateAuthorities{
+		items: []ngrok.CertificateAuthority{{ID: "ca_existing", CAPEM: string(rootCA)}},
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	c := fake.NewClientBuilder().WithObjects(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "client-ca", Namespace: "test-namespace"},
+			Data: map[string][]byte{
+				ingressv1alpha1.MutualTLSCACertKey: append(append([]byte{}, rootCA...), intermediateCA...),
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "partner-ca", Namespace: "test-namespace"},
+			Data:       map[string]string{ingressv1alpha1.MutualTLSCACertKey: string(partnerCA)},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing-key", Namespace: "test-namespace"},
+			Data:       map[string][]byte{"tls.crt": rootCA},
+		},
+	).Build()
+
+	resolver := &CertificateAuthorityResolver{
+		Client:                       c,
+		CertificateAuthoritiesClient: certificate_authorities.NewClient(ngrok.NewClientConfig("test-api-key", ngrok.WithBaseURL(server.URL))),
+	}
+
+	mtls := &ingressv1alpha1.EndpointMutualTLS{
+		CertificateAuthorities:         []string{"ca_explicit"},
+		CertificateAuthoritySecrets:    []string{"client-ca"},
+		CertificateAuthorityConfigMaps: []string{"partner-ca"},
+	}
+	ids, err := resolver.ResolveCertificateAuthorityIDs(ctx, "test-namespace", mtls)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ca_explicit", "ca_existing", "ca_2", "ca_3"}, ids)
+	require.Len(t, api.created, 2)
+	assert.Equal(t, string(intermediateCA), api.created[0].CAPEM)
+	assert.Equal(t, "Created by the ngrok ingress controller from ConfigMap test-namespace/partner-ca", api.created[1].Description)
+
+	// resolving again reuses the uploaded certificate authorities
+	ids, err = resolver.ResolveCertificateAuthorityIDs(ctx, "test-namespace", mtls)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ca_explicit", "ca_existing", "ca_2", "ca_3"}, ids)
+	assert.Len(t, api.created, 2)
+
+	_, err = resolver.ResolveCertificateAuthorityIDs(ctx, "test-namespace", &ingressv1alpha1.EndpointMutualTLS{
+		CertificateAuthoritySecrets: []string{"missing-key"},
+	})
+	assert.ErrorIs(t, err, ingressv1alpha1.ErrInvalidMutualTLS)
+	assert.True(t, errors.As(err, &ierr.ErrInvalidConfiguration{}))
+	assert.ErrorContains(t, err, "key 'ca.crt' is missing or empty")
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ApplyStatus sets the status of the object with a server-side apply patch of its status subresource, rather
+// than updating the whole status. Only the status fields set on the object are applied, and they're owned by
+// the field owner, so fields set by other field managers are left alone. The applied fields are forced, so one
+// still owned by another manager, like the manager that updated the whole status before, is taken over instead
+// of failing every reconcile with a conflict. The object is updated with the resulting status.
+func ApplyStatus(ctx context.Context, c client.Client, obj client.Object, fieldOwner string) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	// the patch only identifies the object and has its status, so no other fields are applied
+	patch := &unstructured.Unstructured{}
+	patch.SetGroupVersionKind(gvk)
+	patch.SetName(obj.GetName())
+	patch.SetNamespace(obj.GetNamespace())
+	if status, ok := content["status"]; ok {
+		patch.Object["status"] = status
+	}
+
+	if err := c.Status().Patch(ctx, patch, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return fmt.Errorf("applying status of %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(patch.Object, obj)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers/testutil"
+)
+
+func TestApplyStatus(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, ingressv1alpha1.AddToScheme(scheme))
+
+	domain := &ingressv1alpha1.Domain{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test-namespace"},
+		Spec:       ingressv1alpha1.DomainSpec{Domain: "example.com"},
+	}
+	applier := &testutil.StatusApplier{}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(domain).
+		WithStatusSubresource(&ingressv1alpha1.Domain{}).
+		WithInterceptorFuncs(applier.Funcs()).
+		Build()
+
+	// each owner only sets the status fields it owns
+	reserved := &ingressv1alpha1.Domain{ObjectMeta: domain.ObjectMeta}
+	reserved.Status = ingressv1alpha1.DomainStatus{ID: "rd_123", Domain: "example.com", CNAMETarget: ptr.To("abc.ngrok-cname.com")}
+	require.NoError(t, ApplyStatus(ctx, c, reserved, "domain-controller"))
+
+	located := &ingressv1alpha1.Domain{ObjectMeta: domain.ObjectMeta}
+	located.Status = ingressv1alpha1.DomainStatus{Region: "us"}
+	require.NoError(t, ApplyStatus(ctx, c, located, "region-controller"))
+
+	moved := &ingressv1alpha1.Domain{ObjectMeta: domain.ObjectMeta}
+	moved.Status = ingressv1alpha1.DomainStatus{ID: "rd_123", Domain: "example.com", CNAMETarget: ptr.To("def.ngrok-cname.com")}
+	require.NoError(t, ApplyStatus(ctx, c, moved, "domain-controller"))
+
+	assert.Equal(t, []string{"domain-controller", "region-controller", "domain-controller"}, applier.FieldOwners)
+
+	got := &ingressv1alpha1.Domain{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(domain), got))
+	assert.Equal(t, "rd_123", got.Status.ID)
+	assert.Equal(t, "def.ngrok-cname.com", *got.Status.CNAMETarget)
+	assert.Equal(t, "us", got.Status.Region, "applying the domain controller's fields kept the region controller's")
+	assert.Equal(t, "example.com", got.Spec.Domain, "only the status is applied")
+
+	assert.Equal(t, got.Status, moved.Status, "the object has the resulting status")
+}
+
+func TestApplyStatusTakesOverFieldsOfOtherManagers(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, ingressv1alpha1.AddToScheme(scheme))
+
+	domain := &ingressv1alpha1.Domain{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test-namespace"},
+		Spec:       ingressv1alpha1.DomainSpec{Domain: "example.com"},
+	}
+	applier := &testutil.StatusApplier{}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(domain).
+		WithStatusSubresource(&ingressv1alpha1.Domain{}).
+		WithInterceptorFuncs(applier.Funcs()).
+		Build()
+
+	previous := &ingressv1alpha1.Domain{ObjectMeta: domain.ObjectMeta}
+	previous.Status = ingressv1alpha1.DomainStatus{ID: "rd_123", Domain: "example.com"}
+	require.NoError(t, ApplyStatus(ctx, c, previous, "previous-controller"))
+
+	// without forcing, applying a different value to a field owned by another manager is a conflict
+	patch := &unstructured.Unstructured{}
+	patch.SetGroupVersionKind(ingressv1alpha1.GroupVersion.WithKind("Domain"))
+	patch.SetName(domain.Name)
+	patch.SetNamespace(domain.Namespace)
+	patch.Object["status"] = map[string]interface{}{"id": "rd_456"}
+	err := c.Status().Patch(ctx, patch, client.Apply, client.FieldOwner("domain-controller"))
+	assert.True(t, apierrors.IsConflict(err), "expected a conflict, got %v", err)
+
+	current := &ingressv1alpha1.Domain{ObjectMeta: domain.ObjectMeta}
+	current.Status = ingressv1alpha1.DomainStatus{ID: "rd_456", Domain: "example.com"}
+	require.NoError(t, ApplyStatus(ctx, c, current, "domain-controller"))
+
+	got := &ingressv1alpha1.Domain{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(domain), got))
+	assert.Equal(t, "rd_456", got.Status.ID)
+
+	// the field is the domain controller's now, so it can change it again without forcing it
+	patch.Object["status"] = map[string]interface{}{"id": "rd_789"}
+	require.NoError(t, c.Status().Patch(ctx, patch, client.Apply, client.FieldOwner("domain-controller")))
+}
@@ -0,0 +1,98 @@
+// Test Utilities for Controllers
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// StatusApplier lets the fake client handle server-side apply patches of status subresources, which it rejects
+// on its own. Apply patches are made as merge patches, so the applied fields are set and every other field is
+// left alone, like the API server does for fields owned by other field managers. Ownership is tracked for the
+// top level fields of the status, and applying a different value to a field owned by another manager without
+// forcing it is a conflict, like it is for the API server.
+type StatusApplier struct {
+	// FieldOwners are the field owners of the apply patches made, in order
+	FieldOwners []string
+
+	// owners are the managers of the status fields of each object, by the object's key and the field's name
+	owners map[client.ObjectKey]map[string]string
+}
+
+// Funcs returns the interceptor functions to build the fake client with
+func (a *StatusApplier) Funcs() interceptor.Funcs {
+	return interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			if patch.Type() != types.ApplyPatchType {
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			}
+
+			patchOpts := (&client.SubResourcePatchOptions{}).ApplyOptions(opts)
+			if patchOpts.FieldManager == "" {
+				return fmt.Errorf("apply patches require a field manager")
+			}
+			a.FieldOwners = append(a.FieldOwners, patchOpts.FieldManager)
+
+			data, err := patch.Data(obj)
+			if err != nil {
+				return err
+			}
+			if err := a.own(ctx, c, obj, data, patchOpts.FieldManager, ptr.Deref(patchOpts.Force, false)); err != nil {
+				return err
+			}
+			return c.SubResource(subResourceName).Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+		},
+	}
+}
+
+// own makes the manager the owner of the status fields the patch applies, returning a conflict if one of them
+// is owned by another manager and has a different value, unless the patch is forced
+func (a *StatusApplier) own(ctx context.Context, c client.Client, obj client.Object, data []byte, manager string, force bool) error {
+	applied := map[string]interface{}{}
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return err
+	}
+	status, _ := applied["status"].(map[string]interface{})
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return err
+	}
+	currentStatus, _ := current.Object["status"].(map[string]interface{})
+
+	if a.owners == nil {
+		a.owners = map[client.ObjectKey]map[string]string{}
+	}
+	key := client.ObjectKeyFromObject(obj)
+	owners := a.owners[key]
+	if owners == nil {
+		owners = map[string]string{}
+		a.owners[key] = owners
+	}
+
+	for field, value := range status {
+		owner, owned := owners[field]
+		if owned && owner != manager && !force && !reflect.DeepEqual(currentStatus[field], value) {
+			return apierrors.NewApplyConflict([]metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: fmt.Sprintf("conflict with %q", owner),
+				Field:   ".status." + field,
+			}}, fmt.Sprintf("Apply failed with 1 conflict: conflict with %q: .status.%s", owner, field))
+		}
+	}
+	for field := range status {
+		owners[field] = manager
+	}
+	return nil
+}
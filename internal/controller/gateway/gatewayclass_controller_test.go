@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("GatewayClassReconciler", func() {
+	var (
+		ctx context.Context
+		r   *GatewayClassReconciler
+	)
+
+	BeforeEach(func() {
+		requireTestEnv()
+		ctx = context.Background()
+		r = &GatewayClassReconciler{
+			Client:   k8sClient,
+			Log:      logf.Log.WithName("gatewayclass"),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+	})
+
+	createGatewayClass := func(name string, controllerName gatewayv1.GatewayController) *gatewayv1.GatewayClass {
+		gwClass := &gatewayv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       gatewayv1.GatewayClassSpec{ControllerName: controllerName},
+		}
+		Expect(k8sClient.Create(ctx, gwClass)).To(Succeed())
+		return gwClass
+	}
+
+	reconcileGatewayClass := func(gwClass *gatewayv1.GatewayClass) *gatewayv1.GatewayClass {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(gwClass)})
+		Expect(err).ToNot(HaveOccurred())
+
+		current := &gatewayv1.GatewayClass{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(gwClass), current)).To(Succeed())
+		return current
+	}
+
+	It("Should accept an ngrok gatewayclass", func() {
+		gwClass := reconcileGatewayClass(createGatewayClass("ngrok-accepted", ControllerName))
+
+		accepted := meta.FindStatusCondition(gwClass.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted))
+		Expect(accepted).ToNot(BeNil())
+		Expect(accepted.Status).To(Equal(metav1.ConditionTrue))
+		Expect(accepted.Reason).To(Equal(string(gatewayv1.GatewayClassReasonAccepted)))
+		Expect(accepted.ObservedGeneration).To(Equal(gwClass.Generation))
+	})
+
+	It("Should update the observed generation when an ngrok gatewayclass changes", func() {
+		gwClass := reconcileGatewayClass(createGatewayClass("ngrok-updated", ControllerName))
+
+		gwClass.Spec.Description = ptr.To("updated")
+		Expect(k8sClient.Update(ctx, gwClass)).To(Succeed())
+		gwClass = reconcileGatewayClass(gwClass)
+
+		accepted := meta.FindStatusCondition(gwClass.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted))
+		Expect(accepted).ToNot(BeNil())
+		Expect(accepted.ObservedGeneration).To(Equal(gwClass.Generation))
+	})
+
+	It("Should leave gatewayclasses of other controllers alone", func() {
+		gwClass := createGatewayClass("foreign", "example.com/gateway-controller")
+		statusBefore := gwClass.Status.DeepCopy()
+
+		gwClass = reconcileGatewayClass(gwClass)
+		Expect(gwClass.Status).To(Equal(*statusBefore))
+		Expect(meta.IsStatusConditionTrue(gwClass.Status.Conditions, string(gatewayv1.GatewayClassConditionStatusAccepted))).To(BeFalse())
+	})
+})
@@ -0,0 +1,395 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+)
+
+// The TCPRoute and TLSRoute controllers expose a route's backend service through an ngrok edge of their own,
+// rather than through the driver like HTTPRoutes. These are the parts they share.
+
+// ngrokParentRefs returns the route's parent refs to Gateways of an ngrok GatewayClass
+func ngrokParentRefs(ctx context.Context, c client.Client, namespace string, parents []gatewayv1.ParentReference) ([]gatewayv1.ParentReference, error) {
+	var refs []gatewayv1.ParentReference
+	for _, parent := range parents {
+		if parent.Group != nil && *parent.Group != gatewayv1.GroupName {
+			continue
+		}
+		if parent.Kind != nil && *parent.Kind != "Gateway" {
+			continue
+		}
+		gwNamespace := namespace
+		if parent.Namespace != nil {
+			gwNamespace = string(*parent.Namespace)
+		}
+
+		gw := &gatewayv1.Gateway{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: gwNamespace, Name: string(parent.Name)}, gw); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		gwClass := &gatewayv1.GatewayClass{}
+		if err := c.Get(ctx, client.ObjectKey{Name: string(gw.Spec.GatewayClassName)}, gwClass); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		if gwClass.Spec.ControllerName == ControllerName {
+			refs = append(refs, parent)
+		}
+	}
+	return refs, nil
+}
+
+// routeBackend is the service and port a route forwards connections to
+type routeBackend struct {
+	service *corev1.Service
+	port    int32
+}
+
+// labels returns the labels matching the edge's backend to the tunnel
+func (b *routeBackend) labels() map[string]string {
+	return map[string]string{
+		"k8s.ngrok.com/namespace":   b.service.Namespace,
+		"k8s.ngrok.com/service":     b.service.Name,
+		"k8s.ngrok.com/service-uid": string(b.service.UID),
+		"k8s.ngrok.com/port":        strconv.Itoa(int(b.port)),
+	}
+}
+
+// routeRefsError is an error resolving the backendRefs of a route, with the reason of the route's ResolvedRefs
+// condition
+type routeRefsError struct {
+	reason gatewayv1.RouteConditionReason
+	err    error
+}
+
+func (e *routeRefsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *routeRefsError) Unwrap() error {
+	return e.err
+}
+
+// firstRuleBackendRefs returns the backendRefs of the route's first rule. An edge forwards connections to a
+// single backend, so only the first backendRef of the first rule is used, and a warning is returned when the
+// route has other rules or backendRefs that are ignored.
+func firstRuleBackendRefs(rules [][]gatewayv1.BackendRef) ([]gatewayv1.BackendRef, string) {
+	if len(rules) == 0 {
+		return nil, ""
+	}
+	ignoredRefs := max(len(rules[0])-1, 0)
+	for _, refs := range rules[1:] {
+		ignoredRefs += len(refs)
+	}
+	if len(rules) == 1 && ignoredRefs == 0 {
+		return rules[0], ""
+	}
+	return rules[0], fmt.Sprintf("only the first backendRef of the first rule is used, ignoring %d other rules and %d other backendRefs", len(rules)-1, ignoredRefs)
+}
+
+// resolveRouteBackend returns the service and port of a route's backendRefs. Like HTTPRoutes, only the first
+// backendRef is used, and it must be a service with a port. Services in other namespaces must be allowed by a
+// ReferenceGrant in their namespace. Refs that can't be resolved return a *routeRefsError.
+func resolveRouteBackend(ctx context.Context, c client.Client, routeKind, namespace string, refs []gatewayv1.BackendRef) (*routeBackend, error) {
+	if len(refs) == 0 {
+		return nil, &routeRefsError{gatewayv1.RouteReasonBackendNotFound, fmt.Errorf("route has no backendRefs")}
+	}
+	ref := refs[0]
+	if ref.Kind != nil && *ref.Kind != "Service" {
+		return nil, &routeRefsError{gatewayv1.RouteReasonInvalidKind, fmt.Errorf("unsupported backendRef kind %s, only Service is supported", *ref.Kind)}
+	}
+	if ref.Port == nil {
+		return nil, &routeRefsError{gatewayv1.RouteReasonUnsupportedValue, fmt.Errorf("backendRef to service %s must specify a port", ref.Name)}
+	}
+
+	serviceNamespace := namespace
+	if ref.Namespace != nil && string(*ref.Namespace) != namespace {
+		serviceNamespace = string(*ref.Namespace)
+		allowed, err := referenceGrantAllows(ctx, c, routeKind, namespace, serviceNamespace, string(ref.Name))
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, &routeRefsError{gatewayv1.RouteReasonRefNotPermitted, fmt.Errorf("no ReferenceGrant in namespace %s allows %s routes in namespace %s to reference service %s",
+				serviceNamespace, routeKind, namespace, ref.Name)}
+		}
+	}
+
+	service := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: serviceNamespace, Name: string(ref.Name)}, service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &routeRefsError{gatewayv1.RouteReasonBackendNotFound, err}
+		}
+		return nil, err
+	}
+	return &routeBackend{service: service, port: int32(*ref.Port)}, nil
+}
+
+// referenceGrantAllows returns true if a ReferenceGrant in the service's namespace allows routes of the kind in
+// the route's namespace to reference the service
+func referenceGrantAllows(ctx context.Context, c client.Client, routeKind, routeNamespace, serviceNamespace, serviceName string) (bool, error) {
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	if err := c.List(ctx, grants, client.InNamespace(serviceNamespace)); err != nil {
+		return false, err
+	}
+	items := make([]*gatewayv1beta1.ReferenceGrant, 0, len(grants.Items))
+	for i := range grants.Items {
+		items = append(items, &grants.Items[i])
+	}
+	return store.ReferenceGrantAllows(items, routeKind, routeNamespace, serviceNamespace, serviceName), nil
+}
+
+// resolvedRefsCondition returns the route's ResolvedRefs condition for the error resolving its backend
+func resolvedRefsCondition(err *routeRefsError) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:    string(gatewayv1.RouteConditionResolvedRefs),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(err.reason),
+			Message: err.Error(),
+		}
+	}
+	return metav1.Condition{
+		Type:   string(gatewayv1.RouteConditionResolvedRefs),
+		Status: metav1.ConditionTrue,
+		Reason: string(gatewayv1.RouteReasonResolvedRefs),
+	}
+}
+
+// routeAccepted is the Accepted condition of a route attached to an ngrok gateway
+var routeAccepted = metav1.Condition{
+	Type:   string(gatewayv1.RouteConditionAccepted),
+	Status: metav1.ConditionTrue,
+	Reason: string(gatewayv1.RouteReasonAccepted),
+}
+
+// routeParentStatuses returns the route's parent statuses with the conditions set for each of its ngrok
+// parents. The statuses other controllers set for their own gateways are kept.
+func routeParentStatuses(current []gatewayv1.RouteParentStatus, parents []gatewayv1.ParentReference, generation int64, conditions ...metav1.Condition) []gatewayv1.RouteParentStatus {
+	var statuses []gatewayv1.RouteParentStatus
+	var previous []gatewayv1.RouteParentStatus
+	for _, status := range current {
+		if status.ControllerName != ControllerName {
+			statuses = append(statuses, status)
+			continue
+		}
+		previous = append(previous, status)
+	}
+
+	for _, parent := range parents {
+		status := gatewayv1.RouteParentStatus{ParentRef: parent, ControllerName: ControllerName}
+		for _, prev := range previous {
+			if reflect.DeepEqual(prev.ParentRef, parent) {
+				status.Conditions = append([]metav1.Condition(nil), prev.Conditions...)
+			}
+		}
+		for _, condition := range conditions {
+			condition.ObservedGeneration = generation
+			meta.SetStatusCondition(&status.Conditions, condition)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// reconcileRouteTunnel creates or updates the route's tunnel to its backend
+func reconcileRouteTunnel(ctx context.Context, c client.Client, scheme *runtime.Scheme, route client.Object, backend *routeBackend) error {
+	tunnel := &ingressv1alpha1.Tunnel{ObjectMeta: routeObjectMeta(route)}
+	_, err := controllerutil.CreateOrUpdate(ctx, c, tunnel, func() error {
+		tunnel.Spec.ForwardsTo = fmt.Sprintf("%s.%s.%s:%d", backend.service.Name, backend.service.Namespace, "svc.cluster.local", backend.port)
+		tunnel.Spec.Labels = backend.labels()
+		return controllerutil.SetControllerReference(route, tunnel, scheme)
+	})
+	return err
+}
+
+// deleteRouteObjects deletes the objects of the route, if it has them
+func deleteRouteObjects(ctx context.Context, c client.Client, route client.Object, objs ...client.Object) error {
+	for _, obj := range objs {
+		if err := c.Get(ctx, client.ObjectKey{Namespace: route.GetNamespace(), Name: route.GetName()}, obj); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(obj, route) {
+			continue
+		}
+		if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeObjectMeta returns the metadata of the tunnel and edge of the route, which are named after it
+func routeObjectMeta(route client.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: route.GetName(), Namespace: route.GetNamespace()}
+}
+
+// routeRefs are the parent and backend refs of a TCPRoute or TLSRoute
+type routeRefs struct {
+	namespace string
+	name      string
+	parents   []gatewayv1.ParentReference
+	backends  []gatewayv1.BackendRef
+}
+
+// referencesGateway returns true if one of the route's parent refs is to the gateway
+func (r routeRefs) referencesGateway(gw client.Object) bool {
+	for _, parent := range r.parents {
+		if parent.Group != nil && *parent.Group != gatewayv1.GroupName {
+			continue
+		}
+		if parent.Kind != nil && *parent.Kind != "Gateway" {
+			continue
+		}
+		namespace := r.namespace
+		if parent.Namespace != nil {
+			namespace = string(*parent.Namespace)
+		}
+		if namespace == gw.GetNamespace() && string(parent.Name) == gw.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesNamespace returns true if one of the route's backend refs is to a service in the namespace, other
+// than the route's own
+func (r routeRefs) referencesNamespace(namespace string) bool {
+	for _, ref := range r.backends {
+		if ref.Namespace != nil && string(*ref.Namespace) == namespace && namespace != r.namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesService returns true if one of the route's backend refs is to the service
+func (r routeRefs) referencesService(svc client.Object) bool {
+	for _, ref := range r.backends {
+		if ref.Kind != nil && *ref.Kind != "Service" {
+			continue
+		}
+		namespace := r.namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		if namespace == svc.GetNamespace() && string(ref.Name) == svc.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// routeMapper maps the Services, ReferenceGrants, Gateways and GatewayClasses routes reference to the routes, so
+// they're reconciled again when a backend service is created, a grant allows or stops allowing a backend in
+// another namespace, or a gateway is moved to or from an ngrok GatewayClass
+type routeMapper struct {
+	client client.Client
+	log    logr.Logger
+	// list returns the refs of every route of the controller's kind
+	list func(ctx context.Context) ([]routeRefs, error)
+}
+
+// requests returns the requests for the routes the match func returns true for
+func (m routeMapper) requests(ctx context.Context, match func(routeRefs) bool) []reconcile.Request {
+	routes, err := m.list(ctx)
+	if err != nil {
+		m.log.Error(err, "failed to list routes")
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, route := range routes {
+		if match(route) {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: route.name, Namespace: route.namespace},
+			})
+		}
+	}
+	return recs
+}
+
+func (m routeMapper) routesForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	return m.requests(ctx, func(route routeRefs) bool {
+		return route.referencesService(obj)
+	})
+}
+
+func (m routeMapper) routesForReferenceGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	return m.requests(ctx, func(route routeRefs) bool {
+		return route.referencesNamespace(obj.GetNamespace())
+	})
+}
+
+func (m routeMapper) routesForGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	return m.requests(ctx, func(route routeRefs) bool {
+		return route.referencesGateway(obj)
+	})
+}
+
+func (m routeMapper) routesForGatewayClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	gateways := &gatewayv1.GatewayList{}
+	if err := m.client.List(ctx, gateways); err != nil {
+		m.log.Error(err, "failed to list gateways for gatewayclass", "name", obj.GetName())
+		return []reconcile.Request{}
+	}
+
+	return m.requests(ctx, func(route routeRefs) bool {
+		for _, gw := range gateways.Items {
+			if string(gw.Spec.GatewayClassName) == obj.GetName() && route.referencesGateway(&gw) {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -25,7 +25,10 @@ SOFTWARE.
 package gateway
 
 import (
+	"go/build"
+	"os"
 	"path/filepath"
+	"runtime/debug"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -37,6 +40,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -53,13 +61,39 @@ func TestAPIs(t *testing.T) {
 	RunSpecs(t, "Controller Suite")
 }
 
+// gatewayCRDPath returns the path of the experimental channel's gateway API CRDs, which include TCPRoute and
+// TLSRoute, in the module cache
+func gatewayCRDPath() string {
+	version := ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "sigs.k8s.io/gateway-api" {
+				version = dep.Version
+			}
+		}
+	}
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		modCache = filepath.Join(build.Default.GOPATH, "pkg", "mod")
+	}
+	return filepath.Join(modCache, "sigs.k8s.io", "gateway-api@"+version, "config", "crd", "experimental")
+}
+
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		// the specs that need the test environment are skipped, see requireTestEnv
+		return
+	}
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "config", "crd", "bases")},
-		ErrorIfCRDPathMissing: false,
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "helm", "ingress-controller", "templates", "crds"),
+			gatewayCRDPath(),
+		},
+		ErrorIfCRDPathMissing: true,
 	}
 
 	var err error
@@ -68,6 +102,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
+	Expect(gatewayv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(gatewayv1alpha2.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(gatewayv1beta1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(ingressv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
 	//+kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -76,7 +114,17 @@ var _ = BeforeSuite(func() {
 
 })
 
+// requireTestEnv skips the spec when the test environment isn't running, since KUBEBUILDER_ASSETS isn't set
+func requireTestEnv() {
+	if k8sClient == nil {
+		Skip("KUBEBUILDER_ASSETS isn't set, run the controller tests with make test")
+	}
+}
+
 var _ = AfterSuite(func() {
+	if testEnv == nil {
+		return
+	}
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
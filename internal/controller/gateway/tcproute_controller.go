@@ -0,0 +1,197 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+)
+
+// TCPRouteReconciler reconciles a TCPRoute object. For a TCPRoute attached to a Gateway of an ngrok
+// GatewayClass, it creates a Tunnel to the route's backend service and a TCPEdge for it, which reserves a TCP
+// address for the route. Both are owned by the TCPRoute, so they're deleted with it. Whether the route's backend
+// resolved is reported in the route's statuses for its ngrok parents.
+type TCPRouteReconciler struct {
+	client.Client
+
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tcproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tcproutes/status,verbs=get;update
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=tunnels,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=tcpedges,verbs=get;list;watch;create;update;delete
+
+func (r *TCPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("TCPRoute", req.NamespacedName)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	route := new(gatewayv1alpha2.TCPRoute)
+	if err := r.Client.Get(ctx, req.NamespacedName, route); err != nil {
+		// the tunnel and edge of a deleted route are garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !controllers.IsUpsert(route) {
+		return ctrl.Result{}, nil
+	}
+
+	parents, err := ngrokParentRefs(ctx, r.Client, route.Namespace, route.Spec.ParentRefs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(parents) == 0 {
+		log.V(1).Info("tcproute isn't attached to an ngrok gateway, removing its tunnel and edge")
+		if err := r.updateStatus(ctx, route, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, deleteRouteObjects(ctx, r.Client, route, &ingressv1alpha1.Tunnel{}, &ingressv1alpha1.TCPEdge{})
+	}
+
+	rules := make([][]gatewayv1alpha2.BackendRef, 0, len(route.Spec.Rules))
+	for _, rule := range route.Spec.Rules {
+		rules = append(rules, rule.BackendRefs)
+	}
+	refs, warning := firstRuleBackendRefs(rules)
+	if warning != "" {
+		log.Info(warning)
+		r.Recorder.Event(route, corev1.EventTypeWarning, "IgnoredBackendRefs", warning)
+	}
+
+	backend, err := resolveRouteBackend(ctx, r.Client, "TCPRoute", route.Namespace, refs)
+	var refsErr *routeRefsError
+	if err != nil && !errors.As(err, &refsErr) {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateStatus(ctx, route, parents, routeAccepted, resolvedRefsCondition(refsErr)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if refsErr != nil {
+		log.Error(refsErr, "Failed to resolve tcproute backend")
+		r.Recorder.Event(route, corev1.EventTypeWarning, string(refsErr.reason), refsErr.Error())
+		// the service watch reconciles the route when its backend is created, but retry in the meantime
+		if apierrors.IsNotFound(refsErr) {
+			return ctrl.Result{}, refsErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := reconcileRouteTunnel(ctx, r.Client, r.Scheme, route, backend); err != nil {
+		log.Error(err, "Failed to create or update tunnel")
+		return ctrl.Result{}, err
+	}
+
+	edge := &ingressv1alpha1.TCPEdge{ObjectMeta: routeObjectMeta(route)}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, edge, func() error {
+		edge.Spec.Backend.Labels = backend.labels()
+		return controllerutil.SetControllerReference(route, edge, r.Scheme)
+	}); err != nil {
+		log.Error(err, "Failed to create or update tcp edge")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// updateStatus sets the conditions of the route's statuses for its ngrok parents
+func (r *TCPRouteReconciler) updateStatus(ctx context.Context, route *gatewayv1alpha2.TCPRoute, parents []gatewayv1alpha2.ParentReference, conditions ...metav1.Condition) error {
+	statuses := routeParentStatuses(route.Status.Parents, parents, route.Generation, conditions...)
+	if reflect.DeepEqual(route.Status.Parents, statuses) {
+		return nil
+	}
+	route.Status.Parents = statuses
+	if err := r.Client.Status().Update(ctx, route); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to update tcproute status")
+		return err
+	}
+	return nil
+}
+
+// listRouteRefs returns the refs of every TCPRoute, for the routeMapper
+func (r *TCPRouteReconciler) listRouteRefs(ctx context.Context) ([]routeRefs, error) {
+	routes := &gatewayv1alpha2.TCPRouteList{}
+	if err := r.Client.List(ctx, routes); err != nil {
+		return nil, err
+	}
+
+	refs := make([]routeRefs, 0, len(routes.Items))
+	for _, route := range routes.Items {
+		ref := routeRefs{namespace: route.Namespace, name: route.Name, parents: route.Spec.ParentRefs}
+		for _, rule := range route.Spec.Rules {
+			ref.backends = append(ref.backends, rule.BackendRefs...)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TCPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	mapper := routeMapper{client: r.Client, log: r.Log, list: r.listRouteRefs}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayv1alpha2.TCPRoute{}).
+		Owns(&ingressv1alpha1.Tunnel{}).
+		Owns(&ingressv1alpha1.TCPEdge{}).
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(mapper.routesForService),
+		).
+		Watches(
+			&gatewayv1beta1.ReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(mapper.routesForReferenceGrant),
+		).
+		Watches(
+			&gatewayv1.Gateway{},
+			handler.EnqueueRequestsFromMapFunc(mapper.routesForGateway),
+		).
+		Watches(
+			&gatewayv1.GatewayClass{},
+			handler.EnqueueRequestsFromMapFunc(mapper.routesForGatewayClass),
+		).
+		Complete(r)
+}
@@ -0,0 +1,269 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+// testNamespaces is the number of namespaces created by the tests, so each test gets a namespace of its own
+var testNamespaces int
+
+// createTestNamespace creates a namespace for the objects of a test
+func createTestNamespace(ctx context.Context) string {
+	testNamespaces++
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("gateway-test-%d", testNamespaces)}}
+	Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+	return ns.Name
+}
+
+// createTestGateway creates a gateway of a GatewayClass with the controller name in the namespace
+func createTestGateway(ctx context.Context, namespace, name string, controllerName gatewayv1.GatewayController) *gatewayv1.Gateway {
+	gwClass := &gatewayv1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace + "-" + name},
+		Spec:       gatewayv1.GatewayClassSpec{ControllerName: controllerName},
+	}
+	Expect(k8sClient.Create(ctx, gwClass)).To(Succeed())
+
+	gw := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: gatewayv1.ObjectName(gwClass.Name),
+			Listeners: []gatewayv1.Listener{{
+				Name:     "tcp",
+				Protocol: gatewayv1.TCPProtocolType,
+				Port:     9000,
+			}},
+		},
+	}
+	Expect(k8sClient.Create(ctx, gw)).To(Succeed())
+	return gw
+}
+
+// createTestService creates a service with a single port in the namespace
+func createTestService(ctx context.Context, namespace, name string) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "tcp", Port: 5432}},
+		},
+	}
+	Expect(k8sClient.Create(ctx, svc)).To(Succeed())
+	return svc
+}
+
+// testBackendRef returns a backendRef to port 5432 of the service
+func testBackendRef(name string) gatewayv1alpha2.BackendRef {
+	return gatewayv1alpha2.BackendRef{
+		BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+			Name: gatewayv1alpha2.ObjectName(name),
+			Port: ptr.To(gatewayv1alpha2.PortNumber(5432)),
+		},
+	}
+}
+
+// routeCondition returns the condition of the type from the route's status for its ngrok parent
+func routeCondition(parents []gatewayv1.RouteParentStatus, conditionType gatewayv1.RouteConditionType) *metav1.Condition {
+	for _, parent := range parents {
+		if parent.ControllerName == ControllerName {
+			return meta.FindStatusCondition(parent.Conditions, string(conditionType))
+		}
+	}
+	return nil
+}
+
+// requestNames returns the names of the objects of the requests
+func requestNames(reqs []reconcile.Request) []types.NamespacedName {
+	names := []types.NamespacedName{}
+	for _, req := range reqs {
+		names = append(names, req.NamespacedName)
+	}
+	return names
+}
+
+var _ = Describe("TCPRouteReconciler", func() {
+	var (
+		ctx       context.Context
+		namespace string
+		recorder  *record.FakeRecorder
+		r         *TCPRouteReconciler
+		route     *gatewayv1alpha2.TCPRoute
+	)
+
+	BeforeEach(func() {
+		requireTestEnv()
+		ctx = context.Background()
+		namespace = createTestNamespace(ctx)
+		recorder = record.NewFakeRecorder(100)
+		r = &TCPRouteReconciler{
+			Client:   k8sClient,
+			Log:      logf.Log.WithName("tcproute"),
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+
+		createTestGateway(ctx, namespace, "ngrok", ControllerName)
+		route = &gatewayv1alpha2.TCPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: namespace},
+			Spec: gatewayv1alpha2.TCPRouteSpec{
+				CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{
+					ParentRefs: []gatewayv1alpha2.ParentReference{{Name: "ngrok"}},
+				},
+				Rules: []gatewayv1alpha2.TCPRouteRule{{
+					BackendRefs: []gatewayv1alpha2.BackendRef{testBackendRef("postgres")},
+				}},
+			},
+		}
+	})
+
+	reconcileRoute := func() error {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(route)})
+		return err
+	}
+
+	getRoute := func() *gatewayv1alpha2.TCPRoute {
+		current := &gatewayv1alpha2.TCPRoute{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), current)).To(Succeed())
+		return current
+	}
+
+	It("Should create a tunnel and tcp edge for a route of an ngrok gateway", func() {
+		svc := createTestService(ctx, namespace, "postgres")
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+
+		tunnel := &ingressv1alpha1.Tunnel{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), tunnel)).To(Succeed())
+		Expect(tunnel.Spec.ForwardsTo).To(Equal(fmt.Sprintf("postgres.%s.svc.cluster.local:5432", namespace)))
+
+		edge := &ingressv1alpha1.TCPEdge{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), edge)).To(Succeed())
+		Expect(edge.Spec.Backend.Labels).To(HaveKeyWithValue("k8s.ngrok.com/service-uid", string(svc.UID)))
+		Expect(metav1.IsControlledBy(edge, getRoute())).To(BeTrue())
+
+		current := getRoute()
+		accepted := routeCondition(current.Status.Parents, gatewayv1.RouteConditionAccepted)
+		Expect(accepted).ToNot(BeNil())
+		Expect(accepted.Status).To(Equal(metav1.ConditionTrue))
+		Expect(accepted.ObservedGeneration).To(Equal(current.Generation))
+		resolvedRefs := routeCondition(current.Status.Parents, gatewayv1.RouteConditionResolvedRefs)
+		Expect(resolvedRefs).ToNot(BeNil())
+		Expect(resolvedRefs.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should retry a route until its backend service exists", func() {
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		err := reconcileRoute()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		resolvedRefs := routeCondition(getRoute().Status.Parents, gatewayv1.RouteConditionResolvedRefs)
+		Expect(resolvedRefs).ToNot(BeNil())
+		Expect(resolvedRefs.Status).To(Equal(metav1.ConditionFalse))
+		Expect(resolvedRefs.Reason).To(Equal(string(gatewayv1.RouteReasonBackendNotFound)))
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &ingressv1alpha1.TCPEdge{}))).To(BeTrue())
+
+		createTestService(ctx, namespace, "postgres")
+		Expect(reconcileRoute()).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &ingressv1alpha1.TCPEdge{})).To(Succeed())
+		resolvedRefs = routeCondition(getRoute().Status.Parents, gatewayv1.RouteConditionResolvedRefs)
+		Expect(resolvedRefs.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should only use a service in another namespace when a ReferenceGrant allows it", func() {
+		otherNamespace := createTestNamespace(ctx)
+		createTestService(ctx, otherNamespace, "postgres")
+		route.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1alpha2.Namespace(otherNamespace))
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+		resolvedRefs := routeCondition(getRoute().Status.Parents, gatewayv1.RouteConditionResolvedRefs)
+		Expect(resolvedRefs).ToNot(BeNil())
+		Expect(resolvedRefs.Status).To(Equal(metav1.ConditionFalse))
+		Expect(resolvedRefs.Reason).To(Equal(string(gatewayv1.RouteReasonRefNotPermitted)))
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &ingressv1alpha1.TCPEdge{}))).To(BeTrue())
+
+		grant := &gatewayv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "tcproutes", Namespace: otherNamespace},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{
+					Group:     gatewayv1.GroupName,
+					Kind:      "TCPRoute",
+					Namespace: gatewayv1.Namespace(namespace),
+				}},
+				To: []gatewayv1beta1.ReferenceGrantTo{{Kind: "Service"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, grant)).To(Succeed())
+		mapper := routeMapper{client: k8sClient, log: r.Log, list: r.listRouteRefs}
+		Expect(requestNames(mapper.routesForReferenceGrant(ctx, grant))).To(ConsistOf(client.ObjectKeyFromObject(route)))
+
+		Expect(reconcileRoute()).To(Succeed())
+		tunnel := &ingressv1alpha1.Tunnel{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), tunnel)).To(Succeed())
+		Expect(tunnel.Spec.ForwardsTo).To(Equal(fmt.Sprintf("postgres.%s.svc.cluster.local:5432", otherNamespace)))
+		resolvedRefs = routeCondition(getRoute().Status.Parents, gatewayv1.RouteConditionResolvedRefs)
+		Expect(resolvedRefs.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should warn about the rules and backendRefs it ignores", func() {
+		createTestService(ctx, namespace, "postgres")
+		route.Spec.Rules[0].BackendRefs = append(route.Spec.Rules[0].BackendRefs, testBackendRef("replica"))
+		route.Spec.Rules = append(route.Spec.Rules, gatewayv1alpha2.TCPRouteRule{
+			BackendRefs: []gatewayv1alpha2.BackendRef{testBackendRef("other")},
+		})
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("IgnoredBackendRefs")))
+	})
+
+	It("Should leave routes of other gateways alone", func() {
+		createTestGateway(ctx, namespace, "other", "example.com/gateway-controller")
+		createTestService(ctx, namespace, "postgres")
+		route.Spec.ParentRefs = []gatewayv1alpha2.ParentReference{{Name: "other"}}
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &ingressv1alpha1.TCPEdge{}))).To(BeTrue())
+		Expect(getRoute().Status.Parents).To(BeEmpty())
+	})
+
+	It("Should map the services, gateways, and gatewayclasses a route references to the route", func() {
+		svc := createTestService(ctx, namespace, "postgres")
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+		mapper := routeMapper{client: k8sClient, log: r.Log, list: r.listRouteRefs}
+		routeName := client.ObjectKeyFromObject(route)
+
+		Expect(requestNames(mapper.routesForService(ctx, svc))).To(ConsistOf(routeName))
+		Expect(requestNames(mapper.routesForService(ctx, createTestService(ctx, namespace, "unused")))).To(BeEmpty())
+
+		gw := &gatewayv1.Gateway{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "ngrok"}, gw)).To(Succeed())
+		Expect(requestNames(mapper.routesForGateway(ctx, gw))).To(ConsistOf(routeName))
+
+		gwClass := &gatewayv1.GatewayClass{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: string(gw.Spec.GatewayClassName)}, gwClass)).To(Succeed())
+		Expect(requestNames(mapper.routesForGatewayClass(ctx, gwClass))).To(ConsistOf(routeName))
+	})
+})
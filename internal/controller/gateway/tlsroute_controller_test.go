@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+var _ = Describe("TLSRouteReconciler", func() {
+	var (
+		ctx       context.Context
+		namespace string
+		r         *TLSRouteReconciler
+		route     *gatewayv1alpha2.TLSRoute
+	)
+
+	BeforeEach(func() {
+		requireTestEnv()
+		ctx = context.Background()
+		namespace = createTestNamespace(ctx)
+		r = &TLSRouteReconciler{
+			Client:   k8sClient,
+			Log:      logf.Log.WithName("tlsroute"),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(100),
+		}
+
+		createTestGateway(ctx, namespace, "ngrok", ControllerName)
+		route = &gatewayv1alpha2.TLSRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: namespace},
+			Spec: gatewayv1alpha2.TLSRouteSpec{
+				CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{
+					ParentRefs: []gatewayv1alpha2.ParentReference{{Name: "ngrok"}},
+				},
+				Hostnames: []gatewayv1alpha2.Hostname{"db.example.com", "replica.example.com"},
+				Rules: []gatewayv1alpha2.TLSRouteRule{{
+					BackendRefs: []gatewayv1alpha2.BackendRef{testBackendRef("postgres")},
+				}},
+			},
+		}
+	})
+
+	reconcileRoute := func() error {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(route)})
+		return err
+	}
+
+	getEdge := func() (*ingressv1alpha1.TLSEdge, error) {
+		edge := &ingressv1alpha1.TLSEdge{}
+		return edge, k8sClient.Get(ctx, client.ObjectKeyFromObject(route), edge)
+	}
+
+	It("Should create a passthrough tls edge on port 443 of the route's hostnames", func() {
+		createTestService(ctx, namespace, "postgres")
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+
+		edge, err := getEdge()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(edge.Spec.Hostports).To(Equal([]string{"db.example.com:443", "replica.example.com:443"}))
+		Expect(edge.Spec.TLSTermination).To(BeNil())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &ingressv1alpha1.Tunnel{})).To(Succeed())
+	})
+
+	It("Should update the edge's hostports when the route's hostnames change", func() {
+		createTestService(ctx, namespace, "postgres")
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+		Expect(reconcileRoute()).To(Succeed())
+
+		// the reconcile updated the route's status, so update the current route
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), route)).To(Succeed())
+		route.Spec.Hostnames = []gatewayv1alpha2.Hostname{"db.example.com"}
+		Expect(k8sClient.Update(ctx, route)).To(Succeed())
+		Expect(reconcileRoute()).To(Succeed())
+
+		edge, err := getEdge()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(edge.Spec.Hostports).To(Equal([]string{"db.example.com:443"}))
+	})
+
+	It("Should not accept a route without hostnames", func() {
+		createTestService(ctx, namespace, "postgres")
+		route.Spec.Hostnames = nil
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		Expect(reconcileRoute()).To(Succeed())
+
+		_, err := getEdge()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		current := &gatewayv1alpha2.TLSRoute{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(route), current)).To(Succeed())
+		accepted := routeCondition(current.Status.Parents, gatewayv1.RouteConditionAccepted)
+		Expect(accepted).ToNot(BeNil())
+		Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+		Expect(accepted.Reason).To(Equal(string(gatewayv1.RouteReasonUnsupportedValue)))
+	})
+
+	It("Should retry a route until its backend service exists", func() {
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+
+		err := reconcileRoute()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = getEdge()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		createTestService(ctx, namespace, "postgres")
+		Expect(reconcileRoute()).To(Succeed())
+		_, err = getEdge()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Should map the services and gateways a route references to the route", func() {
+		svc := createTestService(ctx, namespace, "postgres")
+		Expect(k8sClient.Create(ctx, route)).To(Succeed())
+		mapper := routeMapper{client: k8sClient, log: r.Log, list: r.listRouteRefs}
+		routeName := client.ObjectKeyFromObject(route)
+
+		Expect(requestNames(mapper.routesForService(ctx, svc))).To(ConsistOf(routeName))
+
+		gw := &gatewayv1.Gateway{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "ngrok"}, gw)).To(Succeed())
+		Expect(requestNames(mapper.routesForGateway(ctx, gw))).To(ConsistOf(routeName))
+	})
+})
+
+var _ = Describe("tlsRouteHostports", func() {
+	It("Should return port 443 of each hostname", func() {
+		Expect(tlsRouteHostports([]gatewayv1alpha2.Hostname{"a.example.com", "*.b.example.com"})).
+			To(Equal([]string{"a.example.com:443", "*.b.example.com:443"}))
+	})
+
+	It("Should return no hostports without hostnames", func() {
+		Expect(tlsRouteHostports(nil)).To(BeEmpty())
+	})
+})
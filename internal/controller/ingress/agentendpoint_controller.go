@@ -0,0 +1,130 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/pkg/tunneldriver"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// AgentEndpointReconciler reconciles an AgentEndpoint object
+type AgentEndpointReconciler struct {
+	client.Client
+
+	Log          logr.Logger
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	TunnelDriver *tunneldriver.TunnelDriver
+
+	controller *baseController[*ngrokv1alpha1.AgentEndpoint]
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *AgentEndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.TunnelDriver == nil {
+		return fmt.Errorf("TunnelDriver is nil")
+	}
+
+	r.controller = &baseController[*ngrokv1alpha1.AgentEndpoint]{
+		Kube:     r.Client,
+		Log:      r.Log,
+		Recorder: r.Recorder,
+
+		kubeType: "v1alpha1.AgentEndpoint",
+		update:   r.update,
+		delete:   r.delete,
+		statusID: r.statusID,
+	}
+
+	// Unlike tunnels, only one agent can have an endpoint online on a URL, so agent endpoints are only started by
+	// the leader's agent
+	cont, err := controller.NewUnmanaged("agent-endpoint-controller", mgr, controller.Options{
+		Reconciler: r,
+		LogConstructor: func(_ *reconcile.Request) logr.Logger {
+			return r.Log
+		},
+		NeedLeaderElection: ptr.To(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cont.Watch(
+		source.Kind(mgr.GetCache(), &ngrokv1alpha1.AgentEndpoint{}),
+		&handler.EnqueueRequestForObject{},
+		commonPredicateFilters,
+	); err != nil {
+		return err
+	}
+
+	return mgr.Add(cont)
+}
+
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=agentendpoints,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=agentendpoints/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=agentendpoints/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.1/pkg/reconcile
+func (r *AgentEndpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.controller.reconcile(ctx, req, new(ngrokv1alpha1.AgentEndpoint))
+}
+
+func (r *AgentEndpointReconciler) update(ctx context.Context, endpoint *ngrokv1alpha1.AgentEndpoint) error {
+	tun, err := r.TunnelDriver.CreateAgentEndpoint(ctx, r.statusID(endpoint), endpoint.Spec)
+	if err != nil {
+		return err
+	}
+
+	if endpoint.Status.URL == tun.URL() {
+		return nil
+	}
+	endpoint.Status.URL = tun.URL()
+	return r.Status().Update(ctx, endpoint)
+}
+
+func (r *AgentEndpointReconciler) delete(ctx context.Context, endpoint *ngrokv1alpha1.AgentEndpoint) error {
+	return r.TunnelDriver.DeleteAgentEndpoint(ctx, r.statusID(endpoint))
+}
+
+func (r *AgentEndpointReconciler) statusID(endpoint *ngrokv1alpha1.AgentEndpoint) string {
+	return fmt.Sprintf("%s/%s", endpoint.Namespace, endpoint.Name)
+}
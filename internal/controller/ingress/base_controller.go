@@ -9,6 +9,8 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
 	"github.com/ngrok/ngrok-api-go/v5"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -35,6 +37,11 @@ type baseController[T client.Object] struct {
 	update    func(ctx context.Context, cr T) error
 	delete    func(ctx context.Context, cr T) error
 	errResult func(op baseControllerOp, cr T, err error) (ctrl.Result, error)
+	// upToDate returns true if an existing object has nothing to reconcile, so the update is skipped
+	upToDate func(cr T) bool
+	// requeueAfter returns how long to wait before reconciling an object that was created or updated again,
+	// for objects waiting on something no watch reports. Zero waits for the object's next change.
+	requeueAfter func(cr T) time.Duration
 }
 
 func (r *baseController[T]) reconcile(ctx context.Context, req ctrl.Request, cr T) (ctrl.Result, error) {
@@ -46,59 +53,72 @@ func (r *baseController[T]) reconcile(ctx context.Context, req ctrl.Request, cr
 	}
 
 	crName := req.NamespacedName.String()
-	if controllers.IsUpsert(cr) {
-		if err := controllers.RegisterAndSyncFinalizer(ctx, r.Kube, cr); err != nil {
-			return ctrl.Result{}, err
+	if deleting, err := controllers.HandleDeletion(ctx, r.Kube, cr, func(ctx context.Context) error {
+		return r.cleanup(ctx, cr, crName)
+	}); deleting {
+		if err != nil {
+			if r.errResult != nil {
+				return r.errResult(deleteOp, cr, err)
+			}
+			return reconcileResultFromError(err)
 		}
+		return ctrl.Result{}, nil
+	}
 
-		if r.statusID != nil && r.statusID(cr) == "" {
-			r.Recorder.Event(cr, v1.EventTypeNormal, "Creating", fmt.Sprintf("Creating %s: %s", r.kubeType, crName))
-			if err := r.create(ctx, cr); err != nil {
-				r.Recorder.Event(cr, v1.EventTypeWarning, "CreateError", fmt.Sprintf("Failed to create %s %s: %s", r.kubeType, crName, err.Error()))
-				if r.errResult != nil {
-					return r.errResult(createOp, cr, err)
-				}
-				return reconcileResultFromError(err)
-			}
-			r.Recorder.Event(cr, v1.EventTypeNormal, "Created", fmt.Sprintf("Created %s: %s", r.kubeType, crName))
-		} else {
-			r.Recorder.Event(cr, v1.EventTypeNormal, "Updating", fmt.Sprintf("Updating %s: %s", r.kubeType, crName))
-			if err := r.update(ctx, cr); err != nil {
-				r.Recorder.Event(cr, v1.EventTypeWarning, "UpdateError", fmt.Sprintf("Failed to update %s %s: %s", r.kubeType, crName, err.Error()))
-				if r.errResult != nil {
-					return r.errResult(updateOp, cr, err)
-				}
-				return reconcileResultFromError(err)
+	if err := controllers.RegisterAndSyncFinalizer(ctx, r.Kube, cr); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.statusID != nil && r.statusID(cr) == "" {
+		r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonCreating, fmt.Sprintf("Creating %s: %s", r.kubeType, crName))
+		if err := r.create(ctx, cr); err != nil {
+			r.Recorder.Event(cr, v1.EventTypeWarning, events.ReasonCreateError, fmt.Sprintf("Failed to create %s %s: %s", r.kubeType, crName, err.Error()))
+			if r.errResult != nil {
+				return r.errResult(createOp, cr, err)
 			}
-			r.Recorder.Event(cr, v1.EventTypeNormal, "Updated", fmt.Sprintf("Updated %s: %s", r.kubeType, crName))
+			return reconcileResultFromError(err)
 		}
+		r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonCreated, fmt.Sprintf("Created %s: %s", r.kubeType, crName))
+	} else if r.upToDate != nil && r.upToDate(cr) {
+		ctrl.LoggerFrom(ctx).V(1).Info(fmt.Sprintf("%s is up to date, skipping update", r.kubeType))
 	} else {
-		if controllers.HasFinalizer(cr) {
-			if r.statusID != nil && r.statusID(cr) != "" {
-				sid := r.statusID(cr)
-				r.Recorder.Event(cr, v1.EventTypeNormal, "Deleting", fmt.Sprintf("Deleting %s: %s", r.kubeType, crName))
-				if err := r.delete(ctx, cr); err != nil {
-					if !ngrok.IsNotFound(err) {
-						r.Recorder.Event(cr, v1.EventTypeWarning, "DeleteError", fmt.Sprintf("Failed to delete %s %s: %s", r.kubeType, crName, err.Error()))
-						if r.errResult != nil {
-							return r.errResult(deleteOp, cr, err)
-						}
-						return reconcileResultFromError(err)
-					}
-					log.Info(fmt.Sprintf("%s not found, assuming it was already deleted", r.kubeType), "ID", sid)
-				}
-				r.Recorder.Event(cr, v1.EventTypeNormal, "Deleted", fmt.Sprintf("Deleted %s: %s", r.kubeType, crName))
-			}
-
-			if err := controllers.RemoveAndSyncFinalizer(ctx, r.Kube, cr); err != nil {
-				return ctrl.Result{}, err
+		r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonUpdating, fmt.Sprintf("Updating %s: %s", r.kubeType, crName))
+		if err := r.update(ctx, cr); err != nil {
+			r.Recorder.Event(cr, v1.EventTypeWarning, events.ReasonUpdateError, fmt.Sprintf("Failed to update %s %s: %s", r.kubeType, crName, err.Error()))
+			if r.errResult != nil {
+				return r.errResult(updateOp, cr, err)
 			}
+			return reconcileResultFromError(err)
 		}
+		r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonUpdated, fmt.Sprintf("Updated %s: %s", r.kubeType, crName))
 	}
 
+	if r.requeueAfter != nil {
+		return ctrl.Result{RequeueAfter: r.requeueAfter(cr)}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// cleanup deletes the ngrok resource of an object that is being deleted. Resources that are already gone
+// are considered deleted.
+func (r *baseController[T]) cleanup(ctx context.Context, cr T, crName string) error {
+	if r.statusID == nil || r.statusID(cr) == "" {
+		return nil
+	}
+
+	sid := r.statusID(cr)
+	r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonDeleting, fmt.Sprintf("Deleting %s: %s", r.kubeType, crName))
+	if err := r.delete(ctx, cr); err != nil {
+		if !ngrok.IsNotFound(err) {
+			r.Recorder.Event(cr, v1.EventTypeWarning, events.ReasonDeleteError, fmt.Sprintf("Failed to delete %s %s: %s", r.kubeType, crName, err.Error()))
+			return err
+		}
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("%s not found, assuming it was already deleted", r.kubeType), "ID", sid)
+	}
+	r.Recorder.Event(cr, v1.EventTypeNormal, events.ReasonDeleted, fmt.Sprintf("Deleted %s: %s", r.kubeType, crName))
+	return nil
+}
+
 func reconcileResultFromError(err error) (ctrl.Result, error) {
 	var nerr *ngrok.Error
 	if errors.As(err, &nerr) {
@@ -106,7 +126,7 @@ func reconcileResultFromError(err error) (ctrl.Result, error) {
 		case nerr.StatusCode >= 500:
 			return ctrl.Result{}, err
 		case nerr.StatusCode == http.StatusTooManyRequests:
-			return ctrl.Result{RequeueAfter: time.Minute}, nil
+			return ctrl.Result{RequeueAfter: max(ngrokapi.RetryAfter(err), time.Minute)}, nil
 		default:
 			// the rest are client errors, we don't retry by default
 			return ctrl.Result{}, nil
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+)
+
+var _ = Describe("baseController", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+
+	var (
+		ctx                         context.Context
+		creates, updates, deletes   int
+		req                         ctrl.Request
+		newController               func(c client.Client) *baseController[*ingressv1alpha1.Domain]
+		newDomain                   func() *ingressv1alpha1.Domain
+		deletingDomainWithFinalizer func() *ingressv1alpha1.Domain
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		creates, updates, deletes = 0, 0, 0
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "example-com", Namespace: "test-namespace"}}
+
+		newController = func(c client.Client) *baseController[*ingressv1alpha1.Domain] {
+			return &baseController[*ingressv1alpha1.Domain]{
+				Kube:     c,
+				Log:      logr.Discard(),
+				Recorder: record.NewFakeRecorder(10),
+
+				kubeType: "v1alpha1.Domain",
+				statusID: func(cr *ingressv1alpha1.Domain) string { return cr.Status.ID },
+				create:   func(ctx context.Context, cr *ingressv1alpha1.Domain) error { creates++; return nil },
+				update:   func(ctx context.Context, cr *ingressv1alpha1.Domain) error { updates++; return nil },
+				delete:   func(ctx context.Context, cr *ingressv1alpha1.Domain) error { deletes++; return nil },
+			}
+		}
+
+		newDomain = func() *ingressv1alpha1.Domain {
+			return &ingressv1alpha1.Domain{
+				ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+				Spec:       ingressv1alpha1.DomainSpec{Domain: "example.com"},
+			}
+		}
+
+		deletingDomainWithFinalizer = func() *ingressv1alpha1.Domain {
+			domain := newDomain()
+			domain.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+			controllers.AddFinalizer(domain)
+			domain.Status.ID = "rd_123"
+			return domain
+		}
+	})
+
+	It("Should run cleanup and skip creation for an object with a deletion timestamp", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingDomainWithFinalizer()).Build()
+
+		result, err := newController(c).reconcile(ctx, req, new(ingressv1alpha1.Domain))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(deletes).To(Equal(1))
+		Expect(creates).To(Equal(0))
+		Expect(updates).To(Equal(0))
+
+		// removing the finalizer lets the object be deleted
+		err = c.Get(ctx, req.NamespacedName, &ingressv1alpha1.Domain{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should keep the finalizer when cleanup fails", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingDomainWithFinalizer()).Build()
+		controller := newController(c)
+		controller.delete = func(ctx context.Context, cr *ingressv1alpha1.Domain) error {
+			deletes++
+			return apierrors.NewServiceUnavailable("unavailable")
+		}
+
+		_, err := controller.reconcile(ctx, req, new(ingressv1alpha1.Domain))
+		Expect(err).To(HaveOccurred())
+		Expect(deletes).To(Equal(1))
+		Expect(creates).To(Equal(0))
+		Expect(updates).To(Equal(0))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(controllers.HasFinalizer(domain)).To(BeTrue())
+	})
+
+	It("Should create an object without a deletion timestamp", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDomain()).Build()
+
+		_, err := newController(c).reconcile(ctx, req, new(ingressv1alpha1.Domain))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creates).To(Equal(1))
+		Expect(deletes).To(Equal(0))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(controllers.HasFinalizer(domain)).To(BeTrue())
+	})
+})
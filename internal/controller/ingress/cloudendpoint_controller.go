@@ -0,0 +1,246 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+	"github.com/ngrok/ngrok-api-go/v5"
+)
+
+// errConflictingTrafficPolicies is returned for a CloudEndpoint that sets both a traffic policy name and an inline policy
+var errConflictingTrafficPolicies = errors.New("only one of trafficPolicyName and trafficPolicy may be set")
+
+// CloudEndpointReconciler reconciles a CloudEndpoint object
+type CloudEndpointReconciler struct {
+	client.Client
+
+	Log                  logr.Logger
+	Scheme               *runtime.Scheme
+	Recorder             record.EventRecorder
+	CloudEndpointsClient *ngrokapi.CloudEndpointsClient
+
+	controller *baseController[*ngrokv1alpha1.CloudEndpoint]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CloudEndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.CloudEndpointsClient == nil {
+		return fmt.Errorf("CloudEndpointsClient must be set")
+	}
+
+	r.controller = &baseController[*ngrokv1alpha1.CloudEndpoint]{
+		Kube:     r.Client,
+		Log:      r.Log,
+		Recorder: r.Recorder,
+
+		kubeType: "v1alpha1.CloudEndpoint",
+		statusID: func(cr *ngrokv1alpha1.CloudEndpoint) string { return cr.Status.ID },
+		create:   r.create,
+		update:   r.update,
+		delete:   r.delete,
+		errResult: func(op baseControllerOp, cr *ngrokv1alpha1.CloudEndpoint, err error) (reconcile.Result, error) {
+			// Retrying won't help until the endpoint's spec or its traffic policy is fixed, which triggers
+			// another reconcile
+			if errors.Is(err, errConflictingTrafficPolicies) || errors.Is(err, ingressv1alpha1.ErrInvalidTrafficPolicy) {
+				return ctrl.Result{}, nil
+			}
+			return reconcileResultFromError(err)
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ngrokv1alpha1.CloudEndpoint{}).
+		Watches(
+			&ngrokv1alpha1.NgrokTrafficPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.listCloudEndpointsForTrafficPolicy),
+		).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=cloudendpoints,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=cloudendpoints/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=cloudendpoints/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.1/pkg/reconcile
+func (r *CloudEndpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.controller.reconcile(ctx, req, new(ngrokv1alpha1.CloudEndpoint))
+}
+
+func (r *CloudEndpointReconciler) create(ctx context.Context, endpoint *ngrokv1alpha1.CloudEndpoint) error {
+	policy, err := r.trafficPolicy(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.CloudEndpointsClient.Create(ctx, &ngrokapi.CloudEndpointCreate{
+		URL:           endpoint.Spec.URL,
+		Description:   endpoint.Spec.Description,
+		Metadata:      endpoint.Spec.Metadata,
+		TrafficPolicy: policy,
+	})
+	if err != nil {
+		return err
+	}
+	ctrl.LoggerFrom(ctx).Info("Created cloud endpoint", "ID", resp.ID, "url", resp.URL)
+
+	return r.updateStatus(ctx, endpoint, resp, policy)
+}
+
+func (r *CloudEndpointReconciler) update(ctx context.Context, endpoint *ngrokv1alpha1.CloudEndpoint) error {
+	policy, err := r.trafficPolicy(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.CloudEndpointsClient.Get(ctx, endpoint.Status.ID)
+	if err != nil {
+		// If the endpoint was deleted outside of the controller, clear the ID and requeue to recreate it
+		if ngrok.IsNotFound(err) {
+			ctrl.LoggerFrom(ctx).Info("Cloud endpoint not found, clearing ID and requeuing", "ID", endpoint.Status.ID)
+			endpoint.Status.ID = ""
+			//nolint:errcheck
+			r.Status().Update(ctx, endpoint)
+		}
+		return err
+	}
+
+	// ngrok may reformat the policy it returns, so it's compared to the policy last applied rather than to resp
+	if resp.URL != endpoint.Spec.URL ||
+		resp.Description != endpoint.Spec.Description ||
+		resp.Metadata != endpoint.Spec.Metadata ||
+		!trafficPoliciesEqual(policy, string(endpoint.Status.TrafficPolicy)) {
+		resp, err = r.CloudEndpointsClient.Update(ctx, &ngrokapi.CloudEndpointUpdate{
+			ID:            endpoint.Status.ID,
+			URL:           &endpoint.Spec.URL,
+			Description:   &endpoint.Spec.Description,
+			Metadata:      &endpoint.Spec.Metadata,
+			TrafficPolicy: &policy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.updateStatus(ctx, endpoint, resp, policy)
+}
+
+func (r *CloudEndpointReconciler) delete(ctx context.Context, endpoint *ngrokv1alpha1.CloudEndpoint) error {
+	err := r.CloudEndpointsClient.Delete(ctx, endpoint.Status.ID)
+	if err == nil || ngrok.IsNotFound(err) {
+		endpoint.Status.ID = ""
+	}
+	return err
+}
+
+// trafficPolicy returns the traffic policy the endpoint should apply, either its inline policy or the policy of
+// the NgrokTrafficPolicy it names. It returns an error if the policy isn't valid.
+func (r *CloudEndpointReconciler) trafficPolicy(ctx context.Context, endpoint *ngrokv1alpha1.CloudEndpoint) (string, error) {
+	policy := endpoint.Spec.TrafficPolicy
+	if name := endpoint.Spec.TrafficPolicyName; name != "" {
+		if len(policy) > 0 {
+			return "", errConflictingTrafficPolicies
+		}
+
+		trafficPolicy := &ngrokv1alpha1.NgrokTrafficPolicy{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: endpoint.Namespace, Name: name}, trafficPolicy); err != nil {
+			return "", fmt.Errorf("getting NgrokTrafficPolicy %s/%s: %w", endpoint.Namespace, name, err)
+		}
+		policy = trafficPolicy.Spec.Policy
+	}
+
+	if err := ingressv1alpha1.ValidateTrafficPolicy(policy); err != nil {
+		return "", err
+	}
+	return string(policy), nil
+}
+
+func (r *CloudEndpointReconciler) updateStatus(ctx context.Context, endpoint *ngrokv1alpha1.CloudEndpoint, remote *ngrokapi.CloudEndpoint, policy string) error {
+	if endpoint.Status.ID == remote.ID && endpoint.Status.URI == remote.URI && trafficPoliciesEqual(string(endpoint.Status.TrafficPolicy), policy) {
+		return nil
+	}
+
+	endpoint.Status.ID = remote.ID
+	endpoint.Status.URI = remote.URI
+	endpoint.Status.TrafficPolicy = nil
+	if policy != "" {
+		endpoint.Status.TrafficPolicy = []byte(policy)
+	}
+	return r.Status().Update(ctx, endpoint)
+}
+
+// trafficPoliciesEqual returns true if the JSON encoded policies are equal regardless of formatting and key order,
+// since the status stores the policy as an object and doesn't keep its formatting
+func trafficPoliciesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var aPolicy, bPolicy any
+	if json.Unmarshal([]byte(a), &aPolicy) != nil || json.Unmarshal([]byte(b), &bPolicy) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aPolicy, bPolicy)
+}
+
+func (r *CloudEndpointReconciler) listCloudEndpointsForTrafficPolicy(ctx context.Context, obj client.Object) []reconcile.Request {
+	endpoints := &ngrokv1alpha1.CloudEndpointList{}
+	if err := r.Client.List(ctx, endpoints, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list CloudEndpoints for traffic policy", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, endpoint := range endpoints.Items {
+		if endpoint.Spec.TrafficPolicyName == obj.GetName() {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      endpoint.GetName(),
+					Namespace: endpoint.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
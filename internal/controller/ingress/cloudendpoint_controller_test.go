@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	"github.com/ngrok/ngrok-api-go/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+)
+
+var _ = Describe("CloudEndpointReconciler", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+	const policy = `{"inbound":[{"name":"deny","actions":[{"type":"deny"}]}]}`
+
+	var (
+		ctx      context.Context
+		req      ctrl.Request
+		created  []ngrokapi.CloudEndpointCreate
+		ngrokAPI *httptest.Server
+		endpoint *ngrokv1alpha1.CloudEndpoint
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "example", Namespace: "test-namespace"}}
+		created = nil
+
+		ngrokAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/endpoints"))
+			req := ngrokapi.CloudEndpointCreate{}
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			created = append(created, req)
+			resp := ngrokapi.CloudEndpoint{ID: "ep_123", URL: req.URL, Type: req.Type, TrafficPolicy: req.TrafficPolicy}
+			Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+		}))
+		DeferCleanup(ngrokAPI.Close)
+
+		endpoint = &ngrokv1alpha1.CloudEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       ngrokv1alpha1.CloudEndpointSpec{URL: "https://example.ngrok.app"},
+		}
+		controllers.AddFinalizer(endpoint)
+	})
+
+	// newReconciler returns a reconciler for a client with the endpoint and the other objects
+	newReconciler := func(objs ...client.Object) *CloudEndpointReconciler {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(append(objs, endpoint)...).
+			WithStatusSubresource(&ngrokv1alpha1.CloudEndpoint{}).
+			Build()
+		r := &CloudEndpointReconciler{
+			Client:               c,
+			Log:                  logr.Discard(),
+			Scheme:               scheme,
+			Recorder:             record.NewFakeRecorder(10),
+			CloudEndpointsClient: ngrokapi.NewCloudEndpointsClient(ngrok.NewClientConfig("test-api-key", ngrok.WithBaseURL(ngrokAPI.URL))),
+		}
+		r.controller = &baseController[*ngrokv1alpha1.CloudEndpoint]{
+			Kube:     c,
+			Log:      r.Log,
+			Recorder: r.Recorder,
+
+			kubeType: "v1alpha1.CloudEndpoint",
+			statusID: func(cr *ngrokv1alpha1.CloudEndpoint) string { return cr.Status.ID },
+			create:   r.create,
+			update:   r.update,
+			delete:   r.delete,
+		}
+		return r
+	}
+
+	trafficPolicy := &ngrokv1alpha1.NgrokTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny", Namespace: "test-namespace"},
+		Spec:       ngrokv1alpha1.NgrokTrafficPolicySpec{Policy: json.RawMessage(policy)},
+	}
+
+	It("Should create a cloud endpoint with the policy of the NgrokTrafficPolicy it names", func() {
+		endpoint.Spec.TrafficPolicyName = "deny"
+		r := newReconciler(trafficPolicy)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(HaveLen(1))
+		Expect(created[0].Type).To(Equal("cloud"))
+		Expect(created[0].URL).To(Equal("https://example.ngrok.app"))
+		Expect(created[0].TrafficPolicy).To(Equal(policy))
+
+		got := &ngrokv1alpha1.CloudEndpoint{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.ID).To(Equal("ep_123"))
+		Expect(string(got.Status.TrafficPolicy)).To(MatchJSON(policy))
+	})
+
+	It("Should compare traffic policies regardless of their formatting", func() {
+		Expect(trafficPoliciesEqual(policy, `{"inbound": [{"actions": [{"type": "deny"}], "name": "deny"}]}`)).To(BeTrue())
+		Expect(trafficPoliciesEqual(policy, `{"inbound":[]}`)).To(BeFalse())
+		Expect(trafficPoliciesEqual("", "")).To(BeTrue())
+	})
+
+	It("Should not create a cloud endpoint with both a traffic policy name and an inline policy", func() {
+		endpoint.Spec.TrafficPolicyName = "deny"
+		endpoint.Spec.TrafficPolicy = json.RawMessage(policy)
+		r := newReconciler(trafficPolicy)
+
+		Expect(r.create(ctx, endpoint)).To(MatchError(errConflictingTrafficPolicies))
+		Expect(created).To(BeEmpty())
+	})
+
+	It("Should not create a cloud endpoint with an invalid traffic policy", func() {
+		endpoint.Spec.TrafficPolicy = json.RawMessage(`{"inbound":[{"name":"no-actions"}]}`)
+		r := newReconciler()
+
+		Expect(r.create(ctx, endpoint)).To(MatchError(ingressv1alpha1.ErrInvalidTrafficPolicy))
+		Expect(created).To(BeEmpty())
+	})
+})
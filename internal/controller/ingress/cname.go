@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// cnameCheckLabel is the label used to check the CNAME record of a wildcard domain through a subdomain it covers,
+// since the wildcard itself isn't a name DNS can be queried for
+const cnameCheckLabel = "ngrok-cname-check"
+
+// CNAMEResolver looks up the canonical name of a host, following its CNAME records to the end of the chain.
+// *net.Resolver implements it.
+type CNAMEResolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// CNAMEChecker checks whether the CNAME records of reserved domains point to their ngrok CNAME targets
+type CNAMEChecker struct {
+	// Resolver is used to look up CNAME records. If nil, net.DefaultResolver is used.
+	Resolver CNAMEResolver
+}
+
+// CheckCNAMEResolved returns true if the domain resolves through the expected CNAME target. The domain may
+// point to the target through several CNAME hops, and the target itself may be a CNAME to another host. A
+// domain or target without any DNS records yet isn't resolved, and returns false rather than an error.
+func (c CNAMEChecker) CheckCNAMEResolved(ctx context.Context, domain, expectedTarget string) (bool, error) {
+	if strings.HasPrefix(domain, "*.") {
+		domain = cnameCheckLabel + strings.TrimPrefix(domain, "*")
+	}
+
+	canonical, found, err := c.lookupCNAME(ctx, domain)
+	if err != nil || !found {
+		return false, err
+	}
+	expectedTarget = normalizeHost(expectedTarget)
+	if canonical == expectedTarget {
+		return true, nil
+	}
+
+	// The lookup follows the whole chain, so the domain's canonical name is the end of the target's chain
+	// when the target is one of its hops
+	targetCanonical, found, err := c.lookupCNAME(ctx, expectedTarget)
+	if err != nil || !found {
+		return false, err
+	}
+	return canonical == targetCanonical, nil
+}
+
+// lookupCNAME returns the normalized canonical name of the host, or false if the host doesn't exist
+func (c CNAMEChecker) lookupCNAME(ctx context.Context, host string) (string, bool, error) {
+	resolver := c.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	canonical, err := resolver.LookupCNAME(ctx, host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return normalizeHost(canonical), true, nil
+}
+
+// normalizeHost lowercases the host and removes the trailing dot of a fully qualified name
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
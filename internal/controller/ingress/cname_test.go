@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeResolver resolves hosts through a map of CNAME records, following them to the end of the chain like
+// net.Resolver does. Hosts in addresses have address records but no CNAME.
+type fakeResolver struct {
+	cnames    map[string]string
+	addresses map[string]bool
+	err       error
+}
+
+func (f fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	found := false
+	for i := 0; i < 10; i++ {
+		next, ok := f.cnames[host]
+		if !ok {
+			break
+		}
+		host, found = next, true
+	}
+	if !found && !f.addresses[host] {
+		return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return host + ".", nil
+}
+
+var _ = Describe("CNAMEChecker", func() {
+	ctx := context.Background()
+
+	check := func(resolver fakeResolver, domain string) (bool, error) {
+		return CNAMEChecker{Resolver: resolver}.CheckCNAMEResolved(ctx, domain, "abc.ngrok-cname.com")
+	}
+
+	It("Should be resolved when the domain is a CNAME for the target", func() {
+		resolved, err := check(fakeResolver{
+			cnames:    map[string]string{"example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}, "example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeTrue())
+	})
+
+	It("Should be resolved when the domain reaches the target through several hops", func() {
+		resolved, err := check(fakeResolver{
+			cnames: map[string]string{
+				"example.com":         "lb.example.net",
+				"lb.example.net":      "abc.ngrok-cname.com",
+				"abc.ngrok-cname.com": "edge.ngrok.io",
+			},
+			addresses: map[string]bool{"edge.ngrok.io": true},
+		}, "example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeTrue())
+	})
+
+	It("Should check a wildcard domain through one of its subdomains", func() {
+		resolved, err := check(fakeResolver{
+			cnames:    map[string]string{"ngrok-cname-check.example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}, "*.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeTrue())
+	})
+
+	It("Should not be resolved when the domain points somewhere else", func() {
+		resolved, err := check(fakeResolver{
+			cnames:    map[string]string{"example.com": "old.example.net"},
+			addresses: map[string]bool{"old.example.net": true, "abc.ngrok-cname.com": true},
+		}, "example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeFalse())
+	})
+
+	It("Should not be resolved when the domain has no records yet", func() {
+		resolved, err := check(fakeResolver{
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}, "example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeFalse())
+	})
+
+	It("Should return the error when the lookup fails", func() {
+		lookupErr := &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}
+		resolved, err := check(fakeResolver{err: lookupErr}, "example.com")
+		Expect(errors.Is(err, lookupErr)).To(BeTrue())
+		Expect(resolved).To(BeFalse())
+	})
+})
@@ -26,21 +26,38 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
 	"github.com/ngrok/ngrok-api-go/v5"
 	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
 )
 
+// domainFieldOwner is the field manager of the domain status fields set by the DomainReconciler
+const domainFieldOwner = "ngrok-domain-controller"
+
+const (
+	// cnameRecheckMinInterval and cnameRecheckMaxInterval bound how long to wait before checking a pending CNAME
+	// record again. The wait is as long as the record has been pending, so checks back off exponentially.
+	cnameRecheckMinInterval = 10 * time.Second
+	cnameRecheckMaxInterval = 10 * time.Minute
+)
+
 // DomainReconciler reconciles a Domain object
 type DomainReconciler struct {
 	client.Client
@@ -49,6 +66,12 @@ type DomainReconciler struct {
 	Scheme        *runtime.Scheme
 	Recorder      record.EventRecorder
 	DomainsClient *reserved_domains.Client
+	// CNAMEChecker verifies the CNAME records of reserved domains. The zero value uses the default resolver.
+	CNAMEChecker CNAMEChecker
+	// RetryPolicy retries the reserved domain API calls, see ngrokapi.RetryPolicy
+	RetryPolicy ngrokapi.RetryPolicy
+	// DefaultRegion is the region domains without a region are reserved in. When it's empty, ngrok picks it.
+	DefaultRegion string
 
 	controller *baseController[*ingressv1alpha1.Domain]
 }
@@ -69,6 +92,9 @@ func (r *DomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		create:   r.create,
 		update:   r.update,
 		delete:   r.delete,
+		// resyncs of domains whose current generation is reserved and verified don't need any ngrok API calls
+		upToDate:     (*ingressv1alpha1.Domain).IsUpToDate,
+		requeueAfter: cnameRecheckAfter,
 		errResult: func(op baseControllerOp, cr *ingressv1alpha1.Domain, err error) (reconcile.Result, error) {
 			retryableErrors := []int{
 				// Domain still attached to an edge, probably a race condition.
@@ -82,6 +108,10 @@ func (r *DomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if ngrok.IsErrorCode(err, retryableErrors...) {
 				return ctrl.Result{}, err
 			}
+			// Retrying won't help until the domain's spec is fixed, which triggers another reconcile
+			if errors.Is(err, ingressv1alpha1.ErrInvalidRegion) || errors.Is(err, ingressv1alpha1.ErrInvalidDomain) {
+				return ctrl.Result{}, nil
+			}
 			return reconcileResultFromError(err)
 		},
 	}
@@ -106,6 +136,18 @@ func (r *DomainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 }
 
 func (r *DomainReconciler) create(ctx context.Context, domain *ingressv1alpha1.Domain) error {
+	if err := ingressv1alpha1.ValidateDomain(domain.Spec.Domain); err != nil {
+		return r.reservationFailed(ctx, domain, err)
+	}
+	region, err := ingressv1alpha1.ResolveRegion(domain.Spec, r.DefaultRegion)
+	if err != nil {
+		return r.reservationFailed(ctx, domain, err)
+	}
+	metadata, err := domain.Spec.ResolvedMetadata()
+	if err != nil {
+		return r.reservationFailed(ctx, domain, err)
+	}
+
 	// First check if the reserved domain already exists. The API is sometimes returning dangling CNAME records
 	// errors right now, so we'll check if the domain already exists before trying to create it.
 	resp, err := r.findReservedDomainByHostname(ctx, domain.Spec.Domain)
@@ -115,15 +157,36 @@ func (r *DomainReconciler) create(ctx context.Context, domain *ingressv1alpha1.D
 
 	// Not found, so we'll create it
 	if resp == nil {
+		if domain.Spec.IsWildcard() {
+			// ngrok reserves a wildcard domain when the domain's leading label is a wildcard. Its status will
+			// report both the CNAME target for the domain and for the _acme-challenge record of its parent.
+			ctrl.LoggerFrom(ctx).Info("Reserving wildcard domain", "domain", domain.Spec.Domain)
+		}
 		req := &ngrok.ReservedDomainCreate{
-			Domain:      domain.Spec.Domain,
-			Region:      domain.Spec.Region,
-			Description: domain.Spec.Description,
-			Metadata:    domain.Spec.Metadata,
+			Domain:                      domain.Spec.Domain,
+			Region:                      region,
+			Description:                 domain.Spec.Description,
+			Metadata:                    metadata,
+			CertificateManagementPolicy: domain.Spec.NgrokCertificateManagementPolicy(),
 		}
-		resp, err = r.DomainsClient.Create(ctx, req)
-		if err != nil {
+		// Creating isn't idempotent: a create that failed on ngrok's side or on the network may have reserved the
+		// domain anyway, so unless the last attempt was rate limited, look for the domain again before retrying.
+		var lastErr error
+		err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+			if lastErr != nil && !ngrokapi.IsRateLimited(lastErr) {
+				if resp, err = r.findReservedDomainByHostname(ctx, domain.Spec.Domain); err != nil || resp != nil {
+					return err
+				}
+			}
+			resp, err = r.DomainsClient.Create(ctx, req)
+			lastErr = err
 			return err
+		})
+		if err != nil {
+			if domain.Spec.IsWildcard() && isClientError(err) {
+				err = fmt.Errorf("%w: check that your ngrok account supports wildcard domains", err)
+			}
+			return r.reservationFailed(ctx, domain, err)
 		}
 	}
 
@@ -131,21 +194,38 @@ func (r *DomainReconciler) create(ctx context.Context, domain *ingressv1alpha1.D
 }
 
 func (r *DomainReconciler) update(ctx context.Context, domain *ingressv1alpha1.Domain) error {
-	resp, err := r.DomainsClient.Get(ctx, domain.Status.ID)
+	var resp *ngrok.ReservedDomain
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+		resp, err = r.DomainsClient.Get(ctx, domain.Status.ID)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	if domain.Equal(resp) {
-		return nil
+	metadata, err := domain.Spec.ResolvedMetadata()
+	if err != nil {
+		return err
+	}
+	policy := domain.Spec.NgrokCertificateManagementPolicy()
+	policyChanged := policy != nil && !ptr.Equal(policy, resp.CertificateManagementPolicy)
+	if resp.Description == domain.Spec.Description && resp.Metadata == metadata && !policyChanged {
+		// the status and conditions may still need to catch up to the domain's generation
+		return r.updateStatus(ctx, domain, resp)
 	}
 
 	req := &ngrok.ReservedDomainUpdate{
 		ID:          domain.Status.ID,
 		Description: &domain.Spec.Description,
-		Metadata:    &domain.Spec.Metadata,
+		Metadata:    &metadata,
+	}
+	if policyChanged {
+		req.CertificateManagementPolicy = policy
 	}
-	resp, err = r.DomainsClient.Update(ctx, req)
+	err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+		resp, err = r.DomainsClient.Update(ctx, req)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -153,7 +233,16 @@ func (r *DomainReconciler) update(ctx context.Context, domain *ingressv1alpha1.D
 }
 
 func (r *DomainReconciler) delete(ctx context.Context, domain *ingressv1alpha1.Domain) error {
-	err := r.DomainsClient.Delete(ctx, domain.Status.ID)
+	if domain.Spec.DeletionProtection {
+		// Leave the reserved domain in ngrok and only let the finalizer be removed
+		ctrl.LoggerFrom(ctx).Info("Domain has deletion protection enabled, not deleting the reserved domain", "ID", domain.Status.ID)
+		r.Recorder.Event(domain, v1.EventTypeNormal, events.ReasonDeletionProtected, fmt.Sprintf("Keeping reserved domain %s in ngrok because deletion protection is enabled", domain.Status.ID))
+		return nil
+	}
+
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		return r.DomainsClient.Delete(ctx, domain.Status.ID)
+	})
 	if err == nil || ngrok.IsNotFound(err) {
 		domain.Status.ID = ""
 	}
@@ -162,22 +251,84 @@ func (r *DomainReconciler) delete(ctx context.Context, domain *ingressv1alpha1.D
 
 // finds the reserved domain by the hostname. If it doesn't exist, returns nil
 func (r *DomainReconciler) findReservedDomainByHostname(ctx context.Context, domainName string) (*ngrok.ReservedDomain, error) {
-	iter := r.DomainsClient.List(&ngrok.Paging{})
-	for iter.Next(ctx) {
-		domain := iter.Item()
-		if domain.Domain == domainName {
-			return domain, nil
+	var found *ngrok.ReservedDomain
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		iter := r.DomainsClient.List(&ngrok.Paging{})
+		for iter.Next(ctx) {
+			if domain := iter.Item(); domain.Domain == domainName {
+				found = domain
+				return nil
+			}
 		}
-	}
-	return nil, nil
+		return iter.Err()
+	})
+	return found, err
 }
 
 // updateStatus updates the status fields of the domain resource only if any values have changed
 func (r *DomainReconciler) updateStatus(ctx context.Context, domain *ingressv1alpha1.Domain, ngrokDomain *ngrok.ReservedDomain) error {
-	if domain.Equal(ngrokDomain) {
+	changed := !domain.Equal(ngrokDomain) || !domain.IsReserved()
+	if changed {
+		domain.SetStatus(ngrokDomain)
+	}
+	if r.verifyCNAME(ctx, domain) {
+		changed = true
+	}
+	if !changed {
 		return nil
 	}
-	domain.SetStatus(ngrokDomain)
-	r.Recorder.Event(domain, v1.EventTypeNormal, "Updated", fmt.Sprintf("Updating Domain %s", domain.Name))
-	return r.Status().Update(ctx, domain)
+	r.Recorder.Event(domain, v1.EventTypeNormal, events.ReasonUpdated, fmt.Sprintf("Updating Domain %s", domain.Name))
+	return controllers.ApplyStatus(ctx, r.Client, domain, domainFieldOwner)
+}
+
+// verifyCNAME checks whether the domain's CNAME record points to its CNAME target yet, and marks its
+// CNAMEVerified condition True once it does. Returns true if the condition changed. Failing to look up the
+// record leaves the condition pending until the next reconcile.
+func (r *DomainReconciler) verifyCNAME(ctx context.Context, domain *ingressv1alpha1.Domain) bool {
+	if domain.Status.CNAMETarget == nil || domain.IsCNAMEVerified() {
+		return false
+	}
+
+	resolved, err := r.CNAMEChecker.CheckCNAMEResolved(ctx, domain.Status.Domain, *domain.Status.CNAMETarget)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to check the domain's CNAME record", "domain", domain.Status.Domain)
+		return false
+	}
+	if !resolved {
+		return false
+	}
+	domain.SetCNAMEVerified()
+	return true
+}
+
+// cnameRecheckAfter returns how long to wait before checking the domain's CNAME record again while it's pending.
+// Nothing watches DNS records, so the domain is requeued until its record is verified.
+func cnameRecheckAfter(domain *ingressv1alpha1.Domain) time.Duration {
+	if domain.Status.CNAMETarget == nil || domain.IsCNAMEVerified() {
+		return 0
+	}
+
+	pending := time.Duration(0)
+	if condition := domain.Status.GetCondition(ingressv1alpha1.DomainConditionCNAMEVerified); condition != nil {
+		pending = time.Since(condition.LastTransitionTime.Time)
+	}
+	return min(max(pending, cnameRecheckMinInterval), cnameRecheckMaxInterval)
+}
+
+// isClientError reports whether the ngrok API rejected a request, rather than failing or rate limiting it
+func isClientError(err error) bool {
+	var nerr *ngrok.Error
+	if !errors.As(err, &nerr) {
+		return false
+	}
+	return nerr.StatusCode >= 400 && nerr.StatusCode < 500 && nerr.StatusCode != http.StatusTooManyRequests
+}
+
+// reservationFailed records the error reserving the domain in its DomainReserved condition and returns the error
+func (r *DomainReconciler) reservationFailed(ctx context.Context, domain *ingressv1alpha1.Domain, err error) error {
+	domain.SetReservationFailed(err)
+	if updateErr := controllers.ApplyStatus(ctx, r.Client, domain, domainFieldOwner); updateErr != nil {
+		ctrl.LoggerFrom(ctx).Error(updateErr, "failed to update the domain's conditions")
+	}
+	return err
 }
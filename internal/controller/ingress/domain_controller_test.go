@@ -0,0 +1,529 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers/testutil"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+)
+
+var _ = Describe("DomainReconciler", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+
+	var (
+		ctx     context.Context
+		req     ctrl.Request
+		created []string
+		updated []string
+		deleted []string
+		// certPolicies are the certificate management policies sent when reserving or updating domains
+		certPolicies []*ngrok.ReservedDomainCertPolicy
+		// requests are the method and path of every request made to the ngrok API
+		requests []string
+		resolver fakeResolver
+		// rateLimitedCreates is the number of requests to reserve a domain that are rate limited before one succeeds
+		rateLimitedCreates int
+		// retryAfter is the Retry-After header of the rate limited responses
+		retryAfter string
+		// failedCreates is the number of requests that reserve a domain but fail with a server error anyway
+		failedCreates int
+		// rejectWildcards makes the ngrok API refuse to reserve wildcard domains, like it does for accounts without them
+		rejectWildcards bool
+		// omitCNAMETarget makes the ngrok API reserve domains without returning their CNAME target
+		omitCNAMETarget bool
+		// defaultRegion is the DefaultRegion of the reconciler
+		defaultRegion string
+		ngrokAPI      *httptest.Server
+		reconcile     func(c client.Client) error
+		// result is the result of the last reconcile
+		result ctrl.Result
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "example-com", Namespace: "test-namespace"}}
+		created = nil
+		updated = nil
+		deleted = nil
+		certPolicies = nil
+		requests = nil
+		rateLimitedCreates = 0
+		retryAfter = ""
+		failedCreates = 0
+		rejectWildcards = false
+		omitCNAMETarget = false
+		defaultRegion = ""
+		// the CNAME records haven't been created yet
+		resolver = fakeResolver{}
+
+		ngrokAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch r.Method {
+			case http.MethodGet:
+				if strings.HasSuffix(r.URL.Path, "/rd_123") {
+					resp := ngrok.ReservedDomain{ID: "rd_123", Domain: "example.com", CNAMETarget: ptr.To("abc.ngrok-cname.com")}
+					Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+					return
+				}
+				list := ngrok.ReservedDomainList{}
+				for _, domain := range created {
+					list.ReservedDomains = append(list.ReservedDomains, ngrok.ReservedDomain{ID: "rd_123", Domain: domain, CNAMETarget: ptr.To("abc.ngrok-cname.com")})
+				}
+				Expect(json.NewEncoder(w).Encode(list)).To(Succeed())
+			case http.MethodPatch:
+				req := ngrok.ReservedDomainUpdate{}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				updated = append(updated, r.URL.Path)
+				certPolicies = append(certPolicies, req.CertificateManagementPolicy)
+				resp := ngrok.ReservedDomain{ID: "rd_123", Domain: "example.com", Description: *req.Description, Metadata: *req.Metadata, CNAMETarget: ptr.To("abc.ngrok-cname.com"), CertificateManagementPolicy: req.CertificateManagementPolicy}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case http.MethodPost:
+				if rateLimitedCreates > 0 {
+					rateLimitedCreates--
+					if retryAfter != "" {
+						w.Header().Set("Retry-After", retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = w.Write([]byte(`{"status_code": 429, "msg": "rate limited"}`))
+					return
+				}
+				req := ngrok.ReservedDomainCreate{}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				if rejectWildcards && strings.HasPrefix(req.Domain, "*.") {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"status_code": 400, "msg": "wildcard domains are not available on your plan"}`))
+					return
+				}
+				created = append(created, req.Domain)
+				certPolicies = append(certPolicies, req.CertificateManagementPolicy)
+				if failedCreates > 0 {
+					failedCreates--
+					w.WriteHeader(http.StatusBadGateway)
+					_, _ = w.Write([]byte(`{"status_code": 502, "msg": "bad gateway"}`))
+					return
+				}
+				resp := ngrok.ReservedDomain{ID: "rd_123", Domain: req.Domain, Region: req.Region, CNAMETarget: ptr.To("abc.ngrok-cname.com"), CertificateManagementPolicy: req.CertificateManagementPolicy}
+				if omitCNAMETarget {
+					resp.CNAMETarget = nil
+				}
+				if strings.HasPrefix(req.Domain, "*.") {
+					resp.ACMEChallengeCNAMETarget = ptr.To("abc.acme.ngrok-cname.com")
+				}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case http.MethodDelete:
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		DeferCleanup(ngrokAPI.Close)
+
+		reconcile = func(c client.Client) error {
+			r := &DomainReconciler{
+				Client:   c,
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+				DomainsClient: reserved_domains.NewClient(ngrok.NewClientConfig("test-api-key",
+					ngrok.WithBaseURL(ngrokAPI.URL),
+					ngrok.WithHTTPClient(&http.Client{Transport: &ngrokapi.RetryAfterTransport{}}),
+				)),
+				CNAMEChecker:  CNAMEChecker{Resolver: resolver},
+				RetryPolicy:   ngrokapi.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+				DefaultRegion: defaultRegion,
+			}
+			r.controller = &baseController[*ingressv1alpha1.Domain]{
+				Kube:     c,
+				Log:      r.Log,
+				Recorder: r.Recorder,
+
+				kubeType:     "v1alpha1.Domain",
+				statusID:     func(cr *ingressv1alpha1.Domain) string { return cr.Status.ID },
+				create:       r.create,
+				update:       r.update,
+				delete:       r.delete,
+				upToDate:     (*ingressv1alpha1.Domain).IsUpToDate,
+				requeueAfter: cnameRecheckAfter,
+			}
+			var err error
+			result, err = r.Reconcile(ctx, req)
+			return err
+		}
+	})
+
+	deletingDomain := func(deletionProtection bool) *ingressv1alpha1.Domain {
+		domain := &ingressv1alpha1.Domain{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              req.Name,
+				Namespace:         req.Namespace,
+				DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time},
+			},
+			Spec: ingressv1alpha1.DomainSpec{
+				Domain:             "example.com",
+				DeletionProtection: deletionProtection,
+			},
+		}
+		controllers.AddFinalizer(domain)
+		domain.Status.ID = "rd_123"
+		return domain
+	}
+
+	It("Should delete the reserved domain when deletion protection is disabled", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingDomain(false)).Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(deleted).To(Equal([]string{"/reserved_domains/rd_123"}))
+
+		err := c.Get(ctx, req.NamespacedName, &ingressv1alpha1.Domain{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should keep the reserved domain and remove the finalizer when deletion protection is enabled", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingDomain(true)).Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(deleted).To(BeEmpty())
+
+		err := c.Get(ctx, req.NamespacedName, &ingressv1alpha1.Domain{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	newDomain := func(domainName string) *ingressv1alpha1.Domain {
+		domain := &ingressv1alpha1.Domain{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       ingressv1alpha1.DomainSpec{Domain: domainName},
+		}
+		controllers.AddFinalizer(domain)
+		return domain
+	}
+
+	// newClient returns a client with the domain whose status updates are recorded by applier
+	var applier *testutil.StatusApplier
+	newClient := func(domain *ingressv1alpha1.Domain) client.Client {
+		applier = &testutil.StatusApplier{}
+		return fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(domain).
+			WithStatusSubresource(&ingressv1alpha1.Domain{}).
+			WithInterceptorFuncs(applier.Funcs()).
+			Build()
+	}
+
+	It("Should reserve a wildcard domain and report its CNAME targets", func() {
+		c := newClient(newDomain("*.example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(Equal([]string{"*.example.com"}))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.Domain).To(Equal("*.example.com"))
+		Expect(domain.Status.CNAMETarget).To(Equal(ptr.To("abc.ngrok-cname.com")))
+		Expect(domain.Status.ACMEChallengeCNAMETarget).To(Equal(ptr.To("abc.acme.ngrok-cname.com")))
+		Expect(applier.FieldOwners).To(ConsistOf(domainFieldOwner))
+	})
+
+	It("Should explain a rejected wildcard domain reservation", func() {
+		rejectWildcards = true
+		c := newClient(newDomain("*.example.com"))
+
+		// the ngrok API rejected the domain, so it isn't retried
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(BeEmpty())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsReserved()).To(BeFalse())
+		Expect(domain.Status.GetCondition(ingressv1alpha1.DomainConditionReserved).Message).To(ContainSubstring("check that your ngrok account supports wildcard domains"))
+	})
+
+	It("Should retry reserving the domain when the ngrok API rate limits it", func() {
+		rateLimitedCreates = 2
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(Equal([]string{"example.com"}))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsReserved()).To(BeTrue())
+	})
+
+	It("Should give up reserving the domain when the ngrok API keeps rate limiting it", func() {
+		rateLimitedCreates = 3
+		c := newClient(newDomain("example.com"))
+
+		// the reconcile is requeued for later rather than failing
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(BeEmpty())
+		Expect(rateLimitedCreates).To(Equal(0), "every attempt was made")
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsReserved()).To(BeFalse())
+		Expect(domain.Status.GetCondition(ingressv1alpha1.DomainConditionReserved).Message).To(ContainSubstring("rate limited"))
+	})
+
+	It("Should requeue reserving the domain for as long as the ngrok API's Retry-After asks", func() {
+		rateLimitedCreates = 1
+		retryAfter = "120"
+		c := newClient(newDomain("example.com"))
+
+		// waiting out the Retry-After is left to the requeue rather than blocking the reconcile
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(BeEmpty())
+		Expect(result.RequeueAfter).To(Equal(2 * time.Minute))
+	})
+
+	It("Should not reserve the domain twice when a failed create reserved it anyway", func() {
+		failedCreates = 1
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(Equal([]string{"example.com"}))
+		Expect(requests).To(Equal([]string{
+			"GET /reserved_domains",
+			"POST /reserved_domains",
+			"GET /reserved_domains",
+		}))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsReserved()).To(BeTrue())
+		Expect(domain.Status.ID).To(Equal("rd_123"))
+	})
+
+	It("Should reserve a plain domain without an ACME challenge CNAME target", func() {
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(Equal([]string{"example.com"}))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.CNAMETarget).To(Equal(ptr.To("abc.ngrok-cname.com")))
+		Expect(domain.Status.ACMEChallengeCNAMETarget).To(BeNil())
+	})
+
+	It("Should report a missing CNAME target when the ngrok API doesn't return one", func() {
+		omitCNAMETarget = true
+		euDomain := newDomain("example.com")
+		euDomain.Spec.Region = "eu"
+		c := newClient(euDomain)
+
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.Region).To(Equal("eu"))
+		Expect(domain.Status.CNAMETarget).To(BeNil())
+		Expect(domain.IsCNAMEVerified()).To(BeFalse())
+		condition := domain.Status.GetCondition(ingressv1alpha1.DomainConditionCNAMEVerified)
+		Expect(condition.Reason).To(Equal(ingressv1alpha1.DomainReasonCNAMETargetMissing))
+	})
+
+	It("Should reserve a domain without a region in the default region", func() {
+		defaultRegion = "ap"
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.Region).To(Equal("ap"))
+	})
+
+	It("Should reserve a domain in its own region over the default region", func() {
+		defaultRegion = "ap"
+		euDomain := newDomain("example.com")
+		euDomain.Spec.Region = "eu"
+		c := newClient(euDomain)
+
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.Region).To(Equal("eu"))
+	})
+
+	It("Should not need a CNAME target for a subdomain of an ngrok domain", func() {
+		omitCNAMETarget = true
+		c := newClient(newDomain("my-app.ngrok.app"))
+
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.CNAMETarget).To(BeNil())
+		Expect(domain.IsCNAMEVerified()).To(BeTrue())
+	})
+
+	It("Should leave the CNAME pending until the domain's CNAME record resolves", func() {
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsCNAMEVerified()).To(BeFalse())
+		Expect(domain.Status.GetCondition(ingressv1alpha1.DomainConditionCNAMEVerified).Reason).To(Equal(ingressv1alpha1.DomainReasonCNAMEPending))
+	})
+
+	It("Should verify the CNAME once the domain's CNAME record resolves", func() {
+		resolver = fakeResolver{
+			cnames:    map[string]string{"example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsCNAMEVerified()).To(BeTrue())
+		Expect(domain.Status.GetCondition(ingressv1alpha1.DomainConditionCNAMEVerified).Reason).To(Equal(ingressv1alpha1.DomainReasonCNAMEVerified))
+	})
+
+	It("Should not call the ngrok API for a verified domain whose generation was observed", func() {
+		resolver = fakeResolver{
+			cnames:    map[string]string{"example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}
+		c := newClient(newDomain("example.com"))
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.ObservedGeneration).To(Equal(domain.Generation))
+		Expect(domain.IsUpToDate()).To(BeTrue())
+
+		requests = nil
+		Expect(reconcile(c)).To(Succeed())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("Should update the reserved domain when its generation changes", func() {
+		resolver = fakeResolver{
+			cnames:    map[string]string{"example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}
+		c := newClient(newDomain("example.com"))
+		Expect(reconcile(c)).To(Succeed())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		domain.Spec.Description = "updated description"
+		domain.Generation++
+		Expect(c.Update(ctx, domain)).To(Succeed())
+
+		requests = nil
+		Expect(reconcile(c)).To(Succeed())
+		Expect(requests).ToNot(BeEmpty())
+		Expect(updated).To(Equal([]string{"/reserved_domains/rd_123"}))
+
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.ObservedGeneration).To(Equal(domain.Generation))
+		Expect(domain.IsUpToDate()).To(BeTrue())
+	})
+
+	It("Should reserve the domain with its certificate management policy", func() {
+		domain := newDomain("example.com")
+		domain.Spec.CertificateManagementPolicy = &ingressv1alpha1.DomainCertificateManagementPolicy{Authority: "letsencrypt", PrivateKeyType: "ecdsa"}
+		c := newClient(domain)
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(certPolicies).To(Equal([]*ngrok.ReservedDomainCertPolicy{{Authority: "letsencrypt", PrivateKeyType: "ecdsa"}}))
+
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.Status.CertificateManagementPolicy).To(Equal(&ingressv1alpha1.DomainStatusCertificateManagementPolicy{Authority: "letsencrypt", PrivateKeyType: "ecdsa"}))
+		Expect(domain.IsCertificateReady()).To(BeTrue())
+	})
+
+	It("Should update the certificate management policy of a reserved domain", func() {
+		c := newClient(newDomain("example.com"))
+		Expect(reconcile(c)).To(Succeed())
+		Expect(certPolicies).To(Equal([]*ngrok.ReservedDomainCertPolicy{nil}))
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		domain.Spec.CertificateManagementPolicy = &ingressv1alpha1.DomainCertificateManagementPolicy{Authority: "letsencrypt", PrivateKeyType: "rsa"}
+		domain.Generation++
+		Expect(c.Update(ctx, domain)).To(Succeed())
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(updated).To(Equal([]string{"/reserved_domains/rd_123"}))
+		Expect(certPolicies[1]).To(Equal(&ngrok.ReservedDomainCertPolicy{Authority: "letsencrypt", PrivateKeyType: "rsa"}))
+	})
+
+	It("Should keep checking a domain whose CNAME isn't verified yet", func() {
+		c := newClient(newDomain("example.com"))
+		Expect(reconcile(c)).To(Succeed())
+		Expect(result.RequeueAfter).To(Equal(cnameRecheckMinInterval))
+
+		requests = nil
+		Expect(reconcile(c)).To(Succeed())
+		Expect(requests).To(Equal([]string{"GET /reserved_domains/rd_123"}))
+	})
+
+	It("Should back off checking a CNAME the longer it's pending", func() {
+		domain := newDomain("example.com")
+		domain.Status.CNAMETarget = ptr.To("abc.ngrok-cname.com")
+		domain.Status.SetCondition(metav1.Condition{
+			Type:               ingressv1alpha1.DomainConditionCNAMEVerified,
+			Status:             metav1.ConditionUnknown,
+			Reason:             ingressv1alpha1.DomainReasonCNAMEPending,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+		})
+		Expect(cnameRecheckAfter(domain)).To(BeNumerically("~", time.Minute, time.Second))
+
+		domain.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+		Expect(cnameRecheckAfter(domain)).To(Equal(cnameRecheckMaxInterval))
+	})
+
+	It("Should stop requeueing once the CNAME is verified", func() {
+		resolver = fakeResolver{
+			cnames:    map[string]string{"example.com": "abc.ngrok-cname.com"},
+			addresses: map[string]bool{"abc.ngrok-cname.com": true},
+		}
+		c := newClient(newDomain("example.com"))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("Should not reserve a domain with a wildcard in the middle", func() {
+		c := newClient(newDomain("foo.*.com"))
+
+		err := reconcile(c)
+		Expect(err).To(MatchError(ingressv1alpha1.ErrInvalidDomain))
+		Expect(created).To(BeEmpty())
+
+		domain := &ingressv1alpha1.Domain{}
+		Expect(c.Get(ctx, req.NamespacedName, domain)).To(Succeed())
+		Expect(domain.IsReserved()).To(BeFalse())
+		Expect(domain.Status.GetCondition(ingressv1alpha1.DomainConditionReserved).Reason).To(Equal(ingressv1alpha1.DomainReasonReservationFailed))
+	})
+})
@@ -39,16 +39,21 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
 	ierr "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
 	"github.com/ngrok/ngrok-api-go/v5"
 	"github.com/ngrok/ngrok-api-go/v5/backends/tunnel_group"
+	"github.com/ngrok/ngrok-api-go/v5/backends/weighted"
 )
 
 type routeModuleComparision string
@@ -69,6 +74,13 @@ type HTTPSEdgeReconciler struct {
 
 	NgrokClientset ngrokapi.Clientset
 
+	// Driver, when set, caches the secrets referenced by route modules across reconciles
+	Driver *store.Driver
+
+	// TranslateModulesToPolicy translates the compression, IP restriction, headers, OAuth and OIDC modules of
+	// routes into their traffic policy rather than configuring the modules individually
+	TranslateModulesToPolicy bool
+
 	controller *baseController[*ingressv1alpha1.HTTPSEdge]
 }
 
@@ -96,11 +108,89 @@ func (r *HTTPSEdgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&ingressv1alpha1.HTTPSEdge{}).
-		WithEventFilter(commonPredicateFilters).
+		For(&ingressv1alpha1.HTTPSEdge{}, builder.WithPredicates(commonPredicateFilters)).
+		// Secrets don't have a generation, so their updates get past the predicates that edges use
+		Watches(
+			&v1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.listEdgesForSecret),
+		).
+		Watches(
+			&v1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.listEdgesForConfigMap),
+		).
 		Complete(r)
 }
 
+// listEdgesForSecret returns the edges with a module that references the secret, like an OIDC client
+// secret or a mutual TLS CA bundle, so rotating the secret updates the modules in ngrok
+func (r *HTTPSEdgeReconciler) listEdgesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	edges := &ingressv1alpha1.HTTPSEdgeList{}
+	if err := r.Client.List(ctx, edges, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list HTTPSEdges for secret", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, edge := range edges.Items {
+		if (edge.Spec.MutualTLS != nil && slices.Contains(edge.Spec.MutualTLS.CertificateAuthoritySecrets, obj.GetName())) ||
+			slices.ContainsFunc(edge.Spec.Routes, func(route ingressv1alpha1.HTTPSEdgeRouteSpec) bool {
+				return slices.Contains(routeSecretNames(route), obj.GetName())
+			}) {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      edge.GetName(),
+					Namespace: edge.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
+
+// listEdgesForConfigMap returns the edges whose mutual TLS module loads a CA bundle from the config map
+func (r *HTTPSEdgeReconciler) listEdgesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	edges := &ingressv1alpha1.HTTPSEdgeList{}
+	if err := r.Client.List(ctx, edges, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list HTTPSEdges for config map", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, edge := range edges.Items {
+		if edge.Spec.MutualTLS != nil && slices.Contains(edge.Spec.MutualTLS.CertificateAuthorityConfigMaps, obj.GetName()) {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      edge.GetName(),
+					Namespace: edge.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
+
+// routeSecretNames returns the names of the secrets referenced by the route's modules
+func routeSecretNames(route ingressv1alpha1.HTTPSEdgeRouteSpec) []string {
+	var names []string
+	if route.OIDC != nil {
+		names = append(names, route.OIDC.ClientSecret.Name)
+	}
+	if route.WebhookVerification != nil && route.WebhookVerification.SecretRef != nil {
+		names = append(names, route.WebhookVerification.SecretRef.Name)
+	}
+	if oauth := route.OAuth; oauth != nil {
+		for _, p := range oauthProviders(oauth) {
+			if !p.Provided() {
+				continue
+			}
+			if ref := p.ClientSecretKeyRef(); ref != nil {
+				names = append(names, ref.Name)
+			}
+		}
+	}
+	return names
+}
+
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=httpsedges,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=httpsedges/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=httpsedges/finalizers,verbs=update
@@ -168,7 +258,7 @@ func (r *HTTPSEdgeReconciler) upsert(ctx context.Context, edge *ingressv1alpha1.
 		return err
 	}
 
-	if err := r.setEdgeMutualTLS(ctx, remoteEdge, edge.Spec.MutualTLS); err != nil {
+	if err := r.setEdgeMutualTLS(ctx, remoteEdge, edge.Namespace, edge.Spec.MutualTLS); err != nil {
 		return err
 	}
 
@@ -180,24 +270,39 @@ func (r *HTTPSEdgeReconciler) delete(ctx context.Context, edge *ingressv1alpha1.
 	if err == nil || ngrok.IsNotFound(err) {
 		edge.Status.ID = ""
 	}
-	return err
+	if err != nil {
+		return err
+	}
+
+	// the edge's routes are gone along with it, so the weighted backends only they used aren't needed anymore
+	weightedReconciler := newWeightedBackendReconciler(r.NgrokClientset.WeightedBackends())
+	return r.deleteUnusedWeightedBackends(ctx, edge, weightedReconciler, routeBackendIDs(edge.Status.Routes), nil)
 }
 
 // TODO: This is going to be a bit messy right now, come back and make this cleaner
 func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingressv1alpha1.HTTPSEdge, remoteEdge *ngrok.HTTPSEdge) error {
 	log := ctrl.LoggerFrom(ctx)
 
+	// the backends the edge's routes used before, so the weighted ones they don't use anymore can be deleted
+	previousBackendIDs := routeBackendIDs(edge.Status.Routes)
+	for _, remoteRoute := range remoteEdge.Routes {
+		if remoteRoute.Backend != nil {
+			previousBackendIDs = append(previousBackendIDs, remoteRoute.Backend.Backend.ID)
+		}
+	}
+
 	routeStatuses := make([]ingressv1alpha1.HTTPSEdgeRouteStatus, len(edge.Spec.Routes))
 	tunnelGroupReconciler, err := newTunnelGroupBackendReconciler(r.NgrokClientset.TunnelGroupBackends())
 	if err != nil {
 		return err
 	}
+	weightedReconciler := newWeightedBackendReconciler(r.NgrokClientset.WeightedBackends())
 
 	routeModuleUpdater := &edgeRouteModuleUpdater{
 		edge:             edge,
 		clientset:        r.NgrokClientset.EdgeModules().HTTPS().Routes(),
 		ipPolicyResolver: controllers.IpPolicyResolver{Client: r.Client},
-		secretResolver:   controllers.SecretResolver{Client: r.Client},
+		secretResolver:   controllers.SecretResolver{Client: r.Client, Driver: r.Driver},
 	}
 
 	edgeRoutes := r.NgrokClientset.HTTPSEdgeRoutes()
@@ -210,7 +315,7 @@ func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingress
 		if routeSpec.IPRestriction != nil {
 			if err := routeModuleUpdater.ipPolicyResolver.ValidateIPPolicyNames(ctx, edge.Namespace, routeSpec.IPRestriction.IPPolicies); err != nil {
 				if apierrors.IsNotFound(err) {
-					r.Recorder.Eventf(edge, v1.EventTypeWarning, "FailedValidate", "Could not validate ip restriction: %v", err)
+					r.Recorder.Eventf(edge, v1.EventTypeWarning, events.ReasonFailedValidate, "Could not validate ip restriction: %v", err)
 					continue
 				}
 				return err
@@ -251,10 +356,26 @@ func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingress
 		routeLog = routeLog.WithValues("ngrok.route.id", route.ID)
 		routeCtx := ctrl.LoggerInto(ctx, routeLog)
 
+		if r.TranslateModulesToPolicy {
+			translated, err := routeModuleUpdater.translateModulesToPolicy(routeCtx, &routeSpec)
+			if err != nil {
+				r.Recorder.Event(edge, v1.EventTypeWarning, events.ReasonRouteModuleUpdateFailed, err.Error())
+				return err
+			}
+			routeSpec = *translated
+		} else {
+			translated, err := translateCompressionToPolicy(&routeSpec)
+			if err != nil {
+				r.Recorder.Event(edge, v1.EventTypeWarning, events.ReasonRouteModuleUpdateFailed, err.Error())
+				return err
+			}
+			routeSpec = *translated
+		}
+
 		if isMigratingAuthProviders(route, &routeSpec) {
 			routeLog.Info("Route is migrating auth types. Taking offline before updating")
 			if err := r.takeOfflineWithoutAuth(routeCtx, route); err != nil {
-				r.Recorder.Event(edge, v1.EventTypeWarning, "RouteTakeOfflineFailed", err.Error())
+				r.Recorder.Event(edge, v1.EventTypeWarning, events.ReasonRouteTakeOfflineFailed, err.Error())
 				return err
 			}
 		}
@@ -271,16 +392,16 @@ func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingress
 		// TODO: Check if there are no updates to apply here to skip any unnecessary disruption
 		routeLog.Info("Applying route modules")
 		if err := routeModuleUpdater.updateModulesForRoute(routeCtx, route, &routeSpec); err != nil {
-			r.Recorder.Event(edge, v1.EventTypeWarning, "RouteModuleUpdateFailed", err.Error())
+			r.Recorder.Event(edge, v1.EventTypeWarning, events.ReasonRouteModuleUpdateFailed, err.Error())
 			return err
 		}
 
 		// The route modules were successfully applied, so now we update the route with its specified backend
-		backend, err := tunnelGroupReconciler.findOrCreate(routeCtx, routeSpec.Backend)
+		backendID, err := findOrCreateRouteBackend(routeCtx, &routeSpec, tunnelGroupReconciler, weightedReconciler)
 		if err != nil {
 			return err
 		}
-		routeLog.Info("Updating route", "ngrok.backend.id", backend.ID)
+		routeLog.Info("Updating route", "ngrok.backend.id", backendID)
 
 		// TODO: Do an entropy check here to avoid unnecessary updates
 		req := &ngrok.HTTPSEdgeRouteUpdate{
@@ -289,7 +410,7 @@ func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingress
 			Match:     routeSpec.Match,
 			MatchType: routeSpec.MatchType,
 			Backend: &ngrok.EndpointBackendMutate{
-				BackendID: backend.ID,
+				BackendID: backendID,
 			},
 		}
 		route, err = edgeRoutes.Update(routeCtx, req)
@@ -327,7 +448,57 @@ func (r *HTTPSEdgeReconciler) reconcileRoutes(ctx context.Context, edge *ingress
 
 	edge.Status.Routes = routeStatuses
 
-	return r.Status().Update(ctx, edge)
+	if err := r.Status().Update(ctx, edge); err != nil {
+		return err
+	}
+
+	return r.deleteUnusedWeightedBackends(ctx, edge, weightedReconciler, previousBackendIDs, routeBackendIDs(routeStatuses))
+}
+
+// routeBackendIDs returns the IDs of the backends of the routes
+func routeBackendIDs(routes []ingressv1alpha1.HTTPSEdgeRouteStatus) []string {
+	var ids []string
+	for _, route := range routes {
+		if route.Backend.ID != "" {
+			ids = append(ids, route.Backend.ID)
+		}
+	}
+	return ids
+}
+
+// deleteUnusedWeightedBackends deletes the weighted backends with the previous IDs that the edge doesn't use
+// anymore. Weighted backends with the same weights are shared between edges, so the ones still used by the
+// routes of other edges are kept.
+func (r *HTTPSEdgeReconciler) deleteUnusedWeightedBackends(ctx context.Context, edge *ingressv1alpha1.HTTPSEdge, weightedBackends *weightedBackendReconciler, previousIDs, usedIDs []string) error {
+	unused := map[string]bool{}
+	for _, id := range previousIDs {
+		if !slices.Contains(usedIDs, id) {
+			unused[id] = true
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+
+	edges := &ingressv1alpha1.HTTPSEdgeList{}
+	if err := r.Client.List(ctx, edges); err != nil {
+		return err
+	}
+	for _, other := range edges.Items {
+		if other.Namespace == edge.Namespace && other.Name == edge.Name {
+			continue
+		}
+		for _, id := range routeBackendIDs(other.Status.Routes) {
+			delete(unused, id)
+		}
+	}
+
+	for id := range unused {
+		if err := weightedBackends.delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *HTTPSEdgeReconciler) setEdgeTLSTermination(ctx context.Context, edge *ngrok.HTTPSEdge, tlsTermination *ingressv1alpha1.EndpointTLSTerminationAtEdge) error {
@@ -353,7 +524,7 @@ func (r *HTTPSEdgeReconciler) setEdgeTLSTermination(ctx context.Context, edge *n
 	return err
 }
 
-func (r *HTTPSEdgeReconciler) setEdgeMutualTLS(ctx context.Context, edge *ngrok.HTTPSEdge, mtls *ingressv1alpha1.EndpointMutualTLS) error {
+func (r *HTTPSEdgeReconciler) setEdgeMutualTLS(ctx context.Context, edge *ngrok.HTTPSEdge, namespace string, mtls *ingressv1alpha1.EndpointMutualTLS) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	client := r.NgrokClientset.EdgeModules().HTTPS().MutualTLS()
@@ -367,10 +538,19 @@ func (r *HTTPSEdgeReconciler) setEdgeMutualTLS(ctx context.Context, edge *ngrok.
 		return client.Delete(ctx, edge.ID)
 	}
 
-	_, err := client.Replace(ctx, &ngrok.EdgeMutualTLSReplace{
+	resolver := controllers.CertificateAuthorityResolver{
+		Client:                       r.Client,
+		CertificateAuthoritiesClient: r.NgrokClientset.CertificateAuthorities(),
+	}
+	caIDs, err := resolver.ResolveCertificateAuthorityIDs(ctx, namespace, mtls)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Replace(ctx, &ngrok.EdgeMutualTLSReplace{
 		ID: edge.ID,
 		Module: ngrok.EndpointMutualTLSMutate{
-			CertificateAuthorityIDs: mtls.CertificateAuthorities,
+			CertificateAuthorityIDs: caIDs,
 		},
 	})
 	return err
@@ -531,6 +711,114 @@ func (r *tunnelGroupBackendReconciler) findOrCreate(ctx context.Context, backend
 	return be, nil
 }
 
+// Weighted Backend planner
+type weightedBackendReconciler struct {
+	client   *weighted.Client
+	backends []*ngrok.WeightedBackend
+	listed   bool
+}
+
+func newWeightedBackendReconciler(client *weighted.Client) *weightedBackendReconciler {
+	return &weightedBackendReconciler{client: client}
+}
+
+// findOrCreate returns a weighted backend that splits traffic between the backends with the IDs in proportion to
+// their weights, creating one if there isn't one already. The existing weighted backends are listed the first
+// time one is needed, so edges without weighted routes don't list them.
+func (r *weightedBackendReconciler) findOrCreate(ctx context.Context, description, metadata string, backends map[string]int64) (*ngrok.WeightedBackend, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("backend.weights", backends)
+
+	if err := r.list(ctx); err != nil {
+		return nil, err
+	}
+
+	log.V(3).Info("Searching for weighted backend with matching weights")
+	for _, b := range r.backends {
+		if maps.Equal(b.Backends, backends) {
+			log.V(3).Info("Found matching weighted backend", "id", b.ID)
+			return b, nil
+		}
+	}
+
+	log.V(3).Info("No matching weighted backend found, creating a new one")
+	be, err := r.client.Create(ctx, &ngrok.WeightedBackendCreate{
+		Description: description,
+		Metadata:    metadata,
+		Backends:    backends,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.V(3).Info("Created new weighted backend", "id", be.ID)
+	r.backends = append(r.backends, be)
+	return be, nil
+}
+
+// list lists the existing weighted backends the first time they're needed
+func (r *weightedBackendReconciler) list(ctx context.Context) error {
+	if r.listed {
+		return nil
+	}
+	iter := r.client.List(&ngrok.Paging{})
+	for iter.Next(ctx) {
+		r.backends = append(r.backends, iter.Item())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	r.listed = true
+	return nil
+}
+
+// delete deletes the weighted backend with the ID. IDs of other kinds of backends, like tunnel group backends,
+// are ignored.
+func (r *weightedBackendReconciler) delete(ctx context.Context, id string) error {
+	if err := r.list(ctx); err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(r.backends, func(b *ngrok.WeightedBackend) bool { return b.ID == id })
+	if i < 0 {
+		return nil
+	}
+
+	log := ctrl.LoggerFrom(ctx).WithValues("backend.id", id)
+	log.V(3).Info("Deleting weighted backend that isn't used anymore")
+	if err := r.client.Delete(ctx, id); err != nil && !ngrok.IsNotFound(err) {
+		return err
+	}
+	r.backends = slices.Delete(r.backends, i, i+1)
+	return nil
+}
+
+// findOrCreateRouteBackend returns the ID of the backend that serves the route. That's a weighted backend of the
+// tunnel group backends of the route's weighted backends if it has any, and the tunnel group backend of its
+// backend otherwise.
+func findOrCreateRouteBackend(ctx context.Context, routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec, tunnelGroups *tunnelGroupBackendReconciler, weightedBackends *weightedBackendReconciler) (string, error) {
+	if len(routeSpec.WeightedBackends) == 0 {
+		backend, err := tunnelGroups.findOrCreate(ctx, routeSpec.Backend)
+		if err != nil {
+			return "", err
+		}
+		return backend.ID, nil
+	}
+
+	weights := make(map[string]int64, len(routeSpec.WeightedBackends))
+	for _, weightedBackend := range routeSpec.WeightedBackends {
+		backend, err := tunnelGroups.findOrCreate(ctx, weightedBackend.TunnelGroupBackend)
+		if err != nil {
+			return "", err
+		}
+		weights[backend.ID] += weightedBackend.Weight
+	}
+
+	backend, err := weightedBackends.findOrCreate(ctx, routeSpec.Backend.Description, routeSpec.Backend.Metadata, weights)
+	if err != nil {
+		return "", err
+	}
+	return backend.ID, nil
+}
+
 type edgeRouteModuleUpdater struct {
 	edge *ingressv1alpha1.HTTPSEdge
 
@@ -550,6 +838,7 @@ func (u *edgeRouteModuleUpdater) updateModulesForRoute(ctx context.Context, rout
 		u.setEdgeRouteOAuth,
 		u.setEdgeRouteOIDC,
 		u.setEdgeRouteSAML,
+		u.setEdgeRouteUserAgentFilter,
 		u.setEdgeRouteWebhookVerification,
 		u.setEdgeRoutePolicy,
 	}
@@ -629,6 +918,8 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteCompression(ctx context.Context, ro
 		return client.Delete(ctx, edgeRouteItem(route))
 	}
 
+	// The edge module can only enable compression. Routes choosing algorithms have their compression
+	// translated to a traffic policy instead.
 	log.Info("Updating Compression", "module", compression)
 	_, err := client.Replace(ctx, &ngrok.EdgeRouteCompressionReplace{
 		EdgeID: route.EdgeID,
@@ -781,21 +1072,31 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteOAuth(ctx context.Context, route *n
 		return oauthClient.Delete(ctx, edgeRouteItem(route))
 	}
 
-	var module *ngrok.EndpointOAuth
-	var err error
+	module, err := u.oauthModule(ctx, oauth)
+	if err != nil {
+		return err
+	}
 
-	providers := []OAuthProvider{
-		oauth.Google,
-		oauth.Github,
-		oauth.Gitlab,
-		oauth.Amazon,
-		oauth.Facebook,
-		oauth.Microsoft,
-		oauth.Twitch,
-		oauth.Linkedin,
+	if reflect.DeepEqual(module, route.OAuth) {
+		u.logMatches(log, "OAuth", routeModuleComparisonDeepEqual)
+		return nil
 	}
 
-	for _, p := range providers {
+	log.Info("Updating OAuth module")
+	_, err = oauthClient.Replace(ctx, &ngrok.EdgeRouteOAuthReplace{
+		EdgeID: route.EdgeID,
+		ID:     route.ID,
+		Module: *module,
+	})
+	return err
+}
+
+// oauthModule builds the ngrok OAuth module for the configured provider, looking up its client secret
+func (u *edgeRouteModuleUpdater) oauthModule(ctx context.Context, oauth *ingressv1alpha1.EndpointOAuth) (*ngrok.EndpointOAuth, error) {
+	var module *ngrok.EndpointOAuth
+	var err error
+
+	for _, p := range oauthProviders(oauth) {
 		if !p.Provided() {
 			continue
 		}
@@ -808,7 +1109,7 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteOAuth(ctx context.Context, route *n
 		if secretKeyRef != nil {
 			secret, err = u.getSecret(ctx, *secretKeyRef)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 
@@ -817,21 +1118,9 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteOAuth(ctx context.Context, route *n
 	}
 
 	if module == nil {
-		return ierr.NewErrInvalidConfiguration(fmt.Errorf("no OAuth provider configured"))
+		return nil, ierr.NewErrInvalidConfiguration(fmt.Errorf("no OAuth provider configured"))
 	}
-
-	if reflect.DeepEqual(module, route.OAuth) {
-		u.logMatches(log, "OAuth", routeModuleComparisonDeepEqual)
-		return nil
-	}
-
-	log.Info("Updating OAuth module")
-	_, err = oauthClient.Replace(ctx, &ngrok.EdgeRouteOAuthReplace{
-		EdgeID: route.EdgeID,
-		ID:     route.ID,
-		Module: *module,
-	})
-	return err
+	return module, nil
 }
 
 func (u *edgeRouteModuleUpdater) setEdgeRouteOIDC(ctx context.Context, route *ngrok.HTTPSEdgeRoute, routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec) error {
@@ -909,7 +1198,7 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteSAML(ctx context.Context, route *ng
 		NameIDFormat:       saml.NameIDFormat,
 	}
 
-	if reflect.DeepEqual(&module, route.SAML) {
+	if samlModuleMatches(&module, route.SAML) {
 		u.logMatches(log, "SAML", routeModuleComparisonDeepEqual)
 		return nil
 	}
@@ -923,6 +1212,58 @@ func (u *edgeRouteModuleUpdater) setEdgeRouteSAML(ctx context.Context, route *ng
 	return err
 }
 
+// samlModuleMatches reports whether the route's SAML module has the settings of the desired module. ngrok
+// returns the module with the service provider details it generated, so they're left out of the comparison.
+func samlModuleMatches(desired *ngrok.EndpointSAMLMutate, current *ngrok.EndpointSAML) bool {
+	if current == nil {
+		return false
+	}
+	return desired.OptionsPassthrough == current.OptionsPassthrough &&
+		desired.CookiePrefix == current.CookiePrefix &&
+		desired.InactivityTimeout == current.InactivityTimeout &&
+		desired.MaximumDuration == current.MaximumDuration &&
+		desired.IdPMetadata == current.IdPMetadata &&
+		desired.ForceAuthn == current.ForceAuthn &&
+		ptr.Equal(desired.AllowIdPInitiated, current.AllowIdPInitiated) &&
+		slices.Equal(desired.AuthorizedGroups, current.AuthorizedGroups) &&
+		desired.NameIDFormat == current.NameIDFormat
+}
+
+func (u *edgeRouteModuleUpdater) setEdgeRouteUserAgentFilter(ctx context.Context, route *ngrok.HTTPSEdgeRoute, routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec) error {
+	log := ctrl.LoggerFrom(ctx)
+	userAgentFilter := routeSpec.UserAgentFilter
+
+	client := u.clientset.UserAgentFilter()
+
+	if userAgentFilter == nil {
+		if route.UserAgentFilter == nil {
+			u.logMatches(log, "User Agent Filter", routeModuleComparisonBothNil)
+			return nil
+		}
+
+		log.Info("Deleting User Agent Filter module")
+		return client.Delete(ctx, edgeRouteItem(route))
+	}
+
+	if current := route.UserAgentFilter; current != nil &&
+		slices.Equal(current.UserAgentFilterAllow, userAgentFilter.Allow) &&
+		slices.Equal(current.UserAgentFilterDeny, userAgentFilter.Deny) {
+		u.logMatches(log, "User Agent Filter", routeModuleComparisonDeepEqual)
+		return nil
+	}
+
+	log.Info("Updating User Agent Filter module")
+	_, err := client.Replace(ctx, &ngrok.EdgeRouteUserAgentFilterReplace{
+		EdgeID: route.EdgeID,
+		ID:     route.ID,
+		Module: ngrok.EndpointUserAgentFilter{
+			UserAgentFilterAllow: userAgentFilter.Allow,
+			UserAgentFilterDeny:  userAgentFilter.Deny,
+		},
+	})
+	return err
+}
+
 func (u *edgeRouteModuleUpdater) setEdgeRouteWebhookVerification(ctx context.Context, route *ngrok.HTTPSEdgeRoute, routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec) error {
 	log := ctrl.LoggerFrom(ctx)
 	webhookVerification := routeSpec.WebhookVerification
@@ -986,6 +1327,20 @@ type OAuthProvider interface {
 	ToNgrok(*string) *ngrok.EndpointOAuth
 }
 
+// oauthProviders returns every OAuth provider of the module, whether or not it's provided
+func oauthProviders(oauth *ingressv1alpha1.EndpointOAuth) []OAuthProvider {
+	return []OAuthProvider{
+		oauth.Google,
+		oauth.Github,
+		oauth.Gitlab,
+		oauth.Amazon,
+		oauth.Facebook,
+		oauth.Microsoft,
+		oauth.Twitch,
+		oauth.Linkedin,
+	}
+}
+
 // isMigratingAuthProviders returns true if the auth provider is changing
 // It takes in the current ngrok.HTTPSEdgeRoute and the desired ingressv1alpha1.HTTPSEdgeRouteSpec
 // if the current and desired have different auth types (OAuth, OIDC, SAML), it returns true
@@ -1,12 +1,27 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
 	"testing"
 
+	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/backends/weighted"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestControllers(t *testing.T) {
@@ -35,4 +50,143 @@ var _ = Describe("HTTPSEdgeController", func() {
 		Entry("Removed OIDC and Added Oauth", &ngrok.HTTPSEdgeRoute{OIDC: &ngrok.EndpointOIDC{}}, &ingressv1alpha1.HTTPSEdgeRouteSpec{OAuth: &ingressv1alpha1.EndpointOAuth{}}, true),
 		Entry("Removed OIDC and Added SAML", &ngrok.HTTPSEdgeRoute{OIDC: &ngrok.EndpointOIDC{}}, &ingressv1alpha1.HTTPSEdgeRouteSpec{SAML: &ingressv1alpha1.EndpointSAML{}}, true),
 	)
+
+	DescribeTable("samlModuleMatches", func(current *ngrok.EndpointSAML, expected bool) {
+		desired := &ngrok.EndpointSAMLMutate{
+			CookiePrefix:     "ngrok-saml.",
+			IdPMetadata:      "<EntityDescriptor/>",
+			AuthorizedGroups: []string{"engineering"},
+		}
+		Expect(samlModuleMatches(desired, current)).To(Equal(expected))
+	},
+		Entry("no module", nil, false),
+		Entry("same settings with the generated service provider details",
+			&ngrok.EndpointSAML{CookiePrefix: "ngrok-saml.", IdPMetadata: "<EntityDescriptor/>", AuthorizedGroups: []string{"engineering"}, EntityID: "https://idp.ngrok.com/saml/edghtsrt_123", MetadataURL: "https://idp.ngrok.com/saml/edghtsrt_123/metadata"},
+			true),
+		Entry("different IdP metadata",
+			&ngrok.EndpointSAML{CookiePrefix: "ngrok-saml.", IdPMetadata: "<EntityDescriptor entityID=\"old\"/>", AuthorizedGroups: []string{"engineering"}},
+			false),
+		Entry("different authorized groups",
+			&ngrok.EndpointSAML{CookiePrefix: "ngrok-saml.", IdPMetadata: "<EntityDescriptor/>", AuthorizedGroups: []string{"engineering", "sales"}},
+			false),
+	)
+
+	Describe("listEdgesForSecret", func() {
+		var scheme = runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+
+		edge := func(name, namespace string, route ingressv1alpha1.HTTPSEdgeRouteSpec) *ingressv1alpha1.HTTPSEdge {
+			return &ingressv1alpha1.HTTPSEdge{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       ingressv1alpha1.HTTPSEdgeSpec{Routes: []ingressv1alpha1.HTTPSEdgeRouteSpec{route}},
+			}
+		}
+
+		It("Should enqueue the edges with a route module referencing the secret", func() {
+			oidc := edge("oidc", "test-namespace", ingressv1alpha1.HTTPSEdgeRouteSpec{
+				OIDC: &ingressv1alpha1.EndpointOIDC{ClientSecret: ingressv1alpha1.SecretKeyRef{Name: "idp", Key: "client-secret"}},
+			})
+			oauth := edge("oauth", "test-namespace", ingressv1alpha1.HTTPSEdgeRouteSpec{
+				OAuth: &ingressv1alpha1.EndpointOAuth{Google: &ingressv1alpha1.EndpointOAuthGoogle{
+					OAuthProviderCommon: ingressv1alpha1.OAuthProviderCommon{ClientSecret: &ingressv1alpha1.SecretKeyRef{Name: "idp", Key: "google"}},
+				}},
+			})
+			managedOAuth := edge("managed-oauth", "test-namespace", ingressv1alpha1.HTTPSEdgeRouteSpec{
+				OAuth: &ingressv1alpha1.EndpointOAuth{Github: &ingressv1alpha1.EndpointOAuthGitHub{}},
+			})
+			webhook := edge("webhook", "test-namespace", ingressv1alpha1.HTTPSEdgeRouteSpec{
+				WebhookVerification: &ingressv1alpha1.EndpointWebhookVerification{Provider: "github", SecretRef: &ingressv1alpha1.SecretKeyRef{Name: "other", Key: "secret"}},
+			})
+			otherNamespace := edge("oidc", "other-namespace", ingressv1alpha1.HTTPSEdgeRouteSpec{
+				OIDC: &ingressv1alpha1.EndpointOIDC{ClientSecret: ingressv1alpha1.SecretKeyRef{Name: "idp", Key: "client-secret"}},
+			})
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(oidc, oauth, managedOAuth, webhook, otherNamespace).Build()
+			r := &HTTPSEdgeReconciler{Client: c, Log: logr.Discard()}
+
+			secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "idp", Namespace: "test-namespace"}}
+			Expect(r.listEdgesForSecret(context.Background(), secret)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "oidc", Namespace: "test-namespace"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "oauth", Namespace: "test-namespace"}},
+			))
+		})
+
+		It("Should enqueue the edges loading mutual TLS certificate authorities from the secret or config map", func() {
+			secretCA := &ingressv1alpha1.HTTPSEdge{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-ca", Namespace: "test-namespace"},
+				Spec: ingressv1alpha1.HTTPSEdgeSpec{
+					MutualTLS: &ingressv1alpha1.EndpointMutualTLS{CertificateAuthoritySecrets: []string{"client-ca"}},
+				},
+			}
+			configMapCA := &ingressv1alpha1.HTTPSEdge{
+				ObjectMeta: metav1.ObjectMeta{Name: "config-map-ca", Namespace: "test-namespace"},
+				Spec: ingressv1alpha1.HTTPSEdgeSpec{
+					MutualTLS: &ingressv1alpha1.EndpointMutualTLS{CertificateAuthorityConfigMaps: []string{"client-ca"}},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secretCA, configMapCA).Build()
+			r := &HTTPSEdgeReconciler{Client: c, Log: logr.Discard()}
+
+			meta := metav1.ObjectMeta{Name: "client-ca", Namespace: "test-namespace"}
+			Expect(r.listEdgesForSecret(context.Background(), &v1.Secret{ObjectMeta: meta})).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "secret-ca", Namespace: "test-namespace"}},
+			))
+			Expect(r.listEdgesForConfigMap(context.Background(), &v1.ConfigMap{ObjectMeta: meta})).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "config-map-ca", Namespace: "test-namespace"}},
+			))
+		})
+	})
+
+	Describe("deleteUnusedWeightedBackends", func() {
+		var scheme = runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+
+		var (
+			deleted  []string
+			ngrokAPI *httptest.Server
+		)
+
+		BeforeEach(func() {
+			deleted = nil
+			ngrokAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					resp := ngrok.WeightedBackendList{Backends: []ngrok.WeightedBackend{
+						{ID: "bkdwd_old", Backends: map[string]int64{"bkdtg_stable": 90, "bkdtg_canary": 10}},
+						{ID: "bkdwd_shared", Backends: map[string]int64{"bkdtg_stable": 50, "bkdtg_canary": 50}},
+					}}
+					Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+				case http.MethodDelete:
+					deleted = append(deleted, path.Base(r.URL.Path))
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			DeferCleanup(ngrokAPI.Close)
+		})
+
+		edgeWithBackends := func(name string, backendIDs ...string) *ingressv1alpha1.HTTPSEdge {
+			edge := &ingressv1alpha1.HTTPSEdge{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-namespace"}}
+			for _, id := range backendIDs {
+				edge.Status.Routes = append(edge.Status.Routes, ingressv1alpha1.HTTPSEdgeRouteStatus{
+					Backend: ingressv1alpha1.TunnelGroupBackendStatus{ID: id},
+				})
+			}
+			return edge
+		}
+
+		It("Should delete the weighted backends no edge uses anymore", func() {
+			edge := edgeWithBackends("edge", "bkdtg_stable")
+			other := edgeWithBackends("other", "bkdwd_shared")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(edge, other).Build()
+			r := &HTTPSEdgeReconciler{Client: c, Log: logr.Discard()}
+			weightedBackends := newWeightedBackendReconciler(weighted.NewClient(ngrok.NewClientConfig("test-api-key", ngrok.WithBaseURL(ngrokAPI.URL))))
+
+			previous := []string{"bkdwd_old", "bkdwd_shared", "bkdtg_canary", "bkdtg_stable"}
+			Expect(r.deleteUnusedWeightedBackends(context.Background(), edge, weightedBackends, previous, []string{"bkdtg_stable"})).To(Succeed())
+			Expect(deleted).To(Equal([]string{"bkdwd_old"}))
+		})
+	})
 })
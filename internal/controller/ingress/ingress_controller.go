@@ -9,13 +9,20 @@ import (
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
 	internalerrors "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // This implements the Reconciler for the controller-runtime
@@ -27,28 +34,74 @@ type IngressReconciler struct {
 	Recorder             record.EventRecorder
 	Namespace            string
 	AnnotationsExtractor annotations.Extractor
+	DeprecationWarner    *annotations.DeprecationWarner
 	Driver               *store.Driver
 }
 
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	storedResources := []client.Object{
 		&netv1.IngressClass{},
-		&corev1.Service{},
 		&ingressv1alpha1.Domain{},
 		&ingressv1alpha1.HTTPSEdge{},
+		&ingressv1alpha1.TCPEdge{},
+		&ingressv1alpha1.IPPolicy{},
 		&ingressv1alpha1.Tunnel{},
 		&ingressv1alpha1.NgrokModuleSet{},
+		&ingressv1alpha1.NgrokIngressClassParams{},
 		&ngrokv1alpha1.NgrokTrafficPolicy{},
 	}
 
-	builder := ctrl.NewControllerManagedBy(mgr).For(&netv1.Ingress{})
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).For(&netv1.Ingress{})
 	for _, obj := range storedResources {
-		builder = builder.Watches(
+		controllerBuilder = controllerBuilder.Watches(
 			obj,
 			store.NewUpdateStoreHandler(obj.GetObjectKind().GroupVersionKind().Kind, r.Driver, r.Client))
 	}
 
-	return builder.Complete(r)
+	// Only the secrets referenced by module sets, or already looked up for one, are kept in the store
+	controllerBuilder = controllerBuilder.Watches(
+		&corev1.Secret{},
+		store.NewUpdateStoreHandler("Secret", r.Driver, r.Client),
+		builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return r.Driver.IsSecretReferenced(obj.GetName(), obj.GetNamespace())
+		})))
+
+	controllerBuilder = controllerBuilder.Watches(
+		&corev1.Service{},
+		&serviceBackendHandler{
+			UpdateStoreHandler: store.NewUpdateStoreHandler("Service", r.Driver, r.Client),
+			driver:             r.Driver,
+		})
+
+	return controllerBuilder.Complete(r)
+}
+
+// serviceBackendHandler keeps services updated in the store like the UpdateStoreHandler. Additionally, when a
+// service is created or deleted, it enqueues the ingresses using it as a backend so their edges can react to
+// the backend appearing or going away.
+type serviceBackendHandler struct {
+	*store.UpdateStoreHandler
+	driver *store.Driver
+}
+
+// Create is called in response to a service being created
+func (h *serviceBackendHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.UpdateStoreHandler.Create(ctx, evt, q)
+	h.enqueueIngresses(evt.Object, q)
+}
+
+// Delete is called in response to a service being deleted
+func (h *serviceBackendHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.UpdateStoreHandler.Delete(ctx, evt, q)
+	h.enqueueIngresses(evt.Object, q)
+}
+
+func (h *serviceBackendHandler) enqueueIngresses(obj client.Object, q workqueue.RateLimitingInterface) {
+	for _, ing := range h.driver.ListNgrokIngressesForService(obj.GetName(), obj.GetNamespace()) {
+		q.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ing.Name, Namespace: ing.Namespace},
+		})
+	}
 }
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
@@ -59,6 +112,7 @@ func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses/status,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups="networking.k8s.io",resources=ingressclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=ngrokmodulesets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=ngrokingressclassparams,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngroktrafficpolicies,verbs=get;list;watch
 
 // This reconcile function is called by the controller-runtime manager.
@@ -85,35 +139,61 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			log.Error(err, "Failed to sync after removing ingress from store")
 			return ctrl.Result{}, err
 		}
+		r.Driver.RecordIngressIssues()
 
 		return ctrl.Result{}, nil
 	default:
 		return ctrl.Result{}, err
 	}
 
+	// Periodic resyncs reconcile every ingress, so skip the ones that haven't changed since they were last
+	// reconciled to avoid needlessly syncing with the API server
+	if r.Driver.IngressUnchanged(ingress) {
+		log.V(1).Info("Ingress is unchanged since it was last reconciled, skipping")
+		return ctrl.Result{}, nil
+	}
+
 	// Ensure the ingress object is up to date in the store
 	// Leverage the store to ensure this works off the same data as everything else
-	ingress, err = r.Driver.UpdateIngress(ingress)
+	handled, err := r.Driver.UpdateIngress(ingress)
 	switch {
 	case err == nil:
-		// all good, continue
+		r.Driver.RecordIngressHandled(ingress, nil)
+		ingress = handled
 	case internalerrors.IsErrDifferentIngressClass(err):
 		log.Info("Ingress is not of type ngrok so skipping it")
+		// Only warn about ingresses the controller handled before their class changed. The ingresses of
+		// other controllers are theirs to report on.
+		if controllers.HasFinalizer(ingress) {
+			r.Driver.RecordIngressHandled(ingress, err)
+		}
 		return ctrl.Result{}, nil
 	case internalerrors.IsErrInvalidIngressSpec(err):
 		log.Info("Ingress is not valid so skipping it")
 		return ctrl.Result{}, nil
+	case internalerrors.IsErrIngressIgnored(err):
+		log.Info("Ingress is annotated to be ignored so skipping it")
+		return ctrl.Result{}, nil
 	default:
 		log.Error(err, "Failed to get ingress from store")
 		return ctrl.Result{}, err
 	}
 
 	if controllers.IsUpsert(ingress) {
+		r.DeprecationWarner.Warn(ingress)
+
 		// The object is not being deleted, so register and sync finalizer
 		if err := controllers.RegisterAndSyncFinalizer(ctx, r.Client, ingress); err != nil {
 			log.Error(err, "Failed to register finalizer")
 			return ctrl.Result{}, err
 		}
+
+		// Registering the finalizer may have updated our copy of the ingress, so store it to keep the store
+		// at the latest resource version for the status updates made while syncing
+		if ingress, err = r.Driver.UpdateIngress(ingress); err != nil {
+			log.Error(err, "Failed to update ingress in store")
+			return ctrl.Result{}, err
+		}
 	} else {
 		log.Info("Deleting ingress from store")
 		if controllers.HasFinalizer(ingress) {
@@ -132,7 +212,27 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	err = r.Driver.Sync(ctx, r.Client)
 	if err != nil {
 		log.Error(err, "Failed to sync")
+		return ctrl.Result{}, err
+	}
+	r.Driver.RecordIngressIssues()
+
+	if controllers.IsUpsert(ingress) {
+		// The edges for this ingress depend on its domains being reserved first. Rather than erroring,
+		// requeue through the rate limiter so we back off until the domain controller catches up.
+		err = r.Driver.CheckIngressDomainsReady(ingress)
+		switch {
+		case err == nil:
+			// all good, continue
+		case internalerrors.IsNotAllDomainsReadyYet(err):
+			log.Info("Domains for ingress are not ready yet, requeueing")
+			r.Recorder.Event(ingress, corev1.EventTypeNormal, events.ReasonDomainNotReady, "Waiting for the ingress's domains to be reserved")
+			return ctrl.Result{Requeue: true}, nil
+		default:
+			return ctrl.Result{}, err
+		}
+
+		r.Driver.MarkIngressReconciled(ingress)
 	}
 
-	return ctrl.Result{}, err
+	return ctrl.Result{}, nil
 }
@@ -1,7 +1,27 @@
 package controllers
 
 import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
 )
 
 //nolint:unused
@@ -15,3 +35,203 @@ func makeTestBackend(serviceName string, servicePort int32) netv1.IngressBackend
 		},
 	}
 }
+
+var _ = Describe("IngressReconciler", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+	Describe("Reconcile", func() {
+		It("Should requeue until the ingress's domain is ready and then succeed", func() {
+			ctx := context.Background()
+			ic := store.NewTestIngressClass("ngrok", true, true)
+			ing := store.NewTestIngressV1WithClass("test-ingress", "test-namespace", "ngrok")
+			svc := store.NewTestServiceV1("example", "test-namespace")
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&ic, &ing, &svc).
+				WithStatusSubresource(&netv1.Ingress{}, &ingressv1alpha1.Domain{}).
+				Build()
+
+			driver := store.NewDriver(
+				logr.Discard(),
+				scheme,
+				"k8s.ngrok.com/ingress-controller",
+				types.NamespacedName{Name: "ngrok-ingress-controller"},
+				false,
+			)
+			Expect(driver.Seed(ctx, c)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			r := &IngressReconciler{
+				Client:   c,
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: recorder,
+				Driver:   driver,
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-ingress", Namespace: "test-namespace"}}
+
+			By("requeueing while the domain has not been reserved")
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal DomainNotReady ")))
+
+			domain := &ingressv1alpha1.Domain{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "example-com", Namespace: "test-namespace"}, domain)).To(Succeed())
+			store.NewUpdateStoreHandler("Domain", driver, c).Create(ctx, event.CreateEvent{Object: domain}, nil)
+
+			result, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+
+			edges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(ctx, edges)).To(Succeed())
+			Expect(edges.Items).To(BeEmpty())
+
+			By("succeeding once the domain is ready")
+			oldDomain := domain.DeepCopy()
+			domain.Status.ID = "rd_123"
+			Expect(c.Status().Update(ctx, domain)).To(Succeed())
+			store.NewUpdateStoreHandler("Domain", driver, c).Update(ctx, event.UpdateEvent{ObjectOld: oldDomain, ObjectNew: domain}, nil)
+
+			result, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+
+			Expect(c.List(ctx, edges)).To(Succeed())
+			Expect(edges.Items).To(HaveLen(1))
+		})
+
+		It("Should skip unchanged ingresses on resync", func() {
+			ctx := context.Background()
+			ic := store.NewTestIngressClass("ngrok", true, true)
+			ing := store.NewTestIngressV1WithClass("test-ingress", "test-namespace", "ngrok")
+			svc := store.NewTestServiceV1("example", "test-namespace")
+			domain := store.NewReservedDomainV1("example.com", "test-namespace")
+
+			// syncing lists the existing resources, so count the lists to tell whether the ingress was synced
+			lists := 0
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&ic, &ing, &svc, &domain).
+				WithStatusSubresource(&netv1.Ingress{}, &ingressv1alpha1.Domain{}).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						lists++
+						return c.List(ctx, list, opts...)
+					},
+				}).
+				Build()
+
+			driver := store.NewDriver(
+				logr.Discard(),
+				scheme,
+				"k8s.ngrok.com/ingress-controller",
+				types.NamespacedName{Name: "ngrok-ingress-controller"},
+				false,
+			)
+			Expect(driver.Seed(ctx, c)).To(Succeed())
+
+			r := &IngressReconciler{
+				Client:   c,
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+				Driver:   driver,
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-ingress", Namespace: "test-namespace"}}
+
+			By("syncing the ingress the first time")
+			lists = 0
+			result, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+			Expect(lists).ToNot(BeZero())
+
+			By("skipping the ingress on resync when nothing changed")
+			lists = 0
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(BeZero())
+
+			By("syncing the ingress again once it changes")
+			current := &netv1.Ingress{}
+			Expect(c.Get(ctx, req.NamespacedName, current)).To(Succeed())
+			current.Annotations = map[string]string{"k8s.ngrok.com/labels": "team=payments"}
+			Expect(c.Update(ctx, current)).To(Succeed())
+
+			lists = 0
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).ToNot(BeZero())
+
+			By("skipping the ingress on a resync of a watched resource")
+			store.NewUpdateStoreHandler("Service", driver, c).Update(ctx, event.UpdateEvent{ObjectOld: &svc, ObjectNew: &svc}, nil)
+			lists = 0
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(BeZero())
+
+			By("syncing the ingress again once a watched resource changes")
+			oldSvc := svc.DeepCopy()
+			svc.ResourceVersion = "changed"
+			store.NewUpdateStoreHandler("Service", driver, c).Update(ctx, event.UpdateEvent{ObjectOld: oldSvc, ObjectNew: &svc}, nil)
+
+			lists = 0
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).ToNot(BeZero())
+		})
+
+		It("Should only warn about the class of ingresses it handled before", func() {
+			ctx := context.Background()
+			ic := store.NewTestIngressClass("ngrok", true, true)
+			ing := store.NewTestIngressV1WithClass("test-ingress", "test-namespace", "ngrok")
+			otherIng := store.NewTestIngressV1WithClass("other-ingress", "test-namespace", "nginx")
+			movedIng := store.NewTestIngressV1WithClass("moved-ingress", "test-namespace", "nginx")
+			controllers.AddFinalizer(&movedIng)
+			svc := store.NewTestServiceV1("example", "test-namespace")
+			domain := store.NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&ic, &ing, &otherIng, &movedIng, &svc, &domain).
+				WithStatusSubresource(&netv1.Ingress{}, &ingressv1alpha1.Domain{}).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			driver := store.NewDriver(
+				logr.Discard(),
+				scheme,
+				"k8s.ngrok.com/ingress-controller",
+				types.NamespacedName{Name: "ngrok-ingress-controller"},
+				false,
+			).WithEventRecorder(recorder)
+			Expect(driver.Seed(ctx, c)).To(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+
+			r := &IngressReconciler{
+				Client:   c,
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: recorder,
+				Driver:   driver,
+			}
+			reconcile := func(name string) []string {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: "test-namespace"}})
+				Expect(err).ToNot(HaveOccurred())
+				var recorded []string
+				for len(recorder.Events) > 0 {
+					recorded = append(recorded, <-recorder.Events)
+				}
+				return recorded
+			}
+
+			Expect(reconcile("test-ingress")).To(ContainElement(HavePrefix("Normal IngressAccepted ")))
+			Expect(reconcile("other-ingress")).To(BeEmpty())
+			Expect(reconcile("moved-ingress")).To(ConsistOf(HavePrefix("Warning IngressClassMismatch ")))
+		})
+	})
+})
@@ -25,8 +25,10 @@ SOFTWARE.
 package controllers
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +39,7 @@ import (
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
 	"github.com/ngrok/ngrok-api-go/v5"
 	"github.com/ngrok/ngrok-api-go/v5/ip_policies"
 	"github.com/ngrok/ngrok-api-go/v5/ip_policy_rules"
@@ -121,6 +124,7 @@ func (r *IPPolicyReconciler) update(ctx context.Context, policy *ingressv1alpha1
 	if err != nil {
 		if ngrok.IsNotFound(err) {
 			policy.Status.ID = ""
+			policy.Status.Rules = nil
 			return r.Status().Update(ctx, policy)
 		}
 		return err
@@ -128,7 +132,7 @@ func (r *IPPolicyReconciler) update(ctx context.Context, policy *ingressv1alpha1
 
 	if remotePolicy.Description != policy.Spec.Description ||
 		remotePolicy.Metadata != policy.Spec.Metadata {
-		r.Recorder.Event(policy, v1.EventTypeNormal, "Updating", fmt.Sprintf("Updating IPPolicy %s", policy.Name))
+		r.Recorder.Event(policy, v1.EventTypeNormal, events.ReasonUpdating, fmt.Sprintf("Updating IPPolicy %s", policy.Name))
 		_, err := r.IPPoliciesClient.Update(ctx, &ngrok.IPPolicyUpdate{
 			ID:          policy.Status.ID,
 			Description: ptr.To(policy.Spec.Description),
@@ -137,7 +141,7 @@ func (r *IPPolicyReconciler) update(ctx context.Context, policy *ingressv1alpha1
 		if err != nil {
 			return err
 		}
-		r.Recorder.Event(policy, v1.EventTypeNormal, "Updated", fmt.Sprintf("Updated IPPolicy %s", policy.Name))
+		r.Recorder.Event(policy, v1.EventTypeNormal, events.ReasonUpdated, fmt.Sprintf("Updated IPPolicy %s", policy.Name))
 	}
 
 	return r.createOrUpdateIPPolicyRules(ctx, policy)
@@ -147,23 +151,46 @@ func (r *IPPolicyReconciler) delete(ctx context.Context, policy *ingressv1alpha1
 	err := r.IPPoliciesClient.Delete(ctx, policy.Status.ID)
 	if err == nil || ngrok.IsNotFound(err) {
 		policy.Status.ID = ""
+		policy.Status.Rules = nil
 	}
 	return err
 }
 
+// createOrUpdateIPPolicyRules applies the difference between the policy's spec and remote rules, so rules that
+// don't change keep their IDs, and records the rules the policy ends up with in its status. The status is
+// recorded even when applying a change fails, so it always reflects the rules in ngrok.
 func (r *IPPolicyReconciler) createOrUpdateIPPolicyRules(ctx context.Context, policy *ingressv1alpha1.IPPolicy) error {
 	remoteRules, err := r.getRemotePolicyRules(ctx, policy.Status.ID)
 	if err != nil {
 		return err
 	}
-	iter := newIPPolicyDiff(policy.Status.ID, remoteRules, policy.Spec.Rules)
 
+	applied := make(map[string]*ngrok.IPPolicyRule, len(remoteRules))
+	for _, rule := range remoteRules {
+		applied[rule.ID] = rule
+	}
+
+	err = r.applyIPPolicyDiff(ctx, policy, newIPPolicyDiff(policy.Status.ID, remoteRules, policy.Spec.Rules), applied)
+
+	rules := ipPolicyRuleStatuses(applied)
+	if !slices.Equal(policy.Status.Rules, rules) {
+		policy.Status.Rules = rules
+		if statusErr := r.Status().Update(ctx, policy); statusErr != nil && err == nil {
+			err = statusErr
+		}
+	}
+	return err
+}
+
+// applyIPPolicyDiff makes the changes of the diff, keeping applied up to date with the rules in ngrok by ID
+func (r *IPPolicyReconciler) applyIPPolicyDiff(ctx context.Context, policy *ingressv1alpha1.IPPolicy, iter *IPPolicyDiff, applied map[string]*ngrok.IPPolicyRule) error {
 	for iter.Next() {
 		for _, d := range iter.NeedsDelete() {
 			r.Log.V(3).Info("Deleting IP Policy Rule", "id", d.ID, "policy.id", policy.Status.ID, "cidr", d.CIDR, "action", d.Action)
-			if err := r.IPPolicyRulesClient.Delete(ctx, d.ID); err != nil {
+			if err := r.IPPolicyRulesClient.Delete(ctx, d.ID); err != nil && !ngrok.IsNotFound(err) {
 				return err
 			}
+			delete(applied, d.ID)
 			r.Log.V(3).Info("Deleted IP Policy Rule", "id", d.ID)
 		}
 
@@ -173,6 +200,7 @@ func (r *IPPolicyReconciler) createOrUpdateIPPolicyRules(ctx context.Context, po
 			if err != nil {
 				return err
 			}
+			applied[rule.ID] = rule
 			r.Log.V(3).Info("Created IP Policy Rule", "id", rule.ID, "policy.id", policy.Status.ID, "cidr", rule.CIDR, "action", rule.Action)
 		}
 
@@ -182,6 +210,7 @@ func (r *IPPolicyReconciler) createOrUpdateIPPolicyRules(ctx context.Context, po
 			if err != nil {
 				return err
 			}
+			applied[rule.ID] = rule
 			r.Log.V(3).Info("Updated IP Policy Rule", "id", rule.ID, "policy.id", policy.Status.ID)
 		}
 	}
@@ -189,6 +218,28 @@ func (r *IPPolicyReconciler) createOrUpdateIPPolicyRules(ctx context.Context, po
 	return nil
 }
 
+// ipPolicyRuleStatuses returns the status of the rules ordered by CIDR and action, so the status only changes
+// when the rules do
+func ipPolicyRuleStatuses(rules map[string]*ngrok.IPPolicyRule) []ingressv1alpha1.IPPolicyRuleStatus {
+	statuses := make([]ingressv1alpha1.IPPolicyRuleStatus, 0, len(rules))
+	for _, rule := range rules {
+		statuses = append(statuses, ingressv1alpha1.IPPolicyRuleStatus{ID: rule.ID, CIDR: rule.CIDR, Action: rule.Action})
+	}
+	slices.SortFunc(statuses, func(a, b ingressv1alpha1.IPPolicyRuleStatus) int {
+		if c := cmp.Compare(a.CIDR, b.CIDR); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Action, b.Action); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if len(statuses) == 0 {
+		return nil
+	}
+	return statuses
+}
+
 func (r *IPPolicyReconciler) getRemotePolicyRules(ctx context.Context, policyID string) ([]*ngrok.IPPolicyRule, error) {
 	iter := r.IPPolicyRulesClient.List(&ngrok.Paging{})
 	rules := make([]*ngrok.IPPolicyRule, 0)
@@ -223,6 +274,9 @@ type IPPolicyDiff struct {
 	specDeny    map[string]ingressv1alpha1.IPPolicyRule
 	specAllow   map[string]ingressv1alpha1.IPPolicyRule
 
+	// remoteDuplicates are remote rules for a CIDR that already has a rule with the same action
+	remoteDuplicates []*ngrok.IPPolicyRule
+
 	creates []*ngrok.IPPolicyRuleCreate
 	deletes []*ngrok.IPPolicyRule
 	updates []*ngrok.IPPolicyRuleUpdate
@@ -239,11 +293,15 @@ func newIPPolicyDiff(policyID string, remote []*ngrok.IPPolicyRule, spec []ingre
 
 	// Group the remote rules by their CIDR
 	for _, rule := range remote {
+		group := diff.remoteAllow
 		if rule.Action == IPPolicyRuleActionDeny {
-			diff.remoteDeny[rule.CIDR] = rule
-		} else {
-			diff.remoteAllow[rule.CIDR] = rule
+			group = diff.remoteDeny
+		}
+		if _, ok := group[rule.CIDR]; ok {
+			diff.remoteDuplicates = append(diff.remoteDuplicates, rule)
+			continue
 		}
+		group[rule.CIDR] = rule
 	}
 
 	// Group the spec rules by their CIDR
@@ -297,7 +355,8 @@ func (d *IPPolicyDiff) Next() bool {
 			}
 		}
 		return true
-	case 4: // Delete any remaining rules that are not in the spec.
+	case 4: // Delete any remaining rules that are not in the spec, and any duplicates of the rules that are.
+		d.deletes = append(d.deletes, d.remoteDuplicates...)
 		for cidr, rule := range d.remoteAllow {
 			if !d.existsInSpec(cidr) {
 				d.deletes = append(d.deletes, rule)
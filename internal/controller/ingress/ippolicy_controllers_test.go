@@ -73,3 +73,45 @@ func TestIPPolicyDiff(t *testing.T) {
 
 	assert.False(t, diff.Next())
 }
+
+func TestIPPolicyDiffDeletesDuplicateRules(t *testing.T) {
+	remoteRules := []*ngrok.IPPolicyRule{
+		{ID: "1", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionAllow},
+		{ID: "2", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionAllow},
+	}
+	specRules := []ingressv1alpha1.IPPolicyRule{
+		{CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionAllow},
+	}
+
+	diff := newIPPolicyDiff("test", remoteRules, specRules)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, diff.Next())
+		assert.Empty(t, diff.NeedsCreate())
+		assert.Empty(t, diff.NeedsDelete())
+	}
+
+	// the first rule for the CIDR is kept, so its ID doesn't change
+	assert.True(t, diff.Next())
+	assert.Equal(t, []*ngrok.IPPolicyRule{remoteRules[1]}, diff.NeedsDelete())
+
+	assert.True(t, diff.Next())
+	assert.Empty(t, diff.NeedsUpdate())
+
+	assert.False(t, diff.Next())
+}
+
+func TestIPPolicyRuleStatuses(t *testing.T) {
+	assert.Nil(t, ipPolicyRuleStatuses(map[string]*ngrok.IPPolicyRule{}))
+
+	rules := map[string]*ngrok.IPPolicyRule{
+		"ipr_2": {ID: "ipr_2", CIDR: "192.168.0.0/16", Action: IPPolicyRuleActionDeny},
+		"ipr_1": {ID: "ipr_1", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionAllow},
+		"ipr_3": {ID: "ipr_3", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionDeny},
+	}
+	assert.Equal(t, []ingressv1alpha1.IPPolicyRuleStatus{
+		{ID: "ipr_1", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionAllow},
+		{ID: "ipr_3", CIDR: "10.0.0.0/8", Action: IPPolicyRuleActionDeny},
+		{ID: "ipr_2", CIDR: "192.168.0.0/16", Action: IPPolicyRuleActionDeny},
+	}, ipPolicyRuleStatuses(rules))
+}
@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ierr "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"golang.org/x/exp/maps"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// resolvedRouteModules holds the values looked up from the cluster that translating a route's modules needs
+type resolvedRouteModules struct {
+	// ipPolicyIDs are the IDs of the IP policies the route's IP restriction references
+	ipPolicyIDs []string
+	// oauth is the route's OAuth module, with its client secret filled in
+	oauth *ngrok.EndpointOAuth
+	// oidcClientSecret is the client secret of the route's OIDC module
+	oidcClientSecret string
+}
+
+// oauthProviderConfig is the configuration shared by all of the ngrok OAuth providers
+type oauthProviderConfig struct {
+	ClientID       *string  `json:"client_id,omitempty"`
+	ClientSecret   *string  `json:"client_secret,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	EmailDomains   []string `json:"email_domains,omitempty"`
+	Teams          []string `json:"teams,omitempty"`
+	Organizations  []string `json:"organizations,omitempty"`
+}
+
+// oauthActionConfig is the configuration of the traffic policy oauth action
+type oauthActionConfig struct {
+	Provider                string   `json:"provider"`
+	ClientID                *string  `json:"client_id,omitempty"`
+	ClientSecret            *string  `json:"client_secret,omitempty"`
+	Scopes                  []string `json:"scopes,omitempty"`
+	AllowCORSPreflight      bool     `json:"allow_cors_preflight,omitempty"`
+	AuthID                  string   `json:"auth_id,omitempty"`
+	IdleSessionTimeout      string   `json:"idle_session_timeout,omitempty"`
+	MaxSessionDuration      string   `json:"max_session_duration,omitempty"`
+	UserinfoRefreshInterval string   `json:"userinfo_refresh_interval,omitempty"`
+}
+
+// oidcActionConfig is the configuration of the traffic policy openid-connect action
+type oidcActionConfig struct {
+	IssuerURL          string   `json:"issuer_url"`
+	ClientID           string   `json:"client_id"`
+	ClientSecret       string   `json:"client_secret"`
+	Scopes             []string `json:"scopes,omitempty"`
+	AllowCORSPreflight bool     `json:"allow_cors_preflight,omitempty"`
+	AuthID             string   `json:"auth_id,omitempty"`
+	IdleSessionTimeout string   `json:"idle_session_timeout,omitempty"`
+	MaxSessionDuration string   `json:"max_session_duration,omitempty"`
+}
+
+// restrictIPsActionConfig is the configuration of the traffic policy restrict-ips action
+type restrictIPsActionConfig struct {
+	Enforce    bool     `json:"enforce"`
+	IPPolicies []string `json:"ip_policies"`
+}
+
+// compressResponseActionConfig is the configuration of the traffic policy compress-response action
+type compressResponseActionConfig struct {
+	Algorithms []string `json:"algorithms,omitempty"`
+}
+
+// denyActionConfig is the configuration of the traffic policy deny action
+type denyActionConfig struct {
+	StatusCode int `json:"status_code"`
+}
+
+// translateModulesToPolicy looks up what the route's modules reference and translates them into its traffic
+// policy. See translateRouteModules for which modules are translated.
+func (u *edgeRouteModuleUpdater) translateModulesToPolicy(ctx context.Context, routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec) (*ingressv1alpha1.HTTPSEdgeRouteSpec, error) {
+	resolved := resolvedRouteModules{}
+
+	if routeSpec.IPRestriction != nil && len(routeSpec.IPRestriction.IPPolicies) > 0 {
+		ids, err := u.ipPolicyResolver.ResolveIPPolicyNamesorIds(ctx, u.edge.Namespace, routeSpec.IPRestriction.IPPolicies)
+		if err != nil {
+			return nil, err
+		}
+		resolved.ipPolicyIDs = ids
+	}
+
+	if routeSpec.OAuth != nil {
+		module, err := u.oauthModule(ctx, routeSpec.OAuth)
+		if err != nil {
+			return nil, err
+		}
+		resolved.oauth = module
+	}
+
+	if routeSpec.OIDC != nil {
+		clientSecret, err := u.getSecret(ctx, routeSpec.OIDC.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		if clientSecret == nil {
+			return nil, ierr.NewErrMissingRequiredSecret("missing clientSecret for OIDC")
+		}
+		resolved.oidcClientSecret = *clientSecret
+	}
+
+	translated, err := translateRouteModules(routeSpec, resolved)
+	if err != nil {
+		return nil, err
+	}
+	ctrl.LoggerFrom(ctx).V(1).Info("Translated route modules to traffic policy", "policy", string(translated.Policy))
+	return translated, nil
+}
+
+// translateRouteModules returns a copy of the route with its compression, IP restriction, headers, OAuth and
+// OIDC modules translated into rules of its traffic policy, and the modules themselves removed. The rules go
+// before the route's own policy rules, in the order the modules are applied to traffic. OAuth using GitHub
+// teams or organizations can't be expressed in a traffic policy, so it is left as a module, as are headers
+// interpolating variables without a traffic policy equivalent and the modules that aren't translated.
+func translateRouteModules(routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec, resolved resolvedRouteModules) (*ingressv1alpha1.HTTPSEdgeRouteSpec, error) {
+	translated := routeSpec.DeepCopy()
+	inbound := []ingressv1alpha1.EndpointRule{}
+	outbound := []ingressv1alpha1.EndpointRule{}
+
+	if len(resolved.ipPolicyIDs) > 0 {
+		rule, err := policyRule("IP Restriction", nil, "restrict-ips", restrictIPsActionConfig{Enforce: true, IPPolicies: resolved.ipPolicyIDs})
+		if err != nil {
+			return nil, err
+		}
+		inbound = append(inbound, rule)
+	}
+	translated.IPRestriction = nil
+
+	if resolved.oauth != nil {
+		rules, ok, err := oauthPolicyRules(resolved.oauth)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			inbound = append(inbound, rules...)
+			translated.OAuth = nil
+		}
+	}
+
+	if oidc := routeSpec.OIDC; oidc != nil {
+		rule, err := policyRule("OIDC", nil, "openid-connect", oidcActionConfig{
+			IssuerURL:          oidc.Issuer,
+			ClientID:           oidc.ClientID,
+			ClientSecret:       resolved.oidcClientSecret,
+			Scopes:             oidc.Scopes,
+			AllowCORSPreflight: oidc.OptionsPassthrough,
+			AuthID:             oidc.CookiePrefix,
+			IdleSessionTimeout: policyDuration(uint32(oidc.InactivityTimeout.Seconds())),
+			MaxSessionDuration: policyDuration(uint32(oidc.MaximumDuration.Seconds())),
+		})
+		if err != nil {
+			return nil, err
+		}
+		inbound = append(inbound, rule)
+		translated.OIDC = nil
+	}
+
+	if headers := routeSpec.Headers; headers != nil && headersTranslatable(headers) {
+		if headers.Request != nil {
+			rule, ok, err := headersPolicyRule("Request Headers", headers.Request.Add, headers.Request.Remove)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				inbound = append(inbound, rule)
+			}
+		}
+		if headers.Response != nil {
+			rule, ok, err := headersPolicyRule("Response Headers", headers.Response.Add, headers.Response.Remove)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				outbound = append(outbound, rule)
+			}
+		}
+		translated.Headers = nil
+	}
+
+	if compression := routeSpec.Compression; compression != nil && compression.Enabled {
+		rule, err := compressionPolicyRule(compression)
+		if err != nil {
+			return nil, err
+		}
+		outbound = append(outbound, rule)
+	}
+	translated.Compression = nil
+
+	return withPolicyRules(translated, inbound, outbound)
+}
+
+// translateCompressionToPolicy translates the route's compression module into its traffic policy when the module
+// chooses algorithms, which the compression edge module can't do. Otherwise the route is returned as is.
+func translateCompressionToPolicy(routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec) (*ingressv1alpha1.HTTPSEdgeRouteSpec, error) {
+	compression := routeSpec.Compression
+	if compression == nil || !compression.Enabled || len(compression.Algorithms) == 0 {
+		return routeSpec, nil
+	}
+
+	rule, err := compressionPolicyRule(compression)
+	if err != nil {
+		return nil, err
+	}
+	translated := routeSpec.DeepCopy()
+	translated.Compression = nil
+	return withPolicyRules(translated, nil, []ingressv1alpha1.EndpointRule{rule})
+}
+
+// compressionPolicyRule translates the compression module into a compress-response action
+func compressionPolicyRule(compression *ingressv1alpha1.EndpointCompression) (ingressv1alpha1.EndpointRule, error) {
+	if len(compression.Algorithms) == 0 {
+		return ingressv1alpha1.EndpointRule{
+			Name:    "Compression",
+			Actions: []ingressv1alpha1.EndpointAction{{Type: "compress-response"}},
+		}, nil
+	}
+	return policyRule("Compression", nil, "compress-response", compressResponseActionConfig{Algorithms: compression.Algorithms})
+}
+
+// withPolicyRules runs the translated rules before the rules of the route's traffic policy
+func withPolicyRules(routeSpec *ingressv1alpha1.HTTPSEdgeRouteSpec, inbound, outbound []ingressv1alpha1.EndpointRule) (*ingressv1alpha1.HTTPSEdgeRouteSpec, error) {
+	if len(inbound) == 0 && len(outbound) == 0 {
+		return routeSpec, nil
+	}
+
+	policy := ingressv1alpha1.EndpointPolicy{}
+	if len(routeSpec.Policy) > 0 {
+		if err := json.Unmarshal(routeSpec.Policy, &policy); err != nil {
+			return nil, err
+		}
+	}
+	policy.Inbound = append(inbound, policy.Inbound...)
+	policy.Outbound = append(outbound, policy.Outbound...)
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	routeSpec.Policy = policyJSON
+	return routeSpec, nil
+}
+
+// oauthPolicyRules translates the OAuth module into an oauth action, followed by a rule denying the identities
+// the module doesn't allow. Returns false if the module can't be translated.
+func oauthPolicyRules(module *ngrok.EndpointOAuth) ([]ingressv1alpha1.EndpointRule, bool, error) {
+	// the provider is the only one of the module's providers that is set
+	providers := map[string]json.RawMessage{}
+	b, err := json.Marshal(module.Provider)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(b, &providers); err != nil {
+		return nil, false, err
+	}
+	if len(providers) != 1 {
+		return nil, false, fmt.Errorf("expected exactly one OAuth provider, found %d", len(providers))
+	}
+
+	var provider string
+	config := oauthProviderConfig{}
+	for name, raw := range providers {
+		provider = name
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if len(config.Teams) > 0 || len(config.Organizations) > 0 {
+		return nil, false, nil
+	}
+
+	rule, err := policyRule("OAuth", nil, "oauth", oauthActionConfig{
+		Provider:                provider,
+		ClientID:                config.ClientID,
+		ClientSecret:            config.ClientSecret,
+		Scopes:                  config.Scopes,
+		AllowCORSPreflight:      module.OptionsPassthrough,
+		AuthID:                  module.CookiePrefix,
+		IdleSessionTimeout:      policyDuration(module.InactivityTimeout),
+		MaxSessionDuration:      policyDuration(module.MaximumDuration),
+		UserinfoRefreshInterval: policyDuration(module.AuthCheckInterval),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	rules := []ingressv1alpha1.EndpointRule{rule}
+
+	if len(config.EmailAddresses) == 0 && len(config.EmailDomains) == 0 {
+		return rules, true, nil
+	}
+
+	allowed := []string{}
+	for _, address := range config.EmailAddresses {
+		allowed = append(allowed, fmt.Sprintf("actions.ngrok.oauth.identity.email == %q", address))
+	}
+	for _, domain := range config.EmailDomains {
+		allowed = append(allowed, fmt.Sprintf("actions.ngrok.oauth.identity.email.endsWith(%q)", "@"+domain))
+	}
+	deny, err := policyRule("OAuth Identity Restriction", []string{fmt.Sprintf("!(%s)", strings.Join(allowed, " || "))}, "deny", denyActionConfig{StatusCode: 403})
+	if err != nil {
+		return nil, false, err
+	}
+	return append(rules, deny), true, nil
+}
+
+// policyHeaderVariables are the traffic policy variables with the values of the header module variables
+var policyHeaderVariables = map[string]string{
+	".conn.client_ip":         "conn.client_ip",
+	".conn.client_port":       "conn.client_port",
+	".conn.server_ip":         "conn.server_ip",
+	".conn.server_port":       "conn.server_port",
+	".ngrok.geo.city":         "conn.geo.city",
+	".ngrok.geo.country":      "conn.geo.country",
+	".ngrok.geo.country_code": "conn.geo.country_code",
+	".ngrok.geo.latitude":     "conn.geo.latitude",
+	".ngrok.geo.longitude":    "conn.geo.longitude",
+	".ngrok.geo.postal_code":  "conn.geo.postal_code",
+	".ngrok.geo.subdivision":  "conn.geo.subdivision",
+}
+
+// headersTranslatable returns true if every variable interpolated into the added headers has a traffic policy
+// equivalent
+func headersTranslatable(headers *ingressv1alpha1.EndpointHeaders) bool {
+	var values []string
+	if headers.Request != nil {
+		values = append(values, maps.Values(headers.Request.Add)...)
+	}
+	if headers.Response != nil {
+		values = append(values, maps.Values(headers.Response.Add)...)
+	}
+	for _, value := range values {
+		variables, err := ingressv1alpha1.HeaderValueVariables(value)
+		if err != nil {
+			return false
+		}
+		for _, variable := range variables {
+			if _, ok := policyHeaderVariables[variable]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// policyHeaderValue rewrites the header module variables interpolated into the value as their traffic policy
+// equivalents. See headersTranslatable.
+func policyHeaderValue(value string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			break
+		}
+		variable := strings.TrimSpace(value[start+2 : start+end])
+		b.WriteString(value[:start])
+		b.WriteString("${" + policyHeaderVariables[variable] + "}")
+		value = value[start+end+1:]
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+// headersPolicyRule translates a headers module into a rule removing and then adding headers. Returns false if
+// the module doesn't change any headers.
+func headersPolicyRule(name string, add map[string]string, remove []string) (ingressv1alpha1.EndpointRule, bool, error) {
+	rule := ingressv1alpha1.EndpointRule{Name: name}
+	if len(remove) > 0 {
+		config, err := json.Marshal(store.RemoveHeadersConfig{Headers: remove})
+		if err != nil {
+			return rule, false, err
+		}
+		rule.Actions = append(rule.Actions, ingressv1alpha1.EndpointAction{Type: "remove-headers", Config: config})
+	}
+	if len(add) > 0 {
+		policyAdd := make(map[string]string, len(add))
+		for name, value := range add {
+			policyAdd[name] = policyHeaderValue(value)
+		}
+		config, err := json.Marshal(store.AddHeadersConfig{Headers: policyAdd})
+		if err != nil {
+			return rule, false, err
+		}
+		rule.Actions = append(rule.Actions, ingressv1alpha1.EndpointAction{Type: "add-headers", Config: config})
+	}
+	return rule, len(rule.Actions) > 0, nil
+}
+
+// policyRule builds a rule with a single action
+func policyRule(name string, expressions []string, actionType string, actionConfig any) (ingressv1alpha1.EndpointRule, error) {
+	config, err := json.Marshal(actionConfig)
+	if err != nil {
+		return ingressv1alpha1.EndpointRule{}, err
+	}
+	return ingressv1alpha1.EndpointRule{
+		Name:        name,
+		Expressions: expressions,
+		Actions:     []ingressv1alpha1.EndpointAction{{Type: actionType, Config: config}},
+	}, nil
+}
+
+// policyDuration formats a module's duration in seconds as a traffic policy duration, leaving it unset if zero
+func policyDuration(seconds uint32) string {
+	if seconds == 0 {
+		return ""
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/ngrok-api-go/v5"
+)
+
+var _ = Describe("translateRouteModules", func() {
+	// the route an ingress using a module set with compression, IP restriction, headers and OAuth is translated into
+	newRoute := func() *ingressv1alpha1.HTTPSEdgeRouteSpec {
+		return &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Match:       "/",
+			MatchType:   "path_prefix",
+			Compression: &ingressv1alpha1.EndpointCompression{Enabled: true},
+			IPRestriction: &ingressv1alpha1.EndpointIPPolicy{
+				IPPolicies: []string{"office"},
+			},
+			Headers: &ingressv1alpha1.EndpointHeaders{
+				Request: &ingressv1alpha1.EndpointRequestHeaders{
+					Add:    map[string]string{"X-Send-To-Backend": "true"},
+					Remove: []string{"X-Internal"},
+				},
+				Response: &ingressv1alpha1.EndpointResponseHeaders{
+					Add: map[string]string{"X-Served-By": "ngrok"},
+				},
+			},
+			OAuth: &ingressv1alpha1.EndpointOAuth{
+				Google: &ingressv1alpha1.EndpointOAuthGoogle{},
+			},
+			Policy: json.RawMessage(`{"inbound":[{"name":"existing","actions":[{"type":"deny"}]}]}`),
+		}
+	}
+
+	resolved := func() resolvedRouteModules {
+		return resolvedRouteModules{
+			ipPolicyIDs: []string{"ipp_123"},
+			oauth: &ngrok.EndpointOAuth{
+				CookiePrefix:    "ngrok.",
+				MaximumDuration: 3600,
+				Provider: ngrok.EndpointOAuthProvider{
+					Google: &ngrok.EndpointOAuthGoogle{
+						ClientID:       ptr.To("client-id"),
+						ClientSecret:   ptr.To("client-secret"),
+						Scopes:         []string{"email"},
+						EmailAddresses: []string{"alice@example.com"},
+						EmailDomains:   []string{"ngrok.com"},
+					},
+				},
+			},
+		}
+	}
+
+	It("Should translate the modules of a route into its traffic policy", func() {
+		translated, err := translateRouteModules(newRoute(), resolved())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(translated.Compression).To(BeNil())
+		Expect(translated.IPRestriction).To(BeNil())
+		Expect(translated.Headers).To(BeNil())
+		Expect(translated.OAuth).To(BeNil())
+		Expect(translated.Match).To(Equal("/"))
+
+		Expect(string(translated.Policy)).To(MatchJSON(`{
+			"inbound": [
+				{
+					"name": "IP Restriction",
+					"actions": [{"type": "restrict-ips", "config": {"enforce": true, "ip_policies": ["ipp_123"]}}]
+				},
+				{
+					"name": "OAuth",
+					"actions": [{"type": "oauth", "config": {
+						"provider": "google",
+						"client_id": "client-id",
+						"client_secret": "client-secret",
+						"scopes": ["email"],
+						"auth_id": "ngrok.",
+						"max_session_duration": "1h0m0s"
+					}}]
+				},
+				{
+					"name": "OAuth Identity Restriction",
+					"expressions": ["!(actions.ngrok.oauth.identity.email == \"alice@example.com\" || actions.ngrok.oauth.identity.email.endsWith(\"@ngrok.com\"))"],
+					"actions": [{"type": "deny", "config": {"status_code": 403}}]
+				},
+				{
+					"name": "Request Headers",
+					"actions": [
+						{"type": "remove-headers", "config": {"headers": ["X-Internal"]}},
+						{"type": "add-headers", "config": {"headers": {"X-Send-To-Backend": "true"}}}
+					]
+				},
+				{
+					"name": "existing",
+					"actions": [{"type": "deny"}]
+				}
+			],
+			"outbound": [
+				{
+					"name": "Response Headers",
+					"actions": [{"type": "add-headers", "config": {"headers": {"X-Served-By": "ngrok"}}}]
+				},
+				{
+					"name": "Compression",
+					"actions": [{"type": "compress-response"}]
+				}
+			]
+		}`))
+	})
+
+	It("Should configure the compress-response action with the compression algorithms", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Compression: &ingressv1alpha1.EndpointCompression{Enabled: true, Algorithms: []string{"br", "gzip"}},
+		}
+
+		translated, err := translateRouteModules(route, resolvedRouteModules{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.Compression).To(BeNil())
+		Expect(string(translated.Policy)).To(MatchJSON(`{
+			"outbound": [
+				{
+					"name": "Compression",
+					"actions": [{"type": "compress-response", "config": {"algorithms": ["br", "gzip"]}}]
+				}
+			]
+		}`))
+	})
+
+	It("Should translate OIDC into an openid-connect action", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			OIDC: &ingressv1alpha1.EndpointOIDC{
+				OptionsPassthrough: true,
+				Issuer:             "https://accounts.example.com",
+				ClientID:           "client-id",
+				Scopes:             []string{"openid"},
+			},
+		}
+
+		translated, err := translateRouteModules(route, resolvedRouteModules{oidcClientSecret: "client-secret"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.OIDC).To(BeNil())
+		Expect(string(translated.Policy)).To(MatchJSON(`{
+			"inbound": [
+				{
+					"name": "OIDC",
+					"actions": [{"type": "openid-connect", "config": {
+						"issuer_url": "https://accounts.example.com",
+						"client_id": "client-id",
+						"client_secret": "client-secret",
+						"scopes": ["openid"],
+						"allow_cors_preflight": true
+					}}]
+				}
+			]
+		}`))
+	})
+
+	It("Should leave OAuth restricted to GitHub teams as a module", func() {
+		route := newRoute()
+		route.OAuth = &ingressv1alpha1.EndpointOAuth{Github: &ingressv1alpha1.EndpointOAuthGitHub{Teams: []string{"org/team"}}}
+		r := resolved()
+		r.oauth = &ngrok.EndpointOAuth{
+			Provider: ngrok.EndpointOAuthProvider{Github: &ngrok.EndpointOAuthGitHub{Teams: []string{"org/team"}}},
+		}
+
+		translated, err := translateRouteModules(route, r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.OAuth).To(Equal(route.OAuth))
+		Expect(string(translated.Policy)).ToNot(ContainSubstring(`"oauth"`))
+	})
+
+	It("Should rewrite interpolated header variables as traffic policy variables", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Headers: &ingressv1alpha1.EndpointHeaders{
+				Request: &ingressv1alpha1.EndpointRequestHeaders{
+					Add: map[string]string{"X-Country": "${.ngrok.geo.country_code}", "X-Client": "${ .conn.client_ip }:${.conn.client_port}"},
+				},
+			},
+		}
+
+		translated, err := translateRouteModules(route, resolvedRouteModules{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.Headers).To(BeNil())
+		Expect(string(translated.Policy)).To(MatchJSON(`{
+			"inbound": [
+				{
+					"name": "Request Headers",
+					"actions": [{"type": "add-headers", "config": {"headers": {
+						"X-Country": "${conn.geo.country_code}",
+						"X-Client": "${conn.client_ip}:${conn.client_port}"
+					}}}]
+				}
+			]
+		}`))
+	})
+
+	It("Should leave headers using variables without a traffic policy equivalent as a module", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Headers: &ingressv1alpha1.EndpointHeaders{
+				Request: &ingressv1alpha1.EndpointRequestHeaders{
+					Add: map[string]string{"X-Country": "${.ngrok.geo.country_code}", "X-User": "${.oauth.user.email}"},
+				},
+			},
+		}
+
+		translated, err := translateRouteModules(route, resolvedRouteModules{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.Headers).To(Equal(route.Headers))
+		Expect(translated.Policy).To(BeEmpty())
+	})
+
+	It("Should leave the route alone when it has nothing to translate", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Match:       "/",
+			Compression: &ingressv1alpha1.EndpointCompression{Enabled: false},
+			Policy:      json.RawMessage(`{"inbound":[]}`),
+			SAML:        &ingressv1alpha1.EndpointSAML{IdPMetadata: "<xml/>"},
+		}
+
+		translated, err := translateRouteModules(route, resolvedRouteModules{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.Compression).To(BeNil())
+		Expect(translated.SAML).To(Equal(route.SAML))
+		Expect(string(translated.Policy)).To(Equal(`{"inbound":[]}`))
+	})
+})
+
+var _ = Describe("translateCompressionToPolicy", func() {
+	It("Should translate compression choosing algorithms, before the route's own policy", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Compression: &ingressv1alpha1.EndpointCompression{Enabled: true, Algorithms: []string{"br"}},
+			Headers:     &ingressv1alpha1.EndpointHeaders{Response: &ingressv1alpha1.EndpointResponseHeaders{Remove: []string{"Server"}}},
+			Policy:      json.RawMessage(`{"outbound":[{"name":"mine","actions":[{"type":"deny"}]}]}`),
+		}
+
+		translated, err := translateCompressionToPolicy(route)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated.Compression).To(BeNil())
+		Expect(translated.Headers).To(Equal(route.Headers))
+		Expect(route.Compression).ToNot(BeNil(), "the route passed in is left alone")
+		Expect(string(translated.Policy)).To(MatchJSON(`{
+			"outbound": [
+				{
+					"name": "Compression",
+					"actions": [{"type": "compress-response", "config": {"algorithms": ["br"]}}]
+				},
+				{"name": "mine", "actions": [{"type": "deny"}]}
+			]
+		}`))
+	})
+
+	It("Should leave compression without algorithms to the edge module", func() {
+		route := &ingressv1alpha1.HTTPSEdgeRouteSpec{
+			Compression: &ingressv1alpha1.EndpointCompression{Enabled: true},
+		}
+
+		translated, err := translateCompressionToPolicy(route)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(translated).To(Equal(route))
+	})
+})
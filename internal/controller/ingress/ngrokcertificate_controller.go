@@ -0,0 +1,388 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
+	"github.com/ngrok/ngrok-api-go/v5/tls_certificates"
+)
+
+// managedCertificatePolicy is the certificate management policy a domain is returned to when the certificate
+// bound to it is deleted, so it keeps serving TLS with a certificate issued by ngrok
+var managedCertificatePolicy = ngrok.ReservedDomainCertPolicy{Authority: "letsencrypt", PrivateKeyType: "ecdsa"}
+
+// NgrokCertificateReconciler reconciles a NgrokCertificate object
+type NgrokCertificateReconciler struct {
+	client.Client
+
+	Log                logr.Logger
+	Scheme             *runtime.Scheme
+	Recorder           record.EventRecorder
+	CertificatesClient *tls_certificates.Client
+	DomainsClient      *reserved_domains.Client
+	// RetryPolicy retries the TLS certificate API calls and the domain updates that attach certificates
+	RetryPolicy ngrokapi.RetryPolicy
+
+	controller *baseController[*ngrokv1alpha1.NgrokCertificate]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NgrokCertificateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.CertificatesClient == nil {
+		return fmt.Errorf("CertificatesClient must be set")
+	}
+	if r.DomainsClient == nil {
+		return fmt.Errorf("DomainsClient must be set")
+	}
+
+	r.controller = &baseController[*ngrokv1alpha1.NgrokCertificate]{
+		Kube:     r.Client,
+		Log:      r.Log,
+		Recorder: r.Recorder,
+
+		kubeType:  "v1alpha1.NgrokCertificate",
+		statusID:  func(cr *ngrokv1alpha1.NgrokCertificate) string { return cr.Status.ID },
+		create:    r.create,
+		update:    r.update,
+		delete:    r.delete,
+		errResult: r.errResult,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ngrokv1alpha1.NgrokCertificate{}).
+		Watches(
+			&v1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.listCertificatesForSecret),
+		).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngrokcertificates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngrokcertificates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngrokcertificates/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.1/pkg/reconcile
+func (r *NgrokCertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.controller.reconcile(ctx, req, new(ngrokv1alpha1.NgrokCertificate))
+}
+
+func (r *NgrokCertificateReconciler) create(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) error {
+	resp, secretHash, err := r.upload(ctx, cert)
+	if err != nil {
+		return err
+	}
+
+	// Record the uploaded certificate before binding it, so it isn't uploaded again if binding fails
+	cert.SetStatus(resp)
+	cert.Status.SecretHash = secretHash
+	if err := r.Status().Update(ctx, cert); err != nil {
+		return err
+	}
+
+	return r.bindDomain(ctx, cert)
+}
+
+func (r *NgrokCertificateReconciler) update(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) error {
+	var resp *ngrok.TLSCertificate
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+		resp, err = r.CertificatesClient.Get(ctx, cert.Status.ID)
+		return err
+	})
+	if err != nil {
+		// The certificate was deleted outside of the controller, so clear the ID and requeue to upload it again
+		if ngrok.IsNotFound(err) {
+			ctrl.LoggerFrom(ctx).Info("TLS certificate not found, clearing ID and requeuing", "ID", cert.Status.ID)
+			cert.Status = ngrokv1alpha1.NgrokCertificateStatus{}
+			//nolint:errcheck
+			r.Status().Update(ctx, cert)
+		}
+		return err
+	}
+
+	certPEM, keyPEM, err := r.secretKeyPair(ctx, cert)
+	if err != nil {
+		return err
+	}
+
+	// Uploaded certificates can't be changed, so a rotated certificate is uploaded as a new one that replaces it
+	if hashKeyPair(certPEM, keyPEM) != cert.Status.SecretHash {
+		return r.rotate(ctx, cert, resp.ID)
+	}
+
+	if resp.Description != cert.Spec.Description || resp.Metadata != cert.Spec.Metadata {
+		req := &ngrok.TLSCertificateUpdate{
+			ID:          cert.Status.ID,
+			Description: &cert.Spec.Description,
+			Metadata:    &cert.Spec.Metadata,
+		}
+		err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+			resp, err = r.CertificatesClient.Update(ctx, req)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cert.Status.Domain != "" && cert.Status.Domain != cert.Spec.Domain {
+		if err := r.unbindDomain(ctx, cert.Status.Domain, cert.Status.ID); err != nil {
+			return err
+		}
+	}
+	if err := r.bindDomain(ctx, cert); err != nil {
+		return err
+	}
+	return r.updateStatus(ctx, cert, resp)
+}
+
+func (r *NgrokCertificateReconciler) delete(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) error {
+	// ngrok won't delete a certificate a domain still uses
+	if err := r.unbindDomain(ctx, cert.Status.Domain, cert.Status.ID); err != nil {
+		return err
+	}
+
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		return r.CertificatesClient.Delete(ctx, cert.Status.ID)
+	})
+	if err == nil || ngrok.IsNotFound(err) {
+		cert.Status.ID = ""
+	}
+	return err
+}
+
+func (r *NgrokCertificateReconciler) errResult(op baseControllerOp, cr *ngrokv1alpha1.NgrokCertificate, err error) (reconcile.Result, error) {
+	// Retrying won't help until the Secret is fixed, which triggers another reconcile
+	if errors.Is(err, ngrokv1alpha1.ErrInvalidCertificateSecret) {
+		return ctrl.Result{}, nil
+	}
+	return reconcileResultFromError(err)
+}
+
+// rotate uploads the certificate from the Secret again, binds the new certificate to the domain in place of the
+// old one, and then deletes the old one
+func (r *NgrokCertificateReconciler) rotate(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate, oldID string) error {
+	resp, secretHash, err := r.upload(ctx, cert)
+	if err != nil {
+		return err
+	}
+	ctrl.LoggerFrom(ctx).Info("Secret changed, replacing TLS certificate", "oldID", oldID, "ID", resp.ID)
+
+	cert.SetStatus(resp)
+	cert.Status.SecretHash = secretHash
+	if err := r.Status().Update(ctx, cert); err != nil {
+		return err
+	}
+	if err := r.bindDomain(ctx, cert); err != nil {
+		return err
+	}
+
+	err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		return r.CertificatesClient.Delete(ctx, oldID)
+	})
+	if err != nil && !ngrok.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// upload uploads the certificate and private key from the NgrokCertificate's Secret to ngrok, returning the
+// uploaded certificate and the hash of the key pair
+func (r *NgrokCertificateReconciler) upload(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) (*ngrok.TLSCertificate, string, error) {
+	certPEM, keyPEM, err := r.secretKeyPair(ctx, cert)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req := &ngrok.TLSCertificateCreate{
+		Description:    cert.Spec.Description,
+		Metadata:       cert.Spec.Metadata,
+		CertificatePEM: string(certPEM),
+		PrivateKeyPEM:  string(keyPEM),
+	}
+	var resp *ngrok.TLSCertificate
+	err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+		resp, err = r.CertificatesClient.Create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	ctrl.LoggerFrom(ctx).Info("Uploaded TLS certificate", "ID", resp.ID, "subject", resp.SubjectCommonName)
+	return resp, hashKeyPair(certPEM, keyPEM), nil
+}
+
+// secretKeyPair returns the PEM encoded certificate and private key from the NgrokCertificate's Secret. It
+// returns an error wrapping ErrInvalidCertificateSecret if either one is missing.
+func (r *NgrokCertificateReconciler) secretKeyPair(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) ([]byte, []byte, error) {
+	secret := &v1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: cert.Namespace, Name: cert.Spec.SecretName}, secret); err != nil {
+		return nil, nil, fmt.Errorf("getting Secret %s/%s: %w", cert.Namespace, cert.Spec.SecretName, err)
+	}
+
+	for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, nil, fmt.Errorf("%w: Secret %s/%s has no %s key", ngrokv1alpha1.ErrInvalidCertificateSecret, cert.Namespace, cert.Spec.SecretName, key)
+		}
+	}
+	return secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey], nil
+}
+
+// bindDomain makes the NgrokCertificate's reserved domain serve its uploaded certificate. It returns an error if
+// the domain isn't reserved yet, so binding is retried once it is.
+func (r *NgrokCertificateReconciler) bindDomain(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate) error {
+	domain, err := r.findReservedDomain(ctx, cert.Spec.Domain)
+	if err != nil {
+		return err
+	}
+	if domain == nil {
+		return fmt.Errorf("domain %s is not reserved", cert.Spec.Domain)
+	}
+
+	if domain.Certificate == nil || domain.Certificate.ID != cert.Status.ID {
+		req := &ngrok.ReservedDomainUpdate{
+			ID:            domain.ID,
+			CertificateID: &cert.Status.ID,
+		}
+		err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+			_, err := r.DomainsClient.Update(ctx, req)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		ctrl.LoggerFrom(ctx).Info("Bound TLS certificate to domain", "ID", cert.Status.ID, "domain", domain.Domain)
+		r.Recorder.Event(cert, v1.EventTypeNormal, events.ReasonUpdated, fmt.Sprintf("Bound certificate %s to domain %s", cert.Status.ID, domain.Domain))
+	}
+
+	if cert.Status.Domain == cert.Spec.Domain {
+		return nil
+	}
+	cert.Status.Domain = cert.Spec.Domain
+	return r.Status().Update(ctx, cert)
+}
+
+// unbindDomain returns the reserved domain to a certificate managed by ngrok if it's still serving the certificate
+// with the ID
+func (r *NgrokCertificateReconciler) unbindDomain(ctx context.Context, domainName string, certID string) error {
+	if domainName == "" {
+		return nil
+	}
+	domain, err := r.findReservedDomain(ctx, domainName)
+	if err != nil || domain == nil || domain.Certificate == nil || domain.Certificate.ID != certID {
+		return err
+	}
+
+	policy := managedCertificatePolicy
+	req := &ngrok.ReservedDomainUpdate{
+		ID:                          domain.ID,
+		CertificateManagementPolicy: &policy,
+	}
+	return ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		_, err := r.DomainsClient.Update(ctx, req)
+		return err
+	})
+}
+
+// findReservedDomain finds the reserved domain by its hostname. If it doesn't exist, returns nil
+func (r *NgrokCertificateReconciler) findReservedDomain(ctx context.Context, domainName string) (*ngrok.ReservedDomain, error) {
+	var found *ngrok.ReservedDomain
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		iter := r.DomainsClient.List(&ngrok.Paging{})
+		for iter.Next(ctx) {
+			if domain := iter.Item(); domain.Domain == domainName {
+				found = domain
+				return nil
+			}
+		}
+		return iter.Err()
+	})
+	return found, err
+}
+
+// updateStatus updates the status of the NgrokCertificate only if any values have changed
+func (r *NgrokCertificateReconciler) updateStatus(ctx context.Context, cert *ngrokv1alpha1.NgrokCertificate, ngrokCert *ngrok.TLSCertificate) error {
+	if cert.Status.ID == ngrokCert.ID &&
+		cert.Status.URI == ngrokCert.URI &&
+		cert.Status.SubjectCommonName == ngrokCert.SubjectCommonName &&
+		cert.Status.NotAfter == ngrokCert.NotAfter {
+		return nil
+	}
+	cert.SetStatus(ngrokCert)
+	return r.Status().Update(ctx, cert)
+}
+
+// hashKeyPair returns the hex encoded SHA-256 hash of the PEM encoded certificate and private key
+func hashKeyPair(certPEM, keyPEM []byte) string {
+	h := sha256.New()
+	h.Write(certPEM)
+	h.Write(keyPEM)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *NgrokCertificateReconciler) listCertificatesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	certs := &ngrokv1alpha1.NgrokCertificateList{}
+	if err := r.Client.List(ctx, certs, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list NgrokCertificates for secret", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, cert := range certs.Items {
+		if cert.Spec.SecretName == obj.GetName() {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      cert.GetName(),
+					Namespace: cert.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
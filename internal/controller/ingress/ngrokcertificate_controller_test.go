@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
+	"github.com/ngrok/ngrok-api-go/v5/tls_certificates"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+)
+
+var _ = Describe("NgrokCertificateReconciler", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+	var (
+		ctx           context.Context
+		req           ctrl.Request
+		uploaded      []ngrok.TLSCertificateCreate
+		domainUpdates []ngrok.ReservedDomainUpdate
+		deleted       []string
+		boundCertID   string
+		ngrokAPI      *httptest.Server
+		reconcile     func(c client.Client) error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "wildcard", Namespace: "test-namespace"}}
+		uploaded = nil
+		domainUpdates = nil
+		deleted = nil
+		boundCertID = ""
+
+		ngrokAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/tls_certificates":
+				req := ngrok.TLSCertificateCreate{}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				uploaded = append(uploaded, req)
+				resp := ngrok.TLSCertificate{ID: "cert_new", SubjectCommonName: "*.example.com", NotAfter: "2027-01-01T00:00:00Z"}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/tls_certificates/"):
+				resp := ngrok.TLSCertificate{ID: strings.TrimPrefix(r.URL.Path, "/tls_certificates/"), SubjectCommonName: "*.example.com"}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case r.Method == http.MethodDelete:
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			case r.Method == http.MethodGet && r.URL.Path == "/reserved_domains":
+				domain := ngrok.ReservedDomain{ID: "rd_123", Domain: "*.example.com"}
+				if boundCertID != "" {
+					domain.Certificate = &ngrok.Ref{ID: boundCertID}
+				}
+				Expect(json.NewEncoder(w).Encode(ngrok.ReservedDomainList{ReservedDomains: []ngrok.ReservedDomain{domain}})).To(Succeed())
+			case r.Method == http.MethodPatch && r.URL.Path == "/reserved_domains/rd_123":
+				req := ngrok.ReservedDomainUpdate{}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				domainUpdates = append(domainUpdates, req)
+				Expect(json.NewEncoder(w).Encode(ngrok.ReservedDomain{ID: "rd_123", Domain: "*.example.com"})).To(Succeed())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(ngrokAPI.Close)
+
+		reconcile = func(c client.Client) error {
+			config := ngrok.NewClientConfig("test-api-key", ngrok.WithBaseURL(ngrokAPI.URL))
+			r := &NgrokCertificateReconciler{
+				Client:             c,
+				Log:                logr.Discard(),
+				Scheme:             scheme,
+				Recorder:           record.NewFakeRecorder(10),
+				CertificatesClient: tls_certificates.NewClient(config),
+				DomainsClient:      reserved_domains.NewClient(config),
+				RetryPolicy:        ngrokapi.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			}
+			r.controller = &baseController[*ngrokv1alpha1.NgrokCertificate]{
+				Kube:     c,
+				Log:      r.Log,
+				Recorder: r.Recorder,
+
+				kubeType:  "v1alpha1.NgrokCertificate",
+				statusID:  func(cr *ngrokv1alpha1.NgrokCertificate) string { return cr.Status.ID },
+				create:    r.create,
+				update:    r.update,
+				delete:    r.delete,
+				errResult: r.errResult,
+			}
+			_, err := r.Reconcile(ctx, req)
+			return err
+		}
+	})
+
+	newSecret := func(data map[string][]byte) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: req.Namespace},
+			Type:       v1.SecretTypeTLS,
+			Data:       data,
+		}
+	}
+
+	keyPair := map[string][]byte{v1.TLSCertKey: []byte("cert"), v1.TLSPrivateKeyKey: []byte("key")}
+
+	newCert := func() *ngrokv1alpha1.NgrokCertificate {
+		cert := &ngrokv1alpha1.NgrokCertificate{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			Spec:       ngrokv1alpha1.NgrokCertificateSpec{SecretName: "wildcard-tls", Domain: "*.example.com"},
+		}
+		controllers.AddFinalizer(cert)
+		return cert
+	}
+
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objs...).
+			WithStatusSubresource(&ngrokv1alpha1.NgrokCertificate{}).
+			Build()
+	}
+
+	It("Should upload the certificate from the Secret and bind it to the domain", func() {
+		c := build(newCert(), newSecret(keyPair))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(uploaded).To(HaveLen(1))
+		Expect(uploaded[0].CertificatePEM).To(Equal("cert"))
+		Expect(uploaded[0].PrivateKeyPEM).To(Equal("key"))
+		Expect(domainUpdates).To(HaveLen(1))
+		Expect(*domainUpdates[0].CertificateID).To(Equal("cert_new"))
+
+		cert := &ngrokv1alpha1.NgrokCertificate{}
+		Expect(c.Get(ctx, req.NamespacedName, cert)).To(Succeed())
+		Expect(cert.Status.ID).To(Equal("cert_new"))
+		Expect(cert.Status.Domain).To(Equal("*.example.com"))
+		Expect(cert.Status.SubjectCommonName).To(Equal("*.example.com"))
+		Expect(cert.Status.SecretHash).To(Equal(hashKeyPair([]byte("cert"), []byte("key"))))
+	})
+
+	It("Should not upload anything when the Secret has no private key", func() {
+		c := build(newCert(), newSecret(map[string][]byte{v1.TLSCertKey: []byte("cert")}))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(uploaded).To(BeEmpty())
+		Expect(domainUpdates).To(BeEmpty())
+	})
+
+	It("Should replace the certificate when the Secret changes", func() {
+		cert := newCert()
+		cert.Status = ngrokv1alpha1.NgrokCertificateStatus{ID: "cert_old", Domain: "*.example.com", SecretHash: "outdated"}
+		boundCertID = "cert_old"
+		c := build(cert, newSecret(keyPair))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(uploaded).To(HaveLen(1))
+		Expect(domainUpdates).To(HaveLen(1))
+		Expect(*domainUpdates[0].CertificateID).To(Equal("cert_new"))
+		Expect(deleted).To(Equal([]string{"/tls_certificates/cert_old"}))
+
+		Expect(c.Get(ctx, req.NamespacedName, cert)).To(Succeed())
+		Expect(cert.Status.ID).To(Equal("cert_new"))
+	})
+
+	It("Should leave the certificate alone when the Secret hasn't changed", func() {
+		cert := newCert()
+		cert.Status = ngrokv1alpha1.NgrokCertificateStatus{ID: "cert_old", Domain: "*.example.com", SecretHash: hashKeyPair([]byte("cert"), []byte("key"))}
+		boundCertID = "cert_old"
+		c := build(cert, newSecret(keyPair))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(uploaded).To(BeEmpty())
+		Expect(domainUpdates).To(BeEmpty())
+		Expect(deleted).To(BeEmpty())
+	})
+
+	It("Should return the domain to a managed certificate before deleting the certificate", func() {
+		cert := newCert()
+		cert.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		cert.Status = ngrokv1alpha1.NgrokCertificateStatus{ID: "cert_old", Domain: "*.example.com"}
+		boundCertID = "cert_old"
+		c := build(cert, newSecret(keyPair))
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(domainUpdates).To(HaveLen(1))
+		Expect(domainUpdates[0].CertificateManagementPolicy).To(Equal(&managedCertificatePolicy))
+		Expect(deleted).To(Equal([]string{"/tls_certificates/cert_old"}))
+	})
+})
@@ -0,0 +1,211 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/go-logr/logr"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_addrs"
+)
+
+// ReservedAddrReconciler reconciles a ReservedAddr object
+type ReservedAddrReconciler struct {
+	client.Client
+
+	Log         logr.Logger
+	Scheme      *runtime.Scheme
+	Recorder    record.EventRecorder
+	AddrsClient *reserved_addrs.Client
+	// RetryPolicy retries the reserved address API calls
+	RetryPolicy ngrokapi.RetryPolicy
+
+	controller *baseController[*ingressv1alpha1.ReservedAddr]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReservedAddrReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.AddrsClient == nil {
+		return fmt.Errorf("AddrsClient must be set")
+	}
+
+	r.controller = &baseController[*ingressv1alpha1.ReservedAddr]{
+		Kube:     r.Client,
+		Log:      r.Log,
+		Recorder: r.Recorder,
+
+		kubeType: "v1alpha1.ReservedAddr",
+		statusID: func(cr *ingressv1alpha1.ReservedAddr) string { return cr.Status.ID },
+		create:   r.create,
+		update:   r.update,
+		delete:   r.delete,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ingressv1alpha1.ReservedAddr{}).
+		WithEventFilter(commonPredicateFilters).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=reservedaddrs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=reservedaddrs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=reservedaddrs/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.1/pkg/reconcile
+func (r *ReservedAddrReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.controller.reconcile(ctx, req, new(ingressv1alpha1.ReservedAddr))
+}
+
+func (r *ReservedAddrReconciler) create(ctx context.Context, addr *ingressv1alpha1.ReservedAddr) error {
+	if err := ingressv1alpha1.ValidateRegion(addr.Spec.Region); err != nil {
+		return err
+	}
+
+	// A create call that failed on ngrok's side or on the network may have reserved the address anyway, so look
+	// for an address tagged with this ReservedAddr before reserving another one
+	resp, err := r.findOwnedAddr(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		ctrl.LoggerFrom(ctx).Info("Found reserved TCP address", "ID", resp.ID, "addr", resp.Addr)
+		return r.updateStatus(ctx, addr, resp)
+	}
+
+	req := &ngrok.ReservedAddrCreate{
+		Description: addr.Spec.Description,
+		Metadata:    addr.NgrokMetadata(),
+		Region:      addr.Spec.Region,
+	}
+	// Only rate limited calls are retried here. Any other failure is retried by the next reconcile, after it has
+	// looked for the address again.
+	err = ngrokapi.DoWithRetryIf(ctx, r.RetryPolicy, ngrokapi.IsRateLimited, func() (err error) {
+		resp, err = r.AddrsClient.Create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	ctrl.LoggerFrom(ctx).Info("Reserved TCP address", "ID", resp.ID, "addr", resp.Addr)
+
+	return r.updateStatus(ctx, addr, resp)
+}
+
+func (r *ReservedAddrReconciler) update(ctx context.Context, addr *ingressv1alpha1.ReservedAddr) error {
+	var resp *ngrok.ReservedAddr
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+		resp, err = r.AddrsClient.Get(ctx, addr.Status.ID)
+		return err
+	})
+	if err != nil {
+		// The address was released outside of the controller, so clear the ID and requeue to reserve a new one
+		if ngrok.IsNotFound(err) {
+			ctrl.LoggerFrom(ctx).Info("Reserved addr not found, clearing ID and requeuing", "ID", addr.Status.ID)
+			addr.Status = ingressv1alpha1.ReservedAddrStatus{}
+			//nolint:errcheck
+			r.Status().Update(ctx, addr)
+		}
+		return err
+	}
+
+	if addr.Equal(resp) {
+		return nil
+	}
+
+	metadata := addr.NgrokMetadata()
+	if resp.Description != addr.Spec.Description || resp.Metadata != metadata {
+		req := &ngrok.ReservedAddrUpdate{
+			ID:          addr.Status.ID,
+			Description: &addr.Spec.Description,
+			Metadata:    &metadata,
+		}
+		err = ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() (err error) {
+			resp, err = r.AddrsClient.Update(ctx, req)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return r.updateStatus(ctx, addr, resp)
+}
+
+func (r *ReservedAddrReconciler) delete(ctx context.Context, addr *ingressv1alpha1.ReservedAddr) error {
+	if addr.Spec.DeletionProtection {
+		// Leave the reserved address in ngrok and only let the finalizer be removed
+		ctrl.LoggerFrom(ctx).Info("ReservedAddr has deletion protection enabled, not releasing the address", "ID", addr.Status.ID)
+		r.Recorder.Event(addr, v1.EventTypeNormal, events.ReasonDeletionProtected, fmt.Sprintf("Keeping reserved addr %s in ngrok because deletion protection is enabled", addr.Status.ID))
+		return nil
+	}
+
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		return r.AddrsClient.Delete(ctx, addr.Status.ID)
+	})
+	if err == nil || ngrok.IsNotFound(err) {
+		addr.Status.ID = ""
+	}
+	return err
+}
+
+// findOwnedAddr returns the ngrok reserved address tagged with the UID of the ReservedAddr, or nil if there isn't one
+func (r *ReservedAddrReconciler) findOwnedAddr(ctx context.Context, addr *ingressv1alpha1.ReservedAddr) (*ngrok.ReservedAddr, error) {
+	var found *ngrok.ReservedAddr
+	err := ngrokapi.DoWithRetry(ctx, r.RetryPolicy, func() error {
+		iter := r.AddrsClient.List(&ngrok.Paging{})
+		for iter.Next(ctx) {
+			if ngrokAddr := iter.Item(); addr.Owns(ngrokAddr) {
+				found = ngrokAddr
+				return nil
+			}
+		}
+		return iter.Err()
+	})
+	return found, err
+}
+
+// updateStatus updates the status of the ReservedAddr only if any values have changed
+func (r *ReservedAddrReconciler) updateStatus(ctx context.Context, addr *ingressv1alpha1.ReservedAddr, ngrokAddr *ngrok.ReservedAddr) error {
+	if addr.Equal(ngrokAddr) {
+		return nil
+	}
+	addr.SetStatus(ngrokAddr)
+	r.Recorder.Event(addr, v1.EventTypeNormal, events.ReasonUpdated, fmt.Sprintf("Updating ReservedAddr %s", addr.Name))
+	return r.Status().Update(ctx, addr)
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_addrs"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/controller/controllers"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/ngrokapi"
+)
+
+var _ = Describe("ReservedAddrReconciler", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+
+	var (
+		ctx       context.Context
+		req       ctrl.Request
+		created   []ngrok.ReservedAddrCreate
+		deleted   []string
+		listed    []ngrok.ReservedAddr
+		createErr int
+		ngrokAPI  *httptest.Server
+		reconcile func(c client.Client) error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "postgres", Namespace: "test-namespace"}}
+		created = nil
+		deleted = nil
+		listed = nil
+		createErr = 0
+
+		ngrokAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				if r.URL.Path == "/reserved_addrs" {
+					Expect(json.NewEncoder(w).Encode(ngrok.ReservedAddrList{ReservedAddrs: listed})).To(Succeed())
+					return
+				}
+				resp := ngrok.ReservedAddr{ID: "ra_123", Addr: "1.tcp.ngrok.io:12345", Region: "us"}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case http.MethodPost:
+				req := ngrok.ReservedAddrCreate{}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				created = append(created, req)
+				if createErr != 0 {
+					w.WriteHeader(createErr)
+					Expect(json.NewEncoder(w).Encode(ngrok.Error{StatusCode: int32(createErr), Msg: "create failed"})).To(Succeed())
+					return
+				}
+				resp := ngrok.ReservedAddr{ID: "ra_123", Addr: "1.tcp.ngrok.io:12345", Region: req.Region, Description: req.Description, Metadata: req.Metadata}
+				Expect(json.NewEncoder(w).Encode(resp)).To(Succeed())
+			case http.MethodDelete:
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		DeferCleanup(ngrokAPI.Close)
+
+		reconcile = func(c client.Client) error {
+			r := &ReservedAddrReconciler{
+				Client:      c,
+				Log:         logr.Discard(),
+				Scheme:      scheme,
+				Recorder:    record.NewFakeRecorder(10),
+				AddrsClient: reserved_addrs.NewClient(ngrok.NewClientConfig("test-api-key", ngrok.WithBaseURL(ngrokAPI.URL))),
+				RetryPolicy: ngrokapi.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			}
+			r.controller = &baseController[*ingressv1alpha1.ReservedAddr]{
+				Kube:     c,
+				Log:      r.Log,
+				Recorder: r.Recorder,
+
+				kubeType: "v1alpha1.ReservedAddr",
+				statusID: func(cr *ingressv1alpha1.ReservedAddr) string { return cr.Status.ID },
+				create:   r.create,
+				update:   r.update,
+				delete:   r.delete,
+			}
+			_, err := r.Reconcile(ctx, req)
+			return err
+		}
+	})
+
+	newAddr := func() *ingressv1alpha1.ReservedAddr {
+		addr := &ingressv1alpha1.ReservedAddr{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace, UID: "5f7b9a6e"},
+			Spec:       ingressv1alpha1.ReservedAddrSpec{Region: "eu"},
+		}
+		controllers.AddFinalizer(addr)
+		return addr
+	}
+
+	deletingAddr := func(deletionProtection bool) *ingressv1alpha1.ReservedAddr {
+		addr := newAddr()
+		addr.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		addr.Spec.DeletionProtection = deletionProtection
+		addr.Status.ID = "ra_123"
+		return addr
+	}
+
+	It("Should reserve an address and report it in the status", func() {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newAddr()).
+			WithStatusSubresource(&ingressv1alpha1.ReservedAddr{}).
+			Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(HaveLen(1))
+		Expect(created[0].Region).To(Equal("eu"))
+		Expect(created[0].Metadata).To(ContainSubstring("5f7b9a6e"))
+
+		got := &ingressv1alpha1.ReservedAddr{}
+		Expect(c.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.IsReserved()).To(BeTrue())
+		Expect(got.Status.ID).To(Equal("ra_123"))
+		Expect(got.Status.Addr).To(Equal("1.tcp.ngrok.io:12345"))
+	})
+
+	It("Should use the address tagged with the ReservedAddr instead of reserving another", func() {
+		addr := newAddr()
+		listed = []ngrok.ReservedAddr{
+			{ID: "ra_other", Addr: "2.tcp.ngrok.io:23456", Region: "eu", Metadata: "unrelated"},
+			{ID: "ra_123", Addr: "1.tcp.ngrok.io:12345", Region: "eu", Metadata: addr.NgrokMetadata()},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(addr).
+			WithStatusSubresource(&ingressv1alpha1.ReservedAddr{}).
+			Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(BeEmpty())
+
+		got := &ingressv1alpha1.ReservedAddr{}
+		Expect(c.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		Expect(got.Status.ID).To(Equal("ra_123"))
+	})
+
+	It("Should not retry a create that failed on ngrok's side", func() {
+		createErr = http.StatusInternalServerError
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newAddr()).
+			WithStatusSubresource(&ingressv1alpha1.ReservedAddr{}).
+			Build()
+
+		Expect(reconcile(c)).NotTo(Succeed())
+		Expect(created).To(HaveLen(1))
+	})
+
+	It("Should retry a rate limited create", func() {
+		createErr = http.StatusTooManyRequests
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newAddr()).
+			WithStatusSubresource(&ingressv1alpha1.ReservedAddr{}).
+			Build()
+
+		_ = reconcile(c)
+		Expect(created).To(HaveLen(3))
+	})
+
+	It("Should not reserve another address once one is reserved", func() {
+		addr := newAddr()
+		addr.Status = ingressv1alpha1.ReservedAddrStatus{ID: "ra_123", Addr: "1.tcp.ngrok.io:12345", Region: "us"}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(addr).
+			WithStatusSubresource(&ingressv1alpha1.ReservedAddr{}).
+			Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(created).To(BeEmpty())
+	})
+
+	It("Should release the address when deletion protection is disabled", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingAddr(false)).Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(deleted).To(Equal([]string{"/reserved_addrs/ra_123"}))
+
+		err := c.Get(ctx, req.NamespacedName, &ingressv1alpha1.ReservedAddr{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should keep the address and remove the finalizer when deletion protection is enabled", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deletingAddr(true)).Build()
+
+		Expect(reconcile(c)).To(Succeed())
+		Expect(deleted).To(BeEmpty())
+
+		err := c.Get(ctx, req.NamespacedName, &ingressv1alpha1.ReservedAddr{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
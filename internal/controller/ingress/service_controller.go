@@ -61,11 +61,12 @@ var (
 
 type ServiceReconciler struct {
 	client.Client
-	Log       logr.Logger
-	Scheme    *runtime.Scheme
-	Recorder  record.EventRecorder
-	Namespace string
-	Driver    *store.Driver
+	Log               logr.Logger
+	Scheme            *runtime.Scheme
+	Recorder          record.EventRecorder
+	Namespace         string
+	Driver            *store.Driver
+	DeprecationWarner *annotations.DeprecationWarner
 }
 
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -136,6 +137,10 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if controllers.IsUpsert(svc) {
+		r.DeprecationWarner.Warn(svc)
+	}
+
 	subResourceReconcilers := serviceSubresourceReconcilers{
 		newServiceTCPEdgeReconciler(),
 		newServiceTLSEdgeReconciler(),
@@ -272,6 +277,13 @@ func (r *ServiceReconciler) buildTunnelAndEdge(ctx context.Context, svc *corev1.
 	}
 
 	if domain == "" { // No domain annotation, use TCP edge
+		// The reserved-addr annotation names a ReservedAddr whose address the edge serves, so the address
+		// clients connect to survives the edge being recreated
+		reservedAddr, err := parser.GetStringAnnotation("reserved-addr", svc)
+		if err != nil && !errors.IsMissingAnnotations(err) {
+			return objects, err
+		}
+
 		edge := &ingressv1alpha1.TCPEdge{
 			ObjectMeta: metav1.ObjectMeta{
 				GenerateName: svc.Name + "-",
@@ -285,6 +297,7 @@ func (r *ServiceReconciler) buildTunnelAndEdge(ctx context.Context, svc *corev1.
 				Backend: ingressv1alpha1.TunnelGroupBackend{
 					Labels: backendLabels,
 				},
+				ReservedAddr: reservedAddr,
 			},
 		}
 		if moduleSets != nil {
@@ -608,7 +621,8 @@ func newServiceTunnelReconciler() serviceSubresourceReconciler {
 }
 
 // Given an ingress, it will resolve any ngrok modulesets defined on the ingress to the
-// CRDs and then will merge them in to a single moduleset
+// CRDs and then will merge them in to a single moduleset using the merge strategy of its
+// modules-merge-strategy annotation
 func getNgrokModuleSetForService(ctx context.Context, c client.Client, svc *corev1.Service) (*ingressv1alpha1.NgrokModuleSet, error) {
 	computedModSet := &ingressv1alpha1.NgrokModuleSet{}
 
@@ -620,16 +634,21 @@ func getNgrokModuleSetForService(ctx context.Context, c client.Client, svc *core
 		return computedModSet, err
 	}
 
+	sets := make([]*ingressv1alpha1.NgrokModuleSet, 0, len(modules))
 	for _, module := range modules {
 		// TODO: watch these and cache them so we don't have to make tons of requests
 		resolvedMod := &ingressv1alpha1.NgrokModuleSet{}
 		if err := c.Get(ctx, client.ObjectKey{Namespace: svc.Namespace, Name: module}, resolvedMod); err != nil {
 			return computedModSet, err
 		}
-		computedModSet.Merge(resolvedMod)
+		sets = append(sets, resolvedMod)
 	}
 
-	return computedModSet, nil
+	strategy, err := annotations.ExtractModuleSetMergeStrategyFromAnnotations(svc)
+	if err != nil {
+		return computedModSet, err
+	}
+	return ingressv1alpha1.MergeModuleSetsWithStrategy(strategy, sets...)
 }
 
 func getNgrokTrafficPolicyForService(ctx context.Context, c client.Client, svc *corev1.Service) (*ngrokv1alpha1.NgrokTrafficPolicy, error) {
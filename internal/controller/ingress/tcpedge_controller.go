@@ -86,12 +86,17 @@ func (r *TCPEdgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&ingressv1alpha1.IPPolicy{},
 			handler.EnqueueRequestsFromMapFunc(r.listTCPEdgesForIPPolicy),
 		).
+		Watches(
+			&ingressv1alpha1.ReservedAddr{},
+			handler.EnqueueRequestsFromMapFunc(r.listTCPEdgesForReservedAddr),
+		).
 		Complete(r)
 }
 
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=tcpedges,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=tcpedges/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=tcpedges/finalizers,verbs=update
+//+kubebuilder:rbac:groups=ingress.k8s.ngrok.com,resources=reservedaddrs,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -128,6 +133,7 @@ func (r *TCPEdgeReconciler) create(ctx context.Context, edge *ingressv1alpha1.TC
 	resp, err = r.NgrokClientset.TCPEdges().Create(ctx, &ngrok.TCPEdgeCreate{
 		Description: edge.Spec.Description,
 		Metadata:    edge.Spec.Metadata,
+		Hostports:   edge.Status.Hostports,
 		Backend: &ngrok.EndpointBackendMutate{
 			BackendID: edge.Status.Backend.ID,
 		},
@@ -307,6 +313,10 @@ func (r *TCPEdgeReconciler) updateEdgeStatus(ctx context.Context, edge *ingressv
 func (r *TCPEdgeReconciler) reserveAddrIfEmpty(ctx context.Context, edge *ingressv1alpha1.TCPEdge) error {
 	log := ctrl.LoggerFrom(ctx)
 
+	if edge.Spec.ReservedAddr != "" {
+		return r.useReservedAddr(ctx, edge)
+	}
+
 	if edge.Status.Hostports == nil || len(edge.Status.Hostports) == 0 {
 		metadata := ReservedAddrMetadata{
 			Namespace: edge.Namespace,
@@ -360,6 +370,26 @@ func (r *TCPEdgeReconciler) reserveAddrIfEmpty(ctx context.Context, edge *ingres
 	return nil
 }
 
+// useReservedAddr sets the hostports of the edge to the address of the ReservedAddr named in its spec. It
+// returns an error until the address is reserved, so the edge is requeued rather than served on another address.
+func (r *TCPEdgeReconciler) useReservedAddr(ctx context.Context, edge *ingressv1alpha1.TCPEdge) error {
+	addr := &ingressv1alpha1.ReservedAddr{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: edge.Namespace, Name: edge.Spec.ReservedAddr}, addr); err != nil {
+		return fmt.Errorf("getting ReservedAddr %s/%s: %w", edge.Namespace, edge.Spec.ReservedAddr, err)
+	}
+	if !addr.IsReserved() {
+		return fmt.Errorf("ReservedAddr %s/%s is not reserved yet", addr.Namespace, addr.Name)
+	}
+
+	hostports := []string{addr.Status.Addr}
+	if slices.Equal(edge.Status.Hostports, hostports) {
+		return nil
+	}
+	ctrl.LoggerFrom(ctx).V(3).Info("Using the address of the ReservedAddr", "reservedAddr", addr.Name, "addr", addr.Status.Addr)
+	edge.Status.Hostports = hostports
+	return r.Status().Update(ctx, edge)
+}
+
 func (r *TCPEdgeReconciler) findAddrWithMatchingMetadata(ctx context.Context, metadata ReservedAddrMetadata) (*ngrok.ReservedAddr, error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -447,6 +477,27 @@ func (r *TCPEdgeReconciler) listTCPEdgesForIPPolicy(ctx context.Context, obj cli
 	return recs
 }
 
+func (r *TCPEdgeReconciler) listTCPEdgesForReservedAddr(ctx context.Context, obj client.Object) []reconcile.Request {
+	edges := &ingressv1alpha1.TCPEdgeList{}
+	if err := r.Client.List(ctx, edges, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list TCPEdges for reserved addr", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, edge := range edges.Items {
+		if edge.Spec.ReservedAddr == obj.GetName() {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      edge.GetName(),
+					Namespace: edge.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
+
 func (r *TCPEdgeReconciler) updatePolicyModule(ctx context.Context, edge *ingressv1alpha1.TCPEdge, remoteEdge *ngrok.TCPEdge) error {
 	policy := edge.Spec.Policy
 	client := r.NgrokClientset.EdgeModules().TCP().RawPolicy()
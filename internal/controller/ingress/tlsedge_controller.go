@@ -33,6 +33,7 @@ import (
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -100,6 +101,14 @@ func (r *TLSEdgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&ingressv1alpha1.Domain{},
 			handler.EnqueueRequestsFromMapFunc(r.listTLSEdgesForDomain),
+		).
+		Watches(
+			&v1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.listTLSEdgesForCertificateAuthority),
+		).
+		Watches(
+			&v1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.listTLSEdgesForCertificateAuthority),
 		)
 
 	return controller.Complete(r)
@@ -218,7 +227,7 @@ func (r *TLSEdgeReconciler) updateEdge(ctx context.Context, edge *ingressv1alpha
 		return err
 	}
 
-	if err := r.setMutualTLS(ctx, resp, edge.Spec.MutualTLS); err != nil {
+	if err := r.setMutualTLS(ctx, resp, edge.Namespace, edge.Spec.MutualTLS); err != nil {
 		return err
 	}
 
@@ -297,7 +306,7 @@ func (r *TLSEdgeReconciler) reconcileTunnelGroupBackend(ctx context.Context, edg
 	return r.Status().Update(ctx, edge)
 }
 
-func (r *TLSEdgeReconciler) setMutualTLS(ctx context.Context, edge *ngrok.TLSEdge, mutualTls *ingressv1alpha1.EndpointMutualTLS) error {
+func (r *TLSEdgeReconciler) setMutualTLS(ctx context.Context, edge *ngrok.TLSEdge, namespace string, mutualTls *ingressv1alpha1.EndpointMutualTLS) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	client := r.NgrokClientset.EdgeModules().TLS().MutualTLS()
@@ -311,10 +320,19 @@ func (r *TLSEdgeReconciler) setMutualTLS(ctx context.Context, edge *ngrok.TLSEdg
 		return client.Delete(ctx, edge.ID)
 	}
 
-	_, err := client.Replace(ctx, &ngrok.EdgeMutualTLSReplace{
+	resolver := controllers.CertificateAuthorityResolver{
+		Client:                       r.Client,
+		CertificateAuthoritiesClient: r.NgrokClientset.CertificateAuthorities(),
+	}
+	caIDs, err := resolver.ResolveCertificateAuthorityIDs(ctx, namespace, mutualTls)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Replace(ctx, &ngrok.EdgeMutualTLSReplace{
 		ID: edge.ID,
 		Module: ngrok.EndpointMutualTLSMutate{
-			CertificateAuthorityIDs: mutualTls.CertificateAuthorities,
+			CertificateAuthorityIDs: caIDs,
 		},
 	})
 	return err
@@ -477,6 +495,38 @@ func (r *TLSEdgeReconciler) listTLSEdgesForIPPolicy(ctx context.Context, obj cli
 	return recs
 }
 
+// listTLSEdgesForCertificateAuthority returns the edges whose mutual TLS module loads a CA bundle from the
+// Secret or ConfigMap, so changing the bundle uploads the new certificates
+func (r *TLSEdgeReconciler) listTLSEdgesForCertificateAuthority(ctx context.Context, obj client.Object) []reconcile.Request {
+	edges := &ingressv1alpha1.TLSEdgeList{}
+	if err := r.Client.List(ctx, edges, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list TLSEdges for certificate authority", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, edge := range edges.Items {
+		mtls := edge.Spec.MutualTLS
+		if mtls == nil {
+			continue
+		}
+
+		names := mtls.CertificateAuthorityConfigMaps
+		if _, ok := obj.(*v1.Secret); ok {
+			names = mtls.CertificateAuthoritySecrets
+		}
+		if slices.Contains(names, obj.GetName()) {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      edge.GetName(),
+					Namespace: edge.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
+}
+
 func (r *TLSEdgeReconciler) listTLSEdgesForDomain(ctx context.Context, obj client.Object) []reconcile.Request {
 	r.Log.Info("Listing TLSEdges for domain to determine if they need to be reconciled")
 	domain, ok := obj.(*ingressv1alpha1.Domain)
@@ -30,8 +30,11 @@ import (
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ierr "github.com/ngrok/kubernetes-ingress-controller/internal/errors"
 	"github.com/ngrok/kubernetes-ingress-controller/pkg/tunneldriver"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -92,6 +95,14 @@ func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// Secrets don't have a generation, so their updates get past the predicates that tunnels use
+	if err := cont.Watch(
+		source.Kind(mgr.GetCache(), &v1.Secret{}),
+		handler.EnqueueRequestsFromMapFunc(r.listTunnelsForSecret),
+	); err != nil {
+		return err
+	}
+
 	return mgr.Add(cont)
 }
 
@@ -110,7 +121,53 @@ func (r *TunnelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 func (r *TunnelReconciler) update(ctx context.Context, tunnel *ingressv1alpha1.Tunnel) error {
 	tunnelName := r.statusID(tunnel)
-	return r.TunnelDriver.CreateTunnel(ctx, tunnelName, tunnel.Spec)
+	backendCAPEM, err := r.backendCAPEM(ctx, tunnel)
+	if err != nil {
+		return err
+	}
+	return r.TunnelDriver.CreateTunnel(ctx, tunnelName, tunnel.Spec, backendCAPEM)
+}
+
+// backendCAPEM returns the PEM encoded certificate authorities in the tunnel's backend certificate authority
+// secret, or nil if the tunnel verifies its HTTPS backends against the system's certificate authorities
+func (r *TunnelReconciler) backendCAPEM(ctx context.Context, tunnel *ingressv1alpha1.Tunnel) ([]byte, error) {
+	if tunnel.Spec.BackendConfig == nil || tunnel.Spec.BackendConfig.CertificateAuthoritySecret == "" {
+		return nil, nil
+	}
+
+	secret := &v1.Secret{}
+	name := types.NamespacedName{Name: tunnel.Spec.BackendConfig.CertificateAuthoritySecret, Namespace: tunnel.Namespace}
+	if err := r.Client.Get(ctx, name, secret); err != nil {
+		return nil, err
+	}
+
+	pem := secret.Data[ingressv1alpha1.BackendCACertKey]
+	if len(pem) == 0 {
+		return nil, ierr.NewErrInvalidConfiguration(fmt.Errorf("key '%s' is missing or empty in backend certificate authority Secret %s", ingressv1alpha1.BackendCACertKey, name))
+	}
+	return pem, nil
+}
+
+// listTunnelsForSecret returns the tunnels that verify their backends with the certificate authorities in the secret
+func (r *TunnelReconciler) listTunnelsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	tunnels := &ingressv1alpha1.TunnelList{}
+	if err := r.Client.List(ctx, tunnels, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list Tunnels for secret", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	recs := []reconcile.Request{}
+	for _, tunnel := range tunnels.Items {
+		if tunnel.Spec.BackendConfig != nil && tunnel.Spec.BackendConfig.CertificateAuthoritySecret == obj.GetName() {
+			recs = append(recs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      tunnel.GetName(),
+					Namespace: tunnel.GetNamespace(),
+				},
+			})
+		}
+	}
+	return recs
 }
 
 func (r *TunnelReconciler) delete(ctx context.Context, tunnel *ingressv1alpha1.Tunnel) error {
@@ -0,0 +1,138 @@
+/*
+MIT License
+
+Copyright (c) 2022 ngrok, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ngrok
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const defaultStatusReportInterval = 30 * time.Second
+
+// NgrokControllerStatusReporter periodically writes a summary of the controller's state
+// to a cluster scoped NgrokControllerStatus singleton.
+type NgrokControllerStatusReporter struct {
+	client.Client
+	Log    logr.Logger
+	Driver *store.Driver
+
+	// Name is the name of the NgrokControllerStatus singleton
+	Name string
+	// Interval is how often the status is reported. Defaults to 30 seconds.
+	Interval time.Duration
+	// APIHealthCheck is called on each report to determine if the ngrok API is reachable
+	APIHealthCheck func(ctx context.Context) error
+}
+
+var _ manager.LeaderElectionRunnable = &NgrokControllerStatusReporter{}
+
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngrokcontrollerstatuses,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=ngrok.k8s.ngrok.com,resources=ngrokcontrollerstatuses/status,verbs=get;update;patch
+
+// SetupWithManager adds the reporter to the manager so it runs while the manager is running.
+func (r *NgrokControllerStatusReporter) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Interval <= 0 {
+		r.Interval = defaultStatusReportInterval
+	}
+	return mgr.Add(r)
+}
+
+// NeedLeaderElection makes sure only the leader reports the status
+func (r *NgrokControllerStatusReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start reports the status immediately and then on every interval until the context is cancelled
+func (r *NgrokControllerStatusReporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Report(ctx); err != nil {
+			r.Log.Error(err, "error reporting controller status")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report creates the NgrokControllerStatus singleton if it doesn't exist yet and updates its
+// status with the current counts from the store and the health of the ngrok API
+func (r *NgrokControllerStatusReporter) Report(ctx context.Context) error {
+	status := &ngrokv1alpha1.NgrokControllerStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.Name,
+		},
+	}
+
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(status), status)
+	switch {
+	case err == nil:
+		// all good, continue
+	case apierrors.IsNotFound(err):
+		if err := r.Client.Create(ctx, status); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	counts := r.Driver.Counts()
+	now := metav1.Now()
+
+	status.Status.ManagedIngresses = counts.Ingresses
+	status.Status.HTTPSEdges = counts.HTTPSEdges
+	status.Status.Domains = counts.Domains
+	status.Status.Tunnels = counts.Tunnels
+	status.Status.LastUpdated = now
+
+	status.Status.Degraded = false
+	status.Status.Message = ""
+	if r.APIHealthCheck != nil {
+		if err := r.APIHealthCheck(ctx); err != nil {
+			r.Log.Error(err, "ngrok API health check failed")
+			status.Status.Degraded = true
+			status.Status.Message = fmt.Sprintf("unable to reach the ngrok API: %s", err)
+		} else {
+			status.Status.LastSuccessfulAPIContact = &now
+		}
+	}
+
+	return r.Client.Status().Update(ctx, status)
+}
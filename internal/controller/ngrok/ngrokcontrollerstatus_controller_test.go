@@ -0,0 +1,116 @@
+package ngrok
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+)
+
+func TestNgrokControllers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ngrok Controllers package Test Suite")
+}
+
+var _ = Describe("NgrokControllerStatusReporter", func() {
+	const statusName = "ngrok-ingress-controller-manager"
+
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+	var (
+		ctx      context.Context
+		c        client.Client
+		reporter *NgrokControllerStatusReporter
+		apiErr   error
+	)
+
+	getStatus := func() *ngrokv1alpha1.NgrokControllerStatus {
+		status := &ngrokv1alpha1.NgrokControllerStatus{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: statusName}, status)).To(Succeed())
+		return status
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		apiErr = nil
+
+		ic := store.NewTestIngressClass("ngrok", true, true)
+		i1 := store.NewTestIngressV1WithClass("test-ingress", "test-namespace", "ngrok")
+		i2 := store.NewTestIngressV1WithClass("test-ingress-2", "test-namespace", "ngrok")
+		i3 := store.NewTestIngressV1WithClass("other-ingress", "test-namespace", "other")
+		d1 := store.NewDomainV1("example.com", "test-namespace")
+		e1 := store.NewHTTPSEdge("example-com", "test-namespace", "example.com")
+		c = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&ic, &i1, &i2, &i3, &d1, &e1).
+			WithStatusSubresource(&ngrokv1alpha1.NgrokControllerStatus{}).
+			Build()
+
+		driver := store.NewDriver(
+			logr.Discard(),
+			scheme,
+			"k8s.ngrok.com/ingress-controller",
+			types.NamespacedName{Name: statusName},
+			false,
+		)
+		Expect(driver.Seed(ctx, c)).To(Succeed())
+
+		reporter = &NgrokControllerStatusReporter{
+			Client: c,
+			Log:    logr.Discard(),
+			Driver: driver,
+			Name:   statusName,
+			APIHealthCheck: func(ctx context.Context) error {
+				return apiErr
+			},
+		}
+	})
+
+	It("Should create the singleton with the store's counts", func() {
+		Expect(reporter.Report(ctx)).To(Succeed())
+
+		status := getStatus()
+		Expect(status.Status.ManagedIngresses).To(Equal(2))
+		Expect(status.Status.HTTPSEdges).To(Equal(1))
+		Expect(status.Status.Domains).To(Equal(1))
+		Expect(status.Status.Tunnels).To(Equal(0))
+		Expect(status.Status.Degraded).To(BeFalse())
+		Expect(status.Status.LastSuccessfulAPIContact).ToNot(BeNil())
+	})
+
+	It("Should be degraded when the ngrok API is unreachable", func() {
+		Expect(reporter.Report(ctx)).To(Succeed())
+		lastContact := getStatus().Status.LastSuccessfulAPIContact
+
+		apiErr = errors.New("connection refused")
+		Expect(reporter.Report(ctx)).To(Succeed())
+
+		status := getStatus()
+		Expect(status.Status.Degraded).To(BeTrue())
+		Expect(status.Status.Message).To(ContainSubstring("connection refused"))
+		Expect(status.Status.LastSuccessfulAPIContact.Equal(lastContact)).To(BeTrue())
+
+		apiErr = nil
+		Expect(reporter.Report(ctx)).To(Succeed())
+
+		status = getStatus()
+		Expect(status.Status.Degraded).To(BeFalse())
+		Expect(status.Status.Message).To(BeEmpty())
+	})
+})
@@ -28,8 +28,11 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/store"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -60,7 +63,18 @@ type NgrokTrafficPolicyReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *NgrokTrafficPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	log := log.FromContext(ctx)
+
+	// Invalid policies aren't applied to the edges they're attached to, so let their owners know why
+	policy := &ngrokv1alpha1.NgrokTrafficPolicy{}
+	if err := r.Client.Get(ctx, req.NamespacedName, policy); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	} else if err == nil {
+		if err := ingressv1alpha1.ValidateTrafficPolicy(policy.Spec.Policy); err != nil {
+			log.Error(err, "invalid traffic policy", "policy", req.NamespacedName)
+			r.Recorder.Event(policy, corev1.EventTypeWarning, events.ReasonInvalidTrafficPolicy, err.Error())
+		}
+	}
 
 	err := r.Driver.SyncEdges(ctx, r.Client)
 	return ctrl.Result{}, err
@@ -0,0 +1,62 @@
+// Package credentials resolves the ngrok credentials used by the controller. By default they are read from
+// the environment variables set from the controller's Secret, but they can also be read from another
+// environment variable or a file, for deployments that inject credentials without a Secret.
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultAPIKeyEnv is the environment variable the ngrok API key is read from by default
+	DefaultAPIKeyEnv = "NGROK_API_KEY"
+	// DefaultAuthtokenEnv is the environment variable the ngrok authtoken is read from by default
+	DefaultAuthtokenEnv = "NGROK_AUTHTOKEN"
+)
+
+// Source is where a credential is read from. At most one of Env or File may be set, and when neither is,
+// the credential is read from the default environment variable.
+type Source struct {
+	// Env is the name of an environment variable holding the credential
+	Env string
+	// File is the path of a file holding the credential
+	File string
+}
+
+// Resolve reads a credential from its source, falling back to the default environment variable when the
+// source isn't set. It returns the credential along with a description of where it was read from.
+func Resolve(name string, src Source, defaultEnv string) (string, string, error) {
+	if src.Env != "" && src.File != "" {
+		return "", "", fmt.Errorf("only one of an environment variable or a file may be used for the %s, got both", name)
+	}
+
+	switch {
+	case src.File != "":
+		contents, err := os.ReadFile(src.File)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read the %s from file %q: %w", name, src.File, err)
+		}
+		value := strings.TrimSpace(string(contents))
+		if value == "" {
+			return "", "", fmt.Errorf("the %s file %q is empty", name, src.File)
+		}
+		return value, fmt.Sprintf("file %s", src.File), nil
+	case src.Env != "":
+		return fromEnv(name, src.Env)
+	default:
+		return fromEnv(name, defaultEnv)
+	}
+}
+
+func fromEnv(name, env string) (string, string, error) {
+	value, ok := os.LookupEnv(env)
+	if !ok {
+		return "", "", fmt.Errorf("%s environment variable should be set for the %s, but was not", env, name)
+	}
+	if value == "" {
+		return "", "", fmt.Errorf("%s environment variable for the %s is empty", env, name)
+	}
+	return value, fmt.Sprintf("environment variable %s", env), nil
+}
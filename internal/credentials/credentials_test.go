@@ -0,0 +1,85 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCredentialFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credential")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveFromDefaultEnv(t *testing.T) {
+	t.Setenv(DefaultAPIKeyEnv, "secret-api-key")
+
+	value, from, err := Resolve("api key", Source{}, DefaultAPIKeyEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret-api-key", value)
+	assert.Equal(t, "environment variable NGROK_API_KEY", from)
+}
+
+func TestResolveFromEnv(t *testing.T) {
+	t.Setenv("INJECTED_API_KEY", "injected-api-key")
+
+	value, from, err := Resolve("api key", Source{Env: "INJECTED_API_KEY"}, DefaultAPIKeyEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "injected-api-key", value)
+	assert.Equal(t, "environment variable INJECTED_API_KEY", from)
+}
+
+func TestResolveFromFile(t *testing.T) {
+	path := writeCredentialFile(t, "file-authtoken\n")
+
+	value, from, err := Resolve("authtoken", Source{File: path}, DefaultAuthtokenEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-authtoken", value)
+	assert.Equal(t, "file "+path, from)
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	t.Setenv(DefaultAPIKeyEnv, "secret-api-key")
+	t.Setenv("INJECTED_API_KEY", "injected-api-key")
+	path := writeCredentialFile(t, "file-api-key")
+
+	// the env var takes precedence over the secret
+	value, _, err := Resolve("api key", Source{Env: "INJECTED_API_KEY"}, DefaultAPIKeyEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "injected-api-key", value)
+
+	// the file takes precedence over the secret
+	value, _, err = Resolve("api key", Source{File: path}, DefaultAPIKeyEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-api-key", value)
+}
+
+func TestResolveOnlyOneSource(t *testing.T) {
+	t.Setenv("INJECTED_API_KEY", "injected-api-key")
+	path := writeCredentialFile(t, "file-api-key")
+
+	_, _, err := Resolve("api key", Source{Env: "INJECTED_API_KEY", File: path}, DefaultAPIKeyEnv)
+	assert.Error(t, err)
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Setenv("EMPTY_API_KEY", "")
+
+	_, _, err := Resolve("api key", Source{Env: "UNSET_API_KEY"}, DefaultAPIKeyEnv)
+	assert.Error(t, err)
+
+	_, _, err = Resolve("api key", Source{Env: "EMPTY_API_KEY"}, DefaultAPIKeyEnv)
+	assert.Error(t, err)
+
+	_, _, err = Resolve("api key", Source{File: filepath.Join(t.TempDir(), "missing")}, DefaultAPIKeyEnv)
+	assert.Error(t, err)
+
+	_, _, err = Resolve("api key", Source{File: writeCredentialFile(t, " \n")}, DefaultAPIKeyEnv)
+	assert.Error(t, err)
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // Not all domains are reconciled yet and have a domain in their status
@@ -42,10 +44,53 @@ func (e ErrNotFoundInStore) Error() string {
 	return e.message
 }
 
-// IsErrorNotFound: Reflect: returns true if the error is a ErrNotFoundInStore
+// IsErrorNotFound: Reflect: returns true if the error is a ErrNotFoundInStore or a NotFoundError
 func IsErrorNotFound(err error) bool {
-	_, ok := err.(ErrNotFoundInStore)
-	return ok
+	switch err.(type) {
+	case ErrNotFoundInStore, *NotFoundError:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotFoundError is meant to be used when a named object is not found in the store, so that the caller can
+// tell which object is missing
+type NotFoundError struct {
+	// GroupVersion is the group and version of the object's kind
+	GroupVersion schema.GroupVersion
+	Kind         string
+	Name         string
+	// Namespace is empty for cluster scoped objects
+	Namespace string
+}
+
+// NewNotFoundError returns a new NotFoundError for the object of the kind
+func NewNotFoundError(gvk schema.GroupVersionKind, name, namespace string) *NotFoundError {
+	return &NotFoundError{
+		GroupVersion: gvk.GroupVersion(),
+		Kind:         gvk.Kind,
+		Name:         name,
+		Namespace:    namespace,
+	}
+}
+
+// Error: Stringer: returns the error message
+func (e *NotFoundError) Error() string {
+	if e.Namespace == "" {
+		return fmt.Sprintf("%s %s not found", e.Kind, e.Name)
+	}
+	return fmt.Sprintf("%s %s/%s not found", e.Kind, e.Namespace, e.Name)
+}
+
+// GVK returns the group, version, and kind of the object that wasn't found
+func (e *NotFoundError) GVK() schema.GroupVersionKind {
+	return e.GroupVersion.WithKind(e.Kind)
+}
+
+// NamespacedName returns the name and namespace of the object that wasn't found
+func (e *NotFoundError) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: e.Name, Namespace: e.Namespace}
 }
 
 // ErrInvalidIngressClass is meant to be used when an ingress object has an invalid ingress class
@@ -87,6 +132,27 @@ func IsErrDifferentIngressClass(err error) bool {
 	return ok
 }
 
+// ErrIngressIgnored is meant to be used when an ingress object is annotated to be ignored by the controller
+type ErrIngressIgnored struct {
+	annotation string
+}
+
+// NewErrIngressIgnored returns a new ErrIngressIgnored for an ingress carrying the annotation
+func NewErrIngressIgnored(annotation string) ErrIngressIgnored {
+	return ErrIngressIgnored{annotation: annotation}
+}
+
+// Error: Stringer: returns the error message
+func (e ErrIngressIgnored) Error() string {
+	return fmt.Sprintf("The controller will not reconcile this ingress object because it is annotated with %s.", e.annotation)
+}
+
+// IsErrIngressIgnored: Reflect: returns true if the error is a ErrIngressIgnored
+func IsErrIngressIgnored(err error) bool {
+	_, ok := err.(ErrIngressIgnored)
+	return ok
+}
+
 // ErrInvalidIngressSpec is meant to be used when an ingress object has an invalid spec
 type ErrInvalidIngressSpec struct {
 	errors []string
@@ -151,3 +217,46 @@ func (e ErrInvalidConfiguration) Error() string {
 func (e ErrInvalidConfiguration) Unwrap() error {
 	return e.cause
 }
+
+// ErrNamespaceNotWatched is meant to be used when an object from a namespace the controller doesn't watch is
+// added to the store
+type ErrNamespaceNotWatched struct {
+	namespace string
+}
+
+// NewErrNamespaceNotWatched returns a new ErrNamespaceNotWatched
+func NewErrNamespaceNotWatched(namespace string) ErrNamespaceNotWatched {
+	return ErrNamespaceNotWatched{namespace: namespace}
+}
+
+// Error: Stringer: returns the error message
+func (e ErrNamespaceNotWatched) Error() string {
+	return fmt.Sprintf("namespace %s is not watched", e.namespace)
+}
+
+// IsErrNamespaceNotWatched: Reflect: returns true if the error is a ErrNamespaceNotWatched
+func IsErrNamespaceNotWatched(err error) bool {
+	_, ok := err.(ErrNamespaceNotWatched)
+	return ok
+}
+
+// ErrInvalidContinueToken is meant to be used when a continue token for paging through a list can't be used
+type ErrInvalidContinueToken struct {
+	token string
+}
+
+// NewErrInvalidContinueToken returns a new ErrInvalidContinueToken
+func NewErrInvalidContinueToken(token string) ErrInvalidContinueToken {
+	return ErrInvalidContinueToken{token: token}
+}
+
+// Error: Stringer: returns the error message
+func (e ErrInvalidContinueToken) Error() string {
+	return fmt.Sprintf("invalid continue token %q", e.token)
+}
+
+// IsErrInvalidContinueToken: Reflect: returns true if the error is a ErrInvalidContinueToken
+func IsErrInvalidContinueToken(err error) bool {
+	_, ok := err.(ErrInvalidContinueToken)
+	return ok
+}
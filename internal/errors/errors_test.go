@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestAddErrorToNewInvalidIngressSpec(t *testing.T) {
@@ -14,3 +16,17 @@ func TestAddErrorToNewInvalidIngressSpec(t *testing.T) {
 	assert.True(t, err.HasErrors())
 	assert.Len(t, err.errors, 2)
 }
+
+func TestNotFoundError(t *testing.T) {
+	err := NewNotFoundError(schema.GroupVersionKind{Group: "ingress.k8s.ngrok.com", Version: "v1alpha1", Kind: "NgrokModuleSet"}, "modules", "test")
+	assert.True(t, IsErrorNotFound(err))
+	assert.Equal(t, "NgrokModuleSet test/modules not found", err.Error())
+	assert.Equal(t, schema.GroupVersionKind{Group: "ingress.k8s.ngrok.com", Version: "v1alpha1", Kind: "NgrokModuleSet"}, err.GVK())
+	assert.Equal(t, types.NamespacedName{Name: "modules", Namespace: "test"}, err.NamespacedName())
+
+	clusterScoped := NewNotFoundError(schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "IngressClass"}, "ngrok", "")
+	assert.Equal(t, "IngressClass ngrok not found", clusterScoped.Error())
+
+	assert.True(t, IsErrorNotFound(NewErrorNotFound("not found")))
+	assert.False(t, IsErrorNotFound(NewErrInvalidContinueToken("token")))
+}
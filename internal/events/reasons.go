@@ -0,0 +1,60 @@
+// Package events holds the reasons for the Kubernetes events emitted by the controllers. Each reason is
+// distinct so events can be filtered with `kubectl get events --field-selector reason=<reason>`.
+package events
+
+// Reasons for the lifecycle of the ngrok resources managed by the controllers
+const (
+	ReasonCreating    = "Creating"
+	ReasonCreated     = "Created"
+	ReasonCreateError = "CreateError"
+	ReasonUpdating    = "Updating"
+	ReasonUpdated     = "Updated"
+	ReasonUpdateError = "UpdateError"
+	ReasonDeleting    = "Deleting"
+	ReasonDeleted     = "Deleted"
+	ReasonDeleteError = "DeleteError"
+)
+
+// Reasons for HTTPSEdge routes
+const (
+	ReasonFailedValidate          = "FailedValidate"
+	ReasonRouteTakeOfflineFailed  = "RouteTakeOfflineFailed"
+	ReasonRouteModuleUpdateFailed = "RouteModuleUpdateFailed"
+)
+
+// Reasons for translating ingresses into ngrok resources
+const (
+	// ReasonEdgeCreated is emitted on an ingress when an edge is created for one of its hosts
+	ReasonEdgeCreated = "EdgeCreated"
+	// ReasonModuleResolutionFailed is emitted on an ingress when its module sets or traffic policy can't be resolved
+	ReasonModuleResolutionFailed = "ModuleResolutionFailed"
+	// ReasonBackendNotFound is emitted on an ingress when one of its backend services can't be found
+	ReasonBackendNotFound = "BackendNotFound"
+	// ReasonDomainNotReady is emitted on an ingress while its domains haven't been reserved yet
+	ReasonDomainNotReady = "DomainNotReady"
+	// ReasonTLSConflict is emitted on an ingress when the TLS it requests conflicts with its reserved domain's certificate
+	ReasonTLSConflict = "TLSConflict"
+	// ReasonRouteConflict is emitted on an ingress when one of its paths is already routed on the same edge by another ingress
+	ReasonRouteConflict = "RouteConflict"
+	// ReasonInvalidPath is emitted on an ingress when one of its paths is an invalid path expression
+	ReasonInvalidPath = "InvalidPath"
+	// ReasonDeprecatedAnnotation is emitted on an object using a deprecated annotation
+	ReasonDeprecatedAnnotation = "DeprecatedAnnotation"
+	// ReasonIngressAccepted is emitted on an ingress when the controller accepts it as one it handles
+	ReasonIngressAccepted = "IngressAccepted"
+	// ReasonIngressClassMismatch is emitted on an ingress the controller handled before, but now ignores because it uses another controller's class
+	ReasonIngressClassMismatch = "IngressClassMismatch"
+)
+
+// Reasons for Domains
+const (
+	// ReasonDeletionProtected is emitted on a Domain with deletion protection when it is deleted without
+	// releasing its reserved domain in ngrok
+	ReasonDeletionProtected = "DeletionProtected"
+)
+
+// Reasons for NgrokTrafficPolicies
+const (
+	// ReasonInvalidTrafficPolicy is emitted on an NgrokTrafficPolicy whose policy document ngrok can't apply
+	ReasonInvalidTrafficPolicy = "InvalidTrafficPolicy"
+)
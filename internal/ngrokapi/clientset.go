@@ -3,6 +3,8 @@ package ngrokapi
 import (
 	"github.com/ngrok/ngrok-api-go/v5"
 	tunnel_group_backends "github.com/ngrok/ngrok-api-go/v5/backends/tunnel_group"
+	weighted_backends "github.com/ngrok/ngrok-api-go/v5/backends/weighted"
+	"github.com/ngrok/ngrok-api-go/v5/certificate_authorities"
 	https_edges "github.com/ngrok/ngrok-api-go/v5/edges/https"
 	https_edge_routes "github.com/ngrok/ngrok-api-go/v5/edges/https_routes"
 	tcp_edges "github.com/ngrok/ngrok-api-go/v5/edges/tcp"
@@ -11,9 +13,12 @@ import (
 	"github.com/ngrok/ngrok-api-go/v5/ip_policy_rules"
 	"github.com/ngrok/ngrok-api-go/v5/reserved_addrs"
 	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
+	"github.com/ngrok/ngrok-api-go/v5/tls_certificates"
 )
 
 type Clientset interface {
+	CertificateAuthorities() *certificate_authorities.Client
+	CloudEndpoints() *CloudEndpointsClient
 	Domains() *reserved_domains.Client
 	EdgeModules() EdgeModulesClientset
 	HTTPSEdges() *https_edges.Client
@@ -22,39 +27,57 @@ type Clientset interface {
 	IPPolicyRules() *ip_policy_rules.Client
 	TCPAddresses() *reserved_addrs.Client
 	TCPEdges() *tcp_edges.Client
+	TLSCertificates() *tls_certificates.Client
 	TLSEdges() *tls_edges.Client
 	TunnelGroupBackends() *tunnel_group_backends.Client
+	WeightedBackends() *weighted_backends.Client
 }
 
 type DefaultClientset struct {
-	domainsClient             *reserved_domains.Client
-	edgeModulesClientset      *defaultEdgeModulesClientset
-	httpsEdgesClient          *https_edges.Client
-	httpsEdgeRoutesClient     *https_edge_routes.Client
-	ipPoliciesClient          *ip_policies.Client
-	ipPolicyRulesClient       *ip_policy_rules.Client
-	tcpAddrsClient            *reserved_addrs.Client
-	tcpEdgesClient            *tcp_edges.Client
-	tlsEdgesClient            *tls_edges.Client
-	tunnelGroupBackendsClient *tunnel_group_backends.Client
+	certificateAuthoritiesClient *certificate_authorities.Client
+	cloudEndpointsClient         *CloudEndpointsClient
+	domainsClient                *reserved_domains.Client
+	edgeModulesClientset         *defaultEdgeModulesClientset
+	httpsEdgesClient             *https_edges.Client
+	httpsEdgeRoutesClient        *https_edge_routes.Client
+	ipPoliciesClient             *ip_policies.Client
+	ipPolicyRulesClient          *ip_policy_rules.Client
+	tcpAddrsClient               *reserved_addrs.Client
+	tcpEdgesClient               *tcp_edges.Client
+	tlsCertificatesClient        *tls_certificates.Client
+	tlsEdgesClient               *tls_edges.Client
+	tunnelGroupBackendsClient    *tunnel_group_backends.Client
+	weightedBackendsClient       *weighted_backends.Client
 }
 
 // NewClientSet creates a new ClientSet from an ngrok client config.
 func NewClientSet(config *ngrok.ClientConfig) *DefaultClientset {
 	return &DefaultClientset{
-		domainsClient:             reserved_domains.NewClient(config),
-		edgeModulesClientset:      newEdgeModulesClientset(config),
-		httpsEdgesClient:          https_edges.NewClient(config),
-		httpsEdgeRoutesClient:     https_edge_routes.NewClient(config),
-		ipPoliciesClient:          ip_policies.NewClient(config),
-		ipPolicyRulesClient:       ip_policy_rules.NewClient(config),
-		tcpAddrsClient:            reserved_addrs.NewClient(config),
-		tcpEdgesClient:            tcp_edges.NewClient(config),
-		tlsEdgesClient:            tls_edges.NewClient(config),
-		tunnelGroupBackendsClient: tunnel_group_backends.NewClient(config),
+		certificateAuthoritiesClient: certificate_authorities.NewClient(config),
+		cloudEndpointsClient:         NewCloudEndpointsClient(config),
+		domainsClient:                reserved_domains.NewClient(config),
+		edgeModulesClientset:         newEdgeModulesClientset(config),
+		httpsEdgesClient:             https_edges.NewClient(config),
+		httpsEdgeRoutesClient:        https_edge_routes.NewClient(config),
+		ipPoliciesClient:             ip_policies.NewClient(config),
+		ipPolicyRulesClient:          ip_policy_rules.NewClient(config),
+		tcpAddrsClient:               reserved_addrs.NewClient(config),
+		tcpEdgesClient:               tcp_edges.NewClient(config),
+		tlsCertificatesClient:        tls_certificates.NewClient(config),
+		tlsEdgesClient:               tls_edges.NewClient(config),
+		tunnelGroupBackendsClient:    tunnel_group_backends.NewClient(config),
+		weightedBackendsClient:       weighted_backends.NewClient(config),
 	}
 }
 
+func (c *DefaultClientset) CertificateAuthorities() *certificate_authorities.Client {
+	return c.certificateAuthoritiesClient
+}
+
+func (c *DefaultClientset) CloudEndpoints() *CloudEndpointsClient {
+	return c.cloudEndpointsClient
+}
+
 func (c *DefaultClientset) Domains() *reserved_domains.Client {
 	return c.domainsClient
 }
@@ -83,6 +106,10 @@ func (c *DefaultClientset) TCPAddresses() *reserved_addrs.Client {
 	return c.tcpAddrsClient
 }
 
+func (c *DefaultClientset) TLSCertificates() *tls_certificates.Client {
+	return c.tlsCertificatesClient
+}
+
 func (c *DefaultClientset) TLSEdges() *tls_edges.Client {
 	return c.tlsEdgesClient
 }
@@ -94,3 +121,7 @@ func (c *DefaultClientset) TCPEdges() *tcp_edges.Client {
 func (c *DefaultClientset) TunnelGroupBackends() *tunnel_group_backends.Client {
 	return c.tunnelGroupBackendsClient
 }
+
+func (c *DefaultClientset) WeightedBackends() *weighted_backends.Client {
+	return c.weightedBackendsClient
+}
@@ -0,0 +1,90 @@
+package ngrokapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+)
+
+// CloudEndpoint is an always-on endpoint hosted by ngrok rather than by an agent. ngrok-api-go can only list
+// and get endpoints, so the cloud endpoint types and client are defined here and call the endpoints API directly.
+type CloudEndpoint struct {
+	ID            string `json:"id,omitempty"`
+	URI           string `json:"uri,omitempty"`
+	URL           string `json:"url,omitempty"`
+	PublicURL     string `json:"public_url,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Metadata      string `json:"metadata,omitempty"`
+	TrafficPolicy string `json:"traffic_policy,omitempty"`
+}
+
+type CloudEndpointCreate struct {
+	URL           string `json:"url"`
+	Type          string `json:"type"`
+	Description   string `json:"description,omitempty"`
+	Metadata      string `json:"metadata,omitempty"`
+	TrafficPolicy string `json:"traffic_policy,omitempty"`
+}
+
+type CloudEndpointUpdate struct {
+	ID            string  `json:"id,omitempty"`
+	URL           *string `json:"url,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Metadata      *string `json:"metadata,omitempty"`
+	TrafficPolicy *string `json:"traffic_policy,omitempty"`
+}
+
+// cloudEndpointType is the type of endpoint the CloudEndpointsClient creates
+const cloudEndpointType = "cloud"
+
+type CloudEndpointsClient struct {
+	base *ngrok.BaseClient
+}
+
+func NewCloudEndpointsClient(config *ngrok.ClientConfig) *CloudEndpointsClient {
+	return &CloudEndpointsClient{base: ngrok.NewBaseClient(config)}
+}
+
+// Create creates a cloud endpoint
+func (c *CloudEndpointsClient) Create(ctx context.Context, endpoint *CloudEndpointCreate) (*CloudEndpoint, error) {
+	if endpoint == nil {
+		return nil, errors.New("cloud endpoint create cannot be nil")
+	}
+	endpoint.Type = cloudEndpointType
+
+	var res CloudEndpoint
+	if err := c.base.Do(ctx, "POST", &url.URL{Path: "/endpoints"}, endpoint, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Get gets the cloud endpoint with the ID
+func (c *CloudEndpointsClient) Get(ctx context.Context, id string) (*CloudEndpoint, error) {
+	var res CloudEndpoint
+	if err := c.base.Do(ctx, "GET", &url.URL{Path: "/endpoints/" + url.PathEscape(id)}, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Update updates the fields of the cloud endpoint that are set
+func (c *CloudEndpointsClient) Update(ctx context.Context, endpoint *CloudEndpointUpdate) (*CloudEndpoint, error) {
+	if endpoint == nil {
+		return nil, errors.New("cloud endpoint update cannot be nil")
+	}
+
+	var res CloudEndpoint
+	if err := c.base.Do(ctx, "PATCH", &url.URL{Path: "/endpoints/" + url.PathEscape(endpoint.ID)}, endpoint, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Delete deletes the cloud endpoint with the ID
+func (c *CloudEndpointsClient) Delete(ctx context.Context, id string) error {
+	return c.base.Do(ctx, "DELETE", &url.URL{Path: "/endpoints/" + url.PathEscape(id)}, nil, nil)
+}
@@ -0,0 +1,178 @@
+package ngrokapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+)
+
+// RetryPolicy is how DoWithRetry retries ngrok API calls that fail because of rate limiting or errors on
+// ngrok's side. The delay before each retry doubles, starting at BaseDelay, up to MaxDelay. The zero value
+// uses DefaultRetryPolicy, so reconcilers that leave their RetryPolicy unset get the default retries.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the call is made before giving up, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration
+	// Jitter is the fraction of each delay, from 0 to 1, that's randomized so that calls failing together don't
+	// all retry at the same time
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by DoWithRetry for the zero RetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+}
+
+// delay returns how long to wait before the retry after the attempt, counting from 1
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// IsRetryable returns true if the call that failed with the error may succeed when it's retried. The ngrok API
+// rate limiting the call, a server error, or a network error are retryable, while any other error, like a 4xx
+// response for a bad request, is terminal.
+func IsRetryable(err error) bool {
+	var ngrokErr *ngrok.Error
+	if errors.As(err, &ngrokErr) {
+		return ngrokErr.StatusCode == http.StatusTooManyRequests || ngrokErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsRateLimited returns true if the ngrok API rejected the call because of rate limiting. Unlike the other
+// retryable errors, the call is known not to have been made, so even a call that isn't idempotent can be retried.
+func IsRateLimited(err error) bool {
+	var ngrokErr *ngrok.Error
+	return errors.As(err, &ngrokErr) && ngrokErr.StatusCode == http.StatusTooManyRequests
+}
+
+// retryAfterDetail is the ngrok error detail RetryAfterTransport copies a rate limited response's Retry-After
+// header into, since ngrok.Error doesn't keep the response's headers
+const retryAfterDetail = "retry_after"
+
+// RetryAfter returns how long the ngrok API asked to wait before retrying the call that failed with the error, or 0
+// if the error isn't a rate limited response with a Retry-After header. The header is only available on errors
+// from clients whose HTTP client uses RetryAfterTransport.
+func RetryAfter(err error) time.Duration {
+	var ngrokErr *ngrok.Error
+	if !errors.As(err, &ngrokErr) || ngrokErr.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	value := ngrokErr.Details[retryAfterDetail]
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// RetryAfterTransport wraps an http.RoundTripper so that the Retry-After header of the ngrok API's rate limited
+// responses is kept in the details of the ngrok.Error the client returns for them, where RetryAfter finds it
+type RetryAfterTransport struct {
+	// Base makes the requests, http.DefaultTransport is used when it's nil
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var ngrokErr ngrok.Error
+	if json.Unmarshal(body, &ngrokErr) == nil {
+		if ngrokErr.Details == nil {
+			ngrokErr.Details = map[string]string{}
+		}
+		ngrokErr.Details[retryAfterDetail] = resp.Header.Get("Retry-After")
+		if withRetryAfter, err := json.Marshal(&ngrokErr); err == nil {
+			body = withRetryAfter
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp, nil
+}
+
+// DoWithRetry calls fn until it succeeds, returns an error that isn't retryable, or the policy's attempts run
+// out, in which case the last error is returned. It stops waiting to retry when the context is done. The zero
+// policy uses DefaultRetryPolicy. A rate limited call is retried no sooner than its Retry-After, and when that's
+// longer than the policy's MaxDelay the error is returned right away so that the caller can requeue instead.
+func DoWithRetry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	return DoWithRetryIf(ctx, policy, IsRetryable, fn)
+}
+
+// DoWithRetryIf is DoWithRetry, only retrying the errors retryable returns true for. Calls that create resources
+// aren't idempotent, so they're retried with IsRateLimited: a create that failed on ngrok's side or on the network
+// may have created the resource anyway. Otherwise, fn has to look for the resource before creating it again.
+func DoWithRetryIf(ctx context.Context, policy RetryPolicy, retryable func(error) bool, fn func() error) error {
+	if policy == (RetryPolicy{}) {
+		policy = DefaultRetryPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		delay := policy.delay(attempt)
+		if retryAfter := RetryAfter(err); retryAfter > delay {
+			if policy.MaxDelay > 0 && retryAfter > policy.MaxDelay {
+				return err
+			}
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w, last error: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package ngrokapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ngrok/ngrok-api-go/v5"
+	"github.com/ngrok/ngrok-api-go/v5/reserved_domains"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, Jitter: 0.5}
+
+// failingTimes returns a function that fails with the error the first n times it's called and succeeds after
+func failingTimes(n int, err error) (fn func() error, calls *int) {
+	calls = new(int)
+	return func() error {
+		*calls++
+		if *calls <= n {
+			return err
+		}
+		return nil
+	}, calls
+}
+
+func TestDoWithRetrySucceedsAfterRetryableErrors(t *testing.T) {
+	for _, status := range []int32{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		fn, calls := failingTimes(3, &ngrok.Error{StatusCode: status})
+		assert.NoError(t, DoWithRetry(context.Background(), testRetryPolicy, fn), status)
+		assert.Equal(t, 4, *calls, status)
+	}
+}
+
+func TestDoWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	rateLimited := &ngrok.Error{StatusCode: http.StatusTooManyRequests}
+	fn, calls := failingTimes(10, rateLimited)
+	assert.Equal(t, rateLimited, DoWithRetry(context.Background(), testRetryPolicy, fn))
+	assert.Equal(t, 5, *calls)
+}
+
+func TestDoWithRetryReturnsTerminalErrorsImmediately(t *testing.T) {
+	for _, err := range []error{
+		&ngrok.Error{StatusCode: http.StatusBadRequest},
+		&ngrok.Error{StatusCode: http.StatusNotFound},
+		errors.New("invalid domain"),
+	} {
+		fn, calls := failingTimes(1, err)
+		assert.Equal(t, err, DoWithRetry(context.Background(), testRetryPolicy, fn))
+		assert.Equal(t, 1, *calls)
+	}
+}
+
+func TestDoWithRetryIfOnlyRetriesRateLimitedCreates(t *testing.T) {
+	fn, calls := failingTimes(2, &ngrok.Error{StatusCode: http.StatusTooManyRequests})
+	assert.NoError(t, DoWithRetryIf(context.Background(), testRetryPolicy, IsRateLimited, fn))
+	assert.Equal(t, 3, *calls)
+
+	for _, err := range []error{
+		&ngrok.Error{StatusCode: http.StatusInternalServerError},
+		&net.OpError{Op: "read", Err: errors.New("connection reset by peer")},
+	} {
+		fn, calls := failingTimes(1, err)
+		assert.Equal(t, err, DoWithRetryIf(context.Background(), testRetryPolicy, IsRateLimited, fn))
+		assert.Equal(t, 1, *calls)
+	}
+}
+
+func TestDoWithRetryStopsWhenTheContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	unavailable := &ngrok.Error{StatusCode: http.StatusServiceUnavailable}
+	calls := 0
+	err := DoWithRetry(ctx, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}, func() error {
+		calls++
+		cancel()
+		return unavailable
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 800*time.Millisecond, policy.delay(4))
+	assert.Equal(t, time.Second, policy.delay(5))
+	assert.Equal(t, time.Second, policy.delay(50))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(1)
+		assert.True(t, delay > 50*time.Millisecond && delay <= 100*time.Millisecond, delay)
+	}
+}
+
+func TestDoWithRetryWaitsForRetryAfter(t *testing.T) {
+	rateLimited := &ngrok.Error{StatusCode: http.StatusTooManyRequests, Details: map[string]string{retryAfterDetail: "1"}}
+	fn, calls := failingTimes(1, rateLimited)
+	start := time.Now()
+	assert.NoError(t, DoWithRetry(context.Background(), RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}, fn))
+	assert.Equal(t, 2, *calls)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestDoWithRetryReturnsRetryAfterLongerThanMaxDelay(t *testing.T) {
+	rateLimited := &ngrok.Error{StatusCode: http.StatusTooManyRequests, Details: map[string]string{retryAfterDetail: "120"}}
+	fn, calls := failingTimes(1, rateLimited)
+	assert.Equal(t, rateLimited, DoWithRetry(context.Background(), testRetryPolicy, fn))
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, 2*time.Minute, RetryAfter(rateLimited))
+}
+
+func TestRetryAfter(t *testing.T) {
+	at := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	for value, expected := range map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"soon":                          0,
+		"Mon, 02 Jan 2006 15:04:05 GMT": 0,
+	} {
+		err := &ngrok.Error{StatusCode: http.StatusTooManyRequests, Details: map[string]string{retryAfterDetail: value}}
+		assert.Equal(t, expected, RetryAfter(err), value)
+	}
+
+	retryAfter := RetryAfter(&ngrok.Error{StatusCode: http.StatusTooManyRequests, Details: map[string]string{retryAfterDetail: at}})
+	assert.True(t, retryAfter > 59*time.Minute && retryAfter <= time.Hour, retryAfter)
+
+	assert.Zero(t, RetryAfter(&ngrok.Error{StatusCode: http.StatusServiceUnavailable, Details: map[string]string{retryAfterDetail: "30"}}))
+	assert.Zero(t, RetryAfter(errors.New("rate limited")))
+}
+
+func TestRetryAfterTransportKeepsTheRetryAfterHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status_code": 429, "msg": "rate limited", "details": {"operation_id": "op_123"}}`))
+	}))
+	defer server.Close()
+
+	client := reserved_domains.NewClient(ngrok.NewClientConfig("test-api-key",
+		ngrok.WithBaseURL(server.URL),
+		ngrok.WithHTTPClient(&http.Client{Transport: &RetryAfterTransport{}}),
+	))
+	_, err := client.Get(context.Background(), "rd_123")
+
+	var ngrokErr *ngrok.Error
+	assert.ErrorAs(t, err, &ngrokErr)
+	assert.Equal(t, "rate limited", ngrokErr.Msg)
+	assert.Equal(t, "op_123", ngrokErr.OperationID())
+	assert.Equal(t, 30*time.Second, RetryAfter(err))
+}
@@ -16,37 +16,53 @@ package store
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/cache"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // CacheStores stores cache.Store for all Kinds of k8s objects that
 // the Ingress Controller reads.
 type CacheStores struct {
 	// Core Kubernetes Stores
-	IngressV1      cache.Store
-	IngressClassV1 cache.Store
-	ServiceV1      cache.Store
+	IngressV1       cache.Indexer
+	IngressClassV1  cache.Store
+	ServiceV1       cache.Store
+	SecretV1        cache.Store
+	EndpointSliceV1 cache.Indexer
 
 	// Gateway API Stores
-	Gateway      cache.Store
-	GatewayClass cache.Store
-	HTTPRoute    cache.Store
+	Gateway        cache.Store
+	GatewayClass   cache.Store
+	HTTPRoute      cache.Store
+	GRPCRoute      cache.Store
+	ReferenceGrant cache.Store
 
 	// Ngrok Stores
-	DomainV1             cache.Store
-	TunnelV1             cache.Store
-	HTTPSEdgeV1          cache.Store
-	NgrokModuleV1        cache.Store
-	NgrokTrafficPolicyV1 cache.Store
+	DomainV1                  cache.Store
+	TunnelV1                  cache.Store
+	HTTPSEdgeV1               cache.Store
+	TCPEdgeV1                 cache.Store
+	IPPolicyV1                cache.Store
+	NgrokModuleV1             cache.Store
+	NgrokTrafficPolicyV1      cache.Store
+	NgrokIngressClassParamsV1 cache.Store
+
+	// missingSecrets remembers secrets that were recently looked up and not found
+	missingSecrets *expiringKeys
 
 	log logr.Logger
 	l   *sync.RWMutex
@@ -56,22 +72,156 @@ type CacheStores struct {
 func NewCacheStores(logger logr.Logger) CacheStores {
 	return CacheStores{
 		// Core Kubernetes Stores
-		IngressV1:      cache.NewStore(keyFunc),
-		IngressClassV1: cache.NewStore(clusterResourceKeyFunc),
-		ServiceV1:      cache.NewStore(keyFunc),
+		IngressV1:       cache.NewIndexer(keyFunc, cache.Indexers{ingressServiceIndex: ingressServiceIndexFunc, ingressHostIndex: ingressHostIndexFunc}),
+		IngressClassV1:  cache.NewStore(clusterResourceKeyFunc),
+		ServiceV1:       cache.NewStore(keyFunc),
+		SecretV1:        cache.NewStore(keyFunc),
+		EndpointSliceV1: cache.NewIndexer(keyFunc, cache.Indexers{endpointSliceServiceIndex: endpointSliceServiceIndexFunc}),
 		// Gateway API Stores
-		Gateway:      cache.NewStore(keyFunc),
-		GatewayClass: cache.NewStore(keyFunc),
-		HTTPRoute:    cache.NewStore(keyFunc),
+		Gateway:        cache.NewStore(keyFunc),
+		GatewayClass:   cache.NewStore(keyFunc),
+		HTTPRoute:      cache.NewStore(keyFunc),
+		GRPCRoute:      cache.NewStore(keyFunc),
+		ReferenceGrant: cache.NewStore(keyFunc),
 		// Ngrok Stores
-		DomainV1:             cache.NewStore(keyFunc),
-		TunnelV1:             cache.NewStore(keyFunc),
-		HTTPSEdgeV1:          cache.NewStore(keyFunc),
-		NgrokModuleV1:        cache.NewStore(keyFunc),
-		NgrokTrafficPolicyV1: cache.NewStore(keyFunc),
-		l:                    &sync.RWMutex{},
-		log:                  logger,
+		DomainV1:                  cache.NewStore(keyFunc),
+		TunnelV1:                  cache.NewStore(keyFunc),
+		HTTPSEdgeV1:               cache.NewStore(keyFunc),
+		TCPEdgeV1:                 cache.NewStore(keyFunc),
+		IPPolicyV1:                cache.NewStore(keyFunc),
+		NgrokModuleV1:             cache.NewStore(keyFunc),
+		NgrokTrafficPolicyV1:      cache.NewStore(keyFunc),
+		NgrokIngressClassParamsV1: cache.NewStore(keyFunc),
+		missingSecrets:            newExpiringKeys(missingSecretTTL),
+		l:                         &sync.RWMutex{},
+		log:                       logger,
+	}
+}
+
+// ingressServiceIndex indexes ingresses by the "namespace/name" keys of the services they use as backends
+const ingressServiceIndex = "ingress-services"
+
+func ingressServiceIndexFunc(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*netv1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type for ingress service index: %T", obj)
+	}
+
+	var keys []string
+	if ing.Spec.DefaultBackend != nil && ing.Spec.DefaultBackend.Service != nil {
+		keys = append(keys, getKey(ing.Spec.DefaultBackend.Service.Name, ing.Namespace))
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				keys = append(keys, getKey(path.Backend.Service.Name, ing.Namespace))
+			}
+		}
+	}
+	return keys, nil
+}
+
+// ingressHostIndex indexes ingresses by the lowercased hosts of their rules and TLS config. Each host is also
+// indexed by the wildcard domain covering it, so "app.example.com" can be found with "*.example.com".
+const ingressHostIndex = "ingress-hosts"
+
+func ingressHostIndexFunc(obj interface{}) ([]string, error) {
+	ing, ok := obj.(*netv1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type for ingress host index: %T", obj)
+	}
+
+	hosts := []string{}
+	for _, rule := range ing.Spec.Rules {
+		hosts = append(hosts, rule.Host)
+	}
+	for _, tls := range ing.Spec.TLS {
+		hosts = append(hosts, tls.Hosts...)
+	}
+
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+		host = strings.ToLower(host)
+		add(host)
+		if _, parent, found := strings.Cut(host, "."); found && !strings.HasPrefix(host, "*.") {
+			add("*." + parent)
+		}
+	}
+	return keys, nil
+}
+
+// endpointSliceServiceIndex indexes endpoint slices by the "namespace/name" key of the service they belong to
+const endpointSliceServiceIndex = "endpointslice-service"
+
+func endpointSliceServiceIndexFunc(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type for endpoint slice service index: %T", obj)
+	}
+
+	serviceName, ok := slice.Labels[discoveryv1.LabelServiceName]
+	if !ok || serviceName == "" {
+		return nil, nil
+	}
+	return []string{getKey(serviceName, slice.Namespace)}, nil
+}
+
+// missingSecretTTL is how long a secret that wasn't found is remembered as missing
+const missingSecretTTL = 10 * time.Second
+
+// expiringKeys is a set of keys that are forgotten after a ttl
+type expiringKeys struct {
+	ttl  time.Duration
+	now  func() time.Time
+	l    sync.Mutex
+	keys map[string]time.Time
+}
+
+func newExpiringKeys(ttl time.Duration) *expiringKeys {
+	return &expiringKeys{
+		ttl:  ttl,
+		now:  time.Now,
+		keys: make(map[string]time.Time),
+	}
+}
+
+func (e *expiringKeys) add(key string) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	e.keys[key] = e.now().Add(e.ttl)
+}
+
+func (e *expiringKeys) has(key string) bool {
+	e.l.Lock()
+	defer e.l.Unlock()
+	expiresAt, ok := e.keys[key]
+	if !ok {
+		return false
 	}
+	if !e.now().Before(expiresAt) {
+		delete(e.keys, key)
+		return false
+	}
+	return true
+}
+
+func (e *expiringKeys) remove(key string) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	delete(e.keys, key)
 }
 
 func keyFunc(obj interface{}) (string, error) {
@@ -106,12 +256,20 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 		return c.IngressClassV1.Get(obj)
 	case *corev1.Service:
 		return c.ServiceV1.Get(obj)
+	case *corev1.Secret:
+		return c.SecretV1.Get(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSliceV1.Get(obj)
 
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1.HTTPRoute:
 		return c.HTTPRoute.Get(obj)
+	case *gatewayv1alpha2.GRPCRoute:
+		return c.GRPCRoute.Get(obj)
+	case *gatewayv1beta1.ReferenceGrant:
+		return c.ReferenceGrant.Get(obj)
 	case *gatewayv1.Gateway:
 		return c.Gateway.Get(obj)
 	case *gatewayv1.GatewayClass:
@@ -126,21 +284,70 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 		return c.TunnelV1.Get(obj)
 	case *ingressv1alpha1.HTTPSEdge:
 		return c.HTTPSEdgeV1.Get(obj)
+	case *ingressv1alpha1.TCPEdge:
+		return c.TCPEdgeV1.Get(obj)
+	case *ingressv1alpha1.IPPolicy:
+		return c.IPPolicyV1.Get(obj)
 	case *ingressv1alpha1.NgrokModuleSet:
 		return c.NgrokModuleV1.Get(obj)
 	case *ngrokv1alpha1.NgrokTrafficPolicy:
 		return c.NgrokTrafficPolicyV1.Get(obj)
+	case *ingressv1alpha1.NgrokIngressClassParams:
+		return c.NgrokIngressClassParamsV1.Get(obj)
 	default:
 		return nil, false, fmt.Errorf("unsupported object type: %T", obj)
 	}
 }
 
+// list returns the items in one of the stores while holding the read lock, so readers never observe an
+// Add or Delete that is only partially applied across the stores.
+func (c CacheStores) list(store cache.Store) []interface{} {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return store.List()
+}
+
+// getByKey looks up an item by key in one of the stores while holding the read lock.
+func (c CacheStores) getByKey(store cache.Store, key string) (interface{}, bool, error) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return store.GetByKey(key)
+}
+
+// byIndex returns the items matching an indexed value in one of the indexers while holding the read lock.
+func (c CacheStores) byIndex(indexer cache.Indexer, indexName, indexedValue string) ([]interface{}, error) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	return indexer.ByIndex(indexName, indexedValue)
+}
+
 // Add stores a provided runtime.Object into the CacheStore if it's of a supported type.
 // The CacheStore must be initialized (see NewCacheStores()) or this will panic.
 func (c CacheStores) Add(obj runtime.Object) error {
 	c.l.Lock()
 	defer c.l.Unlock()
+	return c.add(obj)
+}
+
+// AddAll stores each of the provided runtime.Objects like Add, but takes the lock once for all of them, so
+// readers don't contend with every object added during warmup. Objects of unsupported types are skipped and
+// the errors for them are returned together as an aggregate once the rest are added.
+// The CacheStore must be initialized (see NewCacheStores()) or this will panic.
+func (c CacheStores) AddAll(objs ...runtime.Object) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	var errs []error
+	for _, obj := range objs {
+		if err := c.add(obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
 
+// add stores the object in the store for its type. The caller must hold the write lock.
+func (c CacheStores) add(obj runtime.Object) error {
 	switch obj := obj.(type) {
 	// ----------------------------------------------------------------------------
 	// Kubernetes Core API Support
@@ -151,12 +358,21 @@ func (c CacheStores) Add(obj runtime.Object) error {
 		return c.IngressClassV1.Add(obj)
 	case *corev1.Service:
 		return c.ServiceV1.Add(obj)
+	case *corev1.Secret:
+		c.missingSecrets.remove(getKey(obj.Name, obj.Namespace))
+		return c.SecretV1.Add(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSliceV1.Add(obj)
 
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1.HTTPRoute:
 		return c.HTTPRoute.Add(obj)
+	case *gatewayv1alpha2.GRPCRoute:
+		return c.GRPCRoute.Add(obj)
+	case *gatewayv1beta1.ReferenceGrant:
+		return c.ReferenceGrant.Add(obj)
 	case *gatewayv1.Gateway:
 		return c.Gateway.Add(obj)
 	case *gatewayv1.GatewayClass:
@@ -171,10 +387,16 @@ func (c CacheStores) Add(obj runtime.Object) error {
 		return c.TunnelV1.Add(obj)
 	case *ingressv1alpha1.HTTPSEdge:
 		return c.HTTPSEdgeV1.Add(obj)
+	case *ingressv1alpha1.TCPEdge:
+		return c.TCPEdgeV1.Add(obj)
+	case *ingressv1alpha1.IPPolicy:
+		return c.IPPolicyV1.Add(obj)
 	case *ingressv1alpha1.NgrokModuleSet:
 		return c.NgrokModuleV1.Add(obj)
 	case *ngrokv1alpha1.NgrokTrafficPolicy:
 		return c.NgrokTrafficPolicyV1.Add(obj)
+	case *ingressv1alpha1.NgrokIngressClassParams:
+		return c.NgrokIngressClassParamsV1.Add(obj)
 
 	default:
 		return fmt.Errorf("unsupported object type: %T", obj)
@@ -197,12 +419,20 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 		return c.IngressClassV1.Delete(obj)
 	case *corev1.Service:
 		return c.ServiceV1.Delete(obj)
+	case *corev1.Secret:
+		return c.SecretV1.Delete(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSliceV1.Delete(obj)
 
 	// ----------------------------------------------------------------------------
 	// Kubernetes Gateway API Support
 	// ----------------------------------------------------------------------------
 	case *gatewayv1.HTTPRoute:
 		return c.HTTPRoute.Delete(obj)
+	case *gatewayv1alpha2.GRPCRoute:
+		return c.GRPCRoute.Delete(obj)
+	case *gatewayv1beta1.ReferenceGrant:
+		return c.ReferenceGrant.Delete(obj)
 	case *gatewayv1.Gateway:
 		return c.Gateway.Delete(obj)
 	case *gatewayv1.GatewayClass:
@@ -217,10 +447,16 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 		return c.TunnelV1.Delete(obj)
 	case *ingressv1alpha1.HTTPSEdge:
 		return c.HTTPSEdgeV1.Delete(obj)
+	case *ingressv1alpha1.TCPEdge:
+		return c.TCPEdgeV1.Delete(obj)
+	case *ingressv1alpha1.IPPolicy:
+		return c.IPPolicyV1.Delete(obj)
 	case *ingressv1alpha1.NgrokModuleSet:
 		return c.NgrokModuleV1.Delete(obj)
 	case *ngrokv1alpha1.NgrokTrafficPolicy:
 		return c.NgrokTrafficPolicyV1.Delete(obj)
+	case *ingressv1alpha1.NgrokIngressClassParams:
+		return c.NgrokIngressClassParamsV1.Delete(obj)
 	default:
 		return fmt.Errorf("unsupported object type: %T", obj)
 	}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"reflect"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+)
+
+// ingressCertificateDescription is the description of the certificates uploaded for the TLS secrets of ingresses
+const ingressCertificateDescription = "Created by kubernetes-ingress-controller"
+
+// ingressTLSSecret returns the name of the secret in the ingress's TLS config with the certificate for the host,
+// or an empty string if the host should be served with a certificate managed by ngrok
+func ingressTLSSecret(ingress *netv1.Ingress, host string) string {
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" && tlsHostsCover(tls.Hosts, host) {
+			return tls.SecretName
+		}
+	}
+	return ""
+}
+
+// calculateNgrokCertificates returns the NgrokCertificates that upload the certificates in the TLS secrets of
+// ingresses and bind them to the reserved domains of their hosts, keyed by domain. A reserved domain can only
+// serve one certificate, so when ingresses use different secrets for a domain the first ingress's secret wins
+// and the others are warned.
+func (d *Driver) calculateNgrokCertificates() map[string]ngrokv1alpha1.NgrokCertificate {
+	certificates := map[string]ngrokv1alpha1.NgrokCertificate{}
+	owners := map[string]*netv1.Ingress{}
+
+	for _, ingress := range d.store.ListNgrokIngressesV1() {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host == "" {
+				continue
+			}
+			secretName := ingressTLSSecret(ingress, rule.Host)
+			if secretName == "" {
+				continue
+			}
+
+			domain := d.ingressEdgeHost(ingress, rule.Host)
+			if existing, ok := certificates[domain]; ok {
+				owner := owners[domain]
+				if existing.Namespace != ingress.Namespace || existing.Spec.SecretName != secretName {
+					d.reportIngressIssue(ingress, events.ReasonTLSConflict,
+						"Host %s already serves the certificate from Secret %s/%s of ingress %s/%s",
+						rule.Host, existing.Namespace, existing.Spec.SecretName, owner.Namespace, owner.Name)
+				}
+				continue
+			}
+
+			certificates[domain] = ngrokv1alpha1.NgrokCertificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      domainResourceName(domain),
+					Namespace: ingress.Namespace,
+					// certificates are labeled like the edges of their domain
+					Labels: d.edgeLabels(domain),
+				},
+				Spec: ngrokv1alpha1.NgrokCertificateSpec{
+					SecretName:  secretName,
+					Domain:      domain,
+					Description: ingressCertificateDescription,
+					Metadata:    d.ingressMetadata,
+				},
+			}
+			owners[domain] = ingress
+		}
+	}
+	return certificates
+}
+
+// applyNgrokCertificates creates and updates the desired certificates, and deletes the ones the controller
+// created for TLS secrets that ingresses no longer use. Deleting a certificate returns its domain to a
+// certificate managed by ngrok.
+func (d *Driver) applyNgrokCertificates(ctx context.Context, c client.Client, desiredCertificates map[string]ngrokv1alpha1.NgrokCertificate, currentCertificates []ngrokv1alpha1.NgrokCertificate) error {
+	for _, currCertificate := range currentCertificates {
+		desiredCertificate, ok := desiredCertificates[currCertificate.Spec.Domain]
+		if ok && desiredCertificate.Name == currCertificate.Name && desiredCertificate.Namespace == currCertificate.Namespace {
+			if !reflect.DeepEqual(desiredCertificate.Spec, currCertificate.Spec) {
+				currCertificate.Spec = desiredCertificate.Spec
+				if err := c.Update(ctx, &currCertificate); err != nil {
+					d.log.Error(err, "error updating certificate", "certificate", desiredCertificate)
+					return err
+				}
+			}
+
+			// matched and updated the certificate, no longer desired
+			delete(desiredCertificates, currCertificate.Spec.Domain)
+			continue
+		}
+
+		if err := c.Delete(ctx, &currCertificate); client.IgnoreNotFound(err) != nil {
+			d.log.Error(err, "error deleting certificate", "certificate", currCertificate)
+			return err
+		}
+	}
+
+	// the set of desired certificates now only contains new certificates, create them
+	for _, certificate := range desiredCertificates {
+		if err := c.Create(ctx, &certificate); err != nil {
+			d.log.Error(err, "error creating certificate", "certificate", certificate)
+			return err
+		}
+	}
+	return nil
+}
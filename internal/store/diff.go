@@ -0,0 +1,104 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+// FieldChange is a field that differs between two versions of an object. Old is nil for a field that was
+// added and New is nil for a field that was removed.
+type FieldChange struct {
+	// Path is the dot separated path to the field using its json names, e.g. modules.compression.enabled
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// String describes the change for logging
+func (c FieldChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("%s: added %v", c.Path, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("%s: removed %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// Diff returns the changes between the modules of two versions of a module set, sorted by path, so what would
+// change in ngrok can be logged or checked before it's applied. Either module set may be nil, in which case
+// all of the other's modules are reported as added or removed. Lists are compared as a whole, while the
+// fields of modules and the keys of maps are compared individually.
+func Diff(old, new *ingressv1alpha1.NgrokModuleSet) ([]FieldChange, error) {
+	oldFields, err := moduleFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := moduleFields(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for path, oldValue := range oldFields {
+		newValue, ok := newFields[path]
+		if !ok {
+			changes = append(changes, FieldChange{Path: path, Old: oldValue})
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, newValue := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// moduleFields flattens the modules of the module set into their values keyed by path. Fields that are unset
+// are left out, the same as when the modules are serialized.
+func moduleFields(ms *ingressv1alpha1.NgrokModuleSet) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if ms == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(ms.Modules)
+	if err != nil {
+		return nil, fmt.Errorf("serializing modules of NgrokModuleSet %s: %w", ms.Name, err)
+	}
+	var modules map[string]interface{}
+	if err := json.Unmarshal(raw, &modules); err != nil {
+		return nil, fmt.Errorf("deserializing modules of NgrokModuleSet %s: %w", ms.Name, err)
+	}
+
+	for name, module := range modules {
+		flattenFields("modules."+name, module, fields)
+	}
+	return fields, nil
+}
+
+// flattenFields adds the leaf values of the value to fields. An empty object is kept as a value of its own, so
+// that enabling a module without any settings is still a change.
+func flattenFields(prefix string, value interface{}, fields map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok || len(object) == 0 {
+		fields[prefix] = value
+		return
+	}
+	for key, v := range object {
+		flattenFields(prefix+"."+key, v, fields)
+	}
+}
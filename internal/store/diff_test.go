@@ -0,0 +1,88 @@
+package store
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+var _ = Describe("Diff", func() {
+	It("Should report no changes for equal module sets", func() {
+		old := NewTestNgrokModuleSet("modules", "test", false)
+		old.Modules.Headers = NewTestNgrokModuleSetModules().Headers
+		new := old.DeepCopy()
+		new.ResourceVersion = "2"
+
+		changes, err := Diff(&old, new)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("Should report added, removed, and modified fields across modules sorted by path", func() {
+		old := NewTestNgrokModuleSet("modules", "test", true)
+		old.Modules.Headers = NewTestNgrokModuleSetModules().Headers
+		old.Modules.RateLimit = &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 10, Burst: 5}
+
+		new := NewTestNgrokModuleSet("modules", "test", false)
+		new.Modules.Headers = NewTestNgrokModuleSetModules().Headers
+		new.Modules.Compression = nil
+		new.Modules.RateLimit = &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 20, Burst: 5}
+		new.Modules.Headers.Request.Add["X-Forwarded-Port"] = "443"
+		delete(new.Modules.Headers.Request.Add, "X-Forwarded-Host")
+		new.Modules.Headers.Response.Remove = []string{"Server"}
+		new.Modules.IPRestriction = &ingressv1alpha1.EndpointIPPolicy{IPPolicies: []string{"office"}}
+
+		changes, err := Diff(&old, &new)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(Equal([]FieldChange{
+			{Path: "modules.compression.enabled", Old: true},
+			{Path: "modules.headers.request.add.X-Forwarded-Host", Old: "example.com"},
+			{Path: "modules.headers.request.add.X-Forwarded-Port", New: "443"},
+			{Path: "modules.headers.response.remove", Old: []interface{}{"Server", "X-Powered-By"}, New: []interface{}{"Server"}},
+			{Path: "modules.ipRestriction.policies", New: []interface{}{"office"}},
+			{Path: "modules.rateLimit.requestsPerSecond", Old: float64(10), New: float64(20)},
+		}))
+	})
+
+	It("Should report a module enabled without any settings", func() {
+		old := NewTestNgrokModuleSet("modules", "test", true)
+		new := NewTestNgrokModuleSet("modules", "test", true)
+		new.Modules.Tracing = &ingressv1alpha1.EndpointTracing{}
+
+		changes, err := Diff(&old, &new)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(Equal([]FieldChange{
+			{Path: "modules.tracing", New: map[string]interface{}{}},
+		}))
+	})
+
+	It("Should handle nil module sets", func() {
+		ms := NewTestNgrokModuleSet("modules", "test", false)
+		ms.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+
+		added, err := Diff(nil, &ms)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(added).ToNot(BeEmpty())
+		for _, change := range added {
+			Expect(change.Old).To(BeNil())
+		}
+
+		removed, err := Diff(&ms, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(removed).To(HaveLen(len(added)))
+		for _, change := range removed {
+			Expect(change.New).To(BeNil())
+		}
+
+		none, err := Diff(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(none).To(BeEmpty())
+	})
+
+	It("Should describe changes for logging", func() {
+		Expect(FieldChange{Path: "modules.compression.enabled", Old: false, New: true}.String()).To(Equal("modules.compression.enabled: false -> true"))
+		Expect(FieldChange{Path: "modules.tracing", New: map[string]interface{}{}}.String()).To(Equal("modules.tracing: added map[]"))
+		Expect(FieldChange{Path: "modules.compression.enabled", Old: true}.String()).To(Equal("modules.compression.enabled: removed true"))
+	})
+})
@@ -5,26 +5,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 
 	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -40,12 +51,20 @@ const (
 	labelPort                = "k8s.ngrok.com/port"
 )
 
+const (
+	// BackendMissingBehaviorServe503 keeps the routes of a missing backend service on the edge and responds with a 503
+	BackendMissingBehaviorServe503 = "serve-503"
+	// BackendMissingBehaviorTeardown removes the routes of a missing backend service, and the edge once it has no routes left
+	BackendMissingBehaviorTeardown = "teardown"
+)
+
 // Driver maintains the store of information, can derive new information from the store, and can
 // synchronize the desired state of the store to the actual state of the cluster.
 type Driver struct {
 	store Storer
 
 	cacheStores     CacheStores
+	controllerName  string
 	log             logr.Logger
 	scheme          *runtime.Scheme
 	ingressMetadata string
@@ -58,7 +77,16 @@ type Driver struct {
 	syncPartialCh       chan error
 	syncAllowConcurrent bool
 
-	gatewayEnabled bool
+	gatewayEnabled           bool
+	backendMissingBehavior   string
+	verifyUpstreamTLS        bool
+	backendKeepAliveInterval time.Duration
+	recorder                 record.EventRecorder
+	ingressEvents            *ingressEvents
+	storeOptions             []Option
+	watchNamespaces          []string
+
+	reconciled *reconciledHashes
 }
 
 // NewDriver creates a new driver with a basic logger and cache store setup
@@ -68,13 +96,96 @@ func NewDriver(logger logr.Logger, scheme *runtime.Scheme, controllerName string
 	return &Driver{
 		store:          s,
 		cacheStores:    cacheStores,
+		controllerName: controllerName,
 		log:            logger,
 		scheme:         scheme,
 		managerName:    managerName,
 		gatewayEnabled: gatewayEnabled,
+
+		backendMissingBehavior: BackendMissingBehaviorServe503,
+		reconciled:             newReconciledHashes(),
 	}
 }
 
+// WithBackendMissingBehavior sets what happens to an ingress's edge when its backend service is deleted.
+// Individual ingresses can override this with the k8s.ngrok.com/backend-missing-behavior annotation.
+func (d *Driver) WithBackendMissingBehavior(behavior string) *Driver {
+	d.backendMissingBehavior = behavior
+	return d
+}
+
+// WithUpstreamTLSVerification sets whether tunnels verify the certificates of HTTPS backends. Without this
+// they skip verification. Individual services can opt out with the k8s.ngrok.com/upstream-tls-skip-verify annotation.
+func (d *Driver) WithUpstreamTLSVerification(verify bool) *Driver {
+	d.verifyUpstreamTLS = verify
+	return d
+}
+
+// WithBackendKeepAliveInterval sets how often keepalive probes are sent on connections to backend services.
+// Individual services can override this with the k8s.ngrok.com/backend-keepalive-interval annotation.
+func (d *Driver) WithBackendKeepAliveInterval(interval time.Duration) *Driver {
+	d.backendKeepAliveInterval = interval
+	return d
+}
+
+// WithEventRecorder sets the recorder used to emit events on ingresses for the results of translating them, and
+// for RecordIngressHandled
+func (d *Driver) WithEventRecorder(recorder record.EventRecorder) *Driver {
+	d.recorder = recorder
+	d.ingressEvents = newIngressEvents(recorder)
+	return d
+}
+
+// WithWatchNamespaces limits the driver's store to objects in the namespaces. See WithWatchNamespaces for the
+// store. Without any namespaces, objects in all namespaces are handled.
+func (d *Driver) WithWatchNamespaces(namespaces ...string) *Driver {
+	d.watchNamespaces = namespaces
+	d.withStoreOptions(WithWatchNamespaces(namespaces...))
+	return d
+}
+
+// WithCacheSynced makes the driver's store report the sync status of the informers feeding it. Syncing waits
+// for them to sync, so the resources of objects the store hasn't seen yet aren't torn down.
+func (d *Driver) WithCacheSynced(synced ...cache.InformerSynced) *Driver {
+	d.withStoreOptions(WithCacheSynced(synced...))
+	return d
+}
+
+// withStoreOptions recreates the driver's store with the options added to those already applied. The new
+// store shares the cache stores of the old one, so nothing already in the store is lost.
+func (d *Driver) withStoreOptions(opts ...Option) {
+	d.storeOptions = append(d.storeOptions, opts...)
+	d.store = New(d.cacheStores, d.controllerName, d.log, d.storeOptions...)
+}
+
+// RecordIngressHandled emits an event on the ingress for the result of UpdateIngress checking whether the
+// controller handles it: accepted, or dropped for using another controller's ingress class. Each result is only
+// recorded once per version of the ingress.
+func (d *Driver) RecordIngressHandled(ing *netv1.Ingress, handleErr error) {
+	d.ingressEvents.record(ing, handleErr)
+}
+
+// RecordIngressIssues emits a Warning event on the ingresses for each issue the last sync found translating them,
+// like a route conflict or a missing backend, that wasn't found when the issues were last recorded. Each issue
+// is recorded once when it appears rather than on every sync.
+func (d *Driver) RecordIngressIssues() {
+	d.ingressEvents.recordIssues()
+}
+
+// reportIngressIssue adds an issue the sync in progress found translating the ingress, which is recorded by
+// RecordIngressIssues
+func (d *Driver) reportIngressIssue(ing *netv1.Ingress, reason, messageFmt string, args ...interface{}) {
+	d.ingressEvents.report(ing, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// recordIngressEvent emits an event on the ingress if the driver has an event recorder
+func (d *Driver) recordIngressEvent(ing *netv1.Ingress, eventType, reason, messageFmt string, args ...interface{}) {
+	if d.recorder == nil {
+		return
+	}
+	d.recorder.Eventf(ing, eventType, reason, messageFmt, args...)
+}
+
 // WithMetaData allows you to pass in custom metadata to be added to all resources created by the controller
 func (d *Driver) WithMetaData(customMetadata map[string]string) *Driver {
 	ingressMetadata, err := d.setMetadataOwner("kubernetes-ingress-controller", customMetadata)
@@ -119,95 +230,81 @@ func (d *Driver) setMetadataOwner(owner string, customMetadata map[string]string
 // - IngressClasses
 // - Gateways
 // - HTTPRoutes
+// - GRPCRoutes
 // - Services
 // - Secrets
 // - Domains
 // - Edges
-// When the sync method becomes a background process, this likely won't be needed anymore
+// - IPPolicies
+// When the sync method becomes a background process, this likely won't be needed anymore.
+// Namespaced objects are only listed in the watched namespaces, since the controller may not be allowed to list
+// them in any others.
 func (d *Driver) Seed(ctx context.Context, c client.Reader) error {
-	ingresses := &netv1.IngressList{}
-	if err := c.List(ctx, ingresses); err != nil {
+	objs, err := listObjects(ctx, c, []client.ObjectList{&netv1.IngressClassList{}})
+	if err != nil {
 		return err
 	}
-	for _, ing := range ingresses.Items {
-		if err := d.store.Update(&ing); err != nil {
-			return err
-		}
-	}
 
-	ingressClasses := &netv1.IngressClassList{}
-	if err := c.List(ctx, ingressClasses); err != nil {
-		return err
-	}
-	for _, ingClass := range ingressClasses.Items {
-		if err := d.store.Update(&ingClass); err != nil {
-			return err
-		}
+	namespaces := d.watchNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
 	}
-
-	if d.gatewayEnabled {
-		gateways := &gatewayv1.GatewayList{}
-		if err := c.List(ctx, gateways); err != nil {
-			return err
-		}
-		for _, gtw := range gateways.Items {
-			if err := d.store.Update(&gtw); err != nil {
-				return err
-			}
+	for _, ns := range namespaces {
+		lists := []client.ObjectList{&netv1.IngressList{}}
+		if d.gatewayEnabled {
+			lists = append(lists,
+				&gatewayv1.GatewayList{},
+				&gatewayv1.HTTPRouteList{},
+				&gatewayv1alpha2.GRPCRouteList{},
+				&gatewayv1beta1.ReferenceGrantList{},
+			)
 		}
+		lists = append(lists,
+			&corev1.ServiceList{},
+			&ingressv1alpha1.DomainList{},
+			&ingressv1alpha1.HTTPSEdgeList{},
+			&ingressv1alpha1.TCPEdgeList{},
+			&ingressv1alpha1.TunnelList{},
+			&ingressv1alpha1.IPPolicyList{},
+		)
 
-		httproutes := &gatewayv1.HTTPRouteList{}
-		if err := c.List(ctx, httproutes); err != nil {
+		nsObjs, err := listObjects(ctx, c, lists, client.InNamespace(ns))
+		if err != nil {
 			return err
 		}
-		for _, httproute := range httproutes.Items {
-			if err := d.store.Update(&httproute); err != nil {
-				return err
-			}
-		}
+		objs = append(objs, nsObjs...)
 	}
 
-	services := &corev1.ServiceList{}
-	if err := c.List(ctx, services); err != nil {
-		return err
-	}
-	for _, svc := range services.Items {
-		if err := d.store.Update(&svc); err != nil {
-			return err
-		}
-	}
+	// the listed objects are added to the store at once, so readers don't wait on the lock for each of them
+	return d.store.AddAll(objs...)
+}
 
-	domains := &ingressv1alpha1.DomainList{}
-	if err := c.List(ctx, domains); err != nil {
-		return err
-	}
-	for _, domain := range domains.Items {
-		if err := d.store.Update(&domain); err != nil {
-			return err
-		}
+// waitForCacheSync blocks until the store has synced, returning an error if the context is done first
+func (d *Driver) waitForCacheSync(ctx context.Context) error {
+	if !d.store.WaitForCacheSync(ctx) {
+		return fmt.Errorf("waiting for the store to sync: %w", ctx.Err())
 	}
+	return nil
+}
 
-	edges := &ingressv1alpha1.HTTPSEdgeList{}
-	if err := c.List(ctx, edges); err != nil {
-		return err
-	}
-	for _, edge := range edges.Items {
-		if err := d.store.Update(&edge); err != nil {
-			return err
+// listObjects lists the objects of each of the lists
+func listObjects(ctx context.Context, c client.Reader, lists []client.ObjectList, opts ...client.ListOption) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	for _, list := range lists {
+		if err := c.List(ctx, list, opts...); err != nil {
+			// GRPCRoutes are only in the experimental channel of the Gateway API CRDs, which may not be installed
+			if _, ok := list.(*gatewayv1alpha2.GRPCRouteList); ok && meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
 		}
-	}
-
-	tunnels := &ingressv1alpha1.TunnelList{}
-	if err := c.List(ctx, tunnels); err != nil {
-		return err
-	}
-	for _, tunnel := range tunnels.Items {
-		if err := d.store.Update(&tunnel); err != nil {
-			return err
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
 		}
+		objs = append(objs, items...)
 	}
-
-	return nil
+	return objs, nil
 }
 
 func (d *Driver) PrintState(setupLog logr.Logger) {
@@ -231,28 +328,129 @@ func (d *Driver) PrintState(setupLog logr.Logger) {
 	}
 }
 
+// ResourceCounts is a summary of the resources the driver is currently tracking in its store
+type ResourceCounts struct {
+	Ingresses  int
+	HTTPSEdges int
+	Domains    int
+	Tunnels    int
+}
+
+// Counts returns the number of ngrok ingresses, edges, domains, and tunnels currently in the store
+func (d *Driver) Counts() ResourceCounts {
+	return ResourceCounts{
+		Ingresses:  len(d.store.ListNgrokIngressesV1()),
+		HTTPSEdges: len(d.store.ListHTTPSEdgesV1()),
+		Domains:    len(d.store.ListDomainsV1()),
+		Tunnels:    len(d.store.ListTunnelsV1()),
+	}
+}
+
+// ListNgrokIngressesForService returns the ngrok ingresses that use the service as a backend. See the
+// store's ListNgrokIngressesForService.
+func (d *Driver) ListNgrokIngressesForService(name, namespace string) []*netv1.Ingress {
+	return d.store.ListNgrokIngressesForService(name, namespace)
+}
+
+// GetSecretV1 looks up a secret in the store, falling back to the API for secrets that haven't made it
+// into the store yet. Secrets that aren't found in the API are remembered as missing for a short time so
+// repeated lookups for them don't hit the API, until the secret is added to the store.
+func (d *Driver) GetSecretV1(ctx context.Context, c client.Reader, name, namespace string) (*corev1.Secret, error) {
+	secret, err := d.store.GetSecretV1(name, namespace)
+	if err == nil {
+		return secret, nil
+	}
+	if !errors.IsErrorNotFound(err) {
+		return nil, err
+	}
+
+	key := getKey(name, namespace)
+	if d.cacheStores.missingSecrets.has(key) {
+		return nil, errors.NewNotFoundError(corev1.SchemeGroupVersion.WithKind("Secret"), name, namespace)
+	}
+
+	secret = &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			d.cacheStores.missingSecrets.add(key)
+			return nil, errors.NewNotFoundError(corev1.SchemeGroupVersion.WithKind("Secret"), name, namespace)
+		}
+		return nil, err
+	}
+
+	if _, err := d.store.Update(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// UpdateIngress updates the ingress in the store and returns a copy of it that is safe for the caller
+// to modify, e.g. to sync its finalizers, without racing with other readers of the store.
+// IsSecretReferenced returns true if the secret is one the store keeps: a secret referenced by a module set in the
+// store, or one that was already looked up with GetSecretV1. The secret watch uses it so the rest aren't stored.
+func (d *Driver) IsSecretReferenced(name, namespace string) bool {
+	if _, err := d.store.GetSecretV1(name, namespace); err == nil {
+		return true
+	}
+	for _, ms := range d.store.ListNgrokModuleSetsV1() {
+		if ms.Namespace == namespace && slices.Contains(moduleSetSecretNames(ms), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadModuleSetSecrets looks up the secrets referenced by the module sets in the store. The secret watch only
+// stores the secrets that are referenced when they change, so this adds the ones that existed before a module set
+// referenced them.
+func (d *Driver) loadModuleSetSecrets(ctx context.Context, c client.Reader) error {
+	for _, ms := range d.store.ListNgrokModuleSetsV1() {
+		for _, name := range moduleSetSecretNames(ms) {
+			if _, err := d.GetSecretV1(ctx, c, name, ms.Namespace); err != nil && !errors.IsErrorNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (d *Driver) UpdateIngress(ingress *netv1.Ingress) (*netv1.Ingress, error) {
-	if err := d.store.Update(ingress); err != nil {
+	if _, err := d.store.Update(ingress); err != nil {
 		return nil, err
 	}
-	return d.store.GetNgrokIngressV1(ingress.Name, ingress.Namespace)
+	ing, err := d.store.GetNgrokIngressV1(ingress.Name, ingress.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return ing.DeepCopy(), nil
 }
 
+// UpdateGateway updates the gateway in the store and returns a copy of it that is safe for the caller to modify.
 func (d *Driver) UpdateGateway(gateway *gatewayv1.Gateway) (*gatewayv1.Gateway, error) {
-	if err := d.store.Update(gateway); err != nil {
+	if _, err := d.store.Update(gateway); err != nil {
+		return nil, err
+	}
+	gw, err := d.store.GetGateway(gateway.Name, gateway.Namespace)
+	if err != nil {
 		return nil, err
 	}
-	return d.store.GetGateway(gateway.Name, gateway.Namespace)
+	return gw.DeepCopy(), nil
 }
 
+// UpdateHTTPRoute updates the HTTPRoute in the store and returns a copy of it that is safe for the caller to modify.
 func (d *Driver) UpdateHTTPRoute(httproute *gatewayv1.HTTPRoute) (*gatewayv1.HTTPRoute, error) {
-	if err := d.store.Update(httproute); err != nil {
+	if _, err := d.store.Update(httproute); err != nil {
 		return nil, err
 	}
-	return d.store.GetHTTPRoute(httproute.Name, httproute.Namespace)
+	route, err := d.store.GetHTTPRouteV1(httproute.Name, httproute.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return route.DeepCopy(), nil
 }
 
 func (d *Driver) DeleteIngress(ingress *netv1.Ingress) error {
+	d.ingressEvents.forget(ingress)
 	return d.store.Delete(ingress)
 }
 
@@ -264,6 +462,22 @@ func (d *Driver) DeleteHTTPRoute(httproute *gatewayv1.HTTPRoute) error {
 	return d.store.Delete(httproute)
 }
 
+// UpdateGRPCRoute updates the GRPCRoute in the store and returns a copy of it that is safe for the caller to modify.
+func (d *Driver) UpdateGRPCRoute(grpcroute *gatewayv1alpha2.GRPCRoute) (*gatewayv1alpha2.GRPCRoute, error) {
+	if _, err := d.store.Update(grpcroute); err != nil {
+		return nil, err
+	}
+	route, err := d.store.GetGRPCRouteV1Alpha2(grpcroute.Name, grpcroute.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return route.DeepCopy(), nil
+}
+
+func (d *Driver) DeleteGRPCRoute(grpcroute *gatewayv1alpha2.GRPCRoute) error {
+	return d.store.Delete(grpcroute)
+}
+
 // Delete an ingress object given the NamespacedName
 // Takes a namespacedName string as a parameter and
 // deletes the ingress object from the cacheStores map
@@ -272,6 +486,8 @@ func (d *Driver) DeleteNamedIngress(n types.NamespacedName) error {
 	// set NamespacedName on the ingress object
 	ingress.SetNamespace(n.Namespace)
 	ingress.SetName(n.Name)
+	d.reconciled.forget(n)
+	d.ingressEvents.forget(ingress)
 	return d.cacheStores.Delete(ingress)
 }
 
@@ -291,6 +507,13 @@ func (d *Driver) DeleteNamedHTTPRoute(n types.NamespacedName) error {
 	return d.cacheStores.Delete(httproute)
 }
 
+func (d *Driver) DeleteNamedGRPCRoute(n types.NamespacedName) error {
+	grpcroute := &gatewayv1alpha2.GRPCRoute{}
+	grpcroute.SetNamespace(n.Namespace)
+	grpcroute.SetName(n.Name)
+	return d.cacheStores.Delete(grpcroute)
+}
+
 // syncStart will:
 //   - let the first caller proceed, indicated by returning true
 //   - while the first one is running any subsequent calls will be batched to the last call
@@ -365,6 +588,9 @@ func (d *Driver) syncDone() {
 // Sync calculates what the desired state for each of our CRDs should be based on the ingresses and other
 // objects in the store. It then compares that to the actual state of the cluster and updates the cluster
 func (d *Driver) Sync(ctx context.Context, c client.Client) error {
+	if err := d.waitForCacheSync(ctx); err != nil {
+		return err
+	}
 	// This function gets called a lot in the current architecture. At the end it also syncs
 	// resources which in turn triggers more reconcile events. Its all eventually consistent, but
 	// its noisy and can make us hit ngrok api limits. We should probably just change this to be
@@ -379,13 +605,20 @@ func (d *Driver) Sync(ctx context.Context, c client.Client) error {
 	}
 
 	d.log.Info("syncing driver state!!")
+	if err := d.loadModuleSetSecrets(ctx, c); err != nil {
+		return err
+	}
+	d.ingressEvents.beginSync()
 	desiredDomains, desiredIngressDomains, desiredGatewayDomainMap := d.calculateDomains()
-	desiredEdges := d.calculateHTTPSEdges(&desiredIngressDomains, desiredGatewayDomainMap)
+	desiredEdges := d.withoutUnreservedIngressEdges(d.calculateHTTPSEdges(&desiredIngressDomains, desiredGatewayDomainMap), desiredIngressDomains)
 	desiredTunnels := d.calculateTunnels()
+	desiredCertificates := d.calculateNgrokCertificates()
+	d.ingressEvents.completeSync()
 
 	currDomains := &ingressv1alpha1.DomainList{}
 	currEdges := &ingressv1alpha1.HTTPSEdgeList{}
 	currTunnels := &ingressv1alpha1.TunnelList{}
+	currCertificates := &ngrokv1alpha1.NgrokCertificateList{}
 
 	if err := c.List(ctx, currDomains); err != nil {
 		d.log.Error(err, "error listing domains")
@@ -405,11 +638,22 @@ func (d *Driver) Sync(ctx context.Context, c client.Client) error {
 		d.log.Error(err, "error listing tunnels")
 		return err
 	}
+	if err := c.List(ctx, currCertificates, client.MatchingLabels{
+		labelControllerNamespace: d.managerName.Namespace,
+		labelControllerName:      d.managerName.Name,
+	}); err != nil {
+		d.log.Error(err, "error listing certificates")
+		return err
+	}
 
 	if err := d.applyDomains(ctx, c, desiredDomains, currDomains.Items); err != nil {
 		return err
 	}
 
+	if err := d.applyNgrokCertificates(ctx, c, desiredCertificates, currCertificates.Items); err != nil {
+		return err
+	}
+
 	if err := d.applyHTTPSEdges(ctx, c, desiredEdges, currEdges.Items); err != nil {
 		return err
 	}
@@ -422,20 +666,23 @@ func (d *Driver) Sync(ctx context.Context, c client.Client) error {
 		return err
 	}
 
-	// UpdateGatewayStatuses
-	//if err := d.updateGatewayStatuses(ctx, c); err != nil {
-	//	return err
-	//}
+	if d.gatewayEnabled {
+		if err := d.updateGatewayStatuses(ctx, c); err != nil {
+			return err
+		}
 
-	// UpdateHTTPRouteStatuses
-	//if err := d.updateHTTPRouteStatuses(ctx, c); err != nil {
-	//	return err
-	//}
+		if err := d.updateHTTPRouteStatuses(ctx, c); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (d *Driver) SyncEdges(ctx context.Context, c client.Client) error {
+	if err := d.waitForCacheSync(ctx); err != nil {
+		return err
+	}
 	if !d.syncAllowConcurrent {
 		if proceed, wait := d.syncStart(true); proceed {
 			defer d.syncDone()
@@ -445,9 +692,12 @@ func (d *Driver) SyncEdges(ctx context.Context, c client.Client) error {
 	}
 
 	d.log.Info("syncing edges state!!")
+	if err := d.loadModuleSetSecrets(ctx, c); err != nil {
+		return err
+	}
 	_, desiredIngressDomains, desiredGatewayDomainMap := d.calculateDomains()
 
-	desiredEdges := d.calculateHTTPSEdges(&desiredIngressDomains, desiredGatewayDomainMap)
+	desiredEdges := d.withoutUnreservedIngressEdges(d.calculateHTTPSEdges(&desiredIngressDomains, desiredGatewayDomainMap), desiredIngressDomains)
 	currEdges := &ingressv1alpha1.HTTPSEdgeList{}
 	if err := c.List(ctx, currEdges, client.MatchingLabels{
 		labelControllerNamespace: d.managerName.Namespace,
@@ -464,14 +714,29 @@ func (d *Driver) SyncEdges(ctx context.Context, c client.Client) error {
 	return nil
 }
 
+// withoutUnreservedIngressEdges removes the edges of the ingress domains that aren't reserved yet from the
+// desired edges. ngrok can't serve an edge for a domain that isn't reserved, so its edge is created once it is,
+// and the ingresses with the domain are requeued until then, see CheckIngressDomainsReady.
+func (d *Driver) withoutUnreservedIngressEdges(edges map[string]ingressv1alpha1.HTTPSEdge, ingressDomains []ingressv1alpha1.Domain) map[string]ingressv1alpha1.HTTPSEdge {
+	for _, domain := range ingressDomains {
+		if _, ok := edges[domain.Spec.Domain]; ok && !d.domainReserved(domain) {
+			d.log.V(1).Info("domain is not ready yet, skipping its edge", "domain", domain.Spec.Domain)
+			delete(edges, domain.Spec.Domain)
+		}
+	}
+	return edges
+}
+
 func (d *Driver) applyDomains(ctx context.Context, c client.Client, desiredDomains, currentDomains []ingressv1alpha1.Domain) error {
 	for _, desiredDomain := range desiredDomains {
 		found := false
 		for _, currDomain := range currentDomains {
 			if desiredDomain.Name == currDomain.Name && desiredDomain.Namespace == currDomain.Namespace {
-				// It matches so lets update it if anything is different
-				if !reflect.DeepEqual(desiredDomain.Spec, currDomain.Spec) {
-					currDomain.Spec = desiredDomain.Spec
+				// It matches so lets update it if anything is different. Only the domain and metadata come from
+				// the ingresses and gateways, the rest of the spec is left to users.
+				if desiredDomain.Spec.Domain != currDomain.Spec.Domain || desiredDomain.Spec.Metadata != currDomain.Spec.Metadata {
+					currDomain.Spec.Domain = desiredDomain.Spec.Domain
+					currDomain.Spec.Metadata = desiredDomain.Spec.Metadata
 					if err := c.Update(ctx, &currDomain); err != nil {
 						d.log.Error(err, "error updating domain", "domain", desiredDomain)
 						return err
@@ -495,9 +760,15 @@ func (d *Driver) applyDomains(ctx context.Context, c client.Client, desiredDomai
 }
 
 func (d *Driver) applyHTTPSEdges(ctx context.Context, c client.Client, desiredEdges map[string]ingressv1alpha1.HTTPSEdge, currentEdges []ingressv1alpha1.HTTPSEdge) error {
+	// the domain label holds a label safe version of the domain, map it back to the desired edge's domain
+	labelDomains := make(map[string]string, len(desiredEdges))
+	for domain := range desiredEdges {
+		labelDomains[domainLabelValue(domain)] = domain
+	}
+
 	// update or delete edge we don't need anymore
 	for _, currEdge := range currentEdges {
-		domain := currEdge.Labels[labelDomain]
+		domain := labelDomains[currEdge.Labels[labelDomain]]
 
 		if desiredEdge, ok := desiredEdges[domain]; ok {
 			needsUpdate := false
@@ -525,11 +796,15 @@ func (d *Driver) applyHTTPSEdges(ctx context.Context, c client.Client, desiredEd
 	}
 
 	// the set of desired edges now only contains new edges, create them
-	for _, edge := range desiredEdges {
+	for domain, edge := range desiredEdges {
 		if err := c.Create(ctx, &edge); err != nil {
 			d.log.Error(err, "error creating edge", "edge", edge)
 			return err
 		}
+
+		for _, ing := range d.ingressesForEdgeHost(domain) {
+			d.recordIngressEvent(ing, corev1.EventTypeNormal, events.ReasonEdgeCreated, "Created HTTPSEdge %s for %s", edge.Name, domain)
+		}
 	}
 
 	return nil
@@ -591,16 +866,77 @@ func (d *Driver) updateIngressStatuses(ctx context.Context, c client.Client) err
 	for _, ingress := range ingresses {
 		newLBIPStatus := d.calculateIngressLoadBalancerIPStatus(ingress, c)
 		if !reflect.DeepEqual(ingress.Status.LoadBalancer.Ingress, newLBIPStatus) {
+			// The ingress is shared with the store's other readers, so update a copy of it
+			ingress = ingress.DeepCopy()
 			ingress.Status.LoadBalancer.Ingress = newLBIPStatus
 			if err := c.Status().Update(ctx, ingress); err != nil {
 				d.log.Error(err, "error updating ingress status", "ingress", ingress)
 				return err
 			}
+			// Keep the store's copy at the resource version the update returned
+			if _, err := d.store.Update(ingress); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// CheckIngressDomainsReady returns a NotAllDomainsReadyYetError if the domain of any of the ingress's rule hosts
+// hasn't been reserved yet. Edges for a host aren't created until its domain is reserved, see
+// withoutUnreservedIngressEdges, so callers should requeue the ingress until this returns nil.
+func (d *Driver) CheckIngressDomainsReady(ingress *netv1.Ingress) error {
+	desiredDomains := d.calculateDomainsFromIngress()
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+
+		host := d.ingressEdgeHost(ingress, rule.Host)
+		domain, ok := desiredDomains[host]
+		if !ok || !d.domainReserved(domain) {
+			d.log.V(1).Info("domain is not ready yet", "domain", host)
+			return errors.NewNotAllDomainsReadyYetError()
+		}
+	}
+	return nil
+}
+
+// domainReserved returns true if the Domain the controller creates for a host, which is in the namespace of an
+// ingress with the host, has been reserved. Domains for the host that the controller didn't create, like ones
+// in other namespaces, aren't looked at, since they could be removed or belong to another controller.
+func (d *Driver) domainReserved(desired ingressv1alpha1.Domain) bool {
+	domain, err := d.store.GetDomainV1(desired.Name, desired.Namespace)
+	if err != nil {
+		return false
+	}
+	return domain.Spec.Domain == desired.Spec.Domain && domain.Status.ID != ""
+}
+
+// ingressTLSConflicts returns the hosts of the ingress that request ngrok managed TLS while their reserved
+// domain uses a manually uploaded certificate. An ingress requests ngrok managed TLS for the hosts in its TLS
+// config that don't have a secret with their own certificate. Domains that aren't reserved yet don't conflict.
+func (d *Driver) ingressTLSConflicts(ingress *netv1.Ingress) []string {
+	conflicts := []string{}
+	domains := d.store.ListDomainsV1()
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" {
+			continue
+		}
+
+		for _, host := range tls.Hosts {
+			edgeHost := d.ingressEdgeHost(ingress, host)
+			for _, domain := range domains {
+				if domain.Spec.Domain == edgeHost && domain.HasManualCertificate() {
+					conflicts = append(conflicts, host)
+					break
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
 func (d *Driver) calculateDomains() ([]ingressv1alpha1.Domain, []ingressv1alpha1.Domain, map[string]ingressv1alpha1.Domain) {
 	var domains, ingressDomains []ingressv1alpha1.Domain
 	ingressDomainMap := d.calculateDomainsFromIngress()
@@ -631,17 +967,18 @@ func (d *Driver) calculateDomainsFromIngress() map[string]ingressv1alpha1.Domain
 			if rule.Host == "" {
 				continue
 			}
+			host := d.ingressEdgeHost(ingress, rule.Host)
 			domain := ingressv1alpha1.Domain{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      strings.Replace(rule.Host, ".", "-", -1),
+					Name:      domainResourceName(host),
 					Namespace: ingress.Namespace,
 				},
 				Spec: ingressv1alpha1.DomainSpec{
-					Domain: rule.Host,
+					Domain: host,
 				},
 			}
 			domain.Spec.Metadata = d.ingressMetadata
-			domainMap[rule.Host] = domain
+			domainMap[host] = domain
 		}
 	}
 
@@ -665,14 +1002,14 @@ func (d *Driver) calculateDomainsFromGateway(ingressDomains map[string]ingressv1
 			}
 			domain := ingressv1alpha1.Domain{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      strings.Replace(domainName, ".", "-", -1),
+					Name:      domainResourceName(domainName),
 					Namespace: gw.Namespace,
 				},
 				Spec: ingressv1alpha1.DomainSpec{
 					Domain: domainName,
 				},
 			}
-			domain.Spec.Metadata = d.gatewayMetadata
+			domain.Spec.Metadata = d.metadataWithLabels(d.gatewayMetadata, gatewayInfrastructureLabels(gw))
 			domainMap[domainName] = domain
 		}
 	}
@@ -681,7 +1018,8 @@ func (d *Driver) calculateDomainsFromGateway(ingressDomains map[string]ingressv1
 }
 
 // Given an ingress, it will resolve any ngrok modulesets defined on the ingress to the
-// CRDs and then will merge them in to a single moduleset
+// CRDs and then will merge them in to a single moduleset using the merge strategy of its
+// modules-merge-strategy annotation
 func (d *Driver) getNgrokModuleSetForIngress(ing *netv1.Ingress) (*ingressv1alpha1.NgrokModuleSet, error) {
 	computedModSet := &ingressv1alpha1.NgrokModuleSet{}
 
@@ -693,15 +1031,136 @@ func (d *Driver) getNgrokModuleSetForIngress(ing *netv1.Ingress) (*ingressv1alph
 		return computedModSet, err
 	}
 
+	return d.mergeNgrokModuleSets(ing, modules)
+}
+
+// mergeNgrokModuleSets resolves the named module sets in the ingress's namespace and merges them using the
+// merge strategy of its modules-merge-strategy annotation
+func (d *Driver) mergeNgrokModuleSets(ing *netv1.Ingress, modules []string) (*ingressv1alpha1.NgrokModuleSet, error) {
+	computedModSet := &ingressv1alpha1.NgrokModuleSet{}
+
+	sets := make([]*ingressv1alpha1.NgrokModuleSet, 0, len(modules))
 	for _, module := range modules {
 		resolvedMod, err := d.store.GetNgrokModuleSetV1(module, ing.Namespace)
 		if err != nil {
 			return computedModSet, err
 		}
-		computedModSet.Merge(resolvedMod)
+		sets = append(sets, resolvedMod)
+	}
+
+	strategy, err := annotations.ExtractModuleSetMergeStrategyFromAnnotations(ing)
+	if err != nil {
+		return computedModSet, err
+	}
+	return ingressv1alpha1.MergeModuleSetsWithStrategy(strategy, sets...)
+}
+
+// routeModules are the modules of an ingress's routes, resolved from its module sets and annotations
+type routeModules struct {
+	modSet          *ingressv1alpha1.NgrokModuleSet
+	userAgentFilter *ingressv1alpha1.EndpointUserAgentFilter
+	policy          json.RawMessage
+}
+
+// resolveRouteModules resolves the route modules of the ingress from the merged module set, including the
+// modules that are configured through the route's traffic policy
+func (d *Driver) resolveRouteModules(ingress *netv1.Ingress, modSet *ingressv1alpha1.NgrokModuleSet) (routeModules, error) {
+	modules := routeModules{modSet: modSet}
+
+	var err error
+	modules.userAgentFilter, err = d.getUserAgentFilterForIngress(ingress, modSet)
+	if err != nil {
+		return modules, fmt.Errorf("user agent filter: %w", err)
+	}
+
+	modules.policy, err = d.getPolicyJSON(ingress, modSet)
+	if err != nil {
+		return modules, fmt.Errorf("traffic policy: %w", err)
+	}
+
+	// the tracing and rate limit rules are each added to the front of the inbound rules, so the rate limit is
+	// added last to reject requests over the limit before any other rule runs
+	if modSet.Modules.Tracing != nil {
+		modules.policy, err = withTracingPolicy(modules.policy, modSet.Modules.Tracing)
+		if err != nil {
+			return modules, fmt.Errorf("tracing module: %w", err)
+		}
 	}
 
-	return computedModSet, nil
+	if modSet.Modules.RateLimit != nil {
+		modules.policy, err = withRateLimitPolicy(modules.policy, modSet.Modules.RateLimit)
+		if err != nil {
+			return modules, fmt.Errorf("rate limit module: %w", err)
+		}
+	}
+
+	if modSet.Modules.Fallback != nil {
+		modules.policy, err = withFallbackPolicy(modules.policy, modSet.Modules.Fallback)
+		if err != nil {
+			return modules, fmt.Errorf("fallback module: %w", err)
+		}
+	}
+
+	return modules, nil
+}
+
+// getPathRouteModules returns the route modules of the paths named by the ingress's path-modules annotation.
+// A path's module sets are merged after the ones of the whole ingress, so the path gets both. The edge modules
+// of a path's module sets, TLS termination and mutual TLS, are ignored since they apply to the whole host.
+func (d *Driver) getPathRouteModules(ingress *netv1.Ingress) (map[string]routeModules, error) {
+	pathSets, err := annotations.ExtractPathNgrokModuleSetsFromAnnotations(ingress)
+	if err != nil {
+		if errors.IsMissingAnnotations(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ingressSets, err := annotations.ExtractNgrokModuleSetsFromAnnotations(ingress)
+	if err != nil && !errors.IsMissingAnnotations(err) {
+		return nil, err
+	}
+
+	paths := map[string]bool{}
+	for _, rule := range ingress.Spec.Rules {
+		for _, path := range ingressRulePaths(ingress, rule) {
+			paths[path.Path] = true
+		}
+	}
+
+	annotatedPaths := maps.Keys(pathSets)
+	slices.Sort(annotatedPaths)
+	pathModules := make(map[string]routeModules, len(pathSets))
+	for _, path := range annotatedPaths {
+		sets := pathSets[path]
+		if !paths[path] {
+			return nil, fmt.Errorf("path %q doesn't match any path of the ingress", path)
+		}
+
+		modSet, err := d.mergeNgrokModuleSets(ingress, append(slices.Clone(ingressSets), sets...))
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		modules, err := d.resolveRouteModules(ingress, modSet)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		pathModules[path] = modules
+	}
+	return pathModules, nil
+}
+
+// getUserAgentFilterForIngress returns the user agent filter of the ingress's annotations, which takes
+// precedence over the one of its module sets
+func (d *Driver) getUserAgentFilterForIngress(ing *netv1.Ingress, modSet *ingressv1alpha1.NgrokModuleSet) (*ingressv1alpha1.EndpointUserAgentFilter, error) {
+	filter, err := annotations.ExtractUserAgentFilterFromAnnotations(ing)
+	if err != nil {
+		if errors.IsMissingAnnotations(err) {
+			return modSet.Modules.UserAgentFilter, nil
+		}
+		return nil, err
+	}
+	return filter, nil
 }
 
 func (d *Driver) getNgrokTrafficPolicyForIngress(ing *netv1.Ingress) (*ngrokv1alpha1.NgrokTrafficPolicy, error) {
@@ -736,7 +1195,7 @@ func (d *Driver) calculateHTTPSEdges(ingressDomains *[]ingressv1alpha1.Domain, g
 
 	if d.gatewayEnabled {
 		gatewayEdgeMap := make(map[string]ingressv1alpha1.HTTPSEdge)
-		httproutes := d.store.ListHTTPRoutes()
+		httproutes := d.gatewayHTTPRoutes()
 		gateways := d.store.ListGateways()
 		for _, gtw := range gateways {
 			gatewayDomains := make(map[string]string)
@@ -794,7 +1253,7 @@ func (d *Driver) calculateHTTPSEdges(ingressDomains *[]ingressv1alpha1.Domain, g
 						Hostports: hostPorts,
 					},
 				}
-				edge.Spec.Metadata = d.gatewayMetadata
+				edge.Spec.Metadata = d.metadataWithLabels(d.gatewayMetadata, gatewayInfrastructureLabels(gtw))
 				gatewayEdgeMap[routeDomains[0]] = edge
 
 			}
@@ -811,23 +1270,48 @@ func (d *Driver) calculateHTTPSEdges(ingressDomains *[]ingressv1alpha1.Domain, g
 }
 
 func (d *Driver) calculateHTTPSEdgesFromIngress(edgeMap map[string]ingressv1alpha1.HTTPSEdge) {
+	// edges that had routes removed because their backend service is missing and should be torn down if
+	// they don't have any routes left
+	teardownEdges := make(map[string]bool)
+	// the routes for each edge along with the ingress they came from, so they can be ordered across ingresses
+	edgeRoutes := make(map[string][]ingressRoute)
+
 	ingresses := d.store.ListNgrokIngressesV1()
 	for _, ingress := range ingresses {
 		modSet, err := d.getNgrokModuleSetForIngress(ingress)
 		if err != nil {
 			d.log.Error(err, "error getting ngrok moduleset for ingress", "ingress", ingress)
+			d.reportIngressIssue(ingress, events.ReasonModuleResolutionFailed, "Could not resolve module sets: %v", err)
+			continue
+		}
+
+		modules, err := d.resolveRouteModules(ingress, modSet)
+		if err != nil {
+			d.log.Error(err, "error resolving route modules for ingress", "ingress", ingress)
+			d.reportIngressIssue(ingress, events.ReasonModuleResolutionFailed, "Could not resolve %v", err)
 			continue
 		}
 
-		policyJSON, err := d.getPolicyJSON(ingress, modSet)
+		pathModules, err := d.getPathRouteModules(ingress)
 		if err != nil {
-			d.log.Error(err, "error marshalling JSON Policy for ingress", "ingress", ingress)
+			d.log.Error(err, "error resolving path module sets for ingress", "ingress", ingress)
+			d.reportIngressIssue(ingress, events.ReasonModuleResolutionFailed, "Could not resolve path module sets: %v", err)
 			continue
 		}
 
+		labels := d.ingressLabels(ingress)
+		routeMetadata := d.metadataWithLabels(d.ingressMetadata, labels)
+
+		for _, host := range d.ingressTLSConflicts(ingress) {
+			d.log.Info("ingress requests ngrok managed TLS but its domain uses a manually uploaded certificate", "ingress", ingress.Name, "namespace", ingress.Namespace, "host", host)
+			d.reportIngressIssue(ingress, events.ReasonTLSConflict,
+				"Host %s requests ngrok managed TLS, but its reserved domain uses a manually uploaded certificate", host)
+		}
+
 		for _, rule := range ingress.Spec.Rules {
 			// TODO: Handle routes without hosts that then apply to all edges
-			edge, ok := edgeMap[rule.Host]
+			edgeHost := d.ingressEdgeHost(ingress, rule.Host)
+			edge, ok := edgeMap[edgeHost]
 			if !ok {
 				d.log.Error(err, "could not find edge associated with rule", "host", rule.Host)
 				continue
@@ -843,19 +1327,24 @@ func (d *Driver) calculateHTTPSEdgesFromIngress(edgeMap map[string]ingressv1alph
 				edge.Spec.MutualTLS = modSet.Modules.MutualTLS
 			}
 
+			// the edge is shared by every ingress using the host, so it carries the labels of all of them
+			edge.Spec.Metadata = d.metadataWithLabels(edge.Spec.Metadata, labels)
+
 			// If any rule for an ingress matches, then it applies to this ingress
-			for _, httpIngressPath := range rule.HTTP.Paths {
-				matchType := "path_prefix"
-				if httpIngressPath.PathType != nil {
-					switch *httpIngressPath.PathType {
-					case netv1.PathTypePrefix:
-						matchType = "path_prefix"
-					case netv1.PathTypeExact:
-						matchType = "exact_path"
-					case netv1.PathTypeImplementationSpecific:
-						matchType = "path_prefix" // Path Prefix seems like a sane default for most cases
-					default:
-						d.log.Error(fmt.Errorf("unknown path type"), "unknown path type", "pathType", *httpIngressPath.PathType)
+			for _, httpIngressPath := range ingressRulePaths(ingress, rule) {
+				matchType, ok := pathMatchType(httpIngressPath.PathType)
+				if !ok {
+					d.log.Error(fmt.Errorf("unknown path type"), "unknown path type", "pathType", *httpIngressPath.PathType)
+					continue
+				}
+
+				match := httpIngressPath.Path
+				var expression string
+				if isPathExpression(httpIngressPath) {
+					match, expression, err = pathExpressionRoute(httpIngressPath.Path)
+					if err != nil {
+						d.log.Error(err, "invalid path expression", "ingress", ingress.Name, "namespace", ingress.Namespace, "path", httpIngressPath.Path)
+						d.reportIngressIssue(ingress, events.ReasonInvalidPath, "Path %s can't be routed: %v", httpIngressPath.Path, err)
 						continue
 					}
 				}
@@ -869,33 +1358,257 @@ func (d *Driver) calculateHTTPSEdgesFromIngress(edgeMap map[string]ingressv1alph
 				serviceUID, servicePort, err := d.getEdgeBackend(*httpIngressPath.Backend.Service, ingress.Namespace)
 				if err != nil {
 					d.log.Error(err, "could not find port for service", "namespace", ingress.Namespace, "service", serviceName)
+					if !errors.IsErrorNotFound(err) {
+						continue
+					}
+					d.reportIngressIssue(ingress, events.ReasonBackendNotFound, "Backend service %s/%s not found", ingress.Namespace, serviceName)
+
+					// The backend service doesn't exist (anymore), decide whether the edge keeps serving this path
+					switch d.backendMissingBehaviorForIngress(ingress) {
+					case BackendMissingBehaviorServe503:
+						route, err := d.serviceUnavailableRoute(match, matchType, ingress.Namespace, serviceName, httpIngressPath.Backend.Service.Port)
+						if err != nil {
+							d.log.Error(err, "error creating service unavailable route", "namespace", ingress.Namespace, "service", serviceName)
+							continue
+						}
+						if expression != "" {
+							// the 503 only covers the requests of the expression, not all of its literal prefix
+							route.Policy, err = withPathExpressionPolicy(route.Policy, expression)
+							if err != nil {
+								d.log.Error(err, "error adding path expression to service unavailable route", "namespace", ingress.Namespace, "service", serviceName)
+								continue
+							}
+						}
+						route.Metadata = routeMetadata
+						edgeRoutes[edgeHost] = append(edgeRoutes[edgeHost], ingressRoute{route: route, ingress: ingress, path: httpIngressPath.Path, expression: expression})
+					case BackendMissingBehaviorTeardown:
+						teardownEdges[edgeHost] = true
+					}
+					continue
+				}
+
+				routeModules := modules
+				if m, ok := pathModules[httpIngressPath.Path]; ok {
+					routeModules = m
+				}
+				policy := routeModules.policy
+				if expression != "" {
+					policy, err = withPathExpressionPolicy(policy, expression)
+					if err != nil {
+						d.log.Error(err, "error adding path expression to JSON Policy for ingress", "ingress", ingress)
+						continue
+					}
+				}
+				route := ingressv1alpha1.HTTPSEdgeRouteSpec{
+					Match:     match,
+					MatchType: matchType,
+					Backend: ingressv1alpha1.TunnelGroupBackend{
+						Labels: d.ngrokLabels(ingress.Namespace, serviceUID, serviceName, servicePort),
+					},
+					CircuitBreaker:      routeModules.modSet.Modules.CircuitBreaker,
+					Compression:         routeModules.modSet.Modules.Compression,
+					IPRestriction:       routeModules.modSet.Modules.IPRestriction,
+					Headers:             routeModules.modSet.Modules.Headers,
+					OAuth:               routeModules.modSet.Modules.OAuth,
+					Policy:              policy,
+					OIDC:                routeModules.modSet.Modules.OIDC,
+					SAML:                routeModules.modSet.Modules.SAML,
+					UserAgentFilter:     routeModules.userAgentFilter,
+					WebhookVerification: routeModules.modSet.Modules.WebhookVerification,
+				}
+				route.Metadata = routeMetadata
+
+				edgeRoutes[edgeHost] = append(edgeRoutes[edgeHost], ingressRoute{route: route, ingress: ingress, path: httpIngressPath.Path, expression: expression})
+			}
+
+			edgeMap[edgeHost] = edge
+		}
+	}
+
+	for host, routes := range edgeRoutes {
+		edge := edgeMap[host]
+		sortIngressRoutes(routes)
+		// An edge can only have one route per match. Hosts sharing a wildcard edge can't be told apart by
+		// their routes, so the first route wins and the ingresses of the others are warned.
+		matched := map[string]*netv1.Ingress{}
+		for i, r := range routes {
+			if r.expression != "" {
+				if covering := coveringPrefixRoute(routes, i); covering != nil {
+					d.log.Info("skipping path expression whose literal prefix is covered by another route", "host", host, "path", r.path, "route", covering.route.Match)
+					d.reportIngressIssue(r.ingress, events.ReasonRouteConflict,
+						"Path expression %s on %s can't be routed because the requests it doesn't match belong to path %s of ingress %s/%s",
+						r.path, host, covering.path, covering.ingress.Namespace, covering.ingress.Name)
 					continue
 				}
+			}
+
+			key := r.route.MatchType + " " + r.route.Match
+			if owner, ok := matched[key]; ok {
+				if owner == r.ingress {
+					d.reportIngressIssue(r.ingress, events.ReasonRouteConflict,
+						"Path %s on %s is routed more than once by the ingress, only its first backend is used", r.route.Match, host)
+				} else {
+					d.reportIngressIssue(r.ingress, events.ReasonRouteConflict,
+						"Path %s on %s is already routed by ingress %s/%s", r.route.Match, host, owner.Namespace, owner.Name)
+				}
+				continue
+			}
+			matched[key] = r.ingress
+			edge.Spec.Routes = append(edge.Spec.Routes, r.route)
+		}
+		edgeMap[host] = edge
+	}
+
+	for host := range teardownEdges {
+		if edge, ok := edgeMap[host]; ok && len(edge.Spec.Routes) == 0 {
+			d.log.Info("tearing down edge without any available backends", "host", host)
+			delete(edgeMap, host)
+		}
+	}
+}
+
+// ingressesForEdgeHost returns the ngrok ingresses with a rule served by the edge for the host
+func (d *Driver) ingressesForEdgeHost(host string) []*netv1.Ingress {
+	var ingresses []*netv1.Ingress
+	for _, ing := range d.store.ListNgrokIngressesV1() {
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" && d.ingressEdgeHost(ing, rule.Host) == host {
+				ingresses = append(ingresses, ing)
+				break
+			}
+		}
+	}
+	return ingresses
+}
+
+// ingressRoute is an edge route along with the ingress and path it was created from
+type ingressRoute struct {
+	route   ingressv1alpha1.HTTPSEdgeRouteSpec
+	ingress *netv1.Ingress
+	// path is the ingress path of the route
+	path string
+	// expression is the anchored path expression the route's policy matches, if the path is a path expression
+	expression string
+}
+
+// coveringPrefixRoute returns another prefix route of the edge whose match is a prefix of the literal prefix of
+// the path expression route at index i, if there is one. The expression route responds with a 404 to the requests the
+// expression doesn't match, but those requests belong to the covering route, like a "/" path or the ingress's
+// default backend, so the expression can't be routed without breaking it.
+func coveringPrefixRoute(routes []ingressRoute, i int) *ingressRoute {
+	r := routes[i]
+	for j := range routes {
+		other := &routes[j]
+		if j == i {
+			continue
+		}
+		if other.route.MatchType == MatchTypePathPrefix && strings.HasPrefix(r.route.Match, other.route.Match) {
+			return other
+		}
+	}
+	return nil
+}
+
+// sortIngressRoutes orders the routes of an edge deterministically when multiple ingresses share the same host,
+// so the edge is the same regardless of the order the ingresses were reconciled in. Routes are ordered by
+//  1. the longest match first, so more specific paths take priority
+//  2. the oldest ingress first, by creation timestamp
+//  3. the ingress's namespace and name
+//
+// Routes that are still tied come from the same ingress and keep the order of its paths.
+func sortIngressRoutes(routes []ingressRoute) {
+	slices.SortStableFunc(routes, func(a, b ingressRoute) int {
+		if c := cmp.Compare(len(b.route.Match), len(a.route.Match)); c != 0 {
+			return c
+		}
+		if c := a.ingress.CreationTimestamp.Time.Compare(b.ingress.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(getKey(a.ingress.Name, a.ingress.Namespace), getKey(b.ingress.Name, b.ingress.Namespace))
+	})
+}
+
+// backendMissingBehaviorForIngress returns the behavior for missing backend services of the ingress, which
+// is the driver's default unless it's overridden by the ingress's annotation
+func (d *Driver) backendMissingBehaviorForIngress(ing *netv1.Ingress) string {
+	behavior, err := annotations.ExtractBackendMissingBehaviorFromAnnotations(ing)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading backend missing behavior annotation", "ingress", ing.Name, "namespace", ing.Namespace)
+		}
+		return d.backendMissingBehavior
+	}
+
+	switch behavior {
+	case BackendMissingBehaviorServe503, BackendMissingBehaviorTeardown:
+		return behavior
+	default:
+		d.log.Error(fmt.Errorf("unknown backend missing behavior %q", behavior), "invalid backend missing behavior annotation", "ingress", ing.Name, "namespace", ing.Namespace)
+		return d.backendMissingBehavior
+	}
+}
 
-				route := ingressv1alpha1.HTTPSEdgeRouteSpec{
-					Match:     httpIngressPath.Path,
-					MatchType: matchType,
-					Backend: ingressv1alpha1.TunnelGroupBackend{
-						Labels: d.ngrokLabels(ingress.Namespace, serviceUID, serviceName, servicePort),
+// serviceUnavailableRoute creates a route for a path whose backend service doesn't exist. The route's
+// policy responds with a 503 before the request is forwarded to the (non-existent) backend.
+func (d *Driver) serviceUnavailableRoute(path, matchType, namespace, serviceName string, servicePort netv1.ServiceBackendPort) (ingressv1alpha1.HTTPSEdgeRouteSpec, error) {
+	config, err := json.Marshal(CustomResponseConfig{
+		StatusCode: 503,
+		Content:    "Service Unavailable",
+	})
+	if err != nil {
+		return ingressv1alpha1.HTTPSEdgeRouteSpec{}, err
+	}
+
+	policy, err := json.Marshal(ingressv1alpha1.EndpointPolicy{
+		Inbound: []ingressv1alpha1.EndpointRule{
+			{
+				Name: "Backend Unavailable",
+				Actions: []ingressv1alpha1.EndpointAction{
+					{
+						Type:   "custom-response",
+						Config: config,
 					},
-					CircuitBreaker:      modSet.Modules.CircuitBreaker,
-					Compression:         modSet.Modules.Compression,
-					IPRestriction:       modSet.Modules.IPRestriction,
-					Headers:             modSet.Modules.Headers,
-					OAuth:               modSet.Modules.OAuth,
-					Policy:              policyJSON,
-					OIDC:                modSet.Modules.OIDC,
-					SAML:                modSet.Modules.SAML,
-					WebhookVerification: modSet.Modules.WebhookVerification,
-				}
-				route.Metadata = d.ingressMetadata
+				},
+			},
+		},
+	})
+	if err != nil {
+		return ingressv1alpha1.HTTPSEdgeRouteSpec{}, err
+	}
 
-				edge.Spec.Routes = append(edge.Spec.Routes, route)
-			}
+	labels := d.ngrokLabels(namespace, "", serviceName, servicePort.Number)
+	if servicePort.Number == 0 {
+		// a named port can't be resolved to its number without the service, so the port is labeled by its name
+		labels[labelPort] = servicePort.Name
+	}
+
+	route := ingressv1alpha1.HTTPSEdgeRouteSpec{
+		Match:     path,
+		MatchType: matchType,
+		Backend: ingressv1alpha1.TunnelGroupBackend{
+			Labels: labels,
+		},
+		Policy: policy,
+	}
+	route.Metadata = d.ingressMetadata
+	return route, nil
+}
 
-			edgeMap[rule.Host] = edge
+// withPolicyRules adds the inbound rules to the front of the policy's inbound rules and the outbound rules to
+// the end of its outbound rules
+func withPolicyRules(policyJSON json.RawMessage, inbound, outbound []ingressv1alpha1.EndpointRule) (json.RawMessage, error) {
+	policy := ingressv1alpha1.EndpointPolicy{}
+	if len(policyJSON) > 0 {
+		if err := json.Unmarshal(policyJSON, &policy); err != nil {
+			return nil, err
 		}
 	}
+
+	if len(inbound) > 0 {
+		policy.Inbound = append(append([]ingressv1alpha1.EndpointRule{}, inbound...), policy.Inbound...)
+	}
+	policy.Outbound = append(policy.Outbound, outbound...)
+	return json.Marshal(policy)
 }
 
 // retrieves the traffic policy for an ingress and falls back to the modSet policy if it doesn't exist
@@ -915,6 +1628,9 @@ func (d *Driver) getPolicyJSON(ingress *netv1.Ingress, modSet *ingressv1alpha1.N
 	}
 
 	if trafficPolicy != nil {
+		if err := ingressv1alpha1.ValidateTrafficPolicy(trafficPolicy.Spec.Policy); err != nil {
+			return nil, fmt.Errorf("NgrokTrafficPolicy %s/%s: %w", trafficPolicy.Namespace, trafficPolicy.Name, err)
+		}
 		return trafficPolicy.Spec.Policy, nil
 	}
 
@@ -942,7 +1658,7 @@ func (d *Driver) calculateHTTPSEdgesFromGateway(edgeMap map[string]ingressv1alph
 			if len(allowedRoutes) > 0 {
 				createHttpsedge := false
 				for _, routeKind := range allowedRoutes {
-					if routeKind.Kind == "HTTPRoute" {
+					if routeKind.Kind == "HTTPRoute" || routeKind.Kind == "GRPCRoute" {
 						createHttpsedge = true
 					}
 				}
@@ -957,26 +1673,19 @@ func (d *Driver) calculateHTTPSEdgesFromGateway(edgeMap map[string]ingressv1alph
 			}
 			// TODO: Calculate routes from httpRoutes
 			// TODO: skip if no backend services
-			httproutes := d.store.ListHTTPRoutes()
+			httproutes := d.gatewayHTTPRoutes()
 			for _, httproute := range httproutes {
+				if isGRPCRoute(httproute) && !listenerAllowsKind(listener, "GRPCRoute") {
+					continue
+				}
 				for _, parent := range httproute.Spec.ParentRefs {
 					if string(parent.Name) != gtw.Name {
 						// not our gateway so skip
 						continue
 					}
 
-					if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces.From != nil {
-						switch *listener.AllowedRoutes.Namespaces.From {
-						case gatewayv1.NamespacesFromAll:
-						case gatewayv1.NamespacesFromSame:
-							if httproute.Namespace != gtw.Namespace {
-								continue
-							}
-						case gatewayv1.NamespacesFromSelector:
-							if httproute.Namespace != listener.AllowedRoutes.Namespaces.Selector.String() {
-								continue
-							}
-						}
+					if !listenerAllowsNamespace(listener, gtw, httproute.Namespace) {
+						continue
 					}
 
 					// matches our gateway
@@ -987,26 +1696,10 @@ func (d *Driver) calculateHTTPSEdgesFromGateway(edgeMap map[string]ingressv1alph
 						}
 						// matches gateway and listener
 						for _, rule := range httproute.Spec.Rules {
-							// TODO: resolve rule.Matches
-							// TODO: resolve rule.Filters
-							// for v0 we will only resolve the first backendRef
-							pathMatch := "/"
-							pathMatchType := "path_prefix"
-							// first match with a path will be accepted as the route's path
-							for _, match := range rule.Matches {
-								if match.Path != nil {
-									pathMatch = *match.Path.Value
-									if *match.Path.Type == gatewayv1.PathMatchExact {
-										pathMatchType = "exact_path"
-									}
-									break
-								}
-							}
-							route := ingressv1alpha1.HTTPSEdgeRouteSpec{
-								Match:     pathMatch,     // change based on the rule.match
-								MatchType: pathMatchType, // change based on rule.Matches
+							if err := unsupportedRuleMatch(rule); err != nil {
+								d.log.Info("skipping route rule with unsupported match", "namespace", httproute.Namespace, "route", httproute.Name, "reason", err.Error())
+								continue
 							}
-
 							// TODO: set with values from rules.Filters + rules.Matches
 							// this HTTPRouteRule comes direct from gateway api yaml, and func returns the policy,
 							// which goes directly into the edge route in ngrok.
@@ -1016,42 +1709,43 @@ func (d *Driver) calculateHTTPSEdgesFromGateway(edgeMap map[string]ingressv1alph
 								d.log.Error(err, "error creating policy from HTTPRouteRule", "rule", rule)
 								continue
 							}
+
+							backends := d.gatewayRuleBackends(httproute, rule.BackendRefs)
+							if len(backends) == 0 && backendRefWeightsAllZero(rule.BackendRefs) {
+								// the rule's requests are responded to with a 500 before they're forwarded, but the
+								// route still needs a backend, so it's the rule's backends regardless of their weights
+								if err := withZeroWeightBackendsPolicy(policy); err != nil {
+									d.log.Error(err, "error adding zero weight backends policy", "rule", rule)
+									continue
+								}
+								backends = d.gatewayRuleBackends(httproute, withoutBackendRefWeights(rule.BackendRefs))
+							}
+
 							policyStr, err := json.Marshal(policy)
 							if err != nil {
 								d.log.Error(err, "cannot convert policy json", "Policy", policy)
 								continue
 							}
-							route.Policy = policyStr
-
-							for idx, backendref := range rule.BackendRefs {
-								// currently the ingress controller doesn't support weighted backends
-								// so we'll only support one backendref per rule
-								// TODO: remove when tested with multiple backends
-								if idx > 0 {
-									break
-								}
-								// handle backendref
-								refKind := string(*backendref.Kind)
-								if refKind != "Service" {
-									// only support services currently
-									continue
-								}
-
-								refName := string(backendref.Name)
-								serviceUID, servicePort, err := d.getEdgeBackendRef(backendref.BackendRef, httproute.Namespace)
-								if err != nil {
-									d.log.Error(err, "could not find port for service", "namespace", httproute.Namespace, "service", refName)
-									continue
-								}
+							route := ingressv1alpha1.HTTPSEdgeRouteSpec{
+								Policy:  policyStr,
+								Headers: gatewayRouteHeaders(rule.Filters),
+							}
 
-								route.Backend = ingressv1alpha1.TunnelGroupBackend{
-									Labels: d.ngrokLabels(httproute.Namespace, serviceUID, refName, servicePort),
+							if len(backends) > 0 {
+								// the first backend serves all the route's traffic unless there are several to split it between
+								route.Backend = backends[0].TunnelGroupBackend
+								if len(backends) > 1 {
+									route.WeightedBackends = backends
 								}
-
 							}
-							route.Metadata = d.gatewayMetadata
+							route.Metadata = d.metadataWithLabels(d.gatewayMetadata, gatewayInfrastructureLabels(gtw))
 
-							edge.Spec.Routes = append(edge.Spec.Routes, route)
+							// each of the rule's matches is a route of its own, since a request matching any of them
+							// matches the rule
+							for _, match := range gatewayRulePathMatches(rule) {
+								route.Match, route.MatchType = match.match, match.matchType
+								edge.Spec.Routes = append(edge.Spec.Routes, route)
+							}
 						}
 					}
 				}
@@ -1062,6 +1756,127 @@ func (d *Driver) calculateHTTPSEdgesFromGateway(edgeMap map[string]ingressv1alph
 	}
 }
 
+// gatewayRulePathMatch is the path an edge route matches for a match of an HTTPRoute rule
+type gatewayRulePathMatch struct {
+	match     string
+	matchType string
+}
+
+// gatewayRulePathMatches returns the distinct paths of the rule's matches, in order. A rule without matches,
+// and a match without a path, matches every path.
+func gatewayRulePathMatches(rule gatewayv1.HTTPRouteRule) []gatewayRulePathMatch {
+	matches := rule.Matches
+	if len(matches) == 0 {
+		matches = []gatewayv1.HTTPRouteMatch{{}}
+	}
+
+	var paths []gatewayRulePathMatch
+	for _, match := range matches {
+		path := gatewayRulePathMatch{match: "/", matchType: MatchTypePathPrefix}
+		if match.Path != nil {
+			path.match = ptr.Deref(match.Path.Value, "/")
+			if ptr.Deref(match.Path.Type, gatewayv1.PathMatchPathPrefix) == gatewayv1.PathMatchExact {
+				path.matchType = MatchTypeExactPath
+			}
+		}
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// maxBackendWeight is the highest weight ngrok allows for a backend of a weighted backend
+const maxBackendWeight = 10000
+
+// gatewayRuleBackends returns the tunnel group backends of the rule's service backend refs with their weights.
+// Refs without a weight have a weight of 1, and refs with a weight of 0 don't get any traffic, so they're left
+// out. Weights are scaled down in proportion to each other when any of them is above the highest weight ngrok
+// allows, but never to 0.
+func (d *Driver) gatewayRuleBackends(httproute *gatewayv1.HTTPRoute, backendRefs []gatewayv1.HTTPBackendRef) []ingressv1alpha1.WeightedTunnelGroupBackend {
+	var backends []ingressv1alpha1.WeightedTunnelGroupBackend
+	var maxWeight int64
+	for _, backendref := range backendRefs {
+		if backendref.Kind != nil && *backendref.Kind != "Service" {
+			// only support services currently
+			continue
+		}
+
+		refName := string(backendref.Name)
+		namespace, err := d.backendRefNamespace(httproute, backendref.BackendRef)
+		if err != nil {
+			d.log.Error(err, "backend reference not permitted", "namespace", httproute.Namespace, "service", refName)
+			continue
+		}
+		serviceUID, servicePort, err := d.getEdgeBackendRef(backendref.BackendRef, namespace)
+		if err != nil {
+			d.log.Error(err, "could not find port for service", "namespace", namespace, "service", refName)
+			continue
+		}
+
+		weight := int64(1)
+		if backendref.Weight != nil {
+			weight = int64(*backendref.Weight)
+		}
+		if weight == 0 {
+			continue
+		}
+		maxWeight = max(maxWeight, weight)
+		backends = append(backends, ingressv1alpha1.WeightedTunnelGroupBackend{
+			TunnelGroupBackend: ingressv1alpha1.TunnelGroupBackend{
+				Labels: d.ngrokLabels(namespace, serviceUID, refName, servicePort),
+			},
+			Weight: weight,
+		})
+	}
+
+	if maxWeight > maxBackendWeight {
+		for i := range backends {
+			backends[i].Weight = max(1, backends[i].Weight*maxBackendWeight/maxWeight)
+		}
+	}
+	return backends
+}
+
+// backendRefWeightsAllZero returns true if the rule has backend refs and all of them have a weight of 0
+func backendRefWeightsAllZero(backendRefs []gatewayv1.HTTPBackendRef) bool {
+	for _, backendRef := range backendRefs {
+		if backendRef.Weight == nil || *backendRef.Weight != 0 {
+			return false
+		}
+	}
+	return len(backendRefs) > 0
+}
+
+// withoutBackendRefWeights returns copies of the backend refs without their weights
+func withoutBackendRefWeights(backendRefs []gatewayv1.HTTPBackendRef) []gatewayv1.HTTPBackendRef {
+	refs := make([]gatewayv1.HTTPBackendRef, len(backendRefs))
+	for i, backendRef := range backendRefs {
+		refs[i] = *backendRef.DeepCopy()
+		refs[i].Weight = nil
+	}
+	return refs
+}
+
+// zeroWeightBackendsRuleName is the name of the inbound rule that responds to the requests of a rule whose
+// backends all have a weight of 0
+const zeroWeightBackendsRuleName = "No backends with weight"
+
+// withZeroWeightBackendsPolicy adds a rule to the start of the policy's inbound rules that responds with a 500
+// to every request, which is what the Gateway API requires for a rule whose backends all have a weight of 0
+func withZeroWeightBackendsPolicy(policy *ingressv1alpha1.EndpointPolicy) error {
+	config, err := json.Marshal(CustomResponseConfig{StatusCode: 500})
+	if err != nil {
+		return err
+	}
+	rule := ingressv1alpha1.EndpointRule{
+		Name:    zeroWeightBackendsRuleName,
+		Actions: []ingressv1alpha1.EndpointAction{{Type: "custom-response", Config: config}},
+	}
+	policy.Inbound = append([]ingressv1alpha1.EndpointRule{rule}, policy.Inbound...)
+	return nil
+}
+
 type Actions struct {
 	endpointActions []ingressv1alpha1.EndpointAction
 }
@@ -1095,14 +1910,13 @@ func (d *Driver) createEndpointPolicyForGateway(rule *gatewayv1.HTTPRouteRule, n
 			}
 		}
 
+		// HTTPS edge routes are only selected by the request's path, so method and query param matches can't pick
+		// the route a request goes to. They're logged and the rule's route matches on its path alone. Rules with
+		// header matches aren't routed at all, see unsupportedRuleMatch.
 		if match.Method != nil {
 			d.log.Error(fmt.Errorf("unsupported match type"), "Unsupported match type", "HTTPMethod", *match.Method)
 		}
 
-		if len(match.Headers) > 0 {
-			d.log.Error(fmt.Errorf("unsupported match type"), "Unsupported match type", "HTTPHeaderMatch", match.Headers)
-		}
-
 		if len(match.QueryParams) > 0 {
 			d.log.Error(fmt.Errorf("unsupported match type"), "Unsupported match type", "HTTPQueryParamMatch", match.QueryParams)
 		}
@@ -1148,14 +1962,14 @@ func (d *Driver) createEndpointPolicyForGateway(rule *gatewayv1.HTTPRouteRule, n
 				return nil, err
 			}
 		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
-			err := d.handleHTTPHeaderFilter(filter.RequestHeaderModifier, &inboundActions, nil)
-			if err != nil {
-				return nil, err
-			}
+			// the route's request headers module changes the request headers, see gatewayRouteHeaders
 		case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
-			err := d.handleHTTPHeaderFilter(filter.ResponseHeaderModifier, &outboundActions, responseHeaders)
-			if err != nil {
-				return nil, err
+			// the route's response headers module changes the response headers, see gatewayRouteHeaders. Redirects
+			// are responded to before the module, so the headers added to responses are added to them here.
+			if filter.ResponseHeaderModifier != nil {
+				for _, header := range append(filter.ResponseHeaderModifier.Add, filter.ResponseHeaderModifier.Set...) {
+					responseHeaders[string(header.Name)] = header.Value
+				}
 			}
 		case gatewayv1.HTTPRouteFilterURLRewrite:
 			err := d.handleURLRewriteFilter(filter.URLRewrite, pathPrefixMatches, &inboundActions)
@@ -1193,6 +2007,22 @@ func (d *Driver) createEndpointPolicyForGateway(rule *gatewayv1.HTTPRouteRule, n
 	return policy, nil
 }
 
+// unsupportedRuleMatch returns an error describing the first match of the rule that can't be routed. Edge routes
+// can't match regular expressions. HTTPS edge routes are only selected by the request's path, so a header match can't send a request to a different route
+// than the other requests for its path, like a canary's. Rejecting those requests instead would break the rules
+// for the same path without header matches, so rules with header matches aren't routed.
+func unsupportedRuleMatch(rule gatewayv1.HTTPRouteRule) error {
+	for _, match := range rule.Matches {
+		if len(match.Headers) > 0 {
+			return fmt.Errorf("header matches aren't supported, routes can only be selected by path")
+		}
+		if match.Path != nil && ptr.Deref(match.Path.Type, gatewayv1.PathMatchPathPrefix) == gatewayv1.PathMatchRegularExpression {
+			return fmt.Errorf("regular expression path %s isn't supported, routes can only match exact paths and path prefixes", ptr.Deref(match.Path.Value, ""))
+		}
+	}
+	return nil
+}
+
 type RemoveHeadersConfig struct {
 	Headers []string `json:"headers"`
 }
@@ -1201,6 +2031,12 @@ type AddHeadersConfig struct {
 	Headers map[string]string `json:"headers"`
 }
 
+type CustomResponseConfig struct {
+	StatusCode int               `json:"status_code"`
+	Content    string            `json:"content,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+}
+
 func (d *Driver) handleExtensionRef(extensionRef *gatewayv1.LocalObjectReference, namespace string, inboundRules *EndpointRules,
 	outboundRules *EndpointRules) error {
 
@@ -1217,6 +2053,9 @@ func (d *Driver) handleExtensionRef(extensionRef *gatewayv1.LocalObjectReference
 		if jsonMessage == nil {
 			return errors.NewErrorNotFound(fmt.Sprintf("PolicyCRD %v found with no policy", extensionRef.Name))
 		}
+		if err := ingressv1alpha1.ValidateTrafficPolicy(jsonMessage); err != nil {
+			return fmt.Errorf("NgrokTrafficPolicy %s/%s: %w", namespace, extensionRef.Name, err)
+		}
 		var policyStruct ingressv1alpha1.EndpointPolicy
 		err = json.Unmarshal(jsonMessage, &policyStruct)
 		if err != nil {
@@ -1232,45 +2071,6 @@ func (d *Driver) handleExtensionRef(extensionRef *gatewayv1.LocalObjectReference
 	return nil
 }
 
-func (d *Driver) handleHTTPHeaderFilter(filter *gatewayv1.HTTPHeaderFilter, actions *Actions, requestRedirectHeaders map[string]string) error {
-	if filter == nil {
-		return nil
-	}
-
-	if err := d.handleHTTPHeaderFilterRemove(filter.Remove, actions); err != nil {
-		return err
-	}
-
-	if err := d.handleHTTPHeaderFilterAdd(filter.Add, actions, requestRedirectHeaders); err != nil {
-		return err
-	}
-
-	if err := d.handleHTTPHeaderFilterSet(filter, actions, requestRedirectHeaders); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (d *Driver) handleHTTPHeaderFilterRemove(headersToRemove []string, actions *Actions) error {
-	if len(headersToRemove) == 0 {
-		return nil
-	}
-
-	removeHeaders, err := json.Marshal(RemoveHeadersConfig{Headers: headersToRemove})
-	if err != nil {
-		d.log.Error(err, "cannot convert headers to json", "headers", headersToRemove)
-		return err
-	}
-
-	actions.endpointActions = append(actions.endpointActions, ingressv1alpha1.EndpointAction{
-		Type:   "remove-headers",
-		Config: removeHeaders,
-	})
-
-	return nil
-}
-
 func (d *Driver) handleHTTPHeaderFilterAdd(headersToAdd []gatewayv1.HTTPHeader, actions *Actions, requestRedirectHeaders map[string]string) error {
 	if len(headersToAdd) == 0 {
 		return nil
@@ -1301,24 +2101,48 @@ func (d *Driver) handleHTTPHeaderFilterAdd(headersToAdd []gatewayv1.HTTPHeader,
 	return nil
 }
 
-func (d *Driver) handleHTTPHeaderFilterSet(filter *gatewayv1.HTTPHeaderFilter, actions *Actions, requestRedirectHeaders map[string]string) error {
-	if filter == nil {
-		return nil
-	}
-	removeHeaders := []string{}
-	for _, header := range filter.Set {
-		removeHeaders = append(removeHeaders, string(header.Name))
+// gatewayRouteHeaders returns the request and response headers modules of the RequestHeaderModifier and
+// ResponseHeaderModifier filters, or nil if there are neither. The modules remove headers before adding them,
+// so a header that's set is both removed and added.
+func gatewayRouteHeaders(filters []gatewayv1.HTTPRouteFilter) *ingressv1alpha1.EndpointHeaders {
+	var headers *ingressv1alpha1.EndpointHeaders
+	for _, filter := range filters {
+		switch {
+		case filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier && filter.RequestHeaderModifier != nil:
+			if headers == nil {
+				headers = &ingressv1alpha1.EndpointHeaders{}
+			}
+			if headers.Request == nil {
+				headers.Request = &ingressv1alpha1.EndpointRequestHeaders{}
+			}
+			headers.Request.Add, headers.Request.Remove = headerFilterChanges(filter.RequestHeaderModifier, headers.Request.Add, headers.Request.Remove)
+		case filter.Type == gatewayv1.HTTPRouteFilterResponseHeaderModifier && filter.ResponseHeaderModifier != nil:
+			if headers == nil {
+				headers = &ingressv1alpha1.EndpointHeaders{}
+			}
+			if headers.Response == nil {
+				headers.Response = &ingressv1alpha1.EndpointResponseHeaders{}
+			}
+			headers.Response.Add, headers.Response.Remove = headerFilterChanges(filter.ResponseHeaderModifier, headers.Response.Add, headers.Response.Remove)
+		}
 	}
+	return headers
+}
 
-	if err := d.handleHTTPHeaderFilterRemove(removeHeaders, actions); err != nil {
-		return err
+// headerFilterChanges adds the headers the filter adds, sets, and removes to the headers to add and remove
+func headerFilterChanges(filter *gatewayv1.HTTPHeaderFilter, add map[string]string, remove []string) (map[string]string, []string) {
+	for _, header := range filter.Set {
+		remove = append(remove, string(header.Name))
 	}
+	remove = append(remove, filter.Remove...)
 
-	if err := d.handleHTTPHeaderFilterAdd(filter.Set, actions, requestRedirectHeaders); err != nil {
-		return err
+	for _, header := range append(filter.Add, filter.Set...) {
+		if add == nil {
+			add = make(map[string]string)
+		}
+		add[string(header.Name)] = header.Value
 	}
-
-	return nil
+	return add, remove
 }
 
 type URLRedirectConfig struct {
@@ -1499,7 +2323,7 @@ func (d *Driver) calculateTunnels() map[tunnelKey]ingressv1alpha1.Tunnel {
 func (d *Driver) calculateTunnelsFromIngress(tunnels map[tunnelKey]ingressv1alpha1.Tunnel) {
 	for _, ingress := range d.store.ListNgrokIngressesV1() {
 		for _, rule := range ingress.Spec.Rules {
-			for _, path := range rule.HTTP.Paths {
+			for _, path := range ingressRulePaths(ingress, rule) {
 				// We only support service backends right now.
 				// TODO: support resource backends
 				if path.Backend.Service == nil {
@@ -1515,7 +2339,7 @@ func (d *Driver) calculateTunnelsFromIngress(tunnels map[tunnelKey]ingressv1alph
 				key := tunnelKey{ingress.Namespace, serviceName, strconv.Itoa(int(servicePort))}
 				tunnel, found := tunnels[key]
 				if !found {
-					targetAddr := fmt.Sprintf("%s.%s.%s:%d", serviceName, key.namespace, clusterDomain, servicePort)
+					targetAddr := d.serviceTargetAddr(serviceName, key.namespace, servicePort)
 					tunnel = ingressv1alpha1.Tunnel{
 						ObjectMeta: metav1.ObjectMeta{
 							GenerateName:    fmt.Sprintf("%s-%d-", serviceName, servicePort),
@@ -1526,14 +2350,22 @@ func (d *Driver) calculateTunnelsFromIngress(tunnels map[tunnelKey]ingressv1alph
 						Spec: ingressv1alpha1.TunnelSpec{
 							ForwardsTo: targetAddr,
 							Labels:     d.ngrokLabels(ingress.Namespace, serviceUID, serviceName, servicePort),
+							Metadata:   d.ingressMetadata,
 							BackendConfig: &ingressv1alpha1.BackendConfig{
-								Protocol: protocol,
+								Protocol:                   protocol,
+								MaxConnections:             d.serviceMaxConnections(serviceName, ingress.Namespace),
+								KeepAliveInterval:          d.serviceKeepAliveInterval(serviceName, ingress.Namespace),
+								InsecureSkipVerify:         d.serviceUpstreamTLSSkipVerify(serviceName, ingress.Namespace),
+								ServerName:                 d.serviceAnnotation(serviceName, ingress.Namespace, annotations.ExtractUpstreamTLSServerNameFromAnnotations),
+								CertificateAuthoritySecret: d.serviceAnnotation(serviceName, ingress.Namespace, annotations.ExtractUpstreamTLSCASecretFromAnnotations),
 							},
 							AppProtocol: appProtocol,
 						},
 					}
 				}
 
+				tunnel.Spec.Metadata = d.metadataWithLabels(tunnel.Spec.Metadata, d.ingressLabels(ingress))
+
 				hasIngressReference := false
 				for _, ref := range tunnel.OwnerReferences {
 					if ref.UID == ingress.UID {
@@ -1559,8 +2391,154 @@ func (d *Driver) calculateTunnelsFromIngress(tunnels map[tunnelKey]ingressv1alph
 	}
 }
 
+// serviceUpstreamTLSSkipVerify returns whether the tunnels for a backend service skip verifying the certificate of
+// its HTTPS ports. They always do unless the driver verifies upstream TLS, in which case the service can still opt
+// out with its annotation.
+func (d *Driver) serviceUpstreamTLSSkipVerify(serviceName, namespace string) bool {
+	if !d.verifyUpstreamTLS {
+		return true
+	}
+
+	service, err := d.store.GetServiceV1(serviceName, namespace)
+	if err != nil {
+		return false
+	}
+
+	skip, err := annotations.ExtractUpstreamTLSSkipVerifyFromAnnotations(service)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading service annotation", "service", serviceName, "namespace", namespace)
+		}
+		return false
+	}
+	return skip
+}
+
+// ingressLabels returns the labels from the k8s.ngrok.com/labels annotation on the ingress, ignoring them
+// if they aren't valid
+func (d *Driver) ingressLabels(ing *netv1.Ingress) map[string]string {
+	labels, err := annotations.ExtractLabelsFromAnnotations(ing)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading labels annotation", "ingress", ing.Name, "namespace", ing.Namespace)
+		}
+		return nil
+	}
+	return labels
+}
+
+// gatewayInfrastructureLabels returns the labels and annotations of the gateway's infrastructure, which are
+// meant for the resources created for the gateway, to be added to the metadata of its ngrok resources. Labels
+// win over annotations with the same key.
+func gatewayInfrastructureLabels(gtw *gatewayv1.Gateway) map[string]string {
+	if gtw.Spec.Infrastructure == nil {
+		return nil
+	}
+	labels := make(map[string]string, len(gtw.Spec.Infrastructure.Annotations)+len(gtw.Spec.Infrastructure.Labels))
+	for k, v := range gtw.Spec.Infrastructure.Annotations {
+		labels[string(k)] = string(v)
+	}
+	for k, v := range gtw.Spec.Infrastructure.Labels {
+		labels[string(k)] = string(v)
+	}
+	return labels
+}
+
+// metadataWithLabels adds the labels to the JSON metadata for an ngrok resource. Keys already in the
+// metadata, such as the controller's defaults, take precedence over the labels.
+func (d *Driver) metadataWithLabels(metadata string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return metadata
+	}
+
+	merged := make(map[string]string)
+	if metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &merged); err != nil {
+			d.log.Error(err, "error unmarshalling metadata, not adding labels", "metadata", metadata)
+			return metadata
+		}
+	}
+	for k, v := range labels {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+
+	jsonString, err := json.Marshal(merged)
+	if err != nil {
+		d.log.Error(err, "error marshalling metadata with labels", "labels", labels)
+		return metadata
+	}
+	return string(jsonString)
+}
+
+// serviceMaxConnections returns the maximum number of concurrent connections to a backend service from the
+// annotation on the service, or 0 when there is no limit
+func (d *Driver) serviceMaxConnections(serviceName, namespace string) int32 {
+	service, err := d.store.GetServiceV1(serviceName, namespace)
+	if err != nil {
+		return 0
+	}
+
+	maxConnections, err := annotations.ExtractMaxConnectionsFromAnnotations(service)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading max connections annotation", "service", serviceName, "namespace", namespace)
+		}
+		return 0
+	}
+
+	if maxConnections <= 0 || maxConnections > math.MaxInt32 {
+		d.log.Error(fmt.Errorf("max connections must be a positive number, got %d", maxConnections), "ignoring max connections annotation", "service", serviceName, "namespace", namespace)
+		return 0
+	}
+	return int32(maxConnections)
+}
+
+// serviceAnnotation returns the value of a string annotation on a backend service, or an empty string if the
+// service or its annotation is missing
+func (d *Driver) serviceAnnotation(serviceName, namespace string, extract func(client.Object) (string, error)) string {
+	service, err := d.store.GetServiceV1(serviceName, namespace)
+	if err != nil {
+		return ""
+	}
+
+	value, err := extract(service)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading service annotation", "service", serviceName, "namespace", namespace)
+		}
+		return ""
+	}
+	return value
+}
+
+// serviceKeepAliveInterval returns how often keepalive probes are sent on connections to a backend service from
+// the annotation on the service, falling back to the driver's default. It returns nil to use the tunnel's default.
+func (d *Driver) serviceKeepAliveInterval(serviceName, namespace string) *metav1.Duration {
+	interval := d.backendKeepAliveInterval
+
+	service, err := d.store.GetServiceV1(serviceName, namespace)
+	if err == nil {
+		fromAnnotation, err := annotations.ExtractBackendKeepAliveIntervalFromAnnotations(service)
+		switch {
+		case err == nil && fromAnnotation < time.Second:
+			d.log.Error(fmt.Errorf("backend keepalive interval must be at least 1s, got %s", fromAnnotation), "ignoring backend keepalive interval annotation", "service", serviceName, "namespace", namespace)
+		case err == nil:
+			interval = fromAnnotation
+		case !errors.IsMissingAnnotations(err):
+			d.log.Error(err, "error reading backend keepalive interval annotation", "service", serviceName, "namespace", namespace)
+		}
+	}
+
+	if interval == 0 {
+		return nil
+	}
+	return &metav1.Duration{Duration: interval}
+}
+
 func (d *Driver) calculateTunnelsFromGateway(tunnels map[tunnelKey]ingressv1alpha1.Tunnel) {
-	httproutes := d.store.ListHTTPRoutes()
+	httproutes := d.gatewayHTTPRoutes()
 
 	for _, httproute := range httproutes {
 		for _, rule := range httproute.Spec.Rules {
@@ -1573,34 +2551,56 @@ func (d *Driver) calculateTunnelsFromGateway(tunnels map[tunnelKey]ingressv1alph
 				//}
 
 				serviceName := string(backendRef.Name)
-				serviceUID, servicePort, protocol, appProtocol, err := d.getTunnelBackendFromGateway(backendRef.BackendRef, httproute.Namespace)
+				// the tunnel to a backend in another namespace is made in the backend's namespace
+				namespace, err := d.backendRefNamespace(httproute, backendRef.BackendRef)
 				if err != nil {
-					d.log.Error(err, "could not find port for service", "namespace", httproute.Namespace, "service", serviceName)
+					d.log.Error(err, "backend reference not permitted", "namespace", httproute.Namespace, "service", serviceName)
+					continue
 				}
-
-				key := tunnelKey{httproute.Namespace, serviceName, strconv.Itoa(int(servicePort))}
+				serviceUID, servicePort, protocol, appProtocol, err := d.getTunnelBackendFromGateway(backendRef.BackendRef, namespace)
+				if err != nil {
+					d.log.Error(err, "could not find port for service", "namespace", namespace, "service", serviceName)
+				}
+				key := tunnelKey{namespace, serviceName, strconv.Itoa(int(servicePort))}
 				tunnel, found := tunnels[key]
 				if !found {
-					targetAddr := fmt.Sprintf("%s.%s.%s:%d", serviceName, key.namespace, clusterDomain, servicePort)
+					targetAddr := d.serviceTargetAddr(serviceName, key.namespace, servicePort)
 					tunnel = ingressv1alpha1.Tunnel{
 						ObjectMeta: metav1.ObjectMeta{
 							GenerateName:    fmt.Sprintf("%s-%d-", serviceName, servicePort),
-							Namespace:       httproute.Namespace,
+							Namespace:       namespace,
 							OwnerReferences: nil, // fill owner references below
 							Labels:          d.tunnelLabels(serviceName, servicePort),
 						},
 						Spec: ingressv1alpha1.TunnelSpec{
 							ForwardsTo: targetAddr,
-							Labels:     d.ngrokLabels(httproute.Namespace, serviceUID, serviceName, servicePort),
+							Labels:     d.ngrokLabels(namespace, serviceUID, serviceName, servicePort),
+							Metadata:   d.gatewayMetadata,
 							BackendConfig: &ingressv1alpha1.BackendConfig{
-								Protocol: protocol,
+								Protocol:                   protocol,
+								MaxConnections:             d.serviceMaxConnections(serviceName, namespace),
+								KeepAliveInterval:          d.serviceKeepAliveInterval(serviceName, namespace),
+								InsecureSkipVerify:         d.serviceUpstreamTLSSkipVerify(serviceName, namespace),
+								ServerName:                 d.serviceAnnotation(serviceName, namespace, annotations.ExtractUpstreamTLSServerNameFromAnnotations),
+								CertificateAuthoritySecret: d.serviceAnnotation(serviceName, namespace, annotations.ExtractUpstreamTLSCASecretFromAnnotations),
 							},
 							AppProtocol: appProtocol,
 						},
 					}
 				}
 
-				hasReference := false
+				if isGRPCRoute(httproute) {
+					// the service is also the backend of a GRPCRoute, so it has to be reached over HTTP/2
+					tunnel.Spec.AppProtocol = grpcAppProtocol
+				}
+				for _, ref := range httproute.Spec.ParentRefs {
+					if gtw := d.parentGateway(ref, httproute.Namespace); gtw != nil {
+						tunnel.Spec.Metadata = d.metadataWithLabels(tunnel.Spec.Metadata, gatewayInfrastructureLabels(gtw))
+					}
+				}
+
+				// owner references can't cross namespaces, so only routes in the tunnel's namespace own it
+				hasReference := httproute.Namespace != namespace
 				for _, ref := range tunnel.OwnerReferences {
 					if ref.UID == httproute.UID {
 						hasReference = true
@@ -1635,7 +2635,7 @@ func (d *Driver) calculateIngressLoadBalancerIPStatus(ing *netv1.Ingress, c clie
 	hostnames := make(map[string]netv1.IngressLoadBalancerIngress)
 	for _, domain := range domains.Items {
 		for _, rule := range ing.Spec.Rules {
-			if rule.Host == domain.Spec.Domain && domain.Status.CNAMETarget != nil {
+			if d.ingressEdgeHost(ing, rule.Host) == domain.Spec.Domain && domain.Status.CNAMETarget != nil {
 				hostnames[domain.Spec.Domain] = netv1.IngressLoadBalancerIngress{
 					Hostname: *domain.Status.CNAMETarget,
 				}
@@ -1658,10 +2658,9 @@ func (d *Driver) getEdgeBackend(backendSvc netv1.IngressServiceBackend, namespac
 	return string(service.UID), servicePort.Port, nil
 }
 
+// getEdgeBackendRef returns the UID and port of the service a backendRef references in the namespace, which is
+// the namespace backendRefNamespace returns for it
 func (d *Driver) getEdgeBackendRef(backendRef gatewayv1.BackendRef, namespace string) (string, int32, error) {
-	if backendRef.Namespace != nil && string(*backendRef.Namespace) != namespace {
-		return "", 0, fmt.Errorf("namespace %s not supported", string(*backendRef.Namespace))
-	}
 	service, servicePort, err := d.findBackendRefServicePort(backendRef, namespace)
 	if err != nil {
 		return "", 0, err
@@ -1690,6 +2689,9 @@ func (d *Driver) findBackendRefServicesPort(service *corev1.Service, backendRef
 			return &port, nil
 		}
 	}
+	if port, ok := externalNameServicePort(service, int32(*backendRef.Port)); ok {
+		return port, nil
+	}
 	return nil, fmt.Errorf("could not find matching port for service %s, backend port %v, name %s", service.Name, int32(*backendRef.Port), string(backendRef.Name))
 }
 
@@ -1746,15 +2748,34 @@ func (d *Driver) findBackendServicePort(backendSvc netv1.IngressServiceBackend,
 }
 
 func (d *Driver) findServicesPort(service *corev1.Service, backendSvcPort netv1.ServiceBackendPort) (*corev1.ServicePort, error) {
-	for _, port := range service.Spec.Ports {
-		if (backendSvcPort.Number > 0 && port.Port == backendSvcPort.Number) || port.Name == backendSvcPort.Name {
-			d.log.V(3).Info("Found matching port for service", "namespace", service.Namespace, "service", service.Name, "port.name", port.Name, "port.number", port.Port)
-			return &port, nil
-		}
+	if port, ok := findServicePort(service, backendSvcPort); ok {
+		d.log.V(3).Info("Found matching port for service", "namespace", service.Namespace, "service", service.Name, "port.name", port.Name, "port.number", port.Port)
+		return port, nil
 	}
 	return nil, fmt.Errorf("could not find matching port for service %s, backend port %v, name %s", service.Name, backendSvcPort.Number, backendSvcPort.Name)
 }
 
+// externalNameServicePort returns the port for a backend port number that an ExternalName service doesn't list.
+// ExternalName services are only an alias for a DNS name, so they often don't declare their ports, and any port
+// number on the external host is valid.
+func externalNameServicePort(service *corev1.Service, number int32) (*corev1.ServicePort, bool) {
+	if service.Spec.Type != corev1.ServiceTypeExternalName || number <= 0 {
+		return nil, false
+	}
+	return &corev1.ServicePort{Port: number, Protocol: corev1.ProtocolTCP}, true
+}
+
+// serviceTargetAddr returns the address tunnels forward to for a port of a backend service. Traffic for
+// ExternalName services goes straight to their external DNS name, and traffic for any other service goes
+// to the service's cluster DNS name.
+func (d *Driver) serviceTargetAddr(serviceName, namespace string, port int32) string {
+	service, err := d.store.GetServiceV1(serviceName, namespace)
+	if err == nil && service.Spec.Type == corev1.ServiceTypeExternalName && service.Spec.ExternalName != "" {
+		return net.JoinHostPort(strings.TrimSuffix(service.Spec.ExternalName, "."), strconv.Itoa(int(port)))
+	}
+	return fmt.Sprintf("%s.%s.%s:%d", serviceName, namespace, clusterDomain, port)
+}
+
 func (d *Driver) getPortAnnotatedProtocol(service *corev1.Service, portName string) (string, error) {
 	if service.Annotations != nil {
 		annotation := service.Annotations["k8s.ngrok.com/app-protocols"]
@@ -1800,8 +2821,58 @@ func (d *Driver) edgeLabels(domain string) map[string]string {
 	return map[string]string{
 		labelControllerNamespace: d.managerName.Namespace,
 		labelControllerName:      d.managerName.Name,
-		labelDomain:              domain,
+		labelDomain:              domainLabelValue(domain),
+	}
+}
+
+// ingressEdgeHost returns the host of the edge that serves an ingress rule's host. By default every host
+// gets its own edge, but ingresses annotated with k8s.ngrok.com/wildcard-edge share a single wildcard
+// edge for all of the subdomains of each parent domain.
+func (d *Driver) ingressEdgeHost(ing *netv1.Ingress, host string) string {
+	useWildcard, err := annotations.ExtractUseWildcardEdgeFromAnnotations(ing)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			d.log.Error(err, "error reading wildcard edge annotation", "ingress", ing.Name, "namespace", ing.Namespace)
+		}
+		return host
+	}
+	if !useWildcard {
+		return host
+	}
+	return wildcardHost(host)
+}
+
+// wildcardHost returns the wildcard domain covering a host, e.g. "*.example.com" for "foo.example.com".
+// Hosts that are already wildcards or apex domains are returned as is.
+func wildcardHost(host string) string {
+	if strings.HasPrefix(host, "*.") {
+		return host
+	}
+	_, parent, found := strings.Cut(host, ".")
+	if !found || !strings.Contains(parent, ".") {
+		return host
+	}
+	return "*." + parent
+}
+
+// domainResourceName returns a valid kubernetes resource name for a domain. The dots of a domain are replaced
+// with dashes, so only a wildcard domain keeps the dot after its leading "wildcard" label, e.g. "*.example.com"
+// is "wildcard.example-com". That way it can't collide with the name of a host like "wildcard.example.com".
+func domainResourceName(domain string) string {
+	if parent, ok := strings.CutPrefix(domain, "*."); ok {
+		return "wildcard." + domainResourceName(parent)
+	}
+	return strings.Replace(domain, ".", "-", -1)
+}
+
+// domainLabelValue returns a valid label value for a domain, since label values can't contain wildcards. The
+// leading "*." of a wildcard domain is replaced with "wildcard_", e.g. "wildcard_example.com" for
+// "*.example.com", which can't collide with a host since hosts can't contain underscores.
+func domainLabelValue(domain string) string {
+	if parent, ok := strings.CutPrefix(domain, "*."); ok {
+		return "wildcard_" + parent
 	}
+	return domain
 }
 
 func (d *Driver) tunnelLabels(serviceName string, port int32) map[string]string {
@@ -3,22 +3,32 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"math/rand"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
 )
 
 const defaultManagerName = "ngrok-ingress-controller"
@@ -30,7 +40,9 @@ var _ = Describe("Driver", func() {
 	cname := "cnametarget.com"
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1beta1.AddToScheme(scheme))
 	BeforeEach(func() {
 		// create a fake logger to pass into the cachestore
 		logger := logr.New(logr.Discard().GetSink())
@@ -72,6 +84,21 @@ var _ = Describe("Driver", func() {
 				Expect(foundObj).To(Equal(obj))
 			}
 		})
+		It("Should only add objects in the watched namespaces", func() {
+			driver.WithWatchNamespaces("test-namespace")
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i2 := NewTestIngressV1("test-ingress", "kube-system")
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&i1, &i2, &ic1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+			for obj, watched := range map[runtime.Object]bool{&i1: true, &i2: false, &ic1: true} {
+				_, found, err := driver.store.Get(obj)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(Equal(watched))
+			}
+		})
 	})
 
 	Describe("DeleteIngress", func() {
@@ -117,6 +144,33 @@ var _ = Describe("Driver", func() {
 				Expect(tunnels.Items).To(HaveLen(0))
 			})
 		})
+		Context("When the store's informers haven't synced", func() {
+			It("Should wait for them before syncing", func() {
+				i1 := NewTestIngressV1("test-ingress", "test-namespace")
+				ic1 := NewTestIngressClass("test-ingress-class", true, true)
+				s := NewTestServiceV1("example", "test-namespace")
+				obs := []runtime.Object{&ic1, &i1, &s}
+				c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+				Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+				var synced atomic.Bool
+				driver.WithCacheSynced(synced.Load)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				err := driver.Sync(ctx, c)
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+
+				domains := &ingressv1alpha1.DomainList{}
+				Expect(c.List(context.Background(), domains)).To(Succeed())
+				Expect(domains.Items).To(BeEmpty())
+
+				synced.Store(true)
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+				Expect(c.List(context.Background(), domains)).To(Succeed())
+				Expect(domains.Items).To(HaveLen(1))
+			})
+		})
 		Context("When there are just ingresses and CRDs need to be created", func() {
 			It("Should create the CRDs", func() {
 				i1 := NewTestIngressV1("test-ingress", "test-namespace")
@@ -124,11 +178,12 @@ var _ = Describe("Driver", func() {
 				ic1 := NewTestIngressClass("test-ingress-class", true, true)
 				ic2 := NewTestIngressClass("test-ingress-class-2", true, true)
 				s := NewTestServiceV1("example", "test-namespace")
-				obs := []runtime.Object{&ic1, &ic2, &i1, &i2, &s}
+				d1 := NewReservedDomainV1("example.com", "test-namespace")
+				obs := []runtime.Object{&ic1, &ic2, &i1, &i2, &s, &d1}
 				c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
 
 				for _, obj := range obs {
-					err := driver.store.Update(obj)
+					_, err := driver.store.Update(obj)
 					Expect(err).ToNot(HaveOccurred())
 				}
 				err := driver.Seed(context.Background(), c)
@@ -166,204 +221,1096 @@ var _ = Describe("Driver", func() {
 				Expect(foundTunnel.Labels["k8s.ngrok.com/controller-name"]).To(Equal(defaultManagerName))
 			})
 		})
+		Context("When the domain has fields set by users", func() {
+			It("Should keep them", func() {
+				i1 := NewTestIngressV1("test-ingress", "test-namespace")
+				ic1 := NewTestIngressClass("test-ingress-class", true, true)
+				s := NewTestServiceV1("example", "test-namespace")
+				d1 := NewReservedDomainV1("example.com", "test-namespace")
+				d1.Spec.Region = "eu"
+				d1.Spec.DeletionProtection = true
+				d1.Spec.MetadataMap = map[string]string{"team": "edge"}
+				d1.Spec.CertificateManagementPolicy = &ingressv1alpha1.DomainCertificateManagementPolicy{}
+				obs := []runtime.Object{&ic1, &i1, &s, &d1}
+				c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+				Expect(driver.Seed(context.Background(), c)).To(Succeed())
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+				foundDomain := &ingressv1alpha1.Domain{}
+				Expect(c.Get(context.Background(), types.NamespacedName{
+					Namespace: "test-namespace",
+					Name:      "example-com",
+				}, foundDomain)).To(Succeed())
+				Expect(foundDomain.Spec.Domain).To(Equal("example.com"))
+				Expect(foundDomain.Spec.Region).To(Equal("eu"))
+				Expect(foundDomain.Spec.DeletionProtection).To(BeTrue())
+				Expect(foundDomain.Spec.MetadataMap).To(Equal(map[string]string{"team": "edge"}))
+				Expect(foundDomain.Spec.CertificateManagementPolicy).ToNot(BeNil())
+			})
+		})
+		Context("When ingresses use multiple subdomains of the same domain", func() {
+			var c client.Client
+			var i1, i2 netv1.Ingress
+			// reservedHosts are the hosts whose domains are reserved
+			var reservedHosts []string
+
+			BeforeEach(func() {
+				i1 = NewTestIngressV1("test-ingress", "test-namespace")
+				i1.Spec.Rules[0].Host = "foo.example.com"
+				i2 = NewTestIngressV1("test-ingress-2", "test-namespace")
+				i2.Spec.Rules[0].Host = "bar.example.com"
+				reservedHosts = []string{"foo.example.com", "bar.example.com"}
+			})
+
+			JustBeforeEach(func() {
+				ic1 := NewTestIngressClass("test-ingress-class", true, true)
+				s := NewTestServiceV1("example", "test-namespace")
+				obs := []runtime.Object{&ic1, &i1, &i2, &s}
+				for _, host := range reservedHosts {
+					d := NewReservedDomainV1(host, "test-namespace")
+					obs = append(obs, &d)
+				}
+				c = fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+				Expect(driver.Seed(context.Background(), c)).To(Succeed())
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+			})
+
+			It("Should create an edge per host by default", func() {
+				foundDomains := &ingressv1alpha1.DomainList{}
+				Expect(c.List(context.Background(), foundDomains)).To(Succeed())
+				Expect(foundDomains.Items).To(HaveLen(2))
+
+				foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+				Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+				Expect(foundEdges.Items).To(HaveLen(2))
+				hostports := []string{}
+				for _, edge := range foundEdges.Items {
+					hostports = append(hostports, edge.Spec.Hostports...)
+				}
+				Expect(hostports).To(ConsistOf("foo.example.com:443", "bar.example.com:443"))
+			})
+
+			Context("When the ingresses are annotated to use a wildcard edge", func() {
+				BeforeEach(func() {
+					i1.SetAnnotations(map[string]string{"k8s.ngrok.com/wildcard-edge": "true"})
+					i2.SetAnnotations(map[string]string{"k8s.ngrok.com/wildcard-edge": "true"})
+					i2.Spec.Rules[0].HTTP.Paths[0].Path = "/bar"
+					reservedHosts = []string{"*.example.com"}
+				})
+
+				It("Should create a single wildcard edge for all of the hosts", func() {
+					foundDomain := &ingressv1alpha1.Domain{}
+					Expect(c.Get(context.Background(), types.NamespacedName{
+						Namespace: "test-namespace",
+						Name:      "wildcard.example-com",
+					}, foundDomain)).To(Succeed())
+					Expect(foundDomain.Spec.Domain).To(Equal("*.example.com"))
+
+					foundDomains := &ingressv1alpha1.DomainList{}
+					Expect(c.List(context.Background(), foundDomains)).To(Succeed())
+					Expect(foundDomains.Items).To(HaveLen(1))
+
+					foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+					Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+					Expect(foundEdges.Items).To(HaveLen(1))
+					foundEdge := foundEdges.Items[0]
+					Expect(foundEdge.Spec.Hostports).To(Equal([]string{"*.example.com:443"}))
+					Expect(foundEdge.Name).To(HavePrefix("wildcard.example-com-"))
+					Expect(foundEdge.Labels["k8s.ngrok.com/domain"]).To(Equal("wildcard_example.com"))
+					Expect(foundEdge.Spec.Routes).To(HaveLen(2))
+				})
+
+				Context("When the hosts use the same path", func() {
+					BeforeEach(func() {
+						i2.Spec.Rules[0].HTTP.Paths[0].Path = i1.Spec.Rules[0].HTTP.Paths[0].Path
+					})
+
+					It("Should only route the path once", func() {
+						foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+						Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+						Expect(foundEdges.Items).To(HaveLen(1))
+						Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+					})
+				})
+
+				Context("When another ingress uses a host named wildcard", func() {
+					BeforeEach(func() {
+						i2.SetAnnotations(nil)
+						i2.Spec.Rules[0].Host = "wildcard.example.com"
+						reservedHosts = []string{"*.example.com", "wildcard.example.com"}
+					})
+
+					It("Should keep the host apart from the wildcard domain", func() {
+						Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+						foundDomains := &ingressv1alpha1.DomainList{}
+						Expect(c.List(context.Background(), foundDomains)).To(Succeed())
+						Expect(foundDomains.Items).To(HaveLen(2))
+
+						foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+						Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+						hostports := []string{}
+						for _, edge := range foundEdges.Items {
+							hostports = append(hostports, edge.Spec.Hostports...)
+						}
+						Expect(hostports).To(ConsistOf("*.example.com:443", "wildcard.example.com:443"))
+					})
+				})
+
+				It("Should keep the existing wildcard edge on the next sync", func() {
+					Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+					foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+					Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+					Expect(foundEdges.Items).To(HaveLen(1))
+				})
+			})
+		})
 	})
 
-	Describe("calculateIngressLoadBalancerIPStatus", func() {
-		It("Should return the correct status", func() {
+	Describe("upstream TLS skip verify", func() {
+		DescribeTable("calculateTunnelsFromIngress", func(annotated bool, verify bool, expected bool) {
 			i1 := NewTestIngressV1("test-ingress", "test-namespace")
-			i1.Spec = netv1.IngressSpec{
-				Rules: []netv1.IngressRule{
-					{
-						Host: "test-domain.com",
-					},
-				},
+			i2 := NewTestIngressV1("test-ingress-2", "test-namespace")
+			i2.Spec.Rules[0].Host = "other.example.com"
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			s.Annotations = map[string]string{"k8s.ngrok.com/app-protocols": `{"http":"HTTPS"}`}
+			if annotated {
+				s.Annotations["k8s.ngrok.com/upstream-tls-skip-verify"] = "true"
 			}
-			domainList := &ingressv1alpha1.DomainList{
-				Items: []ingressv1alpha1.Domain{
-					{
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname,
-						},
-					},
-				},
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&i2)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
+			driver.WithUpstreamTLSVerification(verify)
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.Spec.BackendConfig.Protocol).To(Equal("HTTPS"))
+				Expect(tunnel.Spec.BackendConfig.InsecureSkipVerify).To(Equal(expected))
 			}
-			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+		},
+			Entry("neither the annotation nor verification", false, false, true),
+			Entry("only the annotation", true, false, true),
+			Entry("only verification", false, true, false),
+			Entry("both the annotation and verification", true, true, true),
+		)
 
-			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
-			Expect(len(status)).To(Equal(1))
-			Expect(status[0].Hostname).To(Equal(cname))
+		It("Should not be changed by an ingress annotation", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.SetAnnotations(map[string]string{"k8s.ngrok.com/upstream-tls-skip-verify": "true"})
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
+			driver.WithUpstreamTLSVerification(true)
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.Spec.BackendConfig.InsecureSkipVerify).To(BeFalse())
+			}
 		})
+	})
 
-		It("Should return empty status if no matching domain found", func() {
+	Describe("TLS conflicts", func() {
+		DescribeTable("ingressTLSConflicts", func(tls []netv1.IngressTLS, status ingressv1alpha1.DomainStatus, expected []string) {
 			i1 := NewTestIngressV1("test-ingress", "test-namespace")
-			i1.Spec = netv1.IngressSpec{
-				Rules: []netv1.IngressRule{
-					{
-						Host: "test-domain.com",
-					},
-				},
+			i1.Spec.TLS = tls
+			domain := ingressv1alpha1.Domain{
+				ObjectMeta: metav1.ObjectMeta{Name: "example-com", Namespace: "test-namespace"},
+				Spec:       ingressv1alpha1.DomainSpec{Domain: "example.com"},
+				Status:     status,
 			}
-			domainList := &ingressv1alpha1.DomainList{
-				Items: []ingressv1alpha1.Domain{
-					{
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "another-domain.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname,
-						},
-					},
-				},
+			Expect(driver.store.Update(&domain)).Error().To(Succeed())
+
+			Expect(driver.ingressTLSConflicts(&i1)).To(Equal(expected))
+		},
+			Entry("ngrok managed TLS with a manually uploaded certificate",
+				[]netv1.IngressTLS{{Hosts: []string{"example.com"}}},
+				ingressv1alpha1.DomainStatus{ID: "rd_123", Certificate: &ingressv1alpha1.DomainStatusCertificate{ID: "cert_123"}},
+				[]string{"example.com"},
+			),
+			Entry("ngrok managed TLS with a certificate management policy",
+				[]netv1.IngressTLS{{Hosts: []string{"example.com"}}},
+				ingressv1alpha1.DomainStatus{ID: "rd_123", CertificateManagementPolicy: &ingressv1alpha1.DomainStatusCertificateManagementPolicy{Authority: "letsencrypt"}},
+				[]string{},
+			),
+			Entry("ngrok managed TLS with an ngrok domain",
+				[]netv1.IngressTLS{{Hosts: []string{"example.com"}}},
+				ingressv1alpha1.DomainStatus{ID: "rd_123"},
+				[]string{},
+			),
+			Entry("a TLS secret with a manually uploaded certificate",
+				[]netv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}},
+				ingressv1alpha1.DomainStatus{ID: "rd_123", Certificate: &ingressv1alpha1.DomainStatusCertificate{ID: "cert_123"}},
+				[]string{},
+			),
+			Entry("no TLS with a manually uploaded certificate",
+				nil,
+				ingressv1alpha1.DomainStatus{ID: "rd_123", Certificate: &ingressv1alpha1.DomainStatusCertificate{ID: "cert_123"}},
+				[]string{},
+			),
+			Entry("ngrok managed TLS for another host",
+				[]netv1.IngressTLS{{Hosts: []string{"other.example.com"}}},
+				ingressv1alpha1.DomainStatus{ID: "rd_123", Certificate: &ingressv1alpha1.DomainStatusCertificate{ID: "cert_123"}},
+				[]string{},
+			),
+		)
+	})
+
+	Describe("max connections", func() {
+		DescribeTable("calculateTunnelsFromIngress", func(annotation string, expected int32) {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i2 := NewTestIngressV1("test-ingress-2", "test-namespace")
+			i1.UID = "ingress-1"
+			i2.UID = "ingress-2"
+			i2.Spec.Rules[0].Host = "other.example.com"
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			if annotation != "" {
+				s.Annotations = map[string]string{"k8s.ngrok.com/max-connections": annotation}
 			}
-			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&i2)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			// both ingresses share the tunnel, and so the limit, for the service
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.OwnerReferences).To(HaveLen(2))
+				Expect(tunnel.Spec.BackendConfig.MaxConnections).To(Equal(expected))
+			}
+		},
+			Entry("no annotation", "", int32(0)),
+			Entry("a positive limit", "10", int32(10)),
+			Entry("a zero limit", "0", int32(0)),
+			Entry("a negative limit", "-1", int32(0)),
+			Entry("an invalid limit", "lots", int32(0)),
+		)
+	})
 
-			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
-			Expect(len(status)).To(Equal(0))
-		})
+	Describe("backend keepalive interval", func() {
+		DescribeTable("calculateTunnelsFromIngress", func(defaultInterval time.Duration, annotation string, expected *metav1.Duration) {
+			driver.WithBackendKeepAliveInterval(defaultInterval)
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			if annotation != "" {
+				s.Annotations = map[string]string{"k8s.ngrok.com/backend-keepalive-interval": annotation}
+			}
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
 
-		It("Should return empty status if domain CNAME target is nil", func() {
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.Spec.BackendConfig.KeepAliveInterval).To(Equal(expected))
+			}
+		},
+			Entry("no default or annotation", time.Duration(0), "", nil),
+			Entry("the default", 30*time.Second, "", &metav1.Duration{Duration: 30 * time.Second}),
+			Entry("the annotation", time.Duration(0), "1m", &metav1.Duration{Duration: time.Minute}),
+			Entry("the annotation overriding the default", 30*time.Second, "10s", &metav1.Duration{Duration: 10 * time.Second}),
+			Entry("an interval under a second", 30*time.Second, "500ms", &metav1.Duration{Duration: 30 * time.Second}),
+			Entry("an invalid interval", time.Duration(0), "often", nil),
+		)
+	})
+
+	Describe("upstream TLS", func() {
+		It("Should configure the tunnel's backend TLS from the service's annotations", func() {
 			i1 := NewTestIngressV1("test-ingress", "test-namespace")
-			i1.Spec = netv1.IngressSpec{
-				Rules: []netv1.IngressRule{
-					{
-						Host: "test-domain.com",
-					},
-				},
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			s.Spec.Ports[0].Name = "https"
+			s.Annotations = map[string]string{
+				"k8s.ngrok.com/app-protocols":            `{"https": "HTTPS"}`,
+				"k8s.ngrok.com/upstream-tls-server-name": "api.internal.example.com",
+				"k8s.ngrok.com/upstream-tls-ca-secret":   "backend-ca",
 			}
-			domainList := &ingressv1alpha1.DomainList{
-				Items: []ingressv1alpha1.Domain{
-					{
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: nil,
-						},
-					},
-				},
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.Spec.BackendConfig.Protocol).To(Equal("HTTPS"))
+				Expect(tunnel.Spec.BackendConfig.ServerName).To(Equal("api.internal.example.com"))
+				Expect(tunnel.Spec.BackendConfig.CertificateAuthoritySecret).To(Equal("backend-ca"))
 			}
-			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+		})
 
-			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
-			Expect(len(status)).To(Equal(0))
+		It("Should verify against the system's certificate authorities without annotations", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+			Expect(driver.store.Update(&s)).Error().To(Succeed())
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(tunnel.Spec.BackendConfig.ServerName).To(BeEmpty())
+				Expect(tunnel.Spec.BackendConfig.CertificateAuthoritySecret).To(BeEmpty())
+			}
 		})
+	})
 
-		It("Should return multiple statuses for multiple different domains", func() {
-			cname1 := "cnametarget1.com"
-			cname2 := "cnametarget2.com"
+	Describe("labels annotation", func() {
+		decodeMetadata := func(metadata string) map[string]string {
+			m := map[string]string{}
+			Expect(json.Unmarshal([]byte(metadata), &m)).To(Succeed())
+			return m
+		}
+
+		DescribeTable("Sync", func(annotation string, expected map[string]string) {
+			driver.WithMetaData(map[string]string{"env": "prod"})
 			i1 := NewTestIngressV1("test-ingress", "test-namespace")
-			i1.Spec = netv1.IngressSpec{
-				Rules: []netv1.IngressRule{
-					{
-						Host: "test-domain1.com",
-					},
-					{
-						Host: "test-domain2.com",
-					},
-				},
+			if annotation != "" {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/labels": annotation})
 			}
-			domainList := &ingressv1alpha1.DomainList{
-				Items: []ingressv1alpha1.Domain{
-					{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "test-domain1.com",
-						},
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain1.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname1,
-						},
-					},
-					{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "test-domain2.com",
-						},
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain2.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname2,
-						},
-					},
-				},
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic1, &i1, &s, &d1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+			Expect(decodeMetadata(foundEdges.Items[0].Spec.Metadata)).To(Equal(expected))
+			Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+			Expect(decodeMetadata(foundEdges.Items[0].Spec.Routes[0].Metadata)).To(Equal(expected))
+
+			foundTunnels := &ingressv1alpha1.TunnelList{}
+			Expect(c.List(context.Background(), foundTunnels)).To(Succeed())
+			Expect(foundTunnels.Items).To(HaveLen(1))
+			Expect(decodeMetadata(foundTunnels.Items[0].Spec.Metadata)).To(Equal(expected))
+		},
+			Entry("no annotation", "",
+				map[string]string{"env": "prod", "owned-by": "kubernetes-ingress-controller"}),
+			Entry("labels", "team=payments,cost-center=1234",
+				map[string]string{"env": "prod", "owned-by": "kubernetes-ingress-controller", "team": "payments", "cost-center": "1234"}),
+			Entry("labels conflicting with the controller's", "env=dev,owned-by=payments,team=payments",
+				map[string]string{"env": "prod", "owned-by": "kubernetes-ingress-controller", "team": "payments"}),
+			Entry("invalid labels", "team=payments,not a label",
+				map[string]string{"env": "prod", "owned-by": "kubernetes-ingress-controller"}),
+		)
+	})
+
+	Describe("When multiple ingresses share the same host", func() {
+		var ingresses []*netv1.Ingress
+
+		BeforeEach(func() {
+			t0 := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			t1 := metav1.NewTime(t0.Add(time.Hour))
+			newIngress := func(name, path string, created metav1.Time) *netv1.Ingress {
+				ing := NewTestIngressV1(name, "test-namespace")
+				ing.CreationTimestamp = created
+				ing.Spec.Rules[0].HTTP.Paths[0].Path = path
+				return &ing
 			}
-			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
 
-			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
-			Expect(status).Should(ConsistOf(
-				HaveField("Hostname", cname1),
-				HaveField("Hostname", cname2),
-			))
+			ingresses = []*netv1.Ingress{
+				newIngress("d-ingress", "/", t1),
+				newIngress("b-ingress", "/baz", t1),
+				newIngress("a-ingress", "/bar", t1),
+				newIngress("z-ingress", "/foo", t0),
+				newIngress("c-ingress", "/longer-path", t1),
+			}
 		})
 
-		It("Should only have a single status for multiple domains that match", func() {
-			cname1 := "cnametarget1.com"
-			cname2 := "cnametarget2.com"
-			i1 := NewTestIngressV1("test-ingress", "test-namespace")
-			i1.Spec = netv1.IngressSpec{
-				Rules: []netv1.IngressRule{
-					{
-						Host: "test-domain1.com",
-					},
-					{
-						Host: "test-domain1.com",
-					},
-				},
+		expectedOrder := []string{"/longer-path", "/foo", "/bar", "/baz", "/"}
+
+		It("Should order the routes by match length, then creation timestamp, then name", func() {
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			obs := []runtime.Object{&ic1, &svc, &d1}
+			for _, ing := range ingresses {
+				obs = append(obs, ing)
 			}
-			domainList := &ingressv1alpha1.DomainList{
-				Items: []ingressv1alpha1.Domain{
-					{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "test-domain1.com",
-						},
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain1.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname1,
-						},
-					},
-					{
-						ObjectMeta: metav1.ObjectMeta{
-							Name: "test-domain2.com",
-						},
-						Spec: ingressv1alpha1.DomainSpec{
-							Domain: "test-domain2.com",
-						},
-						Status: ingressv1alpha1.DomainStatus{
-							CNAMETarget: &cname2,
-						},
-					},
-				},
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+
+			matches := []string{}
+			for _, route := range foundEdges.Items[0].Spec.Routes {
+				matches = append(matches, route.Match)
 			}
-			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+			Expect(matches).To(Equal(expectedOrder))
+		})
 
-			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
-			Expect(status).Should(ConsistOf(
-				HaveField("Hostname", cname1),
-			))
+		It("Should yield the same route order regardless of the order of the ingresses", func() {
+			r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			for i := 0; i < 10; i++ {
+				routes := []ingressRoute{}
+				for _, ing := range ingresses {
+					routes = append(routes, ingressRoute{
+						route:   ingressv1alpha1.HTTPSEdgeRouteSpec{Match: ing.Spec.Rules[0].HTTP.Paths[0].Path},
+						ingress: ing,
+					})
+				}
+				r.Shuffle(len(routes), func(i, j int) { routes[i], routes[j] = routes[j], routes[i] })
+
+				sortIngressRoutes(routes)
+
+				matches := []string{}
+				for _, route := range routes {
+					matches = append(matches, route.route.Match)
+				}
+				Expect(matches).To(Equal(expectedOrder))
+			}
 		})
 	})
 
-	Describe("getNgrokModuleSetForIngress", func() {
-		var ms1, ms2, ms3 *ingressv1alpha1.NgrokModuleSet
+	Describe("When an ingress's backend service is deleted", func() {
+		var c client.Client
+		var i1 netv1.Ingress
+		var svc corev1.Service
 
 		BeforeEach(func() {
-			ms1 = &ingressv1alpha1.NgrokModuleSet{
-				ObjectMeta: metav1.ObjectMeta{Name: "ms1", Namespace: "test"},
-				Modules: ingressv1alpha1.NgrokModuleSetModules{
-					Compression: &ingressv1alpha1.EndpointCompression{
-						Enabled: true,
-					},
-				},
-			}
-			ms2 = &ingressv1alpha1.NgrokModuleSet{
-				ObjectMeta: metav1.ObjectMeta{Name: "ms2", Namespace: "test"},
-				Modules: ingressv1alpha1.NgrokModuleSetModules{
+			i1 = NewTestIngressV1("test-ingress", "test-namespace")
+		})
+
+		JustBeforeEach(func() {
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			svc = NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			obs := []runtime.Object{&ic1, &i1, &svc, &d1}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			Expect(c.Delete(context.Background(), &svc)).To(Succeed())
+			Expect(driver.store.Delete(&svc)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+		})
+
+		It("Should list the ingress for the service", func() {
+			ings := driver.ListNgrokIngressesForService("example", "test-namespace")
+			Expect(ings).To(HaveLen(1))
+			Expect(ings[0].Name).To(Equal("test-ingress"))
+
+			Expect(driver.ListNgrokIngressesForService("other", "test-namespace")).To(BeEmpty())
+		})
+
+		It("Should keep the edge serving a 503 by default", func() {
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+
+			routes := foundEdges.Items[0].Spec.Routes
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Policy).ToNot(BeNil())
+			Expect(string(routes[0].Policy)).To(ContainSubstring("custom-response"))
+			Expect(string(routes[0].Policy)).To(ContainSubstring("503"))
+			Expect(routes[0].Backend.Labels).To(HaveKeyWithValue(labelPort, "80"))
+		})
+
+		Context("When the ingress's backend uses a named port", func() {
+			BeforeEach(func() {
+				i1.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = netv1.ServiceBackendPort{Name: "http"}
+			})
+
+			It("Should label the 503 route with the port's name", func() {
+				foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+				Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+				Expect(foundEdges.Items).To(HaveLen(1))
+
+				routes := foundEdges.Items[0].Spec.Routes
+				Expect(routes).To(HaveLen(1))
+				Expect(string(routes[0].Policy)).To(ContainSubstring("503"))
+				Expect(routes[0].Backend.Labels).To(HaveKeyWithValue(labelPort, "http"))
+			})
+		})
+
+		Context("When the ingress is annotated to tear down the edge", func() {
+			BeforeEach(func() {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/backend-missing-behavior": BackendMissingBehaviorTeardown})
+			})
+
+			It("Should delete the edge", func() {
+				foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+				Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+				Expect(foundEdges.Items).To(BeEmpty())
+			})
+		})
+
+		Context("When the driver is configured to tear down edges", func() {
+			BeforeEach(func() {
+				driver.WithBackendMissingBehavior(BackendMissingBehaviorTeardown)
+			})
+
+			It("Should delete the edge", func() {
+				foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+				Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+				Expect(foundEdges.Items).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Events", func() {
+		var recorder *record.FakeRecorder
+		var i1 netv1.Ingress
+		var withService bool
+		var domain ingressv1alpha1.Domain
+		var extraObjs []runtime.Object
+		var c client.Client
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(100)
+			driver.WithEventRecorder(recorder)
+			i1 = NewTestIngressV1("test-ingress", "test-namespace")
+			withService = true
+			domain = NewReservedDomainV1("example.com", "test-namespace")
+			extraObjs = nil
+		})
+
+		JustBeforeEach(func() {
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			obs := []runtime.Object{&ic1, &i1, &domain}
+			if withService {
+				s := NewTestServiceV1("example", "test-namespace")
+				obs = append(obs, &s)
+			}
+			obs = append(obs, extraObjs...)
+			c = fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(obs...).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+			driver.RecordIngressIssues()
+		})
+
+		recordedEvents := func() []string {
+			events := []string{}
+			for {
+				select {
+				case e := <-recorder.Events:
+					events = append(events, e)
+				default:
+					return events
+				}
+			}
+		}
+
+		It("Should emit EdgeCreated when an edge is created for the ingress", func() {
+			Expect(recordedEvents()).To(ContainElement(HavePrefix("Normal EdgeCreated ")))
+		})
+
+		Context("When the ingress requests ngrok managed TLS for a domain with a manually uploaded certificate", func() {
+			BeforeEach(func() {
+				i1.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{"example.com"}}}
+				domain.Status.Certificate = &ingressv1alpha1.DomainStatusCertificate{ID: "cert_123"}
+			})
+
+			It("Should warn about the conflict and still create the edge", func() {
+				events := recordedEvents()
+				Expect(events).To(ContainElement("Warning TLSConflict Host example.com requests ngrok managed TLS, but its reserved domain uses a manually uploaded certificate"))
+				Expect(events).To(ContainElement(HavePrefix("Normal EdgeCreated ")))
+			})
+		})
+
+		Context("When another ingress routes the same path on a shared wildcard edge", func() {
+			BeforeEach(func() {
+				i1.Spec.Rules[0].Host = "foo.example.com"
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/wildcard-edge": "true"})
+				i2 := NewTestIngressV1("test-ingress-2", "test-namespace")
+				i2.Spec.Rules[0].Host = "bar.example.com"
+				i2.SetAnnotations(map[string]string{"k8s.ngrok.com/wildcard-edge": "true"})
+				extraObjs = append(extraObjs, &i2)
+			})
+
+			It("Should emit RouteConflict on the ingress whose route is dropped", func() {
+				Expect(recordedEvents()).To(ContainElement("Warning RouteConflict Path / on *.example.com is already routed by ingress test-namespace/test-ingress"))
+			})
+		})
+
+		Context("When the ingress routes the same path twice", func() {
+			BeforeEach(func() {
+				i1.Spec.Rules[0].HTTP.Paths = append(i1.Spec.Rules[0].HTTP.Paths, *i1.Spec.Rules[0].HTTP.Paths[0].DeepCopy())
+			})
+
+			It("Should emit RouteConflict for the route that's dropped", func() {
+				Expect(recordedEvents()).To(ContainElement("Warning RouteConflict Path / on example.com is routed more than once by the ingress, only its first backend is used"))
+			})
+		})
+
+		Context("When a path expression's literal prefix is covered by another path", func() {
+			BeforeEach(func() {
+				expression := *i1.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+				expression.Path = "/users/[0-9]+"
+				expression.PathType = ptr.To(netv1.PathTypeImplementationSpecific)
+				i1.Spec.Rules[0].HTTP.Paths = append(i1.Spec.Rules[0].HTTP.Paths, expression)
+			})
+
+			It("Should emit RouteConflict on the ingress", func() {
+				Expect(recordedEvents()).To(ContainElement("Warning RouteConflict Path expression /users/[0-9]+ on example.com can't be routed because the requests it doesn't match belong to path / of ingress test-namespace/test-ingress"))
+			})
+		})
+
+		Context("When the ingress's module set doesn't exist", func() {
+			BeforeEach(func() {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "does-not-exist"})
+			})
+
+			It("Should emit ModuleResolutionFailed", func() {
+				Expect(recordedEvents()).To(ContainElement(HavePrefix("Warning ModuleResolutionFailed ")))
+			})
+		})
+
+		Context("When the ingress's module sets conflict", func() {
+			BeforeEach(func() {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "base,app"})
+				base := NewTestNgrokModuleSet("base", "test-namespace", false)
+				base.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+				app := NewTestNgrokModuleSet("app", "test-namespace", false)
+				app.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+				app.Modules.RateLimit.RequestsPerSecond = 50
+				Expect(driver.store.Add(&base)).To(Succeed())
+				Expect(driver.store.Add(&app)).To(Succeed())
+			})
+
+			It("Should emit ModuleResolutionFailed with the conflicting field", func() {
+				Expect(recordedEvents()).To(ContainElement(And(
+					HavePrefix("Warning ModuleResolutionFailed "),
+					ContainSubstring(`NgrokModuleSet "app" conflicts with an earlier module set`),
+					ContainSubstring("modules.rateLimit.requestsPerSecond is set to both 10 and 50"),
+				)))
+			})
+
+			Context("When the ingress uses the override merge strategy", func() {
+				BeforeEach(func() {
+					i1.Annotations["k8s.ngrok.com/modules-merge-strategy"] = "override"
+				})
+
+				It("Should let the later module set win and create the edge", func() {
+					events := recordedEvents()
+					Expect(events).ToNot(ContainElement(HavePrefix("Warning ModuleResolutionFailed ")))
+					Expect(events).To(ContainElement(HavePrefix("Normal EdgeCreated ")))
+				})
+			})
+		})
+
+		Context("When the ingress's traffic policy doesn't exist", func() {
+			BeforeEach(func() {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/traffic-policy": "does-not-exist"})
+			})
+
+			It("Should emit ModuleResolutionFailed", func() {
+				Expect(recordedEvents()).To(ContainElement(HavePrefix("Warning ModuleResolutionFailed ")))
+			})
+		})
+
+		Context("When the ingress's traffic policy is invalid", func() {
+			BeforeEach(func() {
+				i1.SetAnnotations(map[string]string{"k8s.ngrok.com/traffic-policy": "invalid"})
+				policy := NewTestNgrokTrafficPolicy("invalid", "test-namespace", `{"inbound": [{"name": "no-actions"}]}`)
+				Expect(driver.store.Add(&policy)).To(Succeed())
+			})
+
+			It("Should emit ModuleResolutionFailed", func() {
+				Expect(recordedEvents()).To(ContainElement(And(
+					HavePrefix("Warning ModuleResolutionFailed "),
+					ContainSubstring("inbound rule 0 (\"no-actions\") has no actions"),
+				)))
+			})
+		})
+
+		Context("When the ingress's backend service doesn't exist", func() {
+			BeforeEach(func() {
+				withService = false
+			})
+
+			It("Should emit BackendNotFound", func() {
+				Expect(recordedEvents()).To(ContainElement("Warning BackendNotFound Backend service test-namespace/example not found"))
+			})
+
+			It("Should only emit BackendNotFound again once the backend has been found in between", func() {
+				Expect(recordedEvents()).To(ContainElement(HavePrefix("Warning BackendNotFound ")))
+
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+				driver.RecordIngressIssues()
+				Expect(recordedEvents()).To(BeEmpty())
+
+				s := NewTestServiceV1("example", "test-namespace")
+				Expect(driver.store.Add(&s)).To(Succeed())
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+				driver.RecordIngressHandled(&i1, nil)
+				driver.RecordIngressIssues()
+				Expect(recordedEvents()).ToNot(ContainElement(HavePrefix("Warning BackendNotFound ")))
+
+				Expect(driver.store.Delete(&s)).To(Succeed())
+				Expect(driver.Sync(context.Background(), c)).To(Succeed())
+				driver.RecordIngressIssues()
+				Expect(recordedEvents()).To(ContainElement("Warning BackendNotFound Backend service test-namespace/example not found"))
+			})
+		})
+	})
+
+	Describe("GetSecretV1", func() {
+		var c client.Client
+		var gets int
+
+		BeforeEach(func() {
+			gets = 0
+			secret := NewTestSecretV1("test-secret", "test-namespace")
+			c = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&secret).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						gets++
+						return cl.Get(ctx, key, obj, opts...)
+					},
+				}).
+				Build()
+		})
+
+		It("Should cache a secret that is present", func() {
+			secret, err := driver.GetSecretV1(context.Background(), c, "test-secret", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secret.Data).To(HaveKeyWithValue("key", []byte("value")))
+
+			secret, err = driver.GetSecretV1(context.Background(), c, "test-secret", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secret.Name).To(Equal("test-secret"))
+			Expect(gets).To(Equal(1))
+		})
+
+		It("Should negatively cache a missing secret until it appears", func() {
+			_, err := driver.GetSecretV1(context.Background(), c, "missing-secret", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			_, err = driver.GetSecretV1(context.Background(), c, "missing-secret", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(gets).To(Equal(1))
+
+			secret := NewTestSecretV1("missing-secret", "test-namespace")
+			Expect(driver.store.Update(&secret)).Error().To(Succeed())
+
+			found, err := driver.GetSecretV1(context.Background(), c, "missing-secret", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.Name).To(Equal("missing-secret"))
+			Expect(gets).To(Equal(1))
+		})
+
+		It("Should look up a missing secret again once the negative cache expires", func() {
+			now := time.Now()
+			driver.cacheStores.missingSecrets.now = func() time.Time { return now }
+
+			_, err := driver.GetSecretV1(context.Background(), c, "missing-secret", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(gets).To(Equal(1))
+
+			now = now.Add(missingSecretTTL)
+			_, err = driver.GetSecretV1(context.Background(), c, "missing-secret", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(gets).To(Equal(2))
+		})
+	})
+
+	Describe("IsSecretReferenced", func() {
+		It("Should only keep the secrets referenced by module sets or already looked up", func() {
+			ms := NewTestNgrokModuleSet("test-module-set", "test-namespace", false)
+			ms.Modules.MutualTLS = NewTestNgrokModuleSetModules().MutualTLS
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			Expect(driver.IsSecretReferenced("client-ca", "test-namespace")).To(BeTrue())
+			Expect(driver.IsSecretReferenced("client-ca", "other-namespace")).To(BeFalse())
+			Expect(driver.IsSecretReferenced("test-secret", "test-namespace")).To(BeFalse())
+
+			secret := NewTestSecretV1("test-secret", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&secret).Build()
+			_, err := driver.GetSecretV1(context.Background(), c, "test-secret", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(driver.IsSecretReferenced("test-secret", "test-namespace")).To(BeTrue())
+		})
+
+		It("Should load the secrets module sets reference when they were created before the module set", func() {
+			ms := NewTestNgrokModuleSet("test-module-set", "test-namespace", false)
+			ms.Modules.MutualTLS = &ingressv1alpha1.EndpointMutualTLS{CertificateAuthoritySecrets: []string{"client-ca", "missing-ca"}}
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			secret := NewTestSecretV1("client-ca", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&secret).Build()
+
+			Expect(driver.loadModuleSetSecrets(context.Background(), c)).To(Succeed())
+			found, err := driver.store.GetSecretV1("client-ca", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.Data).To(HaveKeyWithValue("key", []byte("value")))
+			_, err = driver.store.GetSecretV1("missing-ca", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("CheckIngressDomainsReady", func() {
+		BeforeEach(func() {
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			Expect(driver.store.Update(&ic1)).Error().To(Succeed())
+		})
+
+		It("Should not be ready when the domain is not in the store", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+
+			err := driver.CheckIngressDomainsReady(&i1)
+			Expect(errors.IsNotAllDomainsReadyYet(err)).To(BeTrue())
+		})
+
+		It("Should not be ready until the domain has been reserved", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			d1.Status.ID = ""
+			Expect(driver.store.Update(&d1)).Error().To(Succeed())
+
+			err := driver.CheckIngressDomainsReady(&i1)
+			Expect(errors.IsNotAllDomainsReadyYet(err)).To(BeTrue())
+
+			d1.Status.ID = "rd_123"
+			Expect(driver.store.Update(&d1)).Error().To(Succeed())
+
+			err = driver.CheckIngressDomainsReady(&i1)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should not be ready when only a domain the controller didn't create is reserved", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			Expect(driver.store.Update(&i1)).Error().To(Succeed())
+			d1 := NewReservedDomainV1("example.com", "other-namespace")
+			Expect(driver.store.Update(&d1)).Error().To(Succeed())
+
+			err := driver.CheckIngressDomainsReady(&i1)
+			Expect(errors.IsNotAllDomainsReadyYet(err)).To(BeTrue())
+		})
+
+		It("Should ignore rules without a host", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec.Rules[0].Host = ""
+
+			err := driver.CheckIngressDomainsReady(&i1)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("calculateIngressLoadBalancerIPStatus", func() {
+		It("Should return the correct status", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec = netv1.IngressSpec{
+				Rules: []netv1.IngressRule{
+					{
+						Host: "test-domain.com",
+					},
+				},
+			}
+			domainList := &ingressv1alpha1.DomainList{
+				Items: []ingressv1alpha1.Domain{
+					{
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname,
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+
+			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
+			Expect(len(status)).To(Equal(1))
+			Expect(status[0].Hostname).To(Equal(cname))
+		})
+
+		It("Should return empty status if no matching domain found", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec = netv1.IngressSpec{
+				Rules: []netv1.IngressRule{
+					{
+						Host: "test-domain.com",
+					},
+				},
+			}
+			domainList := &ingressv1alpha1.DomainList{
+				Items: []ingressv1alpha1.Domain{
+					{
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "another-domain.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname,
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+
+			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
+			Expect(len(status)).To(Equal(0))
+		})
+
+		It("Should return empty status if domain CNAME target is nil", func() {
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec = netv1.IngressSpec{
+				Rules: []netv1.IngressRule{
+					{
+						Host: "test-domain.com",
+					},
+				},
+			}
+			domainList := &ingressv1alpha1.DomainList{
+				Items: []ingressv1alpha1.Domain{
+					{
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: nil,
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+
+			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
+			Expect(len(status)).To(Equal(0))
+		})
+
+		It("Should return multiple statuses for multiple different domains", func() {
+			cname1 := "cnametarget1.com"
+			cname2 := "cnametarget2.com"
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec = netv1.IngressSpec{
+				Rules: []netv1.IngressRule{
+					{
+						Host: "test-domain1.com",
+					},
+					{
+						Host: "test-domain2.com",
+					},
+				},
+			}
+			domainList := &ingressv1alpha1.DomainList{
+				Items: []ingressv1alpha1.Domain{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-domain1.com",
+						},
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain1.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname1,
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-domain2.com",
+						},
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain2.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname2,
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+
+			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
+			Expect(status).Should(ConsistOf(
+				HaveField("Hostname", cname1),
+				HaveField("Hostname", cname2),
+			))
+		})
+
+		It("Should only have a single status for multiple domains that match", func() {
+			cname1 := "cnametarget1.com"
+			cname2 := "cnametarget2.com"
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.Spec = netv1.IngressSpec{
+				Rules: []netv1.IngressRule{
+					{
+						Host: "test-domain1.com",
+					},
+					{
+						Host: "test-domain1.com",
+					},
+				},
+			}
+			domainList := &ingressv1alpha1.DomainList{
+				Items: []ingressv1alpha1.Domain{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-domain1.com",
+						},
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain1.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname1,
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-domain2.com",
+						},
+						Spec: ingressv1alpha1.DomainSpec{
+							Domain: "test-domain2.com",
+						},
+						Status: ingressv1alpha1.DomainStatus{
+							CNAMETarget: &cname2,
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithLists(domainList).WithScheme(scheme).Build()
+
+			status := driver.calculateIngressLoadBalancerIPStatus(&i1, c)
+			Expect(status).Should(ConsistOf(
+				HaveField("Hostname", cname1),
+			))
+		})
+	})
+
+	Describe("getNgrokModuleSetForIngress", func() {
+		var ms1, ms2, ms3 *ingressv1alpha1.NgrokModuleSet
+
+		BeforeEach(func() {
+			ms1 = &ingressv1alpha1.NgrokModuleSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms1", Namespace: "test"},
+				Modules: ingressv1alpha1.NgrokModuleSetModules{
+					Compression: &ingressv1alpha1.EndpointCompression{
+						Enabled: true,
+					},
+				},
+			}
+			ms2 = &ingressv1alpha1.NgrokModuleSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms2", Namespace: "test"},
+				Modules: ingressv1alpha1.NgrokModuleSetModules{
 					Compression: &ingressv1alpha1.EndpointCompression{
 						Enabled: false,
 					},
@@ -391,21 +1338,53 @@ var _ = Describe("Driver", func() {
 
 			ms, err := driver.getNgrokModuleSetForIngress(&ing)
 			Expect(err).To(BeNil())
-			Expect(ms.Modules.Compression).To(BeNil())
-			Expect(ms.Modules.Headers).To(BeNil())
-			Expect(ms.Modules.IPRestriction).To(BeNil())
-			Expect(ms.Modules.TLSTermination).To(BeNil())
-			Expect(ms.Modules.WebhookVerification).To(BeNil())
+			Expect(ms.Modules.Compression).To(BeNil())
+			Expect(ms.Modules.Headers).To(BeNil())
+			Expect(ms.Modules.IPRestriction).To(BeNil())
+			Expect(ms.Modules.TLSTermination).To(BeNil())
+			Expect(ms.Modules.WebhookVerification).To(BeNil())
+		})
+
+		It("Should return the matching module set if the ingress has a modules annotaion", func() {
+			ing := NewTestIngressV1("test-ingress", "test")
+			ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "ms1"})
+			Expect(driver.store.Add(&ing)).To(BeNil())
+
+			ms, err := driver.getNgrokModuleSetForIngress(&ing)
+			Expect(err).To(BeNil())
+			Expect(ms.Modules).To(Equal(ms1.Modules))
+		})
+
+		It("Should override conflicting fields with the later module set's when the strategy is override", func() {
+			ing := NewTestIngressV1("test-ingress", "test")
+			ms4 := &ingressv1alpha1.NgrokModuleSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms4", Namespace: "test"},
+				Modules: ingressv1alpha1.NgrokModuleSetModules{
+					IPRestriction: &ingressv1alpha1.EndpointIPPolicy{IPPolicies: []string{"policy3"}},
+					Compression:   &ingressv1alpha1.EndpointCompression{Enabled: true, Algorithms: []string{"br"}},
+				},
+			}
+			Expect(driver.store.Add(ms4)).To(BeNil())
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/modules":                "ms2,ms4",
+				"k8s.ngrok.com/modules-merge-strategy": "override",
+			})
+
+			ms, err := driver.getNgrokModuleSetForIngress(&ing)
+			Expect(err).To(BeNil())
+			Expect(ms.Modules.IPRestriction.IPPolicies).To(Equal([]string{"policy3"}))
+			Expect(ms.Modules.Compression).To(Equal(&ingressv1alpha1.EndpointCompression{Enabled: true, Algorithms: []string{"br"}}))
 		})
 
-		It("Should return the matching module set if the ingress has a modules annotaion", func() {
+		It("Should return an error for an unknown merge strategy", func() {
 			ing := NewTestIngressV1("test-ingress", "test")
-			ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "ms1"})
-			Expect(driver.store.Add(&ing)).To(BeNil())
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/modules":                "ms1,ms2",
+				"k8s.ngrok.com/modules-merge-strategy": "random",
+			})
 
-			ms, err := driver.getNgrokModuleSetForIngress(&ing)
-			Expect(err).To(BeNil())
-			Expect(ms.Modules).To(Equal(ms1.Modules))
+			_, err := driver.getNgrokModuleSetForIngress(&ing)
+			Expect(err).To(MatchError(ContainSubstring(`unknown module set merge strategy "random"`)))
 		})
 
 		It("merges modules with the last one winning if multiple module sets are specified", func() {
@@ -428,6 +1407,393 @@ var _ = Describe("Driver", func() {
 		})
 	})
 
+	Describe("getPathRouteModules", func() {
+		var ing netv1.Ingress
+
+		BeforeEach(func() {
+			compression := NewTestNgrokModuleSet("compression", "test", true)
+			oauth := NewTestNgrokModuleSet("oauth", "test", false)
+			oauth.Modules.OAuth = NewTestNgrokModuleSetModules().OAuth
+			rateLimit := NewTestNgrokModuleSet("rate-limit", "test", false)
+			rateLimit.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+			for _, ms := range []*ingressv1alpha1.NgrokModuleSet{&compression, &oauth, &rateLimit} {
+				Expect(driver.store.Add(ms)).To(BeNil())
+			}
+
+			ing = NewTestIngressV1("test-ingress", "test")
+			adminPath := *ing.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+			adminPath.Path = "/admin"
+			ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, adminPath)
+		})
+
+		It("Should return nothing without the path-modules annotation", func() {
+			pathModules, err := driver.getPathRouteModules(&ing)
+			Expect(err).To(BeNil())
+			Expect(pathModules).To(BeEmpty())
+		})
+
+		It("Should merge the path's module sets after the ingress's", func() {
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/modules":      "compression",
+				"k8s.ngrok.com/path-modules": `{"/admin": "oauth, rate-limit"}`,
+			})
+
+			pathModules, err := driver.getPathRouteModules(&ing)
+			Expect(err).To(BeNil())
+			Expect(pathModules).To(HaveLen(1))
+			Expect(pathModules).To(HaveKey("/admin"))
+			admin := pathModules["/admin"]
+			Expect(admin.modSet.Modules.Compression).To(Equal(&ingressv1alpha1.EndpointCompression{Enabled: true}))
+			Expect(admin.modSet.Modules.OAuth).ToNot(BeNil())
+			Expect(admin.modSet.Modules.OAuth.Google.EmailDomains).To(Equal([]string{"ngrok.com"}))
+			Expect(string(admin.policy)).To(ContainSubstring("rate-limit"))
+		})
+
+		It("Should only protect the annotated path's route", func() {
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/path-modules": `{"/admin": "oauth"}`,
+			})
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+			ingressDomains, _, gatewayDomains := driver.calculateDomains()
+			edgeMap := driver.calculateHTTPSEdges(&ingressDomains, gatewayDomains)
+			Expect(edgeMap).To(HaveLen(1))
+			for _, edge := range edgeMap {
+				Expect(edge.Spec.Routes).To(HaveLen(2))
+				for _, route := range edge.Spec.Routes {
+					if route.Match == "/admin" {
+						Expect(route.OAuth).ToNot(BeNil())
+					} else {
+						Expect(route.OAuth).To(BeNil())
+					}
+				}
+			}
+		})
+
+		It("Should return an error for a path that isn't one of the ingress's", func() {
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/path-modules": `{"/missing": "oauth"}`,
+			})
+
+			_, err := driver.getPathRouteModules(&ing)
+			Expect(err).To(MatchError(ContainSubstring(`path "/missing" doesn't match any path of the ingress`)))
+		})
+
+		It("Should return an error for a missing module set", func() {
+			ing.SetAnnotations(map[string]string{
+				"k8s.ngrok.com/path-modules": `{"/admin": "missing"}`,
+			})
+
+			_, err := driver.getPathRouteModules(&ing)
+			Expect(err).To(MatchError(ContainSubstring("path /admin:")))
+		})
+	})
+
+	Describe("path types", func() {
+		var ing netv1.Ingress
+
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test")
+			ing.Spec.Rules[0].HTTP.Paths[0].Path = "/api"
+			exact := *ing.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+			exact.Path = "/health"
+			exact.PathType = ptr.To(netv1.PathTypeExact)
+			expression := *ing.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+			expression.Path = "/users/[0-9]+/profile"
+			expression.PathType = ptr.To(netv1.PathTypeImplementationSpecific)
+			ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, exact, expression)
+		})
+
+		edgeRoutes := func() map[string]ingressv1alpha1.HTTPSEdgeRouteSpec {
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+			ingressDomains, _, gatewayDomains := driver.calculateDomains()
+			edges := driver.calculateHTTPSEdges(&ingressDomains, gatewayDomains)
+			Expect(edges).To(HaveLen(1))
+			routes := map[string]ingressv1alpha1.HTTPSEdgeRouteSpec{}
+			for _, edge := range edges {
+				for _, route := range edge.Spec.Routes {
+					routes[route.Match] = route
+				}
+			}
+			return routes
+		}
+
+		It("Should route exact paths and path expressions", func() {
+			routes := edgeRoutes()
+			Expect(routes).To(HaveLen(3))
+			Expect(routes["/api"].MatchType).To(Equal(MatchTypePathPrefix))
+			Expect(string(routes["/api"].Policy)).ToNot(ContainSubstring(pathExpressionRuleName))
+			Expect(routes["/health"].MatchType).To(Equal(MatchTypeExactPath))
+
+			Expect(routes).To(HaveKey("/users/"))
+			Expect(routes["/users/"].MatchType).To(Equal(MatchTypePathPrefix))
+			policy := ingressv1alpha1.EndpointPolicy{}
+			Expect(json.Unmarshal(routes["/users/"].Policy, &policy)).To(Succeed())
+			Expect(policy.Inbound).To(HaveLen(1))
+			Expect(policy.Inbound[0].Expressions).To(Equal([]string{`!req.url.path.matches("^(?:/users/[0-9]+/profile)")`}))
+			Expect(policy.Inbound[0].Actions[0].Type).To(Equal("custom-response"))
+			Expect(string(policy.Inbound[0].Actions[0].Config)).To(MatchJSON(`{"status_code": 404}`))
+		})
+
+		It("Should check path expressions before the route's other traffic policy rules", func() {
+			ms := NewTestNgrokModuleSet("rate-limit", "test", false)
+			ms.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+			Expect(driver.store.Add(&ms)).To(BeNil())
+			ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "rate-limit"})
+
+			policy := ingressv1alpha1.EndpointPolicy{}
+			Expect(json.Unmarshal(edgeRoutes()["/users/"].Policy, &policy)).To(Succeed())
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(policy.Inbound[0].Name).To(Equal(pathExpressionRuleName))
+		})
+
+		It("Should skip invalid path expressions", func() {
+			ing.Spec.Rules[0].HTTP.Paths[2].Path = "/users/[0-9"
+			routes := edgeRoutes()
+			Expect(routes).To(HaveLen(2))
+			Expect(routes).ToNot(HaveKey("/users/"))
+		})
+
+		It("Should only respond with a 503 to the requests of a path expression whose backend is missing", func() {
+			ing.Spec.Rules[0].HTTP.Paths[2].Backend.Service.Name = "missing"
+			policy := ingressv1alpha1.EndpointPolicy{}
+			Expect(json.Unmarshal(edgeRoutes()["/users/"].Policy, &policy)).To(Succeed())
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(policy.Inbound[0].Name).To(Equal(pathExpressionRuleName))
+			Expect(policy.Inbound[1].Name).To(Equal("Backend Unavailable"))
+		})
+
+		DescribeTable("Should not route path expressions whose requests belong to other paths",
+			func(mutate func(*netv1.Ingress), expectedRoutes ...string) {
+				mutate(&ing)
+				routes := edgeRoutes()
+				Expect(routes).To(HaveLen(len(expectedRoutes)))
+				for _, match := range expectedRoutes {
+					Expect(routes).To(HaveKey(match))
+					Expect(string(routes[match].Policy)).ToNot(ContainSubstring(pathExpressionRuleName))
+				}
+			},
+			Entry("a / path", func(ing *netv1.Ingress) {
+				ing.Spec.Rules[0].HTTP.Paths[0].Path = "/"
+			}, "/", "/health"),
+			Entry("the default backend", func(ing *netv1.Ingress) {
+				ing.Spec.DefaultBackend = &netv1.IngressBackend{
+					Service: &netv1.IngressServiceBackend{Name: "example", Port: netv1.ServiceBackendPort{Number: 80}},
+				}
+			}, "/", "/api", "/health"),
+			Entry("an expression with a / literal prefix and a / path", func(ing *netv1.Ingress) {
+				ing.Spec.Rules[0].HTTP.Paths[0].Path = "/"
+				ing.Spec.Rules[0].HTTP.Paths[2].Path = "/(users|groups)/[0-9]+"
+			}, "/", "/health"),
+			Entry("a prefix path with the same literal prefix", func(ing *netv1.Ingress) {
+				ing.Spec.Rules[0].HTTP.Paths[0].Path = "/users/"
+			}, "/users/", "/health"),
+			Entry("another expression with the same literal prefix", func(ing *netv1.Ingress) {
+				other := *ing.Spec.Rules[0].HTTP.Paths[2].DeepCopy()
+				other.Path = "/users/[a-z]+/settings"
+				ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, other)
+			}, "/api", "/health"),
+		)
+	})
+
+	Describe("default backend", func() {
+		var ing netv1.Ingress
+
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test")
+			ing.Spec.Rules[0].HTTP.Paths[0].Path = "/api"
+			ing.Spec.DefaultBackend = &netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{Name: "default", Port: netv1.ServiceBackendPort{Number: 80}},
+			}
+		})
+
+		calculate := func() (map[string]ingressv1alpha1.HTTPSEdgeRouteSpec, map[tunnelKey]ingressv1alpha1.Tunnel) {
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			defaultSvc := NewTestServiceV1("default", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc, &defaultSvc).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+			ingressDomains, _, gatewayDomains := driver.calculateDomains()
+			edges := driver.calculateHTTPSEdges(&ingressDomains, gatewayDomains)
+			Expect(edges).To(HaveLen(1))
+			routes := map[string]ingressv1alpha1.HTTPSEdgeRouteSpec{}
+			for _, edge := range edges {
+				for _, route := range edge.Spec.Routes {
+					routes[route.Match] = route
+				}
+			}
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+			return routes, tunnels
+		}
+
+		It("Should route unmatched paths to the default backend", func() {
+			routes, tunnels := calculate()
+			Expect(routes).To(HaveLen(2))
+			Expect(routes).To(HaveKey("/api"))
+			Expect(routes).To(HaveKey("/"))
+			Expect(routes["/"].MatchType).To(Equal(MatchTypePathPrefix))
+			Expect(routes["/"].Backend.Labels).To(HaveKeyWithValue("k8s.ngrok.com/service", "default"))
+
+			Expect(tunnels).To(HaveKey(tunnelKey{"test", "default", "80"}))
+			Expect(tunnels).To(HaveKey(tunnelKey{"test", "example", "80"}))
+		})
+
+		It("Should route a rule without paths to the default backend", func() {
+			ing.Spec.Rules[0].HTTP = nil
+			routes, tunnels := calculate()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes["/"].Backend.Labels).To(HaveKeyWithValue("k8s.ngrok.com/service", "default"))
+			Expect(tunnels).To(HaveLen(1))
+		})
+
+		It("Should prefer the rule's own catch-all path", func() {
+			ing.Spec.Rules[0].HTTP.Paths[0].Path = "/"
+			routes, tunnels := calculate()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes["/"].Backend.Labels).To(HaveKeyWithValue("k8s.ngrok.com/service", "example"))
+			Expect(tunnels).ToNot(HaveKey(tunnelKey{"test", "default", "80"}))
+		})
+	})
+
+	Describe("ingress TLS certificates", func() {
+		var ing netv1.Ingress
+
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test")
+			ing.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{ing.Spec.Rules[0].Host}, SecretName: "example-tls"}}
+		})
+
+		sync := func(c client.Client) []ngrokv1alpha1.NgrokCertificate {
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			certificates := &ngrokv1alpha1.NgrokCertificateList{}
+			Expect(c.List(context.Background(), certificates)).To(Succeed())
+			return certificates.Items
+		}
+
+		It("Should upload the TLS secret's certificate for the host", func() {
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+
+			certificates := sync(c)
+			Expect(certificates).To(HaveLen(1))
+			Expect(certificates[0].Namespace).To(Equal("test"))
+			Expect(certificates[0].Spec.Domain).To(Equal(ing.Spec.Rules[0].Host))
+			Expect(certificates[0].Spec.SecretName).To(Equal("example-tls"))
+			Expect(certificates[0].Labels).To(HaveKeyWithValue(labelControllerName, defaultManagerName))
+		})
+
+		It("Should leave hosts without a TLS secret to ngrok managed certificates", func() {
+			ing.Spec.TLS[0].SecretName = ""
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+
+			Expect(sync(c)).To(BeEmpty())
+		})
+
+		It("Should delete the certificate when the ingress stops using the TLS secret", func() {
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			svc := NewTestServiceV1("example", "test")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+			Expect(sync(c)).To(HaveLen(1))
+
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(&ing), &ing)).To(Succeed())
+			ing.Spec.TLS = nil
+			Expect(c.Update(context.Background(), &ing)).To(Succeed())
+			Expect(sync(c)).To(BeEmpty())
+		})
+	})
+
+	Describe("ExternalName services", func() {
+		var ing netv1.Ingress
+		var svc corev1.Service
+
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test")
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = netv1.ServiceBackendPort{Number: 8443}
+			svc = NewTestServiceV1("example", "test")
+			svc.Spec.Type = corev1.ServiceTypeExternalName
+			svc.Spec.ExternalName = "admin.db.example.net."
+			svc.Spec.Ports = nil
+		})
+
+		calculate := func() map[tunnelKey]ingressv1alpha1.Tunnel {
+			ic := NewTestIngressClass("test-ingress-class", true, true)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic, &ing, &svc).Build()
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromIngress(tunnels)
+			return tunnels
+		}
+
+		It("Should forward to the external name on the backend's port", func() {
+			tunnels := calculate()
+			Expect(tunnels).To(HaveKey(tunnelKey{"test", "example", "8443"}))
+			Expect(tunnels[tunnelKey{"test", "example", "8443"}].Spec.ForwardsTo).To(Equal("admin.db.example.net:8443"))
+		})
+
+		It("Should use the ports the service declares", func() {
+			svc.Spec.Ports = []corev1.ServicePort{{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443}}
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = netv1.ServiceBackendPort{Name: "https"}
+			tunnels := calculate()
+			Expect(tunnels).To(HaveKey(tunnelKey{"test", "example", "443"}))
+			Expect(tunnels[tunnelKey{"test", "example", "443"}].Spec.ForwardsTo).To(Equal("admin.db.example.net:443"))
+		})
+
+		It("Should forward to the cluster DNS name of other services", func() {
+			svc = NewTestServiceV1("example", "test")
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = netv1.ServiceBackendPort{Number: 80}
+			tunnels := calculate()
+			Expect(tunnels[tunnelKey{"test", "example", "80"}].Spec.ForwardsTo).To(Equal("example.test.svc.cluster.local:80"))
+		})
+	})
+
+	Describe("getUserAgentFilterForIngress", func() {
+		moduleSetFilter := &ingressv1alpha1.EndpointUserAgentFilter{Deny: []string{"(?i).*bot.*"}}
+		modSet := &ingressv1alpha1.NgrokModuleSet{
+			Modules: ingressv1alpha1.NgrokModuleSetModules{UserAgentFilter: moduleSetFilter},
+		}
+
+		It("Should use the module set's filter without annotations", func() {
+			ing := NewTestIngressV1("test-ingress", "test")
+
+			filter, err := driver.getUserAgentFilterForIngress(&ing, modSet)
+			Expect(err).To(BeNil())
+			Expect(filter).To(Equal(moduleSetFilter))
+		})
+
+		It("Should prefer the ingress's annotations over the module set", func() {
+			ing := NewTestIngressV1("test-ingress", "test")
+			ing.SetAnnotations(map[string]string{"k8s.ngrok.com/user-agent-filter-allow": "Mozilla/.*\nSafari/.*"})
+
+			filter, err := driver.getUserAgentFilterForIngress(&ing, modSet)
+			Expect(err).To(BeNil())
+			Expect(filter).To(Equal(&ingressv1alpha1.EndpointUserAgentFilter{Allow: []string{"Mozilla/.*", "Safari/.*"}}))
+		})
+
+		It("Should return an error for an invalid pattern", func() {
+			ing := NewTestIngressV1("test-ingress", "test")
+			ing.SetAnnotations(map[string]string{"k8s.ngrok.com/user-agent-filter-deny": "scraper("})
+
+			_, err := driver.getUserAgentFilterForIngress(&ing, modSet)
+			Expect(err).To(MatchError(ingressv1alpha1.ErrInvalidUserAgentFilter))
+		})
+	})
+
 	Describe("createEndpointPolicyForGateway", func() {
 		var rule *gatewayv1.HTTPRouteRule
 		var namespace string
@@ -457,19 +1823,15 @@ var _ = Describe("Driver", func() {
 		})
 
 		It("Should return a merged policy if there rules with extensionRef", func() {
+			firstHostname := gatewayv1.PreciseHostname("first-hostname.com")
 			hostname := gatewayv1.PreciseHostname("test-hostname.com")
 			replacePrefixMatch := "/paprika"
 
 			rule.Filters = []gatewayv1.HTTPRouteFilter{
 				{
-					Type: "RequestHeaderModifier",
-					RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
-						Add: []gatewayv1.HTTPHeader{
-							{
-								Name:  "test-header",
-								Value: "test-value",
-							},
-						},
+					Type: "URLRewrite",
+					URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+						Hostname: &firstHostname,
 					},
 				},
 				{
@@ -492,7 +1854,7 @@ var _ = Describe("Driver", func() {
 				},
 			}
 
-			expectedPolicy := `{"enabled":true,"inbound":[{"actions":[{"type":"add-headers","config":{"headers":{"test-header":"test-value"}}}],"name":"Inbound HTTPRouteRule 1"},{"actions":[{"type":"deny"}],"name":"t"},{"actions":[{"type":"add-headers","config":{"headers":{"Host":"test-hostname.com"}}}],"name":"Inbound HTTPRouteRule 2"}]}`
+			expectedPolicy := `{"enabled":true,"inbound":[{"actions":[{"type":"add-headers","config":{"headers":{"Host":"first-hostname.com"}}}],"name":"Inbound HTTPRouteRule 1"},{"actions":[{"type":"deny"}],"name":"t"},{"actions":[{"type":"add-headers","config":{"headers":{"Host":"test-hostname.com"}}}],"name":"Inbound HTTPRouteRule 2"}]}`
 
 			policy, err := driver.createEndpointPolicyForGateway(rule, namespace)
 			Expect(err).To(BeNil())
@@ -506,6 +1868,419 @@ var _ = Describe("Driver", func() {
 			Expect(len(policy.Outbound)).To(BeZero())
 			Expect(string(jsonString)).To(Equal(expectedPolicy))
 		})
+
+		It("Should leave header filters to the route's headers modules", func() {
+			rule.Filters = []gatewayv1.HTTPRouteFilter{
+				{
+					Type: "RequestHeaderModifier",
+					RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Add: []gatewayv1.HTTPHeader{{Name: "test-header", Value: "test-value"}},
+					},
+				},
+				{
+					Type: "ResponseHeaderModifier",
+					ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Remove: []string{"Server"},
+					},
+				},
+			}
+
+			policy, err := driver.createEndpointPolicyForGateway(rule, namespace)
+			Expect(err).To(BeNil())
+			Expect(policy.Inbound).To(BeEmpty())
+			Expect(policy.Outbound).To(BeEmpty())
+		})
+	})
+
+	Describe("unsupportedRuleMatch", func() {
+		It("Should reject rules with header matches", func() {
+			rule := gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{
+				{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/")}},
+				{Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "true"}}},
+			}}
+			Expect(unsupportedRuleMatch(rule)).To(MatchError(ContainSubstring("header matches aren't supported")))
+		})
+
+		It("Should accept rules matching only paths", func() {
+			rule := gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{
+				{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/")}},
+			}}
+			Expect(unsupportedRuleMatch(rule)).To(Succeed())
+		})
+	})
+
+	Describe("gatewayRouteHeaders", func() {
+		It("Should return nil without header filters", func() {
+			Expect(gatewayRouteHeaders(nil)).To(BeNil())
+			Expect(gatewayRouteHeaders([]gatewayv1.HTTPRouteFilter{{
+				Type:         "ExtensionRef",
+				ExtensionRef: &gatewayv1.LocalObjectReference{Name: "test-policy", Kind: "NgrokTrafficPolicy"},
+			}})).To(BeNil())
+		})
+
+		It("Should translate header filters to the request and response headers modules", func() {
+			headers := gatewayRouteHeaders([]gatewayv1.HTTPRouteFilter{
+				{
+					Type: "RequestHeaderModifier",
+					RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Add:    []gatewayv1.HTTPHeader{{Name: "X-Added", Value: "added"}},
+						Set:    []gatewayv1.HTTPHeader{{Name: "X-Set", Value: "set"}},
+						Remove: []string{"X-Removed"},
+					},
+				},
+				{
+					Type: "ResponseHeaderModifier",
+					ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Remove: []string{"Server"},
+					},
+				},
+			})
+
+			Expect(headers).To(Equal(&ingressv1alpha1.EndpointHeaders{
+				Request: &ingressv1alpha1.EndpointRequestHeaders{
+					Add:    map[string]string{"X-Added": "added", "X-Set": "set"},
+					Remove: []string{"X-Set", "X-Removed"},
+				},
+				Response: &ingressv1alpha1.EndpointResponseHeaders{
+					Remove: []string{"Server"},
+				},
+			}))
+		})
+	})
+
+	Describe("gatewayRuleBackends", func() {
+		backendRef := func(name string, weight *int32) gatewayv1.HTTPBackendRef {
+			return gatewayv1.HTTPBackendRef{
+				BackendRef: gatewayv1.BackendRef{
+					BackendObjectReference: gatewayv1.BackendObjectReference{
+						Name: gatewayv1.ObjectName(name),
+						Port: ptr.To(gatewayv1.PortNumber(80)),
+					},
+					Weight: weight,
+				},
+			}
+		}
+
+		httproute := NewTestHTTPRoute("test-route", "test-namespace", "test-gateway")
+
+		BeforeEach(func() {
+			for _, name := range []string{"stable", "canary"} {
+				svc := NewTestServiceV1(name, "test-namespace")
+				Expect(driver.store.Add(&svc)).To(Succeed())
+			}
+		})
+
+		It("Should give backend refs without a weight a weight of 1", func() {
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{backendRef("stable", nil)})
+			Expect(backends).To(HaveLen(1))
+			Expect(backends[0].Weight).To(Equal(int64(1)))
+			Expect(backends[0].Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "stable", 80)))
+		})
+
+		It("Should keep the weights of the backend refs", func() {
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{
+				backendRef("stable", ptr.To(int32(90))),
+				backendRef("canary", ptr.To(int32(10))),
+			})
+			Expect(backends).To(HaveLen(2))
+			Expect(backends[0].Weight).To(Equal(int64(90)))
+			Expect(backends[0].Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "stable", 80)))
+			Expect(backends[1].Weight).To(Equal(int64(10)))
+			Expect(backends[1].Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "canary", 80)))
+		})
+
+		It("Should scale weights down to the highest weight ngrok allows", func() {
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{
+				backendRef("stable", ptr.To(int32(1000000))),
+				backendRef("canary", ptr.To(int32(500000))),
+			})
+			Expect(backends).To(HaveLen(2))
+			Expect(backends[0].Weight).To(Equal(int64(10000)))
+			Expect(backends[1].Weight).To(Equal(int64(5000)))
+		})
+
+		It("Should leave out backend refs with a weight of 0", func() {
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{
+				backendRef("stable", ptr.To(int32(1))),
+				backendRef("canary", ptr.To(int32(0))),
+			})
+			Expect(backends).To(HaveLen(1))
+			Expect(backends[0].Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "stable", 80)))
+		})
+
+		It("Should not scale weights down to 0", func() {
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{
+				backendRef("stable", ptr.To(int32(1000000))),
+				backendRef("canary", ptr.To(int32(1))),
+			})
+			Expect(backends).To(HaveLen(2))
+			Expect(backends[0].Weight).To(Equal(int64(10000)))
+			Expect(backends[1].Weight).To(Equal(int64(1)))
+		})
+
+		It("Should skip backend refs that aren't to existing services", func() {
+			other := backendRef("canary", nil)
+			other.Kind = ptr.To(gatewayv1.Kind("Bucket"))
+			backends := driver.gatewayRuleBackends(&httproute, []gatewayv1.HTTPBackendRef{
+				backendRef("missing", nil),
+				other,
+				backendRef("stable", nil),
+			})
+			Expect(backends).To(HaveLen(1))
+			Expect(backends[0].Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "stable", 80)))
+		})
+	})
+
+	Describe("ReferenceGrants", func() {
+		var httproute gatewayv1.HTTPRoute
+
+		BeforeEach(func() {
+			httproute = NewTestHTTPRoute("test-route", "test-namespace", "test-gateway")
+			httproute.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("backends"))
+			svc := NewTestServiceV1("example", "backends")
+			Expect(driver.store.Add(&svc)).To(Succeed())
+			Expect(driver.store.Add(&httproute)).To(Succeed())
+		})
+
+		It("Should skip backends in other namespaces without a ReferenceGrant", func() {
+			Expect(driver.gatewayRuleBackends(&httproute, httproute.Spec.Rules[0].BackendRefs)).To(BeEmpty())
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromGateway(tunnels)
+			Expect(tunnels).To(BeEmpty())
+		})
+
+		It("Should skip backends when the ReferenceGrant is for routes in another namespace", func() {
+			grant := NewTestReferenceGrant("allow-routes", "backends", "other-namespace")
+			Expect(driver.store.Add(&grant)).To(Succeed())
+			Expect(driver.gatewayRuleBackends(&httproute, httproute.Spec.Rules[0].BackendRefs)).To(BeEmpty())
+		})
+
+		It("Should skip backends when the ReferenceGrant is for other services", func() {
+			grant := NewTestReferenceGrant("allow-routes", "backends", "test-namespace")
+			grant.Spec.To[0].Name = ptr.To(gatewayv1.ObjectName("other"))
+			Expect(driver.store.Add(&grant)).To(Succeed())
+			Expect(driver.gatewayRuleBackends(&httproute, httproute.Spec.Rules[0].BackendRefs)).To(BeEmpty())
+		})
+
+		It("Should use backends in other namespaces a ReferenceGrant allows", func() {
+			grant := NewTestReferenceGrant("allow-routes", "backends", "test-namespace")
+			Expect(driver.store.Add(&grant)).To(Succeed())
+
+			backends := driver.gatewayRuleBackends(&httproute, httproute.Spec.Rules[0].BackendRefs)
+			Expect(backends).To(HaveLen(1))
+			Expect(backends[0].Labels).To(Equal(driver.ngrokLabels("backends", "", "example", 80)))
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromGateway(tunnels)
+			Expect(tunnels).To(HaveKey(tunnelKey{"backends", "example", "80"}))
+			tunnel := tunnels[tunnelKey{"backends", "example", "80"}]
+			Expect(tunnel.Namespace).To(Equal("backends"))
+			Expect(tunnel.Spec.ForwardsTo).To(Equal("example.backends.svc.cluster.local:80"))
+			Expect(tunnel.Spec.Labels).To(Equal(backends[0].Labels))
+			Expect(tunnel.OwnerReferences).To(BeEmpty())
+		})
+	})
+
+	Describe("Gateway infrastructure", func() {
+		var gtw gatewayv1.Gateway
+
+		metadataOf := func(metadata string) map[string]string {
+			values := map[string]string{}
+			Expect(json.Unmarshal([]byte(metadata), &values)).To(Succeed())
+			return values
+		}
+
+		BeforeEach(func() {
+			driver = NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, true)
+			driver.WithMetaData(map[string]string{"env": "test"})
+
+			gtw = NewTestGateway("test-gateway", "test-namespace", "ngrok")
+			gtw.Spec.Listeners[0].Port = 443
+			gtw.Spec.Listeners[0].Protocol = gatewayv1.HTTPSProtocolType
+			// the API server defaults the routes a listener allows
+			gtw.Spec.Listeners[0].AllowedRoutes = &gatewayv1.AllowedRoutes{
+				Namespaces: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSame)},
+			}
+			gtw.Spec.Infrastructure = &gatewayv1.GatewayInfrastructure{
+				Labels:      map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{"team": "payments"},
+				Annotations: map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{"cost-center": "42", "team": "ignored"},
+			}
+			httproute := NewTestHTTPRoute("test-route", "test-namespace", "test-gateway")
+			svc := NewTestServiceV1("example", "test-namespace")
+			gwClass := NewTestGatewayClass("ngrok", DefaultGatewayControllerName)
+			for _, obj := range []client.Object{&gwClass, &gtw, &httproute, &svc} {
+				Expect(driver.store.Add(obj)).To(Succeed())
+			}
+		})
+
+		It("Should add the infrastructure's labels and annotations to the metadata of the gateway's resources", func() {
+			expected := map[string]string{
+				"owned-by":    "kubernetes-gateway-api",
+				"env":         "test",
+				"team":        "payments",
+				"cost-center": "42",
+			}
+
+			_, _, gatewayDomains := driver.calculateDomains()
+			Expect(gatewayDomains).To(HaveKey("example.com"))
+			Expect(metadataOf(gatewayDomains["example.com"].Spec.Metadata)).To(Equal(expected))
+
+			var ingressDomains []ingressv1alpha1.Domain
+			edges := driver.calculateHTTPSEdges(&ingressDomains, gatewayDomains)
+			Expect(edges).To(HaveKey("example.com"))
+			Expect(metadataOf(edges["example.com"].Spec.Metadata)).To(Equal(expected))
+			Expect(edges["example.com"].Spec.Routes).To(HaveLen(1))
+			Expect(metadataOf(edges["example.com"].Spec.Routes[0].Metadata)).To(Equal(expected))
+
+			tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+			driver.calculateTunnelsFromGateway(tunnels)
+			Expect(tunnels).To(HaveLen(1))
+			for _, tunnel := range tunnels {
+				Expect(metadataOf(tunnel.Spec.Metadata)).To(Equal(expected))
+			}
+		})
+
+		It("Should keep the controller's metadata over the infrastructure's", func() {
+			gtw.Spec.Infrastructure.Labels["owned-by"] = "someone-else"
+
+			_, _, gatewayDomains := driver.calculateDomains()
+			Expect(metadataOf(gatewayDomains["example.com"].Spec.Metadata)).To(HaveKeyWithValue("owned-by", "kubernetes-gateway-api"))
+		})
+	})
+
+	Describe("Gateway routes", func() {
+		var httproute gatewayv1.HTTPRoute
+
+		BeforeEach(func() {
+			driver = NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, true)
+			httproute = NewTestHTTPRoute("test-route", "test-namespace", "test-gateway")
+		})
+
+		edgeRoutes := func() []ingressv1alpha1.HTTPSEdgeRouteSpec {
+			gtw := NewTestGateway("test-gateway", "test-namespace", "ngrok")
+			gtw.Spec.Listeners[0].Port = 443
+			gtw.Spec.Listeners[0].Protocol = gatewayv1.HTTPSProtocolType
+			// the API server defaults the routes a listener allows
+			gtw.Spec.Listeners[0].AllowedRoutes = &gatewayv1.AllowedRoutes{
+				Namespaces: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSame)},
+			}
+			svc := NewTestServiceV1("example", "test-namespace")
+			gwClass := NewTestGatewayClass("ngrok", DefaultGatewayControllerName)
+			for _, obj := range []client.Object{&gwClass, &gtw, &httproute, &svc} {
+				Expect(driver.store.Add(obj)).To(Succeed())
+			}
+
+			_, _, gatewayDomains := driver.calculateDomains()
+			var ingressDomains []ingressv1alpha1.Domain
+			edges := driver.calculateHTTPSEdges(&ingressDomains, gatewayDomains)
+			Expect(edges).To(HaveKey("example.com"))
+			return edges["example.com"].Spec.Routes
+		}
+
+		It("Should route each of a rule's matches", func() {
+			httproute.Spec.Rules[0].Matches = []gatewayv1.HTTPRouteMatch{
+				{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/api")}},
+				{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchExact), Value: ptr.To("/health")}},
+				{Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/api")}},
+			}
+
+			routes := edgeRoutes()
+			Expect(routes).To(HaveLen(2))
+			Expect(routes[0].Match).To(Equal("/api"))
+			Expect(routes[0].MatchType).To(Equal(MatchTypePathPrefix))
+			Expect(routes[1].Match).To(Equal("/health"))
+			Expect(routes[1].MatchType).To(Equal(MatchTypeExactPath))
+			Expect(routes[1].Backend).To(Equal(routes[0].Backend))
+		})
+
+		It("Should respond with a 500 to the requests of rules whose backends all have a weight of 0", func() {
+			httproute.Spec.Rules[0].BackendRefs[0].Weight = ptr.To(int32(0))
+
+			routes := edgeRoutes()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Backend.Labels).To(Equal(driver.ngrokLabels("test-namespace", "", "example", 80)))
+			Expect(routes[0].WeightedBackends).To(BeEmpty())
+			policy := ingressv1alpha1.EndpointPolicy{}
+			Expect(json.Unmarshal(routes[0].Policy, &policy)).To(Succeed())
+			Expect(policy.Inbound).ToNot(BeEmpty())
+			Expect(policy.Inbound[0].Name).To(Equal(zeroWeightBackendsRuleName))
+			Expect(string(policy.Inbound[0].Actions[0].Config)).To(MatchJSON(`{"status_code": 500}`))
+		})
+
+		It("Should not route rules with header matches", func() {
+			canary := *httproute.Spec.Rules[0].DeepCopy()
+			canary.Matches = []gatewayv1.HTTPRouteMatch{{
+				Path:    &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/")},
+				Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "true"}},
+			}}
+			httproute.Spec.Rules = append([]gatewayv1.HTTPRouteRule{canary}, httproute.Spec.Rules...)
+
+			routes := edgeRoutes()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Match).To(Equal("/"))
+			Expect(string(routes[0].Policy)).ToNot(ContainSubstring("x-canary"))
+		})
+	})
+
+	Describe("RecordIngressHandled", func() {
+		var recorder *record.FakeRecorder
+		var ing, otherIng netv1.Ingress
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			driver.WithEventRecorder(recorder)
+
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(driver.store.Add(&ic)).To(Succeed())
+			ing = NewTestIngressV1WithClass("ngrok-ingress", "test-namespace", ngrokIngressClass)
+			otherIng = NewTestIngressV1WithClass("other-ingress", "test-namespace", "other")
+		})
+
+		It("Should record an event when an ingress is accepted", func() {
+			_, err := driver.UpdateIngress(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			driver.RecordIngressHandled(&ing, err)
+			Expect(recorder.Events).To(Receive(Equal("Normal IngressAccepted Ingress is handled by the ngrok ingress controller")))
+		})
+
+		It("Should record a warning when an ingress uses another ingress class", func() {
+			_, err := driver.UpdateIngress(&otherIng)
+			Expect(err).To(HaveOccurred())
+			driver.RecordIngressHandled(&otherIng, err)
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning IngressClassMismatch The controller will not reconcile this ingress object due to the ingress class mismatching.")))
+		})
+
+		It("Should not record events when ingresses are looked up or listed", func() {
+			_, err := driver.UpdateIngress(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = driver.UpdateIngress(&otherIng)
+			Expect(err).To(HaveOccurred())
+			Expect(driver.store.ListNgrokIngressesV1()).To(HaveLen(1))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should only record an event once for each version of an ingress", func() {
+			driver.RecordIngressHandled(&ing, nil)
+			driver.RecordIngressHandled(&ing, nil)
+			Expect(recorder.Events).To(HaveLen(1))
+
+			ing.ResourceVersion = "2"
+			driver.RecordIngressHandled(&ing, nil)
+			Expect(recorder.Events).To(HaveLen(2))
+		})
+
+		It("Should forget the events recorded for an ingress once it's deleted", func() {
+			driver.RecordIngressHandled(&ing, nil)
+			Expect(driver.DeleteNamedIngress(types.NamespacedName{Name: ing.Name, Namespace: ing.Namespace})).To(Succeed())
+			driver.RecordIngressHandled(&ing, nil)
+			Expect(recorder.Events).To(HaveLen(2))
+		})
+
+		It("Should not record events without a recorder", func() {
+			driver.WithEventRecorder(nil)
+			driver.RecordIngressHandled(&otherIng, errors.NewErrDifferentIngressClass(nil, nil))
+		})
 	})
 
 	Describe("When not running concurrently", func() {
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+const (
+	// fallbackExpression matches upstream responses that should be replaced by the fallback backend
+	fallbackExpression = "res.status_code >= 500"
+
+	defaultFallbackStatusCode = 503
+)
+
+// fallbackPolicyRule builds the outbound policy rule that replaces an upstream 5xx response with the
+// response of the fallback backend
+func fallbackPolicyRule(fallback *ingressv1alpha1.EndpointFallback) (ingressv1alpha1.EndpointRule, error) {
+	if fallback.Static == nil {
+		return ingressv1alpha1.EndpointRule{}, fmt.Errorf("fallback module requires a static backend")
+	}
+
+	statusCode := fallback.Static.StatusCode
+	if statusCode == 0 {
+		statusCode = defaultFallbackStatusCode
+	}
+	if statusCode < 100 || statusCode > 599 {
+		return ingressv1alpha1.EndpointRule{}, fmt.Errorf("invalid fallback status code %d", statusCode)
+	}
+
+	config, err := json.Marshal(CustomResponseConfig{
+		StatusCode: statusCode,
+		Content:    fallback.Static.Content,
+		Headers:    fallback.Static.Headers,
+	})
+	if err != nil {
+		return ingressv1alpha1.EndpointRule{}, err
+	}
+
+	return ingressv1alpha1.EndpointRule{
+		Name:        "Fallback on upstream 5xx",
+		Expressions: []string{fallbackExpression},
+		Actions: []ingressv1alpha1.EndpointAction{
+			{
+				Type:   "custom-response",
+				Config: config,
+			},
+		},
+	}, nil
+}
+
+// withFallbackPolicy adds the fallback rule to the end of the policy's outbound rules so the other outbound
+// rules still see the upstream's response before it's replaced
+func withFallbackPolicy(policyJSON json.RawMessage, fallback *ingressv1alpha1.EndpointFallback) (json.RawMessage, error) {
+	if fallback == nil {
+		return policyJSON, nil
+	}
+
+	rule, err := fallbackPolicyRule(fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	return withPolicyRules(policyJSON, nil, []ingressv1alpha1.EndpointRule{rule})
+}
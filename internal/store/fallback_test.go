@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+)
+
+var _ = Describe("Fallback", func() {
+	decodePolicy := func(policyJSON json.RawMessage) ingressv1alpha1.EndpointPolicy {
+		policy := ingressv1alpha1.EndpointPolicy{}
+		Expect(json.Unmarshal(policyJSON, &policy)).To(Succeed())
+		return policy
+	}
+
+	decodeResponse := func(rule ingressv1alpha1.EndpointRule) CustomResponseConfig {
+		Expect(rule.Actions).To(HaveLen(1))
+		Expect(rule.Actions[0].Type).To(Equal("custom-response"))
+		config := CustomResponseConfig{}
+		Expect(json.Unmarshal(rule.Actions[0].Config, &config)).To(Succeed())
+		return config
+	}
+
+	Describe("withFallbackPolicy", func() {
+		It("Should respond with the static backend on an upstream 5xx", func() {
+			policyJSON, err := withFallbackPolicy(nil, &ingressv1alpha1.EndpointFallback{
+				Static: &ingressv1alpha1.EndpointStaticBackend{
+					StatusCode: 200,
+					Content:    "Down for maintenance",
+					Headers:    map[string]string{"content-type": "text/plain"},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(BeEmpty())
+			Expect(policy.Outbound).To(HaveLen(1))
+			Expect(policy.Outbound[0].Expressions).To(Equal([]string{"res.status_code >= 500"}))
+			Expect(decodeResponse(policy.Outbound[0])).To(Equal(CustomResponseConfig{
+				StatusCode: 200,
+				Content:    "Down for maintenance",
+				Headers:    map[string]string{"content-type": "text/plain"},
+			}))
+		})
+
+		It("Should default to a 503", func() {
+			policyJSON, err := withFallbackPolicy(nil, &ingressv1alpha1.EndpointFallback{
+				Static: &ingressv1alpha1.EndpointStaticBackend{},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Outbound).To(HaveLen(1))
+			Expect(decodeResponse(policy.Outbound[0]).StatusCode).To(Equal(503))
+		})
+
+		It("Should run after the existing policy rules", func() {
+			existing, err := json.Marshal(ingressv1alpha1.EndpointPolicy{
+				Inbound: []ingressv1alpha1.EndpointRule{
+					{Name: "existing", Actions: []ingressv1alpha1.EndpointAction{{Type: "deny"}}},
+				},
+				Outbound: []ingressv1alpha1.EndpointRule{
+					{Name: "existing-outbound", Actions: []ingressv1alpha1.EndpointAction{{Type: "add-headers"}}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			policyJSON, err := withFallbackPolicy(existing, &ingressv1alpha1.EndpointFallback{
+				Static: &ingressv1alpha1.EndpointStaticBackend{},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(1))
+			Expect(policy.Outbound).To(HaveLen(2))
+			Expect(policy.Outbound[0].Name).To(Equal("existing-outbound"))
+			Expect(policy.Outbound[1].Name).To(Equal("Fallback on upstream 5xx"))
+		})
+
+		It("Should error without a fallback backend", func() {
+			_, err := withFallbackPolicy(nil, &ingressv1alpha1.EndpointFallback{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should error on an invalid status code", func() {
+			_, err := withFallbackPolicy(nil, &ingressv1alpha1.EndpointFallback{
+				Static: &ingressv1alpha1.EndpointStaticBackend{StatusCode: 1000},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should leave the policy alone without a fallback", func() {
+			policyJSON, err := withFallbackPolicy(json.RawMessage(`{"inbound":[]}`), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(policyJSON)).To(Equal(`{"inbound":[]}`))
+		})
+	})
+
+	Describe("Sync", func() {
+		var driver *Driver
+		var recorder *record.FakeRecorder
+		var scheme *runtime.Scheme
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+			utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+			recorder = record.NewFakeRecorder(10)
+			driver = NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, false)
+			driver.syncAllowConcurrent = true
+			driver.WithEventRecorder(recorder)
+		})
+
+		sync := func(fallback *ingressv1alpha1.EndpointFallback) *ingressv1alpha1.HTTPSEdgeList {
+			ms := NewTestNgrokModuleSet("fallback", "test-namespace", false)
+			ms.Modules.Fallback = fallback
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "fallback"})
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic1, &i1, &s, &d1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			return foundEdges
+		}
+
+		It("Should add the fallback rule to the edge routes of ingresses using the module", func() {
+			foundEdges := sync(&ingressv1alpha1.EndpointFallback{
+				Static: &ingressv1alpha1.EndpointStaticBackend{Content: "Down for maintenance"},
+			})
+			Expect(foundEdges.Items).To(HaveLen(1))
+			Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+
+			policy := decodePolicy(foundEdges.Items[0].Spec.Routes[0].Policy)
+			Expect(policy.Outbound).To(HaveLen(1))
+			Expect(policy.Outbound[0].Expressions).To(Equal([]string{"res.status_code >= 500"}))
+			Expect(decodeResponse(policy.Outbound[0]).Content).To(Equal("Down for maintenance"))
+		})
+
+		It("Should not route the ingress when its fallback backend is missing", func() {
+			foundEdges := sync(&ingressv1alpha1.EndpointFallback{})
+			for _, edge := range foundEdges.Items {
+				Expect(edge.Spec.Routes).To(BeEmpty())
+			}
+			driver.RecordIngressIssues()
+			Eventually(recorder.Events).Should(Receive(HavePrefix("Warning ModuleResolutionFailed ")))
+		})
+	})
+})
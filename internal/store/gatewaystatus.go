@@ -0,0 +1,419 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// supportedRouteKinds are the kinds of the routes that are served through the HTTPS edges of gateway listeners
+var supportedRouteKinds = []gatewayv1.Kind{"HTTPRoute", "GRPCRoute"}
+
+// updateGatewayStatuses sets the listener statuses and conditions of each gateway in the store handled by the
+// controller, so that tools watching them can tell when the gateway's listeners are live with ngrok. The
+// statuses of gateways of other controllers' gateway classes are left to those controllers.
+func (d *Driver) updateGatewayStatuses(ctx context.Context, c client.Client) error {
+	reservedDomains := d.reservedDomains()
+	routes := d.gatewayHTTPRoutes()
+
+	for _, gtw := range d.store.ListNgrokGateways() {
+		newStatus := d.calculateGatewayStatus(gtw, routes, reservedDomains)
+		if reflect.DeepEqual(gtw.Status, newStatus) {
+			continue
+		}
+		// The gateway is shared with the store's other readers, so update a copy of it
+		gtw = gtw.DeepCopy()
+		gtw.Status = newStatus
+		if err := c.Status().Update(ctx, gtw); err != nil {
+			d.log.Error(err, "error updating gateway status", "gateway", client.ObjectKeyFromObject(gtw))
+			return err
+		}
+		// Keep the store's copy at the resource version the update returned
+		if _, err := d.store.Update(gtw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateHTTPRouteStatuses sets the statuses of each HTTPRoute and GRPCRoute in the store for the gateways in
+// the store they reference. The statuses other controllers set for their own gateways are kept.
+func (d *Driver) updateHTTPRouteStatuses(ctx context.Context, c client.Client) error {
+	for _, httproute := range d.store.ListHTTPRoutes() {
+		parents := d.calculateRouteParentStatuses(httproute, httproute.Status.Parents)
+		if reflect.DeepEqual(httproute.Status.Parents, parents) {
+			continue
+		}
+		httproute = httproute.DeepCopy()
+		httproute.Status.Parents = parents
+		if err := c.Status().Update(ctx, httproute); err != nil {
+			d.log.Error(err, "error updating httproute status", "httproute", client.ObjectKeyFromObject(httproute))
+			return err
+		}
+		if _, err := d.store.Update(httproute); err != nil {
+			return err
+		}
+	}
+
+	for _, grpcroute := range d.store.ListGRPCRoutes() {
+		parents := d.calculateRouteParentStatuses(grpcRouteToHTTPRoute(grpcroute), grpcroute.Status.Parents)
+		if reflect.DeepEqual(grpcroute.Status.Parents, parents) {
+			continue
+		}
+		grpcroute = grpcroute.DeepCopy()
+		grpcroute.Status.Parents = parents
+		if err := c.Status().Update(ctx, grpcroute); err != nil {
+			d.log.Error(err, "error updating grpcroute status", "grpcroute", client.ObjectKeyFromObject(grpcroute))
+			return err
+		}
+		if _, err := d.store.Update(grpcroute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reservedDomains returns the names of the domains in the store that have been reserved with ngrok
+func (d *Driver) reservedDomains() map[string]bool {
+	reserved := map[string]bool{}
+	for _, domain := range d.store.ListDomainsV1() {
+		if domain.Status.ID != "" {
+			reserved[domain.Spec.Domain] = true
+		}
+	}
+	return reserved
+}
+
+// calculateGatewayStatus returns the status of the gateway given the routes that may attach to its listeners.
+// A listener is programmed once the domain of its hostname is reserved, and the gateway is programmed once all
+// of its listeners are. The last transition times of conditions that haven't changed are kept.
+func (d *Driver) calculateGatewayStatus(gtw *gatewayv1.Gateway, routes []*gatewayv1.HTTPRoute, reservedDomains map[string]bool) gatewayv1.GatewayStatus {
+	status := *gtw.Status.DeepCopy()
+
+	previousListeners := map[gatewayv1.SectionName]gatewayv1.ListenerStatus{}
+	for _, listenerStatus := range status.Listeners {
+		previousListeners[listenerStatus.Name] = listenerStatus
+	}
+
+	status.Listeners = nil
+	var pendingListeners []string
+	for _, listener := range gtw.Spec.Listeners {
+		listenerStatus := gatewayv1.ListenerStatus{
+			Name:           listener.Name,
+			SupportedKinds: []gatewayv1.RouteGroupKind{},
+			Conditions:     previousListeners[listener.Name].Conditions,
+		}
+
+		for _, kind := range supportedRouteKinds {
+			if listenerAllowsKind(listener, string(kind)) {
+				listenerStatus.SupportedKinds = append(listenerStatus.SupportedKinds, gatewayv1.RouteGroupKind{
+					Group: ptr.To(gatewayv1.Group(gatewayv1.GroupName)),
+					Kind:  kind,
+				})
+			}
+		}
+
+		for _, route := range routes {
+			if routeAttachesToListener(route, gtw, listener) {
+				listenerStatus.AttachedRoutes++
+			}
+		}
+
+		meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+			Type:               string(gatewayv1.ListenerConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1.ListenerReasonAccepted),
+			ObservedGeneration: gtw.Generation,
+		})
+
+		resolvedRefs := metav1.Condition{
+			Type:               string(gatewayv1.ListenerConditionResolvedRefs),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1.ListenerReasonResolvedRefs),
+			ObservedGeneration: gtw.Generation,
+		}
+		if listener.AllowedRoutes != nil && len(listener.AllowedRoutes.Kinds) > len(listenerStatus.SupportedKinds) {
+			resolvedRefs.Status = metav1.ConditionFalse
+			resolvedRefs.Reason = string(gatewayv1.ListenerReasonInvalidRouteKinds)
+			resolvedRefs.Message = fmt.Sprintf("only the %v route kinds are supported", supportedRouteKinds)
+		}
+		meta.SetStatusCondition(&listenerStatus.Conditions, resolvedRefs)
+
+		programmed := metav1.Condition{
+			Type:               string(gatewayv1.ListenerConditionProgrammed),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gatewayv1.ListenerReasonProgrammed),
+			ObservedGeneration: gtw.Generation,
+		}
+		switch {
+		case listener.Hostname == nil:
+			programmed.Status = metav1.ConditionFalse
+			programmed.Reason = string(gatewayv1.ListenerReasonInvalid)
+			programmed.Message = "listeners need a hostname to be served by ngrok"
+		case !reservedDomains[string(*listener.Hostname)]:
+			programmed.Status = metav1.ConditionFalse
+			programmed.Reason = string(gatewayv1.ListenerReasonPending)
+			programmed.Message = fmt.Sprintf("waiting for the domain %s to be reserved", *listener.Hostname)
+		}
+		if programmed.Status != metav1.ConditionTrue {
+			pendingListeners = append(pendingListeners, string(listener.Name))
+		}
+		meta.SetStatusCondition(&listenerStatus.Conditions, programmed)
+
+		status.Listeners = append(status.Listeners, listenerStatus)
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.GatewayConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1.GatewayReasonAccepted),
+		ObservedGeneration: gtw.Generation,
+	})
+
+	programmed := metav1.Condition{
+		Type:               string(gatewayv1.GatewayConditionProgrammed),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1.GatewayReasonProgrammed),
+		ObservedGeneration: gtw.Generation,
+	}
+	if len(pendingListeners) > 0 {
+		programmed.Status = metav1.ConditionFalse
+		programmed.Reason = string(gatewayv1.GatewayReasonPending)
+		programmed.Message = fmt.Sprintf("listeners %v aren't programmed yet", pendingListeners)
+	}
+	meta.SetStatusCondition(&status.Conditions, programmed)
+
+	return status
+}
+
+// calculateRouteParentStatuses returns the parent statuses of the route, given its current ones, with a status
+// for each gateway handled by the controller it references. A route is accepted by a gateway when one of its listeners
+// serves one of the route's hostnames and at least one of its rules can be routed, and its refs are resolved when
+// all its backends are ports of existing services. A route with some rules that can't be routed is partially invalid.
+func (d *Driver) calculateRouteParentStatuses(route *gatewayv1.HTTPRoute, current []gatewayv1.RouteParentStatus) []gatewayv1.RouteParentStatus {
+	controllerName := d.store.GatewayControllerName()
+	var parents []gatewayv1.RouteParentStatus
+	previous := map[string]gatewayv1.RouteParentStatus{}
+	for _, parent := range current {
+		if parent.ControllerName != controllerName {
+			parents = append(parents, parent)
+			continue
+		}
+		previous[parentRefKey(parent.ParentRef, route.Namespace)] = parent
+	}
+
+	resolvedRefs := d.routeResolvedRefsCondition(route)
+	for _, ref := range route.Spec.ParentRefs {
+		gtw := d.parentGateway(ref, route.Namespace)
+		if gtw == nil {
+			continue
+		}
+
+		parent := gatewayv1.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: controllerName,
+			// The current conditions belong to the store's copy of the route, so they're copied before they're set
+			Conditions: append([]metav1.Condition(nil), previous[parentRefKey(ref, route.Namespace)].Conditions...),
+		}
+		accepted := routeAcceptedCondition(route, gtw, ref)
+		partiallyInvalid, unsupported := routeUnsupportedRulesCondition(route)
+		if accepted.Status == metav1.ConditionTrue && unsupported == len(route.Spec.Rules) && unsupported > 0 {
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = partiallyInvalid.Reason
+			accepted.Message = partiallyInvalid.Message
+		}
+		accepted.ObservedGeneration = route.Generation
+		meta.SetStatusCondition(&parent.Conditions, accepted)
+		if accepted.Status == metav1.ConditionTrue && unsupported > 0 {
+			partiallyInvalid.ObservedGeneration = route.Generation
+			meta.SetStatusCondition(&parent.Conditions, partiallyInvalid)
+		} else {
+			meta.RemoveStatusCondition(&parent.Conditions, partiallyInvalid.Type)
+		}
+		resolvedRefs.ObservedGeneration = route.Generation
+		meta.SetStatusCondition(&parent.Conditions, resolvedRefs)
+		parents = append(parents, parent)
+	}
+	return parents
+}
+
+// routeAcceptedCondition returns the Accepted condition of the route for the gateway the parent ref references
+func routeAcceptedCondition(route *gatewayv1.HTTPRoute, gtw *gatewayv1.Gateway, ref gatewayv1.ParentReference) metav1.Condition {
+	allowed := false
+	for _, listener := range gtw.Spec.Listeners {
+		if ref.SectionName != nil && *ref.SectionName != listener.Name {
+			continue
+		}
+		if !listenerAllowsKind(listener, routeKind(route)) || !listenerAllowsNamespace(listener, gtw, route.Namespace) {
+			continue
+		}
+		allowed = true
+		if routeAttachesToListener(route, gtw, listener) {
+			return metav1.Condition{
+				Type:   string(gatewayv1.RouteConditionAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: string(gatewayv1.RouteReasonAccepted),
+			}
+		}
+	}
+
+	if !allowed {
+		return metav1.Condition{
+			Type:    string(gatewayv1.RouteConditionAccepted),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(gatewayv1.RouteReasonNotAllowedByListeners),
+			Message: fmt.Sprintf("no listener of gateway %s/%s allows the route", gtw.Namespace, gtw.Name),
+		}
+	}
+	return metav1.Condition{
+		Type:    string(gatewayv1.RouteConditionAccepted),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(gatewayv1.RouteReasonNoMatchingListenerHostname),
+		Message: fmt.Sprintf("no listener of gateway %s/%s has one of the route's hostnames", gtw.Namespace, gtw.Name),
+	}
+}
+
+// routeUnsupportedRulesCondition returns the PartiallyInvalid condition of the route along with the number of its
+// rules that can't be routed. The condition describes the first of them, see unsupportedRuleMatch.
+func routeUnsupportedRulesCondition(route *gatewayv1.HTTPRoute) (metav1.Condition, int) {
+	condition := metav1.Condition{
+		Type:   string(gatewayv1.RouteConditionPartiallyInvalid),
+		Status: metav1.ConditionTrue,
+		Reason: string(gatewayv1.RouteReasonUnsupportedValue),
+	}
+	unsupported := 0
+	for i, rule := range route.Spec.Rules {
+		if err := unsupportedRuleMatch(rule); err != nil {
+			if unsupported == 0 {
+				condition.Message = fmt.Sprintf("rule %d: %v", i, err)
+			}
+			unsupported++
+		}
+	}
+	return condition, unsupported
+}
+
+// routeResolvedRefsCondition returns the ResolvedRefs condition of the route, which is false if any of its
+// backends isn't a service, is a service in another namespace no ReferenceGrant allows, or isn't a port of an
+// existing service
+func (d *Driver) routeResolvedRefsCondition(route *gatewayv1.HTTPRoute) metav1.Condition {
+	for _, rule := range route.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			if backendRef.Kind != nil && *backendRef.Kind != "Service" {
+				return metav1.Condition{
+					Type:    string(gatewayv1.RouteConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(gatewayv1.RouteReasonInvalidKind),
+					Message: fmt.Sprintf("backend %s is a %s, only services are supported", backendRef.Name, *backendRef.Kind),
+				}
+			}
+			namespace, err := d.backendRefNamespace(route, backendRef.BackendRef)
+			if err != nil {
+				return metav1.Condition{
+					Type:    string(gatewayv1.RouteConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(gatewayv1.RouteReasonRefNotPermitted),
+					Message: err.Error(),
+				}
+			}
+			if _, _, err := d.getEdgeBackendRef(backendRef.BackendRef, namespace); err != nil {
+				return metav1.Condition{
+					Type:    string(gatewayv1.RouteConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+					Message: err.Error(),
+				}
+			}
+		}
+	}
+	return metav1.Condition{
+		Type:   string(gatewayv1.RouteConditionResolvedRefs),
+		Status: metav1.ConditionTrue,
+		Reason: string(gatewayv1.RouteReasonResolvedRefs),
+	}
+}
+
+// parentGateway returns the gateway in the store the parent ref of a route in the namespace references, or nil
+// if the ref isn't to a gateway in the store that's handled by the controller
+func (d *Driver) parentGateway(ref gatewayv1.ParentReference, namespace string) *gatewayv1.Gateway {
+	if ref.Group != nil && *ref.Group != gatewayv1.GroupName {
+		return nil
+	}
+	if ref.Kind != nil && *ref.Kind != "Gateway" {
+		return nil
+	}
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	gtw, err := d.store.GetGateway(string(ref.Name), namespace)
+	if err != nil || !d.store.IsNgrokGateway(gtw) {
+		return nil
+	}
+	return gtw
+}
+
+// parentRefKey returns a key for the parent ref of a route in the namespace, to match up parent statuses
+func parentRefKey(ref gatewayv1.ParentReference, namespace string) string {
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	key := getKey(string(ref.Name), namespace)
+	if ref.SectionName != nil {
+		key += "#" + string(*ref.SectionName)
+	}
+	return key
+}
+
+// routeAttachesToListener returns true if the route references the gateway, and the listener allows the route
+// and serves one of its hostnames
+func routeAttachesToListener(route *gatewayv1.HTTPRoute, gtw *gatewayv1.Gateway, listener gatewayv1.Listener) bool {
+	if listener.Hostname == nil {
+		return false
+	}
+	if !listenerAllowsKind(listener, routeKind(route)) || !listenerAllowsNamespace(listener, gtw, route.Namespace) {
+		return false
+	}
+
+	referenced := false
+	for _, ref := range route.Spec.ParentRefs {
+		namespace := route.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		if string(ref.Name) == gtw.Name && namespace == gtw.Namespace && (ref.SectionName == nil || *ref.SectionName == listener.Name) {
+			referenced = true
+			break
+		}
+	}
+	if !referenced {
+		return false
+	}
+
+	for _, hostname := range route.Spec.Hostnames {
+		if hostname == *listener.Hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerAllowsNamespace returns true if routes in the namespace can attach to the gateway's listener
+func listenerAllowsNamespace(listener gatewayv1.Listener, gtw *gatewayv1.Gateway, namespace string) bool {
+	if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil || listener.AllowedRoutes.Namespaces.From == nil {
+		return true
+	}
+	switch *listener.AllowedRoutes.Namespaces.From {
+	case gatewayv1.NamespacesFromSame:
+		return namespace == gtw.Namespace
+	case gatewayv1.NamespacesFromSelector:
+		return namespace == listener.AllowedRoutes.Namespaces.Selector.String()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+var _ = Describe("Gateway statuses", func() {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1beta1.AddToScheme(scheme))
+
+	var (
+		ctx       context.Context
+		driver    *Driver
+		c         client.Client
+		gtw       gatewayv1.Gateway
+		httproute gatewayv1.HTTPRoute
+		domain    ingressv1alpha1.Domain
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		driver = NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, true)
+		gtw = NewTestGateway("test-gateway", "test-namespace", "ngrok")
+		httproute = NewTestHTTPRoute("test-route", "test-namespace", "test-gateway")
+		domain = NewDomainV1("example.com", "test-namespace")
+		domain.Status.ID = "rd_123"
+		gwClass := NewTestGatewayClass("ngrok", DefaultGatewayControllerName)
+		Expect(driver.store.Add(&gwClass)).To(Succeed())
+	})
+
+	// updateStatuses adds the objects to the client and the store and updates the statuses of the gateway and
+	// route in the store
+	updateStatuses := func(objs ...client.Object) {
+		c = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objs...).
+			WithStatusSubresource(&gatewayv1.Gateway{}, &gatewayv1.HTTPRoute{}).
+			Build()
+		for _, obj := range objs {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+			Expect(driver.store.Add(obj)).To(Succeed())
+		}
+		Expect(driver.updateGatewayStatuses(ctx, c)).To(Succeed())
+		Expect(driver.updateHTTPRouteStatuses(ctx, c)).To(Succeed())
+	}
+
+	getGateway := func() *gatewayv1.Gateway {
+		got := &gatewayv1.Gateway{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(&gtw), got)).To(Succeed())
+		return got
+	}
+
+	getRoute := func() *gatewayv1.HTTPRoute {
+		got := &gatewayv1.HTTPRoute{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(&httproute), got)).To(Succeed())
+		return got
+	}
+
+	expectCondition := func(conditions []metav1.Condition, conditionType string, status metav1.ConditionStatus, reason string) {
+		condition := meta.FindStatusCondition(conditions, conditionType)
+		Expect(condition).ToNot(BeNil(), conditionType)
+		Expect(condition.Status).To(Equal(status), conditionType)
+		Expect(condition.Reason).To(Equal(reason), conditionType)
+	}
+
+	It("Should program the gateway's listeners once their domains are reserved", func() {
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		got := getGateway()
+		expectCondition(got.Status.Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		expectCondition(got.Status.Conditions, "Programmed", metav1.ConditionTrue, "Programmed")
+		Expect(got.Status.Listeners).To(HaveLen(1))
+		listener := got.Status.Listeners[0]
+		Expect(listener.Name).To(Equal(gatewayv1.SectionName("http")))
+		Expect(listener.AttachedRoutes).To(Equal(int32(1)))
+		Expect(listener.SupportedKinds).To(HaveLen(2))
+		expectCondition(listener.Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		expectCondition(listener.Conditions, "ResolvedRefs", metav1.ConditionTrue, "ResolvedRefs")
+		expectCondition(listener.Conditions, "Programmed", metav1.ConditionTrue, "Programmed")
+
+		route := getRoute()
+		Expect(route.Status.Parents).To(HaveLen(1))
+		Expect(route.Status.Parents[0].ControllerName).To(Equal(DefaultGatewayControllerName))
+		Expect(route.Status.Parents[0].ParentRef.Name).To(Equal(gatewayv1.ObjectName("test-gateway")))
+		expectCondition(route.Status.Parents[0].Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		expectCondition(route.Status.Parents[0].Conditions, "ResolvedRefs", metav1.ConditionTrue, "ResolvedRefs")
+	})
+
+	It("Should leave listeners pending until their domains are reserved", func() {
+		domain.Status.ID = ""
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		got := getGateway()
+		expectCondition(got.Status.Conditions, "Programmed", metav1.ConditionFalse, "Pending")
+		expectCondition(got.Status.Listeners[0].Conditions, "Programmed", metav1.ConditionFalse, "Pending")
+	})
+
+	It("Should not resolve the refs of routes to missing services", func() {
+		updateStatuses(&gtw, &httproute, &domain)
+
+		route := getRoute()
+		Expect(route.Status.Parents).To(HaveLen(1))
+		expectCondition(route.Status.Parents[0].Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		expectCondition(route.Status.Parents[0].Conditions, "ResolvedRefs", metav1.ConditionFalse, "BackendNotFound")
+	})
+
+	It("Should not resolve the refs of routes to services in other namespaces without a ReferenceGrant", func() {
+		httproute.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("backends"))
+		svc := NewTestServiceV1("example", "backends")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		route := getRoute()
+		expectCondition(route.Status.Parents[0].Conditions, "ResolvedRefs", metav1.ConditionFalse, "RefNotPermitted")
+	})
+
+	It("Should resolve the refs of routes to services in other namespaces a ReferenceGrant allows", func() {
+		httproute.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("backends"))
+		svc := NewTestServiceV1("example", "backends")
+		grant := NewTestReferenceGrant("allow-routes", "backends", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc, &grant)
+
+		route := getRoute()
+		expectCondition(route.Status.Parents[0].Conditions, "ResolvedRefs", metav1.ConditionTrue, "ResolvedRefs")
+	})
+
+	It("Should not accept routes without a hostname of one of the gateway's listeners", func() {
+		httproute.Spec.Hostnames = []gatewayv1.Hostname{"other.example.com"}
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		Expect(getGateway().Status.Listeners[0].AttachedRoutes).To(Equal(int32(0)))
+		route := getRoute()
+		expectCondition(route.Status.Parents[0].Conditions, "Accepted", metav1.ConditionFalse, "NoMatchingListenerHostname")
+	})
+
+	It("Should not accept routes whose rules all have header matches", func() {
+		httproute.Spec.Rules[0].Matches = []gatewayv1.HTTPRouteMatch{
+			{Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "true"}}},
+		}
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		route := getRoute()
+		expectCondition(route.Status.Parents[0].Conditions, "Accepted", metav1.ConditionFalse, "UnsupportedValue")
+		Expect(meta.FindStatusCondition(route.Status.Parents[0].Conditions, "PartiallyInvalid")).To(BeNil())
+	})
+
+	It("Should report routes with some rules with header matches as partially invalid", func() {
+		canary := *httproute.Spec.Rules[0].DeepCopy()
+		canary.Matches = []gatewayv1.HTTPRouteMatch{
+			{Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "true"}}},
+		}
+		httproute.Spec.Rules = append(httproute.Spec.Rules, canary)
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		route := getRoute()
+		expectCondition(route.Status.Parents[0].Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		expectCondition(route.Status.Parents[0].Conditions, "PartiallyInvalid", metav1.ConditionTrue, "UnsupportedValue")
+		Expect(meta.FindStatusCondition(route.Status.Parents[0].Conditions, "PartiallyInvalid").Message).To(HavePrefix("rule 1: "))
+	})
+
+	It("Should keep the parent statuses of other controllers", func() {
+		other := gatewayv1.RouteParentStatus{
+			ParentRef:      gatewayv1.ParentReference{Name: "other-gateway"},
+			ControllerName: "example.com/gateway-controller",
+			Conditions: []metav1.Condition{{
+				Type:               "Accepted",
+				Status:             metav1.ConditionTrue,
+				Reason:             "Accepted",
+				LastTransitionTime: metav1.Now(),
+			}},
+		}
+		httproute.Spec.ParentRefs = append(httproute.Spec.ParentRefs, other.ParentRef)
+		httproute.Status.Parents = []gatewayv1.RouteParentStatus{other}
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		route := getRoute()
+		Expect(route.Status.Parents).To(HaveLen(2))
+		Expect(route.Status.Parents[0].ControllerName).To(Equal(other.ControllerName))
+		Expect(route.Status.Parents[1].ControllerName).To(Equal(DefaultGatewayControllerName))
+	})
+
+	It("Should leave the statuses of gateways of other controllers alone", func() {
+		otherClass := NewTestGatewayClass("other", "example.com/gateway-controller")
+		Expect(driver.store.Add(&otherClass)).To(Succeed())
+		gtw.Spec.GatewayClassName = "other"
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		Expect(getGateway().Status.Conditions).To(BeEmpty())
+		Expect(getGateway().Status.Listeners).To(BeEmpty())
+		Expect(getRoute().Status.Parents).To(BeEmpty())
+	})
+
+	It("Should report route statuses with the store's gateway controller name", func() {
+		driver.withStoreOptions(WithGatewayControllerName("example.com/ngrok"))
+		customClass := NewTestGatewayClass("custom", "example.com/ngrok")
+		Expect(driver.store.Add(&customClass)).To(Succeed())
+		gtw.Spec.GatewayClassName = "custom"
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+
+		expectCondition(getGateway().Status.Conditions, "Accepted", metav1.ConditionTrue, "Accepted")
+		route := getRoute()
+		Expect(route.Status.Parents).To(HaveLen(1))
+		Expect(route.Status.Parents[0].ControllerName).To(Equal(gatewayv1.GatewayController("example.com/ngrok")))
+	})
+
+	It("Should not update statuses that haven't changed", func() {
+		svc := NewTestServiceV1("example", "test-namespace")
+		updateStatuses(&gtw, &httproute, &domain, &svc)
+		gatewayVersion := getGateway().ResourceVersion
+		routeVersion := getRoute().ResourceVersion
+
+		Expect(driver.updateGatewayStatuses(ctx, c)).To(Succeed())
+		Expect(driver.updateHTTPRouteStatuses(ctx, c)).To(Succeed())
+
+		Expect(getGateway().ResourceVersion).To(Equal(gatewayVersion))
+		Expect(getRoute().ResourceVersion).To(Equal(routeVersion))
+	})
+})
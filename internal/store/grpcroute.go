@@ -0,0 +1,128 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// grpcAppProtocol is the app protocol of tunnels to the backends of GRPCRoutes. gRPC needs HTTP/2 end to end,
+// including for trailers, so it's used regardless of the appProtocol of the service's port.
+const grpcAppProtocol = "http2"
+
+// gatewayHTTPRoutes returns the HTTPRoutes in the store, followed by the GRPCRoutes converted to HTTPRoutes,
+// so edges and tunnels are calculated the same way for both. The converted routes have the GRPCRoute's kind.
+func (d *Driver) gatewayHTTPRoutes() []*gatewayv1.HTTPRoute {
+	routes := d.store.ListHTTPRoutes()
+	for _, grpcroute := range d.store.ListGRPCRoutes() {
+		routes = append(routes, grpcRouteToHTTPRoute(grpcroute))
+	}
+	return routes
+}
+
+// isGRPCRoute returns true if the HTTPRoute was converted from a GRPCRoute
+func isGRPCRoute(httproute *gatewayv1.HTTPRoute) bool {
+	return httproute.Kind == "GRPCRoute"
+}
+
+// routeKind returns the kind of the route, which is an HTTPRoute unless it was converted from a GRPCRoute. The
+// kind isn't read from the route's type meta, since it's empty for routes read from the cache.
+func routeKind(route *gatewayv1.HTTPRoute) string {
+	if isGRPCRoute(route) {
+		return "GRPCRoute"
+	}
+	return "HTTPRoute"
+}
+
+// listenerAllowsKind returns true if routes of the kind can attach to the listener. Listeners that don't list
+// the kinds they allow allow every kind.
+func listenerAllowsKind(listener gatewayv1.Listener, kind string) bool {
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		return true
+	}
+	for _, routeKind := range listener.AllowedRoutes.Kinds {
+		if string(routeKind.Kind) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcRouteToHTTPRoute converts the GRPCRoute to an HTTPRoute. gRPC requests are HTTP/2 POST requests to
+// /<service>/<method>, so a method match of a service and method is an exact path match, and one of just a
+// service is a path prefix match. Method matches that can only be expressed as regular expressions are kept as
+// such, and end up being rejected like they are for HTTPRoutes, see unsupportedRuleMatch.
+func grpcRouteToHTTPRoute(grpcroute *gatewayv1alpha2.GRPCRoute) *gatewayv1.HTTPRoute {
+	httproute := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1alpha2.SchemeGroupVersion.String(),
+			Kind:       "GRPCRoute",
+		},
+		ObjectMeta: *grpcroute.ObjectMeta.DeepCopy(),
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: *grpcroute.Spec.CommonRouteSpec.DeepCopy(),
+			Hostnames:       append([]gatewayv1.Hostname(nil), grpcroute.Spec.Hostnames...),
+		},
+	}
+
+	for _, rule := range grpcroute.Spec.Rules {
+		var httpRule gatewayv1.HTTPRouteRule
+		for _, match := range rule.Matches {
+			httpRule.Matches = append(httpRule.Matches, grpcMatchToHTTPMatch(match))
+		}
+		for _, filter := range rule.Filters {
+			httpRule.Filters = append(httpRule.Filters, gatewayv1.HTTPRouteFilter{
+				Type:                   gatewayv1.HTTPRouteFilterType(filter.Type),
+				RequestHeaderModifier:  filter.RequestHeaderModifier,
+				ResponseHeaderModifier: filter.ResponseHeaderModifier,
+				RequestMirror:          filter.RequestMirror,
+				ExtensionRef:           filter.ExtensionRef,
+			})
+		}
+		for _, backendRef := range rule.BackendRefs {
+			httpRule.BackendRefs = append(httpRule.BackendRefs, gatewayv1.HTTPBackendRef{BackendRef: backendRef.BackendRef})
+		}
+		httproute.Spec.Rules = append(httproute.Spec.Rules, httpRule)
+	}
+	return httproute
+}
+
+// grpcMatchToHTTPMatch returns the HTTPRoute match of the gRPC requests the GRPCRoute match matches. A match
+// of a method of any service can't be expressed as an exact path or a path prefix, so it's a regular expression
+// match.
+func grpcMatchToHTTPMatch(match gatewayv1alpha2.GRPCRouteMatch) gatewayv1.HTTPRouteMatch {
+	httpMatch := gatewayv1.HTTPRouteMatch{
+		Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To("/")},
+	}
+	for _, header := range match.Headers {
+		httpMatch.Headers = append(httpMatch.Headers, gatewayv1.HTTPHeaderMatch{
+			Type:  header.Type,
+			Name:  gatewayv1.HTTPHeaderName(header.Name),
+			Value: header.Value,
+		})
+	}
+
+	method := match.Method
+	if method == nil || (method.Service == nil && method.Method == nil) {
+		return httpMatch
+	}
+	matchType := ptr.Deref(method.Type, gatewayv1alpha2.GRPCMethodMatchExact)
+	switch {
+	case matchType == gatewayv1alpha2.GRPCMethodMatchRegularExpression:
+		httpMatch.Path.Type = ptr.To(gatewayv1.PathMatchRegularExpression)
+		httpMatch.Path.Value = ptr.To(fmt.Sprintf("/%s/%s", ptr.Deref(method.Service, "[^/]+"), ptr.Deref(method.Method, ".+")))
+	case method.Service == nil:
+		httpMatch.Path.Type = ptr.To(gatewayv1.PathMatchRegularExpression)
+		httpMatch.Path.Value = ptr.To(fmt.Sprintf("/[^/]+/%s", regexp.QuoteMeta(*method.Method)))
+	case method.Method != nil:
+		httpMatch.Path.Type = ptr.To(gatewayv1.PathMatchExact)
+		httpMatch.Path.Value = ptr.To(fmt.Sprintf("/%s/%s", *method.Service, *method.Method))
+	default:
+		httpMatch.Path.Value = ptr.To(fmt.Sprintf("/%s/", *method.Service))
+	}
+	return httpMatch
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+var _ = Describe("GRPCRoutes", func() {
+	newGRPCRoute := func(matches ...gatewayv1alpha2.GRPCRouteMatch) *gatewayv1alpha2.GRPCRoute {
+		return &gatewayv1alpha2.GRPCRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: "test-namespace", UID: "route-uid"},
+			Spec: gatewayv1alpha2.GRPCRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: []gatewayv1.ParentReference{{Name: "test-gateway"}}},
+				Hostnames:       []gatewayv1.Hostname{"grpc.example.com"},
+				Rules: []gatewayv1alpha2.GRPCRouteRule{{
+					Matches: matches,
+					BackendRefs: []gatewayv1alpha2.GRPCBackendRef{{
+						BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{
+							Kind: ptr.To(gatewayv1.Kind("Service")),
+							Name: "example",
+							Port: ptr.To(gatewayv1.PortNumber(80)),
+						}},
+					}},
+				}},
+			},
+		}
+	}
+
+	DescribeTable("grpcRouteToHTTPRoute path matches",
+		func(method *gatewayv1alpha2.GRPCMethodMatch, pathType gatewayv1.PathMatchType, path string) {
+			httproute := grpcRouteToHTTPRoute(newGRPCRoute(gatewayv1alpha2.GRPCRouteMatch{Method: method}))
+			Expect(httproute.Kind).To(Equal("GRPCRoute"))
+			Expect(httproute.Spec.Hostnames).To(Equal([]gatewayv1.Hostname{"grpc.example.com"}))
+			Expect(httproute.Spec.Rules).To(HaveLen(1))
+			Expect(httproute.Spec.Rules[0].BackendRefs[0].Name).To(Equal(gatewayv1.ObjectName("example")))
+			Expect(httproute.Spec.Rules[0].Matches).To(Equal([]gatewayv1.HTTPRouteMatch{{
+				Path: &gatewayv1.HTTPPathMatch{Type: ptr.To(pathType), Value: ptr.To(path)},
+			}}))
+		},
+		Entry("every method", nil, gatewayv1.PathMatchPathPrefix, "/"),
+		Entry("a service", &gatewayv1alpha2.GRPCMethodMatch{Service: ptr.To("foo.Bar")}, gatewayv1.PathMatchPathPrefix, "/foo.Bar/"),
+		Entry("a service's method", &gatewayv1alpha2.GRPCMethodMatch{Service: ptr.To("foo.Bar"), Method: ptr.To("Get")}, gatewayv1.PathMatchExact, "/foo.Bar/Get"),
+		Entry("a method of any service", &gatewayv1alpha2.GRPCMethodMatch{Method: ptr.To("Get")}, gatewayv1.PathMatchRegularExpression, "/[^/]+/Get"),
+		Entry("a regular expression", &gatewayv1alpha2.GRPCMethodMatch{
+			Type:    ptr.To(gatewayv1alpha2.GRPCMethodMatchRegularExpression),
+			Service: ptr.To("foo\\..*"),
+		}, gatewayv1.PathMatchRegularExpression, "/foo\\..*/.+"),
+	)
+
+	It("Should route each of a rule's method matches", func() {
+		httproute := grpcRouteToHTTPRoute(newGRPCRoute(
+			gatewayv1alpha2.GRPCRouteMatch{Method: &gatewayv1alpha2.GRPCMethodMatch{Service: ptr.To("foo.Bar"), Method: ptr.To("Get")}},
+			gatewayv1alpha2.GRPCRouteMatch{Method: &gatewayv1alpha2.GRPCMethodMatch{Service: ptr.To("foo.Baz")}},
+		))
+
+		Expect(gatewayRulePathMatches(httproute.Spec.Rules[0])).To(Equal([]gatewayRulePathMatch{
+			{match: "/foo.Bar/Get", matchType: MatchTypeExactPath},
+			{match: "/foo.Baz/", matchType: MatchTypePathPrefix},
+		}))
+	})
+
+	It("Should not accept routes matching a method of any service", func() {
+		driver := NewDriver(logr.Discard(), runtime.NewScheme(), defaultControllerName, types.NamespacedName{Name: defaultManagerName}, true)
+		gwClass := NewTestGatewayClass("ngrok", DefaultGatewayControllerName)
+		gtw := NewTestGateway("test-gateway", "test-namespace", "ngrok")
+		gtw.Spec.Listeners[0].Hostname = ptr.To(gatewayv1.Hostname("grpc.example.com"))
+		Expect(driver.store.Add(&gwClass)).To(Succeed())
+		Expect(driver.store.Add(&gtw)).To(Succeed())
+
+		httproute := grpcRouteToHTTPRoute(newGRPCRoute(
+			gatewayv1alpha2.GRPCRouteMatch{Method: &gatewayv1alpha2.GRPCMethodMatch{Method: ptr.To("Get")}},
+		))
+		Expect(unsupportedRuleMatch(httproute.Spec.Rules[0])).ToNot(Succeed())
+
+		parents := driver.calculateRouteParentStatuses(httproute, nil)
+		Expect(parents).To(HaveLen(1))
+		accepted := meta.FindStatusCondition(parents[0].Conditions, "Accepted")
+		Expect(accepted).ToNot(BeNil())
+		Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+		Expect(accepted.Reason).To(Equal("UnsupportedValue"))
+	})
+
+	It("Should make HTTP/2 tunnels to the backends of GRPCRoutes", func() {
+		driver := NewDriver(logr.Discard(), runtime.NewScheme(), defaultControllerName, types.NamespacedName{Name: defaultManagerName}, true)
+		svc := NewTestServiceV1("example", "test-namespace")
+		Expect(driver.store.Add(&svc)).To(Succeed())
+		Expect(driver.store.Add(newGRPCRoute())).To(Succeed())
+
+		tunnels := map[tunnelKey]ingressv1alpha1.Tunnel{}
+		driver.calculateTunnelsFromGateway(tunnels)
+
+		Expect(tunnels).To(HaveLen(1))
+		for _, tunnel := range tunnels {
+			Expect(tunnel.Spec.ForwardsTo).To(Equal("example.test-namespace.svc.cluster.local:80"))
+			Expect(tunnel.Spec.AppProtocol).To(Equal("http2"))
+			Expect(tunnel.OwnerReferences).To(HaveLen(1))
+			Expect(tunnel.OwnerReferences[0].Kind).To(Equal("GRPCRoute"))
+			Expect(tunnel.OwnerReferences[0].UID).To(Equal(types.UID("route-uid")))
+		}
+	})
+})
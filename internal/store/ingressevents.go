@@ -0,0 +1,167 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/events"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// ingressEvents records events on ingresses as the ingress reconciler finds the controller accepts them or drops
+// them for using another controller's ingress class. Ingresses are reconciled again on every resync, so each
+// decision is only recorded once per version of an ingress.
+//
+// It also tracks the issues syncs find translating ingresses, like route conflicts and missing backends. Every
+// sync finds the same issues again until they're fixed, so an issue is only recorded when it first appears, and
+// again if it reappears after a sync without it.
+type ingressEvents struct {
+	recorder record.EventRecorder
+
+	mu       sync.Mutex
+	recorded map[string]string
+
+	// pending are the issues found by the sync in progress, found the ones of the last completed sync, and
+	// reported the ones recorded last
+	pending  map[string]ingressIssues
+	found    map[string]ingressIssues
+	reported map[string]ingressIssues
+}
+
+// ingressIssue is a warning about translating an ingress
+type ingressIssue struct {
+	reason  string
+	message string
+}
+
+// ingressIssues are the issues found translating an ingress
+type ingressIssues struct {
+	ingress *netv1.Ingress
+	issues  map[ingressIssue]bool
+}
+
+func newIngressEvents(recorder record.EventRecorder) *ingressEvents {
+	return &ingressEvents{
+		recorder: recorder,
+		recorded: map[string]string{},
+		pending:  map[string]ingressIssues{},
+		found:    map[string]ingressIssues{},
+		reported: map[string]ingressIssues{},
+	}
+}
+
+// record emits an event for the result of checking whether the ingress should be handled. It does nothing
+// without a recorder, or for errors other than a class mismatch.
+func (e *ingressEvents) record(ing *netv1.Ingress, handleErr error) {
+	if e == nil || e.recorder == nil {
+		return
+	}
+
+	eventType, reason, message := corev1.EventTypeNormal, events.ReasonIngressAccepted, "Ingress is handled by the ngrok ingress controller"
+	switch {
+	case handleErr == nil:
+	case errors.IsErrDifferentIngressClass(handleErr):
+		eventType, reason, message = corev1.EventTypeWarning, events.ReasonIngressClassMismatch, handleErr.Error()
+	default:
+		return
+	}
+
+	key := getKey(ing.Name, ing.Namespace)
+	decision := ing.ResourceVersion + "/" + reason
+	e.mu.Lock()
+	if e.recorded[key] == decision {
+		e.mu.Unlock()
+		return
+	}
+	e.recorded[key] = decision
+	e.mu.Unlock()
+
+	e.recorder.Event(ing, eventType, reason, message)
+}
+
+// forget removes what was recorded for the ingress once it's deleted, so the decisions for deleted ingresses
+// don't pile up.
+func (e *ingressEvents) forget(ing *netv1.Ingress) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := getKey(ing.Name, ing.Namespace)
+	delete(e.recorded, key)
+	delete(e.pending, key)
+	delete(e.found, key)
+	delete(e.reported, key)
+}
+
+// beginSync drops the issues found by a sync that didn't complete, so the issues found next are only the ones of
+// the sync starting
+func (e *ingressEvents) beginSync() {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending = map[string]ingressIssues{}
+}
+
+// report adds an issue the sync in progress found translating the ingress
+func (e *ingressEvents) report(ing *netv1.Ingress, reason, message string) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := getKey(ing.Name, ing.Namespace)
+	found, ok := e.pending[key]
+	if !ok {
+		found = ingressIssues{ingress: ing, issues: map[ingressIssue]bool{}}
+		e.pending[key] = found
+	}
+	found.issues[ingressIssue{reason: reason, message: message}] = true
+}
+
+// completeSync makes the issues found by the sync in progress the ones to record
+func (e *ingressEvents) completeSync() {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.found = e.pending
+	e.pending = map[string]ingressIssues{}
+}
+
+// recordIssues emits a Warning event for each issue found by the last completed sync that wasn't found when the
+// issues were last recorded. It does nothing without a recorder.
+func (e *ingressEvents) recordIssues() {
+	if e == nil || e.recorder == nil {
+		return
+	}
+
+	e.mu.Lock()
+	var issues []ingressIssues
+	for key, found := range e.found {
+		issue := ingressIssues{ingress: found.ingress, issues: map[ingressIssue]bool{}}
+		for i := range found.issues {
+			if !e.reported[key].issues[i] {
+				issue.issues[i] = true
+			}
+		}
+		issues = append(issues, issue)
+	}
+	e.reported = e.found
+	e.mu.Unlock()
+
+	for _, issue := range issues {
+		for i := range issue.issues {
+			e.recorder.Event(issue.ingress, corev1.EventTypeWarning, i.reason, i.message)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations/parser"
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+)
+
+// ValidateNgrokIngress checks an ingress against the stricter rules for ngrok ingresses that should only be
+// served over HTTPS: every rule's host must be covered by the hosts of a spec.tls entry, and every backend
+// service must specify a port by number or name. The errors have the paths of the offending fields so they
+// can be returned as is from an admission webhook. Unlike the store's own checks, ingresses failing these
+// rules are still handled by the controller.
+func ValidateNgrokIngress(ing *netv1.Ingress) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	var tlsHosts []string
+	for _, tls := range ing.Spec.TLS {
+		tlsHosts = append(tlsHosts, tls.Hosts...)
+	}
+
+	if ing.Spec.DefaultBackend != nil {
+		errs = append(errs, validateBackendPort(ing.Spec.DefaultBackend, specPath.Child("defaultBackend"))...)
+	}
+
+	for i, rule := range ing.Spec.Rules {
+		rulePath := specPath.Child("rules").Index(i)
+		if rule.Host != "" && !tlsHostsCover(tlsHosts, rule.Host) {
+			errs = append(errs, field.Invalid(rulePath.Child("host"), rule.Host, "host must be listed in the hosts of spec.tls"))
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for j := range rule.HTTP.Paths {
+			backendPath := rulePath.Child("http", "paths").Index(j).Child("backend")
+			errs = append(errs, validateBackendPort(&rule.HTTP.Paths[j].Backend, backendPath)...)
+		}
+	}
+	return errs
+}
+
+// ValidateModuleSetReferences checks that every NgrokModuleSet named by the ingress's modules and path-modules
+// annotations exists in the ingress's namespace and is valid. The controller only resolves module sets when it
+// reconciles the ingress, so this lets an admission webhook report dangling references when the ingress
+// is applied instead.
+func ValidateModuleSetReferences(ing *netv1.Ingress, store Storer) field.ErrorList {
+	var errs field.ErrorList
+
+	annotation := parser.GetAnnotationWithPrefix("modules")
+	annotationPath := field.NewPath("metadata", "annotations").Key(annotation)
+	modules, err := annotations.ExtractNgrokModuleSetsFromAnnotations(ing)
+	if err == nil {
+		errs = append(errs, validateModuleSetNames(modules, ing.Namespace, annotationPath, store)...)
+	} else if !errors.IsMissingAnnotations(err) {
+		errs = append(errs, field.Invalid(annotationPath, ing.Annotations[annotation], err.Error()))
+	}
+
+	pathAnnotation := parser.GetAnnotationWithPrefix("path-modules")
+	pathAnnotationPath := field.NewPath("metadata", "annotations").Key(pathAnnotation)
+	pathModules, err := annotations.ExtractPathNgrokModuleSetsFromAnnotations(ing)
+	if err != nil {
+		if !errors.IsMissingAnnotations(err) {
+			errs = append(errs, field.Invalid(pathAnnotationPath, ing.Annotations[pathAnnotation], err.Error()))
+		}
+		return errs
+	}
+
+	paths := map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		for _, path := range ingressRulePaths(ing, rule) {
+			paths[path.Path] = true
+		}
+	}
+	// validate the paths in a stable order so repeated admission requests report the same errors
+	annotatedPaths := maps.Keys(pathModules)
+	slices.Sort(annotatedPaths)
+	for _, path := range annotatedPaths {
+		if !paths[path] {
+			errs = append(errs, field.Invalid(pathAnnotationPath, path, "path doesn't match any path of the ingress"))
+			continue
+		}
+		errs = append(errs, validateModuleSetNames(pathModules[path], ing.Namespace, pathAnnotationPath, store)...)
+	}
+	return errs
+}
+
+// validateModuleSetNames checks that each named NgrokModuleSet exists in the namespace and is valid
+func validateModuleSetNames(modules []string, namespace string, annotationPath *field.Path, store Storer) field.ErrorList {
+	moduleSetGVK := ingressv1alpha1.GroupVersion.WithKind("NgrokModuleSet")
+	var errs field.ErrorList
+	for _, module := range modules {
+		if module == "" {
+			errs = append(errs, field.Invalid(annotationPath, module, "module set names can't be empty"))
+			continue
+		}
+		if _, err := store.GetNgrokModuleSetV1(module, namespace); err != nil {
+			// the module set may exist but reference a Secret that doesn't, which is reported as invalid
+			if notFound, ok := err.(*errors.NotFoundError); ok && notFound.GVK() == moduleSetGVK {
+				errs = append(errs, field.NotFound(annotationPath, module))
+			} else {
+				errs = append(errs, field.Invalid(annotationPath, module, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+// validateBackendPort requires a service backend to specify its port's number or name
+func validateBackendPort(backend *netv1.IngressBackend, path *field.Path) field.ErrorList {
+	if backend.Service == nil {
+		return nil
+	}
+	port := backend.Service.Port
+	if port.Number == 0 && port.Name == "" {
+		return field.ErrorList{field.Required(path.Child("service", "port"), "backend service must specify a port number or name")}
+	}
+	return nil
+}
+
+// tlsHostsCover returns true if the host is one of the TLS hosts, or is matched by a wildcard TLS host like
+// "*.example.com" that covers a single label
+func tlsHostsCover(tlsHosts []string, host string) bool {
+	for _, tlsHost := range tlsHosts {
+		if strings.EqualFold(tlsHost, host) {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(tlsHost, "*"); ok {
+			label, found := strings.CutSuffix(strings.ToLower(host), strings.ToLower(suffix))
+			if found && label != "" && !strings.Contains(label, ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
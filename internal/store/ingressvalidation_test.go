@@ -0,0 +1,142 @@
+package store
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+var _ = Describe("ValidateNgrokIngress", func() {
+	var ing netv1.Ingress
+
+	BeforeEach(func() {
+		ing = NewTestIngressV1("test-ingress", "test-namespace")
+		ing.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{"example.com"}}}
+	})
+
+	It("Should accept an ingress with TLS for its host and backend ports", func() {
+		ing.Spec.DefaultBackend = &netv1.IngressBackend{
+			Service: &netv1.IngressServiceBackend{Name: "default", Port: netv1.ServiceBackendPort{Name: "http"}},
+		}
+		Expect(ValidateNgrokIngress(&ing)).To(BeEmpty())
+	})
+
+	It("Should accept a host covered by a wildcard TLS host", func() {
+		ing.Spec.Rules[0].Host = "app.example.com"
+		ing.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{"*.example.com"}}}
+		Expect(ValidateNgrokIngress(&ing)).To(BeEmpty())
+
+		ing.Spec.Rules[0].Host = "deep.app.example.com"
+		Expect(ValidateNgrokIngress(&ing)).To(HaveLen(1))
+	})
+
+	It("Should reject a host missing from spec.tls", func() {
+		ing.Spec.TLS = nil
+		errs := ValidateNgrokIngress(&ing)
+		Expect(errs).To(Equal(field.ErrorList{
+			field.Invalid(field.NewPath("spec", "rules").Index(0).Child("host"), "example.com", "host must be listed in the hosts of spec.tls"),
+		}))
+		Expect(errs.ToAggregate().Error()).To(Equal(`spec.rules[0].host: Invalid value: "example.com": host must be listed in the hosts of spec.tls`))
+	})
+
+	It("Should reject backends without a port", func() {
+		ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = netv1.ServiceBackendPort{}
+		ing.Spec.DefaultBackend = &netv1.IngressBackend{
+			Service: &netv1.IngressServiceBackend{Name: "default"},
+		}
+		errs := ValidateNgrokIngress(&ing)
+		Expect(errs).To(Equal(field.ErrorList{
+			field.Required(field.NewPath("spec", "defaultBackend", "service", "port"), "backend service must specify a port number or name"),
+			field.Required(field.NewPath("spec", "rules").Index(0).Child("http", "paths").Index(0).Child("backend", "service", "port"), "backend service must specify a port number or name"),
+		}))
+	})
+})
+
+var _ = Describe("ValidateModuleSetReferences", func() {
+	var store Storer
+	var ing netv1.Ingress
+	annotationPath := field.NewPath("metadata", "annotations").Key("k8s.ngrok.com/modules")
+
+	BeforeEach(func() {
+		logger := logr.New(logr.Discard().GetSink())
+		store = New(NewCacheStores(logger), defaultControllerName, logger)
+		rateLimit := NewTestNgrokModuleSet("rate-limit", "test-namespace", false)
+		rateLimit.Modules.RateLimit = NewTestNgrokModuleSetModules().RateLimit
+		for _, ms := range []ingressv1alpha1.NgrokModuleSet{
+			NewTestNgrokModuleSet("compression", "test-namespace", true),
+			rateLimit,
+			NewTestNgrokModuleSet("other-namespace", "other", true),
+		} {
+			Expect(store.Add(&ms)).To(BeNil())
+		}
+		ing = NewTestIngressV1("test-ingress", "test-namespace")
+	})
+
+	It("Should accept an ingress without the modules annotation", func() {
+		Expect(ValidateModuleSetReferences(&ing, store)).To(BeEmpty())
+	})
+
+	It("Should accept references to existing module sets", func() {
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "compression, rate-limit"})
+		Expect(ValidateModuleSetReferences(&ing, store)).To(BeEmpty())
+	})
+
+	It("Should reject dangling references", func() {
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "compression,missing,other-namespace"})
+		Expect(ValidateModuleSetReferences(&ing, store)).To(Equal(field.ErrorList{
+			field.NotFound(annotationPath, "missing"),
+			field.NotFound(annotationPath, "other-namespace"),
+		}))
+	})
+
+	It("Should reject module sets with headers using unknown variables", func() {
+		ms := NewTestNgrokModuleSet("geo-headers", "test-namespace", false)
+		ms.Modules.Headers = NewTestNgrokModuleSetModules().Headers
+		ms.Modules.Headers.Request.Add = map[string]string{"X-Country": "${.geo.country_code}"}
+		Expect(store.Add(&ms)).To(BeNil())
+
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "geo-headers"})
+		errs := ValidateModuleSetReferences(&ing, store)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeInvalid))
+		Expect(errs[0].Detail).To(ContainSubstring(`request header X-Country uses unknown variable ".geo.country_code"`))
+	})
+
+	It("Should check the module sets of the path-modules annotation", func() {
+		pathAnnotationPath := field.NewPath("metadata", "annotations").Key("k8s.ngrok.com/path-modules")
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/path-modules": `{"/": "compression,rate-limit"}`})
+		Expect(ValidateModuleSetReferences(&ing, store)).To(BeEmpty())
+
+		ing.SetAnnotations(map[string]string{
+			"k8s.ngrok.com/modules":      "compression",
+			"k8s.ngrok.com/path-modules": `{"/": "missing", "/admin": "rate-limit"}`,
+		})
+		Expect(ValidateModuleSetReferences(&ing, store)).To(Equal(field.ErrorList{
+			field.NotFound(pathAnnotationPath, "missing"),
+			field.Invalid(pathAnnotationPath, "/admin", "path doesn't match any path of the ingress"),
+		}))
+
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/path-modules": "/admin=rate-limit"})
+		errs := ValidateModuleSetReferences(&ing, store)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeInvalid))
+		Expect(errs[0].Field).To(Equal(pathAnnotationPath.String()))
+	})
+
+	It("Should reject a malformed annotation", func() {
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": " "})
+		errs := ValidateModuleSetReferences(&ing, store)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeInvalid))
+		Expect(errs[0].Field).To(Equal(annotationPath.String()))
+
+		ing.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "compression,,rate-limit"})
+		Expect(ValidateModuleSetReferences(&ing, store)).To(Equal(field.ErrorList{
+			field.Invalid(annotationPath, "", "module set names can't be empty"),
+		}))
+	})
+})
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var storeObjectsDesc = prometheus.NewDesc(
+	"ngrok_ingress_controller_store_objects",
+	"The number of objects of each type currently cached in the store.",
+	[]string{"object_type"},
+	nil,
+)
+
+// storeCollector is a prometheus.Collector reporting the number of objects cached in a store. The counts are
+// taken from the store's list methods on each scrape, so they're always current.
+type storeCollector struct {
+	store Storer
+}
+
+// RegisterStoreMetrics registers gauges with the number of ingresses, ngrok ingresses, ingress classes, services
+// and module sets cached in the store, labeled by object_type.
+func RegisterStoreMetrics(store Storer, reg prometheus.Registerer) error {
+	return reg.Register(&storeCollector{store: store})
+}
+
+// Describe implements prometheus.Collector
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storeObjectsDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := map[string]int{
+		"ingress":         len(c.store.ListIngressesV1()),
+		"ngrok_ingress":   len(c.store.ListNgrokIngressesV1()),
+		"ingress_class":   len(c.store.ListIngressClassesV1()),
+		"service":         len(c.store.ListServicesV1()),
+		"ngrok_moduleset": len(c.store.ListNgrokModuleSetsV1()),
+	}
+	for objectType, count := range counts {
+		ch <- prometheus.MustNewConstMetric(storeObjectsDesc, prometheus.GaugeValue, float64(count), objectType)
+	}
+}
+
+// RegisterMetrics registers the metrics for the driver's store with reg. See RegisterStoreMetrics.
+func (d *Driver) RegisterMetrics(reg prometheus.Registerer) error {
+	return RegisterStoreMetrics(d.store, reg)
+}
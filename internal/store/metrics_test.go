@@ -0,0 +1,64 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("RegisterStoreMetrics", func() {
+	var store Storer
+	var reg *prometheus.Registry
+
+	BeforeEach(func() {
+		logger := logr.New(logr.Discard().GetSink())
+		store = New(NewCacheStores(logger), defaultControllerName, logger)
+		reg = prometheus.NewRegistry()
+		Expect(RegisterStoreMetrics(store, reg)).To(Succeed())
+
+		ngrokClass := NewTestIngressClass(ngrokIngressClass, true, true)
+		otherClass := NewTestIngressClass("other", false, false)
+		ngrokIng1 := NewTestIngressV1WithClass("ngrok-1", "test-namespace", ngrokIngressClass)
+		ngrokIng2 := NewTestIngressV1WithClass("ngrok-2", "test-namespace", ngrokIngressClass)
+		otherIng := NewTestIngressV1WithClass("other", "test-namespace", "other")
+		svc := NewTestServiceV1("example", "test-namespace")
+		moduleSet := NewTestNgrokModuleSet("modules", "test-namespace", true)
+		for _, obj := range []runtime.Object{&ngrokClass, &otherClass, &ngrokIng1, &ngrokIng2, &otherIng, &svc, &moduleSet} {
+			Expect(store.Add(obj)).To(Succeed())
+		}
+	})
+
+	It("Should report the number of objects in the store", func() {
+		expected := `
+# HELP ngrok_ingress_controller_store_objects The number of objects of each type currently cached in the store.
+# TYPE ngrok_ingress_controller_store_objects gauge
+ngrok_ingress_controller_store_objects{object_type="ingress"} 3
+ngrok_ingress_controller_store_objects{object_type="ingress_class"} 2
+ngrok_ingress_controller_store_objects{object_type="ngrok_ingress"} 2
+ngrok_ingress_controller_store_objects{object_type="ngrok_moduleset"} 1
+ngrok_ingress_controller_store_objects{object_type="service"} 1
+`
+		Expect(testutil.GatherAndCompare(reg, strings.NewReader(expected))).To(Succeed())
+	})
+
+	It("Should refresh the counts on each scrape", func() {
+		svc := NewTestServiceV1("another", "test-namespace")
+		Expect(store.Add(&svc)).To(Succeed())
+
+		expected := `
+# HELP ngrok_ingress_controller_store_objects The number of objects of each type currently cached in the store.
+# TYPE ngrok_ingress_controller_store_objects gauge
+ngrok_ingress_controller_store_objects{object_type="ingress"} 3
+ngrok_ingress_controller_store_objects{object_type="ingress_class"} 2
+ngrok_ingress_controller_store_objects{object_type="ngrok_ingress"} 2
+ngrok_ingress_controller_store_objects{object_type="ngrok_moduleset"} 1
+ngrok_ingress_controller_store_objects{object_type="service"} 2
+`
+		Expect(testutil.GatherAndCompare(reg, strings.NewReader(expected))).To(Succeed())
+	})
+})
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+const (
+	// MatchTypePathPrefix is the ngrok route match type of Prefix and ImplementationSpecific ingress paths
+	MatchTypePathPrefix = "path_prefix"
+	// MatchTypeExactPath is the ngrok route match type of Exact ingress paths
+	MatchTypeExactPath = "exact_path"
+
+	// pathExpressionMetacharacters are the characters that make an ImplementationSpecific path a regular
+	// expression instead of a prefix. Dots are left out since they're common in literal paths like /robots.txt.
+	pathExpressionMetacharacters = `^$*+?()[]{}|\`
+)
+
+// NormalizedRoute is an ingress path with the ngrok match type of its path type resolved
+type NormalizedRoute struct {
+	Host      string
+	Path      string
+	MatchType string
+	// Expression is the regular expression the request path must also match, for ImplementationSpecific paths
+	// that are path expressions. The Path is then the expression's literal prefix.
+	Expression string
+	Backend    netv1.IngressBackend
+}
+
+// pathMatchType returns the ngrok route match type of the ingress path type. A path without a type, or with
+// the ImplementationSpecific type, is matched as a prefix. ImplementationSpecific paths that are path
+// expressions are further restricted by their expression, see pathExpressionRoute.
+func pathMatchType(pathType *netv1.PathType) (string, bool) {
+	if pathType == nil {
+		return MatchTypePathPrefix, true
+	}
+	switch *pathType {
+	case netv1.PathTypePrefix, netv1.PathTypeImplementationSpecific:
+		return MatchTypePathPrefix, true
+	case netv1.PathTypeExact:
+		return MatchTypeExactPath, true
+	default:
+		return "", false
+	}
+}
+
+// ingressRulePaths returns the HTTP paths of the ingress rule. The ingress's default backend handles the
+// requests that don't match any of them, so it's added as a catch-all "/" Prefix path unless the rule already
+// has one. Routes are ordered longest match first, so the catch-all doesn't shadow the rule's other paths.
+func ingressRulePaths(ing *netv1.Ingress, rule netv1.IngressRule) []netv1.HTTPIngressPath {
+	var paths []netv1.HTTPIngressPath
+	if rule.HTTP != nil {
+		paths = slices.Clone(rule.HTTP.Paths)
+	}
+	if ing.Spec.DefaultBackend == nil {
+		return paths
+	}
+
+	for _, path := range paths {
+		matchType, ok := pathMatchType(path.PathType)
+		if ok && matchType == MatchTypePathPrefix && path.Path == "/" {
+			return paths
+		}
+	}
+	return append(paths, netv1.HTTPIngressPath{
+		Path:     "/",
+		PathType: ptr.To(netv1.PathTypePrefix),
+		Backend:  *ing.Spec.DefaultBackend,
+	})
+}
+
+// isPathExpression returns true if the ingress path is an ImplementationSpecific path with regular expression
+// metacharacters, which is matched as a path expression
+func isPathExpression(path netv1.HTTPIngressPath) bool {
+	return path.PathType != nil && *path.PathType == netv1.PathTypeImplementationSpecific &&
+		strings.ContainsAny(path.Path, pathExpressionMetacharacters)
+}
+
+// pathExpressionRoute returns the prefix an ngrok route matches for the path expression, along with the
+// expression anchored to the start of the path. ngrok routes can only match exact paths and prefixes, so the
+// route matches the expression's literal prefix and its traffic policy rejects the requests the expression
+// doesn't match, see withPathExpressionPolicy. Like prefixes, expressions aren't anchored to the end of the
+// path unless they end with $.
+func pathExpressionRoute(path string) (string, string, error) {
+	unanchored := strings.TrimPrefix(path, "^")
+	re, err := regexp.Compile(unanchored)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path expression: %w", err)
+	}
+
+	prefix, _ := re.LiteralPrefix()
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/"
+	}
+	return prefix, "^(?:" + unanchored + ")", nil
+}
+
+// pathExpressionRuleName is the name of the inbound rule that rejects requests that don't match the path expression
+const pathExpressionRuleName = "Match path expression"
+
+// withPathExpressionPolicy adds a rule to the start of the policy's inbound rules that responds with a 404 to
+// requests whose path doesn't match the anchored path expression, before any other rule acts on them. This
+// is only correct when no other route would serve those requests, so expressions whose literal prefix is
+// covered by another route aren't routed, see coveringPrefixRoute.
+func withPathExpressionPolicy(policyJSON json.RawMessage, expression string) (json.RawMessage, error) {
+	config, err := json.Marshal(CustomResponseConfig{StatusCode: 404})
+	if err != nil {
+		return nil, err
+	}
+
+	rule := ingressv1alpha1.EndpointRule{
+		Name:        pathExpressionRuleName,
+		Expressions: []string{fmt.Sprintf("!req.url.path.matches(%s)", strconv.Quote(expression))},
+		Actions:     []ingressv1alpha1.EndpointAction{{Type: "custom-response", Config: config}},
+	}
+	return withPolicyRules(policyJSON, []ingressv1alpha1.EndpointRule{rule}, nil)
+}
+
+// NormalizePaths returns the routes of the ingress's paths, in order, with the ngrok match type of each path
+// resolved from its path type. An empty path matches every request, so it's normalized to "/". Exact paths
+// are matched literally, so an error is returned for one with a wildcard, as well as for an unknown path type
+// and an invalid path expression.
+func (s Store) NormalizePaths(ing *netv1.Ingress) ([]NormalizedRoute, error) {
+	var routes []NormalizedRoute
+	var errs field.ErrorList
+	rulesPath := field.NewPath("spec", "rules")
+	supportedPathTypes := []string{string(netv1.PathTypeExact), string(netv1.PathTypePrefix), string(netv1.PathTypeImplementationSpecific)}
+
+	for i, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for j, path := range rule.HTTP.Paths {
+			pathPath := rulesPath.Index(i).Child("http", "paths").Index(j)
+
+			matchType, ok := pathMatchType(path.PathType)
+			if !ok {
+				errs = append(errs, field.NotSupported(pathPath.Child("pathType"), *path.PathType, supportedPathTypes))
+				continue
+			}
+			if matchType == MatchTypeExactPath && strings.Contains(path.Path, "*") {
+				errs = append(errs, field.Invalid(pathPath.Child("path"), path.Path, "exact paths can't contain wildcards"))
+				continue
+			}
+
+			p := path.Path
+			if p == "" {
+				p = "/"
+			}
+			var expression string
+			if isPathExpression(path) {
+				var err error
+				p, expression, err = pathExpressionRoute(path.Path)
+				if err != nil {
+					errs = append(errs, field.Invalid(pathPath.Child("path"), path.Path, err.Error()))
+					continue
+				}
+			}
+			routes = append(routes, NormalizedRoute{
+				Host:       rule.Host,
+				Path:       p,
+				MatchType:  matchType,
+				Expression: expression,
+				Backend:    path.Backend,
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return routes, nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+const (
+	// rateLimitWindow is the window the rate limit module's requests per second are counted over
+	rateLimitWindow = "1s"
+
+	// clientIPBucketKey counts the requests of each client IP separately
+	clientIPBucketKey = "conn.client_ip"
+)
+
+type RateLimitConfig struct {
+	Name      string   `json:"name"`
+	Algorithm string   `json:"algorithm"`
+	Capacity  int32    `json:"capacity"`
+	Rate      string   `json:"rate"`
+	BucketKey []string `json:"bucket_key,omitempty"`
+}
+
+// rateLimitPolicyRule builds the inbound policy rule that rejects requests over the module's rate limit
+func rateLimitPolicyRule(rateLimit *ingressv1alpha1.EndpointRateLimit) (ingressv1alpha1.EndpointRule, error) {
+	if rateLimit.RequestsPerSecond <= 0 {
+		return ingressv1alpha1.EndpointRule{}, fmt.Errorf("rate limit requests per second must be positive, got %d", rateLimit.RequestsPerSecond)
+	}
+	if rateLimit.Burst < 0 {
+		return ingressv1alpha1.EndpointRule{}, fmt.Errorf("rate limit burst can't be negative, got %d", rateLimit.Burst)
+	}
+
+	rateLimitConfig := RateLimitConfig{
+		Name:      "Rate limit",
+		Algorithm: "sliding_window",
+		Capacity:  rateLimit.RequestsPerSecond + rateLimit.Burst,
+		Rate:      rateLimitWindow,
+	}
+	if rateLimit.PerClientIP {
+		rateLimitConfig.BucketKey = []string{clientIPBucketKey}
+	}
+
+	config, err := json.Marshal(rateLimitConfig)
+	if err != nil {
+		return ingressv1alpha1.EndpointRule{}, err
+	}
+
+	return ingressv1alpha1.EndpointRule{
+		Name: "Rate limit",
+		Actions: []ingressv1alpha1.EndpointAction{
+			{
+				Type:   "rate-limit",
+				Config: config,
+			},
+		},
+	}, nil
+}
+
+// withRateLimitPolicy adds the rate limit rule to the front of the policy's inbound rules so requests over the
+// limit are rejected before any of the other rules run
+func withRateLimitPolicy(policyJSON json.RawMessage, rateLimit *ingressv1alpha1.EndpointRateLimit) (json.RawMessage, error) {
+	if rateLimit == nil {
+		return policyJSON, nil
+	}
+
+	rule, err := rateLimitPolicyRule(rateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return withPolicyRules(policyJSON, []ingressv1alpha1.EndpointRule{rule}, nil)
+}
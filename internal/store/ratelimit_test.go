@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+)
+
+var _ = Describe("RateLimit", func() {
+	decodePolicy := func(policyJSON json.RawMessage) ingressv1alpha1.EndpointPolicy {
+		policy := ingressv1alpha1.EndpointPolicy{}
+		Expect(json.Unmarshal(policyJSON, &policy)).To(Succeed())
+		return policy
+	}
+
+	decodeConfig := func(rule ingressv1alpha1.EndpointRule) RateLimitConfig {
+		Expect(rule.Actions).To(HaveLen(1))
+		Expect(rule.Actions[0].Type).To(Equal("rate-limit"))
+		config := RateLimitConfig{}
+		Expect(json.Unmarshal(rule.Actions[0].Config, &config)).To(Succeed())
+		return config
+	}
+
+	Describe("withRateLimitPolicy", func() {
+		It("Should allow the requests per second plus the burst", func() {
+			policyJSON, err := withRateLimitPolicy(nil, &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 10, Burst: 5})
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(1))
+			config := decodeConfig(policy.Inbound[0])
+			Expect(config.Capacity).To(Equal(int32(15)))
+			Expect(config.Rate).To(Equal("1s"))
+			Expect(config.BucketKey).To(BeEmpty())
+		})
+
+		It("Should limit each client IP separately", func() {
+			policyJSON, err := withRateLimitPolicy(nil, &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 10, PerClientIP: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			config := decodeConfig(decodePolicy(policyJSON).Inbound[0])
+			Expect(config.BucketKey).To(Equal([]string{"conn.client_ip"}))
+		})
+
+		It("Should run before the existing policy rules", func() {
+			policyJSON, err := withRateLimitPolicy(json.RawMessage(`{"inbound":[{"name":"existing"}]}`), &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(policy.Inbound[0].Name).To(Equal("Rate limit"))
+			Expect(policy.Inbound[1].Name).To(Equal("existing"))
+		})
+
+		It("Should error unless the requests per second is positive", func() {
+			_, err := withRateLimitPolicy(nil, &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 0})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should leave the policy alone without a rate limit", func() {
+			policyJSON, err := withRateLimitPolicy(json.RawMessage(`{"inbound":[]}`), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(policyJSON)).To(Equal(`{"inbound":[]}`))
+		})
+	})
+
+	Describe("Sync", func() {
+		It("Should add the rate limit rule to the edge routes of ingresses using the module", func() {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+			utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+			driver := NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, false)
+			driver.syncAllowConcurrent = true
+
+			ms := NewTestNgrokModuleSet("rate-limited", "test-namespace", false)
+			ms.Modules.RateLimit = &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 10}
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "rate-limited"})
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic1, &i1, &s, &d1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+			Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+
+			policy := decodePolicy(foundEdges.Items[0].Spec.Routes[0].Policy)
+			Expect(policy.Inbound).To(HaveLen(1))
+			Expect(decodeConfig(policy.Inbound[0]).Capacity).To(Equal(int32(10)))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// refNotPermittedError is returned for a route's backendRef to a service in another namespace that no
+// ReferenceGrant in the service's namespace allows the route to reference
+type refNotPermittedError struct {
+	routeKind      string
+	routeNamespace string
+	namespace      string
+	name           string
+}
+
+// Error: Stringer: returns the error message
+func (e *refNotPermittedError) Error() string {
+	return fmt.Sprintf("no ReferenceGrant in namespace %s allows %s routes in namespace %s to reference service %s",
+		e.namespace, e.routeKind, e.routeNamespace, e.name)
+}
+
+// backendRefNamespace returns the namespace of the service a route's backendRef references. The service is in
+// the route's namespace unless the backendRef names another namespace, which is only allowed when a
+// ReferenceGrant in that namespace permits routes of the route's kind in the route's namespace to reference it.
+func (d *Driver) backendRefNamespace(route *gatewayv1.HTTPRoute, backendRef gatewayv1.BackendRef) (string, error) {
+	if backendRef.Namespace == nil || string(*backendRef.Namespace) == route.Namespace {
+		return route.Namespace, nil
+	}
+
+	namespace := string(*backendRef.Namespace)
+	kind := routeKind(route)
+	if !d.referenceGrantAllows(kind, route.Namespace, namespace, string(backendRef.Name)) {
+		return "", &refNotPermittedError{
+			routeKind:      kind,
+			routeNamespace: route.Namespace,
+			namespace:      namespace,
+			name:           string(backendRef.Name),
+		}
+	}
+	return namespace, nil
+}
+
+// referenceGrantAllows returns true if a ReferenceGrant in the store allows routes of the kind in the route's
+// namespace to reference the service
+func (d *Driver) referenceGrantAllows(routeKind, routeNamespace, serviceNamespace, serviceName string) bool {
+	return ReferenceGrantAllows(d.store.ListReferenceGrants(), routeKind, routeNamespace, serviceNamespace, serviceName)
+}
+
+// ReferenceGrantAllows returns true if one of the grants in the service's namespace allows routes of the kind in
+// the route's namespace to reference the service. Grants without a name in their "to" allow every service.
+func ReferenceGrantAllows(grants []*gatewayv1beta1.ReferenceGrant, routeKind, routeNamespace, serviceNamespace, serviceName string) bool {
+	for _, grant := range grants {
+		if grant.Namespace != serviceNamespace {
+			continue
+		}
+
+		fromRoute := slices.ContainsFunc(grant.Spec.From, func(from gatewayv1beta1.ReferenceGrantFrom) bool {
+			return from.Group == gatewayv1.GroupName && string(from.Kind) == routeKind && string(from.Namespace) == routeNamespace
+		})
+		toService := slices.ContainsFunc(grant.Spec.To, func(to gatewayv1beta1.ReferenceGrantTo) bool {
+			return to.Group == "" && to.Kind == "Service" && (to.Name == nil || string(*to.Name) == serviceName)
+		})
+		if fromRoute && toService {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"fmt"
+
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
+)
+
+// IngressResolutionError is returned for an ngrok ingress with service backends that can't be resolved to a
+// port of an existing service
+type IngressResolutionError struct {
+	Ingress *netv1.Ingress
+	// Errors has an error for each unresolvable backend, with the path of the backend's field
+	Errors field.ErrorList
+}
+
+// Error: Stringer: returns the error message
+func (e IngressResolutionError) Error() string {
+	return fmt.Sprintf("ingress %s/%s has unresolvable backends: %v", e.Ingress.Namespace, e.Ingress.Name, e.Errors.ToAggregate())
+}
+
+// ListResolvableNgrokIngressesV1 returns the ingresses ListNgrokIngressesV1 returns whose service backends all
+// resolve to a port of an existing service, so edges aren't made for ingresses whose backends don't exist yet.
+// The other ingresses are left out, with an IngressResolutionError for each of them. Resource backends aren't
+// supported, so they're not resolved.
+func (s Store) ListResolvableNgrokIngressesV1() ([]*netv1.Ingress, []IngressResolutionError) {
+	var resolvable []*netv1.Ingress
+	var unresolvable []IngressResolutionError
+	for _, ing := range s.ListNgrokIngressesV1() {
+		if errs := s.resolveIngressBackends(ing); len(errs) > 0 {
+			unresolvable = append(unresolvable, IngressResolutionError{Ingress: ing, Errors: errs})
+			continue
+		}
+		resolvable = append(resolvable, ing)
+	}
+	return resolvable, unresolvable
+}
+
+// resolveIngressBackends returns an error for each of the ingress's service backends whose service doesn't exist
+// or doesn't have the backend's port
+func (s Store) resolveIngressBackends(ing *netv1.Ingress) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if ing.Spec.DefaultBackend != nil {
+		errs = append(errs, s.resolveIngressBackend(ing.Spec.DefaultBackend, ing.Namespace, specPath.Child("defaultBackend"))...)
+	}
+	for i, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for j := range rule.HTTP.Paths {
+			backendPath := specPath.Child("rules").Index(i).Child("http", "paths").Index(j).Child("backend")
+			errs = append(errs, s.resolveIngressBackend(&rule.HTTP.Paths[j].Backend, ing.Namespace, backendPath)...)
+		}
+	}
+	return errs
+}
+
+// resolveIngressBackend returns an error if the backend is a service backend that doesn't resolve to a port of
+// an existing service. Ports are matched by number or name, the same way ResolveBackend matches them.
+func (s Store) resolveIngressBackend(backend *netv1.IngressBackend, namespace string, path *field.Path) field.ErrorList {
+	if backend.Service == nil {
+		return nil
+	}
+
+	service, err := s.GetServiceV1(backend.Service.Name, namespace)
+	if err != nil {
+		if errors.IsErrorNotFound(err) {
+			return field.ErrorList{field.NotFound(path.Child("service", "name"), backend.Service.Name)}
+		}
+		return field.ErrorList{field.InternalError(path.Child("service", "name"), err)}
+	}
+
+	port := backend.Service.Port
+	if _, ok := findServicePort(service, port); ok {
+		return nil
+	}
+	if port.Name != "" {
+		return field.ErrorList{field.NotFound(path.Child("service", "port", "name"), port.Name)}
+	}
+	return field.ErrorList{field.NotFound(path.Child("service", "port", "number"), port.Number)}
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconciledHashes remembers a hash of each ingress as of its last successful reconcile. Periodic resyncs
+// reconcile every ingress, and most of them haven't changed, so an ingress whose hash is unchanged can be
+// skipped. The ingress's translation also depends on the other resources the driver watches, so all the
+// hashes are forgotten whenever one of those changes.
+type reconciledHashes struct {
+	mu     sync.Mutex
+	hashes map[types.NamespacedName]string
+}
+
+func newReconciledHashes() *reconciledHashes {
+	return &reconciledHashes{hashes: make(map[types.NamespacedName]string)}
+}
+
+func (r *reconciledHashes) unchanged(key types.NamespacedName, hash string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.hashes[key]
+	return ok && h == hash
+}
+
+func (r *reconciledHashes) set(key types.NamespacedName, hash string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hashes[key] = hash
+}
+
+func (r *reconciledHashes) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.hashes, key)
+}
+
+func (r *reconciledHashes) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hashes = make(map[types.NamespacedName]string)
+}
+
+// hashIngress hashes the parts of the ingress that affect how it's reconciled
+func hashIngress(ing *netv1.Ingress) (string, error) {
+	b, err := json.Marshal(struct {
+		Spec              netv1.IngressSpec
+		Annotations       map[string]string
+		Labels            map[string]string
+		Finalizers        []string
+		DeletionTimestamp *metav1.Time
+	}{
+		Spec:              ing.Spec,
+		Annotations:       ing.Annotations,
+		Labels:            ing.Labels,
+		Finalizers:        ing.Finalizers,
+		DeletionTimestamp: ing.DeletionTimestamp,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// IngressUnchanged returns true if neither the ingress nor any of the resources the driver watches have
+// changed since the ingress was last marked reconciled, in which case reconciling it again would do nothing
+func (d *Driver) IngressUnchanged(ing *netv1.Ingress) bool {
+	hash, err := hashIngress(ing)
+	if err != nil {
+		d.log.Error(err, "error hashing ingress", "ingress", ing.Name, "namespace", ing.Namespace)
+		return false
+	}
+	return d.reconciled.unchanged(types.NamespacedName{Name: ing.Name, Namespace: ing.Namespace}, hash)
+}
+
+// MarkIngressReconciled remembers the ingress as successfully reconciled, so that it can be skipped until it
+// or one of the resources the driver watches changes
+func (d *Driver) MarkIngressReconciled(ing *netv1.Ingress) {
+	key := types.NamespacedName{Name: ing.Name, Namespace: ing.Namespace}
+	hash, err := hashIngress(ing)
+	if err != nil {
+		d.log.Error(err, "error hashing ingress", "ingress", ing.Name, "namespace", ing.Namespace)
+		d.reconciled.forget(key)
+		return
+	}
+	d.reconciled.set(key, hash)
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Resync", func() {
+	var driver *Driver
+
+	BeforeEach(func() {
+		driver = NewDriver(logr.Discard(), runtime.NewScheme(), defaultControllerName, types.NamespacedName{Name: defaultManagerName}, false)
+	})
+
+	It("Should not consider an ingress unchanged before it's reconciled", func() {
+		ing := NewTestIngressV1("test-ingress", "test-namespace")
+		Expect(driver.IngressUnchanged(&ing)).To(BeFalse())
+	})
+
+	It("Should consider an ingress unchanged after it's reconciled", func() {
+		ing := NewTestIngressV1("test-ingress", "test-namespace")
+		driver.MarkIngressReconciled(&ing)
+
+		// status and resource version changes don't affect the reconcile
+		ing.ResourceVersion = "2"
+		ing.Status.LoadBalancer.Ingress = nil
+		Expect(driver.IngressUnchanged(&ing)).To(BeTrue())
+	})
+
+	It("Should consider an ingress changed when its spec or annotations change", func() {
+		ing := NewTestIngressV1("test-ingress", "test-namespace")
+		driver.MarkIngressReconciled(&ing)
+
+		changedSpec := ing.DeepCopy()
+		changedSpec.Spec.Rules[0].Host = "changed.example.com"
+		Expect(driver.IngressUnchanged(changedSpec)).To(BeFalse())
+
+		changedAnnotations := ing.DeepCopy()
+		changedAnnotations.Annotations = map[string]string{"k8s.ngrok.com/modules": "changed"}
+		Expect(driver.IngressUnchanged(changedAnnotations)).To(BeFalse())
+	})
+
+	It("Should forget reconciled ingresses when they're invalidated or deleted", func() {
+		ing := NewTestIngressV1("test-ingress", "test-namespace")
+		driver.MarkIngressReconciled(&ing)
+		driver.reconciled.invalidate()
+		Expect(driver.IngressUnchanged(&ing)).To(BeFalse())
+
+		driver.MarkIngressReconciled(&ing)
+		Expect(driver.DeleteNamedIngress(types.NamespacedName{Name: ing.Name, Namespace: ing.Namespace})).To(Succeed())
+		Expect(driver.IngressUnchanged(&ing)).To(BeFalse())
+	})
+})
@@ -14,19 +14,32 @@ limitations under the License.
 package store
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 
+	"github.com/ngrok/kubernetes-ingress-controller/internal/annotations"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/go-logr/logr"
 )
@@ -37,31 +50,64 @@ import (
 type Storer interface {
 	Get(obj runtime.Object) (item interface{}, exists bool, err error)
 	Add(runtime.Object) error
-	Update(runtime.Object) error
+	AddAll(objs ...runtime.Object) error
+	Update(runtime.Object) (changed bool, err error)
 	Delete(runtime.Object) error
 
+	HasSynced() bool
+	WaitForCacheSync(ctx context.Context) bool
+
 	GetIngressClassV1(name string) (*netv1.IngressClass, error)
+	GetIngressClassParametersV1(ic *netv1.IngressClass) (*ingressv1alpha1.NgrokIngressClassParams, error)
 	GetIngressV1(name, namespace string) (*netv1.Ingress, error)
 	GetServiceV1(name, namespace string) (*corev1.Service, error)
+	GetSecretV1(name, namespace string) (*corev1.Secret, error)
+	GetEndpointSlicesForService(serviceName, namespace string) ([]*discoveryv1.EndpointSlice, error)
+	ResolveBackend(ing *netv1.Ingress, path netv1.HTTPIngressPath) (*corev1.Service, int32, error)
 	GetNgrokIngressV1(name, namespace string) (*netv1.Ingress, error)
 	GetNgrokModuleSetV1(name, namespace string) (*ingressv1alpha1.NgrokModuleSet, error)
+	GetDefaultNgrokModuleSet() (*ingressv1alpha1.NgrokModuleSet, error)
+	GetMutualTLSCACertificates(ms *ingressv1alpha1.NgrokModuleSet) ([]string, error)
+	GetOIDCClientSecret(ms *ingressv1alpha1.NgrokModuleSet) (string, error)
 	GetNgrokTrafficPolicyV1(name, namespace string) (*ngrokv1alpha1.NgrokTrafficPolicy, error)
 	GetGateway(name string, namespace string) (*gatewayv1.Gateway, error)
-	GetHTTPRoute(name string, namespace string) (*gatewayv1.HTTPRoute, error)
+	GetHTTPRouteV1(name string, namespace string) (*gatewayv1.HTTPRoute, error)
+	GetGRPCRouteV1Alpha2(name string, namespace string) (*gatewayv1alpha2.GRPCRoute, error)
+	GetTCPEdgeV1(name, namespace string) (*ingressv1alpha1.TCPEdge, error)
+	GetDomainV1(name, namespace string) (*ingressv1alpha1.Domain, error)
+	GetIPPolicyV1(name, namespace string) (*ingressv1alpha1.IPPolicy, error)
 
 	ListIngressClassesV1() []*netv1.IngressClass
 	ListNgrokIngressClassesV1() []*netv1.IngressClass
 
 	ListIngressesV1() []*netv1.Ingress
 	ListNgrokIngressesV1() []*netv1.Ingress
+	ListNgrokIngressesV1Paged(continueToken string, limit int) ([]*netv1.Ingress, string, error)
+	ListResolvableNgrokIngressesV1() ([]*netv1.Ingress, []IngressResolutionError)
+	ListNgrokIngressesForService(serviceName, namespace string) []*netv1.Ingress
+	GetIngressesForDomain(domain string) []*netv1.Ingress
+	ListNgrokIngressesByLabels(selector labels.Selector) []*netv1.Ingress
+	NormalizePaths(ing *netv1.Ingress) ([]NormalizedRoute, error)
+	GetIngressMetadata(ing *netv1.Ingress) (map[string]string, error)
+
+	ListServicesV1() []*corev1.Service
 
 	ListGateways() []*gatewayv1.Gateway
+	ListNgrokGateways() []*gatewayv1.Gateway
+	IsNgrokGateway(gtw *gatewayv1.Gateway) bool
+	GatewayControllerName() gatewayv1.GatewayController
 	ListHTTPRoutes() []*gatewayv1.HTTPRoute
+	ListHTTPRoutesForGatewayClass(className string) []*gatewayv1.HTTPRoute
+	ListGRPCRoutes() []*gatewayv1alpha2.GRPCRoute
+	ListReferenceGrants() []*gatewayv1beta1.ReferenceGrant
 
 	ListDomainsV1() []*ingressv1alpha1.Domain
 	ListTunnelsV1() []*ingressv1alpha1.Tunnel
 	ListHTTPSEdgesV1() []*ingressv1alpha1.HTTPSEdge
+	ListTCPEdgesV1() []*ingressv1alpha1.TCPEdge
+	ListIPPoliciesV1() []*ingressv1alpha1.IPPolicy
 	ListNgrokModuleSetsV1() []*ingressv1alpha1.NgrokModuleSet
+	ListSecretsV1(namespace string) []*corev1.Secret
 }
 
 // Store implements Storer and can be used to list Ingress, Services
@@ -69,20 +115,107 @@ type Storer interface {
 // be synced and updated by the caller.
 // It is ingressClass filter aware.
 type Store struct {
-	stores         CacheStores
-	controllerName string
-	log            logr.Logger
+	stores          CacheStores
+	controllerNames []string
+	log             logr.Logger
+	namespaces      map[string]bool
+	synced          []cache.InformerSynced
+
+	gatewayControllerName gatewayv1.GatewayController
+}
+
+// AnnotationDefaultModuleSet marks a module set as the cluster wide default when set to "true", like
+// ingressclass.kubernetes.io/is-default-class does for ingress classes
+const AnnotationDefaultModuleSet = "k8s.ngrok.com/is-default-module-set"
+
+// AnnotationIgnore makes the store skip an ingress when set to "true", even if it uses one of the controller's
+// ingress classes or has no class while the controller's class is the default
+const AnnotationIgnore = "k8s.ngrok.com/ignore"
+
+// DefaultGatewayControllerName is the controller name of the gateway classes the store handles by default. It
+// matches the controller name of the gateway controller.
+const DefaultGatewayControllerName gatewayv1.GatewayController = "ngrok.com/gateway-controller"
+
+// Option configures optional behavior of a Store
+type Option func(*Store)
+
+// WithAdditionalControllerNames makes the store also handle ingresses whose class belongs to one of the
+// controller names, e.g. a staging controller running alongside the main one
+func WithAdditionalControllerNames(names ...string) Option {
+	return func(s *Store) {
+		s.controllerNames = append(s.controllerNames, names...)
+	}
+}
+
+// WithWatchNamespaces limits the store to objects in the namespaces. Adding an object from any other namespace
+// fails, and lookups skip them. Cluster scoped objects are always allowed. Without any namespaces, the store
+// handles objects in all namespaces.
+func WithWatchNamespaces(namespaces ...string) Option {
+	return func(s *Store) {
+		if len(namespaces) == 0 {
+			return
+		}
+		s.namespaces = make(map[string]bool, len(namespaces))
+		for _, ns := range namespaces {
+			s.namespaces[ns] = true
+		}
+	}
+}
+
+// WithGatewayControllerName makes the store handle the gateway classes of the controller name instead of
+// those of DefaultGatewayControllerName
+func WithGatewayControllerName(name gatewayv1.GatewayController) Option {
+	return func(s *Store) {
+		s.gatewayControllerName = name
+	}
+}
+
+// WithCacheSynced makes the store report the sync status of the informers feeding its cache stores from
+// HasSynced, so callers can tell an object that's genuinely absent from one that hasn't been synced yet
+func WithCacheSynced(synced ...cache.InformerSynced) Option {
+	return func(s *Store) {
+		s.synced = append(s.synced, synced...)
+	}
 }
 
 var _ Storer = Store{}
 
-// New creates a new object store to be used in the ingress controller.
-func New(cs CacheStores, controllerName string, logger logr.Logger) Storer {
-	return Store{
-		stores:         cs,
-		controllerName: controllerName,
-		log:            logger,
+// New creates a new object store to be used in the ingress controller. The store handles the ingress classes
+// of the controller name along with those of any additional controller names, so that one controller can serve
+// several ingress classes.
+func New(cs CacheStores, controllerName string, logger logr.Logger, opts ...Option) Storer {
+	s := Store{
+		stores:          cs,
+		controllerNames: []string{controllerName},
+		log:             logger,
+
+		gatewayControllerName: DefaultGatewayControllerName,
 	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// HasSynced returns true once every informer given with WithCacheSynced has synced. Until then, getters may
+// return a not found error for an object that exists but isn't in the cache stores yet. A store without any
+// informers is fed directly by the caller and is always synced.
+func (s Store) HasSynced() bool {
+	for _, synced := range s.synced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForCacheSync blocks until the store has synced, returning true, or the context is done, returning false.
+// Reconcilers can call it before reading from the store so they don't act on a cache that isn't warm yet.
+func (s Store) WaitForCacheSync(ctx context.Context) bool {
+	if s.HasSynced() {
+		return true
+	}
+	return cache.WaitForCacheSync(ctx.Done(), s.HasSynced)
 }
 
 // Get proxies the call to the underlying store.
@@ -90,15 +223,82 @@ func (s Store) Get(obj runtime.Object) (interface{}, bool, error) {
 	return s.stores.Get(obj)
 }
 
-// Add proxies the call to the underlying store.
+// Add proxies the call to the underlying store. Objects from namespaces the store doesn't watch are rejected.
 func (s Store) Add(obj runtime.Object) error {
+	if ns, watched := s.watches(obj); !watched {
+		return errors.NewErrNamespaceNotWatched(ns)
+	}
 	return s.stores.Add(obj.DeepCopyObject())
 }
 
-// Update proxies the call to the underlying store.
-// An add for an object with the same key thats already present is just an update
-func (s Store) Update(obj runtime.Object) error {
-	return s.stores.Add(obj.DeepCopyObject())
+// AddAll adds the objects to the underlying store at once, which is faster than adding them one at a time when
+// warming up the store with many objects. Objects from namespaces the store doesn't watch, or of unsupported
+// types, are skipped, and the errors for them are returned together as an aggregate once the rest are added.
+func (s Store) AddAll(objs ...runtime.Object) error {
+	var errs []error
+	copies := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		if ns, watched := s.watches(obj); !watched {
+			errs = append(errs, errors.NewErrNamespaceNotWatched(ns))
+			continue
+		}
+		copies = append(copies, obj.DeepCopyObject())
+	}
+	if err := s.stores.AddAll(copies...); err != nil {
+		errs = append(errs, err.(utilerrors.Aggregate).Errors()...)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Update proxies the call to the underlying store, adding the object if it isn't present yet. It returns
+// whether the object changed, so callers can skip work like ngrok API calls for objects that are semantically
+// the same as the stored version. Only the resourceVersion, managed fields, or status differing isn't a change.
+func (s Store) Update(obj runtime.Object) (bool, error) {
+	existing, exists, err := s.Get(obj)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Add(obj); err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+	existingObj, ok := existing.(runtime.Object)
+	if !ok {
+		return true, nil
+	}
+	same, err := semanticallyEqual(existingObj, obj)
+	if err != nil {
+		return false, err
+	}
+	return !same, nil
+}
+
+// semanticallyEqual compares two versions of an object, ignoring the fields that change without the object
+// itself changing: the resourceVersion, the managed fields, and the status
+func semanticallyEqual(a, b runtime.Object) (bool, error) {
+	contentA, err := comparableContent(a)
+	if err != nil {
+		return false, err
+	}
+	contentB, err := comparableContent(b)
+	if err != nil {
+		return false, err
+	}
+	return equality.Semantic.DeepEqual(contentA, contentB), nil
+}
+
+// comparableContent returns the object as unstructured content without the fields semanticallyEqual ignores
+func comparableContent(obj runtime.Object) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	unstructured.RemoveNestedField(content, "status")
+	return content, nil
 }
 
 // Delete proxies the call to the underlying store.
@@ -106,41 +306,170 @@ func (s Store) Delete(obj runtime.Object) error {
 	return s.stores.Delete(obj)
 }
 
+// watches returns the namespace of the object and whether the store handles objects in it
+func (s Store) watches(obj interface{}) (string, bool) {
+	if len(s.namespaces) == 0 {
+		return "", true
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return "", true
+	}
+	ns := o.GetNamespace()
+	return ns, ns == "" || s.namespaces[ns]
+}
+
+// filterWatched returns the items that are in watched namespaces
+func (s Store) filterWatched(items []interface{}) []interface{} {
+	if len(s.namespaces) == 0 {
+		return items
+	}
+	watched := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if _, ok := s.watches(item); ok {
+			watched = append(watched, item)
+		}
+	}
+	return watched
+}
+
+// list returns the items in one of the stores that are in watched namespaces
+func (s Store) list(store cache.Store) []interface{} {
+	return s.filterWatched(s.stores.list(store))
+}
+
+// getByKey looks up an item by key in one of the stores. Items in namespaces that aren't watched don't exist.
+func (s Store) getByKey(store cache.Store, key string) (interface{}, bool, error) {
+	item, exists, err := s.stores.getByKey(store, key)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	if _, ok := s.watches(item); !ok {
+		return nil, false, nil
+	}
+	return item, true, nil
+}
+
+// byIndex returns the items in watched namespaces matching an indexed value in one of the indexers
+func (s Store) byIndex(indexer cache.Indexer, indexName, indexedValue string) ([]interface{}, error) {
+	items, err := s.stores.byIndex(indexer, indexName, indexedValue)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterWatched(items), nil
+}
+
 // GetIngressClassV1 returns the 'name' IngressClass resource.
 func (s Store) GetIngressClassV1(name string) (*netv1.IngressClass, error) {
-	p, exists, err := s.stores.IngressClassV1.GetByKey(name)
+	p, exists, err := s.getByKey(s.stores.IngressClassV1, name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("IngressClass %v not found", name))
+		return nil, errors.NewNotFoundError(netv1.SchemeGroupVersion.WithKind("IngressClass"), name, "")
 	}
 	return p.(*netv1.IngressClass), nil
 }
 
 // GetIngressV1 returns the 'name' Ingress resource.
-func (s Store) GetIngressV1(name, namespcae string) (*netv1.Ingress, error) {
-	p, exists, err := s.stores.IngressV1.GetByKey(getKey(name, namespcae))
+func (s Store) GetIngressV1(name, namespace string) (*netv1.Ingress, error) {
+	p, exists, err := s.getByKey(s.stores.IngressV1, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("Ingress %v not found", name))
+		return nil, errors.NewNotFoundError(netv1.SchemeGroupVersion.WithKind("Ingress"), name, namespace)
 	}
 	return p.(*netv1.Ingress), nil
 }
 
 func (s Store) GetServiceV1(name, namespace string) (*corev1.Service, error) {
-	p, exists, err := s.stores.ServiceV1.GetByKey(getKey(name, namespace))
+	p, exists, err := s.getByKey(s.stores.ServiceV1, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("Service %v not found", name))
+		return nil, errors.NewNotFoundError(corev1.SchemeGroupVersion.WithKind("Service"), name, namespace)
 	}
 	return p.(*corev1.Service), nil
 }
 
+// ResolveBackend returns the service of an ingress path's backend, and the number of the backend's port on the
+// service. Named ports are resolved to the number of the service's port with that name. It returns a not found
+// error if the service doesn't exist or doesn't have the port.
+func (s Store) ResolveBackend(ing *netv1.Ingress, path netv1.HTTPIngressPath) (*corev1.Service, int32, error) {
+	backend := path.Backend.Service
+	if backend == nil {
+		return nil, 0, fmt.Errorf("path %q of ingress %s/%s doesn't have a service backend", path.Path, ing.Namespace, ing.Name)
+	}
+
+	service, err := s.GetServiceV1(backend.Name, ing.Namespace)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	servicePort, ok := findServicePort(service, backend.Port)
+	if !ok {
+		if backend.Port.Name != "" {
+			return nil, 0, errors.NewErrorNotFound(fmt.Sprintf("service %s/%s doesn't have a port named %s", service.Namespace, service.Name, backend.Port.Name))
+		}
+		return nil, 0, errors.NewErrorNotFound(fmt.Sprintf("service %s/%s doesn't have port %d", service.Namespace, service.Name, backend.Port.Number))
+	}
+	return service, servicePort.Port, nil
+}
+
+// findServicePort returns the port of the service that a backend's port refers to by number or name
+func findServicePort(service *corev1.Service, port netv1.ServiceBackendPort) (*corev1.ServicePort, bool) {
+	for i, servicePort := range service.Spec.Ports {
+		if (port.Number > 0 && servicePort.Port == port.Number) || (port.Name != "" && servicePort.Name == port.Name) {
+			return &service.Spec.Ports[i], true
+		}
+	}
+	return externalNameServicePort(service, port.Number)
+}
+
+// GetSecretV1 returns the 'name' Secret resource.
+func (s Store) GetSecretV1(name, namespace string) (*corev1.Secret, error) {
+	p, exists, err := s.getByKey(s.stores.SecretV1, getKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(corev1.SchemeGroupVersion.WithKind("Secret"), name, namespace)
+	}
+	return p.(*corev1.Secret), nil
+}
+
+// GetEndpointSlicesForService returns the EndpointSlices belonging to the service, found by their
+// kubernetes.io/service-name label. A service without any slices yet returns an empty list, and it's only
+// an error when the service itself doesn't exist.
+func (s Store) GetEndpointSlicesForService(serviceName, namespace string) ([]*discoveryv1.EndpointSlice, error) {
+	if _, err := s.GetServiceV1(serviceName, namespace); err != nil {
+		return nil, err
+	}
+
+	items, err := s.byIndex(s.stores.EndpointSliceV1, endpointSliceServiceIndex, getKey(serviceName, namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	endpointSlices := []*discoveryv1.EndpointSlice{}
+	for _, item := range items {
+		slice, ok := item.(*discoveryv1.EndpointSlice)
+		if !ok {
+			s.log.Info("getEndpointSlicesForService: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		endpointSlices = append(endpointSlices, slice)
+	}
+
+	sort.SliceStable(endpointSlices, func(i, j int) bool {
+		return endpointSlices[i].Name < endpointSlices[j].Name
+	})
+
+	return endpointSlices, nil
+}
+
 // GetNgrokIngressV1 looks up the Ingress resource by name and namespace and returns it if it's found
 func (s Store) GetNgrokIngressV1(name, namespace string) (*netv1.Ingress, error) {
 	ing, err := s.GetIngressV1(name, namespace)
@@ -155,55 +484,242 @@ func (s Store) GetNgrokIngressV1(name, namespace string) (*netv1.Ingress, error)
 	return ing, nil
 }
 
+// GetIngressMetadata returns the metadata for the ngrok edges of the ingress from its k8s.ngrok.com/metadata
+// annotation, a JSON object of string values. It returns an empty map without the annotation, and an invalid
+// annotation content error if the annotation isn't such a JSON object.
+func (s Store) GetIngressMetadata(ing *netv1.Ingress) (map[string]string, error) {
+	metadata, err := annotations.ExtractMetadataFromAnnotations(ing)
+	if errors.IsMissingAnnotations(err) {
+		return map[string]string{}, nil
+	}
+	return metadata, err
+}
+
+// GetNgrokModuleSetV1 returns the named module set, or an invalid configuration error if its modules can't be used
 func (s Store) GetNgrokModuleSetV1(name, namespace string) (*ingressv1alpha1.NgrokModuleSet, error) {
-	p, exists, err := s.stores.NgrokModuleV1.GetByKey(getKey(name, namespace))
+	p, exists, err := s.getByKey(s.stores.NgrokModuleV1, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("NgrokModuleSet %v not found", name))
+		return nil, errors.NewNotFoundError(ingressv1alpha1.GroupVersion.WithKind("NgrokModuleSet"), name, namespace)
+	}
+
+	ms := p.(*ingressv1alpha1.NgrokModuleSet)
+	if err := ms.Validate(); err != nil {
+		return nil, errors.NewErrInvalidConfiguration(err)
+	}
+	if _, err := s.GetMutualTLSCACertificates(ms); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetOIDCClientSecret(ms); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// GetMutualTLSCACertificates returns the PEM encoded CA certificates of the Secrets referenced by the module
+// set's mutual TLS module, in the order they're referenced. It returns a not found error for a Secret that
+// isn't in the store, and an invalid configuration error for one without a CA certificate.
+func (s Store) GetMutualTLSCACertificates(ms *ingressv1alpha1.NgrokModuleSet) ([]string, error) {
+	mtls := ms.Modules.MutualTLS
+	if mtls == nil {
+		return nil, nil
+	}
+
+	var certs []string
+	for _, secretName := range mtls.CertificateAuthoritySecrets {
+		secret, err := s.GetSecretV1(secretName, ms.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		cert := secret.Data[ingressv1alpha1.MutualTLSCACertKey]
+		if len(cert) == 0 {
+			return nil, errors.NewErrInvalidConfiguration(fmt.Errorf("NgrokModuleSet %v: %w: Secret %v has no %s key", ms.Name, ingressv1alpha1.ErrInvalidMutualTLS, secretName, ingressv1alpha1.MutualTLSCACertKey))
+		}
+		certs = append(certs, string(cert))
+	}
+	return certs, nil
+}
+
+// GetOIDCClientSecret returns the client secret of the module set's OIDC module from the Secret it references,
+// or an empty string without an OIDC module. It returns a not found error for a Secret that isn't in the store,
+// and an invalid configuration error for one without the referenced key.
+func (s Store) GetOIDCClientSecret(ms *ingressv1alpha1.NgrokModuleSet) (string, error) {
+	oidc := ms.Modules.OIDC
+	if oidc == nil {
+		return "", nil
+	}
+
+	ref := oidc.ClientSecret
+	secret, err := s.GetSecretV1(ref.Name, ms.Namespace)
+	if err != nil {
+		return "", err
+	}
+	clientSecret := secret.Data[ref.Key]
+	if len(clientSecret) == 0 {
+		return "", errors.NewErrInvalidConfiguration(fmt.Errorf("NgrokModuleSet %v: %w: Secret %v has no %s key", ms.Name, ingressv1alpha1.ErrInvalidOIDC, ref.Name, ref.Key))
+	}
+	return string(clientSecret), nil
+}
+
+// moduleSetSecretNames returns the names of the Secrets, in the module set's namespace, that the store reads for
+// its mutual TLS and OIDC modules
+func moduleSetSecretNames(ms *ingressv1alpha1.NgrokModuleSet) []string {
+	var names []string
+	if mtls := ms.Modules.MutualTLS; mtls != nil {
+		names = append(names, mtls.CertificateAuthoritySecrets...)
+	}
+	if oidc := ms.Modules.OIDC; oidc != nil && oidc.ClientSecret.Name != "" {
+		names = append(names, oidc.ClientSecret.Name)
+	}
+	return names
+}
+
+// GetDefaultNgrokModuleSet returns the module set marked with AnnotationDefaultModuleSet, whose modules apply to
+// every ngrok ingress that doesn't configure them itself. It returns a not found error if no module set is the
+// default, and an invalid configuration error if more than one is, or if the default's modules can't be used.
+func (s Store) GetDefaultNgrokModuleSet() (*ingressv1alpha1.NgrokModuleSet, error) {
+	var defaults []*ingressv1alpha1.NgrokModuleSet
+	for _, ms := range s.ListNgrokModuleSetsV1() {
+		if ms.Annotations[AnnotationDefaultModuleSet] == "true" {
+			defaults = append(defaults, ms)
+		}
+	}
+
+	switch len(defaults) {
+	case 0:
+		return nil, errors.NewErrorNotFound("no NgrokModuleSet is marked as the default")
+	case 1:
+		return s.GetNgrokModuleSetV1(defaults[0].Name, defaults[0].Namespace)
+	default:
+		keys := make([]string, 0, len(defaults))
+		for _, ms := range defaults {
+			keys = append(keys, getKey(ms.Name, ms.Namespace))
+		}
+		return nil, errors.NewErrInvalidConfiguration(fmt.Errorf("only one NgrokModuleSet can be marked as the default, found %s", strings.Join(keys, ", ")))
 	}
-	return p.(*ingressv1alpha1.NgrokModuleSet), nil
 }
 
 func (s Store) GetNgrokTrafficPolicyV1(name, namespace string) (*ngrokv1alpha1.NgrokTrafficPolicy, error) {
-	p, exists, err := s.stores.NgrokTrafficPolicyV1.GetByKey(getKey(name, namespace))
+	p, exists, err := s.getByKey(s.stores.NgrokTrafficPolicyV1, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("NgrokTrafficPolicy %v not found", name))
+		return nil, errors.NewNotFoundError(ngrokv1alpha1.GroupVersion.WithKind("NgrokTrafficPolicy"), name, namespace)
 	}
 	return p.(*ngrokv1alpha1.NgrokTrafficPolicy), nil
 }
 
+// GetIngressClassParametersV1 returns the NgrokIngressClassParams referenced by the IngressClass's spec.parameters,
+// or nil if it doesn't have any. Namespace scoped references are looked up in their namespace, and cluster scoped
+// references, the default, without one.
+func (s Store) GetIngressClassParametersV1(ic *netv1.IngressClass) (*ingressv1alpha1.NgrokIngressClassParams, error) {
+	ref := ic.Spec.Parameters
+	if ref == nil {
+		return nil, nil
+	}
+
+	apiGroup := ""
+	if ref.APIGroup != nil {
+		apiGroup = *ref.APIGroup
+	}
+	if apiGroup != ingressv1alpha1.GroupVersion.Group || ref.Kind != "NgrokIngressClassParams" {
+		return nil, errors.NewErrInvalidConfiguration(fmt.Errorf("IngressClass %s parameters reference unsupported kind %s.%s", ic.Name, ref.Kind, apiGroup))
+	}
+
+	// NgrokIngressClassParams are cluster scoped, so a namespace scoped reference can't point to any
+	if ref.Scope != nil && *ref.Scope == netv1.IngressClassParametersReferenceScopeNamespace {
+		return nil, errors.NewErrInvalidConfiguration(fmt.Errorf("IngressClass %s parameters reference is namespace scoped, but NgrokIngressClassParams are cluster scoped", ic.Name))
+	}
+
+	p, exists, err := s.getByKey(s.stores.NgrokIngressClassParamsV1, getKey(ref.Name, ""))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(ingressv1alpha1.GroupVersion.WithKind("NgrokIngressClassParams"), ref.Name, "")
+	}
+	return p.(*ingressv1alpha1.NgrokIngressClassParams), nil
+}
+
 func (s Store) GetGateway(name string, namespace string) (*gatewayv1.Gateway, error) {
-	gtw, exists, err := s.stores.Gateway.GetByKey(getKey(name, namespace))
+	gtw, exists, err := s.getByKey(s.stores.Gateway, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("Gateway %v not found", name))
+		return nil, errors.NewNotFoundError(gatewayv1.SchemeGroupVersion.WithKind("Gateway"), name, namespace)
 	}
 	return gtw.(*gatewayv1.Gateway), nil
 }
 
-func (s Store) GetHTTPRoute(name string, namespace string) (*gatewayv1.HTTPRoute, error) {
-	obj, exists, err := s.stores.HTTPRoute.GetByKey(getKey(name, namespace))
+// GetHTTPRouteV1 returns the named gateway.networking.k8s.io/v1 HTTPRoute
+func (s Store) GetHTTPRouteV1(name string, namespace string) (*gatewayv1.HTTPRoute, error) {
+	obj, exists, err := s.getByKey(s.stores.HTTPRoute, getKey(name, namespace))
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewErrorNotFound(fmt.Sprintf("HTTPRoute %v not found", name))
+		return nil, errors.NewNotFoundError(gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute"), name, namespace)
 	}
 	return obj.(*gatewayv1.HTTPRoute), nil
 }
 
+// GetGRPCRouteV1Alpha2 returns the named gateway.networking.k8s.io/v1alpha2 GRPCRoute
+func (s Store) GetGRPCRouteV1Alpha2(name string, namespace string) (*gatewayv1alpha2.GRPCRoute, error) {
+	obj, exists, err := s.getByKey(s.stores.GRPCRoute, getKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(gatewayv1alpha2.SchemeGroupVersion.WithKind("GRPCRoute"), name, namespace)
+	}
+	return obj.(*gatewayv1alpha2.GRPCRoute), nil
+}
+
+// GetTCPEdgeV1 returns the named TCPEdge
+func (s Store) GetTCPEdgeV1(name, namespace string) (*ingressv1alpha1.TCPEdge, error) {
+	edge, exists, err := s.getByKey(s.stores.TCPEdgeV1, getKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(ingressv1alpha1.GroupVersion.WithKind("TCPEdge"), name, namespace)
+	}
+	return edge.(*ingressv1alpha1.TCPEdge), nil
+}
+
+// GetDomainV1 returns the named Domain, which reserves a domain in ngrok
+func (s Store) GetDomainV1(name, namespace string) (*ingressv1alpha1.Domain, error) {
+	domain, exists, err := s.getByKey(s.stores.DomainV1, getKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(ingressv1alpha1.GroupVersion.WithKind("Domain"), name, namespace)
+	}
+	return domain.(*ingressv1alpha1.Domain), nil
+}
+
+// GetIPPolicyV1 returns the named IPPolicy, which manages a set of IP allow and deny rules in ngrok
+func (s Store) GetIPPolicyV1(name, namespace string) (*ingressv1alpha1.IPPolicy, error) {
+	policy, exists, err := s.getByKey(s.stores.IPPolicyV1, getKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(ingressv1alpha1.GroupVersion.WithKind("IPPolicy"), name, namespace)
+	}
+	return policy.(*ingressv1alpha1.IPPolicy), nil
+}
+
 // ListIngressClassesV1 returns the list of Ingresses in the Ingress v1 store.
 func (s Store) ListIngressClassesV1() []*netv1.IngressClass {
 	// filter ingress rules
 	var classes []*netv1.IngressClass
-	for _, item := range s.stores.IngressClassV1.List() {
+	for _, item := range s.list(s.stores.IngressClassV1) {
 		class, ok := item.(*netv1.IngressClass)
 		if !ok {
 			s.log.Info("listIngressClassesV1: dropping object of unexpected type: %#v", item)
@@ -220,12 +736,12 @@ func (s Store) ListIngressClassesV1() []*netv1.IngressClass {
 }
 
 // ListNgrokIngressClassesV1 returns the list of Ingresses in the Ingress v1 store filtered
-// by ones that match the controllerName
+// by ones that match any of the controller names
 func (s Store) ListNgrokIngressClassesV1() []*netv1.IngressClass {
 	filteredClasses := []*netv1.IngressClass{}
 	classes := s.ListIngressClassesV1()
 	for _, class := range classes {
-		if class.Spec.Controller == s.controllerName {
+		if slices.Contains(s.controllerNames, class.Spec.Controller) {
 			filteredClasses = append(filteredClasses, class)
 		}
 	}
@@ -238,7 +754,7 @@ func (s Store) ListIngressesV1() []*netv1.Ingress {
 	// filter ingress rules
 	var ingresses []*netv1.Ingress
 
-	for _, item := range s.stores.IngressV1.List() {
+	for _, item := range s.list(s.stores.IngressV1) {
 		ing, ok := item.(*netv1.Ingress)
 		if !ok {
 			e := fmt.Sprintf("listIngressesV1: dropping object of unexpected type: %#v", item)
@@ -256,10 +772,30 @@ func (s Store) ListIngressesV1() []*netv1.Ingress {
 	return ingresses
 }
 
+// ListServicesV1 returns the list of Services in the Service v1 store.
+func (s Store) ListServicesV1() []*corev1.Service {
+	var services []*corev1.Service
+	for _, item := range s.list(s.stores.ServiceV1) {
+		svc, ok := item.(*corev1.Service)
+		if !ok {
+			s.log.Info("listServicesV1: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		services = append(services, svc)
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", services[i].Namespace, services[i].Name),
+			fmt.Sprintf("%s/%s", services[j].Namespace, services[j].Name)) < 0
+	})
+
+	return services
+}
+
 func (s Store) ListGateways() []*gatewayv1.Gateway {
 	var gateways []*gatewayv1.Gateway
 
-	for _, item := range s.stores.Gateway.List() {
+	for _, item := range s.list(s.stores.Gateway) {
 		gtw, ok := item.(*gatewayv1.Gateway)
 		if !ok {
 			e := fmt.Sprintf("Gateway: dropping object of unexpected type: %#v", item)
@@ -277,10 +813,32 @@ func (s Store) ListGateways() []*gatewayv1.Gateway {
 	return gateways
 }
 
+// ListNgrokGateways returns the gateways handled by the controller, which are those of gateway classes that
+// belong to the store's gateway controller, sorted by namespace and name
+func (s Store) ListNgrokGateways() []*gatewayv1.Gateway {
+	var gateways []*gatewayv1.Gateway
+	for _, gtw := range s.ListGateways() {
+		if s.IsNgrokGateway(gtw) {
+			gateways = append(gateways, gtw)
+		}
+	}
+	return gateways
+}
+
+// IsNgrokGateway returns true if the gateway's class is in the store and belongs to the store's gateway controller
+func (s Store) IsNgrokGateway(gtw *gatewayv1.Gateway) bool {
+	return s.handlesGatewayClass(string(gtw.Spec.GatewayClassName))
+}
+
+// GatewayControllerName returns the controller name of the gateway classes the store handles
+func (s Store) GatewayControllerName() gatewayv1.GatewayController {
+	return s.gatewayControllerName
+}
+
 func (s Store) ListHTTPRoutes() []*gatewayv1.HTTPRoute {
 	var httproutes []*gatewayv1.HTTPRoute
 
-	for _, item := range s.stores.HTTPRoute.List() {
+	for _, item := range s.list(s.stores.HTTPRoute) {
 		httproute, ok := item.(*gatewayv1.HTTPRoute)
 		if !ok {
 			e := fmt.Sprintf("HTTPRoute: dropping object of unexpected type: %#v", item)
@@ -293,24 +851,235 @@ func (s Store) ListHTTPRoutes() []*gatewayv1.HTTPRoute {
 	return httproutes
 }
 
+// ListGRPCRoutes returns the GRPCRoutes in the store
+func (s Store) ListGRPCRoutes() []*gatewayv1alpha2.GRPCRoute {
+	var grpcroutes []*gatewayv1alpha2.GRPCRoute
+
+	for _, item := range s.list(s.stores.GRPCRoute) {
+		grpcroute, ok := item.(*gatewayv1alpha2.GRPCRoute)
+		if !ok {
+			e := fmt.Sprintf("GRPCRoute: dropping object of unexpected type: %#v", item)
+			s.log.Error(fmt.Errorf(e), e)
+			continue
+		}
+		grpcroutes = append(grpcroutes, grpcroute)
+	}
+
+	return grpcroutes
+}
+
+// ListReferenceGrants returns the ReferenceGrants, which allow routes to reference backends in other namespaces
+func (s Store) ListReferenceGrants() []*gatewayv1beta1.ReferenceGrant {
+	var grants []*gatewayv1beta1.ReferenceGrant
+	for _, item := range s.list(s.stores.ReferenceGrant) {
+		grant, ok := item.(*gatewayv1beta1.ReferenceGrant)
+		if !ok {
+			s.log.Info("listReferenceGrants: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		grants = append(grants, grant)
+	}
+
+	sort.SliceStable(grants, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", grants[i].Namespace, grants[i].Name),
+			fmt.Sprintf("%s/%s", grants[j].Namespace, grants[j].Name)) < 0
+	})
+
+	return grants
+}
+
+// ListHTTPRoutesForGatewayClass returns the HTTPRoutes attached to gateways of the gateway class, sorted by
+// namespace and name. Like ingresses with another controller's ingress class, there are none unless the
+// gateway class is in the store and belongs to the store's gateway controller.
+func (s Store) ListHTTPRoutesForGatewayClass(className string) []*gatewayv1.HTTPRoute {
+	if !s.handlesGatewayClass(className) {
+		return nil
+	}
+
+	gateways := map[string]bool{}
+	for _, gtw := range s.ListGateways() {
+		if string(gtw.Spec.GatewayClassName) == className {
+			gateways[getKey(gtw.Name, gtw.Namespace)] = true
+		}
+	}
+
+	var httproutes []*gatewayv1.HTTPRoute
+	for _, httproute := range s.ListHTTPRoutes() {
+		for _, ref := range httproute.Spec.ParentRefs {
+			if ref.Group != nil && *ref.Group != gatewayv1.GroupName {
+				continue
+			}
+			if ref.Kind != nil && *ref.Kind != "Gateway" {
+				continue
+			}
+			namespace := httproute.Namespace
+			if ref.Namespace != nil {
+				namespace = string(*ref.Namespace)
+			}
+			if gateways[getKey(string(ref.Name), namespace)] {
+				httproutes = append(httproutes, httproute)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(httproutes, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", httproutes[i].Namespace, httproutes[i].Name),
+			fmt.Sprintf("%s/%s", httproutes[j].Namespace, httproutes[j].Name)) < 0
+	})
+	return httproutes
+}
+
+// handlesGatewayClass returns true if the gateway class is in the store and belongs to the store's gateway
+// controller
+func (s Store) handlesGatewayClass(className string) bool {
+	item, exists, err := s.getByKey(s.stores.GatewayClass, getKey(className, ""))
+	if err != nil || !exists {
+		return false
+	}
+	gwClass, ok := item.(*gatewayv1.GatewayClass)
+	return ok && gwClass.Spec.ControllerName == s.gatewayControllerName
+}
+
+// ListNgrokIngressesV1 returns all the ingresses handled by the controller, sorted by namespace and name
 func (s Store) ListNgrokIngressesV1() []*netv1.Ingress {
-	ings := s.ListIngressesV1()
+	// the first page can't have an invalid token
+	ingresses, _, _ := s.ListNgrokIngressesV1Paged("", 0)
+	return ingresses
+}
+
+// ListNgrokIngressesV1Paged returns a page of at most limit ingresses handled by the controller, sorted by
+// namespace and name, along with a token to pass to get the next page. The token is empty for the last page.
+// Start with an empty token, and use a limit of 0 to get all the ingresses in one page. Ingresses added or
+// removed while paging only show up in pages that haven't been returned yet.
+func (s Store) ListNgrokIngressesV1Paged(continueToken string, limit int) ([]*netv1.Ingress, string, error) {
+	after, err := decodeContinueToken(continueToken)
+	if err != nil {
+		return nil, "", err
+	}
 
 	var ingresses []*netv1.Ingress
-	for _, ing := range ings {
+	for _, ing := range s.ListIngressesV1() {
+		key := getKey(ing.Name, ing.Namespace)
+		if after != "" && key <= after {
+			continue
+		}
 		ok, err := s.shouldHandleIngress(ing)
-		if ok && err == nil {
+		if !ok || err != nil {
+			continue
+		}
+		if limit > 0 && len(ingresses) == limit {
+			last := ingresses[len(ingresses)-1]
+			return ingresses, encodeContinueToken(getKey(last.Name, last.Namespace)), nil
+		}
+		ingresses = append(ingresses, ing)
+	}
+	return ingresses, "", nil
+}
+
+// encodeContinueToken returns an opaque token for continuing a list after the "namespace/name" key
+func encodeContinueToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeContinueToken returns the "namespace/name" key a list continues after, or an empty key for an empty
+// token
+func decodeContinueToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", errors.NewErrInvalidContinueToken(token)
+	}
+	namespace, name, ok := strings.Cut(string(key), "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", errors.NewErrInvalidContinueToken(token)
+	}
+	return string(key), nil
+}
+
+// ListNgrokIngressesForService returns the ngrok ingresses that use the service as a backend in their rules'
+// paths or their default backend. The ingresses are found with the ingress store's service index rather than
+// by scanning every ingress, and are filtered by ingress class like ListNgrokIngressesV1.
+func (s Store) ListNgrokIngressesForService(serviceName, namespace string) []*netv1.Ingress {
+	items, err := s.byIndex(s.stores.IngressV1, ingressServiceIndex, getKey(serviceName, namespace))
+	if err != nil {
+		s.log.Error(err, "error listing ingresses for service", "service", serviceName, "namespace", namespace)
+		return nil
+	}
+
+	var ingresses []*netv1.Ingress
+	for _, item := range items {
+		ing, ok := item.(*netv1.Ingress)
+		if !ok {
+			continue
+		}
+		handled, err := s.shouldHandleIngress(ing)
+		if handled && err == nil {
 			ingresses = append(ingresses, ing)
 		}
 	}
+
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", ingresses[i].Namespace, ingresses[i].Name),
+			fmt.Sprintf("%s/%s", ingresses[j].Namespace, ingresses[j].Name)) < 0
+	})
 	return ingresses
 }
 
+// GetIngressesForDomain returns the ngrok ingresses that requested a reserved domain, so the domain's status can
+// be reflected on them. Ingresses match when one of their rule or TLS hosts is the domain, or for a wildcard
+// domain like "*.example.com", is a subdomain the wildcard covers. The ingresses are found with the ingress
+// store's host index, filtered by ingress class like ListNgrokIngressesV1, and sorted by namespace and name.
+func (s Store) GetIngressesForDomain(domain string) []*netv1.Ingress {
+	items, err := s.byIndex(s.stores.IngressV1, ingressHostIndex, strings.ToLower(domain))
+	if err != nil {
+		s.log.Error(err, "error listing ingresses for domain", "domain", domain)
+		return nil
+	}
+
+	var ingresses []*netv1.Ingress
+	for _, item := range items {
+		ing, ok := item.(*netv1.Ingress)
+		if !ok {
+			continue
+		}
+		handled, err := s.shouldHandleIngress(ing)
+		if handled && err == nil {
+			ingresses = append(ingresses, ing)
+		}
+	}
+
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", ingresses[i].Namespace, ingresses[i].Name),
+			fmt.Sprintf("%s/%s", ingresses[j].Namespace, ingresses[j].Name)) < 0
+	})
+	return ingresses
+}
+
+// ListNgrokIngressesByLabels returns the ingresses handled by the controller whose labels match the selector,
+// sorted by namespace and name. A nil or empty selector matches every ingress, like ListNgrokIngressesV1.
+func (s Store) ListNgrokIngressesByLabels(selector labels.Selector) []*netv1.Ingress {
+	ingresses := s.ListNgrokIngressesV1()
+	if selector == nil || selector.Empty() {
+		return ingresses
+	}
+
+	var matched []*netv1.Ingress
+	for _, ing := range ingresses {
+		if selector.Matches(labels.Set(ing.Labels)) {
+			matched = append(matched, ing)
+		}
+	}
+	return matched
+}
+
 // ListDomainsV1 returns the list of Domains in the Domain v1 store.
 func (s Store) ListDomainsV1() []*ingressv1alpha1.Domain {
 	// filter ingress rules
 	var domains []*ingressv1alpha1.Domain
-	for _, item := range s.stores.DomainV1.List() {
+	for _, item := range s.list(s.stores.DomainV1) {
 		domain, ok := item.(*ingressv1alpha1.Domain)
 		if !ok {
 			s.log.Info("listDomainsV1: dropping object of unexpected type: %#v", item)
@@ -327,10 +1096,34 @@ func (s Store) ListDomainsV1() []*ingressv1alpha1.Domain {
 	return domains
 }
 
+// ListSecretsV1 returns the list of Secrets in the namespace from the Secret v1 store. An empty namespace
+// lists the Secrets in all namespaces.
+func (s Store) ListSecretsV1(namespace string) []*corev1.Secret {
+	var secrets []*corev1.Secret
+	for _, item := range s.list(s.stores.SecretV1) {
+		secret, ok := item.(*corev1.Secret)
+		if !ok {
+			s.log.Info("listSecretsV1: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		if namespace != "" && secret.Namespace != namespace {
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", secrets[i].Namespace, secrets[i].Name),
+			fmt.Sprintf("%s/%s", secrets[j].Namespace, secrets[j].Name)) < 0
+	})
+
+	return secrets
+}
+
 // ListTunnelsV1 returns the list of Tunnels in the Tunnel v1 store.
 func (s Store) ListTunnelsV1() []*ingressv1alpha1.Tunnel {
 	var tunnels []*ingressv1alpha1.Tunnel
-	for _, item := range s.stores.TunnelV1.List() {
+	for _, item := range s.list(s.stores.TunnelV1) {
 		tunnel, ok := item.(*ingressv1alpha1.Tunnel)
 		if !ok {
 			s.log.Info("listTunnelsV1: dropping object of unexpected type: %#v", item)
@@ -350,7 +1143,7 @@ func (s Store) ListTunnelsV1() []*ingressv1alpha1.Tunnel {
 // ListHTTPSEdgesV1 returns the list of HTTPSEdges in the HTTPSEdge v1 store.
 func (s Store) ListHTTPSEdgesV1() []*ingressv1alpha1.HTTPSEdge {
 	var edges []*ingressv1alpha1.HTTPSEdge
-	for _, item := range s.stores.HTTPSEdgeV1.List() {
+	for _, item := range s.list(s.stores.HTTPSEdgeV1) {
 		edge, ok := item.(*ingressv1alpha1.HTTPSEdge)
 		if !ok {
 			s.log.Info("listHTTPSEdgesV1: dropping object of unexpected type: %#v", item)
@@ -367,10 +1160,50 @@ func (s Store) ListHTTPSEdgesV1() []*ingressv1alpha1.HTTPSEdge {
 	return edges
 }
 
+// ListTCPEdgesV1 returns the list of TCPEdges in the TCPEdge v1 store.
+func (s Store) ListTCPEdgesV1() []*ingressv1alpha1.TCPEdge {
+	var edges []*ingressv1alpha1.TCPEdge
+	for _, item := range s.list(s.stores.TCPEdgeV1) {
+		edge, ok := item.(*ingressv1alpha1.TCPEdge)
+		if !ok {
+			s.log.Info("listTCPEdgesV1: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		edges = append(edges, edge)
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", edges[i].Namespace, edges[i].Name),
+			fmt.Sprintf("%s/%s", edges[j].Namespace, edges[j].Name)) < 0
+	})
+
+	return edges
+}
+
+// ListIPPoliciesV1 returns the list of IPPolicies in the IPPolicy v1 store.
+func (s Store) ListIPPoliciesV1() []*ingressv1alpha1.IPPolicy {
+	var policies []*ingressv1alpha1.IPPolicy
+	for _, item := range s.list(s.stores.IPPolicyV1) {
+		policy, ok := item.(*ingressv1alpha1.IPPolicy)
+		if !ok {
+			s.log.Info("listIPPoliciesV1: dropping object of unexpected type: %#v", item)
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", policies[i].Namespace, policies[i].Name),
+			fmt.Sprintf("%s/%s", policies[j].Namespace, policies[j].Name)) < 0
+	})
+
+	return policies
+}
+
 // ListNgrokModuleSetsV1 returns the list of NgrokModules in the NgrokModuleSet v1 store.
 func (s Store) ListNgrokModuleSetsV1() []*ingressv1alpha1.NgrokModuleSet {
 	var modules []*ingressv1alpha1.NgrokModuleSet
-	for _, item := range s.stores.NgrokModuleV1.List() {
+	for _, item := range s.list(s.stores.NgrokModuleV1) {
 		module, ok := item.(*ingressv1alpha1.NgrokModuleSet)
 		if !ok {
 			s.log.Info("listNgrokModulesV1: dropping object of unexpected type: %#v", item)
@@ -388,6 +1221,9 @@ func (s Store) ListNgrokModuleSetsV1() []*ingressv1alpha1.NgrokModuleSet {
 }
 
 func (s Store) shouldHandleIngress(ing *netv1.Ingress) (bool, error) {
+	if ing.Annotations[AnnotationIgnore] == "true" {
+		return false, errors.NewErrIngressIgnored(AnnotationIgnore)
+	}
 	ok, err := s.shouldHandleIngressIsValid(ing)
 	if err != nil {
 		return ok, err
@@ -427,7 +1263,7 @@ func (s Store) shouldHandleIngressIsValid(ing *netv1.Ingress) (bool, error) {
 			errs.AddError("A host is required to be set")
 		}
 
-		for _, path := range ing.Spec.Rules[0].HTTP.Paths {
+		for _, path := range ingressRulePaths(ing, ing.Spec.Rules[0]) {
 			if path.Backend.Resource != nil {
 				errs.AddError("Resource backends are not supported")
 			}
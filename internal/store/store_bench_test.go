@@ -0,0 +1,99 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// BenchmarkConcurrentStoreAccess adds objects to the store from several writers while several readers list
+// them. Run it with -race to check the store is safe for concurrent use.
+func BenchmarkConcurrentStoreAccess(b *testing.B) {
+	const writers = 4
+	const readers = 8
+
+	logger := logr.New(logr.Discard().GetSink())
+	s := New(NewCacheStores(logger), defaultControllerName, logger)
+
+	ic := NewTestIngressClass(ngrokIngressClass, true, true)
+	if err := s.Add(&ic); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				name := fmt.Sprintf("ingress-%d-%d", i, w)
+				ing := NewTestIngressV1WithClass(name, "test-namespace", ngrokIngressClass)
+				if err := s.Add(&ing); err != nil {
+					b.Error(err)
+				}
+				svc := NewTestServiceV1(name, "test-namespace")
+				if err := s.Add(&svc); err != nil {
+					b.Error(err)
+				}
+				secret := NewTestSecretV1(name, "test-namespace")
+				if err := s.Add(&secret); err != nil {
+					b.Error(err)
+				}
+			}(w)
+		}
+		for r := 0; r < readers; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.ListNgrokIngressesV1()
+				s.ListNgrokIngressClassesV1()
+				s.ListSecretsV1("test-namespace")
+				s.ListNgrokModuleSetsV1()
+				s.ListDomainsV1()
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// warmupObjects returns n ingresses, services, and secrets to add to the store, as during controller startup
+func warmupObjects(n int) []runtime.Object {
+	objs := make([]runtime.Object, 0, n)
+	for i := 0; len(objs) < n; i++ {
+		name := fmt.Sprintf("warmup-%d", i)
+		ing := NewTestIngressV1WithClass(name, "test-namespace", ngrokIngressClass)
+		svc := NewTestServiceV1(name, "test-namespace")
+		secret := NewTestSecretV1(name, "test-namespace")
+		objs = append(objs, &ing, &svc, &secret)
+	}
+	return objs[:n]
+}
+
+// BenchmarkAddAll compares adding 10k objects to the store at once with adding them one at a time
+func BenchmarkAddAll(b *testing.B) {
+	logger := logr.New(logr.Discard().GetSink())
+	objs := warmupObjects(10000)
+
+	b.Run("AddAll", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := New(NewCacheStores(logger), defaultControllerName, logger)
+			if err := s.AddAll(objs...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := New(NewCacheStores(logger), defaultControllerName, logger)
+			for _, obj := range objs {
+				if err := s.Add(obj); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
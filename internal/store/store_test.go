@@ -1,18 +1,37 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/errors"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 const ngrokIngressClass = "ngrok"
 const defaultControllerName = "k8s.ngrok.com/ingress-controller"
+const stagingControllerName = "k8s.ngrok.com/ingress-controller-staging"
 
 func TestStore(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -29,6 +48,190 @@ var _ = Describe("Store", func() {
 		store = New(cacheStores, defaultControllerName, logger)
 	})
 
+	var _ = Describe("AddAll", func() {
+		It("adds every object", func() {
+			ing := NewTestIngressV1("test-ingress", "test-namespace")
+			svc := NewTestServiceV1("test-service", "test-namespace")
+			secret := NewTestSecretV1("test-secret", "test-namespace")
+			Expect(store.AddAll(&ing, &svc, &secret)).To(Succeed())
+
+			Expect(store.GetIngressV1("test-ingress", "test-namespace")).ToNot(BeNil())
+			Expect(store.GetServiceV1("test-service", "test-namespace")).ToNot(BeNil())
+			Expect(store.GetSecretV1("test-secret", "test-namespace")).ToNot(BeNil())
+		})
+		It("reports the objects it couldn't add and adds the rest", func() {
+			logger := logr.New(logr.Discard().GetSink())
+			store = New(NewCacheStores(logger), defaultControllerName, logger, WithWatchNamespaces("test-namespace"))
+
+			svc := NewTestServiceV1("test-service", "test-namespace")
+			unwatched := NewTestServiceV1("other-service", "other-namespace")
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "test-namespace"}}
+			secret := NewTestSecretV1("test-secret", "test-namespace")
+
+			err := store.AddAll(&svc, &unwatched, pod, &secret)
+			agg, ok := err.(utilerrors.Aggregate)
+			Expect(ok).To(BeTrue())
+			Expect(agg.Errors()).To(HaveLen(2))
+			Expect(errors.IsErrNamespaceNotWatched(agg.Errors()[0])).To(BeTrue())
+			Expect(agg.Errors()[1]).To(MatchError("unsupported object type: *v1.Pod"))
+
+			Expect(store.GetServiceV1("test-service", "test-namespace")).ToNot(BeNil())
+			Expect(store.GetSecretV1("test-secret", "test-namespace")).ToNot(BeNil())
+			Expect(store.ListServicesV1()).To(HaveLen(1))
+		})
+	})
+
+	var _ = Describe("HasSynced", func() {
+		var synced atomic.Bool
+		var syncedStore Storer
+		BeforeEach(func() {
+			synced.Store(false)
+			logger := logr.New(logr.Discard().GetSink())
+			syncedStore = New(NewCacheStores(logger), defaultControllerName, logger, WithCacheSynced(synced.Load))
+		})
+
+		It("is always synced without any informers", func() {
+			Expect(store.HasSynced()).To(BeTrue())
+			Expect(store.WaitForCacheSync(context.Background())).To(BeTrue())
+		})
+		It("follows the sync status of the informers", func() {
+			Expect(syncedStore.HasSynced()).To(BeFalse())
+			synced.Store(true)
+			Expect(syncedStore.HasSynced()).To(BeTrue())
+		})
+		It("tells a getter's not found error apart from a cache that isn't warm yet", func() {
+			_, err := syncedStore.GetServiceV1("example", "test")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(syncedStore.HasSynced()).To(BeFalse())
+		})
+		It("waits for the informers to sync", func() {
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				synced.Store(true)
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			Expect(syncedStore.WaitForCacheSync(ctx)).To(BeTrue())
+		})
+		It("stops waiting when the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			Expect(syncedStore.WaitForCacheSync(ctx)).To(BeFalse())
+		})
+	})
+
+	var _ = Describe("GetIngressMetadata", func() {
+		var ing netv1.Ingress
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test-namespace")
+		})
+
+		It("returns an empty map without the annotation", func() {
+			metadata, err := store.GetIngressMetadata(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metadata).To(BeEmpty())
+			Expect(metadata).ToNot(BeNil())
+		})
+		It("returns the metadata of the annotation", func() {
+			ing.Annotations = map[string]string{"k8s.ngrok.com/metadata": `{"team": "payments", "cost-center": "1234"}`}
+			metadata, err := store.GetIngressMetadata(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metadata).To(Equal(map[string]string{"team": "payments", "cost-center": "1234"}))
+		})
+		It("returns an invalid content error for malformed JSON", func() {
+			ing.Annotations = map[string]string{"k8s.ngrok.com/metadata": `{"team": "payments"`}
+			metadata, err := store.GetIngressMetadata(&ing)
+			Expect(errors.IsInvalidContent(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("k8s.ngrok.com/metadata")))
+			Expect(metadata).To(BeNil())
+		})
+	})
+
+	var _ = Describe("NormalizePaths", func() {
+		var ing netv1.Ingress
+		BeforeEach(func() {
+			ing = NewTestIngressV1("test-ingress", "test-namespace")
+		})
+		withPath := func(path string, pathType *netv1.PathType) {
+			ing.Spec.Rules[0].HTTP.Paths[0].Path = path
+			ing.Spec.Rules[0].HTTP.Paths[0].PathType = pathType
+		}
+
+		DescribeTable("resolves the match type of the path type", func(pathType *netv1.PathType, matchType string) {
+			withPath("/api", pathType)
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(routes).To(Equal([]NormalizedRoute{{
+				Host:      "example.com",
+				Path:      "/api",
+				MatchType: matchType,
+				Backend:   ing.Spec.Rules[0].HTTP.Paths[0].Backend,
+			}}))
+		},
+			Entry("Prefix", ptr.To(netv1.PathTypePrefix), MatchTypePathPrefix),
+			Entry("Exact", ptr.To(netv1.PathTypeExact), MatchTypeExactPath),
+			Entry("ImplementationSpecific defaults to Prefix", ptr.To(netv1.PathTypeImplementationSpecific), MatchTypePathPrefix),
+			Entry("no path type defaults to Prefix", nil, MatchTypePathPrefix),
+		)
+
+		It("normalizes an empty path to /", func() {
+			withPath("", ptr.To(netv1.PathTypeImplementationSpecific))
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Path).To(Equal("/"))
+		})
+		It("allows wildcards in prefix paths", func() {
+			withPath("/static/*", ptr.To(netv1.PathTypeImplementationSpecific))
+			_, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("rejects an exact path with a wildcard", func() {
+			withPath("/api/*", ptr.To(netv1.PathTypeExact))
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).To(MatchError(`spec.rules[0].http.paths[0].path: Invalid value: "/api/*": exact paths can't contain wildcards`))
+			Expect(routes).To(BeNil())
+		})
+		It("rejects an unknown path type", func() {
+			withPath("/api", ptr.To(netv1.PathType("Regex")))
+			_, err := store.NormalizePaths(&ing)
+			Expect(err).To(MatchError(ContainSubstring(`spec.rules[0].http.paths[0].pathType: Unsupported value: "Regex"`)))
+		})
+		DescribeTable("routes ImplementationSpecific path expressions by their literal prefix", func(path, prefix, expression string) {
+			withPath(path, ptr.To(netv1.PathTypeImplementationSpecific))
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].MatchType).To(Equal(MatchTypePathPrefix))
+			Expect(routes[0].Path).To(Equal(prefix))
+			Expect(routes[0].Expression).To(Equal(expression))
+		},
+			Entry("a literal path", "/robots.txt", "/robots.txt", ""),
+			Entry("a character class", "/users/[0-9]+/profile", "/users/", "^(?:/users/[0-9]+/profile)"),
+			Entry("an optional character", "/users?", "/user", "^(?:/users?)"),
+			Entry("an anchored expression", "^/api/v[12]$", "/api/v", "^(?:/api/v[12]$)"),
+			Entry("an alternation", "/a|/b", "/", "^(?:/a|/b)"),
+		)
+		It("rejects an invalid path expression", func() {
+			withPath("/users/[0-9", ptr.To(netv1.PathTypeImplementationSpecific))
+			_, err := store.NormalizePaths(&ing)
+			Expect(err).To(MatchError(ContainSubstring(`spec.rules[0].http.paths[0].path: Invalid value: "/users/[0-9": invalid path expression`)))
+		})
+		It("matches expressions in prefix paths literally", func() {
+			withPath("/users/[0-9]+", ptr.To(netv1.PathTypePrefix))
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(routes[0].Path).To(Equal("/users/[0-9]+"))
+			Expect(routes[0].Expression).To(BeEmpty())
+		})
+		It("skips rules without HTTP paths", func() {
+			ing.Spec.Rules = append(ing.Spec.Rules, netv1.IngressRule{Host: "other.example.com"})
+			routes, err := store.NormalizePaths(&ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(routes).To(HaveLen(1))
+		})
+	})
+
 	var _ = Describe("GetIngressClassV1", func() {
 		Context("when the ingress class exists", func() {
 			BeforeEach(func() {
@@ -50,6 +253,93 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	var _ = Describe("NotFoundError", func() {
+		DescribeTable("getters return the kind, name, and namespace of the missing object", func(get func() error, gvk schema.GroupVersionKind, name, namespace string) {
+			err := get()
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+
+			notFound, ok := err.(*errors.NotFoundError)
+			Expect(ok).To(BeTrue())
+			Expect(notFound.GVK()).To(Equal(gvk))
+			Expect(notFound.NamespacedName()).To(Equal(types.NamespacedName{Name: name, Namespace: namespace}))
+		},
+			Entry("IngressClass", func() error { _, err := store.GetIngressClassV1("missing"); return err },
+				netv1.SchemeGroupVersion.WithKind("IngressClass"), "missing", ""),
+			Entry("IngressClass parameters", func() error {
+				ic := NewTestIngressClassWithParameters(ngrokIngressClass, "missing")
+				_, err := store.GetIngressClassParametersV1(&ic)
+				return err
+			}, ingressv1alpha1.GroupVersion.WithKind("NgrokIngressClassParams"), "missing", ""),
+			Entry("Ingress", func() error { _, err := store.GetIngressV1("missing", "test-namespace"); return err },
+				netv1.SchemeGroupVersion.WithKind("Ingress"), "missing", "test-namespace"),
+			Entry("ngrok Ingress", func() error { _, err := store.GetNgrokIngressV1("missing", "test-namespace"); return err },
+				netv1.SchemeGroupVersion.WithKind("Ingress"), "missing", "test-namespace"),
+			Entry("Service", func() error { _, err := store.GetServiceV1("missing", "test-namespace"); return err },
+				corev1.SchemeGroupVersion.WithKind("Service"), "missing", "test-namespace"),
+			Entry("Service of EndpointSlices", func() error { _, err := store.GetEndpointSlicesForService("missing", "test-namespace"); return err },
+				corev1.SchemeGroupVersion.WithKind("Service"), "missing", "test-namespace"),
+			Entry("Secret", func() error { _, err := store.GetSecretV1("missing", "test-namespace"); return err },
+				corev1.SchemeGroupVersion.WithKind("Secret"), "missing", "test-namespace"),
+			Entry("NgrokModuleSet", func() error { _, err := store.GetNgrokModuleSetV1("missing", "test-namespace"); return err },
+				ingressv1alpha1.GroupVersion.WithKind("NgrokModuleSet"), "missing", "test-namespace"),
+			Entry("NgrokTrafficPolicy", func() error { _, err := store.GetNgrokTrafficPolicyV1("missing", "test-namespace"); return err },
+				ngrokv1alpha1.GroupVersion.WithKind("NgrokTrafficPolicy"), "missing", "test-namespace"),
+			Entry("Gateway", func() error { _, err := store.GetGateway("missing", "test-namespace"); return err },
+				gatewayv1.SchemeGroupVersion.WithKind("Gateway"), "missing", "test-namespace"),
+			Entry("HTTPRoute", func() error { _, err := store.GetHTTPRouteV1("missing", "test-namespace"); return err },
+				gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute"), "missing", "test-namespace"),
+			Entry("TCPEdge", func() error { _, err := store.GetTCPEdgeV1("missing", "test-namespace"); return err },
+				ingressv1alpha1.GroupVersion.WithKind("TCPEdge"), "missing", "test-namespace"),
+			Entry("Domain", func() error { _, err := store.GetDomainV1("missing", "test-namespace"); return err },
+				ingressv1alpha1.GroupVersion.WithKind("Domain"), "missing", "test-namespace"),
+			Entry("IPPolicy", func() error { _, err := store.GetIPPolicyV1("missing", "test-namespace"); return err },
+				ingressv1alpha1.GroupVersion.WithKind("IPPolicy"), "missing", "test-namespace"),
+		)
+	})
+
+	var _ = Describe("GetIngressClassParametersV1", func() {
+		Context("when the ingress class has no parameters", func() {
+			It("returns nil", func() {
+				ic := NewTestIngressClass(ngrokIngressClass, true, true)
+				params, err := store.GetIngressClassParametersV1(&ic)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params).To(BeNil())
+			})
+		})
+		Context("when the ingress class references parameters", func() {
+			BeforeEach(func() {
+				clusterParams := NewTestNgrokIngressClassParams("cluster-params")
+				Expect(store.Add(&clusterParams)).To(BeNil())
+			})
+			It("returns cluster scoped parameters", func() {
+				ic := NewTestIngressClassWithParameters(ngrokIngressClass, "cluster-params")
+				params, err := store.GetIngressClassParametersV1(&ic)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(params.Name).To(Equal("cluster-params"))
+			})
+			It("returns an error when the reference is namespace scoped", func() {
+				ic := NewTestIngressClassWithParameters(ngrokIngressClass, "cluster-params")
+				ic.Spec.Parameters.Scope = ptr.To(netv1.IngressClassParametersReferenceScopeNamespace)
+				ic.Spec.Parameters.Namespace = ptr.To("test-namespace")
+				params, err := store.GetIngressClassParametersV1(&ic)
+				Expect(err).To(MatchError(ContainSubstring("NgrokIngressClassParams are cluster scoped")))
+				Expect(params).To(BeNil())
+			})
+			It("returns a not found error when the reference dangles", func() {
+				ic := NewTestIngressClassWithParameters(ngrokIngressClass, "missing")
+				params, err := store.GetIngressClassParametersV1(&ic)
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(params).To(BeNil())
+			})
+			It("returns an error when the reference is to another kind", func() {
+				ic := NewTestIngressClassWithParameters(ngrokIngressClass, "cluster-params")
+				ic.Spec.Parameters.Kind = "ConfigMap"
+				_, err := store.GetIngressClassParametersV1(&ic)
+				Expect(err).To(MatchError(ContainSubstring("unsupported kind ConfigMap")))
+			})
+		})
+	})
+
 	var _ = Describe("GetIngressV1", func() {
 		Context("when the ingress exists", func() {
 			BeforeEach(func() {
@@ -92,6 +382,107 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	var _ = Describe("GetSecretV1", func() {
+		BeforeEach(func() {
+			secret := NewTestSecretV1("test-secret", "test-namespace")
+			Expect(store.Add(&secret)).To(BeNil())
+		})
+
+		DescribeTable("returns the secret only when it exists in the namespace", func(name, namespace string, found bool) {
+			secret, err := store.GetSecretV1(name, namespace)
+			if found {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(secret.Name).To(Equal(name))
+				Expect(secret.Namespace).To(Equal(namespace))
+				return
+			}
+			Expect(err).To(HaveOccurred())
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(secret).To(BeNil())
+		},
+			Entry("present", "test-secret", "test-namespace", true),
+			Entry("absent", "does-not-exist", "test-namespace", false),
+			Entry("wrong namespace", "test-secret", "other-namespace", false),
+		)
+	})
+
+	var _ = Describe("ListSecretsV1", func() {
+		BeforeEach(func() {
+			for _, secret := range []corev1.Secret{
+				NewTestSecretV1("b-secret", "test-namespace"),
+				NewTestSecretV1("a-secret", "test-namespace"),
+				NewTestSecretV1("c-secret", "other-namespace"),
+			} {
+				secret := secret
+				Expect(store.Add(&secret)).To(BeNil())
+			}
+		})
+
+		DescribeTable("lists the secrets in the namespace", func(namespace string, expected []string) {
+			names := []string{}
+			for _, secret := range store.ListSecretsV1(namespace) {
+				names = append(names, secret.Namespace+"/"+secret.Name)
+			}
+			Expect(names).To(Equal(expected))
+		},
+			Entry("present", "test-namespace", []string{"test-namespace/a-secret", "test-namespace/b-secret"}),
+			Entry("absent", "empty-namespace", []string{}),
+			Entry("wrong namespace", "other-namespace", []string{"other-namespace/c-secret"}),
+			Entry("all namespaces", "", []string{"other-namespace/c-secret", "test-namespace/a-secret", "test-namespace/b-secret"}),
+		)
+	})
+
+	var _ = Describe("GetEndpointSlicesForService", func() {
+		BeforeEach(func() {
+			for _, svc := range []corev1.Service{
+				NewTestServiceV1("test-service", "test-namespace"),
+				NewTestServiceV1("no-slices", "test-namespace"),
+			} {
+				svc := svc
+				Expect(store.Add(&svc)).To(BeNil())
+			}
+			for _, slice := range []discoveryv1.EndpointSlice{
+				NewTestEndpointSlice("test-service-b", "test-namespace", "test-service"),
+				NewTestEndpointSlice("test-service-a", "test-namespace", "test-service"),
+				NewTestEndpointSlice("test-service-c", "other-namespace", "test-service"),
+				NewTestEndpointSlice("other-service-a", "test-namespace", "other-service"),
+			} {
+				slice := slice
+				Expect(store.Add(&slice)).To(BeNil())
+			}
+		})
+
+		DescribeTable("returns the slices labeled with the service", func(serviceName string, expected []string, found bool) {
+			slices, err := store.GetEndpointSlicesForService(serviceName, "test-namespace")
+			if !found {
+				Expect(err).To(HaveOccurred())
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(slices).To(BeNil())
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			names := []string{}
+			for _, slice := range slices {
+				names = append(names, slice.Namespace+"/"+slice.Name)
+			}
+			Expect(names).To(Equal(expected))
+		},
+			Entry("present", "test-service", []string{"test-namespace/test-service-a", "test-namespace/test-service-b"}, true),
+			Entry("no slices yet", "no-slices", []string{}, true),
+			Entry("service absent", "other-service", nil, false),
+		)
+
+		It("stops returning a slice once it is deleted", func() {
+			slice := NewTestEndpointSlice("test-service-a", "test-namespace", "test-service")
+			Expect(store.Delete(&slice)).To(BeNil())
+
+			slices, err := store.GetEndpointSlicesForService("test-service", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(slices).To(HaveLen(1))
+			Expect(slices[0].Name).To(Equal("test-service-b"))
+		})
+	})
+
 	var _ = Describe("GetNgrokIngressV1", func() {
 		Context("when the ngrok ingress exists", func() {
 			BeforeEach(func() {
@@ -159,32 +550,604 @@ var _ = Describe("Store", func() {
 		icUsNotDefault := NewTestIngressClass("ngrok", false, true)
 		icOtherDefault := NewTestIngressClass("test", true, false)
 		icOtherNotDefault := NewTestIngressClass("test", false, false)
+		icStagingDefault := NewTestIngressClass("ngrok-staging", true, true)
+		icStagingDefault.Spec.Controller = stagingControllerName
+		icStagingNotDefault := NewTestIngressClass("ngrok-staging", false, true)
+		icStagingNotDefault.Spec.Controller = stagingControllerName
+
+		var _ = DescribeTable("IngressClassFiltering", func(additionalControllerNames []string, ingressClasses []netv1.IngressClass, expectedMatchingIngressesCount int) {
+			logger := logr.Discard()
+			store := New(NewCacheStores(logger), defaultControllerName, logger, WithAdditionalControllerNames(additionalControllerNames...))
 
-		var _ = DescribeTable("IngressClassFiltering", func(ingressClasses []netv1.IngressClass, expectedMatchingIngressesCount int) {
 			iMatching := NewTestIngressV1WithClass("test1", "test", "ngrok")
 			iNotMatching := NewTestIngressV1WithClass("test2", "test", "test")
 			iNoClass := NewTestIngressV1("test3", "test")
+			iStaging := NewTestIngressV1WithClass("test4", "test", "ngrok-staging")
 			Expect(store.Add(&iMatching)).To(BeNil())
 			Expect(store.Add(&iNotMatching)).To(BeNil())
 			Expect(store.Add(&iNoClass)).To(BeNil())
+			Expect(store.Add(&iStaging)).To(BeNil())
 			for _, ic := range ingressClasses {
 				Expect(store.Add(&ic)).To(BeNil())
 			}
 			ings := store.ListNgrokIngressesV1()
 			Expect(len(ings)).To(Equal(expectedMatchingIngressesCount))
 		},
-			Entry("No ingress classes", []netv1.IngressClass{}, 0),
-			Entry("just us not as default", []netv1.IngressClass{icUsNotDefault}, 1),
-			Entry("just us as default", []netv1.IngressClass{icUsDefault}, 2),
-			Entry("just another not as default", []netv1.IngressClass{icOtherNotDefault}, 0),
-			Entry("just another as default", []netv1.IngressClass{icOtherDefault}, 0),
-			Entry("us and another neither default", []netv1.IngressClass{icUsNotDefault, icOtherNotDefault}, 1),
-			Entry("us and another them default", []netv1.IngressClass{icUsNotDefault, icOtherDefault}, 1),
-			Entry("us and another us default", []netv1.IngressClass{icUsDefault, icOtherNotDefault}, 2),
-			Entry("us and another both default", []netv1.IngressClass{icUsDefault, icOtherDefault}, 2),
+			Entry("No ingress classes", nil, []netv1.IngressClass{}, 0),
+			Entry("just us not as default", nil, []netv1.IngressClass{icUsNotDefault}, 1),
+			Entry("just us as default", nil, []netv1.IngressClass{icUsDefault}, 2),
+			Entry("just another not as default", nil, []netv1.IngressClass{icOtherNotDefault}, 0),
+			Entry("just another as default", nil, []netv1.IngressClass{icOtherDefault}, 0),
+			Entry("us and another neither default", nil, []netv1.IngressClass{icUsNotDefault, icOtherNotDefault}, 1),
+			Entry("us and another them default", nil, []netv1.IngressClass{icUsNotDefault, icOtherDefault}, 1),
+			Entry("us and another us default", nil, []netv1.IngressClass{icUsDefault, icOtherNotDefault}, 2),
+			Entry("us and another both default", nil, []netv1.IngressClass{icUsDefault, icOtherDefault}, 2),
+			Entry("staging without its controller name", nil, []netv1.IngressClass{icUsNotDefault, icStagingDefault}, 1),
+			Entry("two controllers neither default", []string{stagingControllerName}, []netv1.IngressClass{icUsNotDefault, icStagingNotDefault}, 2),
+			Entry("two controllers staging default", []string{stagingControllerName}, []netv1.IngressClass{icUsNotDefault, icStagingDefault}, 3),
+			Entry("two controllers just staging", []string{stagingControllerName}, []netv1.IngressClass{icStagingNotDefault}, 1),
+			Entry("two controllers and another default", []string{stagingControllerName}, []netv1.IngressClass{icUsNotDefault, icStagingNotDefault, icOtherDefault}, 2),
+		)
+
+		var _ = DescribeTable("IgnoreAnnotation", func(ignore string, ingressClasses []netv1.IngressClass, expectedNames []string) {
+			logger := logr.Discard()
+			store := New(NewCacheStores(logger), defaultControllerName, logger)
+
+			iMatching := NewTestIngressV1WithClass("test1", "test", "ngrok")
+			iNoClass := NewTestIngressV1("test2", "test")
+			iMatching.Annotations = map[string]string{AnnotationIgnore: ignore}
+			iNoClass.Annotations = map[string]string{AnnotationIgnore: ignore}
+			iNotAnnotated := NewTestIngressV1("test3", "test")
+			Expect(store.Add(&iMatching)).To(BeNil())
+			Expect(store.Add(&iNoClass)).To(BeNil())
+			Expect(store.Add(&iNotAnnotated)).To(BeNil())
+			for _, ic := range ingressClasses {
+				Expect(store.Add(&ic)).To(BeNil())
+			}
+
+			var names []string
+			for _, ing := range store.ListNgrokIngressesV1() {
+				names = append(names, ing.Name)
+			}
+			Expect(names).To(Equal(expectedNames))
+
+			for _, name := range []string{"test1", "test2"} {
+				ing, err := store.GetNgrokIngressV1(name, "test")
+				if ignore == "true" {
+					Expect(errors.IsErrIngressIgnored(err)).To(BeTrue())
+					Expect(ing).To(BeNil())
+				} else {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ing.Name).To(Equal(name))
+				}
+			}
+		},
+			Entry("ignored under the default class", "true", []netv1.IngressClass{icUsDefault}, []string{"test3"}),
+			Entry("ignored under the default class among others", "true", []netv1.IngressClass{icUsDefault, icOtherNotDefault}, []string{"test3"}),
+			Entry("not ignored when false", "false", []netv1.IngressClass{icUsDefault}, []string{"test1", "test2", "test3"}),
+			Entry("not ignored when empty", "", []netv1.IngressClass{icUsDefault}, []string{"test1", "test2", "test3"}),
 		)
 	})
 
+	var _ = Describe("ListNgrokIngressesV1Paged", func() {
+		BeforeEach(func() {
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(store.Add(&ic)).To(BeNil())
+			for _, ns := range []string{"team-b", "team-a", "team-c"} {
+				for i := 0; i < 4; i++ {
+					ing := NewTestIngressV1WithClass(fmt.Sprintf("ingress-%d", i), ns, ngrokIngressClass)
+					Expect(store.Add(&ing)).To(BeNil())
+				}
+				other := NewTestIngressV1WithClass("other-class", ns, "other")
+				Expect(store.Add(&other)).To(BeNil())
+			}
+		})
+
+		keys := func(ings []*netv1.Ingress) []string {
+			var keys []string
+			for _, ing := range ings {
+				keys = append(keys, ing.Namespace+"/"+ing.Name)
+			}
+			return keys
+		}
+
+		It("pages through all ngrok ingresses without duplicates", func() {
+			all := keys(store.ListNgrokIngressesV1())
+			Expect(all).To(HaveLen(12))
+
+			for _, limit := range []int{1, 5, 12, 13} {
+				var paged []string
+				token := ""
+				pages := 0
+				for {
+					page, next, err := store.ListNgrokIngressesV1Paged(token, limit)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(page)).To(BeNumerically("<=", limit))
+					paged = append(paged, keys(page)...)
+					pages++
+					if next == "" {
+						break
+					}
+					token = next
+				}
+				Expect(paged).To(Equal(all), "limit %d", limit)
+				Expect(pages).To(Equal((12+limit-1)/limit), "limit %d", limit)
+			}
+		})
+
+		It("sorts the pages by namespace and name", func() {
+			page, next, err := store.ListNgrokIngressesV1Paged("", 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next).ToNot(BeEmpty())
+			Expect(keys(page)).To(Equal([]string{
+				"team-a/ingress-0", "team-a/ingress-1", "team-a/ingress-2", "team-a/ingress-3", "team-b/ingress-0",
+			}))
+		})
+
+		It("returns everything in one page without a limit", func() {
+			page, next, err := store.ListNgrokIngressesV1Paged("", 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next).To(BeEmpty())
+			Expect(page).To(HaveLen(12))
+		})
+
+		It("returns an error for an invalid token", func() {
+			for _, token := range []string{"not base64!", "bm8tc2xhc2g"} {
+				page, next, err := store.ListNgrokIngressesV1Paged(token, 5)
+				Expect(errors.IsErrInvalidContinueToken(err)).To(BeTrue(), token)
+				Expect(err).To(MatchError(fmt.Sprintf("invalid continue token %q", token)))
+				Expect(page).To(BeNil())
+				Expect(next).To(BeEmpty())
+			}
+		})
+	})
+
+	var _ = Describe("ListNgrokIngressesForService", func() {
+		withBackend := func(ing netv1.Ingress, serviceName string) netv1.Ingress {
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = serviceName
+			return ing
+		}
+		withDefaultBackend := func(ing netv1.Ingress, serviceName string) netv1.Ingress {
+			ing.Spec.DefaultBackend = &netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{Name: serviceName, Port: netv1.ServiceBackendPort{Number: 80}},
+			}
+			return ing
+		}
+
+		BeforeEach(func() {
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(store.Add(&ic)).To(BeNil())
+
+			ings := []netv1.Ingress{
+				withBackend(NewTestIngressV1WithClass("api-path", "test-namespace", ngrokIngressClass), "api"),
+				withDefaultBackend(withBackend(NewTestIngressV1WithClass("web-path-api-default", "test-namespace", ngrokIngressClass), "web"), "api"),
+				withBackend(NewTestIngressV1WithClass("web-path", "test-namespace", ngrokIngressClass), "web"),
+				withBackend(NewTestIngressV1WithClass("api-other-namespace", "other-namespace", ngrokIngressClass), "api"),
+				withBackend(NewTestIngressV1WithClass("api-other-class", "test-namespace", "other"), "api"),
+			}
+			for i := range ings {
+				Expect(store.Add(&ings[i])).To(BeNil())
+			}
+		})
+
+		names := func(ings []*netv1.Ingress) []string {
+			var names []string
+			for _, ing := range ings {
+				names = append(names, ing.Name)
+			}
+			return names
+		}
+
+		It("returns the ngrok ingresses using the service in a path or their default backend", func() {
+			Expect(names(store.ListNgrokIngressesForService("api", "test-namespace"))).To(Equal([]string{"api-path", "web-path-api-default"}))
+			Expect(names(store.ListNgrokIngressesForService("web", "test-namespace"))).To(Equal([]string{"web-path", "web-path-api-default"}))
+			Expect(names(store.ListNgrokIngressesForService("api", "other-namespace"))).To(Equal([]string{"api-other-namespace"}))
+		})
+
+		It("returns nothing for a service no ngrok ingress uses", func() {
+			Expect(store.ListNgrokIngressesForService("unused", "test-namespace")).To(BeEmpty())
+		})
+
+		It("stops returning an ingress once it no longer uses the service", func() {
+			updated := withBackend(NewTestIngressV1WithClass("api-path", "test-namespace", ngrokIngressClass), "web")
+			Expect(store.Update(&updated)).Error().To(BeNil())
+			Expect(names(store.ListNgrokIngressesForService("api", "test-namespace"))).To(Equal([]string{"web-path-api-default"}))
+		})
+	})
+
+	var _ = Describe("ListResolvableNgrokIngressesV1", func() {
+		withBackend := func(ing netv1.Ingress, serviceName string, port netv1.ServiceBackendPort) netv1.Ingress {
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = serviceName
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = port
+			return ing
+		}
+		backendPath := field.NewPath("spec", "rules").Index(0).Child("http", "paths").Index(0).Child("backend", "service")
+
+		BeforeEach(func() {
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(store.Add(&ic)).To(BeNil())
+			svc := NewTestServiceV1("api", "test-namespace")
+			Expect(store.Add(&svc)).To(BeNil())
+
+			missingDefault := withBackend(NewTestIngressV1WithClass("missing-default", "test-namespace", ngrokIngressClass), "api", netv1.ServiceBackendPort{Number: 80})
+			missingDefault.Spec.DefaultBackend = &netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{Name: "default", Port: netv1.ServiceBackendPort{Number: 80}},
+			}
+			ings := []netv1.Ingress{
+				withBackend(NewTestIngressV1WithClass("api-port-number", "test-namespace", ngrokIngressClass), "api", netv1.ServiceBackendPort{Number: 80}),
+				withBackend(NewTestIngressV1WithClass("api-port-name", "test-namespace", ngrokIngressClass), "api", netv1.ServiceBackendPort{Name: "http"}),
+				withBackend(NewTestIngressV1WithClass("missing-service", "test-namespace", ngrokIngressClass), "web", netv1.ServiceBackendPort{Number: 80}),
+				withBackend(NewTestIngressV1WithClass("missing-port", "test-namespace", ngrokIngressClass), "api", netv1.ServiceBackendPort{Name: "grpc"}),
+				missingDefault,
+				withBackend(NewTestIngressV1WithClass("other-class", "test-namespace", "other"), "web", netv1.ServiceBackendPort{Number: 80}),
+			}
+			for i := range ings {
+				Expect(store.Add(&ings[i])).To(BeNil())
+			}
+		})
+
+		It("splits the ngrok ingresses by whether their backends resolve", func() {
+			resolvable, unresolvable := store.ListResolvableNgrokIngressesV1()
+
+			var names []string
+			for _, ing := range resolvable {
+				names = append(names, ing.Name)
+			}
+			Expect(names).To(Equal([]string{"api-port-name", "api-port-number"}))
+
+			errs := map[string]field.ErrorList{}
+			for _, err := range unresolvable {
+				errs[err.Ingress.Name] = err.Errors
+			}
+			Expect(errs).To(Equal(map[string]field.ErrorList{
+				"missing-service": {field.NotFound(backendPath.Child("name"), "web")},
+				"missing-port":    {field.NotFound(backendPath.Child("port", "name"), "grpc")},
+				"missing-default": {field.NotFound(field.NewPath("spec", "defaultBackend", "service", "name"), "default")},
+			}))
+		})
+
+		It("resolves an ingress once its backend service exists", func() {
+			svc := NewTestServiceV1("web", "test-namespace")
+			Expect(store.Add(&svc)).To(BeNil())
+
+			resolvable, unresolvable := store.ListResolvableNgrokIngressesV1()
+			Expect(resolvable).To(HaveLen(3))
+			Expect(unresolvable).To(HaveLen(2))
+		})
+
+		It("leaves ListNgrokIngressesV1 unfiltered", func() {
+			Expect(store.ListNgrokIngressesV1()).To(HaveLen(5))
+		})
+	})
+
+	var _ = Describe("ResolveBackend", func() {
+		var ing netv1.Ingress
+
+		BeforeEach(func() {
+			svc := NewTestServiceV1("api", "test-namespace")
+			svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Name: "grpc", Protocol: corev1.ProtocolTCP, Port: 9090})
+			Expect(store.Add(&svc)).To(BeNil())
+			ing = NewTestIngressV1WithClass("api", "test-namespace", ngrokIngressClass)
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "api"
+		})
+
+		resolve := func(port netv1.ServiceBackendPort) (*corev1.Service, int32, error) {
+			path := ing.Spec.Rules[0].HTTP.Paths[0]
+			path.Backend.Service.Port = port
+			return store.ResolveBackend(&ing, path)
+		}
+
+		It("resolves a numbered port", func() {
+			svc, port, err := resolve(netv1.ServiceBackendPort{Number: 9090})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(svc.Name).To(Equal("api"))
+			Expect(port).To(Equal(int32(9090)))
+		})
+
+		It("resolves a named port to its number", func() {
+			svc, port, err := resolve(netv1.ServiceBackendPort{Name: "http"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(svc.Name).To(Equal("api"))
+			Expect(port).To(Equal(int32(80)))
+		})
+
+		It("returns a not found error for a missing service", func() {
+			ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "web"
+			svc, _, err := resolve(netv1.ServiceBackendPort{Number: 80})
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(svc).To(BeNil())
+		})
+
+		It("returns a not found error for a named port the service doesn't have", func() {
+			svc, _, err := resolve(netv1.ServiceBackendPort{Name: "admin"})
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("admin"))
+			Expect(svc).To(BeNil())
+		})
+	})
+
+	var _ = Describe("GetIngressesForDomain", func() {
+		withHost := func(ing netv1.Ingress, host string) netv1.Ingress {
+			ing.Spec.Rules[0].Host = host
+			return ing
+		}
+
+		BeforeEach(func() {
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(store.Add(&ic)).To(BeNil())
+
+			tlsOnly := withHost(NewTestIngressV1WithClass("tls-only", "test-namespace", ngrokIngressClass), "internal.example.org")
+			tlsOnly.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{"API.example.com"}}}
+			ings := []netv1.Ingress{
+				withHost(NewTestIngressV1WithClass("api", "test-namespace", ngrokIngressClass), "api.example.com"),
+				tlsOnly,
+				withHost(NewTestIngressV1WithClass("web", "test-namespace", ngrokIngressClass), "web.example.com"),
+				withHost(NewTestIngressV1WithClass("nested", "test-namespace", ngrokIngressClass), "v1.api.example.com"),
+				withHost(NewTestIngressV1WithClass("other-class", "test-namespace", "other"), "api.example.com"),
+			}
+			for i := range ings {
+				Expect(store.Add(&ings[i])).To(BeNil())
+			}
+		})
+
+		names := func(ingresses []*netv1.Ingress) []string {
+			var names []string
+			for _, ing := range ingresses {
+				names = append(names, ing.Name)
+			}
+			return names
+		}
+
+		It("returns the ngrok ingresses with a rule or TLS host matching the domain", func() {
+			Expect(names(store.GetIngressesForDomain("api.example.com"))).To(Equal([]string{"api", "tls-only"}))
+		})
+
+		It("returns the ngrok ingresses with hosts a wildcard domain covers", func() {
+			Expect(names(store.GetIngressesForDomain("*.example.com"))).To(Equal([]string{"api", "tls-only", "web"}))
+			Expect(names(store.GetIngressesForDomain("*.api.example.com"))).To(Equal([]string{"nested"}))
+		})
+
+		It("returns nothing when no ingress uses the domain", func() {
+			Expect(store.GetIngressesForDomain("example.net")).To(BeEmpty())
+		})
+
+		It("stops returning an ingress once its host changes", func() {
+			ing := withHost(NewTestIngressV1WithClass("api", "test-namespace", ngrokIngressClass), "api.example.net")
+			Expect(store.Update(&ing)).Error().To(BeNil())
+			Expect(names(store.GetIngressesForDomain("api.example.com"))).To(Equal([]string{"tls-only"}))
+		})
+	})
+
+	var _ = Describe("ListNgrokIngressesByLabels", func() {
+		withLabels := func(ing netv1.Ingress, labels map[string]string) netv1.Ingress {
+			ing.Labels = labels
+			return ing
+		}
+
+		BeforeEach(func() {
+			ic := NewTestIngressClass(ngrokIngressClass, false, true)
+			Expect(store.Add(&ic)).To(BeNil())
+
+			ings := []netv1.Ingress{
+				withLabels(NewTestIngressV1WithClass("payments-api", "test-namespace", ngrokIngressClass), map[string]string{"team": "payments", "tier": "api"}),
+				withLabels(NewTestIngressV1WithClass("payments-web", "test-namespace", ngrokIngressClass), map[string]string{"team": "payments", "tier": "web"}),
+				withLabels(NewTestIngressV1WithClass("search-api", "test-namespace", ngrokIngressClass), map[string]string{"team": "search", "tier": "api"}),
+				NewTestIngressV1WithClass("unlabeled", "test-namespace", ngrokIngressClass),
+				withLabels(NewTestIngressV1WithClass("payments-other-class", "test-namespace", "other"), map[string]string{"team": "payments"}),
+			}
+			for i := range ings {
+				Expect(store.Add(&ings[i])).To(BeNil())
+			}
+		})
+
+		names := func(ings []*netv1.Ingress) []string {
+			var names []string
+			for _, ing := range ings {
+				names = append(names, ing.Name)
+			}
+			return names
+		}
+
+		It("returns the ngrok ingresses matching the selector", func() {
+			Expect(names(store.ListNgrokIngressesByLabels(labels.SelectorFromSet(labels.Set{"team": "payments"})))).To(Equal([]string{"payments-api", "payments-web"}))
+			Expect(names(store.ListNgrokIngressesByLabels(labels.SelectorFromSet(labels.Set{"team": "payments", "tier": "api"})))).To(Equal([]string{"payments-api"}))
+
+			selector, err := labels.Parse("tier in (api),team!=payments")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names(store.ListNgrokIngressesByLabels(selector))).To(Equal([]string{"search-api"}))
+
+			selector, err = labels.Parse("!team")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names(store.ListNgrokIngressesByLabels(selector))).To(Equal([]string{"unlabeled"}))
+		})
+
+		It("doesn't return ingresses of other classes even if their labels match", func() {
+			matched := store.ListNgrokIngressesByLabels(labels.SelectorFromSet(labels.Set{"team": "payments"}))
+			Expect(names(matched)).ToNot(ContainElement("payments-other-class"))
+		})
+
+		It("returns nothing when no ngrok ingress matches", func() {
+			Expect(store.ListNgrokIngressesByLabels(labels.SelectorFromSet(labels.Set{"team": "billing"}))).To(BeEmpty())
+		})
+
+		It("returns every ngrok ingress for a nil or empty selector", func() {
+			all := names(store.ListNgrokIngressesV1())
+			Expect(all).To(Equal([]string{"payments-api", "payments-web", "search-api", "unlabeled"}))
+			Expect(names(store.ListNgrokIngressesByLabels(nil))).To(Equal(all))
+			Expect(names(store.ListNgrokIngressesByLabels(labels.Everything()))).To(Equal(all))
+		})
+	})
+
+	var _ = Describe("GetHTTPRouteV1", func() {
+		It("returns the HTTPRoute", func() {
+			route := NewTestHTTPRoute("route", "test-namespace", "gateway")
+			Expect(store.Add(&route)).To(BeNil())
+
+			found, err := store.GetHTTPRouteV1("route", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.Spec.ParentRefs[0].Name).To(Equal(gatewayv1.ObjectName("gateway")))
+		})
+		It("returns a not found error when the HTTPRoute does not exist", func() {
+			_, err := store.GetHTTPRouteV1("route", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+		})
+	})
+
+	var _ = Describe("ListHTTPRoutesForGatewayClass", func() {
+		BeforeEach(func() {
+			ngrokClass := NewTestGatewayClass("ngrok", DefaultGatewayControllerName)
+			otherClass := NewTestGatewayClass("other", "example.com/gateway-controller")
+			ngrokGateway := NewTestGateway("ngrok-gateway", "gateways", "ngrok")
+			otherGateway := NewTestGateway("other-gateway", "gateways", "other")
+			sameNamespaceRoute := NewTestHTTPRoute("same-namespace", "gateways", "ngrok-gateway")
+			crossNamespaceRoute := NewTestHTTPRoute("cross-namespace", "test-namespace", "ngrok-gateway")
+			crossNamespaceRoute.Spec.ParentRefs[0].Namespace = ptr.To(gatewayv1.Namespace("gateways"))
+			wrongNamespaceRoute := NewTestHTTPRoute("wrong-namespace", "test-namespace", "ngrok-gateway")
+			otherRoute := NewTestHTTPRoute("other", "gateways", "other-gateway")
+			for _, obj := range []runtime.Object{&ngrokClass, &otherClass, &ngrokGateway, &otherGateway, &sameNamespaceRoute, &crossNamespaceRoute, &wrongNamespaceRoute, &otherRoute} {
+				Expect(store.Add(obj)).To(BeNil())
+			}
+		})
+
+		names := func(routes []*gatewayv1.HTTPRoute) []string {
+			var names []string
+			for _, route := range routes {
+				names = append(names, route.Namespace+"/"+route.Name)
+			}
+			return names
+		}
+
+		It("returns the HTTPRoutes attached to gateways of the class", func() {
+			Expect(names(store.ListHTTPRoutesForGatewayClass("ngrok"))).To(Equal([]string{"gateways/same-namespace", "test-namespace/cross-namespace"}))
+		})
+		It("returns nothing for another controller's gateway class", func() {
+			Expect(store.ListHTTPRoutesForGatewayClass("other")).To(BeEmpty())
+		})
+		It("returns nothing for a gateway class that isn't in the store", func() {
+			Expect(store.ListHTTPRoutesForGatewayClass("missing")).To(BeEmpty())
+		})
+		It("honors the configured gateway controller name", func() {
+			logger := logr.Discard()
+			cs := NewCacheStores(logger)
+			other := New(cs, defaultControllerName, logger, WithGatewayControllerName("example.com/gateway-controller"))
+			otherClass := NewTestGatewayClass("other", "example.com/gateway-controller")
+			otherGateway := NewTestGateway("other-gateway", "gateways", "other")
+			otherRoute := NewTestHTTPRoute("other", "gateways", "other-gateway")
+			for _, obj := range []runtime.Object{&otherClass, &otherGateway, &otherRoute} {
+				Expect(other.Add(obj)).To(BeNil())
+			}
+			Expect(names(other.ListHTTPRoutesForGatewayClass("other"))).To(Equal([]string{"gateways/other"}))
+		})
+	})
+
+	var _ = Describe("Update", func() {
+		It("reports an object that wasn't stored yet as changed", func() {
+			svc := NewTestServiceV1("example", "test-namespace")
+			Expect(store.Update(&svc)).To(BeTrue())
+		})
+
+		It("reports an identical object as unchanged", func() {
+			ing := NewTestIngressV1WithClass("example", "test-namespace", ngrokIngressClass)
+			ing.ResourceVersion = "1"
+			Expect(store.Add(&ing)).To(BeNil())
+
+			same := NewTestIngressV1WithClass("example", "test-namespace", ngrokIngressClass)
+			same.ResourceVersion = "2"
+			same.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate}}
+			same.Status.LoadBalancer.Ingress = []netv1.IngressLoadBalancerIngress{{Hostname: "example.ngrok.app"}}
+			Expect(store.Update(&same)).To(BeFalse())
+
+			stored, err := store.GetIngressV1("example", "test-namespace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored.ResourceVersion).To(Equal("2"))
+		})
+
+		It("reports a modified object as changed", func() {
+			ing := NewTestIngressV1WithClass("example", "test-namespace", ngrokIngressClass)
+			Expect(store.Add(&ing)).To(BeNil())
+
+			modified := NewTestIngressV1WithClass("example", "test-namespace", ngrokIngressClass)
+			modified.Spec.Rules[0].Host = "other.example.com"
+			Expect(store.Update(&modified)).To(BeTrue())
+			Expect(store.Update(&modified)).To(BeFalse())
+
+			relabeled := modified.DeepCopy()
+			relabeled.Labels = map[string]string{"team": "payments"}
+			Expect(store.Update(relabeled)).To(BeTrue())
+		})
+
+		It("no longer returns an object once it's deleted", func() {
+			ing := NewTestIngressV1WithClass("example", "test-namespace", ngrokIngressClass)
+			Expect(store.Update(&ing)).To(BeTrue())
+			Expect(store.Delete(&ing)).To(Succeed())
+
+			_, err := store.GetIngressV1("example", "test-namespace")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(store.Update(&ing)).To(BeTrue(), "an object added again after being deleted is a change")
+		})
+	})
+
+	var _ = Describe("WatchNamespaces", func() {
+		BeforeEach(func() {
+			logger := logr.Discard()
+			store = New(NewCacheStores(logger), defaultControllerName, logger, WithWatchNamespaces("team-a", "team-b"))
+			ic := NewTestIngressClass(ngrokIngressClass, true, true)
+			Expect(store.Add(&ic)).To(BeNil())
+		})
+
+		It("only returns ngrok ingresses in watched namespaces", func() {
+			watched := NewTestIngressV1WithClass("watched", "team-a", ngrokIngressClass)
+			Expect(store.Add(&watched)).To(BeNil())
+			unwatched := NewTestIngressV1WithClass("unwatched", "team-c", ngrokIngressClass)
+			err := store.Add(&unwatched)
+			Expect(errors.IsErrNamespaceNotWatched(err)).To(BeTrue())
+			Expect(err).To(MatchError("namespace team-c is not watched"))
+
+			ings := store.ListNgrokIngressesV1()
+			Expect(ings).To(HaveLen(1))
+			Expect(ings[0].Name).To(Equal("watched"))
+
+			_, err = store.GetNgrokIngressV1("unwatched", "team-c")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+		})
+
+		It("rejects updates to objects in namespaces that aren't watched", func() {
+			svc := NewTestServiceV1("example", "team-c")
+			changed, err := store.Update(&svc)
+			Expect(errors.IsErrNamespaceNotWatched(err)).To(BeTrue())
+			Expect(changed).To(BeFalse())
+			Expect(store.ListServicesV1()).To(BeEmpty())
+		})
+
+		It("skips objects cached from namespaces that aren't watched", func() {
+			logger := logr.Discard()
+			cs := NewCacheStores(logger)
+			unscoped := New(cs, defaultControllerName, logger)
+			for _, ns := range []string{"team-a", "team-b", "team-c"} {
+				svc := NewTestServiceV1("example", ns)
+				Expect(unscoped.Add(&svc)).To(BeNil())
+			}
+
+			scoped := New(cs, defaultControllerName, logger, WithWatchNamespaces("team-a", "team-b"))
+			Expect(scoped.ListServicesV1()).To(HaveLen(2))
+			Expect(unscoped.ListServicesV1()).To(HaveLen(3))
+			_, err := scoped.GetServiceV1("example", "team-c")
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+		})
+
+		It("watches all namespaces without any namespaces", func() {
+			logger := logr.Discard()
+			store = New(NewCacheStores(logger), defaultControllerName, logger, WithWatchNamespaces())
+			ic := NewTestIngressClass(ngrokIngressClass, true, true)
+			Expect(store.Add(&ic)).To(BeNil())
+			for _, ns := range []string{"team-a", "team-c"} {
+				ing := NewTestIngressV1WithClass("example", ns, ngrokIngressClass)
+				Expect(store.Add(&ing)).To(BeNil())
+			}
+			Expect(store.ListNgrokIngressesV1()).To(HaveLen(2))
+		})
+	})
+
 	var _ = Describe("ListNgrokModulesV1", func() {
 		Context("when there are NgrokModuleSets", func() {
 			BeforeEach(func() {
@@ -208,6 +1171,51 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	var _ = Describe("GetDefaultNgrokModuleSet", func() {
+		BeforeEach(func() {
+			m := NewTestNgrokModuleSet("not-default", "test", false)
+			Expect(store.Add(&m)).To(BeNil())
+		})
+		It("returns a not found error when no module set is the default", func() {
+			unmarked := NewTestNgrokModuleSet("unmarked", "test", true)
+			unmarked.Annotations = map[string]string{AnnotationDefaultModuleSet: "false"}
+			Expect(store.Add(&unmarked)).To(BeNil())
+
+			modset, err := store.GetDefaultNgrokModuleSet()
+			Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+			Expect(modset).To(BeNil())
+		})
+		It("returns the module set marked as the default", func() {
+			m := NewTestDefaultNgrokModuleSet("cluster-default", "ngrok-system")
+			Expect(store.Add(&m)).To(BeNil())
+
+			modset, err := store.GetDefaultNgrokModuleSet()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(modset.Name).To(Equal("cluster-default"))
+			Expect(modset.Namespace).To(Equal("ngrok-system"))
+			Expect(modset.Modules.Compression.Enabled).To(BeTrue())
+		})
+		It("returns an invalid configuration error when more than one module set is the default", func() {
+			for _, ns := range []string{"ngrok-system", "test"} {
+				m := NewTestDefaultNgrokModuleSet("cluster-default", ns)
+				Expect(store.Add(&m)).To(BeNil())
+			}
+
+			modset, err := store.GetDefaultNgrokModuleSet()
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrInvalidConfiguration{}))
+			Expect(err).To(MatchError(ContainSubstring("found ngrok-system/cluster-default, test/cluster-default")))
+			Expect(modset).To(BeNil())
+		})
+		It("returns an invalid configuration error when the default's modules can't be used", func() {
+			m := NewTestDefaultNgrokModuleSet("cluster-default", "ngrok-system")
+			m.Modules.Compression.Algorithms = []string{"lz4"}
+			Expect(store.Add(&m)).To(BeNil())
+
+			_, err := store.GetDefaultNgrokModuleSet()
+			Expect(err).To(MatchError(ingressv1alpha1.ErrInvalidCompression))
+		})
+	})
+
 	var _ = Describe("GetNgrokModuleSetV1", func() {
 		Context("when the NgrokModuleSet exists", func() {
 			BeforeEach(func() {
@@ -220,6 +1228,188 @@ var _ = Describe("Store", func() {
 				Expect(modset.Modules.Compression.Enabled).To(Equal(true))
 			})
 		})
+		Context("when the NgrokModuleSet has every module", func() {
+			BeforeEach(func() {
+				m := NewTestNgrokModuleSet("modules", "test", false)
+				m.Modules = NewTestNgrokModuleSetModules()
+				clientCA := NewTestCASecretV1("client-ca", "test", "client-ca-pem")
+				oidcClient := NewTestSecretV1("oidc-client", "test")
+				oidcClient.Data["client-secret"] = []byte("s3cr3t")
+				Expect(store.AddAll(&m, &clientCA, &oidcClient)).To(BeNil())
+			})
+			It("returns the NgrokModuleSet with the modules", func() {
+				modset, err := store.GetNgrokModuleSetV1("modules", "test")
+				Expect(err).ToNot(HaveOccurred())
+				modules := modset.Modules
+
+				Expect(modules.RateLimit.RequestsPerSecond).To(Equal(int32(10)))
+				Expect(modules.RateLimit.Burst).To(Equal(int32(20)))
+
+				google := modules.OAuth.Google
+				Expect(google.ClientSecretKeyRef()).To(BeNil())
+				Expect(google.Scopes).To(Equal([]string{"email", "profile"}))
+				Expect(google.EmailAddresses).To(Equal([]string{"alice@example.com"}))
+				Expect(google.EmailDomains).To(Equal([]string{"ngrok.com"}))
+
+				Expect(modules.Headers.Request.Add).To(Equal(map[string]string{
+					"X-Forwarded-Proto": "https",
+					"X-Forwarded-Host":  "example.com",
+				}))
+				Expect(modules.Headers.Request.Remove).To(Equal([]string{"Authorization", "Cookie"}))
+				Expect(modules.Headers.Response.Add).To(Equal(map[string]string{"Strict-Transport-Security": "max-age=31536000"}))
+				Expect(modules.Headers.Response.Remove).To(Equal([]string{"Server", "X-Powered-By"}))
+
+				cb := modules.CircuitBreaker
+				Expect(cb.ErrorThresholdPercentage.AsApproximateFloat64()).To(Equal(0.25))
+				Expect(cb.VolumeThreshold).To(Equal(uint32(20)))
+				Expect(cb.NumBuckets).To(Equal(uint32(10)))
+				Expect(cb.TrippedDuration.Duration).To(Equal(30 * time.Second))
+				Expect(cb.RollingWindow.Duration).To(Equal(time.Minute))
+
+				Expect(modules.Compression.Enabled).To(BeTrue())
+				Expect(modules.Compression.Algorithms).To(Equal([]string{"br", "gzip"}))
+
+				Expect(modules.OIDC.Issuer).To(Equal("https://accounts.example.com"))
+				Expect(modules.OIDC.Scopes).To(Equal([]string{"openid", "email"}))
+				Expect(modules.OIDC.CookiePrefix).To(Equal("ngrok-oidc."))
+
+				Expect(modules.SAML.AuthorizedGroups).To(Equal([]string{"engineering"}))
+				Expect(modules.SAML.CookiePrefix).To(Equal("ngrok-saml."))
+			})
+			It("resolves the mutual TLS CA certificates and the OIDC client secret", func() {
+				modset, err := store.GetNgrokModuleSetV1("modules", "test")
+				Expect(err).ToNot(HaveOccurred())
+
+				certs, err := store.GetMutualTLSCACertificates(modset)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(certs).To(Equal([]string{"client-ca-pem"}))
+
+				clientSecret, err := store.GetOIDCClientSecret(modset)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clientSecret).To(Equal("s3cr3t"))
+			})
+		})
+		DescribeTable("returns an invalid configuration error for modules that can't be used",
+			func(configure func(*ingressv1alpha1.NgrokModuleSetModules), expected error, message string) {
+				m := NewTestNgrokModuleSet("modules", "test", false)
+				m.Modules = NewTestNgrokModuleSetModules()
+				configure(&m.Modules)
+				Expect(store.Add(&m)).To(BeNil())
+
+				modset, err := store.GetNgrokModuleSetV1("modules", "test")
+				Expect(err).To(BeAssignableToTypeOf(errors.ErrInvalidConfiguration{}))
+				Expect(err).To(MatchError(expected))
+				Expect(err).To(MatchError(ContainSubstring("NgrokModuleSet modules: ")))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+				Expect(modset).To(BeNil())
+			},
+			Entry("OAuth with a custom app missing its client secret", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.OAuth.Google.ClientID = ptr.To("client-id")
+			}, ingressv1alpha1.ErrInvalidOAuth, ""),
+			Entry("a circuit breaker error threshold above 1.0", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.CircuitBreaker.ErrorThresholdPercentage = resource.MustParse("50")
+			}, ingressv1alpha1.ErrInvalidCircuitBreaker, "errorThresholdPercentage 50 must be between 0 and 1.0"),
+			Entry("an unknown compression algorithm", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.Compression.Algorithms = []string{"gzip", "lz4"}
+			}, ingressv1alpha1.ErrInvalidCompression, `unknown algorithm "lz4"`),
+			Entry("mutual TLS without any certificate authority", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.MutualTLS.CertificateAuthoritySecrets = nil
+			}, ingressv1alpha1.ErrInvalidMutualTLS, ""),
+			Entry("an http OIDC issuer", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.OIDC.Issuer = "http://accounts.example.com"
+			}, ingressv1alpha1.ErrInvalidOIDC, `issuer "http://accounts.example.com" must be an https URL`),
+			Entry("SAML IdP metadata that isn't XML", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.SAML.IdPMetadata = "https://example.okta.com/app/abc/sso/saml/metadata"
+			}, ingressv1alpha1.ErrInvalidSAML, ""),
+			Entry("an empty header name", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.Headers.Request.Add[""] = "value"
+			}, ingressv1alpha1.ErrInvalidHeaders, ""),
+			Entry("a malformed header name", func(modules *ingressv1alpha1.NgrokModuleSetModules) {
+				modules.Headers.Response.Remove = append(modules.Headers.Response.Remove, "X Powered By")
+			}, ingressv1alpha1.ErrInvalidHeaders, `"X Powered By" is not a valid header name`),
+		)
+		Context("when the NgrokModuleSet uses OAuth with a custom app", func() {
+			BeforeEach(func() {
+				m := NewTestNgrokModuleSet("oauth", "test", false)
+				m.Modules.OAuth = NewTestNgrokModuleSetModules().OAuth
+				m.Modules.OAuth.Google.ClientID = ptr.To("client-id")
+				m.Modules.OAuth.Google.ClientSecret = &ingressv1alpha1.SecretKeyRef{Name: "oauth-secret", Key: "key"}
+				Expect(store.Add(&m)).To(BeNil())
+				secret := NewTestSecretV1("oauth-secret", "test")
+				Expect(store.Add(&secret)).To(BeNil())
+			})
+			It("resolves the client secret reference to the secret in the store", func() {
+				modset, err := store.GetNgrokModuleSetV1("oauth", "test")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(modset.Modules.OAuth.Google.ClientID).To(Equal(ptr.To("client-id")))
+				ref := modset.Modules.OAuth.Google.ClientSecretKeyRef()
+				Expect(ref).ToNot(BeNil())
+
+				secret, err := store.GetSecretV1(ref.Name, modset.Namespace)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(secret.Data[ref.Key])).To(Equal("value"))
+			})
+		})
+		Context("when the NgrokModuleSet has mutual TLS", func() {
+			var m ingressv1alpha1.NgrokModuleSet
+			BeforeEach(func() {
+				m = NewTestNgrokModuleSet("mtls", "test", false)
+				m.Modules.MutualTLS = NewTestNgrokModuleSetModules().MutualTLS
+			})
+			It("resolves the CA certificates of all its Secrets", func() {
+				m.Modules.MutualTLS.CertificateAuthoritySecrets = []string{"client-ca", "partner-ca"}
+				clientCA := NewTestCASecretV1("client-ca", "test", "client-ca-pem")
+				partnerCA := NewTestCASecretV1("partner-ca", "test", "partner-ca-pem")
+				Expect(store.Add(&m)).To(BeNil())
+				Expect(store.Add(&clientCA)).To(BeNil())
+				Expect(store.Add(&partnerCA)).To(BeNil())
+
+				modset, err := store.GetNgrokModuleSetV1("mtls", "test")
+				Expect(err).ToNot(HaveOccurred())
+
+				certs, err := store.GetMutualTLSCACertificates(modset)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(certs).To(Equal([]string{"client-ca-pem", "partner-ca-pem"}))
+			})
+			It("returns an invalid configuration error when a Secret has no ca.crt", func() {
+				secret := NewTestSecretV1("client-ca", "test")
+				Expect(store.Add(&m)).To(BeNil())
+				Expect(store.Add(&secret)).To(BeNil())
+
+				modset, err := store.GetNgrokModuleSetV1("mtls", "test")
+				Expect(err).To(BeAssignableToTypeOf(errors.ErrInvalidConfiguration{}))
+				Expect(err).To(MatchError(ingressv1alpha1.ErrInvalidMutualTLS))
+				Expect(err).To(MatchError(ContainSubstring("Secret client-ca has no ca.crt key")))
+				Expect(modset).To(BeNil())
+			})
+			It("returns a not found error when a Secret doesn't exist", func() {
+				Expect(store.Add(&m)).To(BeNil())
+
+				_, err := store.GetNgrokModuleSetV1("mtls", "test")
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("client-ca")))
+			})
+		})
+		Context("when the NgrokModuleSet has OIDC", func() {
+			BeforeEach(func() {
+				m := NewTestNgrokModuleSet("oidc", "test", false)
+				m.Modules.OIDC = NewTestNgrokModuleSetModules().OIDC
+				Expect(store.Add(&m)).To(BeNil())
+			})
+			It("returns a not found error when the client secret's Secret doesn't exist", func() {
+				_, err := store.GetNgrokModuleSetV1("oidc", "test")
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("oidc-client")))
+			})
+			It("returns an invalid configuration error when the Secret doesn't have the key", func() {
+				secret := NewTestSecretV1("oidc-client", "test")
+				Expect(store.Add(&secret)).To(BeNil())
+
+				_, err := store.GetNgrokModuleSetV1("oidc", "test")
+				Expect(err).To(MatchError(ingressv1alpha1.ErrInvalidOIDC))
+				Expect(err).To(MatchError(ContainSubstring("Secret oidc-client has no client-secret key")))
+			})
+		})
 		Context("when the NgrokModuleSet does not exist", func() {
 			It("returns an error", func() {
 				modset, err := store.GetNgrokModuleSetV1("does-not-exist", "does-not-exist")
@@ -230,6 +1420,114 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	var _ = Describe("GetTCPEdgeV1", func() {
+		Context("when the TCPEdge exists", func() {
+			BeforeEach(func() {
+				edge := NewTestTCPEdge("postgres", "test", "postgres")
+				Expect(store.Add(&edge)).To(BeNil())
+			})
+			It("returns the TCPEdge", func() {
+				edge, err := store.GetTCPEdgeV1("postgres", "test")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(edge.Spec.Backend.Labels).To(HaveKeyWithValue("k8s.ngrok.com/service", "postgres"))
+				Expect(edge.Spec.IPRestriction.IPPolicies).To(Equal([]string{"office"}))
+			})
+		})
+		Context("when the TCPEdge is in another namespace", func() {
+			BeforeEach(func() {
+				edge := NewTestTCPEdge("postgres", "other", "postgres")
+				Expect(store.Add(&edge)).To(BeNil())
+			})
+			It("returns a not found error", func() {
+				edge, err := store.GetTCPEdgeV1("postgres", "test")
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(edge).To(BeNil())
+			})
+		})
+	})
+
+	var _ = Describe("GetDomainV1", func() {
+		Context("when the Domain exists", func() {
+			BeforeEach(func() {
+				domain := NewDomainV1("example.com", "test")
+				Expect(store.Add(&domain)).To(BeNil())
+			})
+			It("returns the Domain", func() {
+				domain, err := store.GetDomainV1("example.com", "test")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(domain.Spec.Domain).To(Equal("example.com"))
+			})
+		})
+		Context("when the Domain is in another namespace", func() {
+			BeforeEach(func() {
+				domain := NewDomainV1("example.com", "other")
+				Expect(store.Add(&domain)).To(BeNil())
+			})
+			It("returns a not found error", func() {
+				domain, err := store.GetDomainV1("example.com", "test")
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(domain).To(BeNil())
+			})
+		})
+	})
+
+	var _ = Describe("ListDomainsV1", func() {
+		Context("when there are no Domains", func() {
+			It("returns an empty list", func() {
+				Expect(store.ListDomainsV1()).To(BeEmpty())
+			})
+		})
+		Context("when there are Domains in multiple namespaces", func() {
+			BeforeEach(func() {
+				for _, domain := range []ingressv1alpha1.Domain{
+					NewDomainV1("example.com", "test"),
+					NewDomainV1("api.example.com", "test"),
+					NewDomainV1("example.org", "other"),
+				} {
+					Expect(store.Add(&domain)).To(BeNil())
+				}
+			})
+			It("returns all of them", func() {
+				var domains []string
+				for _, domain := range store.ListDomainsV1() {
+					domains = append(domains, domain.Spec.Domain)
+				}
+				Expect(domains).To(ConsistOf("example.com", "api.example.com", "example.org"))
+			})
+		})
+	})
+
+	var _ = Describe("ListTCPEdgesV1", func() {
+		Context("when there are no TCPEdges", func() {
+			It("returns an empty list", func() {
+				Expect(store.ListTCPEdgesV1()).To(BeEmpty())
+			})
+		})
+		Context("when there are TCPEdges in multiple namespaces", func() {
+			BeforeEach(func() {
+				for _, edge := range []ingressv1alpha1.TCPEdge{
+					NewTestTCPEdge("ssh", "test", "ssh"),
+					NewTestTCPEdge("postgres", "test", "postgres"),
+					NewTestTCPEdge("redis", "other", "redis"),
+				} {
+					Expect(store.Add(&edge)).To(BeNil())
+				}
+			})
+			It("returns them all sorted by namespace and name", func() {
+				var keys []string
+				for _, edge := range store.ListTCPEdgesV1() {
+					keys = append(keys, edge.Namespace+"/"+edge.Name)
+				}
+				Expect(keys).To(Equal([]string{"other/redis", "test/postgres", "test/ssh"}))
+			})
+			It("stops returning deleted TCPEdges", func() {
+				edge := NewTestTCPEdge("ssh", "test", "ssh")
+				Expect(store.Delete(&edge)).To(BeNil())
+				Expect(store.ListTCPEdgesV1()).To(HaveLen(2))
+			})
+		})
+	})
+
 	var _ = Describe("GetNgrokTrafficPolicyV1", func() {
 		Context("when the NgrokTrafficPolicy exists", func() {
 			BeforeEach(func() {
@@ -251,4 +1549,57 @@ var _ = Describe("Store", func() {
 			})
 		})
 	})
+
+	var _ = Describe("GetIPPolicyV1", func() {
+		Context("when the IPPolicy exists", func() {
+			BeforeEach(func() {
+				policy := NewTestIPPolicy("office", "test", ingressv1alpha1.IPPolicyRule{CIDR: "10.0.0.0/8", Action: "allow"})
+				Expect(store.Add(&policy)).To(BeNil())
+			})
+			It("returns the IPPolicy", func() {
+				policy, err := store.GetIPPolicyV1("office", "test")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policy.Spec.Rules).To(HaveLen(1))
+				Expect(policy.Spec.Rules[0].CIDR).To(Equal("10.0.0.0/8"))
+				Expect(policy.Spec.Rules[0].Action).To(Equal("allow"))
+			})
+		})
+		Context("when the IPPolicy is in another namespace", func() {
+			BeforeEach(func() {
+				policy := NewTestIPPolicy("office", "other")
+				Expect(store.Add(&policy)).To(BeNil())
+			})
+			It("returns a not found error", func() {
+				policy, err := store.GetIPPolicyV1("office", "test")
+				Expect(errors.IsErrorNotFound(err)).To(BeTrue())
+				Expect(policy).To(BeNil())
+			})
+		})
+	})
+
+	var _ = Describe("ListIPPoliciesV1", func() {
+		Context("when there are no IPPolicies", func() {
+			It("returns an empty list", func() {
+				Expect(store.ListIPPoliciesV1()).To(BeEmpty())
+			})
+		})
+		Context("when there are IPPolicies", func() {
+			BeforeEach(func() {
+				for _, policy := range []ingressv1alpha1.IPPolicy{
+					NewTestIPPolicy("vpn", "test"),
+					NewTestIPPolicy("office", "test"),
+					NewTestIPPolicy("office", "other"),
+				} {
+					Expect(store.Add(&policy)).To(BeNil())
+				}
+			})
+			It("returns them sorted by namespace and name", func() {
+				policies := store.ListIPPoliciesV1()
+				Expect(policies).To(HaveLen(3))
+				Expect(policies[0].Namespace + "/" + policies[0].Name).To(Equal("other/office"))
+				Expect(policies[1].Namespace + "/" + policies[1].Name).To(Equal("test/office"))
+				Expect(policies[2].Namespace + "/" + policies[2].Name).To(Equal("test/vpn"))
+			})
+		})
+	})
 })
@@ -2,13 +2,19 @@ package store
 
 import (
 	"encoding/json"
+	"time"
 
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 func NewTestIngressClass(name string, isDefault bool, isNgrok bool) netv1.IngressClass {
@@ -94,6 +100,43 @@ func NewTestServiceV1(name string, namespace string) corev1.Service {
 	}
 }
 
+func NewTestEndpointSlice(name string, namespace string, serviceName string) discoveryv1.EndpointSlice {
+	return discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: serviceName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.0.0.1"},
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{
+				Name:     ptr.To("http"),
+				Protocol: ptr.To(corev1.ProtocolTCP),
+				Port:     ptr.To(int32(80)),
+			},
+		},
+	}
+}
+
+func NewTestSecretV1(name string, namespace string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"key": []byte("value"),
+		},
+	}
+}
+
 func NewDomainV1(name string, namespace string) ingressv1alpha1.Domain {
 	return ingressv1alpha1.Domain{
 		ObjectMeta: metav1.ObjectMeta{
@@ -106,6 +149,15 @@ func NewDomainV1(name string, namespace string) ingressv1alpha1.Domain {
 	}
 }
 
+// NewReservedDomainV1 returns the Domain the driver creates for the host in the namespace, reserved in ngrok, so
+// the driver creates the host's edge
+func NewReservedDomainV1(host string, namespace string) ingressv1alpha1.Domain {
+	domain := NewDomainV1(domainResourceName(host), namespace)
+	domain.Spec.Domain = host
+	domain.Status.ID = "rd_" + domainResourceName(host)
+	return domain
+}
+
 func NewHTTPSEdge(name string, namespace string, domain string) ingressv1alpha1.HTTPSEdge {
 	return ingressv1alpha1.HTTPSEdge{
 		ObjectMeta: metav1.ObjectMeta{
@@ -115,6 +167,18 @@ func NewHTTPSEdge(name string, namespace string, domain string) ingressv1alpha1.
 	}
 }
 
+func NewTestIPPolicy(name string, namespace string, rules ...ingressv1alpha1.IPPolicyRule) ingressv1alpha1.IPPolicy {
+	return ingressv1alpha1.IPPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: ingressv1alpha1.IPPolicySpec{
+			Rules: rules,
+		},
+	}
+}
+
 func NewTestNgrokModuleSet(name string, namespace string, compressionEnabled bool) ingressv1alpha1.NgrokModuleSet {
 	return ingressv1alpha1.NgrokModuleSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -129,6 +193,85 @@ func NewTestNgrokModuleSet(name string, namespace string, compressionEnabled boo
 	}
 }
 
+// NewTestDefaultNgrokModuleSet returns a module set with compression enabled that's marked as the cluster wide
+// default
+func NewTestDefaultNgrokModuleSet(name string, namespace string) ingressv1alpha1.NgrokModuleSet {
+	ms := NewTestNgrokModuleSet(name, namespace, true)
+	ms.Annotations = map[string]string{AnnotationDefaultModuleSet: "true"}
+	return ms
+}
+
+// NewTestNgrokModuleSetModules returns a valid configuration of every module. Tests copy the modules they need
+// onto a module set and change fields from there to cover other cases.
+func NewTestNgrokModuleSetModules() ingressv1alpha1.NgrokModuleSetModules {
+	return ingressv1alpha1.NgrokModuleSetModules{
+		RateLimit: &ingressv1alpha1.EndpointRateLimit{
+			RequestsPerSecond: 10,
+			Burst:             20,
+		},
+		// ngrok's managed Google OAuth app, so no client secret needs to be looked up
+		OAuth: &ingressv1alpha1.EndpointOAuth{
+			Google: &ingressv1alpha1.EndpointOAuthGoogle{
+				OAuthProviderCommon: ingressv1alpha1.OAuthProviderCommon{
+					Scopes:         []string{"email", "profile"},
+					EmailAddresses: []string{"alice@example.com"},
+					EmailDomains:   []string{"ngrok.com"},
+				},
+			},
+		},
+		Headers: &ingressv1alpha1.EndpointHeaders{
+			Request: &ingressv1alpha1.EndpointRequestHeaders{
+				Add: map[string]string{
+					"X-Forwarded-Proto": "https",
+					"X-Forwarded-Host":  "example.com",
+				},
+				Remove: []string{"Authorization", "Cookie"},
+			},
+			Response: &ingressv1alpha1.EndpointResponseHeaders{
+				Add:    map[string]string{"Strict-Transport-Security": "max-age=31536000"},
+				Remove: []string{"Server", "X-Powered-By"},
+			},
+		},
+		CircuitBreaker: &ingressv1alpha1.EndpointCircuitBreaker{
+			TrippedDuration:          metav1.Duration{Duration: 30 * time.Second},
+			RollingWindow:            metav1.Duration{Duration: time.Minute},
+			NumBuckets:               10,
+			VolumeThreshold:          20,
+			ErrorThresholdPercentage: resource.MustParse("0.25"),
+		},
+		Compression: &ingressv1alpha1.EndpointCompression{
+			Enabled:    true,
+			Algorithms: []string{"br", "gzip"},
+		},
+		// The CA certificates are read from the Secret, see NewTestCASecretV1
+		MutualTLS: &ingressv1alpha1.EndpointMutualTLS{
+			CertificateAuthoritySecrets: []string{"client-ca"},
+		},
+		OIDC: &ingressv1alpha1.EndpointOIDC{
+			Issuer:   "https://accounts.example.com",
+			ClientID: "client-id",
+			ClientSecret: ingressv1alpha1.SecretKeyRef{
+				Name: "oidc-client",
+				Key:  "client-secret",
+			},
+			Scopes:       []string{"openid", "email"},
+			CookiePrefix: "ngrok-oidc.",
+		},
+		SAML: &ingressv1alpha1.EndpointSAML{
+			IdPMetadata:      `<EntityDescriptor entityID="http://www.okta.com/abc"></EntityDescriptor>`,
+			AuthorizedGroups: []string{"engineering"},
+			CookiePrefix:     "ngrok-saml.",
+		},
+	}
+}
+
+// NewTestCASecretV1 returns a Secret with the PEM encoded CA certificate under the key mutual TLS reads it from
+func NewTestCASecretV1(name string, namespace string, caCert string) corev1.Secret {
+	secret := NewTestSecretV1(name, namespace)
+	secret.Data[ingressv1alpha1.MutualTLSCACertKey] = []byte(caCert)
+	return secret
+}
+
 func NewTestNgrokTrafficPolicy(name string, namespace string, policyStr string) ngrokv1alpha1.NgrokTrafficPolicy {
 	return ngrokv1alpha1.NgrokTrafficPolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -140,3 +283,127 @@ func NewTestNgrokTrafficPolicy(name string, namespace string, policyStr string)
 		},
 	}
 }
+
+func NewTestNgrokIngressClassParams(name string) ingressv1alpha1.NgrokIngressClassParams {
+	return ingressv1alpha1.NgrokIngressClassParams{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+// NewTestIngressClassWithParameters returns an ngrok IngressClass whose spec.parameters references the
+// NgrokIngressClassParams with the given name
+func NewTestIngressClassWithParameters(name string, paramsName string) netv1.IngressClass {
+	ic := NewTestIngressClass(name, false, true)
+	ic.Spec.Parameters = &netv1.IngressClassParametersReference{
+		APIGroup: ptr.To(ingressv1alpha1.GroupVersion.Group),
+		Kind:     "NgrokIngressClassParams",
+		Name:     paramsName,
+	}
+	return ic
+}
+
+func NewTestTCPEdge(name string, namespace string, serviceName string) ingressv1alpha1.TCPEdge {
+	return ingressv1alpha1.TCPEdge{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: ingressv1alpha1.TCPEdgeSpec{
+			Backend: ingressv1alpha1.TunnelGroupBackend{
+				Labels: map[string]string{
+					"k8s.ngrok.com/namespace": namespace,
+					"k8s.ngrok.com/service":   serviceName,
+					"k8s.ngrok.com/port":      "5432",
+				},
+			},
+			IPRestriction: &ingressv1alpha1.EndpointIPPolicy{
+				IPPolicies: []string{"office"},
+			},
+		},
+	}
+}
+
+func NewTestGatewayClass(name string, controllerName gatewayv1.GatewayController) gatewayv1.GatewayClass {
+	return gatewayv1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: gatewayv1.GatewayClassSpec{
+			ControllerName: controllerName,
+		},
+	}
+}
+
+func NewTestGateway(name string, namespace string, className string) gatewayv1.Gateway {
+	return gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: gatewayv1.ObjectName(className),
+			Listeners: []gatewayv1.Listener{
+				{
+					Name:     "http",
+					Hostname: ptr.To(gatewayv1.Hostname("example.com")),
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
+				},
+			},
+		},
+	}
+}
+
+// NewTestReferenceGrant returns a ReferenceGrant that allows HTTPRoutes in fromNamespace to reference every
+// service in the namespace
+func NewTestReferenceGrant(name string, namespace string, fromNamespace string) gatewayv1beta1.ReferenceGrant {
+	return gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{
+				{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: gatewayv1beta1.Namespace(fromNamespace)},
+			},
+			To: []gatewayv1beta1.ReferenceGrantTo{
+				{Group: "", Kind: "Service"},
+			},
+		},
+	}
+}
+
+// NewTestHTTPRoute returns an HTTPRoute attached to the gateway in its namespace that routes all requests to
+// the "example" service
+func NewTestHTTPRoute(name string, namespace string, gatewayName string) gatewayv1.HTTPRoute {
+	return gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{
+					{Name: gatewayv1.ObjectName(gatewayName)},
+				},
+			},
+			Hostnames: []gatewayv1.Hostname{"example.com"},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					BackendRefs: []gatewayv1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name: "example",
+									Port: ptr.To(gatewayv1.PortNumber(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
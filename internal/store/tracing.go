@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+)
+
+const (
+	TracingFormatW3C = "w3c"
+	TracingFormatB3  = "b3"
+)
+
+type tracingHeader struct {
+	name string
+	// valueFormat formats the header's value from the trace ID and the span ID
+	valueFormat string
+}
+
+// tracingHeaders holds the header each tracing format uses along with how its value is generated
+var tracingHeaders = map[string]tracingHeader{
+	// https://www.w3.org/TR/trace-context/#traceparent-header
+	TracingFormatW3C: {
+		name:        "traceparent",
+		valueFormat: "00-%s-%s-01",
+	},
+	// https://github.com/openzipkin/b3-propagation#single-header
+	TracingFormatB3: {
+		name:        "b3",
+		valueFormat: "%s-%s-1",
+	},
+}
+
+// tracingPolicyRules builds the inbound policy rules that generate the configured trace context headers
+// for requests that don't already have them, from the module's trace and span ID variables. Requests that
+// do are forwarded upstream with their headers as is, and so is every request when the variables aren't set.
+func tracingPolicyRules(tracing *ingressv1alpha1.EndpointTracing) ([]ingressv1alpha1.EndpointRule, error) {
+	if tracing == nil {
+		return nil, nil
+	}
+	if (tracing.TraceIDVariable == "") != (tracing.SpanIDVariable == "") {
+		return nil, fmt.Errorf("tracing needs both traceIDVariable and spanIDVariable to generate headers")
+	}
+	if tracing.TraceIDVariable == "" {
+		return nil, nil
+	}
+
+	formats := tracing.Formats
+	if len(formats) == 0 {
+		formats = []string{TracingFormatW3C}
+	}
+
+	rules := []ingressv1alpha1.EndpointRule{}
+	seen := map[string]bool{}
+	for _, format := range formats {
+		if seen[format] {
+			continue
+		}
+		seen[format] = true
+
+		header, ok := tracingHeaders[format]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tracing format %q", format)
+		}
+
+		config, err := json.Marshal(AddHeadersConfig{
+			Headers: map[string]string{header.name: fmt.Sprintf(header.valueFormat, tracing.TraceIDVariable, tracing.SpanIDVariable)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, ingressv1alpha1.EndpointRule{
+			Name:        fmt.Sprintf("Generate %s header", header.name),
+			Expressions: []string{fmt.Sprintf("!('%s' in req.headers)", header.name)},
+			Actions: []ingressv1alpha1.EndpointAction{
+				{
+					Type:   "add-headers",
+					Config: config,
+				},
+			},
+		})
+	}
+
+	return rules, nil
+}
+
+// withTracingPolicy adds the tracing rules to the front of the policy's inbound rules so the trace context
+// headers are in place before any of the other rules run
+func withTracingPolicy(policyJSON json.RawMessage, tracing *ingressv1alpha1.EndpointTracing) (json.RawMessage, error) {
+	rules, err := tracingPolicyRules(tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return policyJSON, nil
+	}
+
+	return withPolicyRules(policyJSON, rules, nil)
+}
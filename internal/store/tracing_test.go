@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
+)
+
+var _ = Describe("Tracing", func() {
+	testTracing := func(formats ...string) *ingressv1alpha1.EndpointTracing {
+		return &ingressv1alpha1.EndpointTracing{
+			Formats:         formats,
+			TraceIDVariable: "${test.trace_id}",
+			SpanIDVariable:  "${test.span_id}",
+		}
+	}
+
+	decodePolicy := func(policyJSON json.RawMessage) ingressv1alpha1.EndpointPolicy {
+		policy := ingressv1alpha1.EndpointPolicy{}
+		Expect(json.Unmarshal(policyJSON, &policy)).To(Succeed())
+		return policy
+	}
+
+	decodeHeaders := func(rule ingressv1alpha1.EndpointRule) map[string]string {
+		Expect(rule.Actions).To(HaveLen(1))
+		Expect(rule.Actions[0].Type).To(Equal("add-headers"))
+		config := AddHeadersConfig{}
+		Expect(json.Unmarshal(rule.Actions[0].Config, &config)).To(Succeed())
+		return config.Headers
+	}
+
+	Describe("withTracingPolicy", func() {
+		It("Should generate a traceparent header by default", func() {
+			policyJSON, err := withTracingPolicy(nil, testTracing())
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(1))
+			headers := decodeHeaders(policy.Inbound[0])
+			Expect(headers).To(HaveKeyWithValue("traceparent", "00-${test.trace_id}-${test.span_id}-01"))
+		})
+
+		It("Should forward an existing traceparent header instead of replacing it", func() {
+			policyJSON, err := withTracingPolicy(nil, testTracing())
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(1))
+			Expect(policy.Inbound[0].Expressions).To(Equal([]string{"!('traceparent' in req.headers)"}))
+			for _, rule := range policy.Inbound {
+				for _, action := range rule.Actions {
+					Expect(action.Type).ToNot(Equal("remove-headers"))
+				}
+			}
+		})
+
+		It("Should generate the headers for each of the selected formats", func() {
+			policyJSON, err := withTracingPolicy(nil, testTracing(TracingFormatW3C, TracingFormatB3, TracingFormatW3C))
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(decodeHeaders(policy.Inbound[0])).To(HaveKey("traceparent"))
+			Expect(decodeHeaders(policy.Inbound[1])).To(HaveKeyWithValue("b3", "${test.trace_id}-${test.span_id}-1"))
+			Expect(policy.Inbound[1].Expressions).To(Equal([]string{"!('b3' in req.headers)"}))
+		})
+
+		It("Should run before the existing policy rules", func() {
+			existing, err := json.Marshal(ingressv1alpha1.EndpointPolicy{
+				Inbound: []ingressv1alpha1.EndpointRule{
+					{Name: "existing", Actions: []ingressv1alpha1.EndpointAction{{Type: "deny"}}},
+				},
+				Outbound: []ingressv1alpha1.EndpointRule{
+					{Name: "existing-outbound", Actions: []ingressv1alpha1.EndpointAction{{Type: "add-headers"}}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			policyJSON, err := withTracingPolicy(existing, testTracing())
+			Expect(err).ToNot(HaveOccurred())
+
+			policy := decodePolicy(policyJSON)
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(policy.Inbound[0].Name).To(Equal("Generate traceparent header"))
+			Expect(policy.Inbound[1].Name).To(Equal("existing"))
+			Expect(policy.Outbound).To(HaveLen(1))
+		})
+
+		It("Should error on an unsupported format", func() {
+			_, err := withTracingPolicy(nil, testTracing("jaeger"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should not generate headers without the trace and span ID variables", func() {
+			policyJSON, err := withTracingPolicy(json.RawMessage(`{"inbound":[]}`), &ingressv1alpha1.EndpointTracing{Formats: []string{TracingFormatW3C}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(policyJSON)).To(Equal(`{"inbound":[]}`))
+
+			_, err = withTracingPolicy(nil, &ingressv1alpha1.EndpointTracing{TraceIDVariable: "${test.trace_id}"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should leave the policy alone without tracing", func() {
+			policyJSON, err := withTracingPolicy(json.RawMessage(`{"inbound":[]}`), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(policyJSON)).To(Equal(`{"inbound":[]}`))
+		})
+	})
+
+	Describe("Sync", func() {
+		It("Should add the tracing rules to the edge routes of ingresses using the module", func() {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+			utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+			driver := NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, false)
+			driver.syncAllowConcurrent = true
+
+			ms := NewTestNgrokModuleSet("tracing", "test-namespace", false)
+			ms.Modules.Tracing = testTracing(TracingFormatW3C)
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "tracing"})
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic1, &i1, &s, &d1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+			Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+
+			policy := decodePolicy(foundEdges.Items[0].Spec.Routes[0].Policy)
+			Expect(policy.Inbound).To(HaveLen(1))
+			Expect(decodeHeaders(policy.Inbound[0])).To(HaveKey("traceparent"))
+		})
+
+		It("Should keep the rate limit rule first when the module set also limits the rate", func() {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			utilruntime.Must(ingressv1alpha1.AddToScheme(scheme))
+			utilruntime.Must(ngrokv1alpha1.AddToScheme(scheme))
+
+			driver := NewDriver(logr.Discard(), scheme, defaultControllerName, types.NamespacedName{Name: defaultManagerName}, false)
+			driver.syncAllowConcurrent = true
+
+			ms := NewTestNgrokModuleSet("tracing", "test-namespace", false)
+			ms.Modules.RateLimit = &ingressv1alpha1.EndpointRateLimit{RequestsPerSecond: 10}
+			ms.Modules.Tracing = testTracing(TracingFormatW3C)
+			i1 := NewTestIngressV1("test-ingress", "test-namespace")
+			i1.SetAnnotations(map[string]string{"k8s.ngrok.com/modules": "tracing"})
+			ic1 := NewTestIngressClass("test-ingress-class", true, true)
+			s := NewTestServiceV1("example", "test-namespace")
+			d1 := NewReservedDomainV1("example.com", "test-namespace")
+			c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&ic1, &i1, &s, &d1).Build()
+
+			Expect(driver.Seed(context.Background(), c)).To(Succeed())
+			Expect(driver.store.Update(&ms)).Error().To(Succeed())
+			Expect(driver.Sync(context.Background(), c)).To(Succeed())
+
+			foundEdges := &ingressv1alpha1.HTTPSEdgeList{}
+			Expect(c.List(context.Background(), foundEdges)).To(Succeed())
+			Expect(foundEdges.Items).To(HaveLen(1))
+			Expect(foundEdges.Items[0].Spec.Routes).To(HaveLen(1))
+
+			policy := decodePolicy(foundEdges.Items[0].Spec.Routes[0].Policy)
+			Expect(policy.Inbound).To(HaveLen(2))
+			Expect(policy.Inbound[0].Name).To(Equal("Rate limit"))
+			Expect(policy.Inbound[1].Name).To(Equal("Generate traceparent header"))
+		})
+	})
+})
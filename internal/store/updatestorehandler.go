@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -37,37 +38,66 @@ func NewUpdateStoreHandler(resourceName string, d *Driver, client client.Client)
 
 // Create is called in response to an create event - e.g. Edge Creation.
 func (e *UpdateStoreHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
-	if err := e.store.Update(evt.Object); err != nil {
+	if _, err := e.store.Update(evt.Object); err != nil {
 		e.log.Error(err, "error updating object in create", "object", evt.Object)
 		return
 	}
+	e.driver.reconciled.invalidate()
 }
 
 // Update is called in response to an update event -  e.g. Edge Updated.
 func (e *UpdateStoreHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	if err := e.store.Update(evt.ObjectNew); err != nil {
+	if _, err := e.store.Update(evt.ObjectNew); err != nil {
 		e.log.Error(err, "error updating object in update", "object", evt.ObjectNew)
 		return
 	}
+	// resyncs send updates for objects that haven't changed, which don't need to invalidate anything
+	if evt.ObjectOld.GetResourceVersion() != evt.ObjectNew.GetResourceVersion() {
+		e.driver.reconciled.invalidate()
+		e.logModuleSetChanges(evt.ObjectOld, evt.ObjectNew)
+	}
 	if err := e.driver.updateIngressStatuses(ctx, e.client); err != nil {
 		e.log.Error(err, "error syncing after object update", "object", evt.ObjectNew)
 		return
 	}
 }
 
+// logModuleSetChanges logs the modules that changed when the objects are versions of a module set
+func (e *UpdateStoreHandler) logModuleSetChanges(oldObj, newObj client.Object) {
+	oldMS, ok := oldObj.(*ingressv1alpha1.NgrokModuleSet)
+	if !ok {
+		return
+	}
+	newMS, ok := newObj.(*ingressv1alpha1.NgrokModuleSet)
+	if !ok {
+		return
+	}
+
+	changes, err := Diff(oldMS, newMS)
+	if err != nil {
+		e.log.Error(err, "error diffing module set", "object", newObj)
+		return
+	}
+	for _, change := range changes {
+		e.log.V(1).Info("NgrokModuleSet changed", "name", newMS.Name, "namespace", newMS.Namespace, "change", change.String())
+	}
+}
+
 // Delete is called in response to a delete event - e.g. Edge Deleted.
 func (e *UpdateStoreHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
 	if err := e.store.Delete(evt.Object); err != nil {
 		e.log.Error(err, "error deleting object", "object", evt.Object)
 		return
 	}
+	e.driver.reconciled.invalidate()
 }
 
 // Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
 // external trigger request
 func (e *UpdateStoreHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
-	if err := e.store.Update(evt.Object); err != nil {
+	if _, err := e.store.Update(evt.Object); err != nil {
 		e.log.Error(err, "error updating object in generic", "object", evt.Object)
 		return
 	}
+	e.driver.reconciled.invalidate()
 }
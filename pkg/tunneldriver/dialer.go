@@ -3,6 +3,9 @@ package tunneldriver
 import (
 	"context"
 	"net"
+	"sync"
+
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
 )
 
 // Dialer is the portion of *net.Dialer that this package uses.
@@ -12,3 +15,104 @@ type Dialer interface {
 }
 
 var _ Dialer = &net.Dialer{}
+
+// backendDialer returns the dialer used to connect to a tunnel's backend, sending keepalive probes on its
+// connections at the interval from the tunnel's backend config.
+func backendDialer(spec ingressv1alpha1.TunnelSpec) *net.Dialer {
+	dialer := &net.Dialer{}
+	if spec.BackendConfig != nil && spec.BackendConfig.KeepAliveInterval != nil {
+		dialer.KeepAlive = spec.BackendConfig.KeepAliveInterval.Duration
+	}
+	return dialer
+}
+
+// connLimiter limits the number of concurrent connections to a backend service. A max of 0 means
+// there is no limit. The max can be changed while connections are open, in which case new connections
+// wait until enough of the open ones are closed.
+type connLimiter struct {
+	mu     sync.Mutex
+	max    int
+	active int
+	// changed is closed and replaced when a slot is released or the max changes, waking up the
+	// connections waiting for a slot
+	changed chan struct{}
+	// users are the tunnels and agent endpoints forwarding to the service. They're guarded by the
+	// TunnelDriver's connLimitersMu, which removes the limiter once the last user is gone.
+	users map[string]struct{}
+}
+
+func newConnLimiter(max int) *connLimiter {
+	return &connLimiter{max: max, changed: make(chan struct{}), users: make(map[string]struct{})}
+}
+
+// notify wakes up the connections waiting for a slot. It must be called with mu held.
+func (l *connLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *connLimiter) setMax(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+	l.notify()
+}
+
+// acquire waits for a free slot. It gives up and returns the context's error if the context is done
+// first.
+func (l *connLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.max <= 0 || l.active < l.max {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *connLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.notify()
+}
+
+// limitedDialer is a Dialer that waits for a slot from its connLimiter before dialing. The slot is
+// released when the returned connection is closed.
+type limitedDialer struct {
+	Dialer
+	limiter *connLimiter
+}
+
+func (d *limitedDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if err := d.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	conn, err := d.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		d.limiter.release()
+		return nil, err
+	}
+	return &limitedConn{Conn: conn, release: d.limiter.release}, nil
+}
+
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -1,6 +1,7 @@
 package tunneldriver
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -9,13 +10,17 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/go-logr/logr"
 	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/version"
 	"golang.org/x/exp/maps"
 	"golang.org/x/sync/errgroup"
@@ -47,15 +52,27 @@ const (
 // TunnelDriver is a driver for creating and deleting ngrok tunnels
 type TunnelDriver struct {
 	session atomic.Pointer[sessionState]
-	tunnels map[string]ngrok.Tunnel
+	tunnels map[string]labeledTunnel
+
+	// agentEndpoints are the endpoints started for AgentEndpoints, keyed separately from the labeled tunnels
+	agentEndpoints map[string]agentEndpoint
+
+	// connLimiters limit the concurrent connections to each backend service, keyed by the service's host so
+	// they are shared by all the tunnels forwarding to it. A limiter is removed when the last tunnel or agent
+	// endpoint using it is. The tunnel and agent endpoint reconcilers both use them, so they are guarded by
+	// connLimitersMu.
+	connLimitersMu sync.Mutex
+	connLimiters   map[string]*connLimiter
 }
 
 // TunnelDriverOpts are options for creating a new TunnelDriver
 type TunnelDriverOpts struct {
 	ServerAddr string
 	Region     string
-	RootCAs    string
-	Comments   *TunnelDriverComments
+	// Authtoken is the ngrok authtoken to connect with. Defaults to the NGROK_AUTHTOKEN environment variable.
+	Authtoken string
+	RootCAs   string
+	Comments  *TunnelDriverComments
 }
 
 type TunnelDriverComments struct {
@@ -88,10 +105,15 @@ func New(ctx context.Context, logger logr.Logger, opts TunnelDriverOpts) (*Tunne
 	}
 	connOpts := []ngrok.ConnectOption{
 		ngrok.WithClientInfo("ngrok-ingress-controller", version.GetVersion(), comments...),
-		ngrok.WithAuthtokenFromEnv(),
 		ngrok.WithLogger(k8sLogger{logger}),
 	}
 
+	if opts.Authtoken != "" {
+		connOpts = append(connOpts, ngrok.WithAuthtoken(opts.Authtoken))
+	} else {
+		connOpts = append(connOpts, ngrok.WithAuthtokenFromEnv())
+	}
+
 	if opts.Region != "" {
 		connOpts = append(connOpts, ngrok.WithRegion(opts.Region))
 	}
@@ -123,7 +145,9 @@ func New(ctx context.Context, logger logr.Logger, opts TunnelDriverOpts) (*Tunne
 	}
 
 	td := &TunnelDriver{
-		tunnels: make(map[string]ngrok.Tunnel),
+		tunnels:        make(map[string]labeledTunnel),
+		agentEndpoints: make(map[string]agentEndpoint),
+		connLimiters:   make(map[string]*connLimiter),
 	}
 
 	td.session.Store(&sessionState{
@@ -248,9 +272,19 @@ func caCerts(hostCA bool) (*x509.CertPool, error) {
 	return customCertPool, nil
 }
 
+// labeledTunnel is a running tunnel started for a Tunnel, along with the spec and backend certificate
+// authorities it was started with
+type labeledTunnel struct {
+	tun          ngrok.Tunnel
+	spec         ingressv1alpha1.TunnelSpec
+	backendCAPEM []byte
+}
+
 // CreateTunnel creates and starts a new tunnel in a goroutine. If a tunnel with the same name already exists,
-// it will be stopped and replaced with a new tunnel unless the labels match.
-func (td *TunnelDriver) CreateTunnel(ctx context.Context, name string, spec ingressv1alpha1.TunnelSpec) error {
+// it will be stopped and replaced with a new tunnel unless the labels, metadata and backend configuration match.
+// The backendCAPEM are the PEM encoded certificates used to verify HTTPS backends instead of the system's,
+// read by the caller from the spec's backend certificate authority secret.
+func (td *TunnelDriver) CreateTunnel(ctx context.Context, name string, spec ingressv1alpha1.TunnelSpec, backendCAPEM []byte) error {
 	session, err := td.getSession()
 	if err != nil {
 		return err
@@ -258,28 +292,45 @@ func (td *TunnelDriver) CreateTunnel(ctx context.Context, name string, spec ingr
 
 	log := log.FromContext(ctx)
 
-	if tun, ok := td.tunnels[name]; ok {
-		if maps.Equal(tun.Labels(), spec.Labels) {
-			log.Info("Tunnel labels match existing tunnel, doing nothing")
+	tlsOpts, err := newBackendTLSOptions(spec, backendCAPEM)
+	if err != nil {
+		return err
+	}
+
+	existing, replacing := td.tunnels[name]
+	if replacing {
+		if maps.Equal(existing.tun.Labels(), spec.Labels) && existing.tun.Metadata() == spec.Metadata &&
+			reflect.DeepEqual(existing.spec.BackendConfig, spec.BackendConfig) && bytes.Equal(existing.backendCAPEM, backendCAPEM) {
+			log.Info("Tunnel labels, metadata and backend match existing tunnel, doing nothing")
 			return nil
 		}
 		// There is already a tunnel with this name, start the new one and defer closing the old one
 		//nolint:errcheck
-		defer td.stopTunnel(context.Background(), tun)
+		defer td.stopTunnel(context.Background(), existing.tun)
 	}
 
-	tun, err := session.Listen(ctx, td.buildTunnelConfig(spec.Labels, spec.ForwardsTo, spec.AppProtocol))
+	tun, err := session.Listen(ctx, td.buildTunnelConfig(spec.Labels, spec.Metadata, spec.ForwardsTo, spec.AppProtocol))
 	if err != nil {
 		return err
 	}
-	td.tunnels[name] = tun
+	td.tunnels[name] = labeledTunnel{tun: tun, spec: spec, backendCAPEM: backendCAPEM}
+
+	maxConnections := 0
+	if spec.BackendConfig != nil {
+		maxConnections = int(spec.BackendConfig.MaxConnections)
+	}
+	user := tunnelConnLimiterUser(name)
+	limiter := td.connLimiterFor(user, spec.ForwardsTo, maxConnections)
+	if replacing && backendHost(existing.spec.ForwardsTo) != backendHost(spec.ForwardsTo) {
+		td.releaseConnLimiter(user, existing.spec.ForwardsTo)
+	}
 
 	protocol := ""
 	if spec.BackendConfig != nil {
 		protocol = spec.BackendConfig.Protocol
 	}
 
-	go handleConnections(ctx, &net.Dialer{}, tun, spec.ForwardsTo, protocol, spec.AppProtocol)
+	go handleConnections(ctx, &limitedDialer{Dialer: backendDialer(spec), limiter: limiter}, tun, spec.ForwardsTo, protocol, spec.AppProtocol, tlsOpts)
 	return nil
 }
 
@@ -287,21 +338,182 @@ func (td *TunnelDriver) CreateTunnel(ctx context.Context, name string, spec ingr
 func (td *TunnelDriver) DeleteTunnel(ctx context.Context, name string) error {
 	log := log.FromContext(ctx).WithValues("name", name)
 
-	tun := td.tunnels[name]
-	if tun == nil {
+	existing, ok := td.tunnels[name]
+	if !ok {
 		log.Info("Tunnel not found while trying to delete tunnel")
 		return nil
 	}
 
-	err := td.stopTunnel(ctx, tun)
+	err := td.stopTunnel(ctx, existing.tun)
 	if err != nil {
 		return err
 	}
 	delete(td.tunnels, name)
+	td.releaseConnLimiter(tunnelConnLimiterUser(name), existing.spec.ForwardsTo)
 	log.Info("Tunnel deleted successfully")
 	return nil
 }
 
+// agentEndpoint is a running endpoint started for an AgentEndpoint, along with the spec it was started from
+type agentEndpoint struct {
+	tun  ngrok.Tunnel
+	spec ngrokv1alpha1.AgentEndpointSpec
+}
+
+// CreateAgentEndpoint starts an endpoint on the spec's URL that forwards its connections to the upstream,
+// without using any edges. If an endpoint with the same name already exists, it is replaced with a new one
+// unless its spec is unchanged. The running endpoint's tunnel is returned.
+func (td *TunnelDriver) CreateAgentEndpoint(ctx context.Context, name string, spec ngrokv1alpha1.AgentEndpointSpec) (ngrok.Tunnel, error) {
+	session, err := td.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	log := log.FromContext(ctx)
+
+	tunnelConfig, err := buildAgentEndpointConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := url.Parse(spec.Upstream.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream url %q: %w", spec.Upstream.URL, err)
+	}
+
+	user := agentEndpointConnLimiterUser(name)
+	existing, replacing := td.agentEndpoints[name]
+	if replacing {
+		if existing.spec == spec {
+			log.Info("Agent endpoint spec matches existing endpoint, doing nothing")
+			return existing.tun, nil
+		}
+		if existing.spec.URL == spec.URL {
+			// ngrok only allows one endpoint to be online on a URL, so the old endpoint is stopped before the new
+			// one is started on the same URL
+			if err := td.stopTunnel(ctx, existing.tun); err != nil {
+				return nil, err
+			}
+			delete(td.agentEndpoints, name)
+			td.releaseConnLimiter(user, upstreamHost(existing.spec))
+			replacing = false
+		} else {
+			// There is already an endpoint with this name, defer closing the old one until the new one is started
+			//nolint:errcheck
+			defer td.stopTunnel(context.Background(), existing.tun)
+		}
+	}
+
+	tun, err := session.Listen(ctx, tunnelConfig)
+	if err != nil {
+		return nil, err
+	}
+	td.agentEndpoints[name] = agentEndpoint{tun: tun, spec: spec}
+
+	protocol := ""
+	if upstream.Scheme == "https" {
+		protocol = "HTTPS"
+	}
+	limiter := td.agentEndpointConnLimiter(user, upstream.Host)
+	if replacing && backendHost(upstreamHost(existing.spec)) != backendHost(upstream.Host) {
+		td.releaseConnLimiter(user, upstreamHost(existing.spec))
+	}
+
+	go handleConnections(ctx, &limitedDialer{Dialer: &net.Dialer{}, limiter: limiter}, tun, upstream.Host, protocol, spec.Upstream.Protocol, backendTLSOptions{})
+	return tun, nil
+}
+
+// DeleteAgentEndpoint stops and deletes an agent endpoint
+func (td *TunnelDriver) DeleteAgentEndpoint(ctx context.Context, name string) error {
+	log := log.FromContext(ctx).WithValues("name", name)
+
+	existing, ok := td.agentEndpoints[name]
+	if !ok {
+		log.Info("Agent endpoint not found while trying to delete it")
+		return nil
+	}
+
+	if err := td.stopTunnel(ctx, existing.tun); err != nil {
+		return err
+	}
+	delete(td.agentEndpoints, name)
+	td.releaseConnLimiter(agentEndpointConnLimiterUser(name), upstreamHost(existing.spec))
+	log.Info("Agent endpoint deleted successfully")
+	return nil
+}
+
+// tunnelConnLimiterUser and agentEndpointConnLimiterUser name the tunnels and agent endpoints using a connLimiter
+func tunnelConnLimiterUser(name string) string        { return "Tunnel/" + name }
+func agentEndpointConnLimiterUser(name string) string { return "AgentEndpoint/" + name }
+
+// backendHost returns the host that connLimiters are keyed by, without the port
+func backendHost(forwardsTo string) string {
+	host, _, err := net.SplitHostPort(forwardsTo)
+	if err != nil {
+		return forwardsTo
+	}
+	return host
+}
+
+// connLimiterFor returns the connLimiter shared by all tunnels forwarding to the same backend host, updating
+// its max to maxConnections. The user keeps the limiter until it's released.
+func (td *TunnelDriver) connLimiterFor(user, forwardsTo string, maxConnections int) *connLimiter {
+	limiter, created := td.getOrCreateConnLimiter(user, forwardsTo, maxConnections)
+	if !created {
+		limiter.setMax(maxConnections)
+	}
+	return limiter
+}
+
+// agentEndpointConnLimiter returns the connLimiter of an agent endpoint's upstream host. Agent endpoints don't
+// have a max of their own, so the limiter keeps the max set by the tunnels forwarding to the same host.
+func (td *TunnelDriver) agentEndpointConnLimiter(user, upstreamHost string) *connLimiter {
+	limiter, _ := td.getOrCreateConnLimiter(user, upstreamHost, 0)
+	return limiter
+}
+
+// upstreamHost returns the host of a running agent endpoint's upstream, whose url was parsed when it started
+func upstreamHost(spec ngrokv1alpha1.AgentEndpointSpec) string {
+	upstream, err := url.Parse(spec.Upstream.URL)
+	if err != nil {
+		return ""
+	}
+	return upstream.Host
+}
+
+// getOrCreateConnLimiter returns the connLimiter of the backend host, creating it with a max of
+// maxConnections if there isn't one yet, and adds the user to it. It reports whether the limiter was created.
+func (td *TunnelDriver) getOrCreateConnLimiter(user, forwardsTo string, maxConnections int) (*connLimiter, bool) {
+	host := backendHost(forwardsTo)
+
+	td.connLimitersMu.Lock()
+	defer td.connLimitersMu.Unlock()
+	limiter, ok := td.connLimiters[host]
+	if !ok {
+		limiter = newConnLimiter(maxConnections)
+		td.connLimiters[host] = limiter
+	}
+	limiter.users[user] = struct{}{}
+	return limiter, !ok
+}
+
+// releaseConnLimiter removes the user from the connLimiter of the backend host, removing the limiter once no
+// tunnel or agent endpoint uses it. Connections that are still open keep the limiter they acquired a slot
+// from, while new tunnels to the host start with a new one.
+func (td *TunnelDriver) releaseConnLimiter(user, forwardsTo string) {
+	host := backendHost(forwardsTo)
+
+	td.connLimitersMu.Lock()
+	defer td.connLimitersMu.Unlock()
+	limiter, ok := td.connLimiters[host]
+	if !ok {
+		return
+	}
+	delete(limiter.users, user)
+	if len(limiter.users) == 0 {
+		delete(td.connLimiters, host)
+	}
+}
+
 func (td *TunnelDriver) stopTunnel(ctx context.Context, tun ngrok.Tunnel) error {
 	if tun == nil {
 		return nil
@@ -309,17 +521,126 @@ func (td *TunnelDriver) stopTunnel(ctx context.Context, tun ngrok.Tunnel) error
 	return tun.CloseWithContext(ctx)
 }
 
-func (td *TunnelDriver) buildTunnelConfig(labels map[string]string, destination, appProtocol string) config.Tunnel {
+func (td *TunnelDriver) buildTunnelConfig(labels map[string]string, metadata, destination, appProtocol string) config.Tunnel {
 	opts := []config.LabeledTunnelOption{}
 	for key, value := range labels {
 		opts = append(opts, config.WithLabel(key, value))
 	}
+	if metadata != "" {
+		opts = append(opts, config.WithMetadata(metadata))
+	}
 	opts = append(opts, config.WithForwardsTo(destination))
 	opts = append(opts, config.WithAppProtocol(appProtocol))
 	return config.LabeledTunnel(opts...)
 }
 
-func handleConnections(ctx context.Context, dialer Dialer, tun ngrok.Tunnel, dest string, protocol string, appProtocol string) {
+// defaultEndpointPorts are the ports endpoints are served on for the schemes of their URLs
+var defaultEndpointPorts = map[string]string{"http": "80", "https": "443", "tls": "443"}
+
+// buildAgentEndpointConfig returns the config for an agent endpoint, choosing the kind of endpoint from the
+// scheme of its URL
+func buildAgentEndpointConfig(spec ngrokv1alpha1.AgentEndpointSpec) (config.Tunnel, error) {
+	u, err := url.Parse(spec.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent endpoint url %q: %w", spec.URL, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("agent endpoint url %q has no host", spec.URL)
+	}
+	// only TCP endpoints can listen on a port of their choosing, the others are served on their scheme's port
+	if defaultPort, ok := defaultEndpointPorts[u.Scheme]; ok && u.Port() != "" && u.Port() != defaultPort {
+		return nil, fmt.Errorf("agent endpoint url %q can't use port %s, %s endpoints are served on port %s", spec.URL, u.Port(), u.Scheme, defaultPort)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		opts := []config.HTTPEndpointOption{
+			config.WithDomain(u.Hostname()),
+			config.WithScheme(config.Scheme(u.Scheme)),
+			config.WithForwardsTo(spec.Upstream.URL),
+		}
+		if spec.Metadata != "" {
+			opts = append(opts, config.WithMetadata(spec.Metadata))
+		}
+		if spec.Upstream.Protocol != "" {
+			opts = append(opts, config.WithAppProtocol(spec.Upstream.Protocol))
+		}
+		return config.HTTPEndpoint(opts...), nil
+	case "tcp":
+		opts := []config.TCPEndpointOption{
+			config.WithRemoteAddr(u.Host),
+			config.WithForwardsTo(spec.Upstream.URL),
+		}
+		if spec.Metadata != "" {
+			opts = append(opts, config.WithMetadata(spec.Metadata))
+		}
+		return config.TCPEndpoint(opts...), nil
+	case "tls":
+		opts := []config.TLSEndpointOption{
+			config.WithDomain(u.Hostname()),
+			config.WithForwardsTo(spec.Upstream.URL),
+		}
+		if spec.Metadata != "" {
+			opts = append(opts, config.WithMetadata(spec.Metadata))
+		}
+		return config.TLSEndpoint(opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported agent endpoint url scheme %q, must be one of http, https, tcp, or tls", u.Scheme)
+	}
+}
+
+// backendTLSOptions configure how the certificates of HTTPS backends are verified
+type backendTLSOptions struct {
+	// serverName overrides the backend's host as the name sent with SNI and verified against its certificate
+	serverName string
+	// rootCAs are the certificate authorities that verify the backend's certificate instead of the system's
+	rootCAs            *x509.CertPool
+	insecureSkipVerify bool
+}
+
+// newBackendTLSOptions returns the backend TLS options from the tunnel's backend config, verifying certificates
+// against the PEM encoded certificate authorities if there are any
+func newBackendTLSOptions(spec ingressv1alpha1.TunnelSpec, backendCAPEM []byte) (backendTLSOptions, error) {
+	var opts backendTLSOptions
+	if spec.BackendConfig != nil {
+		opts.serverName = spec.BackendConfig.ServerName
+		opts.insecureSkipVerify = spec.BackendConfig.InsecureSkipVerify
+	}
+
+	if len(backendCAPEM) > 0 {
+		opts.rootCAs = x509.NewCertPool()
+		if !opts.rootCAs.AppendCertsFromPEM(backendCAPEM) {
+			return opts, fmt.Errorf("no PEM encoded certificates found in the backend certificate authorities")
+		}
+	}
+	return opts, nil
+}
+
+// backendTLSConfig returns the TLS configuration used to connect to an HTTPS backend. The backend's
+// certificate is verified unless the options skip verification.
+func backendTLSConfig(dest string, appProtocol string, opts backendTLSOptions) *tls.Config {
+	host, _, err := net.SplitHostPort(dest)
+	if err != nil {
+		host = dest
+	}
+	if opts.serverName != "" {
+		host = opts.serverName
+	}
+	var nextProtos []string
+	if appProtocol == "http2" {
+		nextProtos = []string{"h2", "http/1.1"}
+	}
+
+	return &tls.Config{
+		ServerName:         host,
+		RootCAs:            opts.rootCAs,
+		InsecureSkipVerify: opts.insecureSkipVerify,
+		Renegotiation:      tls.RenegotiateFreelyAsClient,
+		NextProtos:         nextProtos,
+	}
+}
+
+func handleConnections(ctx context.Context, dialer Dialer, tun ngrok.Tunnel, dest string, protocol string, appProtocol string, tlsOpts backendTLSOptions) {
 	logger := log.FromContext(ctx).WithValues("id", tun.ID(), "protocol", protocol, "dest", dest)
 	for {
 		conn, err := tun.Accept()
@@ -338,7 +659,7 @@ func handleConnections(ctx context.Context, dialer Dialer, tun ngrok.Tunnel, des
 
 		go func() {
 			ctx := log.IntoContext(ctx, connLogger)
-			err := handleConn(ctx, dest, protocol, appProtocol, dialer, conn)
+			err := handleConn(ctx, dest, protocol, appProtocol, tlsOpts, dialer, conn)
 			if err == nil || errors.Is(err, net.ErrClosed) {
 				connLogger.Info("Connection closed")
 				return
@@ -349,7 +670,7 @@ func handleConnections(ctx context.Context, dialer Dialer, tun ngrok.Tunnel, des
 	}
 }
 
-func handleConn(ctx context.Context, dest string, protocol string, appProtocol string, dialer Dialer, conn net.Conn) error {
+func handleConn(ctx context.Context, dest string, protocol string, appProtocol string, tlsOpts backendTLSOptions, dialer Dialer, conn net.Conn) error {
 	log := log.FromContext(ctx)
 	next, err := dialer.DialContext(ctx, "tcp", dest)
 	if err != nil {
@@ -358,21 +679,7 @@ func handleConn(ctx context.Context, dest string, protocol string, appProtocol s
 
 	// Support HTTPS backends
 	if protocol == "HTTPS" {
-		host, _, err := net.SplitHostPort(dest)
-		if err != nil {
-			host = dest
-		}
-		var nextProtos []string
-		if appProtocol == "http2" {
-			nextProtos = []string{"h2", "http/1.1"}
-		}
-
-		next = tls.Client(next, &tls.Config{
-			ServerName:         host,
-			InsecureSkipVerify: true,
-			Renegotiation:      tls.RenegotiateFreelyAsClient,
-			NextProtos:         nextProtos,
-		})
+		next = tls.Client(next, backendTLSConfig(dest, appProtocol, tlsOpts))
 	}
 
 	var g errgroup.Group
@@ -2,13 +2,28 @@ package tunneldriver
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"io"
+	"math/big"
 	"net"
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	ingressv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ingress/v1alpha1"
+	ngrokv1alpha1 "github.com/ngrok/kubernetes-ingress-controller/api/ngrok/v1alpha1"
 	"github.com/ngrok/kubernetes-ingress-controller/internal/mocks"
+	"golang.ngrok.com/ngrok"
+	"golang.ngrok.com/ngrok/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestConnectionIsClosed(t *testing.T) {
@@ -45,8 +60,447 @@ func TestConnectionIsClosed(t *testing.T) {
 		select {}
 	}).AnyTimes()
 
-	go handleConnections(ctx, mockDialer, mockTun, "target:port", "", "")
+	go handleConnections(ctx, mockDialer, mockTun, "target:port", "", "", backendTLSOptions{})
 
 	bothClosed.Wait()
 	ctrl.Finish()
 }
+
+func TestBackendTLSConfig(t *testing.T) {
+	config := backendTLSConfig("example.default.svc.cluster.local:443", "", backendTLSOptions{})
+	if config.InsecureSkipVerify {
+		t.Error("expected the backend certificate to be verified by default")
+	}
+	if config.ServerName != "example.default.svc.cluster.local" {
+		t.Errorf("expected server name to be the backend host, got %q", config.ServerName)
+	}
+	if config.RootCAs != nil {
+		t.Error("expected the system certificate authorities to be used by default")
+	}
+
+	config = backendTLSConfig("example.default.svc.cluster.local:443", "http2", backendTLSOptions{insecureSkipVerify: true})
+	if !config.InsecureSkipVerify {
+		t.Error("expected the backend certificate verification to be skipped")
+	}
+	if len(config.NextProtos) != 2 || config.NextProtos[0] != "h2" {
+		t.Errorf("expected h2 to be negotiated for http2 backends, got %v", config.NextProtos)
+	}
+
+	config = backendTLSConfig("example.default.svc.cluster.local:443", "", backendTLSOptions{serverName: "api.internal.example.com"})
+	if config.ServerName != "api.internal.example.com" {
+		t.Errorf("expected server name to be overridden, got %q", config.ServerName)
+	}
+}
+
+func TestNewBackendTLSOptions(t *testing.T) {
+	spec := ingressv1alpha1.TunnelSpec{
+		BackendConfig: &ingressv1alpha1.BackendConfig{
+			Protocol:                   "HTTPS",
+			ServerName:                 "api.internal.example.com",
+			CertificateAuthoritySecret: "backend-ca",
+		},
+	}
+
+	opts, err := newBackendTLSOptions(spec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.serverName != "api.internal.example.com" || opts.rootCAs != nil {
+		t.Errorf("unexpected options without certificate authorities: %+v", opts)
+	}
+
+	caPEM := newTestCAPEM(t)
+	opts, err = newBackendTLSOptions(spec, caPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := x509.NewCertPool()
+	expected.AppendCertsFromPEM(caPEM)
+	if opts.rootCAs == nil || !opts.rootCAs.Equal(expected) {
+		t.Error("expected the backend certificate authorities to be the root CAs")
+	}
+
+	if _, err := newBackendTLSOptions(spec, []byte("not a certificate")); err == nil {
+		t.Error("expected an error for certificate authorities without PEM encoded certificates")
+	}
+}
+
+// newTestCAPEM returns a PEM encoded self signed certificate authority
+func newTestCAPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "backend-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestBackendDialer(t *testing.T) {
+	dialer := backendDialer(ingressv1alpha1.TunnelSpec{})
+	if dialer.KeepAlive != 0 {
+		t.Errorf("expected the default keepalive interval without a backend config, got %s", dialer.KeepAlive)
+	}
+
+	dialer = backendDialer(ingressv1alpha1.TunnelSpec{
+		BackendConfig: &ingressv1alpha1.BackendConfig{
+			KeepAliveInterval: &metav1.Duration{Duration: 45 * time.Second},
+		},
+	})
+	if dialer.KeepAlive != 45*time.Second {
+		t.Errorf("expected the keepalive interval from the backend config, got %s", dialer.KeepAlive)
+	}
+}
+
+func TestBuildTunnelConfig(t *testing.T) {
+	td := &TunnelDriver{}
+	labels := map[string]string{"k8s.ngrok.com/service": "example"}
+	metadata := `{"team":"payments"}`
+
+	cfg := td.buildTunnelConfig(labels, metadata, "example.default.svc.cluster.local:80", "")
+
+	labeled, ok := cfg.(interface{ Labels() map[string]string })
+	if !ok {
+		t.Fatalf("expected a labeled tunnel config, got %T", cfg)
+	}
+	if len(labeled.Labels()) != 1 || labeled.Labels()["k8s.ngrok.com/service"] != "example" {
+		t.Errorf("expected the tunnel labels to be set, got %v", labeled.Labels())
+	}
+
+	// the metadata is only exposed through the config's bind extra
+	extra := reflect.ValueOf(cfg).MethodByName("Extra").Call(nil)[0]
+	if got := extra.FieldByName("Metadata").String(); got != metadata {
+		t.Errorf("expected the tunnel metadata to be %q, got %q", metadata, got)
+	}
+}
+
+func TestBuildAgentEndpointConfig(t *testing.T) {
+	upstream := ngrokv1alpha1.AgentEndpointUpstream{URL: "http://example.default:80"}
+	cases := []struct {
+		url   string
+		proto string
+	}{
+		{"https://example.ngrok.app", "https"},
+		{"http://example.ngrok.app", "http"},
+		{"tcp://1.tcp.ngrok.io:12345", "tcp"},
+		{"tls://example.ngrok.app", "tls"},
+		{"https://example.ngrok.app:443", "https"},
+	}
+	for _, c := range cases {
+		cfg, err := buildAgentEndpointConfig(ngrokv1alpha1.AgentEndpointSpec{URL: c.url, Upstream: upstream})
+		if err != nil {
+			t.Fatalf("unexpected error building config for %q: %v", c.url, err)
+		}
+		proto, ok := cfg.(interface{ Proto() string })
+		if !ok {
+			t.Fatalf("expected an endpoint config, got %T", cfg)
+		}
+		if proto.Proto() != c.proto {
+			t.Errorf("expected %q to build a %s endpoint, got %s", c.url, c.proto, proto.Proto())
+		}
+	}
+
+	for _, invalid := range []string{"ftp://example.ngrok.app", "example.ngrok.app", "https://example.ngrok.app:8443", "tls://example.ngrok.app:80"} {
+		if _, err := buildAgentEndpointConfig(ngrokv1alpha1.AgentEndpointSpec{URL: invalid, Upstream: upstream}); err == nil {
+			t.Errorf("expected an error building config for %q", invalid)
+		}
+	}
+}
+
+// singleURLSession is an ngrok session that, like ngrok, refuses to start an endpoint while another endpoint is
+// still online on the URL. The tests only start endpoints on one URL.
+type singleURLSession struct {
+	ngrok.Session
+	ctrl   *gomock.Controller
+	online int
+}
+
+func (s *singleURLSession) Listen(context.Context, config.Tunnel) (ngrok.Tunnel, error) {
+	if s.online > 0 {
+		return nil, errors.New("the endpoint is already online")
+	}
+	s.online++
+	tun := mocks.NewMockTunnel(s.ctrl)
+	tun.EXPECT().ID().Return("ep_123").AnyTimes()
+	tun.EXPECT().Accept().Return(nil, errors.New("tunnel closed")).AnyTimes()
+	tun.EXPECT().CloseWithContext(gomock.Any()).DoAndReturn(func(context.Context) error {
+		s.online--
+		return nil
+	})
+	return tun, nil
+}
+
+func TestAgentEndpointIsReplacedOnTheSameURL(t *testing.T) {
+	session := &singleURLSession{ctrl: gomock.NewController(t)}
+	td := &TunnelDriver{
+		agentEndpoints: make(map[string]agentEndpoint),
+		connLimiters:   make(map[string]*connLimiter),
+	}
+	td.session.Store(&sessionState{session: session})
+
+	spec := ngrokv1alpha1.AgentEndpointSpec{
+		URL:      "https://example.ngrok.app",
+		Upstream: ngrokv1alpha1.AgentEndpointUpstream{URL: "http://example.default:80"},
+	}
+	if _, err := td.CreateAgentEndpoint(context.Background(), "example", spec); err != nil {
+		t.Fatalf("unexpected error starting the endpoint: %v", err)
+	}
+
+	spec.Metadata = "updated"
+	if _, err := td.CreateAgentEndpoint(context.Background(), "example", spec); err != nil {
+		t.Fatalf("expected the endpoint to be replaced on the same url, got %v", err)
+	}
+	if session.online != 1 {
+		t.Fatalf("expected one endpoint online, got %d", session.online)
+	}
+}
+
+// countingDialer returns one end of a pipe for each dial and tracks the number of open connections
+type countingDialer struct {
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+}
+
+func (d *countingDialer) DialContext(context.Context, string, string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.open++
+	if d.open > d.maxOpen {
+		d.maxOpen = d.open
+	}
+	conn, other := net.Pipe()
+	other.Close()
+	return &countedConn{Conn: conn, dialer: d}, nil
+}
+
+type countedConn struct {
+	net.Conn
+	dialer *countingDialer
+	once   sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() {
+		c.dialer.mu.Lock()
+		c.dialer.open--
+		c.dialer.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+func TestConnLimiterIsSharedByService(t *testing.T) {
+	td := &TunnelDriver{connLimiters: make(map[string]*connLimiter)}
+
+	// tunnels for two ports of the same service, e.g. from different ingresses, share a limiter
+	limiter := td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 2)
+	if other := td.connLimiterFor("Tunnel/b", "example.default.svc.cluster.local:443", 2); other != limiter {
+		t.Fatal("expected tunnels to the same service to share a limiter")
+	}
+	if other := td.connLimiterFor("Tunnel/c", "other.default.svc.cluster.local:80", 2); other == limiter {
+		t.Fatal("expected tunnels to different services to have their own limiter")
+	}
+
+	backend := &countingDialer{}
+	dialers := []Dialer{
+		&limitedDialer{Dialer: backend, limiter: limiter},
+		&limitedDialer{Dialer: backend, limiter: limiter},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(dialer Dialer) {
+			defer wg.Done()
+			conn, err := dialer.DialContext(context.Background(), "tcp", "example.default.svc.cluster.local:80")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(time.Millisecond)
+			conn.Close()
+			// closing twice only releases the slot once
+			conn.Close()
+		}(dialers[i%len(dialers)])
+	}
+	wg.Wait()
+
+	if backend.maxOpen > 2 {
+		t.Errorf("expected at most 2 concurrent connections to the service, got %d", backend.maxOpen)
+	}
+	if backend.open != 0 {
+		t.Errorf("expected all connections to be closed, got %d open", backend.open)
+	}
+}
+
+func TestConnLimiterMaxCanBeRaised(t *testing.T) {
+	td := &TunnelDriver{connLimiters: make(map[string]*connLimiter)}
+	limiter := td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 1)
+	limiter.acquire(context.Background())
+
+	acquired := make(chan struct{})
+	go func() {
+		limiter.acquire(context.Background())
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected the second connection to wait for the first one")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// raising the max of the service lets the waiting connection through
+	td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 0)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the second connection to proceed after removing the limit")
+	}
+}
+
+func TestLimitedDialerGivesUpWhenCancelled(t *testing.T) {
+	limiter := newConnLimiter(1)
+	if err := limiter.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &countingDialer{}
+	dialer := &limitedDialer{Dialer: backend, limiter: limiter}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dialed := make(chan error)
+	go func() {
+		_, err := dialer.DialContext(ctx, "tcp", "example.default.svc.cluster.local:80")
+		dialed <- err
+	}()
+
+	select {
+	case <-dialed:
+		t.Fatal("expected the dial to wait for a free slot")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-dialed:
+		if err != context.Canceled {
+			t.Fatalf("expected the dial to return %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the dial to give up waiting after its context was cancelled")
+	}
+
+	// the cancelled dial didn't take a slot, so releasing the first one lets the next dial through
+	limiter.release()
+	conn, err := dialer.DialContext(context.Background(), "tcp", "example.default.svc.cluster.local:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestAgentEndpointKeepsServiceMaxConnections(t *testing.T) {
+	td := &TunnelDriver{connLimiters: make(map[string]*connLimiter)}
+	limiter := td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 1)
+
+	// an agent endpoint forwarding to the same service shares its limiter without removing its max
+	if other := td.agentEndpointConnLimiter("AgentEndpoint/a", "example.default.svc.cluster.local:8080"); other != limiter {
+		t.Fatal("expected the agent endpoint to share the service's limiter")
+	}
+	if limiter.max != 1 {
+		t.Fatalf("expected the service's max connections to stay 1, got %d", limiter.max)
+	}
+
+	// an agent endpoint to a service without tunnels gets a limiter without a max
+	if other := td.agentEndpointConnLimiter("AgentEndpoint/b", "other.default.svc.cluster.local"); other.max != 0 {
+		t.Fatalf("expected no max connections for a new limiter, got %d", other.max)
+	}
+}
+
+func TestConnLimitersCanBeUsedConcurrently(t *testing.T) {
+	td := &TunnelDriver{connLimiters: make(map[string]*connLimiter)}
+
+	// the tunnel and agent endpoint reconcilers look up limiters at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 2)
+		}()
+		go func() {
+			defer wg.Done()
+			td.agentEndpointConnLimiter("AgentEndpoint/a", "example.default.svc.cluster.local:80")
+		}()
+	}
+	wg.Wait()
+
+	if len(td.connLimiters) != 1 {
+		t.Fatalf("expected one limiter for the service, got %d", len(td.connLimiters))
+	}
+}
+
+func TestConnLimiterIsRemovedWithItsLastUser(t *testing.T) {
+	td := &TunnelDriver{connLimiters: make(map[string]*connLimiter)}
+	limiter := td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 1)
+	td.connLimiterFor("Tunnel/a", "example.default.svc.cluster.local:80", 1)
+	td.agentEndpointConnLimiter("AgentEndpoint/a", "example.default.svc.cluster.local:8080")
+
+	td.releaseConnLimiter("Tunnel/a", "example.default.svc.cluster.local:80")
+	if len(td.connLimiters) != 1 {
+		t.Fatal("expected the limiter to be kept while the agent endpoint uses it")
+	}
+
+	td.releaseConnLimiter("AgentEndpoint/a", "example.default.svc.cluster.local:8080")
+	if len(td.connLimiters) != 0 {
+		t.Fatalf("expected the limiter to be removed with its last user, got %d limiters", len(td.connLimiters))
+	}
+	if other := td.connLimiterFor("Tunnel/b", "example.default.svc.cluster.local:80", 2); other == limiter {
+		t.Fatal("expected a new tunnel to the service to get a new limiter")
+	}
+}
+
+func TestAgentEndpointConnLimiterIsRemovedWithTheEndpoint(t *testing.T) {
+	session := &singleURLSession{ctrl: gomock.NewController(t)}
+	td := &TunnelDriver{
+		agentEndpoints: make(map[string]agentEndpoint),
+		connLimiters:   make(map[string]*connLimiter),
+	}
+	td.session.Store(&sessionState{session: session})
+
+	spec := ngrokv1alpha1.AgentEndpointSpec{
+		URL:      "https://example.ngrok.app",
+		Upstream: ngrokv1alpha1.AgentEndpointUpstream{URL: "http://example.default:80"},
+	}
+	if _, err := td.CreateAgentEndpoint(context.Background(), "example", spec); err != nil {
+		t.Fatalf("unexpected error starting the endpoint: %v", err)
+	}
+
+	// moving the endpoint to another upstream releases the old upstream's limiter
+	spec.Upstream.URL = "http://other.default:80"
+	if _, err := td.CreateAgentEndpoint(context.Background(), "example", spec); err != nil {
+		t.Fatalf("unexpected error replacing the endpoint: %v", err)
+	}
+	if _, ok := td.connLimiters["example.default"]; ok || len(td.connLimiters) != 1 {
+		t.Fatalf("expected only the new upstream's limiter, got %v", td.connLimiters)
+	}
+
+	if err := td.DeleteAgentEndpoint(context.Background(), "example"); err != nil {
+		t.Fatalf("unexpected error deleting the endpoint: %v", err)
+	}
+	if len(td.connLimiters) != 0 {
+		t.Fatalf("expected the limiter to be removed with the endpoint, got %v", td.connLimiters)
+	}
+}